@@ -1,8 +1,12 @@
 package api
 
 import (
+	"context"
+	"fmt"
 	"mime/multipart"
 	"net/textproto"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -21,6 +25,886 @@ type GetUntaggedTransactionsRequest struct {
 }
 
 type TagTransactionRequest struct {
-	Id  uuid.UUID `json:"id"`
-	Tag string    `json:"tag"`
+	Id uuid.UUID `json:"id"`
+	// Tag is the slug of a category
+	Tag string `json:"tag" example:"groceries"`
+}
+
+func (r TagTransactionRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if r.Id == uuid.Nil {
+		problems["id"] = "is required"
+	}
+	if strings.TrimSpace(r.Tag) == "" {
+		problems["tag"] = "cannot be empty"
+	}
+	return problems
+}
+
+type CreateTransactionRequest struct {
+	Description string `json:"description" example:"Cash at the market"`
+	Note        string `json:"note,omitempty" example:"Vegetables"`
+	// Direction is in or out
+	Direction   string `json:"direction" example:"out"`
+	AmountCents int64  `json:"amountCents" example:"1250"`
+	// Currency is the ISO 4217 code of the amount, defaults to EUR
+	Currency string `json:"currency,omitempty" example:"EUR"`
+	Date     string `json:"date" example:"2025-01-15"`
+	// Account is our own account the transaction was booked on, empty for
+	// cash
+	Account string `json:"account,omitempty" example:"NL91ABNA0417164300"`
+}
+
+func (r CreateTransactionRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if strings.TrimSpace(r.Description) == "" {
+		problems["description"] = "cannot be empty"
+	}
+	if r.Direction != "in" && r.Direction != "out" {
+		problems["direction"] = "must be in or out"
+	}
+	if r.AmountCents <= 0 {
+		problems["amountCents"] = "must be positive"
+	}
+	if r.Currency != "" && len(r.Currency) != 3 {
+		problems["currency"] = "must be an ISO 4217 code"
+	}
+	if _, err := time.Parse(time.DateOnly, r.Date); err != nil {
+		problems["date"] = "must be a date formatted as YYYY-MM-DD"
+	}
+	return problems
+}
+
+type ListRefundLinksRequest struct {
+	Status string `query:"status"`
+}
+
+func (r ListRefundLinksRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	switch r.Status {
+	case "", "suggested", "confirmed", "rejected":
+	default:
+		problems["status"] = "must be one of suggested, confirmed or rejected"
+	}
+	return problems
+}
+
+type RefundLinkRequest struct {
+	ID uuid.UUID `path:"id"`
+}
+
+type ListCounterpartiesRequest struct {
+	Search string `query:"search"`
+}
+
+type CounterpartyRequest struct {
+	ID uuid.UUID `path:"id"`
+}
+
+type UpdateCounterpartyRequest struct {
+	ID         uuid.UUID `json:"-" path:"id"`
+	Name       *string   `json:"name,omitempty"`
+	Aliases    []string  `json:"aliases,omitempty"`
+	IBANs      []string  `json:"ibans,omitempty"`
+	DefaultTag *string   `json:"defaultTag,omitempty"`
+	LogoURL    *string   `json:"logoUrl,omitempty"`
+}
+
+func (r UpdateCounterpartyRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if r.Name != nil && strings.TrimSpace(*r.Name) == "" {
+		problems["name"] = "cannot be empty"
+	}
+	return problems
+}
+
+type MergeCounterpartiesRequest struct {
+	ID        uuid.UUID   `json:"-" path:"id"`
+	SourceIDs []uuid.UUID `json:"sourceIds"`
+}
+
+func (r MergeCounterpartiesRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if len(r.SourceIDs) == 0 {
+		problems["sourceIds"] = "at least one counterparty to merge is required"
+	}
+	return problems
+}
+
+type SplitCounterpartyRequest struct {
+	ID      uuid.UUID `json:"-" path:"id"`
+	Name    string    `json:"name"`
+	Aliases []string  `json:"aliases"`
+	IBANs   []string  `json:"ibans"`
+}
+
+func (r SplitCounterpartyRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if strings.TrimSpace(r.Name) == "" {
+		problems["name"] = "cannot be empty"
+	}
+	if len(r.Aliases) == 0 && len(r.IBANs) == 0 {
+		problems["aliases"] = "at least one alias or IBAN to split off is required"
+	}
+	return problems
+}
+
+type RuleConditions struct {
+	DescriptionRegex string     `json:"descriptionRegex,omitempty" example:"(?i)^huur"`
+	NoteRegex        string     `json:"noteRegex,omitempty"`
+	CounterpartyID   *uuid.UUID `json:"counterpartyId,omitempty"`
+	MinAmountCents   *int64     `json:"minAmountCents,omitempty" example:"100000"`
+	MaxAmountCents   *int64     `json:"maxAmountCents,omitempty" example:"150000"`
+	Direction        string     `json:"direction,omitempty" example:"out"`
+	Account          string     `json:"account,omitempty" example:"NL90INGB0004140494"`
+	// Weekdays with 0 being sunday
+	Weekdays []int `json:"weekdays,omitempty" example:"1,2,3,4,5"`
+}
+
+type RuleActions struct {
+	SetTag     *string `json:"setTag,omitempty" example:"rent"`
+	SetIgnored *bool   `json:"setIgnored,omitempty"`
+	SetNote    *string `json:"setNote,omitempty"`
+}
+
+type RuleRequest struct {
+	ID         uuid.UUID      `json:"-" path:"id"`
+	Name       string         `json:"name" example:"Monthly rent"`
+	Priority   int            `json:"priority" example:"10"`
+	Enabled    bool           `json:"enabled" example:"true"`
+	Conditions RuleConditions `json:"conditions"`
+	Actions    RuleActions    `json:"actions"`
+}
+
+func (r RuleRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if strings.TrimSpace(r.Name) == "" {
+		problems["name"] = "cannot be empty"
+	}
+	switch r.Conditions.Direction {
+	case "", "in", "out":
+	default:
+		problems["conditions.direction"] = "must be in or out"
+	}
+	for _, d := range r.Conditions.Weekdays {
+		if d < 0 || d > 6 {
+			problems["conditions.weekdays"] = "must be between 0 (sunday) and 6 (saturday)"
+		}
+	}
+	return problems
+}
+
+type RuleIDRequest struct {
+	ID uuid.UUID `path:"id"`
+}
+
+type ApplyRuleRequest struct {
+	ID           uuid.UUID `path:"id"`
+	DryRun       bool      `query:"dry_run"`
+	OnlyUntagged bool      `query:"only_untagged"`
+}
+
+type ListRuleSuggestionsRequest struct {
+	Status string `query:"status"`
+}
+
+func (r ListRuleSuggestionsRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	switch r.Status {
+	case "", "pending", "accepted", "dismissed":
+	default:
+		problems["status"] = "must be one of pending, accepted or dismissed"
+	}
+	return problems
+}
+
+type ReviewTagsRequest struct {
+	// Below overrides the configured confidence threshold of the queue.
+	Below    float64 `query:"below"`
+	Page     int     `query:"page"`
+	PageSize int     `query:"page_size"`
+}
+
+func (r ReviewTagsRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if r.Below < 0 || r.Below > 1 {
+		problems["below"] = "must be between 0 and 1"
+	}
+	if r.Page < 0 {
+		problems["page"] = "must be positive"
+	}
+	if r.PageSize < 0 || r.PageSize > 500 {
+		problems["page_size"] = "must be between 1 and 500"
+	}
+	return problems
+}
+
+type ReviewTagRequest struct {
+	ID uuid.UUID `path:"id"`
+	// Tag corrects the machine tag, the current tag is accepted when empty.
+	Tag string `json:"tag,omitempty" example:"groceries"`
+}
+
+type RuleSuggestionRequest struct {
+	ID uuid.UUID `path:"id"`
+}
+
+type CreateCategoryRequest struct {
+	Name     string     `json:"name" example:"Rent"`
+	ParentID *uuid.UUID `json:"parentId,omitempty"`
+	// Type is income or expense, subcategories inherit the type of their
+	// parent when empty
+	Type   string `json:"type,omitempty" example:"expense"`
+	Colour string `json:"colour,omitempty" example:"#1e90ff"`
+	Icon   string `json:"icon,omitempty" example:"home"`
+}
+
+func (r CreateCategoryRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if strings.TrimSpace(r.Name) == "" {
+		problems["name"] = "cannot be empty"
+	}
+	if r.ParentID == nil && r.Type == "" {
+		problems["type"] = "is required for top level categories"
+	}
+	return problems
+}
+
+type CategoryRequest struct {
+	ID uuid.UUID `path:"id"`
+}
+
+type UpdateCategoryRequest struct {
+	ID   uuid.UUID `json:"-" path:"id"`
+	Name *string   `json:"name,omitempty" example:"Rent"`
+	// ParentID moves the category, the nil uuid moves it to the top level
+	ParentID *uuid.UUID `json:"parentId,omitempty"`
+	Type     *string    `json:"type,omitempty" example:"expense"`
+	Colour   *string    `json:"colour,omitempty" example:"#1e90ff"`
+	Icon     *string    `json:"icon,omitempty" example:"home"`
+}
+
+func (r UpdateCategoryRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if r.Name != nil && strings.TrimSpace(*r.Name) == "" {
+		problems["name"] = "cannot be empty"
+	}
+	return problems
+}
+
+type MergeCategoriesRequest struct {
+	ID        uuid.UUID   `json:"-" path:"id"`
+	SourceIDs []uuid.UUID `json:"sourceIds"`
+}
+
+func (r MergeCategoriesRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if len(r.SourceIDs) == 0 {
+		problems["sourceIds"] = "at least one category to merge is required"
+	}
+	return problems
+}
+
+type ReorderCategoriesRequest struct {
+	// IDs of sibling categories in their new order
+	IDs []uuid.UUID `json:"ids"`
+}
+
+func (r ReorderCategoriesRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if len(r.IDs) == 0 {
+		problems["ids"] = "at least one category is required"
+	}
+	return problems
+}
+
+type CategoryTotalsRequest struct {
+	From time.Time `query:"from"`
+	To   time.Time `query:"to"`
+}
+
+func (r CategoryTotalsRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if !r.From.IsZero() && !r.To.IsZero() && r.To.Before(r.From) {
+		problems["to"] = "must not be before from"
+	}
+	return problems
+}
+
+type ListTransactionsRequest struct {
+	From           time.Time `query:"from"`
+	To             time.Time `query:"to"`
+	Tag            string    `query:"tag"`
+	Direction      string    `query:"direction"`
+	CounterpartyID uuid.UUID `query:"counterparty_id"`
+	Labels         []string  `query:"labels"`
+	Page           int       `query:"page"`
+	PageSize       int       `query:"page_size"`
+}
+
+func (r ListTransactionsRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if !r.From.IsZero() && !r.To.IsZero() && r.To.Before(r.From) {
+		problems["to"] = "must not be before from"
+	}
+	switch r.Direction {
+	case "", "in", "out":
+	default:
+		problems["direction"] = "must be in or out"
+	}
+	if r.Page < 0 {
+		problems["page"] = "must be positive"
+	}
+	if r.PageSize < 0 || r.PageSize > 500 {
+		problems["page_size"] = "must be between 1 and 500"
+	}
+	return problems
+}
+
+type LabelTransactionRequest struct {
+	ID     uuid.UUID `json:"-" path:"id"`
+	Labels []string  `json:"labels" example:"vacation-2025,reimbursable"`
+}
+
+func (r LabelTransactionRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if len(r.Labels) == 0 {
+		problems["labels"] = "at least one label is required"
+	}
+	return problems
+}
+
+type UnlabelTransactionRequest struct {
+	ID    uuid.UUID `path:"id"`
+	Label string    `path:"label"`
+}
+
+type BulkLabelRequest struct {
+	TransactionIDs []uuid.UUID `json:"transactionIds"`
+	Add            []string    `json:"add,omitempty" example:"tax-deductible"`
+	Remove         []string    `json:"remove,omitempty" example:"reimbursable"`
+}
+
+func (r BulkLabelRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if len(r.TransactionIDs) == 0 {
+		problems["transactionIds"] = "at least one transaction is required"
+	}
+	if len(r.Add) == 0 && len(r.Remove) == 0 {
+		problems["add"] = "at least one label to add or remove is required"
+	}
+	return problems
+}
+
+type LabelTotalsRequest struct {
+	From time.Time `query:"from"`
+	To   time.Time `query:"to"`
+}
+
+func (r LabelTotalsRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if !r.From.IsZero() && !r.To.IsZero() && r.To.Before(r.From) {
+		problems["to"] = "must not be before from"
+	}
+	return problems
+}
+
+// BulkFilter selects transactions for a bulk operation, at least one field
+// has to be set.
+type BulkFilter struct {
+	From           string     `json:"from,omitempty" example:"2025-01-01"`
+	To             string     `json:"to,omitempty" example:"2025-12-31"`
+	Tag            string     `json:"tag,omitempty" example:"uncategorised"`
+	Direction      string     `json:"direction,omitempty" example:"out"`
+	CounterpartyID *uuid.UUID `json:"counterpartyId,omitempty"`
+	Labels         []string   `json:"labels,omitempty"`
+}
+
+type BulkOperation struct {
+	// Type is one of set_tag, clear_tag, set_ignored, add_label, remove_label
+	// or delete_manual
+	Type    string `json:"type" example:"set_tag"`
+	Tag     string `json:"tag,omitempty" example:"groceries"`
+	Ignored *bool  `json:"ignored,omitempty"`
+	Label   string `json:"label,omitempty" example:"vacation-2025"`
+}
+
+type BulkRequest struct {
+	// IDs of the transactions, when empty the filter selects them
+	IDs       []uuid.UUID   `json:"ids,omitempty"`
+	Filter    *BulkFilter   `json:"filter,omitempty"`
+	Operation BulkOperation `json:"operation"`
+	// Preview only counts the transactions that would change
+	Preview bool `json:"preview" example:"true"`
+}
+
+func (r BulkRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if len(r.IDs) == 0 && r.Filter == nil {
+		problems["ids"] = "either ids or a filter is required"
+	}
+	if len(r.IDs) > 0 && r.Filter != nil {
+		problems["filter"] = "cannot be combined with ids"
+	}
+	if r.Filter != nil {
+		for field, v := range map[string]string{"filter.from": r.Filter.From, "filter.to": r.Filter.To} {
+			if _, err := time.Parse(time.DateOnly, v); v != "" && err != nil {
+				problems[field] = "must be a date formatted as YYYY-MM-DD"
+			}
+		}
+		switch r.Filter.Direction {
+		case "", "in", "out":
+		default:
+			problems["filter.direction"] = "must be in or out"
+		}
+	}
+	if r.Operation.Type == "" {
+		problems["operation.type"] = "is required"
+	}
+	return problems
+}
+
+type BulkBatchRequest struct {
+	BatchID uuid.UUID `path:"batchId"`
+}
+
+type CashflowRequest struct {
+	From     time.Time `query:"from"`
+	To       time.Time `query:"to"`
+	Interval string    `query:"interval"`
+	GroupBy  string    `query:"group_by"`
+}
+
+func (r CashflowRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if !r.From.IsZero() && !r.To.IsZero() && r.To.Before(r.From) {
+		problems["to"] = "must not be before from"
+	}
+	switch r.Interval {
+	case "", "week", "month", "quarter", "year":
+	default:
+		problems["interval"] = "must be week, month, quarter or year"
+	}
+	switch r.GroupBy {
+	case "", "tag", "account", "counterparty":
+	default:
+		problems["group_by"] = "must be tag, account or counterparty"
+	}
+	return problems
+}
+
+type CreateBudgetRequest struct {
+	CategoryID  uuid.UUID `json:"categoryId"`
+	AmountCents int64     `json:"amountCents" example:"40000"`
+	// Period is week, month, quarter or year, defaults to month
+	Period string `json:"period,omitempty" example:"month"`
+	// StartDate is a day in the first period of the budget, defaults to today
+	StartDate string `json:"startDate,omitempty" example:"2025-01-01"`
+	// Rollover adds unused amounts of earlier periods to the budget
+	Rollover bool `json:"rollover" example:"false"`
+}
+
+func (r CreateBudgetRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if r.CategoryID == uuid.Nil {
+		problems["categoryId"] = "is required"
+	}
+	if r.AmountCents <= 0 {
+		problems["amountCents"] = "must be positive"
+	}
+	validBudgetPeriod(problems, &r.Period)
+	if _, err := time.Parse(time.DateOnly, r.StartDate); r.StartDate != "" && err != nil {
+		problems["startDate"] = "must be a date formatted as YYYY-MM-DD"
+	}
+	return problems
+}
+
+type BudgetRequest struct {
+	ID uuid.UUID `path:"id"`
+}
+
+type UpdateBudgetRequest struct {
+	ID          uuid.UUID  `json:"-" path:"id"`
+	CategoryID  *uuid.UUID `json:"categoryId,omitempty"`
+	AmountCents *int64     `json:"amountCents,omitempty" example:"40000"`
+	Period      *string    `json:"period,omitempty" example:"month"`
+	StartDate   *string    `json:"startDate,omitempty" example:"2025-01-01"`
+	Rollover    *bool      `json:"rollover,omitempty"`
+}
+
+func (r UpdateBudgetRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if r.AmountCents != nil && *r.AmountCents <= 0 {
+		problems["amountCents"] = "must be positive"
+	}
+	validBudgetPeriod(problems, r.Period)
+	if r.StartDate != nil {
+		if _, err := time.Parse(time.DateOnly, *r.StartDate); err != nil {
+			problems["startDate"] = "must be a date formatted as YYYY-MM-DD"
+		}
+	}
+	return problems
+}
+
+func validBudgetPeriod(problems map[string]string, period *string) {
+	if period == nil {
+		return
+	}
+	switch *period {
+	case "", "week", "month", "quarter", "year":
+	default:
+		problems["period"] = "must be week, month, quarter or year"
+	}
+}
+
+type BudgetStatusRequest struct {
+	// Period is a month (YYYY-MM) or a day (YYYY-MM-DD) within the periods
+	// to report, defaults to today
+	Period string `query:"period"`
+}
+
+func (r BudgetStatusRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if _, err := r.Date(); err != nil {
+		problems["period"] = "must be formatted as YYYY-MM or YYYY-MM-DD"
+	}
+	return problems
+}
+
+// Date returns the day the status is requested for, the first day for a
+// month and today when no period is given.
+func (r BudgetStatusRequest) Date() (time.Time, error) {
+	switch len(r.Period) {
+	case 0:
+		return time.Now().UTC(), nil
+	case len("2006-01"):
+		return time.Parse("2006-01", r.Period)
+	default:
+		return time.Parse(time.DateOnly, r.Period)
+	}
+}
+
+type ListRecurringRequest struct {
+	Status    string `query:"status"`
+	Direction string `query:"direction"`
+}
+
+func (r ListRecurringRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	switch r.Status {
+	case "", "active", "missed", "ended":
+	default:
+		problems["status"] = "must be active, missed or ended"
+	}
+	switch r.Direction {
+	case "", "in", "out":
+	default:
+		problems["direction"] = "must be in or out"
+	}
+	return problems
+}
+
+type ForecastRequest struct {
+	// Account is the account (usually its IBAN) to forecast, all accounts
+	// when empty
+	Account string `query:"account" json:"account,omitempty" example:"NL91ABNA0417164300"`
+	// Days to project, defaults to 90
+	Days int `query:"days" json:"days,omitempty" example:"90"`
+	// ThresholdCents is the balance below which the forecast warns,
+	// defaults to 0
+	ThresholdCents int64 `query:"threshold" json:"thresholdCents,omitempty" example:"0"`
+	// BalanceCents replaces the current balance known from the transactions
+	BalanceCents *int64 `json:"balanceCents,omitempty" example:"125000"`
+	// WhatIf are extra incomes and expenses to project
+	WhatIf []WhatIfEntry `json:"whatIf,omitempty"`
+}
+
+type WhatIfEntry struct {
+	Date        string `json:"date" example:"2025-11-15"`
+	Description string `json:"description" example:"New laptop"`
+	// AmountCents is positive for income and negative for expenses
+	AmountCents int64 `json:"amountCents" example:"-150000"`
+}
+
+func (r ForecastRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if r.Days < 0 || r.Days > 730 {
+		problems["days"] = "must be between 1 and 730"
+	}
+	for i, e := range r.WhatIf {
+		if _, err := time.Parse(time.DateOnly, e.Date); err != nil {
+			problems[fmt.Sprintf("whatIf[%d].date", i)] = "must be formatted as YYYY-MM-DD"
+		}
+		if e.AmountCents == 0 {
+			problems[fmt.Sprintf("whatIf[%d].amountCents", i)] = "must not be zero"
+		}
+	}
+	return problems
+}
+
+type AccountRequest struct {
+	// ID identifies the account, usually its IBAN
+	ID string `path:"id"`
+}
+
+type UpdateAccountRequest struct {
+	ID   string  `json:"-" path:"id"`
+	Name *string `json:"name,omitempty" example:"Joint account"`
+	// Type is checking, savings or investment
+	Type *string `json:"type,omitempty" example:"savings"`
+	// Currency is the ISO 4217 code the account is held in
+	Currency *string `json:"currency,omitempty" example:"USD"`
+}
+
+func (r UpdateAccountRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if r.Type != nil {
+		switch *r.Type {
+		case "checking", "savings", "investment":
+		default:
+			problems["type"] = "must be checking, savings or investment"
+		}
+	}
+	return problems
+}
+
+type AccountBalancesRequest struct {
+	ID   string    `path:"id"`
+	From time.Time `query:"from"`
+	To   time.Time `query:"to"`
+}
+
+func (r AccountBalancesRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if !r.From.IsZero() && !r.To.IsZero() && r.To.Before(r.From) {
+		problems["to"] = "must not be before from"
+	}
+	if !r.From.IsZero() && !r.To.IsZero() && r.To.Sub(r.From) > 5*366*24*time.Hour {
+		problems["from"] = "must be at most five years before to"
+	}
+	return problems
+}
+
+type BalanceAnchorRequest struct {
+	Account string `json:"-" path:"id"`
+	Date    string `json:"-" path:"date"`
+	// BalanceCents is the closing balance at the end of the day
+	BalanceCents int64 `json:"balanceCents" example:"125000"`
+}
+
+func (r BalanceAnchorRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if _, err := time.Parse(time.DateOnly, r.Date); err != nil {
+		problems["date"] = "must be formatted as YYYY-MM-DD"
+	}
+	return problems
+}
+
+type NetWorthRequest struct {
+	From     time.Time `query:"from"`
+	To       time.Time `query:"to"`
+	Interval string    `query:"interval"`
+}
+
+func (r NetWorthRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if !r.From.IsZero() && !r.To.IsZero() && r.To.Before(r.From) {
+		problems["to"] = "must not be before from"
+	}
+	switch r.Interval {
+	case "", "week", "month", "quarter", "year":
+	default:
+		problems["interval"] = "must be week, month, quarter or year"
+	}
+	return problems
+}
+
+type ConfirmSnapshotsRequest struct {
+	// Through is the last month end to confirm, defaults to today
+	Through time.Time `query:"through"`
+}
+
+type CreateNetWorthItemRequest struct {
+	Name string `json:"name" example:"Mortgage"`
+	// Class is investments, property, vehicle, other_asset, mortgage, loan
+	// or other_liability
+	Class string `json:"class" example:"mortgage"`
+	// Symbol and QuantityMicros (millionths of a unit) value an investment
+	// holding with market prices
+	Symbol         string `json:"symbol,omitempty" example:"VWRL.AS"`
+	QuantityMicros int64  `json:"quantityMicros,omitempty" example:"12500000"`
+	// Loan is the amortisation schedule of a liability
+	Loan *LoanSchedule `json:"loan,omitempty"`
+}
+
+type LoanSchedule struct {
+	PrincipalCents int64 `json:"principalCents" example:"30000000"`
+	// RateBasisPoints is the yearly interest in hundredths of a percent
+	RateBasisPoints int    `json:"rateBasisPoints" example:"385"`
+	StartDate       string `json:"startDate" example:"2022-05-01"`
+	TermMonths      int    `json:"termMonths" example:"360"`
+	// Method is annuity or linear
+	Method string `json:"method" example:"annuity"`
+}
+
+func (r CreateNetWorthItemRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if strings.TrimSpace(r.Name) == "" {
+		problems["name"] = "is required"
+	}
+	switch r.Class {
+	case "investments", "property", "vehicle", "other_asset", "mortgage", "loan", "other_liability":
+	default:
+		problems["class"] = "must be investments, property, vehicle, other_asset, mortgage, loan or other_liability"
+	}
+	if r.QuantityMicros < 0 {
+		problems["quantityMicros"] = "must not be negative"
+	}
+	if r.Loan != nil {
+		if _, err := time.Parse(time.DateOnly, r.Loan.StartDate); err != nil {
+			problems["loan.startDate"] = "must be formatted as YYYY-MM-DD"
+		}
+		switch r.Loan.Method {
+		case "annuity", "linear":
+		default:
+			problems["loan.method"] = "must be annuity or linear"
+		}
+	}
+	return problems
+}
+
+type NetWorthItemRequest struct {
+	ID uuid.UUID `path:"id"`
+}
+
+type NetWorthValuationRequest struct {
+	ID   uuid.UUID `json:"-" path:"id"`
+	Date string    `json:"-" path:"date"`
+	// ValueCents is the value at the end of the day, positive for
+	// liabilities too
+	ValueCents int64 `json:"valueCents" example:"45000000"`
+}
+
+func (r NetWorthValuationRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if _, err := time.Parse(time.DateOnly, r.Date); err != nil {
+		problems["date"] = "must be formatted as YYYY-MM-DD"
+	}
+	return problems
+}
+
+type ImportMarketDataRequest struct {
+	File     multipart.File       `multipart:"file"`
+	Filename string               `multipart:"filename"`
+	Size     int64                `multipart:"size"`
+	Header   textproto.MIMEHeader `multipart:"header"`
+	// Symbol is required unless the file is in the Stooq ASCII format,
+	// which names its ticker
+	Symbol string `form:"symbol"`
+}
+
+type MarketHistoryRequest struct {
+	Symbol string    `path:"symbol"`
+	From   time.Time `query:"from"`
+	To     time.Time `query:"to"`
+}
+
+func (r MarketHistoryRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if !r.From.IsZero() && !r.To.IsZero() && r.To.Before(r.From) {
+		problems["to"] = "must not be before from"
+	}
+	return problems
+}
+
+type MarketSymbolRequest struct {
+	Symbol string `path:"symbol"`
+}
+
+type ImportInvestmentsRequest struct {
+	File     multipart.File       `multipart:"file"`
+	Filename string               `multipart:"filename"`
+	Size     int64                `multipart:"size"`
+	Header   textproto.MIMEHeader `multipart:"header"`
+	// Account identifies the brokerage account, e.g. DEGIRO
+	Account string `form:"account"`
+}
+
+func (r ImportInvestmentsRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if strings.TrimSpace(r.Account) == "" {
+		problems["account"] = "is required"
+	}
+	return problems
+}
+
+type UpdateSecurityRequest struct {
+	ID uuid.UUID `json:"-" path:"id"`
+	// Symbol links the security to its market prices, empty values it at
+	// its last trade
+	Symbol *string `json:"symbol,omitempty" example:"VWRL.AS"`
+	Name   *string `json:"name,omitempty" example:"Vanguard FTSE All-World"`
+}
+
+type InvestmentTradesRequest struct {
+	Security uuid.UUID `query:"security"`
+	Account  string    `query:"account"`
+	From     time.Time `query:"from"`
+	To       time.Time `query:"to"`
+}
+
+type HoldingsRequest struct {
+	Date   time.Time `query:"date"`
+	Method string    `query:"method"`
+}
+
+func (r HoldingsRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	switch r.Method {
+	case "", "fifo", "average":
+	default:
+		problems["method"] = "must be fifo or average"
+	}
+	return problems
+}
+
+type InvestmentPerformanceRequest struct {
+	From   time.Time `query:"from"`
+	To     time.Time `query:"to"`
+	Method string    `query:"method"`
+}
+
+func (r InvestmentPerformanceRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if !r.From.IsZero() && !r.To.IsZero() && r.To.Before(r.From) {
+		problems["to"] = "must not be before from"
+	}
+	switch r.Method {
+	case "", "fifo", "average":
+	default:
+		problems["method"] = "must be fifo or average"
+	}
+	return problems
+}
+
+type ImportFXRatesRequest struct {
+	File     multipart.File       `multipart:"file"`
+	Filename string               `multipart:"filename"`
+	Size     int64                `multipart:"size"`
+	Header   textproto.MIMEHeader `multipart:"header"`
+}
+
+type FXRatesRequest struct {
+	// Currency limits the rates to one currency, all currencies when empty
+	Currency string    `query:"currency"`
+	From     time.Time `query:"from"`
+	To       time.Time `query:"to"`
+}
+
+func (r FXRatesRequest) Valid(ctx context.Context) map[string]string {
+	problems := map[string]string{}
+	if !r.From.IsZero() && !r.To.IsZero() && r.To.Before(r.From) {
+		problems["to"] = "must not be before from"
+	}
+	return problems
 }
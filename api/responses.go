@@ -1,6 +1,7 @@
 package api
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -27,6 +28,540 @@ type Transaction struct {
 	Note        string    `json:"note" example:"Bought fruits and vegetables"`
 	Source      string    `json:"source" example:"MyBank"`
 	AmountCents int64     `json:"amountCents" example:"4250"`
+	Currency    string    `json:"currency" example:"EUR"`
+	Direction   string    `json:"direction" example:"out"`
 	Date        time.Time `json:"date" example:"2025-01-15T00:00:00Z"`
 	Tag         string    `json:"tag" example:"Food"`
+
+	// TagSource is manual, rule, classifier or agent, empty when unknown.
+	TagSource     string     `json:"tagSource,omitempty" example:"agent"`
+	TagConfidence float64    `json:"tagConfidence,omitempty" example:"0.72"`
+	TaggedBy      string     `json:"taggedBy,omitempty" example:"llama3.1"`
+	TaggedAt      *time.Time `json:"taggedAt,omitempty" example:"2025-01-16T08:00:00Z"`
+
+	CounterpartyID   *uuid.UUID `json:"counterpartyId,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	CounterpartyIBAN string     `json:"counterpartyIban,omitempty" example:"NL91ABNA0417164300"`
+
+	// ParentID is the transaction a fee was charged for.
+	ParentID *uuid.UUID `json:"parentId,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+
+	Labels []string `json:"labels,omitempty" example:"vacation-2025"`
+}
+
+type RefundLink struct {
+	ID          uuid.UUID   `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Status      string      `json:"status" example:"suggested"`
+	AmountCents int64       `json:"amountCents" example:"2999"`
+	Original    Transaction `json:"original"`
+	Refund      Transaction `json:"refund"`
+	CreatedAt   time.Time   `json:"createdAt" example:"2025-01-20T10:00:00Z"`
+	UpdatedAt   time.Time   `json:"updatedAt" example:"2025-01-20T10:00:00Z"`
+}
+
+type Counterparty struct {
+	ID         uuid.UUID `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name       string    `json:"name" example:"Albert Heijn"`
+	Aliases    []string  `json:"aliases" example:"AH,ALBERT HEIJN"`
+	IBANs      []string  `json:"ibans" example:"NL91ABNA0417164300"`
+	DefaultTag string    `json:"defaultTag" example:"groceries"`
+	LogoURL    string    `json:"logoUrl" example:"https://example.com/ah.png"`
+}
+
+type Rule struct {
+	ID         uuid.UUID      `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name       string         `json:"name" example:"Monthly rent"`
+	Priority   int            `json:"priority" example:"10"`
+	Enabled    bool           `json:"enabled" example:"true"`
+	Conditions RuleConditions `json:"conditions"`
+	Actions    RuleActions    `json:"actions"`
+	CreatedAt  time.Time      `json:"createdAt"`
+	UpdatedAt  time.Time      `json:"updatedAt"`
+}
+
+type StringChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type BoolChange struct {
+	From bool `json:"from"`
+	To   bool `json:"to"`
+}
+
+type RuleChange struct {
+	TransactionID uuid.UUID     `json:"transactionId"`
+	Description   string        `json:"description"`
+	Date          time.Time     `json:"date"`
+	Tag           *StringChange `json:"tag,omitempty"`
+	Ignored       *BoolChange   `json:"ignored,omitempty"`
+	Note          *StringChange `json:"note,omitempty"`
+}
+
+type ApplyRuleResult struct {
+	DryRun   bool         `json:"dryRun"`
+	Affected int          `json:"affected" example:"12"`
+	Changes  []RuleChange `json:"changes"`
+}
+
+type RuleSuggestion struct {
+	ID        uuid.UUID  `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	KeyType   string     `json:"keyType" example:"counterparty"`
+	Key       string     `json:"key" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Tag       string     `json:"tag" example:"groceries"`
+	Count     int        `json:"count" example:"2"`
+	Status    string     `json:"status" example:"pending"`
+	RuleID    *uuid.UUID `json:"ruleId,omitempty"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+type Category struct {
+	ID       uuid.UUID  `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Slug     string     `json:"slug" example:"rent"`
+	Name     string     `json:"name" example:"Rent"`
+	ParentID *uuid.UUID `json:"parentId,omitempty"`
+	Type     string     `json:"type" example:"expense"`
+	Colour   string     `json:"colour,omitempty" example:"#1e90ff"`
+	Icon     string     `json:"icon,omitempty" example:"home"`
+	Position int        `json:"position" example:"0"`
+	System   bool       `json:"system" example:"false"`
+	Children []Category `json:"children,omitempty"`
+}
+
+type CategoryTotals struct {
+	ID   uuid.UUID `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Slug string    `json:"slug" example:"housing"`
+	Name string    `json:"name" example:"Housing"`
+	Type string    `json:"type" example:"expense"`
+	// Own totals of transactions in the category itself
+	InCents  int64 `json:"inCents" example:"0"`
+	OutCents int64 `json:"outCents" example:"120000"`
+	// Totals including all subcategories
+	TotalInCents  int64            `json:"totalInCents" example:"0"`
+	TotalOutCents int64            `json:"totalOutCents" example:"150000"`
+	Children      []CategoryTotals `json:"children,omitempty"`
+}
+
+type TransactionPage struct {
+	Items    []Transaction `json:"items"`
+	Total    int           `json:"total" example:"120"`
+	Page     int           `json:"page" example:"1"`
+	PageSize int           `json:"pageSize" example:"50"`
+}
+
+type Label struct {
+	ID    uuid.UUID `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name  string    `json:"name" example:"vacation-2025"`
+	Count int       `json:"count" example:"12"`
+}
+
+type LabelTotals struct {
+	Name     string `json:"name" example:"vacation-2025"`
+	Count    int    `json:"count" example:"12"`
+	InCents  int64  `json:"inCents" example:"0"`
+	OutCents int64  `json:"outCents" example:"185000"`
+}
+
+type BulkResult struct {
+	// BatchID identifies the batch to undo, it is empty for previews and
+	// operations that changed nothing
+	BatchID   *uuid.UUID    `json:"batchId,omitempty"`
+	Operation BulkOperation `json:"operation"`
+	Affected  int           `json:"affected" example:"312"`
+	Preview   bool          `json:"preview" example:"false"`
+	CreatedAt time.Time     `json:"createdAt"`
+	UndoneAt  *time.Time    `json:"undoneAt,omitempty"`
+}
+
+type Health struct {
+	Status string `json:"status" example:"ok"`
+	// Agent is the state of the agent circuit breaker: closed, open or
+	// half-open.
+	Agent string `json:"agent" example:"closed"`
+}
+
+type CashflowBucket struct {
+	// Start and End are the first and last day of the bucket
+	Start string `json:"start" example:"2025-01-01"`
+	End   string `json:"end" example:"2025-01-31"`
+	// Group is the tag, account or counterparty name when the report is
+	// grouped
+	Group        string `json:"group,omitempty" example:"groceries"`
+	IncomeCents  int64  `json:"incomeCents" example:"0"`
+	ExpenseCents int64  `json:"expenseCents" example:"45210"`
+	NetCents     int64  `json:"netCents" example:"-45210"`
+	// Unconverted is the number of transactions left out because their
+	// currency has no exchange rate
+	Unconverted int `json:"unconverted,omitempty" example:"0"`
+}
+
+type CashflowReport struct {
+	Interval string `json:"interval" example:"month"`
+	GroupBy  string `json:"groupBy,omitempty" example:"tag"`
+	// Currency is the reporting currency the amounts are converted to
+	Currency string           `json:"currency" example:"EUR"`
+	Buckets  []CashflowBucket `json:"buckets"`
+}
+
+func (r CashflowReport) MarshalCSV() (header []string, rows [][]string) {
+	header = []string{"start", "end", "group", "income_cents", "expense_cents", "net_cents"}
+	for _, b := range r.Buckets {
+		rows = append(rows, []string{
+			b.Start,
+			b.End,
+			b.Group,
+			strconv.FormatInt(b.IncomeCents, 10),
+			strconv.FormatInt(b.ExpenseCents, 10),
+			strconv.FormatInt(b.NetCents, 10),
+		})
+	}
+	return header, rows
+}
+
+type Budget struct {
+	ID          uuid.UUID `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	CategoryID  uuid.UUID `json:"categoryId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	AmountCents int64     `json:"amountCents" example:"40000"`
+	Period      string    `json:"period" example:"month"`
+	// StartDate is the first day of the first period of the budget
+	StartDate string    `json:"startDate" example:"2025-01-01"`
+	Rollover  bool      `json:"rollover" example:"false"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type BudgetStatus struct {
+	BudgetID     uuid.UUID `json:"budgetId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Category     string    `json:"category" example:"groceries"`
+	CategoryName string    `json:"categoryName" example:"Groceries"`
+	Period       string    `json:"period" example:"month"`
+	PeriodStart  string    `json:"periodStart" example:"2025-01-01"`
+	PeriodEnd    string    `json:"periodEnd" example:"2025-01-31"`
+	AmountCents  int64     `json:"amountCents" example:"40000"`
+	// RolloverCents is the unused amount carried over from earlier periods
+	RolloverCents  int64 `json:"rolloverCents" example:"2500"`
+	AvailableCents int64 `json:"availableCents" example:"42500"`
+	SpentCents     int64 `json:"spentCents" example:"31000"`
+	RemainingCents int64 `json:"remainingCents" example:"11500"`
+	// Percent of the available amount that has been spent
+	Percent float64 `json:"percent" example:"72.9"`
+	// ProjectedCents is the spending expected by the end of the period at
+	// the current pace
+	ProjectedCents int64 `json:"projectedCents" example:"46500"`
+}
+
+type RecurringSeries struct {
+	ID             uuid.UUID  `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name           string     `json:"name" example:"Netflix"`
+	CounterpartyID *uuid.UUID `json:"counterpartyId,omitempty"`
+	Account        string     `json:"account" example:"NL91ABNA0417164300"`
+	Currency       string     `json:"currency" example:"EUR"`
+	Direction      string     `json:"direction" example:"out"`
+	// Frequency is weekly, monthly, quarterly or yearly
+	Frequency           string `json:"frequency" example:"monthly"`
+	AmountCents         int64  `json:"amountCents" example:"1399"`
+	PreviousAmountCents int64  `json:"previousAmountCents" example:"1199"`
+	// PreviousAmountCents is the amount before the last price change,
+	// PriceIncreased is set when that change was an increase
+	PriceIncreased bool   `json:"priceIncreased" example:"true"`
+	YearlyCents    int64  `json:"yearlyCents" example:"16788"`
+	FirstDate      string `json:"firstDate" example:"2024-03-05"`
+	LastDate       string `json:"lastDate" example:"2025-09-05"`
+	NextDate       string `json:"nextDate" example:"2025-10-05"`
+	Occurrences    int    `json:"occurrences" example:"19"`
+	// Status is active, missed when the expected payment is overdue or ended
+	// when several payments did not happen
+	Status string `json:"status" example:"active"`
+	// ReportingYearlyCents is YearlyCents in the reporting currency, only
+	// reported by the costs overview
+	ReportingYearlyCents *int64 `json:"reportingYearlyCents,omitempty" example:"16788"`
+}
+
+type RecurringCosts struct {
+	// Currency is the reporting currency the totals are converted to
+	Currency            string            `json:"currency" example:"EUR"`
+	YearlyExpenseCents  int64             `json:"yearlyExpenseCents" example:"142800"`
+	MonthlyExpenseCents int64             `json:"monthlyExpenseCents" example:"11900"`
+	YearlyIncomeCents   int64             `json:"yearlyIncomeCents" example:"4200000"`
+	ExpensesByFrequency map[string]int64  `json:"expensesByFrequency"`
+	Expenses            []RecurringSeries `json:"expenses"`
+}
+
+type Forecast struct {
+	Account string `json:"account,omitempty" example:"NL91ABNA0417164300"`
+	// Currency is the currency of the account, the reporting currency when
+	// all accounts are forecast
+	Currency          string `json:"currency" example:"EUR"`
+	StartBalanceCents int64  `json:"startBalanceCents" example:"125000"`
+	EndBalanceCents   int64  `json:"endBalanceCents" example:"98000"`
+	ThresholdCents    int64  `json:"thresholdCents" example:"0"`
+	// LowBalanceDate is the first day the balance drops below the
+	// threshold, omitted when it stays above
+	LowBalanceDate     *string `json:"lowBalanceDate,omitempty" example:"2025-10-24"`
+	LowestBalanceCents int64   `json:"lowestBalanceCents" example:"-4500"`
+	LowestBalanceDate  string  `json:"lowestBalanceDate" example:"2025-10-24"`
+	// Discretionary is the average spending per category outside the
+	// recurring payments
+	Discretionary []ForecastCategory `json:"discretionary"`
+	Days          []ForecastDay      `json:"days"`
+}
+
+type ForecastCategory struct {
+	Category     string `json:"category" example:"groceries"`
+	MonthlyCents int64  `json:"monthlyCents" example:"42000"`
+}
+
+type ForecastDay struct {
+	Date        string `json:"date" example:"2025-10-24"`
+	IncomeCents int64  `json:"incomeCents" example:"0"`
+	// ExpenseCents includes the discretionary spending
+	ExpenseCents       int64           `json:"expenseCents" example:"16399"`
+	DiscretionaryCents int64           `json:"discretionaryCents" example:"2000"`
+	BalanceCents       int64           `json:"balanceCents" example:"-4500"`
+	Entries            []ForecastEntry `json:"entries"`
+}
+
+type ForecastEntry struct {
+	Description string `json:"description" example:"Netflix"`
+	// AmountCents is positive for income and negative for expenses
+	AmountCents int64 `json:"amountCents" example:"-1399"`
+	// Source is recurring or what_if
+	Source   string     `json:"source" example:"recurring"`
+	SeriesID *uuid.UUID `json:"seriesId,omitempty"`
+}
+
+type Account struct {
+	// ID identifies the account, usually its IBAN
+	ID   string `json:"id" example:"NL91ABNA0417164300"`
+	Name string `json:"name" example:"Joint account"`
+	// Type is checking, savings or investment
+	Type     string `json:"type" example:"checking"`
+	Currency string `json:"currency" example:"EUR"`
+	// BalanceCents is in the currency of the account
+	BalanceCents int64 `json:"balanceCents" example:"125000"`
+}
+
+type BalanceAnchor struct {
+	Account      string `json:"account" example:"NL91ABNA0417164300"`
+	Date         string `json:"date" example:"2025-09-30"`
+	BalanceCents int64  `json:"balanceCents" example:"125000"`
+}
+
+type AccountBalances struct {
+	Account string `json:"account" example:"NL91ABNA0417164300"`
+	From    string `json:"from" example:"2025-09-01"`
+	To      string `json:"to" example:"2025-09-30"`
+	// Mismatches is the number of days the statement reports a different
+	// balance than the reconstructed one
+	Mismatches int            `json:"mismatches" example:"1"`
+	Days       []DailyBalance `json:"days"`
+}
+
+type DailyBalance struct {
+	Date string `json:"date" example:"2025-09-15"`
+	// NetCents is the incoming minus the outgoing amount of the day
+	NetCents     int64 `json:"netCents" example:"-4250"`
+	BalanceCents int64 `json:"balanceCents" example:"120750"`
+	// ReportedCents is the closing balance according to the bank statement
+	ReportedCents *int64 `json:"reportedCents,omitempty" example:"118250"`
+	// Mismatch is set when the reported and reconstructed balance differ,
+	// usually because transactions are missing
+	Mismatch        bool  `json:"mismatch" example:"true"`
+	DifferenceCents int64 `json:"differenceCents" example:"-2500"`
+	// Anchored is set when the balance was set by an anchor
+	Anchored bool `json:"anchored" example:"false"`
+}
+
+type NetWorth struct {
+	Interval string `json:"interval" example:"month"`
+	// Currency is the reporting currency the values are converted to
+	Currency string          `json:"currency" example:"EUR"`
+	Points   []NetWorthPoint `json:"points"`
+	// Breakdown are the components of the last point
+	Breakdown []NetWorthComponent `json:"breakdown"`
+}
+
+type NetWorthPoint struct {
+	Date             string `json:"date" example:"2025-09-30"`
+	AssetsCents      int64  `json:"assetsCents" example:"52000000"`
+	LiabilitiesCents int64  `json:"liabilitiesCents" example:"28000000"`
+	NetWorthCents    int64  `json:"netWorthCents" example:"24000000"`
+	// ByClass sums the components per asset class, liabilities are
+	// positive as well
+	ByClass map[string]int64 `json:"byClass"`
+	// Snapshot is set when the point was read from a monthly snapshot
+	Snapshot bool `json:"snapshot" example:"true"`
+	// Provisional is set when the snapshot was backfilled and is recomputed
+	// until it is confirmed
+	Provisional bool `json:"provisional" example:"false"`
+}
+
+type ConfirmedSnapshots struct {
+	// Confirmed is the number of month ends whose snapshots became final
+	Confirmed int `json:"confirmed" example:"12"`
+}
+
+type NetWorthComponent struct {
+	// Source is account:<id> or item:<id>
+	Source     string `json:"source" example:"account:NL91ABNA0417164300"`
+	Name       string `json:"name" example:"Joint account"`
+	Class      string `json:"class" example:"cash"`
+	Liability  bool   `json:"liability" example:"false"`
+	ValueCents int64  `json:"valueCents" example:"125000"`
+	// Currency is the currency the component is held in, OriginalCents its
+	// value in that currency
+	Currency      string `json:"currency" example:"USD"`
+	OriginalCents int64  `json:"originalCents" example:"146000"`
+}
+
+type NetWorthItem struct {
+	ID             uuid.UUID     `json:"id"`
+	Name           string        `json:"name" example:"Mortgage"`
+	Class          string        `json:"class" example:"mortgage"`
+	Liability      bool          `json:"liability" example:"true"`
+	Symbol         string        `json:"symbol,omitempty" example:"VWRL.AS"`
+	QuantityMicros int64         `json:"quantityMicros,omitempty" example:"12500000"`
+	Loan           *LoanSchedule `json:"loan,omitempty"`
+}
+
+type MarketDataImport struct {
+	// Format is the detected dialect, yahoo or stooq
+	Format string `json:"format" example:"yahoo"`
+	// Imported is the number of days stored, days that were stored before
+	// are overwritten
+	Imported int `json:"imported" example:"252"`
+	// Skipped is the number of rows without prices
+	Skipped int    `json:"skipped" example:"2"`
+	From    string `json:"from,omitempty" example:"2024-10-01"`
+	To      string `json:"to,omitempty" example:"2025-09-30"`
+}
+
+// MarketPrice is the price of a symbol on a day. Prices are decimal strings
+// so no precision is lost, the *Micros fields hold the same prices in
+// millionths of a unit.
+type MarketPrice struct {
+	Symbol      string `json:"symbol" example:"VWRL.AS"`
+	Date        string `json:"date" example:"2025-09-30"`
+	Open        string `json:"open" example:"118.42"`
+	High        string `json:"high" example:"119.10"`
+	Low         string `json:"low" example:"118.02"`
+	Close       string `json:"close" example:"118.96"`
+	CloseMicros int64  `json:"closeMicros" example:"118960000"`
+	Volume      int64  `json:"volume" example:"184213"`
+}
+
+type InvestmentImport struct {
+	Imported int `json:"imported" example:"42"`
+	// Duplicates are trades that were imported before
+	Duplicates int `json:"duplicates" example:"0"`
+	// Skipped are lines that are not trades, such as deposits and currency
+	// exchanges
+	Skipped int `json:"skipped" example:"17"`
+	// Securities are the securities seen for the first time, set their
+	// symbol to value them with market prices
+	Securities []Security `json:"securities"`
+}
+
+type Security struct {
+	ID       uuid.UUID `json:"id"`
+	ISIN     string    `json:"isin" example:"IE00B3RBWM25"`
+	Symbol   string    `json:"symbol" example:"VWRL.AS"`
+	Name     string    `json:"name" example:"VANGUARD FTSE AW"`
+	Currency string    `json:"currency" example:"EUR"`
+}
+
+type InvestmentTrade struct {
+	ID         uuid.UUID  `json:"id"`
+	Account    string     `json:"account" example:"DEGIRO"`
+	SecurityID *uuid.UUID `json:"securityId,omitempty"`
+	Date       time.Time  `json:"date"`
+	// Type is buy, sell, dividend, fee or split
+	Type           string `json:"type" example:"buy"`
+	QuantityMicros int64  `json:"quantityMicros" example:"10000000"`
+	Price          string `json:"price" example:"98.50"`
+	// AmountCents is the change of cash without fees, negative for buys
+	AmountCents int64  `json:"amountCents" example:"-98500"`
+	FeeCents    int64  `json:"feeCents" example:"200"`
+	Currency    string `json:"currency" example:"EUR"`
+	// RatioFrom shares became RatioTo shares in a split
+	RatioFrom   int64  `json:"ratioFrom,omitempty"`
+	RatioTo     int64  `json:"ratioTo,omitempty"`
+	Description string `json:"description" example:"Buy 10 VANGUARD FTSE AW@98.5 EUR (IE00B3RBWM25)"`
+}
+
+type Holdings struct {
+	Date   string `json:"date" example:"2025-09-30"`
+	Method string `json:"method" example:"fifo"`
+	// Holdings include securities that were sold entirely for their
+	// realised gains and dividends
+	Holdings []Holding `json:"holdings"`
+}
+
+type Holding struct {
+	Security       Security `json:"security"`
+	QuantityMicros int64    `json:"quantityMicros" example:"10000000"`
+	Price          string   `json:"price" example:"118.96"`
+	PriceDate      string   `json:"priceDate,omitempty" example:"2025-09-30"`
+	// MarketPrice is false when the holding is valued at its last trade
+	MarketPrice     bool  `json:"marketPrice" example:"true"`
+	ValueCents      int64 `json:"valueCents" example:"118960"`
+	CostCents       int64 `json:"costCents" example:"98700"`
+	UnrealisedCents int64 `json:"unrealisedCents" example:"20260"`
+	RealisedCents   int64 `json:"realisedCents" example:"0"`
+	DividendCents   int64 `json:"dividendCents" example:"1520"`
+	FeeCents        int64 `json:"feeCents" example:"200"`
+	// Incomplete is set when more shares were sold than bought, usually
+	// because earlier purchases were not imported
+	Incomplete bool  `json:"incomplete" example:"false"`
+	Lots       []Lot `json:"lots"`
+}
+
+type Lot struct {
+	Date           string `json:"date" example:"2024-01-02"`
+	QuantityMicros int64  `json:"quantityMicros" example:"10000000"`
+	CostCents      int64  `json:"costCents" example:"98700"`
+}
+
+// InvestmentPerformance is the result of the holdings in one currency.
+// Returns are fractions, 0.05 is 5%.
+type InvestmentPerformance struct {
+	Currency         string `json:"currency" example:"EUR"`
+	From             string `json:"from" example:"2025-01-01"`
+	To               string `json:"to" example:"2025-09-30"`
+	StartValueCents  int64  `json:"startValueCents" example:"1000000"`
+	EndValueCents    int64  `json:"endValueCents" example:"1150000"`
+	NetInvestedCents int64  `json:"netInvestedCents" example:"100000"`
+	RealisedCents    int64  `json:"realisedCents" example:"2500"`
+	// UnrealisedCents includes gains from before the period
+	UnrealisedCents int64 `json:"unrealisedCents" example:"180000"`
+	DividendCents   int64 `json:"dividendCents" example:"4200"`
+	FeeCents        int64 `json:"feeCents" example:"600"`
+	// TimeWeighted is the compounded return over the period, independent
+	// of when money was added
+	TimeWeighted float64 `json:"timeWeighted" example:"0.0452"`
+	// TimeWeightedAnnual is only set for periods of at least a year
+	TimeWeightedAnnual *float64 `json:"timeWeightedAnnual,omitempty"`
+	// MoneyWeighted is the yearly internal rate of return of the money
+	// put in
+	MoneyWeighted *float64 `json:"moneyWeighted,omitempty" example:"0.061"`
+}
+
+type FXImport struct {
+	// Imported is the number of rates stored, rates that were stored before
+	// are overwritten
+	Imported int `json:"imported" example:"7560"`
+	// Skipped is the number of rates the ECB did not publish (N/A)
+	Skipped    int      `json:"skipped" example:"12"`
+	Currencies []string `json:"currencies" example:"USD,GBP,CHF"`
+	From       string   `json:"from,omitempty" example:"2024-01-02"`
+	To         string   `json:"to,omitempty" example:"2025-09-30"`
+}
+
+// FXRate is the ECB reference rate of a currency on a day, the amount of the
+// currency one euro buys. Rates are decimal strings so no precision is lost,
+// RateMicros holds the same rate in millionths.
+type FXRate struct {
+	Currency   string `json:"currency" example:"USD"`
+	Date       string `json:"date" example:"2025-09-30"`
+	Rate       string `json:"rate" example:"1.1741"`
+	RateMicros int64  `json:"rateMicros" example:"1174100"`
 }
@@ -0,0 +1,137 @@
+// Command checkamounts reports the imported transactions whose amount was
+// stored a cent short. Amounts used to be parsed into a float and truncated
+// to cents, so 0.29 became 28 cents. It parses the stored statement of every
+// completed import again and compares the amounts row by row, the
+// transactions are not changed.
+//
+// It exits with status 1 when affected rows were found.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/config"
+	"github.com/lennardclaproth/my-finances-tracker/internal/importer"
+	"github.com/lennardclaproth/my-finances-tracker/internal/parser"
+	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+type mismatch struct {
+	tx       *transaction.Transaction
+	expected int64
+	reason   string
+}
+
+func run(ctx context.Context) (int, error) {
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load config: %w", err)
+	}
+	dbType := storage.Postgres
+	if cfg.Database.Type == "sqlite3" {
+		dbType = storage.Sqlite
+	}
+	db := storage.NewDB(cfg.Database.ConnStr, dbType)
+	defer db.Close()
+
+	imports := storage.NewSQLXImportStore(db)
+	vendors := storage.NewSQLXVendorStore(db)
+	transactions := storage.NewSQLXTransactionStore(db)
+
+	imps, err := imports.FetchCompleted(ctx)
+	if err != nil {
+		return 0, err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "IMPORT\tROW\tTRANSACTION\tDATE\tSTORED\tEXPECTED\tREASON\tDESCRIPTION")
+	affected, checked, skipped := 0, 0, 0
+	for _, imp := range imps {
+		mismatches, n, err := check(ctx, imp, vendors, transactions)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "skipping import %s: %v\n", imp.ID, err)
+			skipped++
+			continue
+		}
+		checked += n
+		for _, m := range mismatches {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%d\t%d\t%s\t%s\n",
+				imp.ID, m.tx.RowNumber, m.tx.ID, m.tx.Date.Format(time.DateOnly),
+				m.tx.AmountCents, m.expected, m.reason, m.tx.Description)
+			if m.reason == "truncated" {
+				affected++
+			}
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return 0, err
+	}
+	fmt.Printf("\nchecked %d transactions of %d imports, %d truncated, %d imports skipped\n", checked, len(imps)-skipped, affected, skipped)
+	return affected, nil
+}
+
+// check parses the statement of the import and returns the transactions
+// whose stored amount differs from the statement, and the number of
+// transactions checked.
+func check(ctx context.Context, imp *importer.Import, vendors *storage.SQLXVendorStore, transactions *storage.SQLXTransactionStore) ([]mismatch, int, error) {
+	v, err := vendors.FetchById(ctx, imp.VendorID)
+	if err != nil {
+		return nil, 0, err
+	}
+	p, err := parser.CreateCsvParser(v.Name)
+	if err != nil {
+		return nil, 0, err
+	}
+	f, err := os.Open(imp.Path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+	txds, err := p.ParseAll(f)
+	if err != nil {
+		return nil, 0, err
+	}
+	expected := map[int]int64{}
+	for row, txd := range txds {
+		expected[row] = txd.Amount.Cents
+	}
+	txs, err := transactions.FetchByImport(ctx, imp.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+	var mismatches []mismatch
+	for _, tx := range txs {
+		want, ok := expected[tx.RowNumber]
+		if !ok || tx.ParentID != nil || tx.AmountCents == want {
+			continue
+		}
+		reason := "differs"
+		if tx.AmountCents == legacyCents(want) {
+			reason = "truncated"
+		}
+		mismatches = append(mismatches, mismatch{tx: tx, expected: want, reason: reason})
+	}
+	return mismatches, len(txs), nil
+}
+
+// legacyCents returns the cents the float parsing stored for an amount,
+// parsing the decimal yields the float closest to cents/100 as does the
+// division.
+func legacyCents(cents int64) int64 {
+	return int64(float64(cents) / 100 * 100)
+}
+
+func main() {
+	affected, err := run(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(2)
+	}
+	if affected > 0 {
+		os.Exit(1)
+	}
+}
@@ -5,19 +5,32 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/google/uuid"
 	_ "github.com/lennardclaproth/my-finances-tracker/docs"
 	"github.com/lennardclaproth/my-finances-tracker/internal/agent"
+	"github.com/lennardclaproth/my-finances-tracker/internal/balance"
 	"github.com/lennardclaproth/my-finances-tracker/internal/bootstrap"
+	"github.com/lennardclaproth/my-finances-tracker/internal/budget"
+	"github.com/lennardclaproth/my-finances-tracker/internal/classifier"
 	"github.com/lennardclaproth/my-finances-tracker/internal/config"
+	"github.com/lennardclaproth/my-finances-tracker/internal/counterparty"
+	"github.com/lennardclaproth/my-finances-tracker/internal/fx"
 	"github.com/lennardclaproth/my-finances-tracker/internal/http"
 	handlers "github.com/lennardclaproth/my-finances-tracker/internal/http/handlers"
 	"github.com/lennardclaproth/my-finances-tracker/internal/jobs"
+	"github.com/lennardclaproth/my-finances-tracker/internal/learning"
 	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+	"github.com/lennardclaproth/my-finances-tracker/internal/marketdata"
+	"github.com/lennardclaproth/my-finances-tracker/internal/networth"
+	"github.com/lennardclaproth/my-finances-tracker/internal/notify"
+	"github.com/lennardclaproth/my-finances-tracker/internal/recurring"
+	"github.com/lennardclaproth/my-finances-tracker/internal/refund"
 	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
+	"github.com/lennardclaproth/my-finances-tracker/internal/tagging"
 	"github.com/lennardclaproth/my-finances-tracker/migrations"
 	httpSwagger "github.com/swaggo/http-swagger"
 	"golang.org/x/sync/errgroup"
@@ -40,12 +53,15 @@ func run(ctx context.Context, args []string) error {
 	// Bootstrap initial data
 	bootstrapData(ctx, db, logger)
 
+	// The breaker is shared by the agent client, the tagger and the health check
+	breaker := agent.NewBreaker(cfg.Agent.Breaker.FailureThreshold, cfg.Agent.Breaker.Cooldown)
+
 	// Wiring: construct handlers and routes at the composition root
-	router := setupRouter(logger, db)
+	router := setupRouter(logger, db, cfg, breaker)
 
 	// Create server and job manager
 	srv := http.NewServer(fmt.Sprintf(":%d", cfg.Server.Port), router, logger)
-	jobMgr := setupJobs(logger, db, cfg)
+	jobMgr := setupJobs(logger, db, cfg, breaker)
 
 	// Run server and jobs concurrently with proper cleanup
 	g, ctx := errgroup.WithContext(ctx)
@@ -104,15 +120,40 @@ func setupDatabase(log logging.Logger, cfg *config.Config) *storage.DB {
 
 // setupRouter constructs all handlers and registers them with the router.
 // This is the composition root where all dependencies are wired together.
-func setupRouter(log logging.Logger, db *storage.DB) *http.Router {
+func setupRouter(log logging.Logger, db *storage.DB, cfg *config.Config, breaker *agent.Breaker) *http.Router {
 	router := http.NewRouter()
 
 	var transactionRepository = storage.NewSQLXTransactionStore(db)
 	var importRepository = storage.NewSQLXImportStore(db)
 	var vendorRepository = storage.NewSQLXVendorStore(db)
+	var refundRepository = storage.NewSQLXRefundStore(db)
+	var counterpartyRepository = storage.NewSQLXCounterpartyStore(db)
+	var ruleRepository = storage.NewSQLXRuleStore(db)
+	var suggestionRepository = storage.NewSQLXSuggestionStore(db)
+	var categoryRepository = storage.NewSQLXCategoryStore(db)
+	var labelRepository = storage.NewSQLXLabelStore(db)
+	var bulkRepository = storage.NewSQLXBulkStore(db)
+	var reportRepository = storage.NewSQLXReportStore(db)
+	var budgetRepository = storage.NewSQLXBudgetStore(db)
+	var recurringRepository = storage.NewSQLXRecurringStore(db)
+	var balanceRepository = storage.NewSQLXBalanceStore(db)
+	var netWorthRepository = storage.NewSQLXNetWorthStore(db)
+	var marketHistoryRepository = storage.NewSQLXMarketHistoryStore(db)
+	var investmentRepository = storage.NewSQLXInvestmentStore(db)
+	var fxRepository = storage.NewSQLXFXStore(db)
+
+	var currency = reportingCurrency(log, cfg)
 
 	var diskWriter = storage.NewDisk("./data/uploads")
 
+	var learner = learning.NewRecordHandler(
+		suggestionRepository,
+		suggestionRepository,
+		suggestionRepository,
+		ruleRepository,
+		cfg.Learning.AutoCreateAfter,
+	)
+
 	// Register routes with their handlers
 	router.HandleWithMiddleware(
 		"POST /import/csv",
@@ -126,42 +167,591 @@ func setupRouter(log logging.Logger, db *storage.DB) *http.Router {
 	)
 	router.HandleWithMiddleware(
 		"POST /transaction/tag",
-		handlers.TagTransaction(log, transactionRepository),
+		handlers.TagTransaction(
+			log,
+			transactionRepository,
+			categoryRepository,
+			learner,
+		),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"GET /transactions",
+		handlers.ListTransactions(log, transactionRepository, labelRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"POST /transactions",
+		handlers.CreateTransaction(log, transactionRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"POST /transactions/bulk",
+		handlers.BulkTransactions(log, bulkRepository, transactionRepository, labelRepository, categoryRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"POST /transactions/bulk/{batchId}/undo",
+		handlers.UndoBulkTransactions(log, bulkRepository, transactionRepository, labelRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"POST /transactions/labels",
+		handlers.BulkLabelTransactions(log, labelRepository, transactionRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"POST /transactions/{id}/labels",
+		handlers.LabelTransaction(log, labelRepository, transactionRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"DELETE /transactions/{id}/labels/{label}",
+		handlers.UnlabelTransaction(log, labelRepository, transactionRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"GET /labels",
+		handlers.ListLabels(log, labelRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"GET /labels/totals",
+		handlers.LabelTotals(log, labelRepository, currency),
+		http.WithRequestLogging(log),
+	)
+
+	router.HandleWithMiddleware(
+		"GET /refunds",
+		handlers.ListRefundLinks(log, refundRepository, transactionRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"POST /refunds/{id}/confirm",
+		handlers.ConfirmRefundLink(log, refundRepository, transactionRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"POST /refunds/{id}/reject",
+		handlers.RejectRefundLink(log, refundRepository, transactionRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"GET /counterparties",
+		handlers.ListCounterparties(log, counterpartyRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"GET /counterparties/{id}",
+		handlers.GetCounterparty(log, counterpartyRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"PATCH /counterparties/{id}",
+		handlers.UpdateCounterparty(log, counterpartyRepository, categoryRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"POST /counterparties/{id}/merge",
+		handlers.MergeCounterparties(log, counterpartyRepository, transactionRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"POST /counterparties/{id}/split",
+		handlers.SplitCounterparty(log, counterpartyRepository, transactionRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"GET /rules",
+		handlers.ListRules(log, ruleRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"POST /rules",
+		handlers.CreateRule(log, ruleRepository, categoryRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"GET /rules/suggestions",
+		handlers.ListRuleSuggestions(log, suggestionRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"POST /rules/suggestions/{id}/accept",
+		handlers.AcceptRuleSuggestion(log, suggestionRepository, ruleRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"POST /rules/suggestions/{id}/dismiss",
+		handlers.DismissRuleSuggestion(log, suggestionRepository, ruleRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"GET /rules/{id}",
+		handlers.GetRule(log, ruleRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"PUT /rules/{id}",
+		handlers.UpdateRule(log, ruleRepository, categoryRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"DELETE /rules/{id}",
+		handlers.DeleteRule(log, ruleRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"POST /rules/{id}/apply",
+		handlers.ApplyRule(log, ruleRepository, transactionRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"GET /categories",
+		handlers.ListCategories(log, categoryRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"POST /categories",
+		handlers.CreateCategory(log, categoryRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"GET /categories/totals",
+		handlers.CategoryTotals(log, categoryRepository, currency),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"POST /categories/reorder",
+		handlers.ReorderCategories(log, categoryRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"PATCH /categories/{id}",
+		handlers.UpdateCategory(log, categoryRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"DELETE /categories/{id}",
+		handlers.DeleteCategory(log, categoryRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"POST /categories/{id}/merge",
+		handlers.MergeCategories(log, categoryRepository),
+		http.WithRequestLogging(log),
+	)
+
+	router.HandleWithMiddleware(
+		"GET /review/tags",
+		handlers.ListTagReviews(log, transactionRepository, labelRepository, cfg.Tagging.ReviewBelow),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"POST /review/tags/{id}",
+		handlers.ReviewTag(log, transactionRepository, categoryRepository, learner),
+		http.WithRequestLogging(log),
+	)
+
+	router.HandleWithMiddleware(
+		"GET /reports/cashflow",
+		handlers.Cashflow(log, reportRepository, currency),
+		http.WithRequestLogging(log),
+	)
+
+	router.HandleWithMiddleware(
+		"GET /budgets",
+		handlers.ListBudgets(log, budgetRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"POST /budgets",
+		handlers.CreateBudget(log, budgetRepository, categoryRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"GET /budgets/status",
+		handlers.BudgetStatus(log, budgetRepository, categoryRepository, reportRepository, currency),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"PATCH /budgets/{id}",
+		handlers.UpdateBudget(log, budgetRepository, categoryRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"DELETE /budgets/{id}",
+		handlers.DeleteBudget(log, budgetRepository),
+		http.WithRequestLogging(log),
+	)
+
+	router.HandleWithMiddleware(
+		"GET /recurring",
+		handlers.ListRecurring(log, recurringRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"GET /recurring/costs",
+		handlers.RecurringCosts(log, recurringRepository, fxRepository, currency),
+		http.WithRequestLogging(log),
+	)
+
+	router.HandleWithMiddleware(
+		"GET /forecast",
+		handlers.Forecast(log, transactionRepository, recurringRepository, balanceRepository, fxRepository, currency),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"POST /forecast",
+		handlers.ForecastWhatIf(log, transactionRepository, recurringRepository, balanceRepository, fxRepository, currency),
+		http.WithRequestLogging(log),
+	)
+
+	router.HandleWithMiddleware(
+		"GET /accounts",
+		handlers.ListAccounts(log, transactionRepository, balanceRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"PATCH /accounts/{id}",
+		handlers.UpdateAccount(log, transactionRepository, balanceRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"GET /accounts/{id}/balances",
+		handlers.AccountBalances(log, transactionRepository, balanceRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"GET /accounts/{id}/anchors",
+		handlers.ListBalanceAnchors(log, balanceRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"PUT /accounts/{id}/anchors/{date}",
+		handlers.SetBalanceAnchor(log, balanceRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"DELETE /accounts/{id}/anchors/{date}",
+		handlers.DeleteBalanceAnchor(log, balanceRepository),
+		http.WithRequestLogging(log),
+	)
+
+	router.HandleWithMiddleware(
+		"GET /networth",
+		handlers.NetWorth(log, transactionRepository, balanceRepository, netWorthRepository, marketHistoryRepository, fxRepository, currency),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"POST /networth/snapshots/confirm",
+		handlers.ConfirmNetWorthSnapshots(log, netWorthRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"GET /networth/items",
+		handlers.ListNetWorthItems(log, netWorthRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"POST /networth/items",
+		handlers.CreateNetWorthItem(log, netWorthRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"DELETE /networth/items/{id}",
+		handlers.DeleteNetWorthItem(log, netWorthRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"PUT /networth/items/{id}/valuations/{date}",
+		handlers.SetNetWorthValuation(log, netWorthRepository),
+		http.WithRequestLogging(log),
+	)
+
+	router.HandleWithMiddleware(
+		"POST /marketdata/import",
+		handlers.ImportMarketData(log, marketHistoryRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"GET /marketdata/{symbol}",
+		handlers.MarketHistory(log, marketHistoryRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"GET /marketdata/{symbol}/latest",
+		handlers.LatestMarketPrice(log, marketHistoryRepository),
+		http.WithRequestLogging(log),
+	)
+
+	router.HandleWithMiddleware(
+		"POST /fx/rates/import",
+		handlers.ImportFXRates(log, fxRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"GET /fx/rates",
+		handlers.ListFXRates(log, fxRepository),
+		http.WithRequestLogging(log),
+	)
+
+	router.HandleWithMiddleware(
+		"POST /investments/import",
+		handlers.ImportInvestments(log, investmentRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"GET /investments/securities",
+		handlers.ListSecurities(log, investmentRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"PATCH /investments/securities/{id}",
+		handlers.UpdateSecurity(log, investmentRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"GET /investments/trades",
+		handlers.ListInvestmentTrades(log, investmentRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"GET /investments/holdings",
+		handlers.Holdings(log, investmentRepository, marketHistoryRepository),
+		http.WithRequestLogging(log),
+	)
+	router.HandleWithMiddleware(
+		"GET /investments/performance",
+		handlers.InvestmentPerformance(log, investmentRepository, marketHistoryRepository),
 		http.WithRequestLogging(log),
 	)
 
 	router.Handle("GET /swagger/", httpSwagger.WrapHandler)
-	router.Handle("GET /health", handlers.HealthHandler())
+	router.Handle("GET /health", handlers.HealthHandler(breaker))
 
 	return router
 }
 
-func setupJobs(log logging.Logger, db *storage.DB, cfg *config.Config) *jobs.Manager {
+func setupJobs(log logging.Logger, db *storage.DB, cfg *config.Config, breaker *agent.Breaker) *jobs.Manager {
 	// Setup and start background jobs here
+	currency := reportingCurrency(log, cfg)
 	importJob := jobs.NewImportJob(
 		storage.NewSQLXVendorStore(db),
 		storage.NewSQLXImportStore(db),
 		storage.NewSQLXTransactionStore(db),
+		storage.NewSQLXRuleStore(db),
 		storage.NewDisk(cfg.DiskStorage.BasePath+"/import"),
+		counterparty.NewAssignHandler(
+			storage.NewSQLXCounterpartyStore(db),
+			storage.NewSQLXCounterpartyStore(db),
+			storage.NewSQLXCounterpartyStore(db),
+		),
+		refund.NewDetectHandler(
+			storage.NewSQLXTransactionStore(db),
+			storage.NewSQLXRefundStore(db),
+			storage.NewSQLXRefundStore(db),
+			cfg.Refunds.WindowDays,
+		),
 		log,
 		5*time.Second,
 	)
 
-	var agentID uuid.UUID
-	agentID, err := uuid.Parse(cfg.Agent.DefaultTagAgentID)
-	if err != nil {
-		agentID = uuid.Nil
-	}
+	classifierService := classifier.NewService(
+		storage.NewSQLXTransactionStore(db),
+		cfg.Classifier.MinSamples,
+	)
 	taggerJob := jobs.NewTaggerJob(
-		agent.NewRunner(
-			cfg.Agent.AgentBaseURL,
-			agentID,
-		),
+		setupTagger(log, db, cfg, classifierService, breaker),
+		breaker,
 		storage.NewSQLXTransactionStore(db),
+		storage.NewSQLXCategoryStore(db),
+		cfg.Agent.BatchSize,
+		cfg.Agent.Concurrency,
+		cfg.Agent.ClaimTimeout,
+		cfg.Tagging.RetryUncategorisedAfter,
 		100*time.Millisecond,
 		log,
 	)
-	return jobs.NewManager(log, importJob, taggerJob)
+	classifierJob := jobs.NewClassifierJob(classifierService, cfg.Classifier.RetrainInterval, log)
+	budgetStore := storage.NewSQLXBudgetStore(db)
+	budgetAlertJob := jobs.NewBudgetAlertJob(
+		budget.NewAlertHandler(
+			budgetStore,
+			budget.NewStatusHandler(budgetStore, storage.NewSQLXCategoryStore(db), storage.NewSQLXReportStore(db), currency),
+			budgetStore,
+			setupNotifier(log, cfg),
+			cfg.Budgets.AlertThresholds...,
+		),
+		storage.NewSQLXTransactionStore(db),
+		cfg.Budgets.CheckInterval,
+		log,
+	)
+	recurringJob := jobs.NewRecurringJob(
+		recurring.NewDetectHandler(
+			storage.NewSQLXTransactionStore(db),
+			storage.NewSQLXCounterpartyStore(db),
+			storage.NewSQLXRecurringStore(db),
+			cfg.Recurring.LookbackMonths,
+			cfg.Recurring.Tolerance,
+		),
+		cfg.Recurring.Interval,
+		log,
+	)
+	netWorthJob := jobs.NewNetWorthSnapshotJob(
+		networth.NewSnapshotHandler(
+			storage.NewSQLXNetWorthStore(db),
+			storage.NewSQLXNetWorthStore(db),
+			networth.NewValueHandler(
+				storage.NewSQLXBalanceStore(db),
+				balance.NewReconstructHandler(
+					storage.NewSQLXTransactionStore(db),
+					storage.NewSQLXBalanceStore(db),
+					storage.NewSQLXBalanceStore(db),
+				),
+				storage.NewSQLXNetWorthStore(db),
+				storage.NewSQLXNetWorthStore(db),
+				storage.NewSQLXMarketHistoryStore(db),
+				storage.NewSQLXFXStore(db),
+				currency,
+			),
+			cfg.NetWorth.BackfillMonths,
+		),
+		cfg.NetWorth.SnapshotInterval,
+		log,
+	)
+
+	all := []jobs.Job{importJob, taggerJob, classifierJob, budgetAlertJob, recurringJob, netWorthJob}
+	if provider := setupPriceProvider(log, cfg); provider != nil {
+		at, err := time.Parse("15:04", cfg.MarketData.RefreshAt)
+		if err != nil && cfg.MarketData.RefreshAt != "" {
+			log.Error(context.Background(), "invalid market data refresh time, using the default", err, "refresh_at", cfg.MarketData.RefreshAt)
+		}
+		marketDataJob := jobs.NewMarketDataJob(
+			marketdata.NewRefreshHandler(
+				provider,
+				storage.NewSQLXMarketHistoryStore(db),
+				storage.NewSQLXMarketHistoryStore(db),
+				storage.NewSQLXMarketHistoryStore(db),
+				cfg.MarketData.Symbols,
+				cfg.MarketData.BackfillDays,
+			),
+			at.Sub(time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC)),
+			log,
+		)
+		all = append(all, marketDataJob)
+	}
+	if cfg.FX.RatesDir != "" {
+		fxRateJob := jobs.NewFXRateJob(
+			fx.NewScanHandler(fx.NewImportHandler(storage.NewSQLXFXStore(db)), cfg.FX.RatesDir),
+			cfg.FX.ScanInterval,
+			log,
+		)
+		all = append(all, fxRateJob)
+	}
+
+	return jobs.NewManager(log, all...)
+}
+
+// reportingCurrency returns the configured reporting currency, amounts in
+// other currencies are converted to it. It falls back to euros when the
+// currency is not set or not a valid code.
+func reportingCurrency(log logging.Logger, cfg *config.Config) string {
+	if cfg.FX.ReportingCurrency == "" {
+		return fx.Base
+	}
+	currency, err := fx.NormaliseCurrency(cfg.FX.ReportingCurrency)
+	if err != nil {
+		log.Error(context.Background(), "invalid reporting currency, using the default", err, "reporting_currency", cfg.FX.ReportingCurrency)
+		return fx.Base
+	}
+	return currency
+}
+
+// setupPriceProvider returns the configured market data provider, rate
+// limited, or nil when prices are only imported by hand.
+func setupPriceProvider(log logging.Logger, cfg *config.Config) marketdata.PriceProvider {
+	m := cfg.MarketData
+	switch m.Provider {
+	case "":
+		return nil
+	case "http":
+		return marketdata.NewLimited(marketdata.NewHTTPProvider(m.URL, m.Headers, m.Timeout), m.RequestsPerMinute)
+	case "file":
+		return marketdata.NewFileProvider(m.Dir)
+	default:
+		log.Error(context.Background(), "skipping market data refresh", fmt.Errorf("unknown market data provider %q", m.Provider))
+		return nil
+	}
+}
+
+// setupNotifier logs notifications and posts them to the webhook when one is
+// configured.
+func setupNotifier(log logging.Logger, cfg *config.Config) notify.Notifier {
+	notifiers := notify.Multi{notify.NewLogNotifier(log)}
+	if cfg.Notifications.WebhookURL != "" {
+		notifiers = append(notifiers, notify.NewWebhookNotifier(cfg.Notifications.WebhookURL, cfg.Notifications.Timeout))
+	}
+	return notifiers
+}
+
+// setupTagger chains the taggers listed in the config, unknown names are
+// logged and skipped. Remote taggers are rate limited.
+func setupTagger(log logging.Logger, db *storage.DB, cfg *config.Config, classifierService *classifier.Service, breaker *agent.Breaker) tagging.Tagger {
+	var agentID uuid.UUID
+	agentID, err := uuid.Parse(cfg.Agent.DefaultTagAgentID)
+	if err != nil {
+		agentID = uuid.Nil
+	}
+	tmpl, err := tagging.LoadTemplate(cfg.Tagging.PromptTemplate)
+	if err != nil {
+		log.Error(context.Background(), "failed to load prompt template", err)
+		panic(err)
+	}
+	prompter := tagging.NewPrompter(tmpl, storage.NewSQLXTransactionStore(db), storage.NewSQLXCounterpartyStore(db), cfg.Tagging.Examples)
+	var routes []tagging.AgentRoute
+	for _, r := range cfg.Agent.Routes {
+		id, err := uuid.Parse(r.AgentID)
+		if err != nil {
+			log.Error(context.Background(), "skipping agent route", err, "agent_id", r.AgentID)
+			continue
+		}
+		routes = append(routes, tagging.AgentRoute{
+			AgentID:        id,
+			Account:        r.Account,
+			MinAmountCents: r.MinAmountCents,
+			MaxAmountCents: r.MaxAmountCents,
+		})
+	}
+	var taggers []tagging.Tagger
+	for _, name := range cfg.Tagging.Chain {
+		switch name {
+		case "rules":
+			taggers = append(taggers, tagging.NewRuleTagger(storage.NewSQLXRuleStore(db)))
+		case "classifier":
+			taggers = append(taggers, tagging.NewClassifierTagger(classifierService))
+		case "agent":
+			r := cfg.Agent.Retry
+			opts := []agent.Option{
+				agent.WithTimeout(cfg.Agent.Timeout),
+				agent.WithRetry(r.MaxAttempts, r.BaseDelay, r.MaxDelay),
+				agent.WithBreaker(breaker),
+			}
+			if strings.EqualFold(cfg.Agent.Method, "POST") {
+				opts = append(opts, agent.WithPost())
+			}
+			client := agent.NewClient(cfg.Agent.AgentBaseURL, opts...)
+			t := tagging.NewAgentTagger(client, prompter, agentID, routes...)
+			taggers = append(taggers, tagging.NewLimited(t, cfg.Agent.RequestsPerMinute))
+		case "openai":
+			o := cfg.Tagging.OpenAI
+			t := tagging.NewOpenAITagger(prompter, o.BaseURL, o.APIKey, o.Model, o.Timeout)
+			taggers = append(taggers, tagging.NewLimited(t, cfg.Agent.RequestsPerMinute))
+		default:
+			log.Error(context.Background(), "skipping tagger", tagging.ErrUnknownTagger, "tagger", name)
+		}
+	}
+	return tagging.NewChain(log, cfg.Tagging.MinConfidence, taggers...)
 }
 
 func bootstrapData(ctx context.Context, db *storage.DB, log logging.Logger) {
@@ -15,9 +15,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/health": {
+        "/accounts": {
             "get": {
-                "description": "Returns 200 when service is healthy",
+                "description": "List the accounts that have transactions or balance anchors with their balance at the end of today",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,12 +25,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Health"
+                    "Accounts"
                 ],
-                "summary": "Health check",
+                "summary": "List accounts",
                 "responses": {
                     "200": {
-                        "description": "status",
+                        "description": "Accounts",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.Account"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -41,44 +50,46 @@ const docTemplate = `{
                 }
             }
         },
-        "/import/csv": {
-            "post": {
-                "description": "Upload a CSV file containing transaction data to import into a specific vendor",
+        "/accounts/{id}": {
+            "patch": {
+                "description": "Change the name, the type (checking, savings, investment) or the ISO 4217 currency of an account. The type decides the asset class of the account in the net worth, the currency how its balance is converted to the reporting currency.",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "imports"
+                    "Accounts"
                 ],
-                "summary": "Import transactions from CSV file",
+                "summary": "Update an account",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "CSV file containing transaction data",
-                        "name": "file",
-                        "in": "formData",
+                        "type": "string",
+                        "description": "Account (usually its IBAN)",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "UUID of the vendor to import transactions for",
-                        "name": "vendor_id",
-                        "in": "formData",
-                        "required": true
+                        "description": "Fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.UpdateAccountRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Import ID of the created import job",
+                        "description": "Updated account",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/api.Account"
                         }
                     },
                     "400": {
-                        "description": "Invalid request (missing file, invalid vendor_id, etc.)",
+                        "description": "Bad request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -86,21 +97,47 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "413": {
-                        "description": "File too large (max 20MB)",
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    },
-                    "415": {
-                        "description": "Unsupported media type (only text/csv and application/vnd.ms-excel allowed)",
+                    }
+                }
+            }
+        },
+        "/accounts/{id}/anchors": {
+            "get": {
+                "description": "List the known closing balances of an account, oldest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "List balance anchors",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account (usually its IBAN)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Anchors",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.BalanceAnchor"
                             }
                         }
                     },
@@ -116,9 +153,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/transactions/tag": {
-            "post": {
-                "description": "Apply a tag to a transaction by id",
+        "/accounts/{id}/anchors/{date}": {
+            "put": {
+                "description": "Set the closing balance of an account at the end of a day, e.g. from a bank statement. Balances of other days are reconstructed from the nearest anchor. An existing anchor on the same day is replaced.",
                 "consumes": [
                     "application/json"
                 ],
@@ -126,19 +163,88 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Transactions"
+                    "Accounts"
                 ],
-                "summary": "Tag a transaction",
+                "summary": "Set a balance anchor",
                 "parameters": [
                     {
-                        "description": "Tag request",
-                        "name": "payload",
+                        "type": "string",
+                        "description": "Account (usually its IBAN)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Day (YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Closing balance",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.TagTransactionRequest"
+                            "$ref": "#/definitions/api.BalanceAnchorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Saved anchor",
+                        "schema": {
+                            "$ref": "#/definitions/api.BalanceAnchor"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
+                }
+            },
+            "delete": {
+                "description": "Delete the closing balance of an account on a day",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "Delete a balance anchor",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account (usually its IBAN)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Day (YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
                     "200": {
@@ -159,6 +265,15 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "404": {
+                        "description": "Anchor not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -171,9 +286,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/transactions/untagged": {
+        "/accounts/{id}/balances": {
             "get": {
-                "description": "Retrieve paginated untagged transactions",
+                "description": "Reconstruct the closing balance of every day from the balance anchors and the transactions. Days on which the balance reported by the bank statement differs from the reconstructed one are flagged, this usually means transactions are missing.",
                 "consumes": [
                     "application/json"
                 ],
@@ -181,32 +296,78 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Transactions"
+                    "Accounts"
                 ],
-                "summary": "Get untagged transactions",
+                "summary": "Daily balances",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Page number",
-                        "name": "page",
+                        "type": "string",
+                        "description": "Account (usually its IBAN)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First date (YYYY-MM-DD), defaults to 30 days before to",
+                        "name": "from",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "default": 20,
-                        "description": "Page size",
-                        "name": "page_size",
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD), defaults to today",
+                        "name": "to",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of transactions",
+                        "description": "Balance per day",
+                        "schema": {
+                            "$ref": "#/definitions/api.AccountBalances"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/budgets": {
+            "get": {
+                "description": "List the budgets of all categories",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Budgets"
+                ],
+                "summary": "List budgets",
+                "responses": {
+                    "200": {
+                        "description": "Budgets",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/api.Transaction"
+                                "$ref": "#/definitions/api.Budget"
                             }
                         }
                     },
@@ -220,44 +381,5674 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "api.TagTransactionRequest": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "type": "string"
-                },
-                "tag": {
-                    "type": "string"
+            },
+            "post": {
+                "description": "Create a budget for an expense category, spending in its subcategories counts towards it. Periods are calendar weeks, months, quarters or years.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Budgets"
+                ],
+                "summary": "Create a budget",
+                "parameters": [
+                    {
+                        "description": "Budget",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateBudgetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created budget",
+                        "schema": {
+                            "$ref": "#/definitions/api.Budget"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Category not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Category already has a budget for the period",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
                 }
             }
         },
-        "api.Transaction": {
-            "type": "object",
-            "properties": {
-                "amountCents": {
-                    "type": "integer",
-                    "example": 4250
-                },
-                "date": {
-                    "type": "string",
-                    "example": "2025-01-15T00:00:00Z"
-                },
-                "description": {
+        "/budgets/status": {
+            "get": {
+                "description": "Report the spending, remaining amount and projected spending by the end of the period for every budget. Amounts are in the reporting currency, spending in other currencies is converted with the rate of its date. Ignored transactions and transfers are left out, refunds lower the spending.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Budgets"
+                ],
+                "summary": "Budget status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Month (YYYY-MM) or day (YYYY-MM-DD) within the periods to report, defaults to today",
+                        "name": "period",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Status per budget",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.BudgetStatus"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/budgets/{id}": {
+            "delete": {
+                "description": "Delete a budget together with its sent alerts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Budgets"
+                ],
+                "summary": "Delete a budget",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Budget not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Change the category, amount, period, start date or rollover of a budget",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Budgets"
+                ],
+                "summary": "Update a budget",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Changes",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.UpdateBudgetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated budget",
+                        "schema": {
+                            "$ref": "#/definitions/api.Budget"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Budget or category not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Category already has a budget for the period",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/categories": {
+            "get": {
+                "description": "List the category tree, siblings are ordered by position",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "List categories",
+                "responses": {
+                    "200": {
+                        "description": "Top level categories with their subcategories",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.Category"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a top level category or a subcategory, the slug used as transaction tag is derived from the name",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Create a category",
+                "parameters": [
+                    {
+                        "description": "Category",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateCategoryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created category",
+                        "schema": {
+                            "$ref": "#/definitions/api.Category"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Parent category not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Category already exists",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/categories/reorder": {
+            "post": {
+                "description": "Put sibling categories in the given order, siblings that are not listed follow in their current order",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Reorder categories",
+                "parameters": [
+                    {
+                        "description": "Sibling categories in their new order",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.ReorderCategoriesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Siblings in their new order",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.Category"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Category not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/categories/totals": {
+            "get": {
+                "description": "Sum incoming and outgoing amounts per category and roll them up to the parent categories. Amounts are converted to the reporting currency with the rate of the transaction date. Ignored transactions are left out, refunds count towards the category of the original purchase.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Category totals",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Totals per top level category",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.CategoryTotals"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/categories/{id}": {
+            "delete": {
+                "description": "Delete a category without subcategories, its transactions move to the parent category or to uncategorised",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Delete a category",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Category cannot be deleted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Category not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Rename, move, retype or restyle a category. Renaming changes the slug, transactions, rules and counterparties follow.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Update a category",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Changes",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.UpdateCategoryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated category",
+                        "schema": {
+                            "$ref": "#/definitions/api.Category"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Category not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Category already exists",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/categories/{id}/merge": {
+            "post": {
+                "description": "Merge the source categories into the target, the target takes over their transactions, rules and subcategories",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Merge categories",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Target category ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Categories to merge",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.MergeCategoriesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Merged category",
+                        "schema": {
+                            "$ref": "#/definitions/api.Category"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Category not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/counterparties": {
+            "get": {
+                "description": "List merchants and payees, optionally filtered by name",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Counterparties"
+                ],
+                "summary": "List counterparties",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Case insensitive search on the name",
+                        "name": "search",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Counterparties",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.Counterparty"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/counterparties/{id}": {
+            "get": {
+                "description": "Get a merchant or payee by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Counterparties"
+                ],
+                "summary": "Get a counterparty",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Counterparty ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Counterparty",
+                        "schema": {
+                            "$ref": "#/definitions/api.Counterparty"
+                        }
+                    },
+                    "404": {
+                        "description": "Counterparty not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update a counterparty, aliases and IBANs replace the existing lists when given",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Counterparties"
+                ],
+                "summary": "Update a counterparty",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Counterparty ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Changes",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.UpdateCounterpartyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated counterparty",
+                        "schema": {
+                            "$ref": "#/definitions/api.Counterparty"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Counterparty not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/counterparties/{id}/merge": {
+            "post": {
+                "description": "Merge the source counterparties into the target, the target takes over their aliases, IBANs and transactions",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Counterparties"
+                ],
+                "summary": "Merge counterparties",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Target counterparty ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Counterparties to merge",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.MergeCounterpartiesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Merged counterparty",
+                        "schema": {
+                            "$ref": "#/definitions/api.Counterparty"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Counterparty not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/counterparties/{id}/split": {
+            "post": {
+                "description": "Move aliases and IBANs, together with the matching transactions, to a new counterparty",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Counterparties"
+                ],
+                "summary": "Split a counterparty",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Counterparty ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Aliases and IBANs to split off",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.SplitCounterpartyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "New counterparty",
+                        "schema": {
+                            "$ref": "#/definitions/api.Counterparty"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Counterparty not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/forecast": {
+            "get": {
+                "description": "Project the daily balance from the current balance reconstructed from the balance anchors and transactions, the recurring incomes and expenses and the average spending per category outside them. An account is forecast in its own currency, all accounts together in the reporting currency. The low balance date is the first day the balance drops below the threshold.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Forecast"
+                ],
+                "summary": "Forecast the balance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account (usually its IBAN), all accounts when empty",
+                        "name": "account",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Days to project, defaults to 90",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Balance in cents below which to warn, defaults to 0",
+                        "name": "threshold",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Projected balance per day",
+                        "schema": {
+                            "$ref": "#/definitions/api.Forecast"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "No exchange rate for the currency of an account",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Same as GET /forecast, additionally projects the given what-if entries and optionally starts from a given balance.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Forecast"
+                ],
+                "summary": "Forecast the balance with what-if entries",
+                "parameters": [
+                    {
+                        "description": "Forecast request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.ForecastRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Projected balance per day",
+                        "schema": {
+                            "$ref": "#/definitions/api.Forecast"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "No exchange rate for the currency of an account",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/fx/rates": {
+            "get": {
+                "description": "List the ECB reference rates against the euro, oldest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exchange rates"
+                ],
+                "summary": "Exchange rates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ISO 4217 code, all currencies when empty",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "First date (YYYY-MM-DD), defaults to 30 days before to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD), defaults to today",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rates",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.FXRate"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/fx/rates/import": {
+            "post": {
+                "description": "Upload an ECB euro foreign exchange reference rate file: eurofxref.csv, eurofxref-hist.csv, eurofxref-daily.xml, eurofxref-hist.xml, eurofxref-hist-90d.xml or the ZIP archive holding one of them. The format is detected from the content, days that were imported before are overwritten.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exchange rates"
+                ],
+                "summary": "Import exchange rates",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "ECB reference rate file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Import result",
+                        "schema": {
+                            "$ref": "#/definitions/api.FXImport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Returns 200 when service is healthy, the status is degraded while the agent circuit breaker is open and tagging is paused",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "status",
+                        "schema": {
+                            "$ref": "#/definitions/api.Health"
+                        }
+                    }
+                }
+            }
+        },
+        "/import/csv": {
+            "post": {
+                "description": "Upload a CSV file containing transaction data to import into a specific vendor",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "imports"
+                ],
+                "summary": "Import transactions from CSV file",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV file containing transaction data",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Vendor of the statement: ING, Revolut, Wise, ABN AMRO or bunq",
+                        "name": "vendor_id",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Import ID of the created import job",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request (missing file, invalid vendor_id, etc.)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "File too large (max 20MB)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported media type (XLS and XLSX spreadsheets, such as the ABN AMRO XLS export, are rejected: upload the CSV or TXT export)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/investments/holdings": {
+            "get": {
+                "description": "Report the holdings at the end of a day with their value, cost basis and realised and unrealised gains. Holdings are valued at the latest market price of their symbol, or at their last trade when there is none. Amounts are in the currency of the security.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Investments"
+                ],
+                "summary": "Holdings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Day (YYYY-MM-DD), defaults to today",
+                        "name": "date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cost basis method (fifo, average), defaults to fifo",
+                        "name": "method",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Holdings",
+                        "schema": {
+                            "$ref": "#/definitions/api.Holdings"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/investments/import": {
+            "post": {
+                "description": "Upload a DEGIRO account statement (Account.csv, in English, Dutch or German). Buys, sells, dividends, dividend tax, fees and stock splits are imported, other lines such as deposits and currency exchanges are skipped. Lines imported before are skipped too, so overlapping statements can be uploaded.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Investments"
+                ],
+                "summary": "Import a broker statement",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "DEGIRO account statement",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Brokerage account the statement belongs to",
+                        "name": "account",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Import result",
+                        "schema": {
+                            "$ref": "#/definitions/api.InvestmentImport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/investments/performance": {
+            "get": {
+                "description": "Report the gains, dividends, fees and the time-weighted and money-weighted returns over a period, per currency. The time-weighted return chains the returns between days with trades, the money-weighted return is the yearly internal rate of return including the value at the start and the end of the period.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Investments"
+                ],
+                "summary": "Investment performance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First date (YYYY-MM-DD), defaults to a year before to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD), defaults to today",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cost basis method (fifo, average), defaults to fifo",
+                        "name": "method",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Performance per currency",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.InvestmentPerformance"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/investments/securities": {
+            "get": {
+                "description": "List the securities seen in broker statements",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Investments"
+                ],
+                "summary": "List securities",
+                "responses": {
+                    "200": {
+                        "description": "Securities",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.Security"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/investments/securities/{id}": {
+            "patch": {
+                "description": "Set the symbol the market prices of a security are stored under, or rename it. Securities without a symbol are valued at their last trade.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Investments"
+                ],
+                "summary": "Update a security",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Security ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.UpdateSecurityRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated security",
+                        "schema": {
+                            "$ref": "#/definitions/api.Security"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Security not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/investments/trades": {
+            "get": {
+                "description": "List the buys, sells, dividends, fees and splits, oldest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Investments"
+                ],
+                "summary": "List trades",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Security ID",
+                        "name": "security",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Brokerage account",
+                        "name": "account",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "First date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Trades",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.InvestmentTrade"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/labels": {
+            "get": {
+                "description": "List all labels with the number of transactions carrying them, most used first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Labels"
+                ],
+                "summary": "List labels",
+                "responses": {
+                    "200": {
+                        "description": "Labels",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.Label"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/labels/totals": {
+            "get": {
+                "description": "Sum incoming and outgoing amounts of the transactions carrying each label. Amounts are converted to the reporting currency with the rate of the transaction date. Ignored transactions are left out, refunds count towards the labels of the original purchase.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Labels"
+                ],
+                "summary": "Label totals",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Totals per label",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.LabelTotals"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/marketdata/import": {
+            "post": {
+                "description": "Upload a Yahoo Finance (Date,Open,High,Low,Close,Adj Close,Volume) or Stooq (Date,Open,High,Low,Close,Volume or \u003cTICKER\u003e,\u003cPER\u003e,\u003cDATE\u003e,...) CSV file. The format is detected from the header, days that were imported before are overwritten.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Market data"
+                ],
+                "summary": "Import market prices",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV file with daily prices",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Symbol the prices belong to, required unless the file names its ticker",
+                        "name": "symbol",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Import result",
+                        "schema": {
+                            "$ref": "#/definitions/api.MarketDataImport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/marketdata/{symbol}": {
+            "get": {
+                "description": "List the daily prices of a symbol, oldest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Market data"
+                ],
+                "summary": "Market prices",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Symbol, e.g. VWRL.AS",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First date (YYYY-MM-DD), defaults to a year before to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD), defaults to today",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Prices",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.MarketPrice"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/marketdata/{symbol}/latest": {
+            "get": {
+                "description": "Return the most recent daily price of a symbol",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Market data"
+                ],
+                "summary": "Latest market price",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Symbol, e.g. VWRL.AS",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Price",
+                        "schema": {
+                            "$ref": "#/definitions/api.MarketPrice"
+                        }
+                    },
+                    "404": {
+                        "description": "No prices for the symbol",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/networth": {
+            "get": {
+                "description": "Report assets, liabilities and net worth at the end of every interval, split per asset class, in the reporting currency. Accounts are valued at their reconstructed balance converted with the ECB rate of the day, manual items at their latest valuation, loans at their outstanding principal. Month ends with a snapshot are read from it so later corrections do not change the history, backfilled snapshots are provisional and recomputed until they are confirmed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Net worth"
+                ],
+                "summary": "Net worth",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First date (YYYY-MM-DD), defaults to a year before to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD), defaults to today",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Interval (week, month, quarter, year), defaults to month",
+                        "name": "interval",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Net worth per interval",
+                        "schema": {
+                            "$ref": "#/definitions/api.NetWorth"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "No exchange rate for the currency of an account",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/networth/items": {
+            "get": {
+                "description": "List the assets and liabilities entered by hand, e.g. a house, a mortgage or a car loan",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Net worth"
+                ],
+                "summary": "List net worth items",
+                "responses": {
+                    "200": {
+                        "description": "Items",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.NetWorthItem"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create an asset or liability. Liabilities with a loan schedule are valued at their outstanding principal, investments with a symbol at the quantity times the market price, other items at their latest valuation.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Net worth"
+                ],
+                "summary": "Create a net worth item",
+                "parameters": [
+                    {
+                        "description": "Item",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateNetWorthItemRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created item",
+                        "schema": {
+                            "$ref": "#/definitions/api.NetWorthItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/networth/items/{id}": {
+            "delete": {
+                "description": "Delete an item together with its valuations, snapshots taken earlier keep its value",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Net worth"
+                ],
+                "summary": "Delete a net worth item",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Item not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/networth/items/{id}/valuations/{date}": {
+            "put": {
+                "description": "Set the value of an item at the end of a day, e.g. the appraised value of a house. An existing valuation on the same day is replaced.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Net worth"
+                ],
+                "summary": "Set a valuation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Day (YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Value",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.NetWorthValuationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Item not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/networth/snapshots/confirm": {
+            "post": {
+                "description": "Make the provisional snapshots through a day final once the statements of their months have been imported, they are no longer recomputed afterwards",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Net worth"
+                ],
+                "summary": "Confirm net worth snapshots",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Last month end to confirm (YYYY-MM-DD), defaults to today",
+                        "name": "through",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Number of month ends confirmed",
+                        "schema": {
+                            "$ref": "#/definitions/api.ConfirmedSnapshots"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/recurring": {
+            "get": {
+                "description": "List the subscriptions, bills and income detected per counterparty with their expected next date and amount. Series are flagged on price increases and missed payments.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recurring"
+                ],
+                "summary": "List recurring transactions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Series status (active, missed, ended)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Direction (in, out)",
+                        "name": "direction",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Recurring series, next expected first",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.RecurringSeries"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/recurring/costs": {
+            "get": {
+                "description": "Sum the recurring payments and income that have not ended to yearly amounts in the reporting currency, the most expensive series first. Series in other currencies are converted with the ECB rate of today.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recurring"
+                ],
+                "summary": "Recurring costs",
+                "responses": {
+                    "200": {
+                        "description": "Yearly costs",
+                        "schema": {
+                            "$ref": "#/definitions/api.RecurringCosts"
+                        }
+                    },
+                    "409": {
+                        "description": "No exchange rate for the currency of a series",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/refunds": {
+            "get": {
+                "description": "List refund links between incoming refunds and the original outgoing transactions, optionally filtered by status",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Refunds"
+                ],
+                "summary": "List refund links",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Link status (suggested, confirmed, rejected)",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Refund links",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.RefundLink"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/refunds/{id}/confirm": {
+            "post": {
+                "description": "Confirm a suggested link, the refund is then netted against the category of the original transaction",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Refunds"
+                ],
+                "summary": "Confirm a refund link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Refund link ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Confirmed link",
+                        "schema": {
+                            "$ref": "#/definitions/api.RefundLink"
+                        }
+                    },
+                    "404": {
+                        "description": "Link not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Link is not a suggestion",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/refunds/{id}/reject": {
+            "post": {
+                "description": "Reject a suggested link, the same pair of transactions will not be suggested again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Refunds"
+                ],
+                "summary": "Reject a refund link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Refund link ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rejected link",
+                        "schema": {
+                            "$ref": "#/definitions/api.RefundLink"
+                        }
+                    },
+                    "404": {
+                        "description": "Link not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Link is not a suggestion",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/cashflow": {
+            "get": {
+                "description": "Sum income and expenses per week, month, quarter or year, optionally split per tag, account or counterparty. Amounts are converted to the reporting currency with the ECB rate of the transaction date, transactions in a currency without rates are counted as unconverted. Ignored transactions and transfers between own accounts are left out, refunds count towards the category of the original purchase. Send Accept: text/csv for CSV output.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Cash flow report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bucket length (week, month, quarter, year), defaults to month",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Split buckets per tag, account or counterparty",
+                        "name": "group_by",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cash flow per bucket",
+                        "schema": {
+                            "$ref": "#/definitions/api.CashflowReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/review/tags": {
+            "get": {
+                "description": "List transactions tagged by the classifier or an agent with a confidence below the threshold, or left uncategorised by them, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Review"
+                ],
+                "summary": "List tags to review",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "Confidence threshold, the configured one by default",
+                        "name": "below",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 50 by default",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Transactions to review",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/review/tags/{id}": {
+            "post": {
+                "description": "Accept the machine tag of a transaction or correct it, either way it becomes a manual tag. Corrections are learned from like manual re-tags.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Review"
+                ],
+                "summary": "Review a tag",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Review",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.ReviewTagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reviewed transaction",
+                        "schema": {
+                            "$ref": "#/definitions/api.Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Transaction not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Tag was not chosen by a machine tagger",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rules": {
+            "get": {
+                "description": "List all rules ordered by priority",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rules"
+                ],
+                "summary": "List rules",
+                "responses": {
+                    "200": {
+                        "description": "Rules",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.Rule"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a rule that is applied to transactions during imports, lower priorities are evaluated first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rules"
+                ],
+                "summary": "Create a rule",
+                "parameters": [
+                    {
+                        "description": "Rule",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.RuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created rule",
+                        "schema": {
+                            "$ref": "#/definitions/api.Rule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rules/suggestions": {
+            "get": {
+                "description": "List rules learned from manual tag corrections, most frequent corrections first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rules"
+                ],
+                "summary": "List rule suggestions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Suggestion status (pending, accepted, dismissed)",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Suggestions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.RuleSuggestion"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rules/suggestions/{id}/accept": {
+            "post": {
+                "description": "Create the rule proposed by a pending suggestion",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rules"
+                ],
+                "summary": "Accept a rule suggestion",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Suggestion ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Accepted suggestion",
+                        "schema": {
+                            "$ref": "#/definitions/api.RuleSuggestion"
+                        }
+                    },
+                    "404": {
+                        "description": "Suggestion not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Suggestion already handled",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rules/suggestions/{id}/dismiss": {
+            "post": {
+                "description": "Dismiss a pending suggestion, it will not be auto-created anymore",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rules"
+                ],
+                "summary": "Dismiss a rule suggestion",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Suggestion ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Dismissed suggestion",
+                        "schema": {
+                            "$ref": "#/definitions/api.RuleSuggestion"
+                        }
+                    },
+                    "404": {
+                        "description": "Suggestion not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Suggestion already handled",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rules/{id}": {
+            "get": {
+                "description": "Get a rule by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rules"
+                ],
+                "summary": "Get a rule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rule",
+                        "schema": {
+                            "$ref": "#/definitions/api.Rule"
+                        }
+                    },
+                    "404": {
+                        "description": "Rule not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the definition of a rule",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rules"
+                ],
+                "summary": "Update a rule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rule",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.RuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated rule",
+                        "schema": {
+                            "$ref": "#/definitions/api.Rule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Rule not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a rule, transactions it classified keep their values",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rules"
+                ],
+                "summary": "Delete a rule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Rule not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rules/{id}/apply": {
+            "post": {
+                "description": "Apply a rule to the stored transactions, with dry_run the transactions that would change are returned without writing anything",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rules"
+                ],
+                "summary": "Apply a rule to existing transactions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only report the changes",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only consider untagged transactions",
+                        "name": "only_untagged",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Changes",
+                        "schema": {
+                            "$ref": "#/definitions/api.ApplyRuleResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Rule not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions": {
+            "get": {
+                "description": "List transactions newest first, filtered by date range, category, direction, counterparty and labels",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transactions"
+                ],
+                "summary": "List transactions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Category slug",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "in or out",
+                        "name": "direction",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Counterparty ID",
+                        "name": "counterparty_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated labels the transactions must all carry",
+                        "name": "labels",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 50 by default",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Transactions",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Enter a transaction that is not on a bank statement, such as a cash payment. Manual entries can be deleted in bulk, imported transactions cannot.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transactions"
+                ],
+                "summary": "Create a manual transaction",
+                "parameters": [
+                    {
+                        "description": "Transaction",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateTransactionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created transaction",
+                        "schema": {
+                            "$ref": "#/definitions/api.Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/bulk": {
+            "post": {
+                "description": "Set or clear the category, set ignored, add or remove a label or delete manual entries for a list of transactions or all transactions matching a filter. The operation runs in a single database transaction and is recorded so it can be undone. With preview only the number of transactions that would change is returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transactions"
+                ],
+                "summary": "Bulk transaction operation",
+                "parameters": [
+                    {
+                        "description": "Selection and operation",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.BulkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Result",
+                        "schema": {
+                            "$ref": "#/definitions/api.BulkResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Transaction not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/bulk/{batchId}/undo": {
+            "post": {
+                "description": "Restore the transactions changed by a bulk operation to their previous state",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transactions"
+                ],
+                "summary": "Undo a bulk operation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Batch ID",
+                        "name": "batchId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Undone batch",
+                        "schema": {
+                            "$ref": "#/definitions/api.BulkResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Batch not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Batch already undone",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/labels": {
+            "post": {
+                "description": "Add and remove labels on multiple transactions at once",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Labels"
+                ],
+                "summary": "Label transactions in bulk",
+                "parameters": [
+                    {
+                        "description": "Transactions and labels",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.BulkLabelRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Transaction not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/tag": {
+            "post": {
+                "description": "Apply a category to a transaction by id, the tag is the slug of an existing category",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transactions"
+                ],
+                "summary": "Tag a transaction",
+                "parameters": [
+                    {
+                        "description": "Tag request",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.TagTransactionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Transaction not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/{id}/labels": {
+            "post": {
+                "description": "Add labels to a transaction, labels that do not exist yet are created",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Labels"
+                ],
+                "summary": "Label a transaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Labels to add",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.LabelTransactionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Transaction not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/{id}/labels/{label}": {
+            "delete": {
+                "description": "Remove a label from a transaction, removing a label the transaction does not carry is not an error",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Labels"
+                ],
+                "summary": "Remove a label from a transaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Label",
+                        "name": "label",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Transaction not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.Account": {
+            "type": "object",
+            "properties": {
+                "balanceCents": {
+                    "description": "BalanceCents is in the currency of the account",
+                    "type": "integer",
+                    "example": 125000
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "id": {
+                    "description": "ID identifies the account, usually its IBAN",
+                    "type": "string",
+                    "example": "NL91ABNA0417164300"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Joint account"
+                },
+                "type": {
+                    "description": "Type is checking, savings or investment",
+                    "type": "string",
+                    "example": "checking"
+                }
+            }
+        },
+        "api.AccountBalances": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string",
+                    "example": "NL91ABNA0417164300"
+                },
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.DailyBalance"
+                    }
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2025-09-01"
+                },
+                "mismatches": {
+                    "description": "Mismatches is the number of days the statement reports a different\nbalance than the reconstructed one",
+                    "type": "integer",
+                    "example": 1
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2025-09-30"
+                }
+            }
+        },
+        "api.ApplyRuleResult": {
+            "type": "object",
+            "properties": {
+                "affected": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.RuleChange"
+                    }
+                },
+                "dryRun": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "api.BalanceAnchor": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string",
+                    "example": "NL91ABNA0417164300"
+                },
+                "balanceCents": {
+                    "type": "integer",
+                    "example": 125000
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2025-09-30"
+                }
+            }
+        },
+        "api.BalanceAnchorRequest": {
+            "type": "object",
+            "properties": {
+                "balanceCents": {
+                    "description": "BalanceCents is the closing balance at the end of the day",
+                    "type": "integer",
+                    "example": 125000
+                }
+            }
+        },
+        "api.BoolChange": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "boolean"
+                },
+                "to": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "api.Budget": {
+            "type": "object",
+            "properties": {
+                "amountCents": {
+                    "type": "integer",
+                    "example": 40000
+                },
+                "categoryId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "period": {
+                    "type": "string",
+                    "example": "month"
+                },
+                "rollover": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "startDate": {
+                    "description": "StartDate is the first day of the first period of the budget",
+                    "type": "string",
+                    "example": "2025-01-01"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.BudgetStatus": {
+            "type": "object",
+            "properties": {
+                "amountCents": {
+                    "type": "integer",
+                    "example": 40000
+                },
+                "availableCents": {
+                    "type": "integer",
+                    "example": 42500
+                },
+                "budgetId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "category": {
+                    "type": "string",
+                    "example": "groceries"
+                },
+                "categoryName": {
+                    "type": "string",
+                    "example": "Groceries"
+                },
+                "percent": {
+                    "description": "Percent of the available amount that has been spent",
+                    "type": "number",
+                    "example": 72.9
+                },
+                "period": {
+                    "type": "string",
+                    "example": "month"
+                },
+                "periodEnd": {
+                    "type": "string",
+                    "example": "2025-01-31"
+                },
+                "periodStart": {
+                    "type": "string",
+                    "example": "2025-01-01"
+                },
+                "projectedCents": {
+                    "description": "ProjectedCents is the spending expected by the end of the period at\nthe current pace",
+                    "type": "integer",
+                    "example": 46500
+                },
+                "remainingCents": {
+                    "type": "integer",
+                    "example": 11500
+                },
+                "rolloverCents": {
+                    "description": "RolloverCents is the unused amount carried over from earlier periods",
+                    "type": "integer",
+                    "example": 2500
+                },
+                "spentCents": {
+                    "type": "integer",
+                    "example": 31000
+                }
+            }
+        },
+        "api.BulkFilter": {
+            "type": "object",
+            "properties": {
+                "counterpartyId": {
+                    "type": "string"
+                },
+                "direction": {
+                    "type": "string",
+                    "example": "out"
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2025-01-01"
+                },
+                "labels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tag": {
+                    "type": "string",
+                    "example": "uncategorised"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2025-12-31"
+                }
+            }
+        },
+        "api.BulkLabelRequest": {
+            "type": "object",
+            "properties": {
+                "add": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "tax-deductible"
+                    ]
+                },
+                "remove": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "reimbursable"
+                    ]
+                },
+                "transactionIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.BulkOperation": {
+            "type": "object",
+            "properties": {
+                "ignored": {
+                    "type": "boolean"
+                },
+                "label": {
+                    "type": "string",
+                    "example": "vacation-2025"
+                },
+                "tag": {
+                    "type": "string",
+                    "example": "groceries"
+                },
+                "type": {
+                    "description": "Type is one of set_tag, clear_tag, set_ignored, add_label, remove_label\nor delete_manual",
+                    "type": "string",
+                    "example": "set_tag"
+                }
+            }
+        },
+        "api.BulkRequest": {
+            "type": "object",
+            "properties": {
+                "filter": {
+                    "$ref": "#/definitions/api.BulkFilter"
+                },
+                "ids": {
+                    "description": "IDs of the transactions, when empty the filter selects them",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "operation": {
+                    "$ref": "#/definitions/api.BulkOperation"
+                },
+                "preview": {
+                    "description": "Preview only counts the transactions that would change",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "api.BulkResult": {
+            "type": "object",
+            "properties": {
+                "affected": {
+                    "type": "integer",
+                    "example": 312
+                },
+                "batchId": {
+                    "description": "BatchID identifies the batch to undo, it is empty for previews and\noperations that changed nothing",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "operation": {
+                    "$ref": "#/definitions/api.BulkOperation"
+                },
+                "preview": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "undoneAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.CashflowBucket": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "string",
+                    "example": "2025-01-31"
+                },
+                "expenseCents": {
+                    "type": "integer",
+                    "example": 45210
+                },
+                "group": {
+                    "description": "Group is the tag, account or counterparty name when the report is\ngrouped",
+                    "type": "string",
+                    "example": "groceries"
+                },
+                "incomeCents": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "netCents": {
+                    "type": "integer",
+                    "example": -45210
+                },
+                "start": {
+                    "description": "Start and End are the first and last day of the bucket",
+                    "type": "string",
+                    "example": "2025-01-01"
+                },
+                "unconverted": {
+                    "description": "Unconverted is the number of transactions left out because their\ncurrency has no exchange rate",
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "api.CashflowReport": {
+            "type": "object",
+            "properties": {
+                "buckets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.CashflowBucket"
+                    }
+                },
+                "currency": {
+                    "description": "Currency is the reporting currency the amounts are converted to",
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "groupBy": {
+                    "type": "string",
+                    "example": "tag"
+                },
+                "interval": {
+                    "type": "string",
+                    "example": "month"
+                }
+            }
+        },
+        "api.Category": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.Category"
+                    }
+                },
+                "colour": {
+                    "type": "string",
+                    "example": "#1e90ff"
+                },
+                "icon": {
+                    "type": "string",
+                    "example": "home"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Rent"
+                },
+                "parentId": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "rent"
+                },
+                "system": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "type": {
+                    "type": "string",
+                    "example": "expense"
+                }
+            }
+        },
+        "api.CategoryTotals": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.CategoryTotals"
+                    }
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "inCents": {
+                    "description": "Own totals of transactions in the category itself",
+                    "type": "integer",
+                    "example": 0
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Housing"
+                },
+                "outCents": {
+                    "type": "integer",
+                    "example": 120000
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "housing"
+                },
+                "totalInCents": {
+                    "description": "Totals including all subcategories",
+                    "type": "integer",
+                    "example": 0
+                },
+                "totalOutCents": {
+                    "type": "integer",
+                    "example": 150000
+                },
+                "type": {
+                    "type": "string",
+                    "example": "expense"
+                }
+            }
+        },
+        "api.ConfirmedSnapshots": {
+            "type": "object",
+            "properties": {
+                "confirmed": {
+                    "description": "Confirmed is the number of month ends whose snapshots became final",
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "api.Counterparty": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "AH",
+                        "ALBERT HEIJN"
+                    ]
+                },
+                "defaultTag": {
+                    "type": "string",
+                    "example": "groceries"
+                },
+                "ibans": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "NL91ABNA0417164300"
+                    ]
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "logoUrl": {
+                    "type": "string",
+                    "example": "https://example.com/ah.png"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Albert Heijn"
+                }
+            }
+        },
+        "api.CreateBudgetRequest": {
+            "type": "object",
+            "properties": {
+                "amountCents": {
+                    "type": "integer",
+                    "example": 40000
+                },
+                "categoryId": {
+                    "type": "string"
+                },
+                "period": {
+                    "description": "Period is week, month, quarter or year, defaults to month",
+                    "type": "string",
+                    "example": "month"
+                },
+                "rollover": {
+                    "description": "Rollover adds unused amounts of earlier periods to the budget",
+                    "type": "boolean",
+                    "example": false
+                },
+                "startDate": {
+                    "description": "StartDate is a day in the first period of the budget, defaults to today",
+                    "type": "string",
+                    "example": "2025-01-01"
+                }
+            }
+        },
+        "api.CreateCategoryRequest": {
+            "type": "object",
+            "properties": {
+                "colour": {
+                    "type": "string",
+                    "example": "#1e90ff"
+                },
+                "icon": {
+                    "type": "string",
+                    "example": "home"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Rent"
+                },
+                "parentId": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Type is income or expense, subcategories inherit the type of their\nparent when empty",
+                    "type": "string",
+                    "example": "expense"
+                }
+            }
+        },
+        "api.CreateNetWorthItemRequest": {
+            "type": "object",
+            "properties": {
+                "class": {
+                    "description": "Class is investments, property, vehicle, other_asset, mortgage, loan\nor other_liability",
+                    "type": "string",
+                    "example": "mortgage"
+                },
+                "loan": {
+                    "description": "Loan is the amortisation schedule of a liability",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.LoanSchedule"
+                        }
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Mortgage"
+                },
+                "quantityMicros": {
+                    "type": "integer",
+                    "example": 12500000
+                },
+                "symbol": {
+                    "description": "Symbol and QuantityMicros (millionths of a unit) value an investment\nholding with market prices",
+                    "type": "string",
+                    "example": "VWRL.AS"
+                }
+            }
+        },
+        "api.CreateTransactionRequest": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "description": "Account is our own account the transaction was booked on, empty for\ncash",
+                    "type": "string",
+                    "example": "NL91ABNA0417164300"
+                },
+                "amountCents": {
+                    "type": "integer",
+                    "example": 1250
+                },
+                "currency": {
+                    "description": "Currency is the ISO 4217 code of the amount, defaults to EUR",
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2025-01-15"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Cash at the market"
+                },
+                "direction": {
+                    "description": "Direction is in or out",
+                    "type": "string",
+                    "example": "out"
+                },
+                "note": {
+                    "type": "string",
+                    "example": "Vegetables"
+                }
+            }
+        },
+        "api.DailyBalance": {
+            "type": "object",
+            "properties": {
+                "anchored": {
+                    "description": "Anchored is set when the balance was set by an anchor",
+                    "type": "boolean",
+                    "example": false
+                },
+                "balanceCents": {
+                    "type": "integer",
+                    "example": 120750
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2025-09-15"
+                },
+                "differenceCents": {
+                    "type": "integer",
+                    "example": -2500
+                },
+                "mismatch": {
+                    "description": "Mismatch is set when the reported and reconstructed balance differ,\nusually because transactions are missing",
+                    "type": "boolean",
+                    "example": true
+                },
+                "netCents": {
+                    "description": "NetCents is the incoming minus the outgoing amount of the day",
+                    "type": "integer",
+                    "example": -4250
+                },
+                "reportedCents": {
+                    "description": "ReportedCents is the closing balance according to the bank statement",
+                    "type": "integer",
+                    "example": 118250
+                }
+            }
+        },
+        "api.FXImport": {
+            "type": "object",
+            "properties": {
+                "currencies": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "USD",
+                        "GBP",
+                        "CHF"
+                    ]
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2024-01-02"
+                },
+                "imported": {
+                    "description": "Imported is the number of rates stored, rates that were stored before\nare overwritten",
+                    "type": "integer",
+                    "example": 7560
+                },
+                "skipped": {
+                    "description": "Skipped is the number of rates the ECB did not publish (N/A)",
+                    "type": "integer",
+                    "example": 12
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2025-09-30"
+                }
+            }
+        },
+        "api.FXRate": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2025-09-30"
+                },
+                "rate": {
+                    "type": "string",
+                    "example": "1.1741"
+                },
+                "rateMicros": {
+                    "type": "integer",
+                    "example": 1174100
+                }
+            }
+        },
+        "api.Forecast": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string",
+                    "example": "NL91ABNA0417164300"
+                },
+                "currency": {
+                    "description": "Currency is the currency of the account, the reporting currency when\nall accounts are forecast",
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.ForecastDay"
+                    }
+                },
+                "discretionary": {
+                    "description": "Discretionary is the average spending per category outside the\nrecurring payments",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.ForecastCategory"
+                    }
+                },
+                "endBalanceCents": {
+                    "type": "integer",
+                    "example": 98000
+                },
+                "lowBalanceDate": {
+                    "description": "LowBalanceDate is the first day the balance drops below the\nthreshold, omitted when it stays above",
+                    "type": "string",
+                    "example": "2025-10-24"
+                },
+                "lowestBalanceCents": {
+                    "type": "integer",
+                    "example": -4500
+                },
+                "lowestBalanceDate": {
+                    "type": "string",
+                    "example": "2025-10-24"
+                },
+                "startBalanceCents": {
+                    "type": "integer",
+                    "example": 125000
+                },
+                "thresholdCents": {
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "api.ForecastCategory": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "example": "groceries"
+                },
+                "monthlyCents": {
+                    "type": "integer",
+                    "example": 42000
+                }
+            }
+        },
+        "api.ForecastDay": {
+            "type": "object",
+            "properties": {
+                "balanceCents": {
+                    "type": "integer",
+                    "example": -4500
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2025-10-24"
+                },
+                "discretionaryCents": {
+                    "type": "integer",
+                    "example": 2000
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.ForecastEntry"
+                    }
+                },
+                "expenseCents": {
+                    "description": "ExpenseCents includes the discretionary spending",
+                    "type": "integer",
+                    "example": 16399
+                },
+                "incomeCents": {
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "api.ForecastEntry": {
+            "type": "object",
+            "properties": {
+                "amountCents": {
+                    "description": "AmountCents is positive for income and negative for expenses",
+                    "type": "integer",
+                    "example": -1399
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Netflix"
+                },
+                "seriesId": {
+                    "type": "string"
+                },
+                "source": {
+                    "description": "Source is recurring or what_if",
+                    "type": "string",
+                    "example": "recurring"
+                }
+            }
+        },
+        "api.ForecastRequest": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "description": "Account is the account (usually its IBAN) to forecast, all accounts\nwhen empty",
+                    "type": "string",
+                    "example": "NL91ABNA0417164300"
+                },
+                "balanceCents": {
+                    "description": "BalanceCents replaces the current balance known from the transactions",
+                    "type": "integer",
+                    "example": 125000
+                },
+                "days": {
+                    "description": "Days to project, defaults to 90",
+                    "type": "integer",
+                    "example": 90
+                },
+                "thresholdCents": {
+                    "description": "ThresholdCents is the balance below which the forecast warns,\ndefaults to 0",
+                    "type": "integer",
+                    "example": 0
+                },
+                "whatIf": {
+                    "description": "WhatIf are extra incomes and expenses to project",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.WhatIfEntry"
+                    }
+                }
+            }
+        },
+        "api.Health": {
+            "type": "object",
+            "properties": {
+                "agent": {
+                    "description": "Agent is the state of the agent circuit breaker: closed, open or\nhalf-open.",
+                    "type": "string",
+                    "example": "closed"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "api.Holding": {
+            "type": "object",
+            "properties": {
+                "costCents": {
+                    "type": "integer",
+                    "example": 98700
+                },
+                "dividendCents": {
+                    "type": "integer",
+                    "example": 1520
+                },
+                "feeCents": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "incomplete": {
+                    "description": "Incomplete is set when more shares were sold than bought, usually\nbecause earlier purchases were not imported",
+                    "type": "boolean",
+                    "example": false
+                },
+                "lots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.Lot"
+                    }
+                },
+                "marketPrice": {
+                    "description": "MarketPrice is false when the holding is valued at its last trade",
+                    "type": "boolean",
+                    "example": true
+                },
+                "price": {
+                    "type": "string",
+                    "example": "118.96"
+                },
+                "priceDate": {
+                    "type": "string",
+                    "example": "2025-09-30"
+                },
+                "quantityMicros": {
+                    "type": "integer",
+                    "example": 10000000
+                },
+                "realisedCents": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "security": {
+                    "$ref": "#/definitions/api.Security"
+                },
+                "unrealisedCents": {
+                    "type": "integer",
+                    "example": 20260
+                },
+                "valueCents": {
+                    "type": "integer",
+                    "example": 118960
+                }
+            }
+        },
+        "api.Holdings": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "example": "2025-09-30"
+                },
+                "holdings": {
+                    "description": "Holdings include securities that were sold entirely for their\nrealised gains and dividends",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.Holding"
+                    }
+                },
+                "method": {
+                    "type": "string",
+                    "example": "fifo"
+                }
+            }
+        },
+        "api.InvestmentImport": {
+            "type": "object",
+            "properties": {
+                "duplicates": {
+                    "description": "Duplicates are trades that were imported before",
+                    "type": "integer",
+                    "example": 0
+                },
+                "imported": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "securities": {
+                    "description": "Securities are the securities seen for the first time, set their\nsymbol to value them with market prices",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.Security"
+                    }
+                },
+                "skipped": {
+                    "description": "Skipped are lines that are not trades, such as deposits and currency\nexchanges",
+                    "type": "integer",
+                    "example": 17
+                }
+            }
+        },
+        "api.InvestmentPerformance": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "dividendCents": {
+                    "type": "integer",
+                    "example": 4200
+                },
+                "endValueCents": {
+                    "type": "integer",
+                    "example": 1150000
+                },
+                "feeCents": {
+                    "type": "integer",
+                    "example": 600
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2025-01-01"
+                },
+                "moneyWeighted": {
+                    "description": "MoneyWeighted is the yearly internal rate of return of the money\nput in",
+                    "type": "number",
+                    "example": 0.061
+                },
+                "netInvestedCents": {
+                    "type": "integer",
+                    "example": 100000
+                },
+                "realisedCents": {
+                    "type": "integer",
+                    "example": 2500
+                },
+                "startValueCents": {
+                    "type": "integer",
+                    "example": 1000000
+                },
+                "timeWeighted": {
+                    "description": "TimeWeighted is the compounded return over the period, independent\nof when money was added",
+                    "type": "number",
+                    "example": 0.0452
+                },
+                "timeWeightedAnnual": {
+                    "description": "TimeWeightedAnnual is only set for periods of at least a year",
+                    "type": "number"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2025-09-30"
+                },
+                "unrealisedCents": {
+                    "description": "UnrealisedCents includes gains from before the period",
+                    "type": "integer",
+                    "example": 180000
+                }
+            }
+        },
+        "api.InvestmentTrade": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string",
+                    "example": "DEGIRO"
+                },
+                "amountCents": {
+                    "description": "AmountCents is the change of cash without fees, negative for buys",
+                    "type": "integer",
+                    "example": -98500
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Buy 10 VANGUARD FTSE AW@98.5 EUR (IE00B3RBWM25)"
+                },
+                "feeCents": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "id": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "string",
+                    "example": "98.50"
+                },
+                "quantityMicros": {
+                    "type": "integer",
+                    "example": 10000000
+                },
+                "ratioFrom": {
+                    "description": "RatioFrom shares became RatioTo shares in a split",
+                    "type": "integer"
+                },
+                "ratioTo": {
+                    "type": "integer"
+                },
+                "securityId": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Type is buy, sell, dividend, fee or split",
+                    "type": "string",
+                    "example": "buy"
+                }
+            }
+        },
+        "api.Label": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "vacation-2025"
+                }
+            }
+        },
+        "api.LabelTotals": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "inCents": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "name": {
+                    "type": "string",
+                    "example": "vacation-2025"
+                },
+                "outCents": {
+                    "type": "integer",
+                    "example": 185000
+                }
+            }
+        },
+        "api.LabelTransactionRequest": {
+            "type": "object",
+            "properties": {
+                "labels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "vacation-2025",
+                        "reimbursable"
+                    ]
+                }
+            }
+        },
+        "api.LoanSchedule": {
+            "type": "object",
+            "properties": {
+                "method": {
+                    "description": "Method is annuity or linear",
+                    "type": "string",
+                    "example": "annuity"
+                },
+                "principalCents": {
+                    "type": "integer",
+                    "example": 30000000
+                },
+                "rateBasisPoints": {
+                    "description": "RateBasisPoints is the yearly interest in hundredths of a percent",
+                    "type": "integer",
+                    "example": 385
+                },
+                "startDate": {
+                    "type": "string",
+                    "example": "2022-05-01"
+                },
+                "termMonths": {
+                    "type": "integer",
+                    "example": 360
+                }
+            }
+        },
+        "api.Lot": {
+            "type": "object",
+            "properties": {
+                "costCents": {
+                    "type": "integer",
+                    "example": 98700
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2024-01-02"
+                },
+                "quantityMicros": {
+                    "type": "integer",
+                    "example": 10000000
+                }
+            }
+        },
+        "api.MarketDataImport": {
+            "type": "object",
+            "properties": {
+                "format": {
+                    "description": "Format is the detected dialect, yahoo or stooq",
+                    "type": "string",
+                    "example": "yahoo"
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2024-10-01"
+                },
+                "imported": {
+                    "description": "Imported is the number of days stored, days that were stored before\nare overwritten",
+                    "type": "integer",
+                    "example": 252
+                },
+                "skipped": {
+                    "description": "Skipped is the number of rows without prices",
+                    "type": "integer",
+                    "example": 2
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2025-09-30"
+                }
+            }
+        },
+        "api.MarketPrice": {
+            "type": "object",
+            "properties": {
+                "close": {
+                    "type": "string",
+                    "example": "118.96"
+                },
+                "closeMicros": {
+                    "type": "integer",
+                    "example": 118960000
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2025-09-30"
+                },
+                "high": {
+                    "type": "string",
+                    "example": "119.10"
+                },
+                "low": {
+                    "type": "string",
+                    "example": "118.02"
+                },
+                "open": {
+                    "type": "string",
+                    "example": "118.42"
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "VWRL.AS"
+                },
+                "volume": {
+                    "type": "integer",
+                    "example": 184213
+                }
+            }
+        },
+        "api.MergeCategoriesRequest": {
+            "type": "object",
+            "properties": {
+                "sourceIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.MergeCounterpartiesRequest": {
+            "type": "object",
+            "properties": {
+                "sourceIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.NetWorth": {
+            "type": "object",
+            "properties": {
+                "breakdown": {
+                    "description": "Breakdown are the components of the last point",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.NetWorthComponent"
+                    }
+                },
+                "currency": {
+                    "description": "Currency is the reporting currency the values are converted to",
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "interval": {
+                    "type": "string",
+                    "example": "month"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.NetWorthPoint"
+                    }
+                }
+            }
+        },
+        "api.NetWorthComponent": {
+            "type": "object",
+            "properties": {
+                "class": {
+                    "type": "string",
+                    "example": "cash"
+                },
+                "currency": {
+                    "description": "Currency is the currency the component is held in, OriginalCents its\nvalue in that currency",
+                    "type": "string",
+                    "example": "USD"
+                },
+                "liability": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Joint account"
+                },
+                "originalCents": {
+                    "type": "integer",
+                    "example": 146000
+                },
+                "source": {
+                    "description": "Source is account:\u003cid\u003e or item:\u003cid\u003e",
+                    "type": "string",
+                    "example": "account:NL91ABNA0417164300"
+                },
+                "valueCents": {
+                    "type": "integer",
+                    "example": 125000
+                }
+            }
+        },
+        "api.NetWorthItem": {
+            "type": "object",
+            "properties": {
+                "class": {
+                    "type": "string",
+                    "example": "mortgage"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "liability": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "loan": {
+                    "$ref": "#/definitions/api.LoanSchedule"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Mortgage"
+                },
+                "quantityMicros": {
+                    "type": "integer",
+                    "example": 12500000
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "VWRL.AS"
+                }
+            }
+        },
+        "api.NetWorthPoint": {
+            "type": "object",
+            "properties": {
+                "assetsCents": {
+                    "type": "integer",
+                    "example": 52000000
+                },
+                "byClass": {
+                    "description": "ByClass sums the components per asset class, liabilities are\npositive as well",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2025-09-30"
+                },
+                "liabilitiesCents": {
+                    "type": "integer",
+                    "example": 28000000
+                },
+                "netWorthCents": {
+                    "type": "integer",
+                    "example": 24000000
+                },
+                "provisional": {
+                    "description": "Provisional is set when the snapshot was backfilled and is recomputed\nuntil it is confirmed",
+                    "type": "boolean",
+                    "example": false
+                },
+                "snapshot": {
+                    "description": "Snapshot is set when the point was read from a monthly snapshot",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "api.NetWorthValuationRequest": {
+            "type": "object",
+            "properties": {
+                "valueCents": {
+                    "description": "ValueCents is the value at the end of the day, positive for\nliabilities too",
+                    "type": "integer",
+                    "example": 45000000
+                }
+            }
+        },
+        "api.RecurringCosts": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "description": "Currency is the reporting currency the totals are converted to",
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "expenses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.RecurringSeries"
+                    }
+                },
+                "expensesByFrequency": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                },
+                "monthlyExpenseCents": {
+                    "type": "integer",
+                    "example": 11900
+                },
+                "yearlyExpenseCents": {
+                    "type": "integer",
+                    "example": 142800
+                },
+                "yearlyIncomeCents": {
+                    "type": "integer",
+                    "example": 4200000
+                }
+            }
+        },
+        "api.RecurringSeries": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string",
+                    "example": "NL91ABNA0417164300"
+                },
+                "amountCents": {
+                    "type": "integer",
+                    "example": 1399
+                },
+                "counterpartyId": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "direction": {
+                    "type": "string",
+                    "example": "out"
+                },
+                "firstDate": {
+                    "type": "string",
+                    "example": "2024-03-05"
+                },
+                "frequency": {
+                    "description": "Frequency is weekly, monthly, quarterly or yearly",
+                    "type": "string",
+                    "example": "monthly"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "lastDate": {
+                    "type": "string",
+                    "example": "2025-09-05"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Netflix"
+                },
+                "nextDate": {
+                    "type": "string",
+                    "example": "2025-10-05"
+                },
+                "occurrences": {
+                    "type": "integer",
+                    "example": 19
+                },
+                "previousAmountCents": {
+                    "type": "integer",
+                    "example": 1199
+                },
+                "priceIncreased": {
+                    "description": "PreviousAmountCents is the amount before the last price change,\nPriceIncreased is set when that change was an increase",
+                    "type": "boolean",
+                    "example": true
+                },
+                "reportingYearlyCents": {
+                    "description": "ReportingYearlyCents is YearlyCents in the reporting currency, only\nreported by the costs overview",
+                    "type": "integer",
+                    "example": 16788
+                },
+                "status": {
+                    "description": "Status is active, missed when the expected payment is overdue or ended\nwhen several payments did not happen",
+                    "type": "string",
+                    "example": "active"
+                },
+                "yearlyCents": {
+                    "type": "integer",
+                    "example": 16788
+                }
+            }
+        },
+        "api.RefundLink": {
+            "type": "object",
+            "properties": {
+                "amountCents": {
+                    "type": "integer",
+                    "example": 2999
+                },
+                "createdAt": {
+                    "type": "string",
+                    "example": "2025-01-20T10:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "original": {
+                    "$ref": "#/definitions/api.Transaction"
+                },
+                "refund": {
+                    "$ref": "#/definitions/api.Transaction"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "suggested"
+                },
+                "updatedAt": {
+                    "type": "string",
+                    "example": "2025-01-20T10:00:00Z"
+                }
+            }
+        },
+        "api.ReorderCategoriesRequest": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "description": "IDs of sibling categories in their new order",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.ReviewTagRequest": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "tag": {
+                    "description": "Tag corrects the machine tag, the current tag is accepted when empty.",
+                    "type": "string",
+                    "example": "groceries"
+                }
+            }
+        },
+        "api.Rule": {
+            "type": "object",
+            "properties": {
+                "actions": {
+                    "$ref": "#/definitions/api.RuleActions"
+                },
+                "conditions": {
+                    "$ref": "#/definitions/api.RuleConditions"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Monthly rent"
+                },
+                "priority": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.RuleActions": {
+            "type": "object",
+            "properties": {
+                "setIgnored": {
+                    "type": "boolean"
+                },
+                "setNote": {
+                    "type": "string"
+                },
+                "setTag": {
+                    "type": "string",
+                    "example": "rent"
+                }
+            }
+        },
+        "api.RuleChange": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "ignored": {
+                    "$ref": "#/definitions/api.BoolChange"
+                },
+                "note": {
+                    "$ref": "#/definitions/api.StringChange"
+                },
+                "tag": {
+                    "$ref": "#/definitions/api.StringChange"
+                },
+                "transactionId": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.RuleConditions": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string",
+                    "example": "NL90INGB0004140494"
+                },
+                "counterpartyId": {
+                    "type": "string"
+                },
+                "descriptionRegex": {
+                    "type": "string",
+                    "example": "(?i)^huur"
+                },
+                "direction": {
+                    "type": "string",
+                    "example": "out"
+                },
+                "maxAmountCents": {
+                    "type": "integer",
+                    "example": 150000
+                },
+                "minAmountCents": {
+                    "type": "integer",
+                    "example": 100000
+                },
+                "noteRegex": {
+                    "type": "string"
+                },
+                "weekdays": {
+                    "description": "Weekdays with 0 being sunday",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2,
+                        3,
+                        4,
+                        5
+                    ]
+                }
+            }
+        },
+        "api.RuleRequest": {
+            "type": "object",
+            "properties": {
+                "actions": {
+                    "$ref": "#/definitions/api.RuleActions"
+                },
+                "conditions": {
+                    "$ref": "#/definitions/api.RuleConditions"
+                },
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Monthly rent"
+                },
+                "priority": {
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "api.RuleSuggestion": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "keyType": {
+                    "type": "string",
+                    "example": "counterparty"
+                },
+                "ruleId": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "pending"
+                },
+                "tag": {
+                    "type": "string",
+                    "example": "groceries"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.Security": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "isin": {
+                    "type": "string",
+                    "example": "IE00B3RBWM25"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "VANGUARD FTSE AW"
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "VWRL.AS"
+                }
+            }
+        },
+        "api.SplitCounterpartyRequest": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "ibans": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.StringChange": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.TagTransactionRequest": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "tag": {
+                    "description": "Tag is the slug of a category",
+                    "type": "string",
+                    "example": "groceries"
+                }
+            }
+        },
+        "api.Transaction": {
+            "type": "object",
+            "properties": {
+                "amountCents": {
+                    "type": "integer",
+                    "example": 4250
+                },
+                "counterpartyIban": {
+                    "type": "string",
+                    "example": "NL91ABNA0417164300"
+                },
+                "counterpartyId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2025-01-15T00:00:00Z"
+                },
+                "description": {
                     "type": "string",
                     "example": "Grocery shopping"
                 },
+                "direction": {
+                    "type": "string",
+                    "example": "out"
+                },
                 "id": {
                     "type": "string",
                     "example": "550e8400-e29b-41d4-a716-446655440000"
                 },
+                "labels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "vacation-2025"
+                    ]
+                },
                 "note": {
                     "type": "string",
                     "example": "Bought fruits and vegetables"
                 },
+                "parentId": {
+                    "description": "ParentID is the transaction a fee was charged for.",
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
                 "source": {
                     "type": "string",
                     "example": "MyBank"
@@ -265,6 +6056,171 @@ const docTemplate = `{
                 "tag": {
                     "type": "string",
                     "example": "Food"
+                },
+                "tagConfidence": {
+                    "type": "number",
+                    "example": 0.72
+                },
+                "tagSource": {
+                    "description": "TagSource is manual, rule, classifier or agent, empty when unknown.",
+                    "type": "string",
+                    "example": "agent"
+                },
+                "taggedAt": {
+                    "type": "string",
+                    "example": "2025-01-16T08:00:00Z"
+                },
+                "taggedBy": {
+                    "type": "string",
+                    "example": "llama3.1"
+                }
+            }
+        },
+        "api.TransactionPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.Transaction"
+                    }
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "pageSize": {
+                    "type": "integer",
+                    "example": 50
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 120
+                }
+            }
+        },
+        "api.UpdateAccountRequest": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "description": "Currency is the ISO 4217 code the account is held in",
+                    "type": "string",
+                    "example": "USD"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Joint account"
+                },
+                "type": {
+                    "description": "Type is checking, savings or investment",
+                    "type": "string",
+                    "example": "savings"
+                }
+            }
+        },
+        "api.UpdateBudgetRequest": {
+            "type": "object",
+            "properties": {
+                "amountCents": {
+                    "type": "integer",
+                    "example": 40000
+                },
+                "categoryId": {
+                    "type": "string"
+                },
+                "period": {
+                    "type": "string",
+                    "example": "month"
+                },
+                "rollover": {
+                    "type": "boolean"
+                },
+                "startDate": {
+                    "type": "string",
+                    "example": "2025-01-01"
+                }
+            }
+        },
+        "api.UpdateCategoryRequest": {
+            "type": "object",
+            "properties": {
+                "colour": {
+                    "type": "string",
+                    "example": "#1e90ff"
+                },
+                "icon": {
+                    "type": "string",
+                    "example": "home"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Rent"
+                },
+                "parentId": {
+                    "description": "ParentID moves the category, the nil uuid moves it to the top level",
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "expense"
+                }
+            }
+        },
+        "api.UpdateCounterpartyRequest": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "defaultTag": {
+                    "type": "string"
+                },
+                "ibans": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "logoUrl": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.UpdateSecurityRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Vanguard FTSE All-World"
+                },
+                "symbol": {
+                    "description": "Symbol links the security to its market prices, empty values it at\nits last trade",
+                    "type": "string",
+                    "example": "VWRL.AS"
+                }
+            }
+        },
+        "api.WhatIfEntry": {
+            "type": "object",
+            "properties": {
+                "amountCents": {
+                    "description": "AmountCents is positive for income and negative for expenses",
+                    "type": "integer",
+                    "example": -150000
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2025-11-15"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "New laptop"
                 }
             }
         }
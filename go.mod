@@ -9,6 +9,7 @@ require (
 	github.com/swaggo/swag v1.16.6
 	go.elastic.co/apm/module/apmsql/v2 v2.7.2
 	go.yaml.in/yaml/v3 v3.0.4
+	golang.org/x/sync v0.19.0
 )
 
 require (
@@ -29,7 +30,6 @@ require (
 	go.uber.org/multierr v1.11.0 // indirect
 	golang.org/x/mod v0.32.0 // indirect
 	golang.org/x/net v0.49.0 // indirect
-	golang.org/x/sync v0.19.0 // indirect
 	golang.org/x/tools v0.41.0 // indirect
 )
 
@@ -42,7 +42,7 @@ require (
 	github.com/prometheus/procfs v0.15.1 // indirect
 	github.com/swaggo/http-swagger v1.3.4
 	go.elastic.co/apm/module/apmhttp/v2 v2.7.2
-	go.elastic.co/apm/v2 v2.7.2 // indirect
+	go.elastic.co/apm/v2 v2.7.2
 	go.elastic.co/fastjson v1.5.1 // indirect
 	golang.org/x/sys v0.40.0 // indirect
 	howett.net/plist v1.0.1 // indirect
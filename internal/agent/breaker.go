@@ -0,0 +1,112 @@
+package agent
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type BreakerState string
+
+const (
+	BreakerClosed BreakerState = "closed"
+	BreakerOpen   BreakerState = "open"
+	// BreakerHalfOpen lets a single call through to probe whether the agent
+	// is back.
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+var ErrCircuitOpen = fmt.Errorf("agent circuit breaker is open")
+
+// Breaker stops calls to the agent after threshold consecutive failures.
+// After the cooldown a single probe is let through, its outcome closes or
+// reopens the breaker.
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	if threshold < 1 {
+		threshold = 5
+	}
+	if cooldown <= 0 {
+		cooldown = time.Minute
+	}
+	return &Breaker{threshold: threshold, cooldown: cooldown, state: BreakerClosed}
+}
+
+// Allow returns ErrCircuitOpen when the call must not be made.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.current() {
+	case BreakerOpen:
+		return ErrCircuitOpen
+	case BreakerHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+	}
+	return nil
+}
+
+// Success records that the agent answered.
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = BreakerClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// Failure records that the agent was unreachable or failed.
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+		b.probing = false
+	}
+}
+
+// abort records that an allowed call was given up before it finished, so a
+// new probe may be made.
+func (b *Breaker) abort() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// State returns the current state, an open breaker whose cooldown has passed
+// is half-open.
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.current()
+}
+
+// Open reports whether calls are currently rejected.
+func (b *Breaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := b.current()
+	return s == BreakerOpen || (s == BreakerHalfOpen && b.probing)
+}
+
+func (b *Breaker) current() BreakerState {
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.cooldown {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
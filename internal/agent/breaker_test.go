@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	const cooldown = 20 * time.Millisecond
+	b := NewBreaker(2, cooldown)
+
+	expect := func(state BreakerState, open bool) {
+		t.Helper()
+		if got := b.State(); got != state {
+			t.Fatalf("State() = %s, want %s", got, state)
+		}
+		if got := b.Open(); got != open {
+			t.Fatalf("Open() = %v, want %v", got, open)
+		}
+	}
+	allow := func(want error) {
+		t.Helper()
+		if err := b.Allow(); !errors.Is(err, want) {
+			t.Fatalf("Allow() = %v, want %v", err, want)
+		}
+	}
+
+	expect(BreakerClosed, false)
+	allow(nil)
+	b.Failure()
+	expect(BreakerClosed, false)
+
+	// a success in between resets the count
+	b.Success()
+	b.Failure()
+	expect(BreakerClosed, false)
+	b.Failure()
+	expect(BreakerOpen, true)
+	allow(ErrCircuitOpen)
+
+	time.Sleep(cooldown)
+	expect(BreakerHalfOpen, false)
+	allow(nil)
+	// a single probe at a time
+	expect(BreakerHalfOpen, true)
+	allow(ErrCircuitOpen)
+
+	// a failed probe reopens the breaker right away
+	b.Failure()
+	expect(BreakerOpen, true)
+	allow(ErrCircuitOpen)
+
+	time.Sleep(cooldown)
+	allow(nil)
+	// an aborted probe lets the next call probe
+	b.abort()
+	expect(BreakerHalfOpen, false)
+	allow(nil)
+	b.Success()
+	expect(BreakerClosed, false)
+	allow(nil)
+	b.Failure()
+	expect(BreakerClosed, false)
+}
+
+func TestNewBreakerDefaults(t *testing.T) {
+	b := NewBreaker(0, 0)
+	if b.threshold != 5 || b.cooldown != time.Minute {
+		t.Errorf("NewBreaker(0, 0) = threshold %d, cooldown %s, want 5, 1m0s", b.threshold, b.cooldown)
+	}
+}
@@ -1,10 +1,15 @@
 package agent
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand/v2"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,20 +18,73 @@ import (
 
 type Option func(*Client)
 
+// WithTimeout limits the duration of a single call attempt.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		if d > 0 {
+			c.timeout = d
+		}
+	}
+}
+
+// WithRetry retries failed calls up to maxAttempts attempts in total. The
+// delay between attempts grows exponentially from baseDelay up to maxDelay
+// with jitter.
+func WithRetry(maxAttempts int, baseDelay, maxDelay time.Duration) Option {
+	return func(c *Client) {
+		if maxAttempts > 0 {
+			c.maxAttempts = maxAttempts
+		}
+		if baseDelay > 0 {
+			c.baseDelay = baseDelay
+		}
+		if maxDelay > 0 {
+			c.maxDelay = maxDelay
+		}
+	}
+}
+
+// WithPost sends the message as a JSON body {"message": ...} in a POST
+// request instead of in the query string of a GET request, for agent
+// services that accept it. Batched prompts can be too long for a query
+// string.
+func WithPost() Option {
+	return func(c *Client) {
+		c.post = true
+	}
+}
+
+// WithBreaker guards the calls with the circuit breaker.
+func WithBreaker(b *Breaker) Option {
+	return func(c *Client) {
+		c.breaker = b
+	}
+}
+
 type Client struct {
 	http    *http.Client
 	baseURL string
+	post    bool
+
+	timeout     time.Duration
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	breaker     *Breaker
 }
 
 func NewClient(baseURL string, opts ...Option) *Client {
 	httpClient := &http.Client{
 		Transport: apmhttp.WrapRoundTripper(http.DefaultTransport),
-		Timeout:   5 * time.Minute,
 	}
 
 	c := &Client{
-		http:    httpClient,
-		baseURL: baseURL,
+		http:        httpClient,
+		baseURL:     baseURL,
+		timeout:     time.Minute,
+		maxAttempts: 1,
+		baseDelay:   500 * time.Millisecond,
+		maxDelay:    30 * time.Second,
 	}
 
 	for _, opt := range opts {
@@ -36,27 +94,161 @@ func NewClient(baseURL string, opts ...Option) *Client {
 	return c
 }
 
-func (c *Client) CallAgent(ctx context.Context, ID uuid.UUID, msg string) error {
-	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/"+ID.String()+"/run", nil)
+// StatusError is returned when the agent answers with an unsuccessful status.
+type StatusError struct {
+	StatusCode int
+	Body       string
+	// RetryAfter is the delay the agent asked for on 429 responses.
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("Agent call failed with status code %d, message: %s", e.StatusCode, e.Body)
+}
+
+// CallAgent runs the agent with the message and returns its answer. Timeouts,
+// connection errors, 5xx and 429 responses are retried, other errors are
+// returned right away. ErrCircuitOpen is returned while the breaker is open.
+func (c *Client) CallAgent(ctx context.Context, ID uuid.UUID, msg string) (string, error) {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if c.breaker != nil {
+			if err := c.breaker.Allow(); err != nil {
+				return "", err
+			}
+		}
+		var answer string
+		answer, err = c.call(ctx, ID, msg)
+		if ctx.Err() != nil {
+			// the caller gave up, this says nothing about the agent
+			if c.breaker != nil {
+				c.breaker.abort()
+			}
+			return "", ctx.Err()
+		}
+		var statusErr *StatusError
+		isStatus := errors.As(err, &statusErr)
+		if c.breaker != nil {
+			if err != nil && (!isStatus || statusErr.StatusCode >= 500) {
+				c.breaker.Failure()
+			} else {
+				// the agent is up, also when it rejects the request
+				c.breaker.Success()
+			}
+		}
+		if err == nil {
+			return answer, nil
+		}
+		retryable := !isStatus || statusErr.StatusCode >= 500 || statusErr.StatusCode == http.StatusTooManyRequests
+		if !retryable || attempt >= c.maxAttempts {
+			return "", err
+		}
+		delay := c.backoff(attempt)
+		if isStatus && statusErr.RetryAfter > 0 {
+			delay = statusErr.RetryAfter
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return "", err
+		}
+	}
+}
+
+// runRequest is the body of a run of the agent.
+type runRequest struct {
+	Message string `json:"message"`
+}
+
+// call makes a single attempt, limited by the client timeout.
+func (c *Client) call(ctx context.Context, ID uuid.UUID, msg string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	req, err := c.newRequest(ctx, ID, msg)
 	if err != nil {
-		return err
+		return "", err
 	}
-	q := req.URL.Query()
-	q.Add("message", msg)
-	req.URL.RawQuery = q.Encode()
 	req.Header.Add("Accept", "application/json")
 	res, err := c.http.Do(req)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer res.Body.Close()
 	bodyBytes, err := io.ReadAll(res.Body)
 	bodyString := string(bodyBytes)
 	if err != nil {
-		return err
+		return "", err
 	}
 	if res.StatusCode >= 300 {
-		return fmt.Errorf("Agent call failed with status code %d, message: %s", res.StatusCode, bodyString)
+		return "", &StatusError{
+			StatusCode: res.StatusCode,
+			Body:       bodyString,
+			RetryAfter: retryAfter(res),
+		}
+	}
+	return bodyString, nil
+}
+
+// newRequest builds the run request, by default a GET request with the
+// message in the query string.
+func (c *Client) newRequest(ctx context.Context, ID uuid.UUID, msg string) (*http.Request, error) {
+	url := c.baseURL + "/" + ID.String() + "/run"
+	if !c.post {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		q := req.URL.Query()
+		q.Add("message", msg)
+		req.URL.RawQuery = q.Encode()
+		return req, nil
+	}
+	body, err := json.Marshal(runRequest{Message: msg})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Content-Type", "application/json")
+	return req, nil
+}
+
+// backoff returns the delay before the next attempt, doubling per attempt up
+// to maxDelay. Half of it is random so clients do not retry in lockstep.
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.baseDelay << (attempt - 1)
+	if d > c.maxDelay || d <= 0 {
+		d = c.maxDelay
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// retryAfter parses the Retry-After header of 429 responses, given either in
+// seconds or as an HTTP date.
+func retryAfter(res *http.Response) time.Duration {
+	if res.StatusCode != http.StatusTooManyRequests {
+		return 0
+	}
+	v := res.Header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return 0
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
-	return nil
 }
@@ -0,0 +1,285 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// stub answers the calls in order with the responses, the last response is
+// repeated. It expects the message in the query string of a GET request, or
+// as a JSON body of a POST request when post is set.
+type stub struct {
+	t         *testing.T
+	id        uuid.UUID
+	post      bool
+	responses []response
+	calls     atomic.Int32
+}
+
+type response struct {
+	status     int
+	body       string
+	retryAfter string
+	delay      time.Duration
+}
+
+func (s *stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := int(s.calls.Add(1))
+	method := http.MethodGet
+	if s.post {
+		method = http.MethodPost
+	}
+	if r.Method != method || r.URL.Path != "/"+s.id.String()+"/run" {
+		s.t.Errorf("request %s %s, want %s /%s/run", r.Method, r.URL.Path, method, s.id)
+	}
+	if !s.post {
+		if got := r.URL.Query().Get("message"); got != "tag these" {
+			s.t.Errorf("message = %q, want the message", got)
+		}
+	} else {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			s.t.Errorf("Content-Type = %q, want application/json", ct)
+		}
+		var body runRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Message != "tag these" {
+			s.t.Errorf("body = %+v (%v), want the message", body, err)
+		}
+	}
+	res := s.responses[min(n, len(s.responses))-1]
+	if res.delay > 0 {
+		select {
+		case <-time.After(res.delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if res.retryAfter != "" {
+		w.Header().Set("Retry-After", res.retryAfter)
+	}
+	w.WriteHeader(res.status)
+	w.Write([]byte(res.body))
+}
+
+func TestCallAgent(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		post        bool
+		responses   []response
+		wantAnswer  string
+		wantStatus  int
+		wantTimeout bool
+		wantCalls   int
+		minDuration time.Duration
+	}{
+		{
+			name:       "answer",
+			responses:  []response{{status: http.StatusOK, body: "groceries"}},
+			wantAnswer: "groceries",
+			wantCalls:  1,
+		},
+		{
+			name:       "answer to a post",
+			opts:       []Option{WithPost()},
+			post:       true,
+			responses:  []response{{status: http.StatusOK, body: "groceries"}},
+			wantAnswer: "groceries",
+			wantCalls:  1,
+		},
+		{
+			name:       "no retries by default",
+			responses:  []response{{status: http.StatusBadGateway, body: "down"}},
+			wantStatus: http.StatusBadGateway,
+			wantCalls:  1,
+		},
+		{
+			name: "5xx is retried with backoff",
+			opts: []Option{WithRetry(3, 20*time.Millisecond, time.Second)},
+			responses: []response{
+				{status: http.StatusInternalServerError},
+				{status: http.StatusServiceUnavailable},
+				{status: http.StatusOK, body: "groceries"},
+			},
+			wantAnswer: "groceries",
+			wantCalls:  3,
+			// half of 20ms and half of 40ms at least
+			minDuration: 30 * time.Millisecond,
+		},
+		{
+			name:       "retries give up after the attempts",
+			opts:       []Option{WithRetry(2, time.Millisecond, time.Millisecond)},
+			responses:  []response{{status: http.StatusInternalServerError, body: "boom"}},
+			wantStatus: http.StatusInternalServerError,
+			wantCalls:  2,
+		},
+		{
+			name:       "4xx is not retried",
+			opts:       []Option{WithRetry(3, time.Millisecond, time.Millisecond)},
+			responses:  []response{{status: http.StatusBadRequest, body: "bad"}},
+			wantStatus: http.StatusBadRequest,
+			wantCalls:  1,
+		},
+		{
+			name: "429 waits for Retry-After",
+			opts: []Option{WithRetry(2, time.Millisecond, time.Millisecond)},
+			responses: []response{
+				{status: http.StatusTooManyRequests, retryAfter: "1"},
+				{status: http.StatusOK, body: "groceries"},
+			},
+			wantAnswer:  "groceries",
+			wantCalls:   2,
+			minDuration: time.Second,
+		},
+		{
+			name:        "attempts time out",
+			opts:        []Option{WithTimeout(30 * time.Millisecond)},
+			responses:   []response{{status: http.StatusOK, delay: time.Second}},
+			wantTimeout: true,
+			wantCalls:   1,
+		},
+		{
+			name: "timeouts are retried",
+			opts: []Option{WithTimeout(30 * time.Millisecond), WithRetry(2, time.Millisecond, time.Millisecond)},
+			responses: []response{
+				{status: http.StatusOK, delay: time.Second},
+				{status: http.StatusOK, body: "groceries"},
+			},
+			wantAnswer: "groceries",
+			wantCalls:  2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &stub{t: t, id: uuid.New(), post: tt.post, responses: tt.responses}
+			srv := httptest.NewServer(s)
+			defer srv.Close()
+
+			start := time.Now()
+			answer, err := NewClient(srv.URL, tt.opts...).CallAgent(context.Background(), s.id, "tag these")
+			elapsed := time.Since(start)
+
+			var statusErr *StatusError
+			switch {
+			case tt.wantStatus != 0:
+				if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.wantStatus {
+					t.Fatalf("CallAgent() error = %v, want status %d", err, tt.wantStatus)
+				}
+			case tt.wantTimeout:
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Fatalf("CallAgent() error = %v, want a timeout", err)
+				}
+				if elapsed > 500*time.Millisecond {
+					t.Errorf("CallAgent() took %s, want it to time out", elapsed)
+				}
+			case err != nil:
+				t.Fatalf("CallAgent() error = %v", err)
+			case answer != tt.wantAnswer:
+				t.Errorf("CallAgent() = %q, want %q", answer, tt.wantAnswer)
+			}
+			if got := int(s.calls.Load()); got != tt.wantCalls {
+				t.Errorf("agent called %d times, want %d", got, tt.wantCalls)
+			}
+			if elapsed < tt.minDuration {
+				t.Errorf("CallAgent() took %s, want at least %s", elapsed, tt.minDuration)
+			}
+		})
+	}
+}
+
+func TestCallAgentBreaker(t *testing.T) {
+	s := &stub{t: t, id: uuid.New(), responses: []response{
+		{status: http.StatusBadRequest},
+		{status: http.StatusBadRequest},
+		{status: http.StatusInternalServerError},
+		{status: http.StatusInternalServerError},
+		{status: http.StatusOK, body: "groceries"},
+	}}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	b := NewBreaker(2, 50*time.Millisecond)
+	c := NewClient(srv.URL, WithBreaker(b))
+	call := func() error {
+		_, err := c.CallAgent(context.Background(), s.id, "tag these")
+		return err
+	}
+
+	// rejected requests mean the agent is up
+	call()
+	call()
+	if got := b.State(); got != BreakerClosed {
+		t.Fatalf("after 4xx State() = %s, want closed", got)
+	}
+	call()
+	call()
+	if got := b.State(); got != BreakerOpen {
+		t.Fatalf("after 5xx State() = %s, want open", got)
+	}
+	if err := call(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("CallAgent() error = %v, want ErrCircuitOpen", err)
+	}
+	if got := s.calls.Load(); got != 4 {
+		t.Fatalf("agent called %d times while open, want 4", got)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if got := b.State(); got != BreakerHalfOpen {
+		t.Fatalf("after the cooldown State() = %s, want half-open", got)
+	}
+	if err := call(); err != nil {
+		t.Fatalf("probe error = %v", err)
+	}
+	if got := b.State(); got != BreakerClosed {
+		t.Fatalf("after the probe State() = %s, want closed", got)
+	}
+}
+
+func TestCallAgentCanceled(t *testing.T) {
+	s := &stub{t: t, id: uuid.New(), responses: []response{{status: http.StatusOK, delay: time.Second}}}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	b := NewBreaker(1, time.Minute)
+	c := NewClient(srv.URL, WithBreaker(b), WithRetry(3, time.Millisecond, time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := c.CallAgent(ctx, s.id, "tag these"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("CallAgent() error = %v, want the context error", err)
+	}
+	// giving up is not a failure of the agent
+	if got := b.State(); got != BreakerClosed {
+		t.Errorf("State() = %s, want closed", got)
+	}
+	if got := s.calls.Load(); got != 1 {
+		t.Errorf("agent called %d times, want 1", got)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	c := NewClient("", WithRetry(5, 100*time.Millisecond, time.Second))
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{40, time.Second},
+	}
+	for _, tt := range tests {
+		for range 20 {
+			if d := c.backoff(tt.attempt); d < tt.max/2 || d > tt.max {
+				t.Errorf("backoff(%d) = %s, want between %s and %s", tt.attempt, d, tt.max/2, tt.max)
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package balance
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+type AccountType string
+
+const (
+	AccountChecking   AccountType = "checking"
+	AccountSavings    AccountType = "savings"
+	AccountInvestment AccountType = "investment"
+)
+
+var ErrInvalidAccountType = fmt.Errorf("account type must be checking, savings or investment")
+
+// Account holds the settings of one of our own accounts. Accounts are
+// identified by the value transactions carry in their account field, usually
+// the IBAN. Accounts without settings are checking accounts in the currency
+// of their transactions.
+type Account struct {
+	ID        string      `db:"id"`
+	Name      string      `db:"name"`
+	Type      AccountType `db:"type"`
+	Currency  string      `db:"currency"`
+	CreatedAt time.Time   `db:"created_at"`
+	UpdatedAt time.Time   `db:"updated_at"`
+}
+
+func NewAccount(id string) (*Account, error) {
+	if id == "" {
+		return nil, ErrInvalidAccount
+	}
+	now := time.Now().UTC()
+	return &Account{ID: id, Type: AccountChecking, Currency: transaction.DefaultCurrency, CreatedAt: now, UpdatedAt: now}, nil
+}
+
+func ParseAccountType(s string) (AccountType, error) {
+	switch t := AccountType(s); t {
+	case AccountChecking, AccountSavings, AccountInvestment:
+		return t, nil
+	default:
+		return "", ErrInvalidAccountType
+	}
+}
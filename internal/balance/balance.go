@@ -0,0 +1,208 @@
+package balance
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// Anchor is a known closing balance of an account at the end of a day, set
+// by the user. Balances of other days are reconstructed from the nearest
+// anchor by summing the transactions in between.
+type Anchor struct {
+	Account      string    `db:"account"`
+	Date         time.Time `db:"date"`
+	BalanceCents int64     `db:"balance_cents"`
+	CreatedAt    time.Time `db:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
+
+var (
+	ErrAnchorNotFound = fmt.Errorf("balance anchor not found")
+	ErrInvalidAccount = fmt.Errorf("account is required")
+	ErrInvalidRange   = fmt.Errorf("to must not be before from")
+)
+
+func NewAnchor(account string, date time.Time, balanceCents int64) (*Anchor, error) {
+	if account == "" {
+		return nil, ErrInvalidAccount
+	}
+	now := time.Now().UTC()
+	return &Anchor{
+		Account:      account,
+		Date:         day(date),
+		BalanceCents: balanceCents,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}, nil
+}
+
+// Daily is the balance of an account at the end of a day.
+type Daily struct {
+	Date time.Time
+	// NetCents is the incoming minus the outgoing amount of the day.
+	NetCents     int64
+	BalanceCents int64
+	// ReportedCents is the closing balance according to the bank statement,
+	// nil when the statement does not provide one for the day.
+	ReportedCents *int64
+	// Anchored is set when BalanceCents was set by an anchor.
+	Anchored bool
+}
+
+// Mismatch reports whether the statement reports a different balance than
+// the one reconstructed, which means transactions are missing or counted
+// twice.
+func (d Daily) Mismatch() bool {
+	return d.ReportedCents != nil && *d.ReportedCents != d.BalanceCents
+}
+
+// DifferenceCents is the reported minus the reconstructed balance.
+func (d Daily) DifferenceCents() int64 {
+	if d.ReportedCents == nil {
+		return 0
+	}
+	return *d.ReportedCents - d.BalanceCents
+}
+
+// Reconstruct returns the daily balances from from through to of an
+// account. The balance of a day is derived from the latest anchor on or
+// before it, or from the earliest anchor after it for days before the first
+// anchor. Without anchors the last balance reported by the statement is
+// used and without that the account starts at zero.
+func Reconstruct(txs []*transaction.Transaction, anchors []*Anchor, from, to time.Time) ([]Daily, error) {
+	from, to = day(from), day(to)
+	if to.Before(from) {
+		return nil, ErrInvalidRange
+	}
+	net := map[time.Time]int64{}
+	byDay := map[time.Time][]*transaction.Transaction{}
+	for _, tx := range txs {
+		d := day(tx.Date)
+		net[d] += signed(tx)
+		byDay[d] = append(byDay[d], tx)
+	}
+	reported := map[time.Time]int64{}
+	for d, txs := range byDay {
+		if cents, ok := closingBalance(txs); ok {
+			reported[d] = cents
+		}
+	}
+
+	// the points the balances are derived from, only anchors set by the
+	// user make a day anchored
+	anchored := len(anchors) > 0
+	points := make([]*Anchor, 0, len(anchors))
+	for _, a := range anchors {
+		points = append(points, &Anchor{Date: day(a.Date), BalanceCents: a.BalanceCents})
+	}
+	if len(points) == 0 {
+		var last time.Time
+		for d := range reported {
+			if d.After(last) {
+				last = d
+			}
+		}
+		if !last.IsZero() {
+			points = append(points, &Anchor{Date: last, BalanceCents: reported[last]})
+		}
+	}
+	if len(points) == 0 {
+		// start at zero the day before the first transaction
+		first := from
+		for d := range net {
+			if d.Before(first) {
+				first = d
+			}
+		}
+		points = append(points, &Anchor{Date: first.AddDate(0, 0, -1)})
+	}
+	slices.SortFunc(points, func(a, b *Anchor) int { return a.Date.Compare(b.Date) })
+
+	// the balance of a day is the balance of its anchor plus the running
+	// total of the net amounts between them
+	ordered := make([]time.Time, 0, len(net))
+	for d := range net {
+		ordered = append(ordered, d)
+	}
+	slices.SortFunc(ordered, func(a, b time.Time) int { return a.Compare(b) })
+	totals := make([]int64, len(ordered))
+	var total int64
+	for i, d := range ordered {
+		total += net[d]
+		totals[i] = total
+	}
+	// totalAt returns the running total at the end of the day
+	totalAt := func(d time.Time) int64 {
+		i, found := slices.BinarySearchFunc(ordered, d, func(a, b time.Time) int { return a.Compare(b) })
+		if found {
+			return totals[i]
+		}
+		if i == 0 {
+			return 0
+		}
+		return totals[i-1]
+	}
+
+	var res []Daily
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		// latest anchor on or before the day, the first one otherwise
+		p := points[0]
+		for _, a := range points {
+			if a.Date.After(d) {
+				break
+			}
+			p = a
+		}
+		daily := Daily{
+			Date:         d,
+			NetCents:     net[d],
+			BalanceCents: p.BalanceCents + totalAt(d) - totalAt(p.Date),
+			Anchored:     anchored && p.Date.Equal(d),
+		}
+		if cents, ok := reported[d]; ok {
+			daily.ReportedCents = &cents
+		}
+		res = append(res, daily)
+	}
+	return res, nil
+}
+
+// closingBalance returns the balance the statement reports at the end of
+// the day. Statements differ in the order of transactions within a day, so
+// the closing transaction is the one whose resulting balance is not the
+// starting balance of another transaction of the day.
+func closingBalance(txs []*transaction.Transaction) (int64, bool) {
+	opening := map[int64]bool{}
+	var candidates []int64
+	for _, tx := range txs {
+		if tx.BalanceAfterCents == nil {
+			continue
+		}
+		opening[*tx.BalanceAfterCents-signed(tx)] = true
+		candidates = append(candidates, *tx.BalanceAfterCents)
+	}
+	if len(candidates) == 0 {
+		return 0, false
+	}
+	for _, c := range candidates {
+		if !opening[c] {
+			return c, true
+		}
+	}
+	// the balances form a cycle, e.g. a payment and its reversal
+	return candidates[len(candidates)-1], true
+}
+
+func signed(tx *transaction.Transaction) int64 {
+	if tx.Direction == transaction.CashOut {
+		return -tx.AmountCents
+	}
+	return tx.AmountCents
+}
+
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
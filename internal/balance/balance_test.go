@@ -0,0 +1,152 @@
+package balance
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+func jan(d int) time.Time {
+	return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC)
+}
+
+// tx is a transaction on the day of January, reported is the balance after
+// it according to the statement, negative when not reported.
+func tx(d int, direction transaction.CashFlowDirection, cents, reported int64) *transaction.Transaction {
+	t := &transaction.Transaction{Date: jan(d), Direction: direction, AmountCents: cents}
+	if reported >= 0 {
+		t.BalanceAfterCents = &reported
+	}
+	return t
+}
+
+func TestReconstruct(t *testing.T) {
+	// in 1000 on the 2nd, out 250 on the 3rd and in 500 on the 5th
+	unreported := []*transaction.Transaction{
+		tx(2, transaction.CashIn, 1000, -1),
+		tx(3, transaction.CashOut, 250, -1),
+		tx(5, transaction.CashIn, 500, -1),
+	}
+	tests := []struct {
+		name    string
+		txs     []*transaction.Transaction
+		anchors []*Anchor
+		// balances from the 1st through the 5th
+		want         []int64
+		wantAnchored []int
+		// reported minus reconstructed balance of the days that mismatch
+		wantMismatch map[int]int64
+	}{
+		{
+			name: "starts at zero",
+			txs:  unreported,
+			want: []int64{0, 1000, 750, 750, 1250},
+		},
+		{
+			// days before the anchor are derived from it as well
+			name:         "single anchor",
+			txs:          unreported,
+			anchors:      []*Anchor{{Date: jan(3), BalanceCents: 5000}},
+			want:         []int64{4250, 5250, 5000, 5000, 5500},
+			wantAnchored: []int{3},
+		},
+		{
+			// every day uses the latest anchor on or before it
+			name:         "latest anchor",
+			txs:          unreported,
+			anchors:      []*Anchor{{Date: jan(4), BalanceCents: 900}, {Date: jan(2), BalanceCents: 100}},
+			want:         []int64{-900, 100, -150, 900, 1400},
+			wantAnchored: []int{2, 4},
+		},
+		{
+			name:    "anchor after the range",
+			txs:     unreported,
+			anchors: []*Anchor{{Date: jan(10), BalanceCents: 2000}},
+			want:    []int64{750, 1750, 1500, 1500, 2000},
+		},
+		{
+			// without anchors the last reported balance is used, it
+			// is not an anchor
+			name: "last reported balance",
+			txs: []*transaction.Transaction{
+				tx(2, transaction.CashIn, 1000, -1),
+				tx(3, transaction.CashOut, 250, -1),
+				tx(5, transaction.CashIn, 500, 2000),
+			},
+			want: []int64{750, 1750, 1500, 1500, 2000},
+		},
+		{
+			// the statement lists the transactions of the 3rd newest
+			// first, the closing balance is the one no other
+			// transaction started from
+			name: "closing balance within a day",
+			txs: []*transaction.Transaction{
+				tx(3, transaction.CashOut, 50, 1700),
+				tx(3, transaction.CashOut, 250, 1750),
+				tx(5, transaction.CashIn, 500, 2200),
+			},
+			want: []int64{2000, 2000, 1700, 1700, 2200},
+		},
+		{
+			// a transaction of the 3rd is missing from the import
+			name: "mismatch with the reported balance",
+			txs: []*transaction.Transaction{
+				tx(2, transaction.CashIn, 1000, 1000),
+				tx(3, transaction.CashOut, 250, 650),
+				tx(5, transaction.CashIn, 500, 1150),
+			},
+			want:         []int64{-100, 900, 650, 650, 1150},
+			wantMismatch: map[int]int64{2: 100},
+		},
+		{
+			// an anchor overrides the reported balances, which are
+			// still compared
+			name: "anchor and reported balances",
+			txs: []*transaction.Transaction{
+				tx(2, transaction.CashIn, 1000, 1000),
+				tx(3, transaction.CashOut, 250, 750),
+			},
+			anchors:      []*Anchor{{Date: jan(1), BalanceCents: 100}},
+			want:         []int64{100, 1100, 850, 850, 850},
+			wantAnchored: []int{1},
+			wantMismatch: map[int]int64{2: -100, 3: -100},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Reconstruct(tt.txs, tt.anchors, jan(1), jan(5))
+			if err != nil {
+				t.Fatalf("Reconstruct() error = %v", err)
+			}
+			var balances []int64
+			var anchored []int
+			mismatch := map[int]int64{}
+			for _, d := range got {
+				balances = append(balances, d.BalanceCents)
+				if d.Anchored {
+					anchored = append(anchored, d.Date.Day())
+				}
+				if d.Mismatch() {
+					mismatch[d.Date.Day()] = d.DifferenceCents()
+				}
+			}
+			if fmt.Sprint(balances) != fmt.Sprint(tt.want) {
+				t.Errorf("Reconstruct() balances = %v, want %v", balances, tt.want)
+			}
+			if fmt.Sprint(anchored) != fmt.Sprint(tt.wantAnchored) {
+				t.Errorf("Reconstruct() anchored days = %v, want %v", anchored, tt.wantAnchored)
+			}
+			if fmt.Sprint(mismatch) != fmt.Sprint(tt.wantMismatch) {
+				t.Errorf("Reconstruct() mismatches = %v, want %v", mismatch, tt.wantMismatch)
+			}
+		})
+	}
+}
+
+func TestReconstructInvalidRange(t *testing.T) {
+	if _, err := Reconstruct(nil, nil, jan(5), jan(1)); err != ErrInvalidRange {
+		t.Errorf("Reconstruct() error = %v, want %v", err, ErrInvalidRange)
+	}
+}
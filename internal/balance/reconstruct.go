@@ -0,0 +1,81 @@
+package balance
+
+import (
+	"context"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// Single-use interfaces only used by ReconstructHandler
+
+type TransactionFetcher interface {
+	FetchByAccount(ctx context.Context, account string) ([]*transaction.Transaction, error)
+}
+
+type AnchorLister interface {
+	Anchors(ctx context.Context, account string) ([]*Anchor, error)
+}
+
+type AccountLister interface {
+	Accounts(ctx context.Context) ([]*Account, error)
+}
+
+// ReconstructHandler reconstructs the daily balances of an account from its
+// anchors and transactions.
+type ReconstructHandler struct {
+	tf TransactionFetcher
+	al AnchorLister
+	ac AccountLister
+}
+
+func NewReconstructHandler(tf TransactionFetcher, al AnchorLister, ac AccountLister) *ReconstructHandler {
+	return &ReconstructHandler{tf: tf, al: al, ac: ac}
+}
+
+func (h *ReconstructHandler) Handle(ctx context.Context, account string, from, to time.Time) ([]Daily, error) {
+	if account == "" {
+		return nil, ErrInvalidAccount
+	}
+	return h.Daily(ctx, account, from, to)
+}
+
+// Daily is Handle for any account including the empty one, which holds the
+// transactions of statements that do not name the account.
+func (h *ReconstructHandler) Daily(ctx context.Context, account string, from, to time.Time) ([]Daily, error) {
+	txs, err := h.tf.FetchByAccount(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	anchors, err := h.al.Anchors(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	return Reconstruct(txs, anchors, from, to)
+}
+
+// Balance returns the balance of the account at the end of today, the sum
+// over all accounts when it is empty.
+func (h *ReconstructHandler) Balance(ctx context.Context, account string) (int64, error) {
+	today := time.Now().UTC()
+	accounts := []string{account}
+	if account == "" {
+		all, err := h.ac.Accounts(ctx)
+		if err != nil {
+			return 0, err
+		}
+		accounts = accounts[:0]
+		for _, a := range all {
+			accounts = append(accounts, a.ID)
+		}
+	}
+	var total int64
+	for _, a := range accounts {
+		days, err := h.Daily(ctx, a, today, today)
+		if err != nil {
+			return 0, err
+		}
+		total += days[len(days)-1].BalanceCents
+	}
+	return total, nil
+}
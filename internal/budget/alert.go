@@ -0,0 +1,128 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/notify"
+)
+
+// DefaultThresholds are the percentages of a budget at which alerts are sent.
+var DefaultThresholds = []int{80, 100}
+
+// Single-use interfaces only used by AlertHandler
+
+type AlertRecorder interface {
+	// RecordAlert stores that the threshold was reached in the period, it
+	// returns false when that alert was recorded before.
+	RecordAlert(ctx context.Context, budgetID uuid.UUID, periodStart time.Time, threshold int, spentCents int64) (bool, error)
+	// ForgetAlert removes a recorded alert that could not be sent.
+	ForgetAlert(ctx context.Context, budgetID uuid.UUID, periodStart time.Time, threshold int) error
+}
+
+// AlertHandler notifies once per budget, period and threshold when the
+// spending reaches a threshold percentage of the available amount.
+type AlertHandler struct {
+	tr         TxRunner
+	sh         *StatusHandler
+	ar         AlertRecorder
+	n          notify.Notifier
+	thresholds []int
+}
+
+func NewAlertHandler(tr TxRunner, sh *StatusHandler, ar AlertRecorder, n notify.Notifier, thresholds ...int) *AlertHandler {
+	if len(thresholds) == 0 {
+		thresholds = DefaultThresholds
+	}
+	thresholds = slices.Clone(thresholds)
+	slices.Sort(thresholds)
+	return &AlertHandler{tr: tr, sh: sh, ar: ar, n: n, thresholds: thresholds}
+}
+
+// Handle evaluates the budgets in their current period and returns the
+// number of alerts sent. An alert is recorded and committed before it is
+// sent, so it is never sent twice, and forgotten again when sending fails so
+// it is retried on the next run. A failing budget does not stop the others,
+// their errors are joined.
+func (h *AlertHandler) Handle(ctx context.Context, now time.Time) (int, error) {
+	statuses, err := h.sh.Handle(ctx, now, now)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	var errs []error
+	for _, s := range statuses {
+		reached := 0
+		for _, t := range h.thresholds {
+			if s.Percent() >= float64(t) {
+				reached = t
+			}
+		}
+		if reached == 0 {
+			continue
+		}
+		isNew, err := h.record(ctx, s, reached)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("budget %s: %w", s.Budget.ID, err))
+			continue
+		}
+		if !isNew {
+			continue
+		}
+		if err := h.n.Notify(ctx, alertMessage(s, reached)); err != nil {
+			errs = append(errs, fmt.Errorf("budget %s: %w", s.Budget.ID, errors.Join(err, h.ar.ForgetAlert(ctx, s.Budget.ID, s.PeriodStart, reached))))
+			continue
+		}
+		sent++
+	}
+	return sent, errors.Join(errs...)
+}
+
+// record records the thresholds up to the reached one and reports whether
+// the reached threshold is new. Only the highest threshold reached is sent,
+// lower ones are recorded with it so they do not follow later.
+func (h *AlertHandler) record(ctx context.Context, s Status, reached int) (bool, error) {
+	isNew := false
+	err := h.tr.WithTx(ctx, func(ctx context.Context) error {
+		isNew = false
+		for _, t := range h.thresholds {
+			if t > reached {
+				break
+			}
+			recorded, err := h.ar.RecordAlert(ctx, s.Budget.ID, s.PeriodStart, t, s.SpentCents)
+			if err != nil {
+				return err
+			}
+			isNew = isNew || (recorded && t == reached)
+		}
+		return nil
+	})
+	return isNew, err
+}
+
+func alertMessage(s Status, threshold int) notify.Message {
+	title := fmt.Sprintf("Budget for %s at %d%%", s.Category.Name, threshold)
+	if threshold >= 100 {
+		title = fmt.Sprintf("Budget for %s exceeded", s.Category.Name)
+	}
+	return notify.Message{
+		Kind:  "budget_alert",
+		Title: title,
+		Body: fmt.Sprintf("Spent %.2f of %.2f between %s and %s, projected %.2f by the end of the period.",
+			float64(s.SpentCents)/100, float64(s.AvailableCents())/100,
+			s.PeriodStart.Format(time.DateOnly), s.PeriodEnd.Format(time.DateOnly), float64(s.ProjectedCents)/100),
+		Data: map[string]any{
+			"budgetId":       s.Budget.ID,
+			"category":       s.Category.Slug,
+			"threshold":      threshold,
+			"periodStart":    s.PeriodStart.Format(time.DateOnly),
+			"spentCents":     s.SpentCents,
+			"availableCents": s.AvailableCents(),
+			"projectedCents": s.ProjectedCents,
+		},
+	}
+}
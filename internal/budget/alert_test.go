@@ -0,0 +1,175 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/category"
+	"github.com/lennardclaproth/my-finances-tracker/internal/notify"
+	"github.com/lennardclaproth/my-finances-tracker/internal/report"
+)
+
+type fakeBudgets []*Budget
+
+func (f fakeBudgets) List(ctx context.Context) ([]*Budget, error) {
+	return f, nil
+}
+
+type fakeCategories []*category.Category
+
+func (f fakeCategories) List(ctx context.Context) ([]*category.Category, error) {
+	return f, nil
+}
+
+type fakeCashflow []report.Cashflow
+
+func (f fakeCashflow) Cashflow(ctx context.Context, from, to time.Time, interval report.Interval, groupBy report.GroupBy, currency string) ([]report.Cashflow, error) {
+	return f, nil
+}
+
+// fakeTx rolls back the alerts recorded in fn when fn fails, or when err is
+// set to fail the commit.
+type fakeTx struct {
+	alerts *fakeAlerts
+	err    error
+}
+
+func (f fakeTx) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	before := map[string]bool{}
+	for k, v := range f.alerts.recorded {
+		before[k] = v
+	}
+	err := fn(ctx)
+	if err == nil {
+		err = f.err
+	}
+	if err != nil {
+		f.alerts.recorded = before
+	}
+	return err
+}
+
+// fakeAlerts records the alerts as budget/threshold, recording the alerts
+// of the budgets in fail fails.
+type fakeAlerts struct {
+	recorded map[string]bool
+	fail     map[uuid.UUID]bool
+}
+
+func (f *fakeAlerts) RecordAlert(ctx context.Context, budgetID uuid.UUID, periodStart time.Time, threshold int, spentCents int64) (bool, error) {
+	if f.fail[budgetID] {
+		return false, errors.New("database down")
+	}
+	key := fmt.Sprintf("%s/%d", budgetID, threshold)
+	if f.recorded[key] {
+		return false, nil
+	}
+	f.recorded[key] = true
+	return true, nil
+}
+
+func (f *fakeAlerts) ForgetAlert(ctx context.Context, budgetID uuid.UUID, periodStart time.Time, threshold int) error {
+	delete(f.recorded, fmt.Sprintf("%s/%d", budgetID, threshold))
+	return nil
+}
+
+// fakeNotifier collects the titles it sent, titles in fail fail to send.
+type fakeNotifier struct {
+	sent []string
+	fail map[string]bool
+}
+
+func (f *fakeNotifier) Notify(ctx context.Context, m notify.Message) error {
+	if f.fail[m.Title] {
+		return errors.New("webhook down")
+	}
+	f.sent = append(f.sent, m.Title)
+	return nil
+}
+
+func TestAlertHandler(t *testing.T) {
+	groceries := &category.Category{ID: uuid.New(), Slug: "groceries", Name: "Groceries"}
+	transport := &category.Category{ID: uuid.New(), Slug: "transport", Name: "Transport"}
+	rent := &category.Category{ID: uuid.New(), Slug: "rent", Name: "Rent"}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	budget := func(c *category.Category, cents int64) *Budget {
+		return &Budget{ID: uuid.New(), CategoryID: c.ID, AmountCents: cents, Period: report.Month, StartDate: start}
+	}
+	groceriesBudget, transportBudget, rentBudget := budget(groceries, 10000), budget(transport, 5000), budget(rent, 100000)
+	// groceries at 90%, transport at 120% and rent at 10%
+	flows := fakeCashflow{
+		{Start: start, Group: "groceries", ExpenseCents: 9000},
+		{Start: start, Group: "transport", ExpenseCents: 6000},
+		{Start: start, Group: "rent", ExpenseCents: 10000},
+	}
+	sh := NewStatusHandler(fakeBudgets{groceriesBudget, transportBudget, rentBudget}, fakeCategories{groceries, transport, rent}, flows, "EUR")
+	now := time.Date(2024, 1, 20, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		commitErr   error
+		failRecord  *Budget
+		failNotify  string
+		wantSent    []string
+		wantErr     bool
+		wantRetried []string
+	}{
+		{
+			name:     "highest threshold per budget",
+			wantSent: []string{"Budget for Groceries at 80%", "Budget for Transport exceeded"},
+		},
+		{
+			// the transport alert is still sent, the groceries alert is
+			// forgotten and sent on the next run
+			name:        "failing notification",
+			failNotify:  "Budget for Groceries at 80%",
+			wantSent:    []string{"Budget for Transport exceeded"},
+			wantErr:     true,
+			wantRetried: []string{"Budget for Groceries at 80%"},
+		},
+		{
+			name:        "failing budget",
+			failRecord:  groceriesBudget,
+			wantSent:    []string{"Budget for Transport exceeded"},
+			wantErr:     true,
+			wantRetried: []string{"Budget for Groceries at 80%"},
+		},
+		{
+			// nothing is sent for alerts that were not recorded
+			name:        "failing commit",
+			commitErr:   errors.New("commit failed"),
+			wantErr:     true,
+			wantRetried: []string{"Budget for Groceries at 80%", "Budget for Transport exceeded"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alerts := &fakeAlerts{recorded: map[string]bool{}, fail: map[uuid.UUID]bool{}}
+			if tt.failRecord != nil {
+				alerts.fail[tt.failRecord.ID] = true
+			}
+			n := &fakeNotifier{fail: map[string]bool{tt.failNotify: true}}
+			sent, err := NewAlertHandler(fakeTx{alerts, tt.commitErr}, sh, alerts, n).Handle(context.Background(), now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Handle() error = %v, want error %v", err, tt.wantErr)
+			}
+			if sent != len(tt.wantSent) || fmt.Sprint(n.sent) != fmt.Sprint(tt.wantSent) {
+				t.Errorf("Handle() sent %d: %v, want %v", sent, n.sent, tt.wantSent)
+			}
+
+			// the next run only sends what was not sent before
+			alerts.fail = map[uuid.UUID]bool{}
+			n = &fakeNotifier{}
+			if _, err := NewAlertHandler(fakeTx{alerts: alerts}, sh, alerts, n).Handle(context.Background(), now); err != nil {
+				t.Fatalf("second Handle() error = %v", err)
+			}
+			if fmt.Sprint(n.sent) != fmt.Sprint(tt.wantRetried) {
+				t.Errorf("second Handle() sent %v, want %v", n.sent, tt.wantRetried)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package budget
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/category"
+	"github.com/lennardclaproth/my-finances-tracker/internal/report"
+)
+
+// Budget limits the spending in a category and its subcategories per period.
+// Periods are calendar weeks, months, quarters or years, the budget applies
+// from the period containing StartDate on.
+type Budget struct {
+	ID          uuid.UUID       `db:"id"`
+	CategoryID  uuid.UUID       `db:"category_id"`
+	AmountCents int64           `db:"amount_cents"`
+	Period      report.Interval `db:"period"`
+	StartDate   time.Time       `db:"start_date"`
+	// Rollover adds the unused amount of earlier periods to the budget.
+	Rollover  bool      `db:"rollover"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+var (
+	ErrBudgetNotFound = fmt.Errorf("budget not found")
+	ErrBudgetExists   = fmt.Errorf("the category already has a budget for this period")
+	ErrInvalidAmount  = fmt.Errorf("budget amount must be positive")
+	ErrInvalidPeriod  = fmt.Errorf("budget period must be week, month, quarter or year")
+	ErrNotExpense     = fmt.Errorf("budgets can only be set on expense categories")
+)
+
+// Shared interfaces used by multiple use cases
+
+type CategoryFetcher interface {
+	FetchByID(ctx context.Context, id uuid.UUID) (*category.Category, error)
+}
+
+type CategoryLister interface {
+	List(ctx context.Context) ([]*category.Category, error)
+}
+
+type BudgetLister interface {
+	List(ctx context.Context) ([]*Budget, error)
+}
+
+// TxRunner runs fn within a single database transaction.
+type TxRunner interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+func NewBudget(categoryID uuid.UUID, amountCents int64, period report.Interval, startDate time.Time, rollover bool) (*Budget, error) {
+	now := time.Now().UTC()
+	b := &Budget{
+		ID:          uuid.New(),
+		CategoryID:  categoryID,
+		AmountCents: amountCents,
+		Period:      period,
+		StartDate:   startDate,
+		Rollover:    rollover,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if b.Period == "" {
+		b.Period = report.Month
+	}
+	if b.StartDate.IsZero() {
+		b.StartDate = now
+	}
+	b.StartDate = b.Period.Start(b.StartDate)
+	return b, b.validate()
+}
+
+func (b *Budget) validate() error {
+	if b.AmountCents <= 0 {
+		return ErrInvalidAmount
+	}
+	if _, err := report.ParseInterval(string(b.Period)); err != nil || b.Period == "" {
+		return ErrInvalidPeriod
+	}
+	return nil
+}
+
+// checkCategory returns ErrNotExpense when the budget's category is not an
+// expense category.
+func checkCategory(ctx context.Context, cf CategoryFetcher, id uuid.UUID) error {
+	c, err := cf.FetchByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if c.Type != category.Expense {
+		return ErrNotExpense
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package budget
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/report"
+)
+
+// Single-use interfaces only used by CreateHandler
+
+type BudgetCreator interface {
+	Create(ctx context.Context, b *Budget) error
+}
+
+type CreateHandler struct {
+	cf CategoryFetcher
+	bc BudgetCreator
+}
+
+func NewCreateHandler(cf CategoryFetcher, bc BudgetCreator) *CreateHandler {
+	return &CreateHandler{cf: cf, bc: bc}
+}
+
+// Handle creates a budget for the expense category with categoryID. An empty
+// period is monthly, a zero start date starts the budget in the current
+// period.
+func (h *CreateHandler) Handle(ctx context.Context, categoryID uuid.UUID, amountCents int64, period report.Interval, startDate time.Time, rollover bool) (*Budget, error) {
+	b, err := NewBudget(categoryID, amountCents, period, startDate, rollover)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCategory(ctx, h.cf, categoryID); err != nil {
+		return nil, err
+	}
+	if err := h.bc.Create(ctx, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Changes holds the fields of a budget to update, nil fields are left
+// untouched.
+type Changes struct {
+	CategoryID  *uuid.UUID
+	AmountCents *int64
+	Period      *report.Interval
+	StartDate   *time.Time
+	Rollover    *bool
+}
+
+// Single-use interfaces only used by UpdateHandler
+
+type BudgetFetcher interface {
+	FetchByID(ctx context.Context, id uuid.UUID) (*Budget, error)
+}
+
+type BudgetUpdater interface {
+	Update(ctx context.Context, b *Budget) error
+}
+
+type UpdateHandler struct {
+	tr TxRunner
+	cf CategoryFetcher
+	bf BudgetFetcher
+	bu BudgetUpdater
+}
+
+func NewUpdateHandler(tr TxRunner, cf CategoryFetcher, bf BudgetFetcher, bu BudgetUpdater) *UpdateHandler {
+	return &UpdateHandler{tr: tr, cf: cf, bf: bf, bu: bu}
+}
+
+// Handle applies the changes to the budget. Changing the period or start
+// date realigns the start date to the start of its period.
+func (h *UpdateHandler) Handle(ctx context.Context, id uuid.UUID, changes Changes) (*Budget, error) {
+	var b *Budget
+	err := h.tr.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		if b, err = h.bf.FetchByID(ctx, id); err != nil {
+			return err
+		}
+		if changes.CategoryID != nil && *changes.CategoryID != b.CategoryID {
+			if err := checkCategory(ctx, h.cf, *changes.CategoryID); err != nil {
+				return err
+			}
+			b.CategoryID = *changes.CategoryID
+		}
+		if changes.AmountCents != nil {
+			b.AmountCents = *changes.AmountCents
+		}
+		if changes.Period != nil {
+			b.Period = *changes.Period
+		}
+		if changes.StartDate != nil {
+			b.StartDate = *changes.StartDate
+		}
+		if changes.Rollover != nil {
+			b.Rollover = *changes.Rollover
+		}
+		if err := b.validate(); err != nil {
+			return err
+		}
+		b.StartDate = b.Period.Start(b.StartDate)
+		b.UpdatedAt = time.Now().UTC()
+		return h.bu.Update(ctx, b)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
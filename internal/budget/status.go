@@ -0,0 +1,142 @@
+package budget
+
+import (
+	"context"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/category"
+	"github.com/lennardclaproth/my-finances-tracker/internal/report"
+)
+
+// Status is the spending against a budget in one period.
+type Status struct {
+	Budget      *Budget
+	Category    *category.Category
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	// RolloverCents is the unused amount carried over from earlier periods.
+	RolloverCents int64
+	// SpentCents is the outgoing minus the incoming amount of the category
+	// and its subcategories, refunds lower the spending.
+	SpentCents int64
+	// ProjectedCents extrapolates the spending so far to the end of the
+	// period.
+	ProjectedCents int64
+}
+
+// AvailableCents is the budget amount including the rollover.
+func (s Status) AvailableCents() int64 {
+	return s.Budget.AmountCents + s.RolloverCents
+}
+
+func (s Status) RemainingCents() int64 {
+	return s.AvailableCents() - s.SpentCents
+}
+
+// Percent is the share of the available amount that has been spent.
+func (s Status) Percent() float64 {
+	if s.AvailableCents() <= 0 {
+		return 100
+	}
+	return float64(s.SpentCents) * 100 / float64(s.AvailableCents())
+}
+
+// Single-use interfaces only used by StatusHandler
+
+type CashflowFetcher interface {
+	Cashflow(ctx context.Context, from, to time.Time, interval report.Interval, groupBy report.GroupBy, currency string) ([]report.Cashflow, error)
+}
+
+type StatusHandler struct {
+	bl       BudgetLister
+	cl       CategoryLister
+	cf       CashflowFetcher
+	currency string
+}
+
+// NewStatusHandler creates a StatusHandler, budgets are amounts in the
+// reporting currency the spending is converted to.
+func NewStatusHandler(bl BudgetLister, cl CategoryLister, cf CashflowFetcher, currency string) *StatusHandler {
+	return &StatusHandler{bl: bl, cl: cl, cf: cf, currency: currency}
+}
+
+// Handle returns the status of every budget in its period containing date,
+// budgets starting after that period are left out. The spending is projected
+// as of today.
+func (h *StatusHandler) Handle(ctx context.Context, date, today time.Time) ([]Status, error) {
+	budgets, err := h.bl.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	all, err := h.cl.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tree := category.NewTree(all)
+	// the cash flow is fetched once per period length, from the earliest
+	// period a rollover has to look at
+	from := map[report.Interval]time.Time{}
+	for _, b := range budgets {
+		first := b.Period.Start(date)
+		if b.StartDate.After(first) {
+			continue
+		}
+		if b.Rollover {
+			first = b.Period.Start(b.StartDate)
+		}
+		if f, ok := from[b.Period]; !ok || first.Before(f) {
+			from[b.Period] = first
+		}
+	}
+	flows := map[report.Interval][]report.Cashflow{}
+	for period, f := range from {
+		if flows[period], err = h.cf.Cashflow(ctx, f, period.End(period.Start(date)), period, report.ByTag, h.currency); err != nil {
+			return nil, err
+		}
+	}
+	statuses := []Status{}
+	for _, b := range budgets {
+		c := tree.ByID(b.CategoryID)
+		start := b.Period.Start(date)
+		if c == nil || b.StartDate.After(start) {
+			continue
+		}
+		spent := spending(tree, c, flows[b.Period])
+		s := Status{Budget: b, Category: c, PeriodStart: start, PeriodEnd: b.Period.End(start), SpentCents: spent[start]}
+		if b.Rollover {
+			for p := b.Period.Start(b.StartDate); p.Before(start); p = b.Period.Next(p) {
+				s.RolloverCents = max(0, s.RolloverCents+b.AmountCents-spent[p])
+			}
+		}
+		s.ProjectedCents = project(s.SpentCents, s.PeriodStart, s.PeriodEnd, today)
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+// spending sums the cash flow of the category and its subcategories per
+// period start.
+func spending(tree *category.Tree, c *category.Category, flows []report.Cashflow) map[time.Time]int64 {
+	spent := map[time.Time]int64{}
+	for _, f := range flows {
+		tagged := tree.BySlug(f.Group)
+		if tagged == nil || !tree.IsDescendant(tagged.ID, c.ID) {
+			continue
+		}
+		spent[f.Start] += f.ExpenseCents - f.IncomeCents
+	}
+	return spent
+}
+
+// project extrapolates the spending linearly over the days of the period
+// that have passed. Past periods are complete and future periods have no
+// spending yet.
+func project(spent int64, start, end, today time.Time) int64 {
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	if today.Before(start) || today.After(end) {
+		return spent
+	}
+	elapsed := int64(today.Sub(start).Hours()/24) + 1
+	total := int64(end.Sub(start).Hours()/24) + 1
+	return spent * total / elapsed
+}
@@ -0,0 +1,151 @@
+package bulk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/label"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+type OperationType string
+
+const (
+	SetTag      OperationType = "set_tag"
+	ClearTag    OperationType = "clear_tag"
+	SetIgnored  OperationType = "set_ignored"
+	AddLabel    OperationType = "add_label"
+	RemoveLabel OperationType = "remove_label"
+	// DeleteManual deletes manually entered transactions, imported
+	// transactions are never deleted in bulk.
+	DeleteManual OperationType = "delete_manual"
+)
+
+// Operation is applied to every selected transaction.
+type Operation struct {
+	Type    OperationType `json:"type"`
+	Tag     string        `json:"tag,omitempty"`
+	Ignored *bool         `json:"ignored,omitempty"`
+	Label   string        `json:"label,omitempty"`
+}
+
+// State is what a transaction looked like before a batch changed it, enough
+// to undo the change.
+type State struct {
+	Tag        string                    `json:"tag"`
+	Provenance transaction.TagProvenance `json:"provenance"`
+	Ignored    bool                      `json:"ignored"`
+	// Transaction and Labels hold the full transaction for deletions.
+	Transaction *transaction.Transaction `json:"transaction,omitempty"`
+	Labels      []string                 `json:"labels,omitempty"`
+}
+
+type Item struct {
+	TransactionID uuid.UUID
+	Before        State
+}
+
+// Batch records a bulk operation and the transactions it changed.
+// Transactions the operation would not change are not part of the batch.
+type Batch struct {
+	ID        uuid.UUID
+	Operation Operation
+	Affected  int
+	Items     []Item
+	CreatedAt time.Time
+	UndoneAt  *time.Time
+}
+
+var (
+	ErrBatchNotFound       = fmt.Errorf("bulk batch not found")
+	ErrAlreadyUndone       = fmt.Errorf("bulk batch was already undone")
+	ErrInvalidOperation    = fmt.Errorf("invalid bulk operation")
+	ErrNoSelection         = fmt.Errorf("either transaction ids or a non-empty filter is required")
+	ErrTransactionNotFound = fmt.Errorf("one or more transactions not found")
+)
+
+// Shared interfaces used by multiple use cases
+
+type TransactionWriter interface {
+	SetTag(ctx context.Context, ids []uuid.UUID, tag string, p transaction.TagProvenance) error
+	SetIgnored(ctx context.Context, ids []uuid.UUID, ignored bool) error
+	Delete(ctx context.Context, ids []uuid.UUID) error
+	Create(ctx context.Context, tx *transaction.Transaction) error
+}
+
+type LabelWriter interface {
+	Ensure(ctx context.Context, names []string) ([]*label.Label, error)
+	FetchByNames(ctx context.Context, names []string) ([]*label.Label, error)
+	Attach(ctx context.Context, transactionIDs, labelIDs []uuid.UUID) error
+	Detach(ctx context.Context, transactionIDs, labelIDs []uuid.UUID) error
+	NamesFor(ctx context.Context, transactionIDs []uuid.UUID) (map[uuid.UUID][]string, error)
+}
+
+// TxRunner runs fn within a single database transaction.
+type TxRunner interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Normalise validates the operation and normalises its label.
+func (o Operation) Normalise() (Operation, error) {
+	o.Tag = strings.TrimSpace(o.Tag)
+	switch o.Type {
+	case SetTag:
+		if o.Tag == "" {
+			return o, fmt.Errorf("%w: set_tag requires a tag", ErrInvalidOperation)
+		}
+	case SetIgnored:
+		if o.Ignored == nil {
+			return o, fmt.Errorf("%w: set_ignored requires ignored", ErrInvalidOperation)
+		}
+	case AddLabel, RemoveLabel:
+		o.Label = label.Normalise(o.Label)
+		if o.Label == "" {
+			return o, fmt.Errorf("%w: %s requires a label", ErrInvalidOperation, o.Type)
+		}
+	case ClearTag, DeleteManual:
+	default:
+		return o, fmt.Errorf("%w: unknown operation %q", ErrInvalidOperation, o.Type)
+	}
+	return o, nil
+}
+
+// changes reports whether the operation changes tx, which carries the given
+// labels.
+func (o Operation) changes(tx *transaction.Transaction, labels []string) bool {
+	switch o.Type {
+	case SetTag:
+		return tx.Tag != o.Tag
+	case ClearTag:
+		return tx.Tag != ""
+	case SetIgnored:
+		return tx.Ignored != *o.Ignored
+	case AddLabel:
+		return !contains(labels, o.Label)
+	case RemoveLabel:
+		return contains(labels, o.Label)
+	case DeleteManual:
+		return tx.IsManual()
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Batch) transactionIDs() []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(b.Items))
+	for _, item := range b.Items {
+		ids = append(ids, item.TransactionID)
+	}
+	return ids
+}
@@ -0,0 +1,171 @@
+package bulk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/label"
+	"github.com/lennardclaproth/my-finances-tracker/internal/money"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// memTransactions keeps transactions and their labels in memory.
+type memTransactions struct {
+	txs    map[uuid.UUID]*transaction.Transaction
+	labels map[uuid.UUID][]string
+	names  map[uuid.UUID]string
+}
+
+func (m *memTransactions) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (m *memTransactions) FetchByIDs(ctx context.Context, ids []uuid.UUID) ([]*transaction.Transaction, error) {
+	var res []*transaction.Transaction
+	for _, id := range ids {
+		if tx, ok := m.txs[id]; ok {
+			copied := *tx
+			res = append(res, &copied)
+		}
+	}
+	return res, nil
+}
+
+func (m *memTransactions) Select(ctx context.Context, f transaction.Filter) ([]*transaction.Transaction, error) {
+	return nil, nil
+}
+
+func (m *memTransactions) SetTag(ctx context.Context, ids []uuid.UUID, tag string, p transaction.TagProvenance) error {
+	return nil
+}
+
+func (m *memTransactions) SetIgnored(ctx context.Context, ids []uuid.UUID, ignored bool) error {
+	return nil
+}
+
+func (m *memTransactions) Delete(ctx context.Context, ids []uuid.UUID) error {
+	for _, id := range ids {
+		delete(m.txs, id)
+		delete(m.labels, id)
+	}
+	return nil
+}
+
+func (m *memTransactions) Create(ctx context.Context, tx *transaction.Transaction) error {
+	m.txs[tx.ID] = tx
+	return nil
+}
+
+func (m *memTransactions) Ensure(ctx context.Context, names []string) ([]*label.Label, error) {
+	var res []*label.Label
+	for _, name := range names {
+		l := &label.Label{ID: uuid.NewSHA1(uuid.Nil, []byte(name)), Name: name}
+		m.names[l.ID] = name
+		res = append(res, l)
+	}
+	return res, nil
+}
+
+func (m *memTransactions) FetchByNames(ctx context.Context, names []string) ([]*label.Label, error) {
+	return m.Ensure(ctx, names)
+}
+
+func (m *memTransactions) Attach(ctx context.Context, transactionIDs, labelIDs []uuid.UUID) error {
+	for _, id := range transactionIDs {
+		for _, labelID := range labelIDs {
+			m.labels[id] = append(m.labels[id], m.names[labelID])
+		}
+		sort.Strings(m.labels[id])
+	}
+	return nil
+}
+
+func (m *memTransactions) Detach(ctx context.Context, transactionIDs, labelIDs []uuid.UUID) error {
+	return nil
+}
+
+func (m *memTransactions) NamesFor(ctx context.Context, transactionIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	res := map[uuid.UUID][]string{}
+	for _, id := range transactionIDs {
+		res[id] = m.labels[id]
+	}
+	return res, nil
+}
+
+// memBatches stores batches as JSON, the way their items are stored.
+type memBatches map[uuid.UUID][]byte
+
+func (m memBatches) Create(ctx context.Context, b *Batch) error {
+	data, err := json.Marshal(b)
+	m[b.ID] = data
+	return err
+}
+
+func (m memBatches) FetchByID(ctx context.Context, id uuid.UUID) (*Batch, error) {
+	var b Batch
+	if err := json.Unmarshal(m[id], &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (m memBatches) MarkUndone(ctx context.Context, b *Batch) error {
+	return m.Create(ctx, b)
+}
+
+func TestDeleteManual(t *testing.T) {
+	date := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	manual, err := transaction.NewManualTransaction("Cash at the market", "Vegetables", transaction.CashOut, money.Amount{Cents: 1250, Currency: "EUR"}, date, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	manual.Tag = "groceries"
+	imported, err := transaction.NewTransaction("ALBERT HEIJN", "", "ABN AMRO", transaction.CashOut, money.Amount{Cents: 2500}, date, 1, uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &memTransactions{
+		txs:    map[uuid.UUID]*transaction.Transaction{manual.ID: manual, imported.ID: imported},
+		labels: map[uuid.UUID][]string{manual.ID: {"cash", "holiday"}, imported.ID: {"holiday"}},
+		names:  map[uuid.UUID]string{},
+	}
+	batches := memBatches{}
+	ids := []uuid.UUID{manual.ID, imported.ID}
+	op := Operation{Type: DeleteManual}
+
+	// only the manual entry is deleted, imported transactions are left alone
+	b, err := NewExecuteHandler(store, store, store, store, batches).Handle(context.Background(), ids, nil, op, false)
+	if err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if b.Affected != 1 {
+		t.Errorf("Handle() affected %d transactions, want 1", b.Affected)
+	}
+	if _, ok := store.txs[manual.ID]; ok {
+		t.Error("manual entry was not deleted")
+	}
+	if _, ok := store.txs[imported.ID]; !ok {
+		t.Error("imported transaction was deleted")
+	}
+
+	// undo brings the manual entry back with its tag and labels
+	if _, err := NewUndoHandler(store, batches, batches, store, store).Handle(context.Background(), b.ID); err != nil {
+		t.Fatalf("undo Handle() error = %v", err)
+	}
+	restored, ok := store.txs[manual.ID]
+	if !ok {
+		t.Fatal("manual entry was not restored")
+	}
+	if restored.Description != manual.Description || restored.AmountCents != manual.AmountCents || restored.Tag != "groceries" ||
+		restored.Checksum != manual.Checksum || !restored.IsManual() || !restored.Date.Equal(date) {
+		t.Errorf("restored %+v, want %+v", restored, manual)
+	}
+	if got := fmt.Sprint(store.labels[manual.ID]); got != "[cash holiday]" {
+		t.Errorf("restored labels %s, want [cash holiday]", got)
+	}
+}
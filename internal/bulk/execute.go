@@ -0,0 +1,134 @@
+package bulk
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// Single-use interfaces only used by ExecuteHandler
+
+type TransactionSelector interface {
+	FetchByIDs(ctx context.Context, ids []uuid.UUID) ([]*transaction.Transaction, error)
+	Select(ctx context.Context, f transaction.Filter) ([]*transaction.Transaction, error)
+}
+
+type BatchCreator interface {
+	Create(ctx context.Context, b *Batch) error
+}
+
+type ExecuteHandler struct {
+	tr TxRunner
+	ts TransactionSelector
+	tw TransactionWriter
+	lw LabelWriter
+	bc BatchCreator
+}
+
+func NewExecuteHandler(tr TxRunner, ts TransactionSelector, tw TransactionWriter, lw LabelWriter, bc BatchCreator) *ExecuteHandler {
+	return &ExecuteHandler{tr: tr, ts: ts, tw: tw, lw: lw, bc: bc}
+}
+
+// Handle applies the operation to the transactions with the given ids, or to
+// the transactions matching the filter when no ids are given, within a single
+// database transaction. With preview nothing is written and the returned
+// batch only tells how many transactions would change.
+func (h *ExecuteHandler) Handle(ctx context.Context, ids []uuid.UUID, f *transaction.Filter, op Operation, preview bool) (*Batch, error) {
+	op, err := op.Normalise()
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 && (f == nil || f.IsEmpty()) {
+		return nil, ErrNoSelection
+	}
+	b := &Batch{ID: uuid.New(), Operation: op, CreatedAt: time.Now().UTC()}
+	err = h.tr.WithTx(ctx, func(ctx context.Context) error {
+		txs, err := h.selectTransactions(ctx, ids, f)
+		if err != nil {
+			return err
+		}
+		var labels map[uuid.UUID][]string
+		if op.Type == AddLabel || op.Type == RemoveLabel || op.Type == DeleteManual {
+			if labels, err = h.lw.NamesFor(ctx, transactionIDs(txs)); err != nil {
+				return err
+			}
+		}
+		for _, tx := range txs {
+			if !op.changes(tx, labels[tx.ID]) {
+				continue
+			}
+			before := State{Tag: tx.Tag, Provenance: tx.TagProvenance, Ignored: tx.Ignored}
+			if op.Type == DeleteManual {
+				before.Transaction = tx
+				before.Labels = labels[tx.ID]
+			}
+			b.Items = append(b.Items, Item{TransactionID: tx.ID, Before: before})
+		}
+		b.Affected = len(b.Items)
+		if preview || b.Affected == 0 {
+			return nil
+		}
+		if err := h.apply(ctx, op, b.transactionIDs()); err != nil {
+			return err
+		}
+		return h.bc.Create(ctx, b)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (h *ExecuteHandler) selectTransactions(ctx context.Context, ids []uuid.UUID, f *transaction.Filter) ([]*transaction.Transaction, error) {
+	if len(ids) == 0 {
+		return h.ts.Select(ctx, *f)
+	}
+	txs, err := h.ts.FetchByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	unique := map[uuid.UUID]bool{}
+	for _, id := range ids {
+		unique[id] = true
+	}
+	if len(txs) != len(unique) {
+		return nil, ErrTransactionNotFound
+	}
+	return txs, nil
+}
+
+func (h *ExecuteHandler) apply(ctx context.Context, op Operation, ids []uuid.UUID) error {
+	switch op.Type {
+	case SetTag:
+		return h.tw.SetTag(ctx, ids, op.Tag, transaction.ManualTag())
+	case ClearTag:
+		return h.tw.SetTag(ctx, ids, "", transaction.TagProvenance{})
+	case SetIgnored:
+		return h.tw.SetIgnored(ctx, ids, *op.Ignored)
+	case AddLabel:
+		labels, err := h.lw.Ensure(ctx, []string{op.Label})
+		if err != nil {
+			return err
+		}
+		return h.lw.Attach(ctx, ids, []uuid.UUID{labels[0].ID})
+	case RemoveLabel:
+		labels, err := h.lw.FetchByNames(ctx, []string{op.Label})
+		if err != nil || len(labels) == 0 {
+			return err
+		}
+		return h.lw.Detach(ctx, ids, []uuid.UUID{labels[0].ID})
+	case DeleteManual:
+		return h.tw.Delete(ctx, ids)
+	}
+	return ErrInvalidOperation
+}
+
+func transactionIDs(txs []*transaction.Transaction) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(txs))
+	for _, tx := range txs {
+		ids = append(ids, tx.ID)
+	}
+	return ids
+}
@@ -0,0 +1,104 @@
+package bulk
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Single-use interfaces only used by UndoHandler
+
+type BatchFetcher interface {
+	FetchByID(ctx context.Context, id uuid.UUID) (*Batch, error)
+}
+
+type BatchUndoneMarker interface {
+	MarkUndone(ctx context.Context, b *Batch) error
+}
+
+type UndoHandler struct {
+	tr TxRunner
+	bf BatchFetcher
+	bu BatchUndoneMarker
+	tw TransactionWriter
+	lw LabelWriter
+}
+
+func NewUndoHandler(tr TxRunner, bf BatchFetcher, bu BatchUndoneMarker, tw TransactionWriter, lw LabelWriter) *UndoHandler {
+	return &UndoHandler{tr: tr, bf: bf, bu: bu, tw: tw, lw: lw}
+}
+
+// Handle restores the transactions changed by the batch to the state they
+// had before it. Changes made to those transactions after the batch are
+// overwritten.
+func (h *UndoHandler) Handle(ctx context.Context, id uuid.UUID) (*Batch, error) {
+	var b *Batch
+	err := h.tr.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		if b, err = h.bf.FetchByID(ctx, id); err != nil {
+			return err
+		}
+		if b.UndoneAt != nil {
+			return ErrAlreadyUndone
+		}
+		if err := h.revert(ctx, b); err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		b.UndoneAt = &now
+		return h.bu.MarkUndone(ctx, b)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (h *UndoHandler) revert(ctx context.Context, b *Batch) error {
+	ids := b.transactionIDs()
+	switch b.Operation.Type {
+	case SetTag, ClearTag:
+		// every transaction gets its own tag and provenance back
+		for _, item := range b.Items {
+			if err := h.tw.SetTag(ctx, []uuid.UUID{item.TransactionID}, item.Before.Tag, item.Before.Provenance); err != nil {
+				return err
+			}
+		}
+	case SetIgnored:
+		return h.tw.SetIgnored(ctx, ids, !*b.Operation.Ignored)
+	case AddLabel:
+		labels, err := h.lw.FetchByNames(ctx, []string{b.Operation.Label})
+		if err != nil || len(labels) == 0 {
+			return err
+		}
+		return h.lw.Detach(ctx, ids, []uuid.UUID{labels[0].ID})
+	case RemoveLabel:
+		labels, err := h.lw.Ensure(ctx, []string{b.Operation.Label})
+		if err != nil {
+			return err
+		}
+		return h.lw.Attach(ctx, ids, []uuid.UUID{labels[0].ID})
+	case DeleteManual:
+		for _, item := range b.Items {
+			if err := h.tw.Create(ctx, item.Before.Transaction); err != nil {
+				return err
+			}
+			if len(item.Before.Labels) == 0 {
+				continue
+			}
+			labels, err := h.lw.Ensure(ctx, item.Before.Labels)
+			if err != nil {
+				return err
+			}
+			labelIDs := make([]uuid.UUID, 0, len(labels))
+			for _, l := range labels {
+				labelIDs = append(labelIDs, l.ID)
+			}
+			if err := h.lw.Attach(ctx, []uuid.UUID{item.TransactionID}, labelIDs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package category
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Type string
+
+const (
+	Income  Type = "income"
+	Expense Type = "expense"
+)
+
+// Uncategorised is the slug of the system category for transactions that
+// could not be categorised. It replaces the former "unk" tag.
+const Uncategorised = "uncategorised"
+
+// Category is a node in the category tree. Transactions reference categories
+// by slug through their tag, so the slug follows the name and renaming retags
+// everything referencing the old slug.
+type Category struct {
+	ID        uuid.UUID  `db:"id"`
+	Slug      string     `db:"slug"`
+	Name      string     `db:"name"`
+	ParentID  *uuid.UUID `db:"parent_id"`
+	Type      Type       `db:"type"`
+	Colour    string     `db:"colour"`
+	Icon      string     `db:"icon"`
+	Position  int        `db:"position"`
+	System    bool       `db:"system"`
+	CreatedAt time.Time  `db:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at"`
+}
+
+var (
+	ErrCategoryNotFound = fmt.Errorf("category not found")
+	ErrInvalidName      = fmt.Errorf("category name must contain at least one letter or digit")
+	ErrInvalidType      = fmt.Errorf("category type must be income or expense")
+	ErrInvalidColour    = fmt.Errorf("category colour must be a hex colour like #1e90ff")
+	ErrSlugTaken        = fmt.Errorf("a category with this name already exists")
+	ErrTypeMismatch     = fmt.Errorf("a subcategory must have the type of its parent")
+	ErrCycle            = fmt.Errorf("a category cannot be moved below itself")
+	ErrSystemCategory   = fmt.Errorf("system categories cannot be renamed, moved, merged or deleted")
+	ErrMergeIntoSelf    = fmt.Errorf("cannot merge a category into itself")
+	ErrHasChildren      = fmt.Errorf("category still has subcategories")
+	ErrInvalidOrder     = fmt.Errorf("categories to reorder must be all siblings of the same parent")
+)
+
+// Shared interfaces used by multiple use cases
+
+type CategoryFetcher interface {
+	FetchByID(ctx context.Context, id uuid.UUID) (*Category, error)
+}
+
+type CategoryLister interface {
+	List(ctx context.Context) ([]*Category, error)
+}
+
+type CategoryUpdater interface {
+	Update(ctx context.Context, c *Category) error
+}
+
+type CategoryDeleter interface {
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type ReferenceRetagger interface {
+	// Retag moves everything referencing the category slug from to the slug
+	// to: transactions, rule actions, rule suggestions and counterparty
+	// default tags.
+	Retag(ctx context.Context, from, to string) error
+}
+
+// TxRunner runs fn within a single database transaction.
+type TxRunner interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slugify derives the slug of a category name, "Eating out" becomes
+// "eating-out".
+func Slugify(name string) string {
+	return strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(strings.TrimSpace(name)), "-"), "-")
+}
+
+var hexColour = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// NewCategory creates a category below parent, a nil parent creates a top
+// level category. An empty type inherits the type of the parent.
+func NewCategory(name string, parent *Category, t Type, colour, icon string) (*Category, error) {
+	c := &Category{
+		ID:        uuid.New(),
+		Type:      t,
+		Icon:      strings.TrimSpace(icon),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+	}
+	if err := c.Rename(name); err != nil {
+		return nil, err
+	}
+	if err := c.SetColour(colour); err != nil {
+		return nil, err
+	}
+	if parent != nil {
+		c.ParentID = &parent.ID
+		if c.Type == "" {
+			c.Type = parent.Type
+		}
+		if c.Type != parent.Type {
+			return nil, ErrTypeMismatch
+		}
+	}
+	if c.Type != Income && c.Type != Expense {
+		return nil, ErrInvalidType
+	}
+	return c, nil
+}
+
+// Rename changes the name and with it the slug of the category.
+func (c *Category) Rename(name string) error {
+	name = strings.TrimSpace(name)
+	slug := Slugify(name)
+	if slug == "" {
+		return ErrInvalidName
+	}
+	if c.System && slug != c.Slug {
+		return ErrSystemCategory
+	}
+	c.Name = name
+	c.Slug = slug
+	c.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// SetColour sets the display colour, an empty colour clears it.
+func (c *Category) SetColour(colour string) error {
+	colour = strings.TrimSpace(colour)
+	if colour != "" && !hexColour.MatchString(colour) {
+		return ErrInvalidColour
+	}
+	c.Colour = strings.ToLower(colour)
+	return nil
+}
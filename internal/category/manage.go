@@ -0,0 +1,230 @@
+package category
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Single-use interfaces only used by CreateHandler
+
+type CategoryCreator interface {
+	Create(ctx context.Context, c *Category) error
+}
+
+type CreateHandler struct {
+	tr TxRunner
+	cl CategoryLister
+	cc CategoryCreator
+}
+
+func NewCreateHandler(tr TxRunner, cl CategoryLister, cc CategoryCreator) *CreateHandler {
+	return &CreateHandler{tr: tr, cl: cl, cc: cc}
+}
+
+// Handle creates a category below the parent with parentID, a nil parentID
+// creates a top level category. New categories are placed after their
+// siblings.
+func (h *CreateHandler) Handle(ctx context.Context, name string, parentID *uuid.UUID, t Type, colour, icon string) (*Category, error) {
+	var c *Category
+	err := h.tr.WithTx(ctx, func(ctx context.Context) error {
+		all, err := h.cl.List(ctx)
+		if err != nil {
+			return err
+		}
+		tree := NewTree(all)
+		var parent *Category
+		if parentID != nil {
+			if parent = tree.byID[*parentID]; parent == nil {
+				return ErrCategoryNotFound
+			}
+		}
+		if c, err = NewCategory(name, parent, t, colour, icon); err != nil {
+			return err
+		}
+		if tree.BySlug(c.Slug) != nil {
+			return ErrSlugTaken
+		}
+		c.Position = len(tree.siblings(c.ParentID))
+		return h.cc.Create(ctx, c)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Changes holds the fields of a category to update, nil fields are left
+// untouched. A ParentID of uuid.Nil moves the category to the top level.
+type Changes struct {
+	Name     *string
+	ParentID *uuid.UUID
+	Type     *Type
+	Colour   *string
+	Icon     *string
+}
+
+type UpdateHandler struct {
+	tr  TxRunner
+	cl  CategoryLister
+	cu  CategoryUpdater
+	ref ReferenceRetagger
+}
+
+func NewUpdateHandler(tr TxRunner, cl CategoryLister, cu CategoryUpdater, ref ReferenceRetagger) *UpdateHandler {
+	return &UpdateHandler{tr: tr, cl: cl, cu: cu, ref: ref}
+}
+
+// Handle applies the changes to the category. Renaming changes the slug, all
+// transactions, rules and counterparties referencing the old slug follow.
+// Changing the type of a category also changes the type of its subcategories.
+func (h *UpdateHandler) Handle(ctx context.Context, id uuid.UUID, ch Changes) (*Category, error) {
+	var c *Category
+	err := h.tr.WithTx(ctx, func(ctx context.Context) error {
+		all, err := h.cl.List(ctx)
+		if err != nil {
+			return err
+		}
+		tree := NewTree(all)
+		if c = tree.byID[id]; c == nil {
+			return ErrCategoryNotFound
+		}
+		oldSlug := c.Slug
+
+		if ch.Name != nil {
+			if err := c.Rename(*ch.Name); err != nil {
+				return err
+			}
+			if other := tree.BySlug(c.Slug); other != nil && other.ID != c.ID {
+				return ErrSlugTaken
+			}
+		}
+		if ch.Colour != nil {
+			if err := c.SetColour(*ch.Colour); err != nil {
+				return err
+			}
+		}
+		if ch.Icon != nil {
+			c.Icon = strings.TrimSpace(*ch.Icon)
+		}
+		if ch.ParentID != nil {
+			if err := h.move(tree, c, *ch.ParentID); err != nil {
+				return err
+			}
+		}
+		if ch.Type != nil && *ch.Type != c.Type {
+			if *ch.Type != Income && *ch.Type != Expense {
+				return ErrInvalidType
+			}
+			if c.ParentID != nil {
+				return ErrTypeMismatch
+			}
+			if err := h.setSubtreeType(ctx, tree, c, *ch.Type); err != nil {
+				return err
+			}
+		}
+
+		c.UpdatedAt = time.Now().UTC()
+		if err := h.cu.Update(ctx, c); err != nil {
+			return err
+		}
+		if c.Slug != oldSlug {
+			return h.ref.Retag(ctx, oldSlug, c.Slug)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// move places c as the last child of the category with parentID.
+func (h *UpdateHandler) move(tree *Tree, c *Category, parentID uuid.UUID) error {
+	current := uuid.Nil
+	if c.ParentID != nil {
+		current = *c.ParentID
+	}
+	if parentID == current {
+		return nil
+	}
+	if c.System {
+		return ErrSystemCategory
+	}
+	if parentID == uuid.Nil {
+		c.ParentID = nil
+	} else {
+		parent := tree.byID[parentID]
+		if parent == nil {
+			return ErrCategoryNotFound
+		}
+		if tree.IsDescendant(parent.ID, c.ID) {
+			return ErrCycle
+		}
+		if parent.Type != c.Type {
+			return ErrTypeMismatch
+		}
+		c.ParentID = &parent.ID
+	}
+	c.Position = len(tree.siblings(c.ParentID))
+	return nil
+}
+
+// setSubtreeType sets the type of c and stores the new type of all its
+// descendants, c itself is stored by the caller.
+func (h *UpdateHandler) setSubtreeType(ctx context.Context, tree *Tree, c *Category, t Type) error {
+	c.Type = t
+	for _, child := range tree.Children(c.ID) {
+		if err := h.setSubtreeType(ctx, tree, child, t); err != nil {
+			return err
+		}
+		child.UpdatedAt = time.Now().UTC()
+		if err := h.cu.Update(ctx, child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type DeleteHandler struct {
+	tr  TxRunner
+	cl  CategoryLister
+	cd  CategoryDeleter
+	ref ReferenceRetagger
+}
+
+func NewDeleteHandler(tr TxRunner, cl CategoryLister, cd CategoryDeleter, ref ReferenceRetagger) *DeleteHandler {
+	return &DeleteHandler{tr: tr, cl: cl, cd: cd, ref: ref}
+}
+
+// Handle deletes a category without subcategories. Its transactions move to
+// the parent category, or to uncategorised for a top level category.
+func (h *DeleteHandler) Handle(ctx context.Context, id uuid.UUID) error {
+	return h.tr.WithTx(ctx, func(ctx context.Context) error {
+		all, err := h.cl.List(ctx)
+		if err != nil {
+			return err
+		}
+		tree := NewTree(all)
+		c := tree.byID[id]
+		if c == nil {
+			return ErrCategoryNotFound
+		}
+		if c.System {
+			return ErrSystemCategory
+		}
+		if len(tree.Children(c.ID)) > 0 {
+			return ErrHasChildren
+		}
+		to := Uncategorised
+		if c.ParentID != nil {
+			to = tree.byID[*c.ParentID].Slug
+		}
+		if err := h.ref.Retag(ctx, c.Slug, to); err != nil {
+			return err
+		}
+		return h.cd.Delete(ctx, c.ID)
+	})
+}
@@ -0,0 +1,157 @@
+package category
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MergeHandler struct {
+	tr  TxRunner
+	cl  CategoryLister
+	cu  CategoryUpdater
+	cd  CategoryDeleter
+	ref ReferenceRetagger
+}
+
+func NewMergeHandler(tr TxRunner, cl CategoryLister, cu CategoryUpdater, cd CategoryDeleter, ref ReferenceRetagger) *MergeHandler {
+	return &MergeHandler{tr: tr, cl: cl, cu: cu, cd: cd, ref: ref}
+}
+
+// Handle merges the source categories into the target. The target takes over
+// their transactions, rules and subcategories after which the sources are
+// deleted. Subcategories taken over get the type of the target.
+func (h *MergeHandler) Handle(ctx context.Context, targetID uuid.UUID, sourceIDs []uuid.UUID) (*Category, error) {
+	var target *Category
+	err := h.tr.WithTx(ctx, func(ctx context.Context) error {
+		all, err := h.cl.List(ctx)
+		if err != nil {
+			return err
+		}
+		tree := NewTree(all)
+		if target = tree.byID[targetID]; target == nil {
+			return ErrCategoryNotFound
+		}
+		position := len(tree.Children(target.ID))
+		for _, id := range sourceIDs {
+			if id == targetID {
+				return ErrMergeIntoSelf
+			}
+			source := tree.byID[id]
+			if source == nil {
+				return ErrCategoryNotFound
+			}
+			if source.System {
+				return ErrSystemCategory
+			}
+			if tree.IsDescendant(target.ID, source.ID) {
+				return ErrCycle
+			}
+			for _, child := range tree.Children(source.ID) {
+				child.ParentID = &target.ID
+				child.Position = position
+				position++
+				if err := h.retype(ctx, tree, child, target.Type); err != nil {
+					return err
+				}
+			}
+			if err := h.ref.Retag(ctx, source.Slug, target.Slug); err != nil {
+				return err
+			}
+			if err := h.cd.Delete(ctx, source.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// retype stores c and its descendants with type t.
+func (h *MergeHandler) retype(ctx context.Context, tree *Tree, c *Category, t Type) error {
+	c.Type = t
+	c.UpdatedAt = time.Now().UTC()
+	if err := h.cu.Update(ctx, c); err != nil {
+		return err
+	}
+	for _, child := range tree.Children(c.ID) {
+		if err := h.retype(ctx, tree, child, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type ReorderHandler struct {
+	tr TxRunner
+	cl CategoryLister
+	cu CategoryUpdater
+}
+
+func NewReorderHandler(tr TxRunner, cl CategoryLister, cu CategoryUpdater) *ReorderHandler {
+	return &ReorderHandler{tr: tr, cl: cl, cu: cu}
+}
+
+// Handle puts the sibling categories with the given ids in the given order.
+// Siblings that are not listed keep their relative order after the listed
+// ones.
+func (h *ReorderHandler) Handle(ctx context.Context, ids []uuid.UUID) ([]*Category, error) {
+	var ordered []*Category
+	err := h.tr.WithTx(ctx, func(ctx context.Context) error {
+		all, err := h.cl.List(ctx)
+		if err != nil {
+			return err
+		}
+		tree := NewTree(all)
+		if len(ids) == 0 {
+			return ErrInvalidOrder
+		}
+		first := tree.byID[ids[0]]
+		if first == nil {
+			return ErrCategoryNotFound
+		}
+		listed := map[uuid.UUID]bool{}
+		for _, id := range ids {
+			c := tree.byID[id]
+			if c == nil {
+				return ErrCategoryNotFound
+			}
+			if listed[id] || !sameParent(c, first) {
+				return ErrInvalidOrder
+			}
+			listed[id] = true
+			ordered = append(ordered, c)
+		}
+		for _, c := range tree.siblings(first.ParentID) {
+			if !listed[c.ID] {
+				ordered = append(ordered, c)
+			}
+		}
+		for i, c := range ordered {
+			if c.Position == i {
+				continue
+			}
+			c.Position = i
+			c.UpdatedAt = time.Now().UTC()
+			if err := h.cu.Update(ctx, c); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ordered, nil
+}
+
+func sameParent(a, b *Category) bool {
+	if a.ParentID == nil || b.ParentID == nil {
+		return a.ParentID == nil && b.ParentID == nil
+	}
+	return *a.ParentID == *b.ParentID
+}
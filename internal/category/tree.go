@@ -0,0 +1,146 @@
+package category
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// Tree is an in-memory view of all categories.
+type Tree struct {
+	byID     map[uuid.UUID]*Category
+	bySlug   map[string]*Category
+	children map[uuid.UUID][]*Category
+	roots    []*Category
+}
+
+// NewTree builds the tree of the given categories. Siblings are ordered by
+// position and name.
+func NewTree(categories []*Category) *Tree {
+	t := &Tree{
+		byID:     make(map[uuid.UUID]*Category, len(categories)),
+		bySlug:   make(map[string]*Category, len(categories)),
+		children: map[uuid.UUID][]*Category{},
+	}
+	for _, c := range categories {
+		t.byID[c.ID] = c
+		t.bySlug[c.Slug] = c
+	}
+	for _, c := range categories {
+		if c.ParentID == nil || t.byID[*c.ParentID] == nil {
+			t.roots = append(t.roots, c)
+			continue
+		}
+		t.children[*c.ParentID] = append(t.children[*c.ParentID], c)
+	}
+	sortSiblings(t.roots)
+	for _, cs := range t.children {
+		sortSiblings(cs)
+	}
+	return t
+}
+
+func sortSiblings(cs []*Category) {
+	slices.SortStableFunc(cs, func(a, b *Category) int {
+		if a.Position != b.Position {
+			return a.Position - b.Position
+		}
+		if a.Name < b.Name {
+			return -1
+		}
+		if a.Name > b.Name {
+			return 1
+		}
+		return 0
+	})
+}
+
+// ByID returns the category with the given id or nil.
+func (t *Tree) ByID(id uuid.UUID) *Category {
+	return t.byID[id]
+}
+
+// BySlug returns the category with the given slug or nil.
+func (t *Tree) BySlug(slug string) *Category {
+	return t.bySlug[slug]
+}
+
+// Roots returns the top level categories.
+func (t *Tree) Roots() []*Category {
+	return t.roots
+}
+
+// Children returns the direct subcategories of the category with the given id.
+func (t *Tree) Children(id uuid.UUID) []*Category {
+	return t.children[id]
+}
+
+// siblings returns the categories below the parent with parentID, nil for
+// the top level.
+func (t *Tree) siblings(parentID *uuid.UUID) []*Category {
+	if parentID == nil {
+		return t.roots
+	}
+	return t.children[*parentID]
+}
+
+// IsDescendant reports whether the category with id is ancestor or one of
+// its subcategories.
+func (t *Tree) IsDescendant(id, ancestor uuid.UUID) bool {
+	for c := t.byID[id]; c != nil; {
+		if c.ID == ancestor {
+			return true
+		}
+		if c.ParentID == nil {
+			return false
+		}
+		c = t.byID[*c.ParentID]
+	}
+	return false
+}
+
+// Totals are the cash flows of a category.
+type Totals struct {
+	InCents  int64
+	OutCents int64
+}
+
+func (t Totals) add(o Totals) Totals {
+	return Totals{InCents: t.InCents + o.InCents, OutCents: t.OutCents + o.OutCents}
+}
+
+// Node is a category with its own totals and the totals rolled up from its
+// subcategories.
+type Node struct {
+	Category *Category
+	Own      Totals
+	Total    Totals
+	Children []*Node
+}
+
+// Rollup attaches the totals per category slug to the tree and rolls them up
+// to the parent categories. Totals of unknown slugs are counted as
+// uncategorised.
+func (t *Tree) Rollup(totals map[string]Totals) []*Node {
+	own := map[string]Totals{}
+	for slug, tot := range totals {
+		if t.bySlug[slug] == nil {
+			slug = Uncategorised
+		}
+		own[slug] = own[slug].add(tot)
+	}
+	var build func(cs []*Category) []*Node
+	build = func(cs []*Category) []*Node {
+		nodes := make([]*Node, 0, len(cs))
+		for _, c := range cs {
+			n := &Node{Category: c, Own: own[c.Slug], Children: build(t.children[c.ID])}
+			n.Total = n.Own
+			for _, child := range n.Children {
+				n.Total = n.Total.add(child.Total)
+			}
+			nodes = append(nodes, n)
+		}
+		return nodes
+	}
+	return build(t.roots)
+}
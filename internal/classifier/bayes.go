@@ -0,0 +1,133 @@
+package classifier
+
+import (
+	"math"
+	"sort"
+)
+
+// Document is a labelled training example.
+type Document struct {
+	Tokens []string
+	Label  string
+}
+
+// Prediction is the most likely label together with its posterior probability.
+type Prediction struct {
+	Label      string
+	Confidence float64
+}
+
+// Model is a multinomial naive Bayes classifier with Laplace smoothing. Token
+// counts are weighted by inverse document frequency so tokens that show up in
+// every transaction (e.g. "pay") carry less weight than distinctive ones.
+type Model struct {
+	labels      []string
+	priors      map[string]float64            // log P(label)
+	likelihoods map[string]map[string]float64 // log P(token | label)
+	unseen      map[string]float64            // log P(unseen token | label)
+	idf         map[string]float64
+	vocabulary  int
+	samples     int
+}
+
+// Train fits a model on the given documents.
+func Train(docs []Document) *Model {
+	m := &Model{
+		priors:      map[string]float64{},
+		likelihoods: map[string]map[string]float64{},
+		unseen:      map[string]float64{},
+		idf:         map[string]float64{},
+		samples:     len(docs),
+	}
+	if len(docs) == 0 {
+		return m
+	}
+
+	// document frequencies for the idf weights
+	df := map[string]int{}
+	for _, d := range docs {
+		seen := map[string]bool{}
+		for _, t := range d.Tokens {
+			if !seen[t] {
+				seen[t] = true
+				df[t]++
+			}
+		}
+	}
+	for t, n := range df {
+		m.idf[t] = math.Log(float64(len(docs)+1)/float64(n+1)) + 1
+	}
+	m.vocabulary = len(df)
+
+	labelDocs := map[string]int{}
+	weights := map[string]map[string]float64{}
+	totals := map[string]float64{}
+	for _, d := range docs {
+		labelDocs[d.Label]++
+		if weights[d.Label] == nil {
+			weights[d.Label] = map[string]float64{}
+		}
+		for _, t := range d.Tokens {
+			w := m.idf[t]
+			weights[d.Label][t] += w
+			totals[d.Label] += w
+		}
+	}
+
+	for label, n := range labelDocs {
+		m.labels = append(m.labels, label)
+		m.priors[label] = math.Log(float64(n) / float64(len(docs)))
+		denominator := totals[label] + float64(m.vocabulary)
+		m.likelihoods[label] = make(map[string]float64, len(weights[label]))
+		for t, w := range weights[label] {
+			m.likelihoods[label][t] = math.Log((w + 1) / denominator)
+		}
+		m.unseen[label] = math.Log(1 / denominator)
+	}
+	sort.Strings(m.labels)
+	return m
+}
+
+// Samples returns the number of documents the model was trained on.
+func (m *Model) Samples() int {
+	return m.samples
+}
+
+// Predict returns the most likely label for the tokens. The confidence is the
+// posterior probability of that label among all known labels. A model without
+// labels returns an empty prediction.
+func (m *Model) Predict(tokens []string) Prediction {
+	if len(m.labels) == 0 {
+		return Prediction{}
+	}
+	scores := make(map[string]float64, len(m.labels))
+	for _, label := range m.labels {
+		score := m.priors[label]
+		for _, t := range tokens {
+			idf, known := m.idf[t]
+			if !known {
+				// tokens never seen during training say nothing about the label
+				continue
+			}
+			l, ok := m.likelihoods[label][t]
+			if !ok {
+				l = m.unseen[label]
+			}
+			score += idf * l
+		}
+		scores[label] = score
+	}
+
+	best := m.labels[0]
+	for _, label := range m.labels[1:] {
+		if scores[label] > scores[best] {
+			best = label
+		}
+	}
+	// normalise the log scores into a posterior with the log-sum-exp trick
+	var sum float64
+	for _, s := range scores {
+		sum += math.Exp(s - scores[best])
+	}
+	return Prediction{Label: best, Confidence: 1 / sum}
+}
@@ -0,0 +1,97 @@
+package classifier
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTrainAndPredictByHand(t *testing.T) {
+	// two documents of one token each: both tokens have an idf of
+	// ln(3/2) + 1, a label gives its own token (idf + 1) / (idf + 2) and the
+	// other 1 / (idf + 2)
+	m := Train([]Document{{Tokens: []string{"rent"}, Label: "housing"}, {Tokens: []string{"milk"}, Label: "groceries"}})
+	idf := math.Log(1.5) + 1
+	own := idf * math.Log((idf+1)/(idf+2))
+	other := idf * math.Log(1/(idf+2))
+	want := 1 / (1 + math.Exp(other-own))
+
+	got := m.Predict([]string{"rent"})
+	if got.Label != "housing" || math.Abs(got.Confidence-want) > 1e-12 {
+		t.Errorf("Predict(rent) = %+v, want housing with %f", got, want)
+	}
+	if m.Samples() != 2 {
+		t.Errorf("Samples() = %d, want 2", m.Samples())
+	}
+}
+
+// corpus is a fixed set of tagged transactions as tokens.
+var corpus = []Document{
+	{Tokens: []string{"ah", "dir:out", "amt:6"}, Label: "groceries"},
+	{Tokens: []string{"ah", "dir:out", "amt:7"}, Label: "groceries"},
+	{Tokens: []string{"albert", "heijn", "dir:out", "amt:6"}, Label: "groceries"},
+	{Tokens: []string{"jumbo", "dir:out", "amt:6"}, Label: "groceries"},
+	{Tokens: []string{"lidl", "dir:out", "amt:5"}, Label: "groceries"},
+	{Tokens: []string{"ns", "groep", "dir:out", "amt:5"}, Label: "transport"},
+	{Tokens: []string{"ns", "reizigers", "dir:out", "amt:6"}, Label: "transport"},
+	{Tokens: []string{"gvb", "dir:out", "amt:5"}, Label: "transport"},
+	{Tokens: []string{"shell", "dir:out", "amt:7"}, Label: "transport"},
+	{Tokens: []string{"netflix", "com", "dir:out", "amt:6"}, Label: "subscriptions"},
+	{Tokens: []string{"spotify", "dir:out", "amt:6"}, Label: "subscriptions"},
+	{Tokens: []string{"huur", "januari", "dir:out", "amt:10"}, Label: "housing"},
+	{Tokens: []string{"salaris", "dir:in", "amt:11"}, Label: "income"},
+	{Tokens: []string{"salaris", "dir:in", "amt:12"}, Label: "income"},
+}
+
+func TestPredict(t *testing.T) {
+	m := Train(corpus)
+	tests := []struct {
+		name          string
+		tokens        []string
+		want          string
+		minConfidence float64
+	}{
+		{"known merchant", []string{"ah", "dir:out", "amt:6"}, "groceries", 0.8},
+		{"other amount", []string{"jumbo", "dir:out", "amt:7"}, "groceries", 0.5},
+		{"shared word", []string{"ns", "dir:out", "amt:5"}, "transport", 0.7},
+		{"subscription", []string{"spotify", "dir:out", "amt:6"}, "subscriptions", 0.5},
+		{"income", []string{"salaris", "dir:in", "amt:11"}, "income", 0.9},
+		{"rent by its word", []string{"huur", "februari", "dir:out", "amt:10"}, "housing", 0.5},
+		// without any known token the most common label wins
+		{"unknown tokens", []string{"bakker", "bart"}, "groceries", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := m.Predict(tt.tokens)
+			if got.Label != tt.want {
+				t.Errorf("Predict() = %+v, want %s", got, tt.want)
+			}
+			if got.Confidence < tt.minConfidence || got.Confidence > 1 {
+				t.Errorf("Predict() confidence = %f, want at least %f", got.Confidence, tt.minConfidence)
+			}
+		})
+	}
+}
+
+func TestPredictConfidence(t *testing.T) {
+	m := Train(corpus)
+	// a distinctive token is more certain than the direction alone
+	merchant := m.Predict([]string{"ah", "dir:out"})
+	direction := m.Predict([]string{"dir:out"})
+	if merchant.Confidence <= direction.Confidence {
+		t.Errorf("confidence of ah = %f, of the direction alone = %f", merchant.Confidence, direction.Confidence)
+	}
+	// the prior of five groceries among fourteen documents
+	if got := m.Predict(nil); got.Label != "groceries" || math.Abs(got.Confidence-5.0/14) > 1e-12 {
+		t.Errorf("Predict(nil) = %+v, want groceries with %f", got, 5.0/14)
+	}
+}
+
+func TestPredictWithoutTraining(t *testing.T) {
+	m := Train(nil)
+	if got := m.Predict([]string{"ah"}); got != (Prediction{}) {
+		t.Errorf("Predict() = %+v, want an empty prediction", got)
+	}
+	if m.Samples() != 0 {
+		t.Errorf("Samples() = %d, want 0", m.Samples())
+	}
+}
@@ -0,0 +1,49 @@
+package classifier
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/counterparty"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// Tokens extracts the features of a transaction: the words of its normalised
+// description and note, its counterparty, direction and an order of magnitude
+// of the amount.
+func Tokens(tx *transaction.Transaction) []string {
+	tokens := words(counterparty.Normalise(tx.Description))
+	tokens = append(tokens, words(tx.Note)...)
+	if tx.CounterpartyID != nil {
+		tokens = append(tokens, "cp:"+tx.CounterpartyID.String())
+	}
+	tokens = append(tokens, "dir:"+string(tx.Direction))
+	tokens = append(tokens, fmt.Sprintf("amt:%d", amountBucket(tx.AmountCents)))
+	return tokens
+}
+
+// words splits text into lower case words of at least two letters, numbers
+// are dropped as they are mostly references and dates.
+func words(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	out := fields[:0]
+	for _, f := range fields {
+		if len([]rune(f)) >= 2 {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// amountBucket groups amounts per half order of magnitude, e.g. 1-3 euro,
+// 3-10 euro, 10-31 euro.
+func amountBucket(cents int64) int {
+	if cents <= 0 {
+		return 0
+	}
+	return int(math.Floor(math.Log10(float64(cents)) * 2))
+}
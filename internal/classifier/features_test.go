@@ -0,0 +1,51 @@
+package classifier
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+func TestTokens(t *testing.T) {
+	landlord := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	tests := []struct {
+		name string
+		tx   *transaction.Transaction
+		want []string
+	}{
+		{"normalised description", tagged("BEA AH 1234 AMSTERDAM", 1234, transaction.CashOut, ""), []string{"ah", "dir:out", "amt:6"}},
+		{"numbers and single letters dropped", &transaction.Transaction{Description: "Albert Heijn BV", Note: "weekly shop 2x", AmountCents: 8950, Direction: transaction.CashOut}, []string{"albert", "heijn", "weekly", "shop", "dir:out", "amt:7"}},
+		{"counterparty", &transaction.Transaction{Description: "/TRTP/SEPA OVERBOEKING/NAME/J Jansen/REMI/Huur", CounterpartyID: &landlord, AmountCents: 150000, Direction: transaction.CashOut}, []string{"jansen", "cp:" + landlord.String(), "dir:out", "amt:10"}},
+		{"income", tagged("Salaris januari", 350000, transaction.CashIn, ""), []string{"salaris", "januari", "dir:in", "amt:11"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Tokens(tt.tx); !slices.Equal(got, tt.want) {
+				t.Errorf("Tokens() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAmountBucket(t *testing.T) {
+	tests := []struct {
+		cents int64
+		want  int
+	}{
+		{-500, 0},
+		{0, 0},
+		{1, 0},
+		{100, 4},
+		{316, 4},
+		{317, 5},
+		{999, 5},
+		{1000, 6},
+	}
+	for _, tt := range tests {
+		if got := amountBucket(tt.cents); got != tt.want {
+			t.Errorf("amountBucket(%d) = %d, want %d", tt.cents, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package classifier
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// DefaultMinSamples is the number of tagged transactions needed before the
+// classifier makes predictions.
+const DefaultMinSamples = 20
+
+// Single-use interfaces only used by Service
+
+type TaggedFetcher interface {
+	// FetchTagged returns the most recent transactions that carry a tag that
+	// should be learned from: tags set by hand or by a rule, not the
+	// predictions of machine taggers.
+	FetchTagged(ctx context.Context, limit int) ([]*transaction.Transaction, error)
+}
+
+// Service holds the current model and retrains it from the tagged
+// transactions in storage. It is safe for concurrent use.
+type Service struct {
+	tf         TaggedFetcher
+	minSamples int
+	maxSamples int
+
+	mu        sync.RWMutex
+	model     *Model
+	trainedAt time.Time
+}
+
+func NewService(tf TaggedFetcher, minSamples int) *Service {
+	if minSamples <= 0 {
+		minSamples = DefaultMinSamples
+	}
+	return &Service{tf: tf, minSamples: minSamples, maxSamples: 20000, model: Train(nil)}
+}
+
+// Retrain fits a new model on the tagged transactions and swaps it in.
+func (s *Service) Retrain(ctx context.Context) (int, error) {
+	txs, err := s.tf.FetchTagged(ctx, s.maxSamples)
+	if err != nil {
+		return 0, err
+	}
+	docs := make([]Document, 0, len(txs))
+	for _, tx := range txs {
+		docs = append(docs, Document{Tokens: Tokens(tx), Label: tx.Tag})
+	}
+	model := Train(docs)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.model = model
+	s.trainedAt = time.Now().UTC()
+	return len(docs), nil
+}
+
+// Predict classifies the transaction. ok is false when the model has not seen
+// enough tagged transactions yet.
+func (s *Service) Predict(tx *transaction.Transaction) (p Prediction, ok bool) {
+	s.mu.RLock()
+	model := s.model
+	s.mu.RUnlock()
+	if model.Samples() < s.minSamples {
+		return Prediction{}, false
+	}
+	return model.Predict(Tokens(tx)), true
+}
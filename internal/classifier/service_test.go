@@ -0,0 +1,67 @@
+package classifier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+type fakeTagged []*transaction.Transaction
+
+func (f fakeTagged) FetchTagged(ctx context.Context, limit int) ([]*transaction.Transaction, error) {
+	return f[:min(limit, len(f))], nil
+}
+
+func tagged(description string, cents int64, direction transaction.CashFlowDirection, tag string) *transaction.Transaction {
+	return &transaction.Transaction{Description: description, AmountCents: cents, Direction: direction, Tag: tag}
+}
+
+func TestService(t *testing.T) {
+	txs := fakeTagged{
+		tagged("AH 1234 AMSTERDAM", 2350, transaction.CashOut, "groceries"),
+		tagged("BEA AH 5678 UTRECHT NLD", 1210, transaction.CashOut, "groceries"),
+		tagged("Jumbo 0421 Zwolle", 3120, transaction.CashOut, "groceries"),
+		tagged("LIDL 1021 BREDA", 870, transaction.CashOut, "groceries"),
+		tagged("NS GROEP IZ NS", 450, transaction.CashOut, "transport"),
+		tagged("NS Reizigers", 2590, transaction.CashOut, "transport"),
+		tagged("GVB 1234 AMSTERDAM", 320, transaction.CashOut, "transport"),
+		tagged("Netflix.com", 1399, transaction.CashOut, "subscriptions"),
+		tagged("Spotify AB", 1099, transaction.CashOut, "subscriptions"),
+		tagged("Salaris januari", 350000, transaction.CashIn, "income"),
+	}
+	s := NewService(txs, len(txs))
+	ah := tagged("AH 9999 DELFT", 1875, transaction.CashOut, "")
+	if _, ok := s.Predict(ah); ok {
+		t.Fatal("Predict() before training = ok, want not enough samples")
+	}
+	n, err := s.Retrain(context.Background())
+	if err != nil || n != len(txs) {
+		t.Fatalf("Retrain() = %d, %v, want %d", n, err, len(txs))
+	}
+
+	tests := []struct {
+		tx   *transaction.Transaction
+		want string
+	}{
+		{ah, "groceries"},
+		{tagged("NS GROEP IZ NS", 1290, transaction.CashOut, ""), "transport"},
+		{tagged("NETFLIX.COM AMSTERDAM", 1399, transaction.CashOut, ""), "subscriptions"},
+		{tagged("Salaris februari", 350000, transaction.CashIn, ""), "income"},
+	}
+	for _, tt := range tests {
+		got, ok := s.Predict(tt.tx)
+		if !ok || got.Label != tt.want {
+			t.Errorf("Predict(%q) = %+v, %v, want %s", tt.tx.Description, got, ok, tt.want)
+		}
+	}
+
+	// a service that needs more samples than there are keeps abstaining
+	strict := NewService(txs, len(txs)+1)
+	if _, err := strict.Retrain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := strict.Predict(ah); ok {
+		t.Error("Predict() with too few samples = ok")
+	}
+}
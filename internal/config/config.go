@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"time"
 
 	"go.yaml.in/yaml/v3"
 )
@@ -11,17 +12,177 @@ import (
 const configPath = "config.yaml"
 
 type Config struct {
-	Server      Server      `yaml:"server"`
-	Database    Database    `yaml:"database"`
-	Logging     Logging     `yaml:"logging"`
-	APM         APMConfig   `yaml:"apm"`
-	DiskStorage DiskStorage `yaml:"disk_storage"`
-	Agent       AgentConfig `yaml:"agent"`
+	Server        Server        `yaml:"server"`
+	Database      Database      `yaml:"database"`
+	Logging       Logging       `yaml:"logging"`
+	APM           APMConfig     `yaml:"apm"`
+	DiskStorage   DiskStorage   `yaml:"disk_storage"`
+	Agent         AgentConfig   `yaml:"agent"`
+	Refunds       Refunds       `yaml:"refunds"`
+	Learning      Learning      `yaml:"learning"`
+	Classifier    Classifier    `yaml:"classifier"`
+	Tagging       Tagging       `yaml:"tagging"`
+	Budgets       Budgets       `yaml:"budgets"`
+	Notifications Notifications `yaml:"notifications"`
+	Recurring     Recurring     `yaml:"recurring"`
+	NetWorth      NetWorth      `yaml:"networth"`
+	MarketData    MarketData    `yaml:"marketdata"`
+	FX            FX            `yaml:"fx"`
 }
 
 type AgentConfig struct {
 	AgentBaseURL      string `yaml:"agent_base_url"`
 	DefaultTagAgentID string `yaml:"default_tag_agent_id"`
+	// BatchSize is the number of untagged transactions claimed and sent to
+	// the taggers in a single request.
+	BatchSize int `yaml:"batch_size"`
+	// Concurrency is the number of tagger workers, each claims its own batch.
+	Concurrency int `yaml:"concurrency"`
+	// RequestsPerMinute limits the requests to remote taggers, shared by all
+	// workers. Zero disables the limit.
+	RequestsPerMinute int `yaml:"requests_per_minute"`
+	// ClaimTimeout is how long a claimed batch is reserved for a worker,
+	// claims of crashed workers are taken over after it.
+	ClaimTimeout time.Duration `yaml:"claim_timeout"`
+	// Method is how the message is sent to the agent: GET sends it in the
+	// message query parameter, POST as a JSON body {"message": ...}.
+	Method string `yaml:"method"`
+	// Timeout limits a single call to the agent.
+	Timeout time.Duration `yaml:"timeout"`
+	Retry   AgentRetry    `yaml:"retry"`
+	Breaker AgentBreaker  `yaml:"breaker"`
+	// Routes send matching transactions to another agent than the default
+	// one, the first matching route is used.
+	Routes []AgentRoute `yaml:"routes"`
+}
+
+// AgentRoute matches transactions on account and amount range, empty fields
+// match any transaction.
+type AgentRoute struct {
+	AgentID        string `yaml:"agent_id"`
+	Account        string `yaml:"account"`
+	MinAmountCents *int64 `yaml:"min_amount_cents"`
+	MaxAmountCents *int64 `yaml:"max_amount_cents"`
+}
+
+type AgentRetry struct {
+	// MaxAttempts is the number of attempts per call including the first.
+	MaxAttempts int           `yaml:"max_attempts"`
+	BaseDelay   time.Duration `yaml:"base_delay"`
+	MaxDelay    time.Duration `yaml:"max_delay"`
+}
+
+type AgentBreaker struct {
+	// FailureThreshold is the number of consecutive failures that opens the
+	// breaker.
+	FailureThreshold int           `yaml:"failure_threshold"`
+	Cooldown         time.Duration `yaml:"cooldown"`
+}
+
+type Refunds struct {
+	WindowDays int `yaml:"window_days"`
+}
+
+type Learning struct {
+	AutoCreateAfter int `yaml:"auto_create_after"`
+}
+
+type Classifier struct {
+	MinSamples      int           `yaml:"min_samples"`
+	RetrainInterval time.Duration `yaml:"retrain_interval"`
+}
+
+type Tagging struct {
+	// Chain lists the taggers to try in order: rules, classifier, agent and
+	// openai.
+	Chain         []string `yaml:"chain"`
+	MinConfidence float64  `yaml:"min_confidence"`
+	// ReviewBelow is the confidence under which machine tags are listed in
+	// the review queue.
+	ReviewBelow float64 `yaml:"review_below"`
+	// RetryUncategorisedAfter is how long to wait before tagging transactions
+	// a machine tagger left uncategorised again, zero disables retries.
+	RetryUncategorisedAfter time.Duration `yaml:"retry_uncategorised_after"`
+	// PromptTemplate is the path of the prompt template for the agent and
+	// openai taggers, the built-in template is used when empty.
+	PromptTemplate string `yaml:"prompt_template"`
+	// Examples is the number of similar tagged transactions added to the
+	// prompt per transaction.
+	Examples int    `yaml:"examples"`
+	OpenAI   OpenAI `yaml:"openai"`
+}
+
+type Budgets struct {
+	// AlertThresholds are the percentages of a budget at which an alert is
+	// sent, once per period.
+	AlertThresholds []int `yaml:"alert_thresholds"`
+	// CheckInterval is how often to look for changed transactions to
+	// evaluate the budgets against.
+	CheckInterval time.Duration `yaml:"check_interval"`
+}
+
+type Recurring struct {
+	Interval time.Duration `yaml:"interval"`
+	// LookbackMonths is how far back transactions are scanned, yearly
+	// payments need at least two years.
+	LookbackMonths int `yaml:"lookback_months"`
+	// Tolerance is the relative difference between amounts of the same
+	// recurring payment.
+	Tolerance float64 `yaml:"tolerance"`
+}
+
+type NetWorth struct {
+	// SnapshotInterval is how often the job checks for months to snapshot.
+	SnapshotInterval time.Duration `yaml:"snapshot_interval"`
+	// BackfillMonths is how many past months are snapshotted when missing,
+	// provisionally until they are confirmed.
+	BackfillMonths int `yaml:"backfill_months"`
+}
+
+type MarketData struct {
+	// Provider is http or file, prices are only imported by hand when
+	// empty.
+	Provider string `yaml:"provider"`
+	// URL is the endpoint of the http provider, see marketdata.HTTPProvider
+	// for its placeholders.
+	URL string `yaml:"url"`
+	// Headers are sent with every request of the http provider.
+	Headers map[string]string `yaml:"headers"`
+	Timeout time.Duration     `yaml:"timeout"`
+	// Dir holds the <SYMBOL>.csv files of the file provider.
+	Dir string `yaml:"dir"`
+	// Symbols are refreshed in addition to the symbols of net worth items
+	// and securities and the symbols that have prices.
+	Symbols []string `yaml:"symbols"`
+	// RefreshAt is the time of day in UTC (HH:MM) prices are refreshed.
+	RefreshAt string `yaml:"refresh_at"`
+	// BackfillDays is how many past days of prices are filled in.
+	BackfillDays      int `yaml:"backfill_days"`
+	RequestsPerMinute int `yaml:"requests_per_minute"`
+}
+
+type FX struct {
+	// ReportingCurrency is the ISO 4217 code reports aggregate amounts in,
+	// EUR when empty.
+	ReportingCurrency string `yaml:"reporting_currency"`
+	// RatesDir is scanned for ECB reference rate files (.csv, .xml or .zip),
+	// rates are only imported by hand when it is empty.
+	RatesDir     string        `yaml:"rates_dir"`
+	ScanInterval time.Duration `yaml:"scan_interval"`
+}
+
+type Notifications struct {
+	// WebhookURL receives notifications as JSON posts, notifications are
+	// only logged when it is empty.
+	WebhookURL string        `yaml:"webhook_url"`
+	Timeout    time.Duration `yaml:"timeout"`
+}
+
+type OpenAI struct {
+	BaseURL string        `yaml:"base_url"`
+	APIKey  string        `yaml:"api_key"`
+	Model   string        `yaml:"model"`
+	Timeout time.Duration `yaml:"timeout"`
 }
 
 type DiskStorage struct {
@@ -0,0 +1,93 @@
+package counterparty
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// Single-use interfaces only used by AssignHandler
+
+type CounterpartyResolver interface {
+	FetchByIBAN(ctx context.Context, iban string) (*Counterparty, error)
+	FetchByAlias(ctx context.Context, alias string) (*Counterparty, error)
+}
+
+// AssignHandler resolves the counterparty of a transaction, creating it when
+// it is seen for the first time.
+type AssignHandler struct {
+	cr CounterpartyResolver
+	cc CounterpartyCreator
+	cu CounterpartyUpdater
+}
+
+func NewAssignHandler(cr CounterpartyResolver, cc CounterpartyCreator, cu CounterpartyUpdater) *AssignHandler {
+	return &AssignHandler{cr: cr, cc: cc, cu: cu}
+}
+
+// Handle sets the counterparty of tx. A counterparty is looked up by IBAN
+// first and by normalised description second, newly seen IBANs and aliases
+// are learned on the way. When the transaction is not tagged yet the default
+// tag of the counterparty is applied.
+func (h *AssignHandler) Handle(ctx context.Context, tx *transaction.Transaction) error {
+	alias := Normalise(tx.Description)
+	iban := NormaliseIBAN(tx.CounterpartyIBAN)
+	if alias == "" && iban == "" {
+		return nil
+	}
+
+	cp, err := h.resolve(ctx, alias, iban)
+	if err != nil {
+		return err
+	}
+	if cp == nil {
+		name := DisplayName(alias)
+		if name == "" {
+			name = iban
+		}
+		if cp, err = NewCounterparty(name); err != nil {
+			return err
+		}
+		cp.AddAlias(alias)
+		cp.AddIBAN(iban)
+		if err := h.cc.Create(ctx, cp); err != nil {
+			return err
+		}
+	} else if (alias != "" && !slices.Contains(cp.Aliases, alias)) || (iban != "" && !slices.Contains(cp.IBANs, iban)) {
+		cp.AddAlias(alias)
+		cp.AddIBAN(iban)
+		if err := h.cu.Update(ctx, cp); err != nil {
+			return err
+		}
+	}
+
+	tx.CounterpartyID = &cp.ID
+	if tx.Tag == "" && cp.DefaultTag != "" {
+		tx.SetTag(cp.DefaultTag, transaction.NewTagProvenance(transaction.TagSourceRule, 1, cp.ID.String()))
+	}
+	return nil
+}
+
+func (h *AssignHandler) resolve(ctx context.Context, alias, iban string) (*Counterparty, error) {
+	if iban != "" {
+		cp, err := h.cr.FetchByIBAN(ctx, iban)
+		if err == nil {
+			return cp, nil
+		}
+		if !errors.Is(err, ErrCounterpartyNotFound) {
+			return nil, err
+		}
+	}
+	if alias != "" {
+		cp, err := h.cr.FetchByAlias(ctx, alias)
+		if err == nil {
+			return cp, nil
+		}
+		if !errors.Is(err, ErrCounterpartyNotFound) {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,159 @@
+package counterparty
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Counterparty is the merchant or payee on the other side of a transaction.
+// Aliases hold normalised descriptions (see Normalise) that resolve to the
+// counterparty, IBANs hold the account numbers it is known to use.
+type Counterparty struct {
+	ID         uuid.UUID `db:"id"`
+	Name       string    `db:"name"`
+	Aliases    []string  `db:"aliases"`
+	IBANs      []string  `db:"ibans"`
+	DefaultTag string    `db:"default_tag"`
+	LogoURL    string    `db:"logo_url"`
+	CreatedAt  time.Time `db:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+var (
+	ErrCounterpartyNotFound = fmt.Errorf("counterparty not found")
+	ErrInvalidName          = fmt.Errorf("counterparty name cannot be empty")
+	ErrMergeIntoSelf        = fmt.Errorf("cannot merge a counterparty into itself")
+	ErrNothingToSplit       = fmt.Errorf("at least one alias or IBAN of the counterparty must be split off")
+	ErrSplitEverything      = fmt.Errorf("cannot split off all aliases and IBANs of a counterparty")
+	ErrInvalidIBAN          = fmt.Errorf("invalid IBAN")
+)
+
+// Shared interfaces used by multiple use cases
+
+type CounterpartyCreator interface {
+	Create(ctx context.Context, cp *Counterparty) error
+}
+
+type CounterpartyFetcher interface {
+	FetchByID(ctx context.Context, id uuid.UUID) (*Counterparty, error)
+}
+
+type CounterpartyUpdater interface {
+	Update(ctx context.Context, cp *Counterparty) error
+}
+
+type CounterpartyDeleter interface {
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type TransactionReassigner interface {
+	// Reassign moves the given transactions to the counterparty with id to.
+	Reassign(ctx context.Context, ids []uuid.UUID, to uuid.UUID) error
+	// ReassignCounterparty moves all transactions of counterparty from to the
+	// counterparty to.
+	ReassignCounterparty(ctx context.Context, from, to uuid.UUID) error
+}
+
+// TxRunner runs fn within a single database transaction.
+type TxRunner interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// NewCounterparty creates a counterparty with a canonical name.
+func NewCounterparty(name string) (*Counterparty, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrInvalidName
+	}
+	return &Counterparty{
+		ID:        uuid.New(),
+		Name:      name,
+		Aliases:   []string{},
+		IBANs:     []string{},
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+	}, nil
+}
+
+// AddAlias adds a normalised alias if it is not known yet.
+func (c *Counterparty) AddAlias(alias string) {
+	alias = Normalise(alias)
+	if alias == "" || slices.Contains(c.Aliases, alias) {
+		return
+	}
+	c.Aliases = append(c.Aliases, alias)
+	c.UpdatedAt = time.Now().UTC()
+}
+
+// AddIBAN adds an IBAN if it is not known yet.
+func (c *Counterparty) AddIBAN(iban string) {
+	iban = NormaliseIBAN(iban)
+	if iban == "" || slices.Contains(c.IBANs, iban) {
+		return
+	}
+	c.IBANs = append(c.IBANs, iban)
+	c.UpdatedAt = time.Now().UTC()
+}
+
+// Absorb takes over the aliases and IBANs of other, used when merging.
+func (c *Counterparty) Absorb(other *Counterparty) {
+	for _, a := range other.Aliases {
+		c.AddAlias(a)
+	}
+	for _, i := range other.IBANs {
+		c.AddIBAN(i)
+	}
+	if c.DefaultTag == "" {
+		c.DefaultTag = other.DefaultTag
+	}
+	if c.LogoURL == "" {
+		c.LogoURL = other.LogoURL
+	}
+	c.UpdatedAt = time.Now().UTC()
+}
+
+// Matches reports whether a transaction with the given description and
+// counterparty IBAN belongs to this counterparty.
+func (c *Counterparty) Matches(description, iban string) bool {
+	if iban = NormaliseIBAN(iban); iban != "" && slices.Contains(c.IBANs, iban) {
+		return true
+	}
+	return slices.Contains(c.Aliases, Normalise(description))
+}
+
+// NormaliseIBAN removes whitespace and upper cases an IBAN.
+func NormaliseIBAN(iban string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(iban), ""))
+}
+
+// ValidIBAN checks the format and the ISO 13616 check digits of a
+// normalised IBAN.
+func ValidIBAN(iban string) bool {
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+	for i, r := range iban {
+		switch {
+		case i < 2 && (r < 'A' || r > 'Z'),
+			i >= 2 && i < 4 && (r < '0' || r > '9'),
+			(r < '0' || r > '9') && (r < 'A' || r > 'Z'):
+			return false
+		}
+	}
+	// the country code and check digits move to the end, letters count as
+	// numbers from A=10 and the whole number modulo 97 must be 1
+	mod := 0
+	for _, r := range iban[4:] + iban[:4] {
+		if r >= 'A' {
+			mod = (mod*100 + int(r-'A') + 10) % 97
+		} else {
+			mod = (mod*10 + int(r-'0')) % 97
+		}
+	}
+	return mod == 1
+}
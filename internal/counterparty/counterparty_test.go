@@ -0,0 +1,122 @@
+package counterparty
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestValidIBAN(t *testing.T) {
+	tests := []struct {
+		iban string
+		want bool
+	}{
+		{"NL91ABNA0417164300", true},
+		{"NL20INGB0001234567", true},
+		{"DE89370400440532013000", true},
+		{"GB82WEST12345698765432", true},
+		{"BE68539007547034", true},
+		{"NL92ABNA0417164300", false},
+		{"NL91ABNA0417164301", false},
+		{"NL91ABNA041716430", false},
+		{"91NLABNA0417164300", false},
+		{"NLX1ABNA0417164300", false},
+		{"NL91ABNA04171643-0", false},
+		{"nl91abna0417164300", false},
+		{"NL91 ABNA 0417 1643 00", false},
+		{"REVOLUT-CURRENT-USD", false},
+		{"NL91", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := ValidIBAN(tt.iban); got != tt.want {
+			t.Errorf("ValidIBAN(%q) = %v, want %v", tt.iban, got, tt.want)
+		}
+	}
+}
+
+func TestNormaliseIBAN(t *testing.T) {
+	if got := NormaliseIBAN(" nl91 abna 0417 1643 00 "); got != "NL91ABNA0417164300" || !ValidIBAN(got) {
+		t.Errorf("NormaliseIBAN() = %q, want a valid NL91ABNA0417164300", got)
+	}
+}
+
+func TestCounterpartyMatches(t *testing.T) {
+	cp, err := NewCounterparty("J Jansen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp.AddAlias("J Jansen")
+	cp.AddIBAN("NL91 ABNA 0417 1643 00")
+	tests := []struct {
+		name        string
+		description string
+		iban        string
+		want        bool
+	}{
+		{"alias", "J JANSEN", "", true},
+		{"sepa name", "/TRTP/SEPA OVERBOEKING/IBAN/NL20INGB0001234567/NAME/J Jansen/REMI/Rent", "", true},
+		{"iban", "Huur januari", "nl91abna0417164300", true},
+		{"other", "P Pietersen", "NL20INGB0001234567", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cp.Matches(tt.description, tt.iban); got != tt.want {
+				t.Errorf("Matches(%q, %q) = %v, want %v", tt.description, tt.iban, got, tt.want)
+			}
+		})
+	}
+}
+
+type fakeStore struct {
+	cp      *Counterparty
+	updated *Counterparty
+}
+
+func (f *fakeStore) FetchByID(ctx context.Context, id uuid.UUID) (*Counterparty, error) {
+	if f.cp == nil || f.cp.ID != id {
+		return nil, ErrCounterpartyNotFound
+	}
+	return f.cp, nil
+}
+
+func (f *fakeStore) Update(ctx context.Context, cp *Counterparty) error {
+	f.updated = cp
+	return nil
+}
+
+func TestUpdateHandlerIBANs(t *testing.T) {
+	tests := []struct {
+		name    string
+		ibans   []string
+		want    []string
+		wantErr error
+	}{
+		{"valid", []string{"nl91 abna 0417 1643 00", "NL20INGB0001234567"}, []string{"NL91ABNA0417164300", "NL20INGB0001234567"}, nil},
+		{"cleared", []string{}, []string{}, nil},
+		{"wrong check digits", []string{"NL91ABNA0417164300", "NL21INGB0001234567"}, nil, ErrInvalidIBAN},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cp, _ := NewCounterparty("J Jansen")
+			cp.AddIBAN("BE68539007547034")
+			store := &fakeStore{cp: cp}
+			got, err := NewUpdateHandler(store, store).Handle(context.Background(), cp.ID, Changes{IBANs: tt.ibans})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Handle() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if store.updated != nil {
+					t.Error("Handle() stored a counterparty with an invalid IBAN")
+				}
+				return
+			}
+			if !slices.Equal(got.IBANs, tt.want) {
+				t.Errorf("IBANs = %v, want %v", got.IBANs, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package counterparty
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type MergeHandler struct {
+	tr  TxRunner
+	cf  CounterpartyFetcher
+	cu  CounterpartyUpdater
+	cd  CounterpartyDeleter
+	txr TransactionReassigner
+}
+
+func NewMergeHandler(tr TxRunner, cf CounterpartyFetcher, cu CounterpartyUpdater, cd CounterpartyDeleter, txr TransactionReassigner) *MergeHandler {
+	return &MergeHandler{tr: tr, cf: cf, cu: cu, cd: cd, txr: txr}
+}
+
+// Handle merges the source counterparties into the target. The target takes
+// over their aliases, IBANs and transactions after which the sources are
+// deleted.
+func (h *MergeHandler) Handle(ctx context.Context, targetID uuid.UUID, sourceIDs []uuid.UUID) (*Counterparty, error) {
+	var target *Counterparty
+	err := h.tr.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		target, err = h.cf.FetchByID(ctx, targetID)
+		if err != nil {
+			return err
+		}
+		for _, id := range sourceIDs {
+			if id == targetID {
+				return ErrMergeIntoSelf
+			}
+			source, err := h.cf.FetchByID(ctx, id)
+			if err != nil {
+				return err
+			}
+			target.Absorb(source)
+			if err := h.txr.ReassignCounterparty(ctx, source.ID, target.ID); err != nil {
+				return err
+			}
+			if err := h.cd.Delete(ctx, source.ID); err != nil {
+				return err
+			}
+		}
+		return h.cu.Update(ctx, target)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return target, nil
+}
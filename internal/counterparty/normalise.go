@@ -0,0 +1,146 @@
+package counterparty
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// cardPrefixes are payment processor and card payment prefixes that are put in
+// front of the merchant name, e.g. "BEA", "CCV*" or "SumUp *".
+var cardPrefixes = []string{
+	"BEA", "GEA", "PAS", "BETAALAUTOMAAT", "APPLE PAY", "GOOGLE PAY",
+	"CCV", "SUMUP", "ZETTLE", "IZ", "SQ", "PAY.NL", "PAYPAL", "MOLLIE", "STRIPE",
+}
+
+// legalSuffixes are company forms that do not distinguish merchants.
+var legalSuffixes = []string{
+	"BV", "B.V.", "NV", "N.V.", "VOF", "V.O.F.", "GMBH", "AG", "LTD", "LIMITED",
+	"INC", "LLC", "SA", "SARL", "SPRL", "BVBA", "SRL", "PLC",
+}
+
+// countryCodes are the country suffixes card terminals add after the city.
+var countryCodes = []string{
+	"NL", "NLD", "BE", "BEL", "DE", "DEU", "FR", "FRA", "GB", "GBR", "UK", "US",
+	"USA", "ES", "ESP", "IT", "ITA", "LU", "LUX", "AT", "AUT", "CH", "CHE", "IE", "IRL",
+}
+
+// Cities are stripped from the end of a description. It holds the places that
+// commonly show up in our statements and can be extended at startup.
+var Cities = []string{
+	"AMSTERDAM", "ROTTERDAM", "DEN HAAG", "'S-GRAVENHAGE", "UTRECHT", "EINDHOVEN",
+	"GRONINGEN", "TILBURG", "ALMERE", "BREDA", "NIJMEGEN", "HAARLEM", "ARNHEM",
+	"ENSCHEDE", "AMERSFOORT", "APELDOORN", "ZWOLLE", "LEIDEN", "MAASTRICHT",
+	"DORDRECHT", "ZAANDAM", "DELFT", "AMSTELVEEN", "HILVERSUM", "SCHIPHOL",
+	"BRUSSEL", "BRUXELLES", "ANTWERPEN", "GENT", "BRUGGE", "LEUVEN", "LIEGE",
+	"BERLIN", "HAMBURG", "MUENCHEN", "MUNCHEN", "KOELN", "KOLN", "DUESSELDORF",
+	"FRANKFURT", "PARIS", "LONDON", "DUBLIN", "LUXEMBOURG",
+}
+
+var (
+	// terminalIDPattern matches card terminal and store numbers such as "1234",
+	// "NR:AB12CD" or "P00357": tokens that contain at least three digits.
+	terminalIDPattern = regexp.MustCompile(`^(NR:|TERM:?|#)?[A-Z]*\d[A-Z0-9\-/.:]*$`)
+	separatorPattern  = regexp.MustCompile(`[\s,;*_]+`)
+	// sepaKey matches the keys of the SEPA fields in a description such as
+	// /TRTP/SEPA OVERBOEKING/IBAN/NL91ABNA0417164300/NAME/J Jansen/REMI/Rent.
+	sepaKey = regexp.MustCompile(`/(TRTP|CSID|NAME|MARF|REMI|IBAN|BIC|EREF|ORDP|BENM|ID|SWOC|ISDT|RTRN|PREF|IREF|CNTP|ULTC|ULTD|PURP)/`)
+)
+
+// Normalise reduces a raw transaction description to the merchant part so that
+// e.g. "AH 1234 AMSTERDAM" and "BEA AH 5678 AMSTERDAM NLD" both become "AH".
+// It strips card payment prefixes, terminal IDs, city and country suffixes and
+// legal company forms. Of a SEPA description only the NAME field is kept.
+func Normalise(description string) string {
+	s := strings.ToUpper(strings.TrimSpace(description))
+	if name, ok := sepaName(s); ok {
+		s = name
+	}
+	tokens := separatorPattern.Split(s, -1)
+	tokens = slices.DeleteFunc(tokens, func(t string) bool { return t == "" })
+
+	// strip leading card payment prefixes, e.g. "BEA, Apple Pay CCV*BAKKER"
+	for len(tokens) > 1 {
+		if slices.Contains(cardPrefixes, tokens[0]) {
+			tokens = tokens[1:]
+			continue
+		}
+		if len(tokens) > 2 && slices.Contains(cardPrefixes, tokens[0]+" "+tokens[1]) {
+			tokens = tokens[2:]
+			continue
+		}
+		break
+	}
+
+	// drop terminal IDs anywhere but the first token, a merchant name can
+	// start with a number
+	kept := tokens[:0:0]
+	for i, t := range tokens {
+		if i > 0 && countDigits(t) >= 3 && terminalIDPattern.MatchString(t) {
+			continue
+		}
+		kept = append(kept, t)
+	}
+	tokens = kept
+
+	// strip trailing country codes and legal suffixes in any order together
+	// with at most one city, "MUSEA BRUGGE BRUGGE BEL" becomes "MUSEA BRUGGE"
+	cityStripped := false
+	for len(tokens) > 1 {
+		last := strings.Trim(tokens[len(tokens)-1], ".")
+		switch {
+		case slices.Contains(countryCodes, last),
+			slices.Contains(legalSuffixes, last),
+			slices.Contains(legalSuffixes, tokens[len(tokens)-1]):
+			tokens = tokens[:len(tokens)-1]
+			continue
+		case !cityStripped && slices.Contains(Cities, last):
+			tokens = tokens[:len(tokens)-1]
+			cityStripped = true
+			continue
+		case !cityStripped && len(tokens) > 2 && slices.Contains(Cities, tokens[len(tokens)-2]+" "+last):
+			tokens = tokens[:len(tokens)-2]
+			cityStripped = true
+			continue
+		}
+		break
+	}
+
+	return strings.Join(tokens, " ")
+}
+
+// sepaName returns the NAME field of a SEPA description, the text up to the
+// next field.
+func sepaName(s string) (string, bool) {
+	_, rest, ok := strings.Cut(s, "/NAME/")
+	if !ok {
+		return "", false
+	}
+	if loc := sepaKey.FindStringIndex(rest); loc != nil {
+		rest = rest[:loc[0]]
+	}
+	return strings.TrimSpace(rest), true
+}
+
+// DisplayName turns a normalised alias into a readable name, e.g.
+// "ALBERT HEIJN" becomes "Albert Heijn".
+func DisplayName(alias string) string {
+	words := strings.Fields(strings.ToLower(alias))
+	for i, w := range words {
+		r := []rune(w)
+		r[0] = unicode.ToUpper(r[0])
+		words[i] = string(r)
+	}
+	return strings.Join(words, " ")
+}
+
+func countDigits(s string) int {
+	n := 0
+	for _, r := range s {
+		if unicode.IsDigit(r) {
+			n++
+		}
+	}
+	return n
+}
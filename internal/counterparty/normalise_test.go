@@ -0,0 +1,55 @@
+package counterparty
+
+import "testing"
+
+func TestNormalise(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		want        string
+	}{
+		{"terminal id and city", "AH 1234 AMSTERDAM", "AH"},
+		{"card prefix and country", "BEA AH 5678 AMSTERDAM NLD", "AH"},
+		{"legal suffix", "Albert Heijn BV", "ALBERT HEIJN"},
+		{"store number", "ALBERT HEIJN 5678", "ALBERT HEIJN"},
+		{"dotted legal suffix", "Coolblue B.V.", "COOLBLUE"},
+		{"processor prefix", "CCV*BAKKER BART", "BAKKER BART"},
+		{"stacked prefixes", "BEA, Apple Pay CCV*BAKKER", "BAKKER"},
+		{"two word city", "HEMA 123 DEN HAAG", "HEMA"},
+		{"only one city", "MUSEA BRUGGE BRUGGE BEL", "MUSEA BRUGGE"},
+		{"terminal number with prefix", "JUMBO NR:AB123C UTRECHT", "JUMBO"},
+		{"leading number", "7-ELEVEN 4711 LONDON GBR", "7-ELEVEN"},
+		{"lower case and spaces", "  netflix.com  ", "NETFLIX.COM"},
+		{"empty", "", ""},
+		{"prefix only", "BEA", "BEA"},
+		// SEPA descriptions are reduced to their NAME field
+		{"sepa transfer", "/TRTP/SEPA OVERBOEKING/IBAN/NL91ABNA0417164300/BIC/ABNANL2A/NAME/J Jansen/REMI/Rent/EREF/NOTPROVIDED", "J JANSEN"},
+		{"sepa direct debit", "/TRTP/SEPA Incasso algemeen doorlopend/CSID/NL84ZZZ341230200000/NAME/Vattenfall Klantenservice N.V./MARF/123456/REMI/Termijnbedrag/IBAN/NL16INGB0000102030/BIC/INGBNL2A/EREF/987654", "VATTENFALL KLANTENSERVICE"},
+		{"sepa name last", "/TRTP/SEPA OVERBOEKING/IBAN/NL91ABNA0417164300/NAME/Bakkerij De Molen B.V.", "BAKKERIJ DE MOLEN"},
+		{"sepa name with city", "/TRTP/SEPA OVERBOEKING/NAME/GEMEENTE AMSTERDAM/REMI/Parkeren", "GEMEENTE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalise(tt.description); got != tt.want {
+				t.Errorf("Normalise(%q) = %q, want %q", tt.description, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	tests := []struct {
+		alias string
+		want  string
+	}{
+		{"ALBERT HEIJN", "Albert Heijn"},
+		{"AH", "Ah"},
+		{"NETFLIX.COM", "Netflix.com"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := DisplayName(tt.alias); got != tt.want {
+			t.Errorf("DisplayName(%q) = %q, want %q", tt.alias, got, tt.want)
+		}
+	}
+}
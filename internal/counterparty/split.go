@@ -0,0 +1,85 @@
+package counterparty
+
+import (
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// Single-use interfaces only used by SplitHandler
+
+type TransactionFetcher interface {
+	FetchByCounterparty(ctx context.Context, id uuid.UUID) ([]*transaction.Transaction, error)
+}
+
+type SplitHandler struct {
+	tr  TxRunner
+	cf  CounterpartyFetcher
+	cc  CounterpartyCreator
+	cu  CounterpartyUpdater
+	tf  TransactionFetcher
+	txr TransactionReassigner
+}
+
+func NewSplitHandler(tr TxRunner, cf CounterpartyFetcher, cc CounterpartyCreator, cu CounterpartyUpdater, tf TransactionFetcher, txr TransactionReassigner) *SplitHandler {
+	return &SplitHandler{tr: tr, cf: cf, cc: cc, cu: cu, tf: tf, txr: txr}
+}
+
+// Handle splits the given aliases and IBANs off the counterparty into a new
+// counterparty with the given name. Transactions that match the split off
+// aliases or IBANs move along to the new counterparty.
+func (h *SplitHandler) Handle(ctx context.Context, id uuid.UUID, name string, aliases, ibans []string) (*Counterparty, error) {
+	var split *Counterparty
+	err := h.tr.WithTx(ctx, func(ctx context.Context) error {
+		cp, err := h.cf.FetchByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if split, err = NewCounterparty(name); err != nil {
+			return err
+		}
+		for _, a := range aliases {
+			if a = Normalise(a); slices.Contains(cp.Aliases, a) {
+				split.AddAlias(a)
+			}
+		}
+		for _, i := range ibans {
+			if i = NormaliseIBAN(i); slices.Contains(cp.IBANs, i) {
+				split.AddIBAN(i)
+			}
+		}
+		if len(split.Aliases) == 0 && len(split.IBANs) == 0 {
+			return ErrNothingToSplit
+		}
+		if len(split.Aliases) == len(cp.Aliases) && len(split.IBANs) == len(cp.IBANs) {
+			return ErrSplitEverything
+		}
+		cp.Aliases = slices.DeleteFunc(cp.Aliases, func(a string) bool { return slices.Contains(split.Aliases, a) })
+		cp.IBANs = slices.DeleteFunc(cp.IBANs, func(i string) bool { return slices.Contains(split.IBANs, i) })
+
+		if err := h.cc.Create(ctx, split); err != nil {
+			return err
+		}
+		if err := h.cu.Update(ctx, cp); err != nil {
+			return err
+		}
+
+		txs, err := h.tf.FetchByCounterparty(ctx, cp.ID)
+		if err != nil {
+			return err
+		}
+		var moved []uuid.UUID
+		for _, tx := range txs {
+			if split.Matches(tx.Description, tx.CounterpartyIBAN) {
+				moved = append(moved, tx.ID)
+			}
+		}
+		return h.txr.Reassign(ctx, moved, split.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return split, nil
+}
@@ -0,0 +1,71 @@
+package counterparty
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Changes holds the fields of a counterparty to update, nil fields are left
+// untouched.
+type Changes struct {
+	Name       *string
+	Aliases    []string
+	IBANs      []string
+	DefaultTag *string
+	LogoURL    *string
+}
+
+type UpdateHandler struct {
+	cf CounterpartyFetcher
+	cu CounterpartyUpdater
+}
+
+func NewUpdateHandler(cf CounterpartyFetcher, cu CounterpartyUpdater) *UpdateHandler {
+	return &UpdateHandler{cf: cf, cu: cu}
+}
+
+// Handle applies the changes to the counterparty. Aliases and IBANs replace
+// the existing lists when given, IBANs must have valid check digits.
+func (h *UpdateHandler) Handle(ctx context.Context, id uuid.UUID, c Changes) (*Counterparty, error) {
+	cp, err := h.cf.FetchByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if c.Name != nil {
+		name := strings.TrimSpace(*c.Name)
+		if name == "" {
+			return nil, ErrInvalidName
+		}
+		cp.Name = name
+	}
+	if c.Aliases != nil {
+		cp.Aliases = []string{}
+		for _, a := range c.Aliases {
+			cp.AddAlias(a)
+		}
+	}
+	if c.IBANs != nil {
+		cp.IBANs = []string{}
+		for _, i := range c.IBANs {
+			if !ValidIBAN(NormaliseIBAN(i)) {
+				return nil, fmt.Errorf("%w %q", ErrInvalidIBAN, i)
+			}
+			cp.AddIBAN(i)
+		}
+	}
+	if c.DefaultTag != nil {
+		cp.DefaultTag = strings.TrimSpace(*c.DefaultTag)
+	}
+	if c.LogoURL != nil {
+		cp.LogoURL = strings.TrimSpace(*c.LogoURL)
+	}
+	cp.UpdatedAt = time.Now().UTC()
+	if err := h.cu.Update(ctx, cp); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
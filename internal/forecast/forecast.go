@@ -0,0 +1,269 @@
+package forecast
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/recurring"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+const (
+	DefaultDays = 90
+	MaxDays     = 730
+	// DefaultHistoryDays is how many days of history the discretionary
+	// spending is averaged over.
+	DefaultHistoryDays = 90
+)
+
+var (
+	ErrInvalidDays  = fmt.Errorf("invalid number of days")
+	ErrInvalidEntry = fmt.Errorf("invalid what-if entry")
+)
+
+type Source string
+
+const (
+	SourceRecurring Source = "recurring"
+	SourceWhatIf    Source = "what_if"
+)
+
+// Entry is a single projected income or expense. AmountCents is positive for
+// income and negative for expenses.
+type Entry struct {
+	Date        time.Time
+	Description string
+	AmountCents int64
+	Source      Source
+	// SeriesID references the recurring series the entry was projected
+	// from.
+	SeriesID *uuid.UUID
+}
+
+// Input holds everything a projection is made from.
+type Input struct {
+	// Today is the last day of the known balance, the projection starts the
+	// day after.
+	Today time.Time
+	Days  int
+	// Account limits the series and history to one account, empty includes
+	// all accounts.
+	Account      string
+	BalanceCents int64
+	// ThresholdCents is the balance below which the forecast warns.
+	ThresholdCents int64
+	Series         []*recurring.Series
+	// History are the transactions the discretionary spending is averaged
+	// over, transactions before Today minus HistoryDays are left out.
+	History     []*transaction.Transaction
+	HistoryDays int
+	WhatIf      []Entry
+}
+
+// Day is the projected balance at the end of a day.
+type Day struct {
+	Date        time.Time
+	IncomeCents int64
+	// ExpenseCents includes the discretionary spending.
+	ExpenseCents       int64
+	DiscretionaryCents int64
+	BalanceCents       int64
+	// Entries are the recurring and what-if entries of the day.
+	Entries []Entry
+}
+
+// CategorySpend is the average discretionary spending of a category.
+type CategorySpend struct {
+	Category string
+	// MonthlyCents is the average spending per 30 days.
+	MonthlyCents int64
+	totalCents   int64
+}
+
+type Forecast struct {
+	Account string
+	// Currency is the currency of the account, the reporting currency when
+	// all accounts are projected.
+	Currency          string
+	StartBalanceCents int64
+	ThresholdCents    int64
+	Days              []Day
+	Discretionary     []CategorySpend
+	// Lowest is the day with the lowest projected balance, the first one
+	// when several days share it.
+	Lowest Day
+	// LowBalanceDate is the first day the balance drops below the threshold,
+	// nil when it stays above.
+	LowBalanceDate *time.Time
+}
+
+func (f *Forecast) EndBalanceCents() int64 {
+	if len(f.Days) == 0 {
+		return f.StartBalanceCents
+	}
+	return f.Days[len(f.Days)-1].BalanceCents
+}
+
+// Project projects the daily balance for the days after today. Recurring
+// series that have not ended are projected on their expected dates, the
+// average spending per category on transactions that are not part of a
+// series is spread evenly over the days and the what-if entries are added
+// on their dates.
+func Project(in Input) (*Forecast, error) {
+	if in.Days == 0 {
+		in.Days = DefaultDays
+	}
+	if in.Days < 0 || in.Days > MaxDays {
+		return nil, ErrInvalidDays
+	}
+	if in.HistoryDays <= 0 {
+		in.HistoryDays = DefaultHistoryDays
+	}
+	today := truncate(in.Today)
+	end := today.AddDate(0, 0, in.Days)
+
+	entries := map[time.Time][]Entry{}
+	for _, e := range in.WhatIf {
+		if e.AmountCents == 0 || e.Date.IsZero() {
+			return nil, ErrInvalidEntry
+		}
+		e.Date = truncate(e.Date)
+		e.Source = SourceWhatIf
+		if e.Date.After(today) && !e.Date.After(end) {
+			entries[e.Date] = append(entries[e.Date], e)
+		}
+	}
+	series := accountSeries(in.Series, in.Account)
+	for _, s := range series {
+		for _, e := range occurrences(s, today, end) {
+			entries[e.Date] = append(entries[e.Date], e)
+		}
+	}
+	spend := discretionary(in.History, series, in.Account, today, in.HistoryDays)
+
+	f := &Forecast{
+		Account:           in.Account,
+		StartBalanceCents: in.BalanceCents,
+		ThresholdCents:    in.ThresholdCents,
+		Days:              make([]Day, 0, in.Days),
+		Discretionary:     spend,
+	}
+	balance := in.BalanceCents
+	for i := 1; i <= in.Days; i++ {
+		day := Day{Date: today.AddDate(0, 0, i), Entries: entries[today.AddDate(0, 0, i)]}
+		for _, e := range day.Entries {
+			if e.AmountCents > 0 {
+				day.IncomeCents += e.AmountCents
+			} else {
+				day.ExpenseCents -= e.AmountCents
+			}
+		}
+		for _, c := range spend {
+			// spread the total without losing the remainder to rounding
+			day.DiscretionaryCents += c.totalCents*int64(i)/int64(in.HistoryDays) - c.totalCents*int64(i-1)/int64(in.HistoryDays)
+		}
+		day.ExpenseCents += day.DiscretionaryCents
+		balance += day.IncomeCents - day.ExpenseCents
+		day.BalanceCents = balance
+		f.Days = append(f.Days, day)
+
+		if len(f.Days) == 1 || day.BalanceCents < f.Lowest.BalanceCents {
+			f.Lowest = day
+		}
+		if f.LowBalanceDate == nil && day.BalanceCents < in.ThresholdCents {
+			date := day.Date
+			f.LowBalanceDate = &date
+		}
+	}
+	return f, nil
+}
+
+// accountSeries returns the series of the account that have not ended.
+func accountSeries(series []*recurring.Series, account string) []*recurring.Series {
+	var res []*recurring.Series
+	for _, s := range series {
+		if s.Status == recurring.StatusEnded || (account != "" && s.Account != account) {
+			continue
+		}
+		res = append(res, s)
+	}
+	return res
+}
+
+// occurrences returns the entries of the series in (today, end]. A payment
+// that is due but still within its grace period is expected tomorrow, the
+// overdue payment of a missed series is not expected anymore.
+func occurrences(s *recurring.Series, today, end time.Time) []Entry {
+	amount := s.AmountCents
+	if s.Direction == transaction.CashOut {
+		amount = -amount
+	}
+	var res []Entry
+	for n := 1; ; n++ {
+		date := truncate(s.Frequency.After(s.LastDate, n))
+		if date.After(end) {
+			return res
+		}
+		if !date.After(today) {
+			if s.Status != recurring.StatusActive {
+				continue
+			}
+			date = today.AddDate(0, 0, 1)
+		}
+		res = append(res, Entry{
+			Date:        date,
+			Description: s.Name,
+			AmountCents: amount,
+			Source:      SourceRecurring,
+			SeriesID:    &s.ID,
+		})
+	}
+}
+
+// discretionary averages the outgoing transactions of the history that do
+// not belong to one of the series per tag, the highest spending first.
+func discretionary(history []*transaction.Transaction, series []*recurring.Series, account string, today time.Time, days int) []CategorySpend {
+	type key struct {
+		account string
+		group   string
+	}
+	recurringKeys := map[key]bool{}
+	for _, s := range series {
+		if s.Direction == transaction.CashOut {
+			recurringKeys[key{account: s.Account, group: s.Key}] = true
+		}
+	}
+	from := today.AddDate(0, 0, -days)
+	totals := map[string]int64{}
+	for _, tx := range history {
+		date := truncate(tx.Date)
+		if tx.Direction != transaction.CashOut || tx.Ignored || !date.After(from) || date.After(today) {
+			continue
+		}
+		if account != "" && tx.Account != account {
+			continue
+		}
+		if recurringKeys[key{account: tx.Account, group: recurring.GroupKey(tx)}] {
+			continue
+		}
+		totals[tx.Tag] += tx.AmountCents
+	}
+	res := make([]CategorySpend, 0, len(totals))
+	for tag, total := range totals {
+		res = append(res, CategorySpend{Category: tag, MonthlyCents: total * 30 / int64(days), totalCents: total})
+	}
+	slices.SortFunc(res, func(a, b CategorySpend) int {
+		if c := cmp.Compare(b.totalCents, a.totalCents); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Category, b.Category)
+	})
+	return res
+}
+
+func truncate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,383 @@
+package forecast
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/recurring"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+func date(s string) time.Time {
+	d, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func series(frequency recurring.Frequency, last string, status recurring.Status) *recurring.Series {
+	return &recurring.Series{
+		ID:          uuid.New(),
+		Key:         "description:NETFLIX",
+		Name:        "Netflix",
+		Account:     "NL01TEST0000000001",
+		Direction:   transaction.CashOut,
+		Frequency:   frequency,
+		AmountCents: 1299,
+		LastDate:    date(last),
+		Status:      status,
+	}
+}
+
+// entryDates returns the dates of the entries of the forecast.
+func entryDates(f *Forecast) []string {
+	var res []string
+	for _, d := range f.Days {
+		for _, e := range d.Entries {
+			res = append(res, e.Date.Format(time.DateOnly))
+		}
+	}
+	return res
+}
+
+func TestProjectRecurring(t *testing.T) {
+	tests := []struct {
+		name    string
+		series  *recurring.Series
+		account string
+		today   string
+		days    int
+		want    []string
+	}{
+		{
+			name:   "month ends after the 31st",
+			series: series(recurring.Monthly, "2024-01-31", recurring.StatusActive),
+			today:  "2024-01-31", days: 90,
+			want: []string{"2024-02-29", "2024-03-31", "2024-04-30"},
+		},
+		{
+			name:   "the 30th in a leap year February",
+			series: series(recurring.Monthly, "2023-12-30", recurring.StatusActive),
+			today:  "2024-01-15", days: 60,
+			want: []string{"2024-01-30", "2024-02-29"},
+		},
+		{
+			name:   "weekly across a month end",
+			series: series(recurring.Weekly, "2024-01-29", recurring.StatusActive),
+			today:  "2024-01-31", days: 14,
+			want: []string{"2024-02-05", "2024-02-12"},
+		},
+		{
+			name:   "quarterly keeps the day of the month",
+			series: series(recurring.Quarterly, "2023-11-30", recurring.StatusActive),
+			today:  "2024-01-01", days: 365,
+			want: []string{"2024-02-29", "2024-05-30", "2024-08-30", "2024-11-30"},
+		},
+		{
+			name:   "yearly on a leap day",
+			series: series(recurring.Yearly, "2024-02-29", recurring.StatusActive),
+			today:  "2024-03-01", days: 365,
+			want: []string{"2025-02-28"},
+		},
+		{
+			name:   "due payment within its grace period is expected tomorrow",
+			series: series(recurring.Monthly, "2024-01-10", recurring.StatusActive),
+			today:  "2024-02-12", days: 30,
+			want: []string{"2024-02-13", "2024-03-10"},
+		},
+		{
+			name:   "missed payment is not expected anymore",
+			series: series(recurring.Monthly, "2024-01-10", recurring.StatusMissed),
+			today:  "2024-02-12", days: 30,
+			want: []string{"2024-03-10"},
+		},
+		{
+			name:   "ended series",
+			series: series(recurring.Monthly, "2024-01-10", recurring.StatusEnded),
+			today:  "2024-01-12", days: 90,
+		},
+		{
+			name:    "series of another account",
+			series:  series(recurring.Monthly, "2024-01-10", recurring.StatusActive),
+			account: "NL02TEST0000000002",
+			today:   "2024-01-12", days: 90,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Project(Input{
+				Today:        date(tt.today),
+				Days:         tt.days,
+				Account:      tt.account,
+				BalanceCents: 100000,
+				Series:       []*recurring.Series{tt.series},
+			})
+			if err != nil {
+				t.Fatalf("Project() error = %v", err)
+			}
+			if got := entryDates(f); !slices.Equal(got, tt.want) {
+				t.Errorf("entries on %v, want %v", got, tt.want)
+			}
+			for _, d := range f.Days {
+				for _, e := range d.Entries {
+					if e.AmountCents != -1299 || e.Source != SourceRecurring || e.SeriesID == nil || *e.SeriesID != tt.series.ID {
+						t.Errorf("entry = %+v", e)
+					}
+				}
+			}
+			if got, want := f.EndBalanceCents(), 100000-1299*int64(len(tt.want)); got != want {
+				t.Errorf("EndBalanceCents() = %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestProjectDiscretionary(t *testing.T) {
+	const account = "NL01TEST0000000001"
+	netflix := series(recurring.Monthly, "2024-03-15", recurring.StatusActive)
+	tx := func(day string, cents int64, tag, description string, opts ...func(*transaction.Transaction)) *transaction.Transaction {
+		t := &transaction.Transaction{
+			ID:          uuid.New(),
+			Description: description,
+			AmountCents: cents,
+			Direction:   transaction.CashOut,
+			Date:        date(day),
+			Tag:         tag,
+			Account:     account,
+		}
+		for _, opt := range opts {
+			opt(t)
+		}
+		return t
+	}
+	history := []*transaction.Transaction{
+		tx("2024-03-02", 400, "groceries", "Albert Heijn"),
+		tx("2024-03-31", 600, "groceries", "Jumbo"),
+		tx("2024-03-20", 90, "transport", "NS"),
+		// the window is (today - 30 days, today]
+		tx("2024-03-01", 5000, "groceries", "Albert Heijn"),
+		tx("2024-04-01", 5000, "groceries", "Albert Heijn"),
+		tx("2024-03-10", 5000, "salary", "Employer", func(t *transaction.Transaction) { t.Direction = transaction.CashIn }),
+		tx("2024-03-11", 5000, "groceries", "Albert Heijn", func(t *transaction.Transaction) { t.Ignored = true }),
+		tx("2024-03-12", 5000, "groceries", "Albert Heijn", func(t *transaction.Transaction) { t.Account = "NL02TEST0000000002" }),
+		// part of a series, it is projected on its dates instead
+		tx("2024-03-15", 1299, "subscriptions", "Netflix"),
+	}
+
+	f, err := Project(Input{
+		Today:        date("2024-03-31"),
+		Days:         30,
+		Account:      account,
+		BalanceCents: 100000,
+		Series:       []*recurring.Series{netflix},
+		History:      history,
+		HistoryDays:  30,
+	})
+	if err != nil {
+		t.Fatalf("Project() error = %v", err)
+	}
+
+	want := []CategorySpend{{Category: "groceries", MonthlyCents: 1000}, {Category: "transport", MonthlyCents: 90}}
+	if len(f.Discretionary) != len(want) {
+		t.Fatalf("Discretionary = %+v, want %+v", f.Discretionary, want)
+	}
+	for i, w := range want {
+		if got := f.Discretionary[i]; got.Category != w.Category || got.MonthlyCents != w.MonthlyCents {
+			t.Errorf("Discretionary[%d] = %+v, want %+v", i, got, w)
+		}
+	}
+
+	// 1090 over 30 days is 36 or 37 a day, adding up to exactly 1090
+	var total int64
+	for _, d := range f.Days {
+		if d.DiscretionaryCents < 36 || d.DiscretionaryCents > 37 {
+			t.Errorf("%s: DiscretionaryCents = %d, want 36 or 37", d.Date.Format(time.DateOnly), d.DiscretionaryCents)
+		}
+		if want := d.DiscretionaryCents + 1299*int64(len(d.Entries)); d.ExpenseCents != want {
+			t.Errorf("%s: ExpenseCents = %d, want %d", d.Date.Format(time.DateOnly), d.ExpenseCents, want)
+		}
+		total += d.DiscretionaryCents
+	}
+	if total != 1090 {
+		t.Errorf("discretionary spending over the history length = %d, want 1090", total)
+	}
+	if got := entryDates(f); !slices.Equal(got, []string{"2024-04-15"}) {
+		t.Errorf("entries on %v, want 2024-04-15", got)
+	}
+	if got := f.EndBalanceCents(); got != 100000-1090-1299 {
+		t.Errorf("EndBalanceCents() = %d, want %d", got, 100000-1090-1299)
+	}
+}
+
+func TestProjectWhatIf(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []Entry
+		want    map[string]int64
+		wantErr error
+	}{
+		{
+			name: "entries within the forecast",
+			entries: []Entry{
+				{Date: date("2024-01-02"), Description: "Bonus", AmountCents: 50000},
+				{Date: date("2024-01-05").Add(18 * time.Hour), Description: "Laptop", AmountCents: -120000},
+				{Date: date("2024-01-05"), Description: "Dinner", AmountCents: -8000},
+			},
+			want: map[string]int64{"2024-01-02": 50000, "2024-01-05": -128000},
+		},
+		{
+			name: "entries outside the forecast are left out",
+			entries: []Entry{
+				{Date: date("2024-01-01"), Description: "Today", AmountCents: -1000},
+				{Date: date("2023-12-24"), Description: "Past", AmountCents: -1000},
+				{Date: date("2024-01-11"), Description: "Last day", AmountCents: -1000},
+				{Date: date("2024-01-12"), Description: "After", AmountCents: -1000},
+			},
+			want: map[string]int64{"2024-01-11": -1000},
+		},
+		{
+			name:    "zero amount",
+			entries: []Entry{{Date: date("2024-01-02"), Description: "Nothing"}},
+			wantErr: ErrInvalidEntry,
+		},
+		{
+			name:    "no date",
+			entries: []Entry{{Description: "Someday", AmountCents: 1000}},
+			wantErr: ErrInvalidEntry,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Project(Input{Today: date("2024-01-01"), Days: 10, BalanceCents: 200000, WhatIf: tt.entries})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Project() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Project() error = %v", err)
+			}
+			got := map[string]int64{}
+			var sum int64
+			for _, d := range f.Days {
+				for _, e := range d.Entries {
+					if e.Source != SourceWhatIf || !e.Date.Equal(d.Date) {
+						t.Errorf("entry = %+v on %s", e, d.Date.Format(time.DateOnly))
+					}
+					got[d.Date.Format(time.DateOnly)] += e.AmountCents
+				}
+				if d.IncomeCents-d.ExpenseCents != got[d.Date.Format(time.DateOnly)] {
+					t.Errorf("%s: income %d, expenses %d", d.Date.Format(time.DateOnly), d.IncomeCents, d.ExpenseCents)
+				}
+				sum += got[d.Date.Format(time.DateOnly)]
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("entries = %v, want %v", got, tt.want)
+			}
+			if f.EndBalanceCents() != 200000+sum {
+				t.Errorf("EndBalanceCents() = %d, want %d", f.EndBalanceCents(), 200000+sum)
+			}
+		})
+	}
+}
+
+func TestProjectLowBalance(t *testing.T) {
+	tests := []struct {
+		name      string
+		balance   int64
+		threshold int64
+		whatIf    []Entry
+		wantLow   string
+		wantDate  string
+		wantCents int64
+	}{
+		{
+			name:      "stays above the threshold",
+			balance:   100000,
+			threshold: 50000,
+			whatIf:    []Entry{{Date: date("2024-01-03"), AmountCents: -50000}},
+			wantDate:  "2024-01-03",
+			wantCents: 50000,
+		},
+		{
+			name:      "drops below and recovers",
+			balance:   100000,
+			threshold: 50000,
+			whatIf: []Entry{
+				{Date: date("2024-01-03"), AmountCents: -60000},
+				{Date: date("2024-01-04"), AmountCents: -10000},
+				{Date: date("2024-01-06"), AmountCents: 80000},
+			},
+			wantLow:   "2024-01-03",
+			wantDate:  "2024-01-04",
+			wantCents: 30000,
+		},
+		{
+			name:      "below from the start",
+			balance:   10000,
+			threshold: 50000,
+			wantLow:   "2024-01-02",
+			wantDate:  "2024-01-02",
+			wantCents: 10000,
+		},
+		{
+			name:      "negative balance with a zero threshold",
+			balance:   1000,
+			whatIf:    []Entry{{Date: date("2024-01-09"), AmountCents: -1001}},
+			wantLow:   "2024-01-09",
+			wantDate:  "2024-01-09",
+			wantCents: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Project(Input{Today: date("2024-01-01"), Days: 10, BalanceCents: tt.balance, ThresholdCents: tt.threshold, WhatIf: tt.whatIf})
+			if err != nil {
+				t.Fatalf("Project() error = %v", err)
+			}
+			var low string
+			if f.LowBalanceDate != nil {
+				low = f.LowBalanceDate.Format(time.DateOnly)
+			}
+			if low != tt.wantLow {
+				t.Errorf("LowBalanceDate = %q, want %q", low, tt.wantLow)
+			}
+			if got := f.Lowest.Date.Format(time.DateOnly); got != tt.wantDate || f.Lowest.BalanceCents != tt.wantCents {
+				t.Errorf("Lowest = %s %d, want %s %d", got, f.Lowest.BalanceCents, tt.wantDate, tt.wantCents)
+			}
+		})
+	}
+}
+
+func TestProjectDays(t *testing.T) {
+	tests := []struct {
+		days    int
+		want    int
+		wantErr error
+	}{
+		{0, DefaultDays, nil},
+		{1, 1, nil},
+		{MaxDays, MaxDays, nil},
+		{-1, 0, ErrInvalidDays},
+		{MaxDays + 1, 0, ErrInvalidDays},
+	}
+	for _, tt := range tests {
+		f, err := Project(Input{Today: date("2024-01-01"), Days: tt.days})
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("Project(%d days) error = %v, want %v", tt.days, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if len(f.Days) != tt.want || !f.Days[0].Date.Equal(date("2024-01-02")) {
+			t.Errorf("Project(%d days) = %d days from %s, want %d from 2024-01-02", tt.days, len(f.Days), f.Days[0].Date, tt.want)
+		}
+	}
+}
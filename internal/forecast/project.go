@@ -0,0 +1,144 @@
+package forecast
+
+import (
+	"context"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/balance"
+	"github.com/lennardclaproth/my-finances-tracker/internal/fx"
+	"github.com/lennardclaproth/my-finances-tracker/internal/recurring"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// Single-use interfaces only used by ProjectHandler
+
+type BalanceFetcher interface {
+	// Balance returns the current balance of the account, of all accounts
+	// when it is empty.
+	Balance(ctx context.Context, account string) (int64, error)
+}
+
+type SeriesLister interface {
+	List(ctx context.Context, status recurring.Status, direction transaction.CashFlowDirection) ([]*recurring.Series, error)
+}
+
+type TransactionFetcher interface {
+	FetchSince(ctx context.Context, from time.Time) ([]*transaction.Transaction, error)
+}
+
+type AccountLister interface {
+	Accounts(ctx context.Context) ([]*balance.Account, error)
+}
+
+// Request describes the forecast to make, BalanceCents overrides the
+// current balance of the account.
+type Request struct {
+	Account        string
+	Days           int
+	BalanceCents   *int64
+	ThresholdCents int64
+	WhatIf         []Entry
+}
+
+// ProjectHandler gathers the balance, recurring series and history of an
+// account and projects them. A single account is projected in its own
+// currency, all accounts together in the reporting currency.
+type ProjectHandler struct {
+	bf          BalanceFetcher
+	sl          SeriesLister
+	tf          TransactionFetcher
+	al          AccountLister
+	rf          fx.RateFetcher
+	currency    string
+	historyDays int
+}
+
+func NewProjectHandler(bf BalanceFetcher, sl SeriesLister, tf TransactionFetcher, al AccountLister, rf fx.RateFetcher, currency string, historyDays int) *ProjectHandler {
+	if historyDays <= 0 {
+		historyDays = DefaultHistoryDays
+	}
+	return &ProjectHandler{bf: bf, sl: sl, tf: tf, al: al, rf: rf, currency: currency, historyDays: historyDays}
+}
+
+func (h *ProjectHandler) Handle(ctx context.Context, req Request, today time.Time) (*Forecast, error) {
+	today = truncate(today)
+	accounts, err := h.al.Accounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	currency := h.currency
+	if req.Account != "" {
+		currency = transaction.DefaultCurrency
+		for _, a := range accounts {
+			if a.ID == req.Account {
+				currency = a.Currency
+			}
+		}
+	}
+	conv := fx.NewConverter(h.rf)
+	in := Input{
+		Today:          today,
+		Days:           req.Days,
+		Account:        req.Account,
+		ThresholdCents: req.ThresholdCents,
+		HistoryDays:    h.historyDays,
+		WhatIf:         req.WhatIf,
+	}
+	if req.BalanceCents != nil {
+		in.BalanceCents = *req.BalanceCents
+	} else if req.Account != "" {
+		if in.BalanceCents, err = h.bf.Balance(ctx, req.Account); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, a := range accounts {
+			cents, err := h.bf.Balance(ctx, a.ID)
+			if err != nil {
+				return nil, err
+			}
+			if cents, err = conv.Convert(ctx, cents, a.Currency, currency, today); err != nil {
+				return nil, err
+			}
+			in.BalanceCents += cents
+		}
+	}
+	series, err := h.sl.List(ctx, "", "")
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range series {
+		if s.Currency != currency && (req.Account == "" || s.Account == req.Account) {
+			converted := *s
+			if converted.AmountCents, err = conv.Convert(ctx, s.AmountCents, s.Currency, currency, today); err != nil {
+				return nil, err
+			}
+			if converted.PreviousAmountCents, err = conv.Convert(ctx, s.PreviousAmountCents, s.Currency, currency, today); err != nil {
+				return nil, err
+			}
+			converted.Currency = currency
+			s = &converted
+		}
+		in.Series = append(in.Series, s)
+	}
+	history, err := h.tf.FetchSince(ctx, today.AddDate(0, 0, -h.historyDays))
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range history {
+		if tx.Currency != currency && (req.Account == "" || tx.Account == req.Account) {
+			converted := *tx
+			if converted.AmountCents, err = conv.Convert(ctx, tx.AmountCents, tx.Currency, currency, tx.Date); err != nil {
+				return nil, err
+			}
+			converted.Currency = currency
+			tx = &converted
+		}
+		in.History = append(in.History, tx)
+	}
+	f, err := Project(in)
+	if err != nil {
+		return nil, err
+	}
+	f.Currency = currency
+	return f, nil
+}
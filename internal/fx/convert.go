@@ -0,0 +1,66 @@
+package fx
+
+import (
+	"context"
+	"time"
+)
+
+// Shared interfaces used by multiple use cases
+
+type RateFetcher interface {
+	// RateOn returns the rate of the currency on the last day on or before
+	// the date that has one, the first later rate when there is none
+	// before it and ErrNoRate when the currency has no rates at all.
+	RateOn(ctx context.Context, currency string, date time.Time) (*Rate, error)
+}
+
+// Converter converts amounts between currencies using the reference rates
+// of the day they were booked on. Rates are cached, a Converter is meant
+// for a single request or job run.
+type Converter struct {
+	rf    RateFetcher
+	rates map[rateKey]int64
+}
+
+type rateKey struct {
+	currency string
+	date     time.Time
+}
+
+func NewConverter(rf RateFetcher) *Converter {
+	return &Converter{rf: rf, rates: map[rateKey]int64{}}
+}
+
+// Convert converts the cents from one currency to the other on the date,
+// amounts already in the target currency are returned as is.
+func (c *Converter) Convert(ctx context.Context, cents int64, from, to string, date time.Time) (int64, error) {
+	if from == to || cents == 0 {
+		return cents, nil
+	}
+	fromMicros, err := c.rate(ctx, from, date)
+	if err != nil {
+		return 0, err
+	}
+	toMicros, err := c.rate(ctx, to, date)
+	if err != nil {
+		return 0, err
+	}
+	return Convert(cents, from, to, fromMicros, toMicros), nil
+}
+
+func (c *Converter) rate(ctx context.Context, currency string, date time.Time) (int64, error) {
+	if currency == Base {
+		return 1_000_000, nil
+	}
+	y, m, d := date.Date()
+	key := rateKey{currency: currency, date: time.Date(y, m, d, 0, 0, 0, 0, time.UTC)}
+	if micros, ok := c.rates[key]; ok {
+		return micros, nil
+	}
+	r, err := c.rf.RateOn(ctx, currency, key.date)
+	if err != nil {
+		return 0, err
+	}
+	c.rates[key] = r.RateMicros
+	return r.RateMicros, nil
+}
@@ -0,0 +1,193 @@
+package fx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnknownFormat = fmt.Errorf("the file is neither an ECB reference rate CSV, XML nor ZIP file")
+	ErrInvalidRow    = fmt.Errorf("invalid row")
+)
+
+// ParseResult holds the rates of a parsed file. Currencies the ECB did not
+// quote on a day, written as N/A, are skipped.
+type ParseResult struct {
+	Rates   []*Rate
+	Skipped int
+}
+
+// ParseECB parses the euro foreign exchange reference rates as published by
+// the ECB: the daily and historic CSV files (eurofxref.csv,
+// eurofxref-hist.csv), the XML files (eurofxref-daily.xml,
+// eurofxref-hist.xml, eurofxref-hist-90d.xml) and the ZIP archives holding
+// them. The format is detected from the content.
+func ParseECB(r io.Reader) (*ParseResult, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("fx: failed to read file: %w", err)
+	}
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\ufeff")))
+	switch {
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		return parseZip(data)
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return parseXML(trimmed)
+	case len(trimmed) > 0:
+		return parseCSV(trimmed)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+func parseZip(data []byte) (*ParseResult, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownFormat, err)
+	}
+	res := &ParseResult{}
+	for _, f := range archive.File {
+		switch strings.ToLower(path.Ext(f.Name)) {
+		case ".csv", ".xml":
+		default:
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("fx: failed to open %s: %w", f.Name, err)
+		}
+		part, err := ParseECB(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		res.Rates = append(res.Rates, part.Rates...)
+		res.Skipped += part.Skipped
+	}
+	if len(res.Rates) == 0 && res.Skipped == 0 {
+		return nil, ErrUnknownFormat
+	}
+	return res, nil
+}
+
+// parseCSV parses a header of currencies followed by a row per day, the
+// daily file writes its date as 17 October 2025 and pads the fields with
+// spaces.
+func parseCSV(data []byte) (*ParseResult, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownFormat, err)
+	}
+	if len(header) < 2 || !strings.EqualFold(strings.TrimSpace(header[0]), "date") {
+		return nil, ErrUnknownFormat
+	}
+	res := &ParseResult{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w on line %d: %v", ErrInvalidRow, line, err)
+		}
+		date, err := parseDate(record[0])
+		if err != nil {
+			return nil, fmt.Errorf("%w on line %d: %v", ErrInvalidRow, line, err)
+		}
+		for i := 1; i < len(header) && i < len(record); i++ {
+			currency := strings.TrimSpace(header[i])
+			if currency == "" {
+				// the files end every line with a comma
+				continue
+			}
+			rate, skip, err := newRate(date, currency, record[i])
+			if err != nil {
+				return nil, fmt.Errorf("%w on line %d: %v", ErrInvalidRow, line, err)
+			}
+			if skip {
+				res.Skipped++
+				continue
+			}
+			res.Rates = append(res.Rates, rate)
+		}
+	}
+}
+
+// ecbEnvelope is the gesmes envelope of the XML files, a cube per day
+// holding a cube per currency.
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+func parseXML(data []byte) (*ParseResult, error) {
+	var envelope ecbEnvelope
+	if err := xml.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownFormat, err)
+	}
+	if len(envelope.Days) == 0 {
+		return nil, ErrUnknownFormat
+	}
+	res := &ParseResult{}
+	for _, day := range envelope.Days {
+		date, err := parseDate(day.Time)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRow, err)
+		}
+		for _, r := range day.Rates {
+			rate, skip, err := newRate(date, r.Currency, r.Rate)
+			if err != nil {
+				return nil, fmt.Errorf("%w on %s: %v", ErrInvalidRow, day.Time, err)
+			}
+			if skip {
+				res.Skipped++
+				continue
+			}
+			res.Rates = append(res.Rates, rate)
+		}
+	}
+	return res, nil
+}
+
+// newRate returns skip for rates the ECB did not publish and for the base
+// currency itself.
+func newRate(date time.Time, currency, value string) (*Rate, bool, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.EqualFold(value, "N/A") || strings.EqualFold(strings.TrimSpace(currency), Base) {
+		return nil, true, nil
+	}
+	micros, err := ParseRate(value)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid %s rate %q", currency, value)
+	}
+	rate, err := NewRate(date, currency, micros)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid currency %q", currency)
+	}
+	return rate, false, nil
+}
+
+func parseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{time.DateOnly, "2 January 2006"} {
+		if d, err := time.Parse(layout, s); err == nil {
+			return d, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
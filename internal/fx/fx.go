@@ -0,0 +1,101 @@
+package fx
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/money"
+)
+
+// Base is the currency the ECB reference rates are quoted against, its rate
+// is always one.
+const Base = "EUR"
+
+var (
+	ErrInvalidCurrency = fmt.Errorf("currency must be a three letter ISO 4217 code")
+	ErrInvalidRate     = fmt.Errorf("rate must be a positive number")
+	ErrNoRate          = fmt.Errorf("no exchange rate, import the ECB reference rates of the currency")
+)
+
+// Rate is the ECB reference rate of a currency on a day, the amount of the
+// currency one euro buys in millionths.
+type Rate struct {
+	Date       time.Time `db:"date"`
+	Currency   string    `db:"currency"`
+	RateMicros int64     `db:"rate_micros"`
+}
+
+func NewRate(date time.Time, currency string, rateMicros int64) (*Rate, error) {
+	currency, err := NormaliseCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+	if rateMicros <= 0 {
+		return nil, ErrInvalidRate
+	}
+	y, m, d := date.Date()
+	return &Rate{Date: time.Date(y, m, d, 0, 0, 0, 0, time.UTC), Currency: currency, RateMicros: rateMicros}, nil
+}
+
+// String formats the rate as a decimal such as 1.0845.
+func (r *Rate) String() string {
+	frac := strings.TrimRight(fmt.Sprintf("%06d", r.RateMicros%1_000_000), "0")
+	for len(frac) < 2 {
+		frac += "0"
+	}
+	return fmt.Sprintf("%d.%s", r.RateMicros/1_000_000, frac)
+}
+
+// ParseRate parses a decimal rate such as 1.0845 into millionths.
+func ParseRate(s string) (int64, error) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || f <= 0 || math.IsInf(f, 0) {
+		return 0, ErrInvalidRate
+	}
+	return int64(math.Round(f * 1e6)), nil
+}
+
+// NormaliseCurrency upper cases the currency and checks it is a three letter
+// code.
+func NormaliseCurrency(currency string) (string, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if len(currency) != 3 {
+		return "", ErrInvalidCurrency
+	}
+	for _, r := range currency {
+		if r < 'A' || r > 'Z' {
+			return "", ErrInvalidCurrency
+		}
+	}
+	return currency, nil
+}
+
+// Convert converts cents between two currencies given their rates against
+// the base currency, rounding half away from zero. The cents are the minor
+// units of each currency, converting 100 EUR cents to JPY gives whole yen.
+func Convert(cents int64, from, to string, fromMicros, toMicros int64) int64 {
+	fromExp, toExp := money.Exponent(from), money.Exponent(to)
+	if fromMicros == toMicros && fromExp == toExp {
+		return cents
+	}
+	n := new(big.Int).Mul(big.NewInt(cents), big.NewInt(toMicros))
+	d := big.NewInt(fromMicros)
+	if toExp > fromExp {
+		n.Mul(n, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(toExp-fromExp)), nil))
+	} else if fromExp > toExp {
+		d.Mul(d, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(fromExp-toExp)), nil))
+	}
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+	if r.Abs(r).Mul(r, big.NewInt(2)).Cmp(d) >= 0 {
+		if n.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q.Int64()
+}
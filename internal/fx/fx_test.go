@@ -0,0 +1,92 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name       string
+		cents      int64
+		from, to   string
+		fromMicros int64
+		toMicros   int64
+		want       int64
+	}{
+		{"euro to dollar", 1000, "EUR", "USD", 1000000, 1100000, 1100},
+		{"dollar to euro", 1000, "USD", "EUR", 1100000, 1000000, 909},
+		{"half away from zero", 5, "EUR", "USD", 1000000, 1100000, 6},
+		{"negative half away from zero", -5, "EUR", "USD", 1000000, 1100000, -6},
+		{"same rate", 1234, "USD", "USD", 1100000, 1100000, 1234},
+		// 100.00 EUR at 160.25 yen
+		{"euro to yen", 10000, "EUR", "JPY", 1000000, 160250000, 16025},
+		{"yen to euro", 16025, "JPY", "EUR", 160250000, 1000000, 10000},
+		// 100.00 EUR at 0.41 dinar is 41.000 BHD
+		{"euro to dinar", 10000, "EUR", "BHD", 1000000, 410000, 41000},
+		{"dinar to euro", 41000, "BHD", "EUR", 410000, 1000000, 10000},
+		// 1000 yen is 2.5625 BHD
+		{"yen to dinar", 1000, "JPY", "BHD", 160000000, 410000, 2563},
+		{"dinar to yen", 2563, "BHD", "JPY", 410000, 160000000, 1000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Convert(tt.cents, tt.from, tt.to, tt.fromMicros, tt.toMicros); got != tt.want {
+				t.Errorf("Convert() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+type fakeRates struct {
+	micros map[string]int64
+	calls  int
+}
+
+func (f *fakeRates) RateOn(ctx context.Context, currency string, date time.Time) (*Rate, error) {
+	f.calls++
+	micros, ok := f.micros[currency]
+	if !ok {
+		return nil, ErrNoRate
+	}
+	return NewRate(date, currency, micros)
+}
+
+func TestConverterConvert(t *testing.T) {
+	rates := &fakeRates{micros: map[string]int64{"USD": 1100000, "JPY": 160000000}}
+	c := NewConverter(rates)
+	ctx := context.Background()
+	day := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		cents    int64
+		from, to string
+		want     int64
+		wantErr  error
+	}{
+		{"same currency", 1234, "USD", "USD", 1234, nil},
+		{"zero", 0, "USD", "CHF", 0, nil},
+		{"from the base currency", 1000, "EUR", "USD", 1100, nil},
+		{"to the base currency", 1100, "USD", "EUR", 1000, nil},
+		{"through the base currency", 1100, "USD", "JPY", 1600, nil},
+		{"without a rate", 1000, "CHF", "EUR", 0, ErrNoRate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.Convert(ctx, tt.cents, tt.from, tt.to, day)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Convert() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Convert() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+	// USD and JPY were fetched once, CHF failed
+	if rates.calls != 3 {
+		t.Errorf("RateOn() called %d times, want 3", rates.calls)
+	}
+}
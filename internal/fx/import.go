@@ -0,0 +1,126 @@
+package fx
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Single-use interfaces only used by ImportHandler
+
+type RateUpserter interface {
+	// Upsert stores the rates, a rate that is already stored for the
+	// currency and day is overwritten.
+	Upsert(ctx context.Context, rates []*Rate) error
+}
+
+// ImportResult summarises an imported file.
+type ImportResult struct {
+	Rates      int
+	Skipped    int
+	Currencies []string
+	// From and To are the first and last day of the file, zero when it held
+	// no rates.
+	From time.Time
+	To   time.Time
+}
+
+// ImportHandler imports the rates of an ECB reference rate file.
+type ImportHandler struct {
+	u RateUpserter
+}
+
+func NewImportHandler(u RateUpserter) *ImportHandler {
+	return &ImportHandler{u: u}
+}
+
+// Handle parses the file and stores its rates, re-importing an overlapping
+// file corrects the days it contains.
+func (h *ImportHandler) Handle(ctx context.Context, r io.Reader) (*ImportResult, error) {
+	parsed, err := ParseECB(r)
+	if err != nil {
+		return nil, err
+	}
+	res := &ImportResult{Rates: len(parsed.Rates), Skipped: parsed.Skipped, Currencies: []string{}}
+	for _, rate := range parsed.Rates {
+		if !slices.Contains(res.Currencies, rate.Currency) {
+			res.Currencies = append(res.Currencies, rate.Currency)
+		}
+		if res.From.IsZero() || rate.Date.Before(res.From) {
+			res.From = rate.Date
+		}
+		if rate.Date.After(res.To) {
+			res.To = rate.Date
+		}
+	}
+	slices.Sort(res.Currencies)
+	if len(parsed.Rates) == 0 {
+		return res, nil
+	}
+	if err := h.u.Upsert(ctx, parsed.Rates); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ScanHandler imports the ECB files placed in a folder, a file is imported
+// again when it changed since the last scan.
+type ScanHandler struct {
+	importer *ImportHandler
+	dir      string
+	seen     map[string]time.Time
+}
+
+func NewScanHandler(importer *ImportHandler, dir string) *ScanHandler {
+	return &ScanHandler{importer: importer, dir: dir, seen: map[string]time.Time{}}
+}
+
+// Handle imports the new and changed .csv, .xml and .zip files in the folder
+// and returns the results per file name. A file that fails to import is
+// retried on the next scan, the others are still imported.
+func (h *ScanHandler) Handle(ctx context.Context) (map[string]*ImportResult, map[string]error, error) {
+	entries, err := os.ReadDir(h.dir)
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	imported := map[string]*ImportResult{}
+	failed := map[string]error{}
+	for _, e := range entries {
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".csv", ".xml", ".zip":
+		default:
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if modified, ok := h.seen[e.Name()]; ok && modified.Equal(info.ModTime()) {
+			continue
+		}
+		res, err := h.importFile(ctx, filepath.Join(h.dir, e.Name()))
+		if err != nil {
+			failed[e.Name()] = err
+			continue
+		}
+		h.seen[e.Name()] = info.ModTime()
+		imported[e.Name()] = res
+	}
+	return imported, failed, nil
+}
+
+func (h *ScanHandler) importFile(ctx context.Context, path string) (*ImportResult, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return h.importer.Handle(ctx, f)
+}
@@ -1,11 +1,15 @@
 package http
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"reflect"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -28,18 +32,44 @@ func JSONDecoder[T any](r *http.Request) (T, error) {
 func QueryDecoder[T any](r *http.Request) (T, error) {
 	var target T
 	values := r.URL.Query()
+	if err := bindValues(&target, "query", values.Get); err != nil {
+		return target, err
+	}
+	if err := bindValues(&target, "path", r.PathValue); err != nil {
+		return target, err
+	}
+	return target, nil
+}
+
+// JSONPathDecoder decodes the request body as JSON and afterwards populates
+// the fields tagged with `path:"name"` from the matched route pattern.
+func JSONPathDecoder[T any](r *http.Request) (T, error) {
+	var req T
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			return req, err
+		}
+	}
+	if err := bindValues(&req, "path", r.PathValue); err != nil {
+		return req, err
+	}
+	return req, nil
+}
 
-	v := reflect.ValueOf(&target).Elem()
+// bindValues populates the fields of target that carry the given struct tag
+// with the values returned by lookup. Empty values are treated as optional.
+func bindValues[T any](target *T, tagName string, lookup func(string) string) error {
+	v := reflect.ValueOf(target).Elem()
 	t := v.Type()
 
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
-		tag := field.Tag.Get("query")
+		tag := field.Tag.Get(tagName)
 		if tag == "" {
 			continue
 		}
 
-		val := values.Get(tag)
+		val := lookup(tag)
 		if val == "" {
 			continue // optional param
 		}
@@ -49,32 +79,62 @@ func QueryDecoder[T any](r *http.Request) (T, error) {
 			continue
 		}
 
+		switch f.Interface().(type) {
+		case uuid.UUID:
+			id, err := uuid.Parse(val)
+			if err != nil {
+				return fmt.Errorf("invalid UUID for %s: %w", tag, err)
+			}
+			f.Set(reflect.ValueOf(id))
+			continue
+		case time.Time:
+			d, err := time.Parse(time.DateOnly, val)
+			if err != nil {
+				return fmt.Errorf("invalid date for %s: %w", tag, err)
+			}
+			f.Set(reflect.ValueOf(d))
+			continue
+		}
+
 		switch f.Kind() {
 		case reflect.String:
 			f.SetString(val)
 		case reflect.Int, reflect.Int64:
 			i, err := strconv.Atoi(val)
 			if err != nil {
-				return target, fmt.Errorf("invalid int for %s: %w", tag, err)
+				return fmt.Errorf("invalid int for %s: %w", tag, err)
 			}
 			f.SetInt(int64(i))
 		case reflect.Float64:
 			fv, err := strconv.ParseFloat(val, 64)
 			if err != nil {
-				return target, fmt.Errorf("invalid float for %s: %w", tag, err)
+				return fmt.Errorf("invalid float for %s: %w", tag, err)
 			}
 			f.SetFloat(fv)
 		case reflect.Bool:
 			bv, err := strconv.ParseBool(val)
 			if err != nil {
-				return target, fmt.Errorf("invalid bool for %s: %w", tag, err)
+				return fmt.Errorf("invalid bool for %s: %w", tag, err)
 			}
 			f.SetBool(bv)
+		case reflect.Slice:
+			// lists are passed comma separated, e.g. ?labels=a,b
+			if f.Type().Elem().Kind() != reflect.String {
+				continue
+			}
+			parts := strings.Split(val, ",")
+			list := reflect.MakeSlice(f.Type(), 0, len(parts))
+			for _, p := range parts {
+				if p = strings.TrimSpace(p); p != "" {
+					list = reflect.Append(list, reflect.ValueOf(p).Convert(f.Type().Elem()))
+				}
+			}
+			f.Set(list)
 		default:
 			// silently ignore unsupported types
 		}
 	}
-	return target, nil
+	return nil
 }
 
 func encode[T any](w http.ResponseWriter, status int, v T) error {
@@ -86,6 +146,45 @@ func encode[T any](w http.ResponseWriter, status int, v T) error {
 	return nil
 }
 
+func encodeCSV(w http.ResponseWriter, status int, m CSVMarshaler) error {
+	header, rows := m.MarshalCSV()
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.WriteHeader(status)
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("encode csv: %w", err)
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("encode csv: %w", err)
+	}
+	return nil
+}
+
+// acceptsCSV reports whether the Accept header prefers text/csv over JSON.
+// CSV has to be asked for explicitly, wildcards keep the JSON default.
+func acceptsCSV(r *http.Request) bool {
+	var csvQ, jsonQ float64
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		params := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		q := 1.0
+		for _, p := range params[1:] {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(p), "q="); ok {
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					q = f
+				}
+			}
+		}
+		switch mediaType {
+		case "text/csv":
+			csvQ = max(csvQ, q)
+		case "application/json":
+			jsonQ = max(jsonQ, q)
+		}
+	}
+	return csvQ > 0 && csvQ > jsonQ
+}
+
 // DecodeMultipartFile is a generic decoder that extracts a multipart file
 // and populates a struct with both file metadata and extra form fields.
 //
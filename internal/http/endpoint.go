@@ -13,6 +13,12 @@ type Validator interface {
 	Valid(ctx context.Context) map[string]string
 }
 
+// CSVMarshaler is implemented by responses that can also be written as CSV,
+// they are when the client prefers text/csv in its Accept header.
+type CSVMarshaler interface {
+	MarshalCSV() (header []string, rows [][]string)
+}
+
 // endpoint creates a wrapper for endpoint logic.
 // endpoint and returns a handler func. It decodes the request into a usable
 // model which it passes into the fn HandlerFunc.
@@ -41,11 +47,22 @@ func Endpoint[T any, R any](decode DecoderFunc[T], log logging.Logger, fn Endpoi
 		// here we call the handler function that satisfies the type defined
 		// above. we pass the request context and the decoded context.
 		status, res, err := fn(r.Context(), req)
+		if err != nil && status >= 400 && status < 500 {
+			// handlers signal client errors (not found, conflicts, ...) by
+			// returning a 4xx status together with the error, these are
+			// passed through to the client instead of being masked as a 500
+			_ = encode(w, status, map[string]string{"error": err.Error()})
+			return
+		}
 		if err != nil {
 			log.Error(r.Context(), "handle: an error occurred while handling a request", err)
 			_ = encode(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
 			return
 		}
+		if m, ok := any(res).(CSVMarshaler); ok && acceptsCSV(r) {
+			_ = encodeCSV(w, status, m)
+			return
+		}
 		_ = encode(w, status, res)
 	}
 }
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/api"
+	"github.com/lennardclaproth/my-finances-tracker/internal/balance"
+	"github.com/lennardclaproth/my-finances-tracker/internal/fx"
+	httpx "github.com/lennardclaproth/my-finances-tracker/internal/http"
+	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
+)
+
+// ListAccounts lists the accounts with their current balance.
+//
+// @Summary     List accounts
+// @Description List the accounts that have transactions or balance anchors with their balance at the end of today
+// @Accept      json
+// @Produce     application/json
+// @Success     200 {array}  api.Account "Accounts"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /accounts [get]
+// @Tags        Accounts
+func ListAccounts(log logging.Logger, transactions *storage.SQLXTransactionStore, store *storage.SQLXBalanceStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req struct{}) (status int, res []api.Account, err error) {
+		accounts, err := store.Accounts(ctx)
+		if err != nil {
+			return http.StatusInternalServerError, nil, err
+		}
+		handler := balance.NewReconstructHandler(transactions, store, store)
+		res = make([]api.Account, 0, len(accounts))
+		for _, a := range accounts {
+			if a.ID == "" {
+				continue
+			}
+			cents, err := handler.Balance(ctx, a.ID)
+			if err != nil {
+				return http.StatusInternalServerError, nil, err
+			}
+			res = append(res, toAccount(a, cents))
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[struct{}], log, endpoint)
+}
+
+// UpdateAccount changes the name, type or currency of an account.
+//
+// @Summary     Update an account
+// @Description Change the name, the type (checking, savings, investment) or the ISO 4217 currency of an account. The type decides the asset class of the account in the net worth, the currency how its balance is converted to the reporting currency.
+// @Accept      json
+// @Produce     application/json
+// @Param       id      path     string                   true "Account (usually its IBAN)"
+// @Param       request body     api.UpdateAccountRequest true "Fields to change"
+// @Success     200     {object} api.Account "Updated account"
+// @Failure     400     {object} map[string]string "Bad request"
+// @Failure     500     {object} map[string]string "Internal server error"
+// @Router      /accounts/{id} [patch]
+// @Tags        Accounts
+func UpdateAccount(log logging.Logger, transactions *storage.SQLXTransactionStore, store *storage.SQLXBalanceStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.UpdateAccountRequest) (status int, res api.Account, err error) {
+		a, err := store.FetchAccount(ctx, req.ID)
+		if err != nil {
+			return balanceErrorStatus(err), res, err
+		}
+		if req.Name != nil {
+			a.Name = *req.Name
+		}
+		if req.Type != nil {
+			if a.Type, err = balance.ParseAccountType(*req.Type); err != nil {
+				return balanceErrorStatus(err), res, err
+			}
+		}
+		if req.Currency != nil {
+			if a.Currency, err = fx.NormaliseCurrency(*req.Currency); err != nil {
+				return balanceErrorStatus(err), res, err
+			}
+		}
+		a.UpdatedAt = time.Now().UTC()
+		if err := store.SaveAccount(ctx, a); err != nil {
+			return balanceErrorStatus(err), res, err
+		}
+		cents, err := balance.NewReconstructHandler(transactions, store, store).Balance(ctx, a.ID)
+		if err != nil {
+			return balanceErrorStatus(err), res, err
+		}
+		return http.StatusOK, toAccount(a, cents), nil
+	}
+	return httpx.Endpoint(httpx.JSONPathDecoder[api.UpdateAccountRequest], log, endpoint)
+}
+
+// AccountBalances reconstructs the daily balances of an account.
+//
+// @Summary     Daily balances
+// @Description Reconstruct the closing balance of every day from the balance anchors and the transactions. Days on which the balance reported by the bank statement differs from the reconstructed one are flagged, this usually means transactions are missing.
+// @Accept      json
+// @Produce     application/json
+// @Param       id   path     string true  "Account (usually its IBAN)"
+// @Param       from query    string false "First date (YYYY-MM-DD), defaults to 30 days before to"
+// @Param       to   query    string false "Last date (YYYY-MM-DD), defaults to today"
+// @Success     200 {object} api.AccountBalances "Balance per day"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /accounts/{id}/balances [get]
+// @Tags        Accounts
+func AccountBalances(log logging.Logger, transactions *storage.SQLXTransactionStore, store *storage.SQLXBalanceStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.AccountBalancesRequest) (status int, res api.AccountBalances, err error) {
+		to := req.To
+		if to.IsZero() {
+			to = time.Now().UTC()
+		}
+		from := req.From
+		if from.IsZero() {
+			from = to.AddDate(0, 0, -30)
+		}
+		handler := balance.NewReconstructHandler(transactions, store, store)
+		days, err := handler.Handle(ctx, req.ID, from, to)
+		if err != nil {
+			return balanceErrorStatus(err), res, err
+		}
+		res = api.AccountBalances{
+			Account: req.ID,
+			From:    from.Format(time.DateOnly),
+			To:      to.Format(time.DateOnly),
+			Days:    make([]api.DailyBalance, 0, len(days)),
+		}
+		for _, d := range days {
+			if d.Mismatch() {
+				res.Mismatches++
+			}
+			res.Days = append(res.Days, api.DailyBalance{
+				Date:            d.Date.Format(time.DateOnly),
+				NetCents:        d.NetCents,
+				BalanceCents:    d.BalanceCents,
+				ReportedCents:   d.ReportedCents,
+				Mismatch:        d.Mismatch(),
+				DifferenceCents: d.DifferenceCents(),
+				Anchored:        d.Anchored,
+			})
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.AccountBalancesRequest], log, endpoint)
+}
+
+// ListBalanceAnchors lists the balance anchors of an account.
+//
+// @Summary     List balance anchors
+// @Description List the known closing balances of an account, oldest first
+// @Accept      json
+// @Produce     application/json
+// @Param       id  path     string true "Account (usually its IBAN)"
+// @Success     200 {array}  api.BalanceAnchor "Anchors"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /accounts/{id}/anchors [get]
+// @Tags        Accounts
+func ListBalanceAnchors(log logging.Logger, store *storage.SQLXBalanceStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.AccountRequest) (status int, res []api.BalanceAnchor, err error) {
+		anchors, err := store.Anchors(ctx, req.ID)
+		if err != nil {
+			return http.StatusInternalServerError, nil, err
+		}
+		res = make([]api.BalanceAnchor, 0, len(anchors))
+		for _, a := range anchors {
+			res = append(res, toBalanceAnchor(a))
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.AccountRequest], log, endpoint)
+}
+
+// SetBalanceAnchor sets the closing balance of an account on a day.
+//
+// @Summary     Set a balance anchor
+// @Description Set the closing balance of an account at the end of a day, e.g. from a bank statement. Balances of other days are reconstructed from the nearest anchor. An existing anchor on the same day is replaced.
+// @Accept      json
+// @Produce     application/json
+// @Param       id      path     string                   true "Account (usually its IBAN)"
+// @Param       date    path     string                   true "Day (YYYY-MM-DD)"
+// @Param       request body     api.BalanceAnchorRequest true "Closing balance"
+// @Success     200     {object} api.BalanceAnchor "Saved anchor"
+// @Failure     400     {object} map[string]string "Bad request"
+// @Failure     500     {object} map[string]string "Internal server error"
+// @Router      /accounts/{id}/anchors/{date} [put]
+// @Tags        Accounts
+func SetBalanceAnchor(log logging.Logger, store *storage.SQLXBalanceStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.BalanceAnchorRequest) (status int, res api.BalanceAnchor, err error) {
+		// the date was checked by Valid
+		date, _ := time.Parse(time.DateOnly, req.Date)
+		a, err := balance.NewAnchor(req.Account, date, req.BalanceCents)
+		if err != nil {
+			return balanceErrorStatus(err), res, err
+		}
+		if err := store.SaveAnchor(ctx, a); err != nil {
+			return balanceErrorStatus(err), res, err
+		}
+		return http.StatusOK, toBalanceAnchor(a), nil
+	}
+	return httpx.Endpoint(httpx.JSONPathDecoder[api.BalanceAnchorRequest], log, endpoint)
+}
+
+// DeleteBalanceAnchor deletes a balance anchor.
+//
+// @Summary     Delete a balance anchor
+// @Description Delete the closing balance of an account on a day
+// @Accept      json
+// @Produce     application/json
+// @Param       id   path     string true "Account (usually its IBAN)"
+// @Param       date path     string true "Day (YYYY-MM-DD)"
+// @Success     200 {object} map[string]string "OK"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     404 {object} map[string]string "Anchor not found"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /accounts/{id}/anchors/{date} [delete]
+// @Tags        Accounts
+func DeleteBalanceAnchor(log logging.Logger, store *storage.SQLXBalanceStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.BalanceAnchorRequest) (status int, res struct{}, err error) {
+		date, _ := time.Parse(time.DateOnly, req.Date)
+		if err := store.DeleteAnchor(ctx, req.Account, date); err != nil {
+			return balanceErrorStatus(err), res, err
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.BalanceAnchorRequest], log, endpoint)
+}
+
+func toAccount(a *balance.Account, balanceCents int64) api.Account {
+	return api.Account{ID: a.ID, Name: a.Name, Type: string(a.Type), Currency: a.Currency, BalanceCents: balanceCents}
+}
+
+func toBalanceAnchor(a *balance.Anchor) api.BalanceAnchor {
+	return api.BalanceAnchor{
+		Account:      a.Account,
+		Date:         a.Date.Format(time.DateOnly),
+		BalanceCents: a.BalanceCents,
+	}
+}
+
+func balanceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, balance.ErrAnchorNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, balance.ErrInvalidAccount),
+		errors.Is(err, balance.ErrInvalidAccountType),
+		errors.Is(err, balance.ErrInvalidRange),
+		errors.Is(err, fx.ErrInvalidCurrency):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/api"
+	"github.com/lennardclaproth/my-finances-tracker/internal/budget"
+	"github.com/lennardclaproth/my-finances-tracker/internal/category"
+	httpx "github.com/lennardclaproth/my-finances-tracker/internal/http"
+	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+	"github.com/lennardclaproth/my-finances-tracker/internal/report"
+	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
+)
+
+// ListBudgets returns all budgets.
+//
+// @Summary     List budgets
+// @Description List the budgets of all categories
+// @Accept      json
+// @Produce     application/json
+// @Success     200 {array}  api.Budget "Budgets"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /budgets [get]
+// @Tags        Budgets
+func ListBudgets(log logging.Logger, store *storage.SQLXBudgetStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req struct{}) (status int, res []api.Budget, err error) {
+		budgets, err := store.List(ctx)
+		if err != nil {
+			return http.StatusInternalServerError, nil, err
+		}
+		res = make([]api.Budget, 0, len(budgets))
+		for _, b := range budgets {
+			res = append(res, toBudget(b))
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[struct{}], log, endpoint)
+}
+
+// CreateBudget creates a budget for a category.
+//
+// @Summary     Create a budget
+// @Description Create a budget for an expense category, spending in its subcategories counts towards it. Periods are calendar weeks, months, quarters or years.
+// @Accept      application/json
+// @Produce     application/json
+// @Param       payload body     api.CreateBudgetRequest true "Budget"
+// @Success     201 {object} api.Budget "Created budget"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     404 {object} map[string]string "Category not found"
+// @Failure     409 {object} map[string]string "Category already has a budget for the period"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /budgets [post]
+// @Tags        Budgets
+func CreateBudget(log logging.Logger, store *storage.SQLXBudgetStore, categories *storage.SQLXCategoryStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.CreateBudgetRequest) (status int, res api.Budget, err error) {
+		var start time.Time
+		if req.StartDate != "" {
+			start, _ = time.Parse(time.DateOnly, req.StartDate)
+		}
+		handler := budget.NewCreateHandler(categories, store)
+		b, err := handler.Handle(ctx, req.CategoryID, req.AmountCents, report.Interval(req.Period), start, req.Rollover)
+		if err != nil {
+			return budgetErrorStatus(err), res, err
+		}
+		return http.StatusCreated, toBudget(b), nil
+	}
+	return httpx.Endpoint(httpx.JSONDecoder[api.CreateBudgetRequest], log, endpoint)
+}
+
+// UpdateBudget changes a budget.
+//
+// @Summary     Update a budget
+// @Description Change the category, amount, period, start date or rollover of a budget
+// @Accept      application/json
+// @Produce     application/json
+// @Param       id      path     string                  true "Budget ID"
+// @Param       payload body     api.UpdateBudgetRequest true "Changes"
+// @Success     200 {object} api.Budget "Updated budget"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     404 {object} map[string]string "Budget or category not found"
+// @Failure     409 {object} map[string]string "Category already has a budget for the period"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /budgets/{id} [patch]
+// @Tags        Budgets
+func UpdateBudget(log logging.Logger, store *storage.SQLXBudgetStore, categories *storage.SQLXCategoryStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.UpdateBudgetRequest) (status int, res api.Budget, err error) {
+		changes := budget.Changes{
+			CategoryID:  req.CategoryID,
+			AmountCents: req.AmountCents,
+			Rollover:    req.Rollover,
+		}
+		if req.Period != nil {
+			p := report.Interval(*req.Period)
+			changes.Period = &p
+		}
+		if req.StartDate != nil {
+			start, _ := time.Parse(time.DateOnly, *req.StartDate)
+			changes.StartDate = &start
+		}
+		handler := budget.NewUpdateHandler(store, categories, store, store)
+		b, err := handler.Handle(ctx, req.ID, changes)
+		if err != nil {
+			return budgetErrorStatus(err), res, err
+		}
+		return http.StatusOK, toBudget(b), nil
+	}
+	return httpx.Endpoint(httpx.JSONPathDecoder[api.UpdateBudgetRequest], log, endpoint)
+}
+
+// DeleteBudget deletes a budget.
+//
+// @Summary     Delete a budget
+// @Description Delete a budget together with its sent alerts
+// @Accept      json
+// @Produce     application/json
+// @Param       id  path     string true "Budget ID"
+// @Success     200 {object} map[string]string "OK"
+// @Failure     404 {object} map[string]string "Budget not found"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /budgets/{id} [delete]
+// @Tags        Budgets
+func DeleteBudget(log logging.Logger, store *storage.SQLXBudgetStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.BudgetRequest) (status int, res struct{}, err error) {
+		if err := store.Delete(ctx, req.ID); err != nil {
+			return budgetErrorStatus(err), res, err
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.BudgetRequest], log, endpoint)
+}
+
+// BudgetStatus reports the spending against every budget.
+//
+// @Summary     Budget status
+// @Description Report the spending, remaining amount and projected spending by the end of the period for every budget. Amounts are in the reporting currency, spending in other currencies is converted with the rate of its date. Ignored transactions and transfers are left out, refunds lower the spending.
+// @Accept      json
+// @Produce     application/json
+// @Param       period query    string false "Month (YYYY-MM) or day (YYYY-MM-DD) within the periods to report, defaults to today"
+// @Success     200 {array}  api.BudgetStatus "Status per budget"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /budgets/status [get]
+// @Tags        Budgets
+func BudgetStatus(log logging.Logger, store *storage.SQLXBudgetStore, categories *storage.SQLXCategoryStore, reports *storage.SQLXReportStore, currency string) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.BudgetStatusRequest) (status int, res []api.BudgetStatus, err error) {
+		date, err := req.Date()
+		if err != nil {
+			return http.StatusBadRequest, nil, err
+		}
+		handler := budget.NewStatusHandler(store, categories, reports, currency)
+		statuses, err := handler.Handle(ctx, date, time.Now())
+		if err != nil {
+			return budgetErrorStatus(err), nil, err
+		}
+		res = make([]api.BudgetStatus, 0, len(statuses))
+		for _, s := range statuses {
+			res = append(res, api.BudgetStatus{
+				BudgetID:       s.Budget.ID,
+				Category:       s.Category.Slug,
+				CategoryName:   s.Category.Name,
+				Period:         string(s.Budget.Period),
+				PeriodStart:    s.PeriodStart.Format(time.DateOnly),
+				PeriodEnd:      s.PeriodEnd.Format(time.DateOnly),
+				AmountCents:    s.Budget.AmountCents,
+				RolloverCents:  s.RolloverCents,
+				AvailableCents: s.AvailableCents(),
+				SpentCents:     s.SpentCents,
+				RemainingCents: s.RemainingCents(),
+				Percent:        math.Round(s.Percent()*10) / 10,
+				ProjectedCents: s.ProjectedCents,
+			})
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.BudgetStatusRequest], log, endpoint)
+}
+
+func toBudget(b *budget.Budget) api.Budget {
+	return api.Budget{
+		ID:          b.ID,
+		CategoryID:  b.CategoryID,
+		AmountCents: b.AmountCents,
+		Period:      string(b.Period),
+		StartDate:   b.StartDate.Format(time.DateOnly),
+		Rollover:    b.Rollover,
+		CreatedAt:   b.CreatedAt,
+		UpdatedAt:   b.UpdatedAt,
+	}
+}
+
+func budgetErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, budget.ErrBudgetNotFound),
+		errors.Is(err, category.ErrCategoryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, budget.ErrBudgetExists):
+		return http.StatusConflict
+	case errors.Is(err, budget.ErrInvalidAmount),
+		errors.Is(err, budget.ErrInvalidPeriod),
+		errors.Is(err, budget.ErrNotExpense):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/api"
+	"github.com/lennardclaproth/my-finances-tracker/internal/bulk"
+	httpx "github.com/lennardclaproth/my-finances-tracker/internal/http"
+	"github.com/lennardclaproth/my-finances-tracker/internal/label"
+	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// BulkTransactions applies an operation to many transactions at once.
+//
+// @Summary     Bulk transaction operation
+// @Description Set or clear the category, set ignored, add or remove a label or delete manual entries for a list of transactions or all transactions matching a filter. The operation runs in a single database transaction and is recorded so it can be undone. With preview only the number of transactions that would change is returned.
+// @Accept      application/json
+// @Produce     application/json
+// @Param       payload body     api.BulkRequest true "Selection and operation"
+// @Success     200 {object} api.BulkResult "Result"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     404 {object} map[string]string "Transaction not found"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /transactions/bulk [post]
+// @Tags        Transactions
+func BulkTransactions(log logging.Logger, store *storage.SQLXBulkStore, txs *storage.SQLXTransactionStore, labels *storage.SQLXLabelStore, categories *storage.SQLXCategoryStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.BulkRequest) (status int, res api.BulkResult, err error) {
+		op := bulk.Operation{
+			Type:    bulk.OperationType(req.Operation.Type),
+			Tag:     req.Operation.Tag,
+			Ignored: req.Operation.Ignored,
+			Label:   req.Operation.Label,
+		}
+		if op.Type == bulk.SetTag {
+			if status, err := validateCategory(ctx, categories, op.Tag); err != nil {
+				return status, res, err
+			}
+		}
+		var f *transaction.Filter
+		if req.Filter != nil {
+			if f, err = toBulkFilter(*req.Filter); err != nil {
+				return http.StatusBadRequest, res, err
+			}
+		}
+		handler := bulk.NewExecuteHandler(store, txs, txs, labels, store)
+		b, err := handler.Handle(ctx, req.IDs, f, op, req.Preview)
+		if err != nil {
+			return bulkErrorStatus(err), res, err
+		}
+		res = toBulkResult(b)
+		res.Preview = req.Preview
+		if req.Preview || b.Affected == 0 {
+			res.BatchID = nil
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.JSONDecoder[api.BulkRequest], log, endpoint)
+}
+
+// UndoBulkTransactions reverts a bulk operation.
+//
+// @Summary     Undo a bulk operation
+// @Description Restore the transactions changed by a bulk operation to their previous state
+// @Accept      json
+// @Produce     application/json
+// @Param       batchId path     string true "Batch ID"
+// @Success     200 {object} api.BulkResult "Undone batch"
+// @Failure     404 {object} map[string]string "Batch not found"
+// @Failure     409 {object} map[string]string "Batch already undone"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /transactions/bulk/{batchId}/undo [post]
+// @Tags        Transactions
+func UndoBulkTransactions(log logging.Logger, store *storage.SQLXBulkStore, txs *storage.SQLXTransactionStore, labels *storage.SQLXLabelStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.BulkBatchRequest) (status int, res api.BulkResult, err error) {
+		handler := bulk.NewUndoHandler(store, store, store, txs, labels)
+		b, err := handler.Handle(ctx, req.BatchID)
+		if err != nil {
+			return bulkErrorStatus(err), res, err
+		}
+		return http.StatusOK, toBulkResult(b), nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.BulkBatchRequest], log, endpoint)
+}
+
+func toBulkFilter(bf api.BulkFilter) (*transaction.Filter, error) {
+	names, err := label.Names(bf.Labels)
+	if err != nil {
+		return nil, err
+	}
+	f := &transaction.Filter{
+		Tag:            bf.Tag,
+		Direction:      transaction.CashFlowDirection(bf.Direction),
+		CounterpartyID: bf.CounterpartyID,
+		Labels:         names,
+	}
+	if bf.From != "" {
+		if f.From, err = time.Parse(time.DateOnly, bf.From); err != nil {
+			return nil, err
+		}
+	}
+	if bf.To != "" {
+		if f.To, err = time.Parse(time.DateOnly, bf.To); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func bulkErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, bulk.ErrBatchNotFound),
+		errors.Is(err, bulk.ErrTransactionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, bulk.ErrAlreadyUndone):
+		return http.StatusConflict
+	case errors.Is(err, bulk.ErrInvalidOperation),
+		errors.Is(err, bulk.ErrNoSelection),
+		errors.Is(err, label.ErrInvalidName):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func toBulkResult(b *bulk.Batch) api.BulkResult {
+	id := b.ID
+	return api.BulkResult{
+		BatchID: &id,
+		Operation: api.BulkOperation{
+			Type:    string(b.Operation.Type),
+			Tag:     b.Operation.Tag,
+			Ignored: b.Operation.Ignored,
+			Label:   b.Operation.Label,
+		},
+		Affected:  b.Affected,
+		CreatedAt: b.CreatedAt,
+		UndoneAt:  b.UndoneAt,
+	}
+}
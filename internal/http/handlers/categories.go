@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/lennardclaproth/my-finances-tracker/api"
+	"github.com/lennardclaproth/my-finances-tracker/internal/category"
+	httpx "github.com/lennardclaproth/my-finances-tracker/internal/http"
+	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
+)
+
+// ListCategories returns the category tree.
+//
+// @Summary     List categories
+// @Description List the category tree, siblings are ordered by position
+// @Accept      json
+// @Produce     application/json
+// @Success     200 {array}  api.Category "Top level categories with their subcategories"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /categories [get]
+// @Tags        Categories
+func ListCategories(log logging.Logger, store *storage.SQLXCategoryStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req struct{}) (status int, res []api.Category, err error) {
+		all, err := store.List(ctx)
+		if err != nil {
+			return http.StatusInternalServerError, nil, err
+		}
+		tree := category.NewTree(all)
+		return http.StatusOK, toCategories(tree, tree.Roots()), nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[struct{}], log, endpoint)
+}
+
+// CreateCategory creates a category.
+//
+// @Summary     Create a category
+// @Description Create a top level category or a subcategory, the slug used as transaction tag is derived from the name
+// @Accept      application/json
+// @Produce     application/json
+// @Param       payload body     api.CreateCategoryRequest true "Category"
+// @Success     201 {object} api.Category "Created category"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     404 {object} map[string]string "Parent category not found"
+// @Failure     409 {object} map[string]string "Category already exists"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /categories [post]
+// @Tags        Categories
+func CreateCategory(log logging.Logger, store *storage.SQLXCategoryStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.CreateCategoryRequest) (status int, res api.Category, err error) {
+		handler := category.NewCreateHandler(store, store, store)
+		c, err := handler.Handle(ctx, req.Name, req.ParentID, category.Type(req.Type), req.Colour, req.Icon)
+		if err != nil {
+			return categoryErrorStatus(err), res, err
+		}
+		return http.StatusCreated, toCategory(c), nil
+	}
+	return httpx.Endpoint(httpx.JSONDecoder[api.CreateCategoryRequest], log, endpoint)
+}
+
+// UpdateCategory renames, moves or restyles a category.
+//
+// @Summary     Update a category
+// @Description Rename, move, retype or restyle a category. Renaming changes the slug, transactions, rules and counterparties follow.
+// @Accept      application/json
+// @Produce     application/json
+// @Param       id      path     string                    true "Category ID"
+// @Param       payload body     api.UpdateCategoryRequest true "Changes"
+// @Success     200 {object} api.Category "Updated category"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     404 {object} map[string]string "Category not found"
+// @Failure     409 {object} map[string]string "Category already exists"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /categories/{id} [patch]
+// @Tags        Categories
+func UpdateCategory(log logging.Logger, store *storage.SQLXCategoryStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.UpdateCategoryRequest) (status int, res api.Category, err error) {
+		handler := category.NewUpdateHandler(store, store, store, store)
+		changes := category.Changes{
+			Name:     req.Name,
+			ParentID: req.ParentID,
+			Colour:   req.Colour,
+			Icon:     req.Icon,
+		}
+		if req.Type != nil {
+			t := category.Type(*req.Type)
+			changes.Type = &t
+		}
+		c, err := handler.Handle(ctx, req.ID, changes)
+		if err != nil {
+			return categoryErrorStatus(err), res, err
+		}
+		return http.StatusOK, toCategory(c), nil
+	}
+	return httpx.Endpoint(httpx.JSONPathDecoder[api.UpdateCategoryRequest], log, endpoint)
+}
+
+// DeleteCategory deletes a category without subcategories.
+//
+// @Summary     Delete a category
+// @Description Delete a category without subcategories, its transactions move to the parent category or to uncategorised
+// @Accept      json
+// @Produce     application/json
+// @Param       id  path     string true "Category ID"
+// @Success     200 {object} map[string]string "OK"
+// @Failure     400 {object} map[string]string "Category cannot be deleted"
+// @Failure     404 {object} map[string]string "Category not found"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /categories/{id} [delete]
+// @Tags        Categories
+func DeleteCategory(log logging.Logger, store *storage.SQLXCategoryStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.CategoryRequest) (status int, res struct{}, err error) {
+		handler := category.NewDeleteHandler(store, store, store, store)
+		if err := handler.Handle(ctx, req.ID); err != nil {
+			return categoryErrorStatus(err), res, err
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.CategoryRequest], log, endpoint)
+}
+
+// MergeCategories merges categories into the one in the path.
+//
+// @Summary     Merge categories
+// @Description Merge the source categories into the target, the target takes over their transactions, rules and subcategories
+// @Accept      application/json
+// @Produce     application/json
+// @Param       id      path     string                     true "Target category ID"
+// @Param       payload body     api.MergeCategoriesRequest true "Categories to merge"
+// @Success     200 {object} api.Category "Merged category"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     404 {object} map[string]string "Category not found"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /categories/{id}/merge [post]
+// @Tags        Categories
+func MergeCategories(log logging.Logger, store *storage.SQLXCategoryStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.MergeCategoriesRequest) (status int, res api.Category, err error) {
+		handler := category.NewMergeHandler(store, store, store, store, store)
+		c, err := handler.Handle(ctx, req.ID, req.SourceIDs)
+		if err != nil {
+			return categoryErrorStatus(err), res, err
+		}
+		return http.StatusOK, toCategory(c), nil
+	}
+	return httpx.Endpoint(httpx.JSONPathDecoder[api.MergeCategoriesRequest], log, endpoint)
+}
+
+// ReorderCategories changes the order of sibling categories.
+//
+// @Summary     Reorder categories
+// @Description Put sibling categories in the given order, siblings that are not listed follow in their current order
+// @Accept      application/json
+// @Produce     application/json
+// @Param       payload body     api.ReorderCategoriesRequest true "Sibling categories in their new order"
+// @Success     200 {array}  api.Category "Siblings in their new order"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     404 {object} map[string]string "Category not found"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /categories/reorder [post]
+// @Tags        Categories
+func ReorderCategories(log logging.Logger, store *storage.SQLXCategoryStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.ReorderCategoriesRequest) (status int, res []api.Category, err error) {
+		handler := category.NewReorderHandler(store, store, store)
+		ordered, err := handler.Handle(ctx, req.IDs)
+		if err != nil {
+			return categoryErrorStatus(err), nil, err
+		}
+		res = make([]api.Category, 0, len(ordered))
+		for _, c := range ordered {
+			res = append(res, toCategory(c))
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.JSONDecoder[api.ReorderCategoriesRequest], log, endpoint)
+}
+
+// CategoryTotals reports the cash flow per category.
+//
+// @Summary     Category totals
+// @Description Sum incoming and outgoing amounts per category and roll them up to the parent categories. Amounts are converted to the reporting currency with the rate of the transaction date. Ignored transactions are left out, refunds count towards the category of the original purchase.
+// @Accept      json
+// @Produce     application/json
+// @Param       from query    string false "First date (YYYY-MM-DD)"
+// @Param       to   query    string false "Last date (YYYY-MM-DD)"
+// @Success     200 {array}  api.CategoryTotals "Totals per top level category"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /categories/totals [get]
+// @Tags        Categories
+func CategoryTotals(log logging.Logger, store *storage.SQLXCategoryStore, currency string) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.CategoryTotalsRequest) (status int, res []api.CategoryTotals, err error) {
+		all, err := store.List(ctx)
+		if err != nil {
+			return http.StatusInternalServerError, nil, err
+		}
+		totals, err := store.Totals(ctx, req.From, req.To, currency)
+		if err != nil {
+			return http.StatusInternalServerError, nil, err
+		}
+		return http.StatusOK, toCategoryTotals(category.NewTree(all).Rollup(totals)), nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.CategoryTotalsRequest], log, endpoint)
+}
+
+// validateCategory checks that slug refers to an existing category, it
+// returns the status and error to respond with otherwise.
+func validateCategory(ctx context.Context, store *storage.SQLXCategoryStore, slug string) (int, error) {
+	_, err := store.FetchBySlug(ctx, slug)
+	if errors.Is(err, category.ErrCategoryNotFound) {
+		return http.StatusBadRequest, fmt.Errorf("unknown category %q: %w", slug, err)
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return 0, nil
+}
+
+func categoryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, category.ErrCategoryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, category.ErrSlugTaken):
+		return http.StatusConflict
+	case errors.Is(err, category.ErrInvalidName),
+		errors.Is(err, category.ErrInvalidType),
+		errors.Is(err, category.ErrInvalidColour),
+		errors.Is(err, category.ErrTypeMismatch),
+		errors.Is(err, category.ErrCycle),
+		errors.Is(err, category.ErrSystemCategory),
+		errors.Is(err, category.ErrMergeIntoSelf),
+		errors.Is(err, category.ErrHasChildren),
+		errors.Is(err, category.ErrInvalidOrder):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func toCategory(c *category.Category) api.Category {
+	return api.Category{
+		ID:       c.ID,
+		Slug:     c.Slug,
+		Name:     c.Name,
+		ParentID: c.ParentID,
+		Type:     string(c.Type),
+		Colour:   c.Colour,
+		Icon:     c.Icon,
+		Position: c.Position,
+		System:   c.System,
+	}
+}
+
+func toCategories(tree *category.Tree, cs []*category.Category) []api.Category {
+	res := make([]api.Category, 0, len(cs))
+	for _, c := range cs {
+		ac := toCategory(c)
+		if children := tree.Children(c.ID); len(children) > 0 {
+			ac.Children = toCategories(tree, children)
+		}
+		res = append(res, ac)
+	}
+	return res
+}
+
+func toCategoryTotals(nodes []*category.Node) []api.CategoryTotals {
+	res := make([]api.CategoryTotals, 0, len(nodes))
+	for _, n := range nodes {
+		t := api.CategoryTotals{
+			ID:            n.Category.ID,
+			Slug:          n.Category.Slug,
+			Name:          n.Category.Name,
+			Type:          string(n.Category.Type),
+			InCents:       n.Own.InCents,
+			OutCents:      n.Own.OutCents,
+			TotalInCents:  n.Total.InCents,
+			TotalOutCents: n.Total.OutCents,
+		}
+		if len(n.Children) > 0 {
+			t.Children = toCategoryTotals(n.Children)
+		}
+		res = append(res, t)
+	}
+	return res
+}
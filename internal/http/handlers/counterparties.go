@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/lennardclaproth/my-finances-tracker/api"
+	"github.com/lennardclaproth/my-finances-tracker/internal/counterparty"
+	httpx "github.com/lennardclaproth/my-finances-tracker/internal/http"
+	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
+)
+
+// ListCounterparties lists the known counterparties.
+//
+// @Summary     List counterparties
+// @Description List merchants and payees, optionally filtered by name
+// @Accept      json
+// @Produce     application/json
+// @Param       search query    string false "Case insensitive search on the name"
+// @Success     200 {array}  api.Counterparty "Counterparties"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /counterparties [get]
+// @Tags        Counterparties
+func ListCounterparties(log logging.Logger, store *storage.SQLXCounterpartyStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.ListCounterpartiesRequest) (status int, res []api.Counterparty, err error) {
+		cps, err := store.List(ctx, req.Search)
+		if err != nil {
+			return http.StatusInternalServerError, nil, err
+		}
+		res = make([]api.Counterparty, 0, len(cps))
+		for _, cp := range cps {
+			res = append(res, toCounterparty(cp))
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.ListCounterpartiesRequest], log, endpoint)
+}
+
+// GetCounterparty returns a single counterparty.
+//
+// @Summary     Get a counterparty
+// @Description Get a merchant or payee by id
+// @Accept      json
+// @Produce     application/json
+// @Param       id  path     string true "Counterparty ID"
+// @Success     200 {object} api.Counterparty "Counterparty"
+// @Failure     404 {object} map[string]string "Counterparty not found"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /counterparties/{id} [get]
+// @Tags        Counterparties
+func GetCounterparty(log logging.Logger, store *storage.SQLXCounterpartyStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.CounterpartyRequest) (status int, res api.Counterparty, err error) {
+		cp, err := store.FetchByID(ctx, req.ID)
+		if err != nil {
+			return counterpartyErrorStatus(err), res, err
+		}
+		return http.StatusOK, toCounterparty(cp), nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.CounterpartyRequest], log, endpoint)
+}
+
+// UpdateCounterparty updates the canonical name, aliases, IBANs, default tag
+// or logo of a counterparty.
+//
+// @Summary     Update a counterparty
+// @Description Update a counterparty, aliases and IBANs replace the existing lists when given
+// @Accept      application/json
+// @Produce     application/json
+// @Param       id      path     string                        true "Counterparty ID"
+// @Param       payload body     api.UpdateCounterpartyRequest true "Changes"
+// @Success     200 {object} api.Counterparty "Updated counterparty"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     404 {object} map[string]string "Counterparty not found"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /counterparties/{id} [patch]
+// @Tags        Counterparties
+func UpdateCounterparty(log logging.Logger, store *storage.SQLXCounterpartyStore, categories *storage.SQLXCategoryStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.UpdateCounterpartyRequest) (status int, res api.Counterparty, err error) {
+		if req.DefaultTag != nil && *req.DefaultTag != "" {
+			if status, err := validateCategory(ctx, categories, *req.DefaultTag); err != nil {
+				return status, res, err
+			}
+		}
+		handler := counterparty.NewUpdateHandler(store, store)
+		cp, err := handler.Handle(ctx, req.ID, counterparty.Changes{
+			Name:       req.Name,
+			Aliases:    req.Aliases,
+			IBANs:      req.IBANs,
+			DefaultTag: req.DefaultTag,
+			LogoURL:    req.LogoURL,
+		})
+		if err != nil {
+			return counterpartyErrorStatus(err), res, err
+		}
+		return http.StatusOK, toCounterparty(cp), nil
+	}
+	return httpx.Endpoint(httpx.JSONPathDecoder[api.UpdateCounterpartyRequest], log, endpoint)
+}
+
+// MergeCounterparties merges counterparties into the one in the path.
+//
+// @Summary     Merge counterparties
+// @Description Merge the source counterparties into the target, the target takes over their aliases, IBANs and transactions
+// @Accept      application/json
+// @Produce     application/json
+// @Param       id      path     string                         true "Target counterparty ID"
+// @Param       payload body     api.MergeCounterpartiesRequest true "Counterparties to merge"
+// @Success     200 {object} api.Counterparty "Merged counterparty"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     404 {object} map[string]string "Counterparty not found"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /counterparties/{id}/merge [post]
+// @Tags        Counterparties
+func MergeCounterparties(log logging.Logger, store *storage.SQLXCounterpartyStore, txs *storage.SQLXTransactionStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.MergeCounterpartiesRequest) (status int, res api.Counterparty, err error) {
+		handler := counterparty.NewMergeHandler(store, store, store, store, txs)
+		cp, err := handler.Handle(ctx, req.ID, req.SourceIDs)
+		if err != nil {
+			return counterpartyErrorStatus(err), res, err
+		}
+		return http.StatusOK, toCounterparty(cp), nil
+	}
+	return httpx.Endpoint(httpx.JSONPathDecoder[api.MergeCounterpartiesRequest], log, endpoint)
+}
+
+// SplitCounterparty splits aliases and IBANs off into a new counterparty.
+//
+// @Summary     Split a counterparty
+// @Description Move aliases and IBANs, together with the matching transactions, to a new counterparty
+// @Accept      application/json
+// @Produce     application/json
+// @Param       id      path     string                       true "Counterparty ID"
+// @Param       payload body     api.SplitCounterpartyRequest true "Aliases and IBANs to split off"
+// @Success     201 {object} api.Counterparty "New counterparty"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     404 {object} map[string]string "Counterparty not found"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /counterparties/{id}/split [post]
+// @Tags        Counterparties
+func SplitCounterparty(log logging.Logger, store *storage.SQLXCounterpartyStore, txs *storage.SQLXTransactionStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.SplitCounterpartyRequest) (status int, res api.Counterparty, err error) {
+		handler := counterparty.NewSplitHandler(store, store, store, store, txs, txs)
+		cp, err := handler.Handle(ctx, req.ID, req.Name, req.Aliases, req.IBANs)
+		if err != nil {
+			return counterpartyErrorStatus(err), res, err
+		}
+		return http.StatusCreated, toCounterparty(cp), nil
+	}
+	return httpx.Endpoint(httpx.JSONPathDecoder[api.SplitCounterpartyRequest], log, endpoint)
+}
+
+func counterpartyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, counterparty.ErrCounterpartyNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, counterparty.ErrInvalidName),
+		errors.Is(err, counterparty.ErrMergeIntoSelf),
+		errors.Is(err, counterparty.ErrNothingToSplit),
+		errors.Is(err, counterparty.ErrSplitEverything),
+		errors.Is(err, counterparty.ErrInvalidIBAN):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func toCounterparty(cp *counterparty.Counterparty) api.Counterparty {
+	return api.Counterparty{
+		ID:         cp.ID,
+		Name:       cp.Name,
+		Aliases:    cp.Aliases,
+		IBANs:      cp.IBANs,
+		DefaultTag: cp.DefaultTag,
+		LogoURL:    cp.LogoURL,
+	}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/api"
+	"github.com/lennardclaproth/my-finances-tracker/internal/balance"
+	"github.com/lennardclaproth/my-finances-tracker/internal/forecast"
+	"github.com/lennardclaproth/my-finances-tracker/internal/fx"
+	httpx "github.com/lennardclaproth/my-finances-tracker/internal/http"
+	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
+)
+
+// Forecast projects the daily balance of an account.
+//
+// @Summary     Forecast the balance
+// @Description Project the daily balance from the current balance reconstructed from the balance anchors and transactions, the recurring incomes and expenses and the average spending per category outside them. An account is forecast in its own currency, all accounts together in the reporting currency. The low balance date is the first day the balance drops below the threshold.
+// @Accept      json
+// @Produce     application/json
+// @Param       account   query    string false "Account (usually its IBAN), all accounts when empty"
+// @Param       days      query    int    false "Days to project, defaults to 90"
+// @Param       threshold query    int    false "Balance in cents below which to warn, defaults to 0"
+// @Success     200 {object} api.Forecast "Projected balance per day"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     409 {object} map[string]string "No exchange rate for the currency of an account"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /forecast [get]
+// @Tags        Forecast
+func Forecast(log logging.Logger, transactions *storage.SQLXTransactionStore, series *storage.SQLXRecurringStore, balances *storage.SQLXBalanceStore, rates *storage.SQLXFXStore, currency string) http.HandlerFunc {
+	return httpx.Endpoint(httpx.QueryDecoder[api.ForecastRequest], log, forecastEndpoint(transactions, series, balances, rates, currency))
+}
+
+// ForecastWhatIf projects the daily balance of an account including
+// hypothetical incomes and expenses.
+//
+// @Summary     Forecast the balance with what-if entries
+// @Description Same as GET /forecast, additionally projects the given what-if entries and optionally starts from a given balance.
+// @Accept      json
+// @Produce     application/json
+// @Param       request body     api.ForecastRequest true "Forecast request"
+// @Success     200     {object} api.Forecast "Projected balance per day"
+// @Failure     400     {object} map[string]string "Bad request"
+// @Failure     409     {object} map[string]string "No exchange rate for the currency of an account"
+// @Failure     500     {object} map[string]string "Internal server error"
+// @Router      /forecast [post]
+// @Tags        Forecast
+func ForecastWhatIf(log logging.Logger, transactions *storage.SQLXTransactionStore, series *storage.SQLXRecurringStore, balances *storage.SQLXBalanceStore, rates *storage.SQLXFXStore, currency string) http.HandlerFunc {
+	return httpx.Endpoint(httpx.JSONDecoder[api.ForecastRequest], log, forecastEndpoint(transactions, series, balances, rates, currency))
+}
+
+func forecastEndpoint(transactions *storage.SQLXTransactionStore, series *storage.SQLXRecurringStore, balances *storage.SQLXBalanceStore, rates *storage.SQLXFXStore, currency string) func(context.Context, api.ForecastRequest) (int, api.Forecast, error) {
+	return func(ctx context.Context, req api.ForecastRequest) (status int, res api.Forecast, err error) {
+		fr := forecast.Request{
+			Account:        req.Account,
+			Days:           req.Days,
+			BalanceCents:   req.BalanceCents,
+			ThresholdCents: req.ThresholdCents,
+		}
+		for _, e := range req.WhatIf {
+			// the dates were checked by Valid
+			date, _ := time.Parse(time.DateOnly, e.Date)
+			fr.WhatIf = append(fr.WhatIf, forecast.Entry{Date: date, Description: e.Description, AmountCents: e.AmountCents})
+		}
+		current := balance.NewReconstructHandler(transactions, balances, balances)
+		handler := forecast.NewProjectHandler(current, series, transactions, balances, rates, currency, forecast.DefaultHistoryDays)
+		f, err := handler.Handle(ctx, fr, time.Now().UTC())
+		if err != nil {
+			return forecastErrorStatus(err), res, err
+		}
+		return http.StatusOK, toForecast(f), nil
+	}
+}
+
+func toForecast(f *forecast.Forecast) api.Forecast {
+	res := api.Forecast{
+		Account:            f.Account,
+		Currency:           f.Currency,
+		StartBalanceCents:  f.StartBalanceCents,
+		EndBalanceCents:    f.EndBalanceCents(),
+		ThresholdCents:     f.ThresholdCents,
+		LowestBalanceCents: f.Lowest.BalanceCents,
+		LowestBalanceDate:  f.Lowest.Date.Format(time.DateOnly),
+		Discretionary:      make([]api.ForecastCategory, 0, len(f.Discretionary)),
+		Days:               make([]api.ForecastDay, 0, len(f.Days)),
+	}
+	if f.LowBalanceDate != nil {
+		date := f.LowBalanceDate.Format(time.DateOnly)
+		res.LowBalanceDate = &date
+	}
+	for _, c := range f.Discretionary {
+		res.Discretionary = append(res.Discretionary, api.ForecastCategory{Category: c.Category, MonthlyCents: c.MonthlyCents})
+	}
+	for _, d := range f.Days {
+		day := api.ForecastDay{
+			Date:               d.Date.Format(time.DateOnly),
+			IncomeCents:        d.IncomeCents,
+			ExpenseCents:       d.ExpenseCents,
+			DiscretionaryCents: d.DiscretionaryCents,
+			BalanceCents:       d.BalanceCents,
+			Entries:            make([]api.ForecastEntry, 0, len(d.Entries)),
+		}
+		for _, e := range d.Entries {
+			day.Entries = append(day.Entries, api.ForecastEntry{
+				Description: e.Description,
+				AmountCents: e.AmountCents,
+				Source:      string(e.Source),
+				SeriesID:    e.SeriesID,
+			})
+		}
+		res.Days = append(res.Days, day)
+	}
+	return res
+}
+
+func forecastErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, forecast.ErrInvalidDays),
+		errors.Is(err, forecast.ErrInvalidEntry):
+		return http.StatusBadRequest
+	case errors.Is(err, fx.ErrNoRate):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
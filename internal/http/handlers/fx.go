@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/api"
+	"github.com/lennardclaproth/my-finances-tracker/internal/fx"
+	httpx "github.com/lennardclaproth/my-finances-tracker/internal/http"
+	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
+)
+
+// ImportFXRates imports the ECB euro foreign exchange reference rates.
+//
+// @Summary     Import exchange rates
+// @Description Upload an ECB euro foreign exchange reference rate file: eurofxref.csv, eurofxref-hist.csv, eurofxref-daily.xml, eurofxref-hist.xml, eurofxref-hist-90d.xml or the ZIP archive holding one of them. The format is detected from the content, days that were imported before are overwritten.
+// @Accept      multipart/form-data
+// @Produce     application/json
+// @Param       file formData file true "ECB reference rate file"
+// @Success     200 {object} api.FXImport "Import result"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /fx/rates/import [post]
+// @Tags        Exchange rates
+func ImportFXRates(log logging.Logger, store *storage.SQLXFXStore) http.Handler {
+	endpoint := func(ctx context.Context, req api.ImportFXRatesRequest) (status int, res api.FXImport, err error) {
+		defer req.File.Close()
+		result, err := fx.NewImportHandler(store).Handle(ctx, req.File)
+		if err != nil {
+			return fxErrorStatus(err), res, err
+		}
+		res = api.FXImport{Imported: result.Rates, Skipped: result.Skipped, Currencies: result.Currencies}
+		if result.Rates > 0 {
+			res.From = result.From.Format(time.DateOnly)
+			res.To = result.To.Format(time.DateOnly)
+		}
+		return http.StatusOK, res, nil
+	}
+	decodeFn := httpx.DecoderFunc[api.ImportFXRatesRequest](func(r *http.Request) (api.ImportFXRatesRequest, error) {
+		return httpx.DecodeMultipartFile[api.ImportFXRatesRequest](r, httpx.MultipartFileDecoderOptions{
+			FieldName: "file",
+			MaxBytes:  20 * 1024 * 1024, // 20 MB
+			MaxMemory: 40 * 1024 * 1024, // 40 MB
+		})
+	})
+	return httpx.Endpoint(decodeFn, log, endpoint)
+}
+
+// ListFXRates lists the stored exchange rates.
+//
+// @Summary     Exchange rates
+// @Description List the ECB reference rates against the euro, oldest first
+// @Accept      json
+// @Produce     application/json
+// @Param       currency query    string false "ISO 4217 code, all currencies when empty"
+// @Param       from     query    string false "First date (YYYY-MM-DD), defaults to 30 days before to"
+// @Param       to       query    string false "Last date (YYYY-MM-DD), defaults to today"
+// @Success     200 {array}  api.FXRate "Rates"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /fx/rates [get]
+// @Tags        Exchange rates
+func ListFXRates(log logging.Logger, store *storage.SQLXFXStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.FXRatesRequest) (status int, res []api.FXRate, err error) {
+		currency := ""
+		if strings.TrimSpace(req.Currency) != "" {
+			if currency, err = fx.NormaliseCurrency(req.Currency); err != nil {
+				return fxErrorStatus(err), nil, err
+			}
+		}
+		to := req.To
+		if to.IsZero() {
+			to = time.Now().UTC()
+		}
+		from := req.From
+		if from.IsZero() {
+			from = to.AddDate(0, 0, -30)
+		}
+		rates, err := store.List(ctx, currency, from, to)
+		if err != nil {
+			return fxErrorStatus(err), nil, err
+		}
+		res = make([]api.FXRate, 0, len(rates))
+		for _, r := range rates {
+			res = append(res, api.FXRate{
+				Currency:   r.Currency,
+				Date:       r.Date.Format(time.DateOnly),
+				Rate:       r.String(),
+				RateMicros: r.RateMicros,
+			})
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.FXRatesRequest], log, endpoint)
+}
+
+func fxErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, fx.ErrUnknownFormat),
+		errors.Is(err, fx.ErrInvalidRow),
+		errors.Is(err, fx.ErrInvalidCurrency),
+		errors.Is(err, fx.ErrInvalidRate):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -1,22 +1,31 @@
 package handlers
 
 import (
+	"encoding/json"
 	"net/http"
+
+	"github.com/lennardclaproth/my-finances-tracker/api"
+	"github.com/lennardclaproth/my-finances-tracker/internal/agent"
 )
 
 // HealthHandler returns a simple health check handler.
 //
 // @Summary     Health check
-// @Description Returns 200 when service is healthy
+// @Description Returns 200 when service is healthy, the status is degraded while the agent circuit breaker is open and tagging is paused
 // @Accept      json
 // @Produce     application/json
-// @Success     200 {object} map[string]string "status"
+// @Success     200 {object} api.Health "status"
 // @Router      /health [get]
 // @Tags        Health
-func HealthHandler() http.HandlerFunc {
+func HealthHandler(breaker *agent.Breaker) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		state := breaker.State()
+		res := api.Health{Status: "ok", Agent: string(state)}
+		if state == agent.BreakerOpen {
+			res.Status = "degraded"
+		}
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`{"status":"ok"}`))
+		_ = json.NewEncoder(w).Encode(res)
 	}
 }
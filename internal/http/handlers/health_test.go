@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/api"
+	"github.com/lennardclaproth/my-finances-tracker/internal/agent"
+)
+
+func TestHealthHandler(t *testing.T) {
+	b := agent.NewBreaker(1, 20*time.Millisecond)
+	health := func() api.Health {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		HealthHandler(b)(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", rec.Code)
+		}
+		var res api.Health
+		if err := json.NewDecoder(rec.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	if got := health(); got.Status != "ok" || got.Agent != "closed" {
+		t.Errorf("closed breaker = %+v", got)
+	}
+	b.Failure()
+	if got := health(); got.Status != "degraded" || got.Agent != "open" {
+		t.Errorf("open breaker = %+v", got)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if got := health(); got.Status != "ok" || got.Agent != "half-open" {
+		t.Errorf("half-open breaker = %+v", got)
+	}
+	b.Success()
+	if got := health(); got.Status != "ok" || got.Agent != "closed" {
+		t.Errorf("closed breaker = %+v", got)
+	}
+}
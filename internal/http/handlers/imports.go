@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"net/http"
 
 	"github.com/google/uuid"
@@ -20,11 +21,11 @@ import (
 // @Accept multipart/form-data
 // @Produce json
 // @Param file formData file true "CSV file containing transaction data"
-// @Param vendor_id formData string true "UUID of the vendor to import transactions for"
+// @Param vendor_id formData string true "Vendor of the statement: ING, Revolut, Wise, ABN AMRO or bunq"
 // @Success 200 {object} uuid.UUID "Import ID of the created import job"
 // @Failure 400 {object} map[string]string "Invalid request (missing file, invalid vendor_id, etc.)"
 // @Failure 413 {object} map[string]string "File too large (max 20MB)"
-// @Failure 415 {object} map[string]string "Unsupported media type (only text/csv and application/vnd.ms-excel allowed)"
+// @Failure 415 {object} map[string]string "Unsupported media type (XLS and XLSX spreadsheets, such as the ABN AMRO XLS export, are rejected: upload the CSV or TXT export)"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /import/csv [post]
 func ImportCsv(
@@ -38,18 +39,23 @@ func ImportCsv(
 		defer req.File.Close()
 		handler := importer.NewFromCsvHandler(ic, dw, dw, vf)
 		res, err = handler.Handle(ctx, req.File, req.VendorID)
+		if errors.Is(err, importer.ErrSpreadsheet) {
+			return http.StatusUnsupportedMediaType, uuid.Nil, err
+		}
 		if err != nil {
 			return http.StatusInternalServerError, uuid.Nil, err
 		}
 		return http.StatusOK, res, nil
 	}
 	// Setup the decoder function.
+	// Browsers on Windows send CSV files as application/vnd.ms-excel, so
+	// spreadsheets are recognised by their content instead.
 	decodeFn := httpx.DecoderFunc[api.ImportCsv](func(r *http.Request) (api.ImportCsv, error) {
 		return httpx.DecodeMultipartFile[api.ImportCsv](r, httpx.MultipartFileDecoderOptions{
 			FieldName:    "file",
 			MaxBytes:     20 * 1024 * 1024, // 20 MB
 			MaxMemory:    40 * 1024 * 1024, // 40 MB
-			AllowedTypes: []string{"text/csv", "application/vnd.ms-excel"},
+			AllowedTypes: []string{"text/csv", "application/vnd.ms-excel", "text/plain", "text/tab-separated-values"},
 		})
 	})
 	// Return the constructed endpoint handler.
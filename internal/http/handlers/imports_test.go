@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/importer"
+	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
+	"github.com/lennardclaproth/my-finances-tracker/internal/vendor"
+)
+
+type fakeImports struct {
+	created []*importer.Import
+}
+
+func (f *fakeImports) Create(ctx context.Context, imp *importer.Import) error {
+	f.created = append(f.created, imp)
+	return nil
+}
+
+type fakeVendors struct{}
+
+func (fakeVendors) FetchByName(ctx context.Context, name vendor.VendorID) (*vendor.Vendor, error) {
+	return &vendor.Vendor{ID: uuid.New(), Name: name}, nil
+}
+
+func TestImportCsv(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		content     []byte
+		want        int
+	}{
+		{"csv", "text/csv", []byte("Date;Name / Description;Amount (EUR)\n"), http.StatusOK},
+		// browsers on Windows send CSV files as Excel files
+		{"csv as excel", "application/vnd.ms-excel", []byte("Date;Name / Description;Amount (EUR)\n"), http.StatusOK},
+		{"txt", "text/plain", []byte("417164300\tEUR\t20240105\t20240105\t1.000,00\t900,00\t-100,00\tBEA\n"), http.StatusOK},
+		{"xls", "application/vnd.ms-excel", []byte("\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1\x00\x00"), http.StatusUnsupportedMediaType},
+		{"xlsx", "application/vnd.ms-excel", []byte("PK\x03\x04\x14\x00\x06\x00"), http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body bytes.Buffer
+			mw := multipart.NewWriter(&body)
+			mw.WriteField("vendor_id", "ABN AMRO")
+			h := textproto.MIMEHeader{}
+			h.Set("Content-Disposition", `form-data; name="file"; filename="statement"`)
+			h.Set("Content-Type", tt.contentType)
+			part, err := mw.CreatePart(h)
+			if err != nil {
+				t.Fatal(err)
+			}
+			part.Write(tt.content)
+			mw.Close()
+
+			dir := t.TempDir()
+			imports := &fakeImports{}
+			req := httptest.NewRequest(http.MethodPost, "/import/csv", &body)
+			req.Header.Set("Content-Type", mw.FormDataContentType())
+			rec := httptest.NewRecorder()
+			ImportCsv(logging.NewSlogLogger(slog.LevelError), imports, storage.NewDisk(dir), fakeVendors{}).ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+			files, _ := os.ReadDir(dir)
+			if tt.want != http.StatusOK && (len(imports.created) > 0 || len(files) > 0) {
+				t.Errorf("rejected upload created %d imports and %d files", len(imports.created), len(files))
+			}
+			if tt.want == http.StatusOK {
+				if len(imports.created) != 1 {
+					t.Fatalf("created %d imports, want 1", len(imports.created))
+				}
+				// the bytes looked at to recognise spreadsheets are stored too
+				if got, err := os.ReadFile(imports.created[0].Path); err != nil || !bytes.Equal(got, tt.content) {
+					t.Errorf("stored %q (%v), want %q", got, err, tt.content)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/api"
+	httpx "github.com/lennardclaproth/my-finances-tracker/internal/http"
+	"github.com/lennardclaproth/my-finances-tracker/internal/investment"
+	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+	"github.com/lennardclaproth/my-finances-tracker/internal/marketdata"
+	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
+)
+
+// ImportInvestments imports a broker statement.
+//
+// @Summary     Import a broker statement
+// @Description Upload a DEGIRO account statement (Account.csv, in English, Dutch or German). Buys, sells, dividends, dividend tax, fees and stock splits are imported, other lines such as deposits and currency exchanges are skipped. Lines imported before are skipped too, so overlapping statements can be uploaded.
+// @Accept      multipart/form-data
+// @Produce     application/json
+// @Param       file    formData file   true "DEGIRO account statement"
+// @Param       account formData string true "Brokerage account the statement belongs to"
+// @Success     200 {object} api.InvestmentImport "Import result"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /investments/import [post]
+// @Tags        Investments
+func ImportInvestments(log logging.Logger, store *storage.SQLXInvestmentStore) http.Handler {
+	endpoint := func(ctx context.Context, req api.ImportInvestmentsRequest) (status int, res api.InvestmentImport, err error) {
+		defer req.File.Close()
+		result, err := investment.NewImportHandler(store, store).Handle(ctx, req.File, req.Account)
+		if err != nil {
+			return investmentErrorStatus(err), res, err
+		}
+		res = api.InvestmentImport{
+			Imported:   result.Imported,
+			Duplicates: result.Duplicates,
+			Skipped:    result.Skipped,
+			Securities: make([]api.Security, 0, len(result.Securities)),
+		}
+		for _, s := range result.Securities {
+			res.Securities = append(res.Securities, toSecurity(s))
+		}
+		return http.StatusOK, res, nil
+	}
+	decodeFn := httpx.DecoderFunc[api.ImportInvestmentsRequest](func(r *http.Request) (api.ImportInvestmentsRequest, error) {
+		return httpx.DecodeMultipartFile[api.ImportInvestmentsRequest](r, httpx.MultipartFileDecoderOptions{
+			FieldName: "file",
+			MaxBytes:  20 * 1024 * 1024, // 20 MB
+			MaxMemory: 40 * 1024 * 1024, // 40 MB
+		})
+	})
+	return httpx.Endpoint(decodeFn, log, endpoint)
+}
+
+// ListSecurities lists the securities that were traded.
+//
+// @Summary     List securities
+// @Description List the securities seen in broker statements
+// @Accept      json
+// @Produce     application/json
+// @Success     200 {array}  api.Security "Securities"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /investments/securities [get]
+// @Tags        Investments
+func ListSecurities(log logging.Logger, store *storage.SQLXInvestmentStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req struct{}) (status int, res []api.Security, err error) {
+		securities, err := store.ListSecurities(ctx)
+		if err != nil {
+			return http.StatusInternalServerError, nil, err
+		}
+		res = make([]api.Security, 0, len(securities))
+		for _, s := range securities {
+			res = append(res, toSecurity(s))
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[struct{}], log, endpoint)
+}
+
+// UpdateSecurity changes the symbol or name of a security.
+//
+// @Summary     Update a security
+// @Description Set the symbol the market prices of a security are stored under, or rename it. Securities without a symbol are valued at their last trade.
+// @Accept      json
+// @Produce     application/json
+// @Param       id      path     string                    true "Security ID"
+// @Param       request body     api.UpdateSecurityRequest true "Fields to change"
+// @Success     200     {object} api.Security "Updated security"
+// @Failure     400     {object} map[string]string "Bad request"
+// @Failure     404     {object} map[string]string "Security not found"
+// @Failure     500     {object} map[string]string "Internal server error"
+// @Router      /investments/securities/{id} [patch]
+// @Tags        Investments
+func UpdateSecurity(log logging.Logger, store *storage.SQLXInvestmentStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.UpdateSecurityRequest) (status int, res api.Security, err error) {
+		s, err := store.FetchSecurity(ctx, req.ID)
+		if err != nil {
+			return investmentErrorStatus(err), res, err
+		}
+		if req.Symbol != nil {
+			s.Symbol = marketdata.NormaliseSymbol(*req.Symbol)
+		}
+		if req.Name != nil && strings.TrimSpace(*req.Name) != "" {
+			s.Name = strings.TrimSpace(*req.Name)
+		}
+		s.UpdatedAt = time.Now().UTC()
+		if err := store.SaveSecurity(ctx, s); err != nil {
+			return investmentErrorStatus(err), res, err
+		}
+		return http.StatusOK, toSecurity(s), nil
+	}
+	return httpx.Endpoint(httpx.JSONPathDecoder[api.UpdateSecurityRequest], log, endpoint)
+}
+
+// ListInvestmentTrades lists imported trades.
+//
+// @Summary     List trades
+// @Description List the buys, sells, dividends, fees and splits, oldest first
+// @Accept      json
+// @Produce     application/json
+// @Param       security query    string false "Security ID"
+// @Param       account  query    string false "Brokerage account"
+// @Param       from     query    string false "First date (YYYY-MM-DD)"
+// @Param       to       query    string false "Last date (YYYY-MM-DD)"
+// @Success     200 {array}  api.InvestmentTrade "Trades"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /investments/trades [get]
+// @Tags        Investments
+func ListInvestmentTrades(log logging.Logger, store *storage.SQLXInvestmentStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.InvestmentTradesRequest) (status int, res []api.InvestmentTrade, err error) {
+		f := investment.TradeFilter{Account: req.Account, From: req.From}
+		if req.Security != uuid.Nil {
+			f.SecurityID = &req.Security
+		}
+		if !req.To.IsZero() {
+			// to includes the whole day
+			f.To = req.To.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		trades, err := store.ListTrades(ctx, f)
+		if err != nil {
+			return http.StatusInternalServerError, nil, err
+		}
+		res = make([]api.InvestmentTrade, 0, len(trades))
+		for _, t := range trades {
+			res = append(res, api.InvestmentTrade{
+				ID:             t.ID,
+				Account:        t.Account,
+				SecurityID:     t.SecurityID,
+				Date:           t.Date,
+				Type:           string(t.Type),
+				QuantityMicros: t.QuantityMicros,
+				Price:          t.Price.String(),
+				AmountCents:    t.AmountCents,
+				FeeCents:       t.FeeCents,
+				Currency:       t.Currency,
+				RatioFrom:      t.RatioFrom,
+				RatioTo:        t.RatioTo,
+				Description:    t.Description,
+			})
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.InvestmentTradesRequest], log, endpoint)
+}
+
+// Holdings reports the holdings with their cost basis and gains.
+//
+// @Summary     Holdings
+// @Description Report the holdings at the end of a day with their value, cost basis and realised and unrealised gains. Holdings are valued at the latest market price of their symbol, or at their last trade when there is none. Amounts are in the currency of the security.
+// @Accept      json
+// @Produce     application/json
+// @Param       date   query    string false "Day (YYYY-MM-DD), defaults to today"
+// @Param       method query    string false "Cost basis method (fifo, average), defaults to fifo"
+// @Success     200 {object} api.Holdings "Holdings"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /investments/holdings [get]
+// @Tags        Investments
+func Holdings(log logging.Logger, store *storage.SQLXInvestmentStore, prices *storage.SQLXMarketHistoryStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.HoldingsRequest) (status int, res api.Holdings, err error) {
+		method, err := investment.ParseMethod(req.Method)
+		if err != nil {
+			return investmentErrorStatus(err), res, err
+		}
+		date := req.Date
+		if date.IsZero() {
+			date = time.Now().UTC()
+		}
+		holdings, err := investment.NewPortfolioHandler(store, store, prices).Holdings(ctx, date, method)
+		if err != nil {
+			return investmentErrorStatus(err), res, err
+		}
+		res = api.Holdings{
+			Date:     date.Format(time.DateOnly),
+			Method:   string(method),
+			Holdings: make([]api.Holding, 0, len(holdings)),
+		}
+		for _, h := range holdings {
+			res.Holdings = append(res.Holdings, toHolding(h))
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.HoldingsRequest], log, endpoint)
+}
+
+// InvestmentPerformance reports the returns of the holdings.
+//
+// @Summary     Investment performance
+// @Description Report the gains, dividends, fees and the time-weighted and money-weighted returns over a period, per currency. The time-weighted return chains the returns between days with trades, the money-weighted return is the yearly internal rate of return including the value at the start and the end of the period.
+// @Accept      json
+// @Produce     application/json
+// @Param       from   query    string false "First date (YYYY-MM-DD), defaults to a year before to"
+// @Param       to     query    string false "Last date (YYYY-MM-DD), defaults to today"
+// @Param       method query    string false "Cost basis method (fifo, average), defaults to fifo"
+// @Success     200 {array}  api.InvestmentPerformance "Performance per currency"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /investments/performance [get]
+// @Tags        Investments
+func InvestmentPerformance(log logging.Logger, store *storage.SQLXInvestmentStore, prices *storage.SQLXMarketHistoryStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.InvestmentPerformanceRequest) (status int, res []api.InvestmentPerformance, err error) {
+		method, err := investment.ParseMethod(req.Method)
+		if err != nil {
+			return investmentErrorStatus(err), nil, err
+		}
+		to := req.To
+		if to.IsZero() {
+			to = time.Now().UTC()
+		}
+		from := req.From
+		if from.IsZero() {
+			from = to.AddDate(-1, 0, 0)
+		}
+		performance, err := investment.NewPortfolioHandler(store, store, prices).Performance(ctx, from, to, method)
+		if err != nil {
+			return investmentErrorStatus(err), nil, err
+		}
+		res = make([]api.InvestmentPerformance, 0, len(performance))
+		for _, p := range performance {
+			res = append(res, api.InvestmentPerformance{
+				Currency:           p.Currency,
+				From:               p.From.Format(time.DateOnly),
+				To:                 p.To.Format(time.DateOnly),
+				StartValueCents:    p.StartValueCents,
+				EndValueCents:      p.EndValueCents,
+				NetInvestedCents:   p.NetInvestedCents,
+				RealisedCents:      p.RealisedCents,
+				UnrealisedCents:    p.UnrealisedCents,
+				DividendCents:      p.DividendCents,
+				FeeCents:           p.FeeCents,
+				TimeWeighted:       p.TimeWeighted,
+				TimeWeightedAnnual: p.TimeWeightedAnnual,
+				MoneyWeighted:      p.MoneyWeighted,
+			})
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.InvestmentPerformanceRequest], log, endpoint)
+}
+
+func toSecurity(s *investment.Security) api.Security {
+	return api.Security{
+		ID:       s.ID,
+		ISIN:     s.ISIN,
+		Symbol:   s.Symbol,
+		Name:     s.Name,
+		Currency: s.Currency,
+	}
+}
+
+func toHolding(h investment.Holding) api.Holding {
+	res := api.Holding{
+		Security:        toSecurity(h.Security),
+		QuantityMicros:  h.Position.QuantityMicros(),
+		Price:           h.Price.String(),
+		MarketPrice:     h.MarketPrice,
+		ValueCents:      h.ValueCents,
+		CostCents:       h.Position.CostCents(),
+		UnrealisedCents: h.UnrealisedCents(),
+		RealisedCents:   h.Position.RealisedCents,
+		DividendCents:   h.Position.DividendCents,
+		FeeCents:        h.Position.FeeCents,
+		Incomplete:      h.Position.Incomplete,
+		Lots:            make([]api.Lot, 0, len(h.Position.Lots)),
+	}
+	if !h.PriceDate.IsZero() {
+		res.PriceDate = h.PriceDate.Format(time.DateOnly)
+	}
+	for _, l := range h.Position.Lots {
+		res.Lots = append(res.Lots, api.Lot{
+			Date:           l.Date.Format(time.DateOnly),
+			QuantityMicros: l.QuantityMicros,
+			CostCents:      l.CostCents,
+		})
+	}
+	return res
+}
+
+func investmentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, investment.ErrSecurityNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, investment.ErrInvalidStatement),
+		errors.Is(err, investment.ErrInvalidLine),
+		errors.Is(err, investment.ErrInvalidAccount),
+		errors.Is(err, investment.ErrInvalidMethod),
+		errors.Is(err, investment.ErrInvalidRange):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
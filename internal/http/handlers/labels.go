@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/api"
+	httpx "github.com/lennardclaproth/my-finances-tracker/internal/http"
+	"github.com/lennardclaproth/my-finances-tracker/internal/label"
+	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
+)
+
+// ListLabels lists the labels in use.
+//
+// @Summary     List labels
+// @Description List all labels with the number of transactions carrying them, most used first
+// @Accept      json
+// @Produce     application/json
+// @Success     200 {array}  api.Label "Labels"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /labels [get]
+// @Tags        Labels
+func ListLabels(log logging.Logger, store *storage.SQLXLabelStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req struct{}) (status int, res []api.Label, err error) {
+		labels, err := store.List(ctx)
+		if err != nil {
+			return http.StatusInternalServerError, nil, err
+		}
+		res = make([]api.Label, 0, len(labels))
+		for _, l := range labels {
+			res = append(res, api.Label{ID: l.ID, Name: l.Name, Count: l.Count})
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[struct{}], log, endpoint)
+}
+
+// LabelTransaction adds labels to a transaction.
+//
+// @Summary     Label a transaction
+// @Description Add labels to a transaction, labels that do not exist yet are created
+// @Accept      application/json
+// @Produce     application/json
+// @Param       id      path     string                      true "Transaction ID"
+// @Param       payload body     api.LabelTransactionRequest true "Labels to add"
+// @Success     200 {object} map[string]string "OK"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     404 {object} map[string]string "Transaction not found"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /transactions/{id}/labels [post]
+// @Tags        Labels
+func LabelTransaction(log logging.Logger, store *storage.SQLXLabelStore, txs *storage.SQLXTransactionStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.LabelTransactionRequest) (status int, res struct{}, err error) {
+		handler := label.NewAssignHandler(store, txs, store, store, store)
+		if err := handler.Handle(ctx, []uuid.UUID{req.ID}, req.Labels, nil); err != nil {
+			return labelErrorStatus(err), res, err
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.JSONPathDecoder[api.LabelTransactionRequest], log, endpoint)
+}
+
+// UnlabelTransaction removes a label from a transaction.
+//
+// @Summary     Remove a label from a transaction
+// @Description Remove a label from a transaction, removing a label the transaction does not carry is not an error
+// @Accept      json
+// @Produce     application/json
+// @Param       id    path     string true "Transaction ID"
+// @Param       label path     string true "Label"
+// @Success     200 {object} map[string]string "OK"
+// @Failure     404 {object} map[string]string "Transaction not found"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /transactions/{id}/labels/{label} [delete]
+// @Tags        Labels
+func UnlabelTransaction(log logging.Logger, store *storage.SQLXLabelStore, txs *storage.SQLXTransactionStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.UnlabelTransactionRequest) (status int, res struct{}, err error) {
+		handler := label.NewAssignHandler(store, txs, store, store, store)
+		if err := handler.Handle(ctx, []uuid.UUID{req.ID}, nil, []string{req.Label}); err != nil {
+			return labelErrorStatus(err), res, err
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.UnlabelTransactionRequest], log, endpoint)
+}
+
+// BulkLabelTransactions adds and removes labels on many transactions.
+//
+// @Summary     Label transactions in bulk
+// @Description Add and remove labels on multiple transactions at once
+// @Accept      application/json
+// @Produce     application/json
+// @Param       payload body     api.BulkLabelRequest true "Transactions and labels"
+// @Success     200 {object} map[string]string "OK"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     404 {object} map[string]string "Transaction not found"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /transactions/labels [post]
+// @Tags        Labels
+func BulkLabelTransactions(log logging.Logger, store *storage.SQLXLabelStore, txs *storage.SQLXTransactionStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.BulkLabelRequest) (status int, res struct{}, err error) {
+		handler := label.NewAssignHandler(store, txs, store, store, store)
+		if err := handler.Handle(ctx, req.TransactionIDs, req.Add, req.Remove); err != nil {
+			return labelErrorStatus(err), res, err
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.JSONDecoder[api.BulkLabelRequest], log, endpoint)
+}
+
+// LabelTotals reports the cash flow per label.
+//
+// @Summary     Label totals
+// @Description Sum incoming and outgoing amounts of the transactions carrying each label. Amounts are converted to the reporting currency with the rate of the transaction date. Ignored transactions are left out, refunds count towards the labels of the original purchase.
+// @Accept      json
+// @Produce     application/json
+// @Param       from query    string false "First date (YYYY-MM-DD)"
+// @Param       to   query    string false "Last date (YYYY-MM-DD)"
+// @Success     200 {array}  api.LabelTotals "Totals per label"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /labels/totals [get]
+// @Tags        Labels
+func LabelTotals(log logging.Logger, store *storage.SQLXLabelStore, currency string) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.LabelTotalsRequest) (status int, res []api.LabelTotals, err error) {
+		totals, err := store.Totals(ctx, req.From, req.To, currency)
+		if err != nil {
+			return http.StatusInternalServerError, nil, err
+		}
+		res = make([]api.LabelTotals, 0, len(totals))
+		for _, t := range totals {
+			res = append(res, api.LabelTotals{Name: t.Name, Count: t.Count, InCents: t.InCents, OutCents: t.OutCents})
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.LabelTotalsRequest], log, endpoint)
+}
+
+func labelErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, label.ErrTransactionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, label.ErrInvalidName):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/api"
+	httpx "github.com/lennardclaproth/my-finances-tracker/internal/http"
+	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+	"github.com/lennardclaproth/my-finances-tracker/internal/marketdata"
+	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
+)
+
+// ImportMarketData imports daily prices from an OHLCV CSV file.
+//
+// @Summary     Import market prices
+// @Description Upload a Yahoo Finance (Date,Open,High,Low,Close,Adj Close,Volume) or Stooq (Date,Open,High,Low,Close,Volume or <TICKER>,<PER>,<DATE>,...) CSV file. The format is detected from the header, days that were imported before are overwritten.
+// @Accept      multipart/form-data
+// @Produce     application/json
+// @Param       file   formData file   true  "CSV file with daily prices"
+// @Param       symbol formData string false "Symbol the prices belong to, required unless the file names its ticker"
+// @Success     200 {object} api.MarketDataImport "Import result"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /marketdata/import [post]
+// @Tags        Market data
+func ImportMarketData(log logging.Logger, store *storage.SQLXMarketHistoryStore) http.Handler {
+	endpoint := func(ctx context.Context, req api.ImportMarketDataRequest) (status int, res api.MarketDataImport, err error) {
+		defer req.File.Close()
+		result, err := marketdata.NewImportHandler(store).Handle(ctx, req.File, req.Symbol)
+		if err != nil {
+			return marketDataErrorStatus(err), res, err
+		}
+		res = api.MarketDataImport{
+			Format:   string(result.Format),
+			Imported: len(result.History),
+			Skipped:  result.Skipped,
+		}
+		for _, h := range result.History {
+			if d := h.Date.Format(time.DateOnly); res.From == "" || d < res.From {
+				res.From = d
+			}
+			if d := h.Date.Format(time.DateOnly); d > res.To {
+				res.To = d
+			}
+		}
+		return http.StatusOK, res, nil
+	}
+	decodeFn := httpx.DecoderFunc[api.ImportMarketDataRequest](func(r *http.Request) (api.ImportMarketDataRequest, error) {
+		return httpx.DecodeMultipartFile[api.ImportMarketDataRequest](r, httpx.MultipartFileDecoderOptions{
+			FieldName: "file",
+			MaxBytes:  20 * 1024 * 1024, // 20 MB
+			MaxMemory: 40 * 1024 * 1024, // 40 MB
+		})
+	})
+	return httpx.Endpoint(decodeFn, log, endpoint)
+}
+
+// MarketHistory lists the daily prices of a symbol.
+//
+// @Summary     Market prices
+// @Description List the daily prices of a symbol, oldest first
+// @Accept      json
+// @Produce     application/json
+// @Param       symbol path     string true  "Symbol, e.g. VWRL.AS"
+// @Param       from   query    string false "First date (YYYY-MM-DD), defaults to a year before to"
+// @Param       to     query    string false "Last date (YYYY-MM-DD), defaults to today"
+// @Success     200 {array}  api.MarketPrice "Prices"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /marketdata/{symbol} [get]
+// @Tags        Market data
+func MarketHistory(log logging.Logger, store *storage.SQLXMarketHistoryStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.MarketHistoryRequest) (status int, res []api.MarketPrice, err error) {
+		to := req.To
+		if to.IsZero() {
+			to = time.Now().UTC()
+		}
+		from := req.From
+		if from.IsZero() {
+			from = to.AddDate(-1, 0, 0)
+		}
+		history, err := store.Range(ctx, req.Symbol, from, to)
+		if err != nil {
+			return marketDataErrorStatus(err), nil, err
+		}
+		res = make([]api.MarketPrice, 0, len(history))
+		for _, h := range history {
+			res = append(res, toMarketPrice(h))
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.MarketHistoryRequest], log, endpoint)
+}
+
+// LatestMarketPrice returns the most recent price of a symbol.
+//
+// @Summary     Latest market price
+// @Description Return the most recent daily price of a symbol
+// @Accept      json
+// @Produce     application/json
+// @Param       symbol path     string true "Symbol, e.g. VWRL.AS"
+// @Success     200 {object} api.MarketPrice "Price"
+// @Failure     404 {object} map[string]string "No prices for the symbol"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /marketdata/{symbol}/latest [get]
+// @Tags        Market data
+func LatestMarketPrice(log logging.Logger, store *storage.SQLXMarketHistoryStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.MarketSymbolRequest) (status int, res api.MarketPrice, err error) {
+		h, err := store.Latest(ctx, req.Symbol)
+		if err != nil {
+			return marketDataErrorStatus(err), res, err
+		}
+		return http.StatusOK, toMarketPrice(h), nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.MarketSymbolRequest], log, endpoint)
+}
+
+func toMarketPrice(h *marketdata.History) api.MarketPrice {
+	return api.MarketPrice{
+		Symbol:      h.Symbol,
+		Date:        h.Date.Format(time.DateOnly),
+		Open:        h.Open.String(),
+		High:        h.High.String(),
+		Low:         h.Low.String(),
+		Close:       h.Close.String(),
+		CloseMicros: int64(h.Close),
+		Volume:      h.Volume,
+	}
+}
+
+func marketDataErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, marketdata.ErrNoHistory):
+		return http.StatusNotFound
+	case errors.Is(err, marketdata.ErrInvalidSymbol),
+		errors.Is(err, marketdata.ErrUnknownFormat),
+		errors.Is(err, marketdata.ErrEmptyFile),
+		errors.Is(err, marketdata.ErrInvalidRow):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/api"
+	"github.com/lennardclaproth/my-finances-tracker/internal/balance"
+	"github.com/lennardclaproth/my-finances-tracker/internal/fx"
+	httpx "github.com/lennardclaproth/my-finances-tracker/internal/http"
+	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+	"github.com/lennardclaproth/my-finances-tracker/internal/networth"
+	"github.com/lennardclaproth/my-finances-tracker/internal/report"
+	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
+)
+
+// NetWorth reports the net worth over time.
+//
+// @Summary     Net worth
+// @Description Report assets, liabilities and net worth at the end of every interval, split per asset class, in the reporting currency. Accounts are valued at their reconstructed balance converted with the ECB rate of the day, manual items at their latest valuation, loans at their outstanding principal. Month ends with a snapshot are read from it so later corrections do not change the history, backfilled snapshots are provisional and recomputed until they are confirmed.
+// @Accept      json
+// @Produce     application/json
+// @Param       from     query    string false "First date (YYYY-MM-DD), defaults to a year before to"
+// @Param       to       query    string false "Last date (YYYY-MM-DD), defaults to today"
+// @Param       interval query    string false "Interval (week, month, quarter, year), defaults to month"
+// @Success     200 {object} api.NetWorth "Net worth per interval"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     409 {object} map[string]string "No exchange rate for the currency of an account"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /networth [get]
+// @Tags        Net worth
+func NetWorth(log logging.Logger, transactions *storage.SQLXTransactionStore, balances *storage.SQLXBalanceStore, store *storage.SQLXNetWorthStore, prices *storage.SQLXMarketHistoryStore, rates *storage.SQLXFXStore, currency string) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.NetWorthRequest) (status int, res api.NetWorth, err error) {
+		interval, err := report.ParseInterval(req.Interval)
+		if err != nil {
+			return netWorthErrorStatus(err), res, err
+		}
+		to := req.To
+		if to.IsZero() {
+			to = time.Now().UTC()
+		}
+		from := req.From
+		if from.IsZero() {
+			from = to.AddDate(-1, 0, 0)
+		}
+		valuer := networth.NewValueHandler(balances, balance.NewReconstructHandler(transactions, balances, balances), store, store, prices, rates, currency)
+		points, err := networth.NewSeriesHandler(store, valuer).Handle(ctx, from, to, interval)
+		if err != nil {
+			return netWorthErrorStatus(err), res, err
+		}
+		res = api.NetWorth{
+			Interval:  string(interval),
+			Currency:  currency,
+			Points:    make([]api.NetWorthPoint, 0, len(points)),
+			Breakdown: []api.NetWorthComponent{},
+		}
+		for _, p := range points {
+			point := api.NetWorthPoint{
+				Date:             p.Date.Format(time.DateOnly),
+				AssetsCents:      p.AssetsCents(),
+				LiabilitiesCents: p.LiabilitiesCents(),
+				NetWorthCents:    p.NetWorthCents(),
+				ByClass:          map[string]int64{},
+				Snapshot:         p.Snapshot,
+				Provisional:      p.Provisional,
+			}
+			for class, cents := range p.ByClass() {
+				point.ByClass[string(class)] = cents
+			}
+			res.Points = append(res.Points, point)
+		}
+		if len(points) > 0 {
+			for _, c := range points[len(points)-1].Components {
+				res.Breakdown = append(res.Breakdown, api.NetWorthComponent{
+					Source:        c.Source,
+					Name:          c.Name,
+					Class:         string(c.Class),
+					Liability:     c.Class.Liability(),
+					ValueCents:    c.ValueCents,
+					Currency:      c.Currency,
+					OriginalCents: c.OriginalCents,
+				})
+			}
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.NetWorthRequest], log, endpoint)
+}
+
+// ConfirmNetWorthSnapshots makes backfilled snapshots final.
+//
+// @Summary     Confirm net worth snapshots
+// @Description Make the provisional snapshots through a day final once the statements of their months have been imported, they are no longer recomputed afterwards
+// @Accept      json
+// @Produce     application/json
+// @Param       through query    string false "Last month end to confirm (YYYY-MM-DD), defaults to today"
+// @Success     200 {object} api.ConfirmedSnapshots "Number of month ends confirmed"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /networth/snapshots/confirm [post]
+// @Tags        Net worth
+func ConfirmNetWorthSnapshots(log logging.Logger, store *storage.SQLXNetWorthStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.ConfirmSnapshotsRequest) (status int, res api.ConfirmedSnapshots, err error) {
+		through := req.Through
+		if through.IsZero() {
+			through = time.Now().UTC()
+		}
+		n, err := networth.NewConfirmHandler(store).Handle(ctx, through)
+		if err != nil {
+			return http.StatusInternalServerError, res, err
+		}
+		return http.StatusOK, api.ConfirmedSnapshots{Confirmed: n}, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.ConfirmSnapshotsRequest], log, endpoint)
+}
+
+// ListNetWorthItems lists the manually entered assets and liabilities.
+//
+// @Summary     List net worth items
+// @Description List the assets and liabilities entered by hand, e.g. a house, a mortgage or a car loan
+// @Accept      json
+// @Produce     application/json
+// @Success     200 {array}  api.NetWorthItem "Items"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /networth/items [get]
+// @Tags        Net worth
+func ListNetWorthItems(log logging.Logger, store *storage.SQLXNetWorthStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req struct{}) (status int, res []api.NetWorthItem, err error) {
+		items, err := store.ListItems(ctx)
+		if err != nil {
+			return http.StatusInternalServerError, nil, err
+		}
+		res = make([]api.NetWorthItem, 0, len(items))
+		for _, i := range items {
+			res = append(res, toNetWorthItem(i))
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[struct{}], log, endpoint)
+}
+
+// CreateNetWorthItem creates a manually entered asset or liability.
+//
+// @Summary     Create a net worth item
+// @Description Create an asset or liability. Liabilities with a loan schedule are valued at their outstanding principal, investments with a symbol at the quantity times the market price, other items at their latest valuation.
+// @Accept      json
+// @Produce     application/json
+// @Param       request body     api.CreateNetWorthItemRequest true "Item"
+// @Success     201     {object} api.NetWorthItem "Created item"
+// @Failure     400     {object} map[string]string "Bad request"
+// @Failure     500     {object} map[string]string "Internal server error"
+// @Router      /networth/items [post]
+// @Tags        Net worth
+func CreateNetWorthItem(log logging.Logger, store *storage.SQLXNetWorthStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.CreateNetWorthItemRequest) (status int, res api.NetWorthItem, err error) {
+		var loan *networth.Loan
+		if req.Loan != nil {
+			// the start date was checked by Valid
+			start, _ := time.Parse(time.DateOnly, req.Loan.StartDate)
+			loan = &networth.Loan{
+				PrincipalCents:  req.Loan.PrincipalCents,
+				RateBasisPoints: req.Loan.RateBasisPoints,
+				StartDate:       start,
+				TermMonths:      req.Loan.TermMonths,
+				Method:          networth.LoanMethod(req.Loan.Method),
+			}
+		}
+		item, err := networth.NewItem(strings.TrimSpace(req.Name), networth.Class(req.Class), strings.TrimSpace(req.Symbol), req.QuantityMicros, loan)
+		if err != nil {
+			return netWorthErrorStatus(err), res, err
+		}
+		if err := store.CreateItem(ctx, item); err != nil {
+			return netWorthErrorStatus(err), res, err
+		}
+		return http.StatusCreated, toNetWorthItem(item), nil
+	}
+	return httpx.Endpoint(httpx.JSONDecoder[api.CreateNetWorthItemRequest], log, endpoint)
+}
+
+// DeleteNetWorthItem deletes a manually entered asset or liability.
+//
+// @Summary     Delete a net worth item
+// @Description Delete an item together with its valuations, snapshots taken earlier keep its value
+// @Accept      json
+// @Produce     application/json
+// @Param       id  path     string true "Item ID"
+// @Success     200 {object} map[string]string "OK"
+// @Failure     404 {object} map[string]string "Item not found"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /networth/items/{id} [delete]
+// @Tags        Net worth
+func DeleteNetWorthItem(log logging.Logger, store *storage.SQLXNetWorthStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.NetWorthItemRequest) (status int, res struct{}, err error) {
+		if err := store.DeleteItem(ctx, req.ID); err != nil {
+			return netWorthErrorStatus(err), res, err
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.NetWorthItemRequest], log, endpoint)
+}
+
+// SetNetWorthValuation sets the value of an item on a day.
+//
+// @Summary     Set a valuation
+// @Description Set the value of an item at the end of a day, e.g. the appraised value of a house. An existing valuation on the same day is replaced.
+// @Accept      json
+// @Produce     application/json
+// @Param       id      path     string                       true "Item ID"
+// @Param       date    path     string                       true "Day (YYYY-MM-DD)"
+// @Param       request body     api.NetWorthValuationRequest true "Value"
+// @Success     200     {object} map[string]string "OK"
+// @Failure     400     {object} map[string]string "Bad request"
+// @Failure     404     {object} map[string]string "Item not found"
+// @Failure     500     {object} map[string]string "Internal server error"
+// @Router      /networth/items/{id}/valuations/{date} [put]
+// @Tags        Net worth
+func SetNetWorthValuation(log logging.Logger, store *storage.SQLXNetWorthStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.NetWorthValuationRequest) (status int, res struct{}, err error) {
+		if _, err := store.FetchItem(ctx, req.ID); err != nil {
+			return netWorthErrorStatus(err), res, err
+		}
+		// the date was checked by Valid
+		date, _ := time.Parse(time.DateOnly, req.Date)
+		v := &networth.Valuation{ItemID: req.ID, Date: date, ValueCents: req.ValueCents}
+		if err := store.SaveValuation(ctx, v); err != nil {
+			return netWorthErrorStatus(err), res, err
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.JSONPathDecoder[api.NetWorthValuationRequest], log, endpoint)
+}
+
+func toNetWorthItem(i *networth.Item) api.NetWorthItem {
+	res := api.NetWorthItem{
+		ID:             i.ID,
+		Name:           i.Name,
+		Class:          string(i.Class),
+		Liability:      i.Class.Liability(),
+		Symbol:         i.Symbol,
+		QuantityMicros: i.QuantityMicros,
+	}
+	if i.Loan != nil {
+		res.Loan = &api.LoanSchedule{
+			PrincipalCents:  i.Loan.PrincipalCents,
+			RateBasisPoints: i.Loan.RateBasisPoints,
+			StartDate:       i.Loan.StartDate.Format(time.DateOnly),
+			TermMonths:      i.Loan.TermMonths,
+			Method:          string(i.Loan.Method),
+		}
+	}
+	return res
+}
+
+func netWorthErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, networth.ErrItemNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, networth.ErrInvalidClass),
+		errors.Is(err, networth.ErrInvalidName),
+		errors.Is(err, networth.ErrInvalidLoan),
+		errors.Is(err, networth.ErrLoanOnAsset),
+		errors.Is(err, networth.ErrHoldingClass),
+		errors.Is(err, report.ErrInvalidInterval):
+		return http.StatusBadRequest
+	case errors.Is(err, fx.ErrNoRate):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
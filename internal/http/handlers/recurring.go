@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/api"
+	"github.com/lennardclaproth/my-finances-tracker/internal/fx"
+	httpx "github.com/lennardclaproth/my-finances-tracker/internal/http"
+	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+	"github.com/lennardclaproth/my-finances-tracker/internal/recurring"
+	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// ListRecurring lists the detected recurring payments and income.
+//
+// @Summary     List recurring transactions
+// @Description List the subscriptions, bills and income detected per counterparty with their expected next date and amount. Series are flagged on price increases and missed payments.
+// @Accept      json
+// @Produce     application/json
+// @Param       status    query    string false "Series status (active, missed, ended)"
+// @Param       direction query    string false "Direction (in, out)"
+// @Success     200 {array}  api.RecurringSeries "Recurring series, next expected first"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /recurring [get]
+// @Tags        Recurring
+func ListRecurring(log logging.Logger, store *storage.SQLXRecurringStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.ListRecurringRequest) (status int, res []api.RecurringSeries, err error) {
+		series, err := store.List(ctx, recurring.Status(req.Status), transaction.CashFlowDirection(req.Direction))
+		if err != nil {
+			return http.StatusInternalServerError, nil, err
+		}
+		res = make([]api.RecurringSeries, 0, len(series))
+		for _, s := range series {
+			res = append(res, toRecurringSeries(s))
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.ListRecurringRequest], log, endpoint)
+}
+
+// RecurringCosts reports the yearly cost of the recurring payments.
+//
+// @Summary     Recurring costs
+// @Description Sum the recurring payments and income that have not ended to yearly amounts in the reporting currency, the most expensive series first. Series in other currencies are converted with the ECB rate of today.
+// @Accept      json
+// @Produce     application/json
+// @Success     200 {object} api.RecurringCosts "Yearly costs"
+// @Failure     409 {object} map[string]string "No exchange rate for the currency of a series"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /recurring/costs [get]
+// @Tags        Recurring
+func RecurringCosts(log logging.Logger, store *storage.SQLXRecurringStore, rates *storage.SQLXFXStore, currency string) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req struct{}) (status int, res api.RecurringCosts, err error) {
+		costs, err := recurring.NewCostsHandler(store, rates, currency).Handle(ctx, time.Now().UTC())
+		if err != nil {
+			return recurringErrorStatus(err), res, err
+		}
+		res = api.RecurringCosts{
+			Currency:            costs.Currency,
+			YearlyExpenseCents:  costs.YearlyExpenseCents,
+			MonthlyExpenseCents: costs.YearlyExpenseCents / 12,
+			YearlyIncomeCents:   costs.YearlyIncomeCents,
+			ExpensesByFrequency: map[string]int64{},
+			Expenses:            make([]api.RecurringSeries, 0, len(costs.Expenses)),
+		}
+		for f, cents := range costs.ExpensesByFrequency {
+			res.ExpensesByFrequency[string(f)] = cents
+		}
+		for _, s := range costs.Expenses {
+			series := toRecurringSeries(s)
+			yearly := costs.YearlyCents[s.ID]
+			series.ReportingYearlyCents = &yearly
+			res.Expenses = append(res.Expenses, series)
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[struct{}], log, endpoint)
+}
+
+func toRecurringSeries(s *recurring.Series) api.RecurringSeries {
+	return api.RecurringSeries{
+		ID:                  s.ID,
+		Name:                s.Name,
+		CounterpartyID:      s.CounterpartyID,
+		Account:             s.Account,
+		Currency:            s.Currency,
+		Direction:           string(s.Direction),
+		Frequency:           string(s.Frequency),
+		AmountCents:         s.AmountCents,
+		PreviousAmountCents: s.PreviousAmountCents,
+		PriceIncreased:      s.PriceIncreased(),
+		YearlyCents:         s.YearlyCents(),
+		FirstDate:           s.FirstDate.Format(time.DateOnly),
+		LastDate:            s.LastDate.Format(time.DateOnly),
+		NextDate:            s.NextDate.Format(time.DateOnly),
+		Occurrences:         s.Occurrences,
+		Status:              string(s.Status),
+	}
+}
+
+func recurringErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, fx.ErrNoRate):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
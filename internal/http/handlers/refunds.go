@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/api"
+	httpx "github.com/lennardclaproth/my-finances-tracker/internal/http"
+	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+	"github.com/lennardclaproth/my-finances-tracker/internal/refund"
+	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
+)
+
+// ListRefundLinks lists detected refund links.
+//
+// @Summary     List refund links
+// @Description List refund links between incoming refunds and the original outgoing transactions, optionally filtered by status
+// @Accept      json
+// @Produce     application/json
+// @Param       status query    string false "Link status (suggested, confirmed, rejected)"
+// @Success     200 {array}  api.RefundLink "Refund links"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /refunds [get]
+// @Tags        Refunds
+func ListRefundLinks(log logging.Logger, links *storage.SQLXRefundStore, txs *storage.SQLXTransactionStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.ListRefundLinksRequest) (status int, res []api.RefundLink, err error) {
+		found, err := links.List(ctx, refund.LinkStatus(req.Status))
+		if err != nil {
+			return http.StatusInternalServerError, nil, err
+		}
+		res, err = toRefundLinks(ctx, txs, found...)
+		if err != nil {
+			return http.StatusInternalServerError, nil, err
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.ListRefundLinksRequest], log, endpoint)
+}
+
+// ConfirmRefundLink confirms a suggested refund link.
+//
+// @Summary     Confirm a refund link
+// @Description Confirm a suggested link, the refund is then netted against the category of the original transaction
+// @Accept      json
+// @Produce     application/json
+// @Param       id  path     string true "Refund link ID"
+// @Success     200 {object} api.RefundLink "Confirmed link"
+// @Failure     404 {object} map[string]string "Link not found"
+// @Failure     409 {object} map[string]string "Link is not a suggestion"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /refunds/{id}/confirm [post]
+// @Tags        Refunds
+func ConfirmRefundLink(log logging.Logger, links *storage.SQLXRefundStore, txs *storage.SQLXTransactionStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.RefundLinkRequest) (status int, res api.RefundLink, err error) {
+		handler := refund.NewReviewHandler(links, links)
+		link, err := handler.Confirm(ctx, req.ID)
+		return refundReviewResult(ctx, txs, link, err)
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.RefundLinkRequest], log, endpoint)
+}
+
+// RejectRefundLink rejects a suggested refund link.
+//
+// @Summary     Reject a refund link
+// @Description Reject a suggested link, the same pair of transactions will not be suggested again
+// @Accept      json
+// @Produce     application/json
+// @Param       id  path     string true "Refund link ID"
+// @Success     200 {object} api.RefundLink "Rejected link"
+// @Failure     404 {object} map[string]string "Link not found"
+// @Failure     409 {object} map[string]string "Link is not a suggestion"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /refunds/{id}/reject [post]
+// @Tags        Refunds
+func RejectRefundLink(log logging.Logger, links *storage.SQLXRefundStore, txs *storage.SQLXTransactionStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.RefundLinkRequest) (status int, res api.RefundLink, err error) {
+		handler := refund.NewReviewHandler(links, links)
+		link, err := handler.Reject(ctx, req.ID)
+		return refundReviewResult(ctx, txs, link, err)
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.RefundLinkRequest], log, endpoint)
+}
+
+func refundReviewResult(ctx context.Context, txs *storage.SQLXTransactionStore, link *refund.Link, err error) (int, api.RefundLink, error) {
+	switch {
+	case errors.Is(err, refund.ErrLinkNotFound):
+		return http.StatusNotFound, api.RefundLink{}, err
+	case errors.Is(err, refund.ErrLinkNotSuggested), errors.Is(err, refund.ErrLinkAlreadyExists):
+		return http.StatusConflict, api.RefundLink{}, err
+	case err != nil:
+		return http.StatusInternalServerError, api.RefundLink{}, err
+	}
+	res, err := toRefundLinks(ctx, txs, link)
+	if err != nil {
+		return http.StatusInternalServerError, api.RefundLink{}, err
+	}
+	return http.StatusOK, res[0], nil
+}
+
+// toRefundLinks maps refund links to their API representation including the
+// linked transactions.
+func toRefundLinks(ctx context.Context, txs *storage.SQLXTransactionStore, links ...*refund.Link) ([]api.RefundLink, error) {
+	ids := make([]uuid.UUID, 0, len(links)*2)
+	for _, l := range links {
+		ids = append(ids, l.OriginalID, l.RefundID)
+	}
+	found, err := txs.FetchByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]api.Transaction, len(found))
+	for _, tx := range found {
+		byID[tx.ID] = toTransaction(tx)
+	}
+	res := make([]api.RefundLink, 0, len(links))
+	for _, l := range links {
+		res = append(res, api.RefundLink{
+			ID:          l.ID,
+			Status:      string(l.Status),
+			AmountCents: l.AmountCents,
+			Original:    byID[l.OriginalID],
+			Refund:      byID[l.RefundID],
+			CreatedAt:   l.CreatedAt,
+			UpdatedAt:   l.UpdatedAt,
+		})
+	}
+	return res, nil
+}
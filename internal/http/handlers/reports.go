@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/api"
+	httpx "github.com/lennardclaproth/my-finances-tracker/internal/http"
+	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+	"github.com/lennardclaproth/my-finances-tracker/internal/report"
+	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
+)
+
+// Cashflow reports income, expenses and net cash flow per period.
+//
+// @Summary     Cash flow report
+// @Description Sum income and expenses per week, month, quarter or year, optionally split per tag, account or counterparty. Amounts are converted to the reporting currency with the ECB rate of the transaction date, transactions in a currency without rates are counted as unconverted. Ignored transactions and transfers between own accounts are left out, refunds count towards the category of the original purchase. Send Accept: text/csv for CSV output.
+// @Accept      json
+// @Produce     application/json,text/csv
+// @Param       from     query    string false "First date (YYYY-MM-DD)"
+// @Param       to       query    string false "Last date (YYYY-MM-DD)"
+// @Param       interval query    string false "Bucket length (week, month, quarter, year), defaults to month"
+// @Param       group_by query    string false "Split buckets per tag, account or counterparty"
+// @Success     200 {object} api.CashflowReport "Cash flow per bucket"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /reports/cashflow [get]
+// @Tags        Reports
+func Cashflow(log logging.Logger, store *storage.SQLXReportStore, currency string) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.CashflowRequest) (status int, res api.CashflowReport, err error) {
+		interval, err := report.ParseInterval(req.Interval)
+		if err != nil {
+			return reportErrorStatus(err), res, err
+		}
+		groupBy, err := report.ParseGroupBy(req.GroupBy)
+		if err != nil {
+			return reportErrorStatus(err), res, err
+		}
+		rows, err := store.Cashflow(ctx, req.From, req.To, interval, groupBy, currency)
+		if err != nil {
+			return reportErrorStatus(err), res, err
+		}
+		res = api.CashflowReport{Interval: string(interval), GroupBy: string(groupBy), Currency: currency, Buckets: make([]api.CashflowBucket, 0, len(rows))}
+		for _, r := range rows {
+			res.Buckets = append(res.Buckets, api.CashflowBucket{
+				Start:        r.Start.Format(time.DateOnly),
+				End:          interval.End(r.Start).Format(time.DateOnly),
+				Group:        r.Group,
+				IncomeCents:  r.IncomeCents,
+				ExpenseCents: r.ExpenseCents,
+				NetCents:     r.NetCents(),
+				Unconverted:  r.Unconverted,
+			})
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.CashflowRequest], log, endpoint)
+}
+
+func reportErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, report.ErrInvalidInterval),
+		errors.Is(err, report.ErrInvalidGroupBy):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/lennardclaproth/my-finances-tracker/api"
+	httpx "github.com/lennardclaproth/my-finances-tracker/internal/http"
+	"github.com/lennardclaproth/my-finances-tracker/internal/learning"
+	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+	"github.com/lennardclaproth/my-finances-tracker/internal/review"
+	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// ListTagReviews lists machine tags that need a human look.
+//
+// @Summary     List tags to review
+// @Description List transactions tagged by the classifier or an agent with a confidence below the threshold, or left uncategorised by them, newest first
+// @Accept      json
+// @Produce     application/json
+// @Param       below     query    number false "Confidence threshold, the configured one by default"
+// @Param       page      query    int    false "Page, starting at 1"
+// @Param       page_size query    int    false "Page size, 50 by default"
+// @Success     200 {object} api.TransactionPage "Transactions to review"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /review/tags [get]
+// @Tags        Review
+func ListTagReviews(log logging.Logger, store *storage.SQLXTransactionStore, labels *storage.SQLXLabelStore, reviewBelow float64) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.ReviewTagsRequest) (status int, res api.TransactionPage, err error) {
+		f := transaction.Filter{ReviewBelow: reviewBelow, Page: req.Page, PageSize: req.PageSize}
+		if req.Below > 0 {
+			f.ReviewBelow = req.Below
+		}
+		if f.ReviewBelow <= 0 {
+			f.ReviewBelow = review.DefaultBelow
+		}
+		txs, total, err := store.Query(ctx, f)
+		if err != nil {
+			return http.StatusInternalServerError, res, err
+		}
+		items, err := toLabelledTransactions(ctx, labels, txs)
+		if err != nil {
+			return http.StatusInternalServerError, res, err
+		}
+		limit, offset := f.Limit()
+		return http.StatusOK, api.TransactionPage{
+			Items:    items,
+			Total:    total,
+			Page:     offset/limit + 1,
+			PageSize: limit,
+		}, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.ReviewTagsRequest], log, endpoint)
+}
+
+// ReviewTag accepts or corrects a machine tag.
+//
+// @Summary     Review a tag
+// @Description Accept the machine tag of a transaction or correct it, either way it becomes a manual tag. Corrections are learned from like manual re-tags.
+// @Accept      application/json
+// @Produce     application/json
+// @Param       id      path     string               true "Transaction ID"
+// @Param       payload body     api.ReviewTagRequest true "Review"
+// @Success     200 {object} api.Transaction "Reviewed transaction"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     404 {object} map[string]string "Transaction not found"
+// @Failure     409 {object} map[string]string "Tag was not chosen by a machine tagger"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /review/tags/{id} [post]
+// @Tags        Review
+func ReviewTag(log logging.Logger, store *storage.SQLXTransactionStore, categories *storage.SQLXCategoryStore, learner *learning.RecordHandler) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.ReviewTagRequest) (status int, res api.Transaction, err error) {
+		if req.Tag != "" {
+			if status, err := validateCategory(ctx, categories, req.Tag); err != nil {
+				return status, res, err
+			}
+		}
+		handler := review.NewTagHandler(store, store)
+		tx, previousTag, err := handler.Handle(ctx, req.ID, req.Tag)
+		if err != nil {
+			return reviewErrorStatus(err), res, err
+		}
+		// learning from the correction is best effort, the tag is saved anyway
+		if _, err := learner.Handle(ctx, tx, previousTag); err != nil {
+			log.Error(ctx, "failed to learn from tag correction", err, "transaction_id", tx.ID)
+		}
+		return http.StatusOK, toTransaction(tx), nil
+	}
+	return httpx.Endpoint(httpx.JSONPathDecoder[api.ReviewTagRequest], log, endpoint)
+}
+
+func reviewErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, transaction.ErrNoTransactionFound):
+		return http.StatusNotFound
+	case errors.Is(err, review.ErrNotReviewable):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
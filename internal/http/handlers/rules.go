@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/api"
+	httpx "github.com/lennardclaproth/my-finances-tracker/internal/http"
+	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+	"github.com/lennardclaproth/my-finances-tracker/internal/rule"
+	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// ListRules lists all tagging rules in evaluation order.
+//
+// @Summary     List rules
+// @Description List all rules ordered by priority
+// @Accept      json
+// @Produce     application/json
+// @Success     200 {array}  api.Rule "Rules"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /rules [get]
+// @Tags        Rules
+func ListRules(log logging.Logger, store *storage.SQLXRuleStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req struct{}) (status int, res []api.Rule, err error) {
+		rules, err := store.List(ctx)
+		if err != nil {
+			return http.StatusInternalServerError, nil, err
+		}
+		res = make([]api.Rule, 0, len(rules))
+		for _, r := range rules {
+			res = append(res, toRule(r))
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[struct{}], log, endpoint)
+}
+
+// GetRule returns a single rule.
+//
+// @Summary     Get a rule
+// @Description Get a rule by id
+// @Accept      json
+// @Produce     application/json
+// @Param       id  path     string true "Rule ID"
+// @Success     200 {object} api.Rule "Rule"
+// @Failure     404 {object} map[string]string "Rule not found"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /rules/{id} [get]
+// @Tags        Rules
+func GetRule(log logging.Logger, store *storage.SQLXRuleStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.RuleIDRequest) (status int, res api.Rule, err error) {
+		r, err := store.FetchByID(ctx, req.ID)
+		if err != nil {
+			return ruleErrorStatus(err), res, err
+		}
+		return http.StatusOK, toRule(r), nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.RuleIDRequest], log, endpoint)
+}
+
+// CreateRule creates a tagging rule.
+//
+// @Summary     Create a rule
+// @Description Create a rule that is applied to transactions during imports, lower priorities are evaluated first
+// @Accept      application/json
+// @Produce     application/json
+// @Param       payload body     api.RuleRequest true "Rule"
+// @Success     201 {object} api.Rule "Created rule"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /rules [post]
+// @Tags        Rules
+func CreateRule(log logging.Logger, store *storage.SQLXRuleStore, categories *storage.SQLXCategoryStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.RuleRequest) (status int, res api.Rule, err error) {
+		if req.Actions.SetTag != nil {
+			if status, err := validateCategory(ctx, categories, *req.Actions.SetTag); err != nil {
+				return status, res, err
+			}
+		}
+		handler := rule.NewCreateHandler(store)
+		r, err := handler.Handle(ctx, toRuleDefinition(req))
+		if err != nil {
+			return ruleErrorStatus(err), res, err
+		}
+		return http.StatusCreated, toRule(r), nil
+	}
+	return httpx.Endpoint(httpx.JSONDecoder[api.RuleRequest], log, endpoint)
+}
+
+// UpdateRule replaces the definition of a rule.
+//
+// @Summary     Update a rule
+// @Description Replace the definition of a rule
+// @Accept      application/json
+// @Produce     application/json
+// @Param       id      path     string          true "Rule ID"
+// @Param       payload body     api.RuleRequest true "Rule"
+// @Success     200 {object} api.Rule "Updated rule"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     404 {object} map[string]string "Rule not found"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /rules/{id} [put]
+// @Tags        Rules
+func UpdateRule(log logging.Logger, store *storage.SQLXRuleStore, categories *storage.SQLXCategoryStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.RuleRequest) (status int, res api.Rule, err error) {
+		if req.Actions.SetTag != nil {
+			if status, err := validateCategory(ctx, categories, *req.Actions.SetTag); err != nil {
+				return status, res, err
+			}
+		}
+		handler := rule.NewUpdateHandler(store, store)
+		r, err := handler.Handle(ctx, req.ID, toRuleDefinition(req))
+		if err != nil {
+			return ruleErrorStatus(err), res, err
+		}
+		return http.StatusOK, toRule(r), nil
+	}
+	return httpx.Endpoint(httpx.JSONPathDecoder[api.RuleRequest], log, endpoint)
+}
+
+// DeleteRule deletes a rule.
+//
+// @Summary     Delete a rule
+// @Description Delete a rule, transactions it classified keep their values
+// @Accept      json
+// @Produce     application/json
+// @Param       id  path     string true "Rule ID"
+// @Success     200 {object} map[string]string "OK"
+// @Failure     404 {object} map[string]string "Rule not found"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /rules/{id} [delete]
+// @Tags        Rules
+func DeleteRule(log logging.Logger, store *storage.SQLXRuleStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.RuleIDRequest) (status int, res struct{}, err error) {
+		if err := store.Delete(ctx, req.ID); err != nil {
+			return ruleErrorStatus(err), res, err
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.RuleIDRequest], log, endpoint)
+}
+
+// ApplyRule backfills a rule onto the stored transactions.
+//
+// @Summary     Apply a rule to existing transactions
+// @Description Apply a rule to the stored transactions, with dry_run the transactions that would change are returned without writing anything
+// @Accept      json
+// @Produce     application/json
+// @Param       id            path     string true  "Rule ID"
+// @Param       dry_run       query    bool   false "Only report the changes"
+// @Param       only_untagged query    bool   false "Only consider untagged transactions"
+// @Success     200 {object} api.ApplyRuleResult "Changes"
+// @Failure     404 {object} map[string]string "Rule not found"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /rules/{id}/apply [post]
+// @Tags        Rules
+func ApplyRule(log logging.Logger, store *storage.SQLXRuleStore, txs *storage.SQLXTransactionStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.ApplyRuleRequest) (status int, res api.ApplyRuleResult, err error) {
+		handler := rule.NewBackfillHandler(store, store, txs, txs)
+		changes, err := handler.Handle(ctx, req.ID, req.DryRun, req.OnlyUntagged)
+		if err != nil {
+			return ruleErrorStatus(err), res, err
+		}
+		res = api.ApplyRuleResult{DryRun: req.DryRun, Affected: len(changes), Changes: make([]api.RuleChange, 0, len(changes))}
+		for _, c := range changes {
+			res.Changes = append(res.Changes, toRuleChange(c))
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.ApplyRuleRequest], log, endpoint)
+}
+
+func ruleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, rule.ErrRuleNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, rule.ErrInvalidName),
+		errors.Is(err, rule.ErrNoConditions),
+		errors.Is(err, rule.ErrNoActions),
+		errors.Is(err, rule.ErrInvalidRegex),
+		errors.Is(err, rule.ErrInvalidAmount),
+		errors.Is(err, rule.ErrInvalidDirection):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func toRuleDefinition(req api.RuleRequest) rule.Definition {
+	weekdays := make([]time.Weekday, 0, len(req.Conditions.Weekdays))
+	for _, d := range req.Conditions.Weekdays {
+		weekdays = append(weekdays, time.Weekday(d))
+	}
+	return rule.Definition{
+		Name:     req.Name,
+		Priority: req.Priority,
+		Enabled:  req.Enabled,
+		Conditions: rule.Conditions{
+			DescriptionRegex: req.Conditions.DescriptionRegex,
+			NoteRegex:        req.Conditions.NoteRegex,
+			CounterpartyID:   req.Conditions.CounterpartyID,
+			MinAmountCents:   req.Conditions.MinAmountCents,
+			MaxAmountCents:   req.Conditions.MaxAmountCents,
+			Direction:        transaction.CashFlowDirection(req.Conditions.Direction),
+			Account:          req.Conditions.Account,
+			Weekdays:         weekdays,
+		},
+		Actions: rule.Actions{
+			SetTag:     req.Actions.SetTag,
+			SetIgnored: req.Actions.SetIgnored,
+			SetNote:    req.Actions.SetNote,
+		},
+	}
+}
+
+func toRule(r *rule.Rule) api.Rule {
+	var weekdays []int
+	for _, d := range r.Conditions.Weekdays {
+		weekdays = append(weekdays, int(d))
+	}
+	return api.Rule{
+		ID:       r.ID,
+		Name:     r.Name,
+		Priority: r.Priority,
+		Enabled:  r.Enabled,
+		Conditions: api.RuleConditions{
+			DescriptionRegex: r.Conditions.DescriptionRegex,
+			NoteRegex:        r.Conditions.NoteRegex,
+			CounterpartyID:   r.Conditions.CounterpartyID,
+			MinAmountCents:   r.Conditions.MinAmountCents,
+			MaxAmountCents:   r.Conditions.MaxAmountCents,
+			Direction:        string(r.Conditions.Direction),
+			Account:          r.Conditions.Account,
+			Weekdays:         weekdays,
+		},
+		Actions: api.RuleActions{
+			SetTag:     r.Actions.SetTag,
+			SetIgnored: r.Actions.SetIgnored,
+			SetNote:    r.Actions.SetNote,
+		},
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+func toRuleChange(c rule.Change) api.RuleChange {
+	res := api.RuleChange{
+		TransactionID: c.TransactionID,
+		Description:   c.Description,
+		Date:          c.Date,
+	}
+	if c.Tag != nil {
+		res.Tag = &api.StringChange{From: c.Tag.From, To: c.Tag.To}
+	}
+	if c.Ignored != nil {
+		res.Ignored = &api.BoolChange{From: c.Ignored.From, To: c.Ignored.To}
+	}
+	if c.Note != nil {
+		res.Note = &api.StringChange{From: c.Note.From, To: c.Note.To}
+	}
+	return res
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/lennardclaproth/my-finances-tracker/api"
+	httpx "github.com/lennardclaproth/my-finances-tracker/internal/http"
+	"github.com/lennardclaproth/my-finances-tracker/internal/learning"
+	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
+)
+
+// ListRuleSuggestions lists rules proposed from manual tag corrections.
+//
+// @Summary     List rule suggestions
+// @Description List rules learned from manual tag corrections, most frequent corrections first
+// @Accept      json
+// @Produce     application/json
+// @Param       status query    string false "Suggestion status (pending, accepted, dismissed)"
+// @Success     200 {array}  api.RuleSuggestion "Suggestions"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /rules/suggestions [get]
+// @Tags        Rules
+func ListRuleSuggestions(log logging.Logger, store *storage.SQLXSuggestionStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.ListRuleSuggestionsRequest) (status int, res []api.RuleSuggestion, err error) {
+		suggestions, err := store.List(ctx, learning.SuggestionStatus(req.Status))
+		if err != nil {
+			return http.StatusInternalServerError, nil, err
+		}
+		res = make([]api.RuleSuggestion, 0, len(suggestions))
+		for _, s := range suggestions {
+			res = append(res, toRuleSuggestion(s))
+		}
+		return http.StatusOK, res, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.ListRuleSuggestionsRequest], log, endpoint)
+}
+
+// AcceptRuleSuggestion creates the rule proposed by a suggestion.
+//
+// @Summary     Accept a rule suggestion
+// @Description Create the rule proposed by a pending suggestion
+// @Accept      json
+// @Produce     application/json
+// @Param       id  path     string true "Suggestion ID"
+// @Success     200 {object} api.RuleSuggestion "Accepted suggestion"
+// @Failure     404 {object} map[string]string "Suggestion not found"
+// @Failure     409 {object} map[string]string "Suggestion already handled"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /rules/suggestions/{id}/accept [post]
+// @Tags        Rules
+func AcceptRuleSuggestion(log logging.Logger, store *storage.SQLXSuggestionStore, rules *storage.SQLXRuleStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.RuleSuggestionRequest) (status int, res api.RuleSuggestion, err error) {
+		handler := learning.NewReviewHandler(store, store, store, rules)
+		s, err := handler.Accept(ctx, req.ID)
+		if err != nil {
+			return suggestionErrorStatus(err), res, err
+		}
+		return http.StatusOK, toRuleSuggestion(s), nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.RuleSuggestionRequest], log, endpoint)
+}
+
+// DismissRuleSuggestion dismisses a suggestion.
+//
+// @Summary     Dismiss a rule suggestion
+// @Description Dismiss a pending suggestion, it will not be auto-created anymore
+// @Accept      json
+// @Produce     application/json
+// @Param       id  path     string true "Suggestion ID"
+// @Success     200 {object} api.RuleSuggestion "Dismissed suggestion"
+// @Failure     404 {object} map[string]string "Suggestion not found"
+// @Failure     409 {object} map[string]string "Suggestion already handled"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /rules/suggestions/{id}/dismiss [post]
+// @Tags        Rules
+func DismissRuleSuggestion(log logging.Logger, store *storage.SQLXSuggestionStore, rules *storage.SQLXRuleStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.RuleSuggestionRequest) (status int, res api.RuleSuggestion, err error) {
+		handler := learning.NewReviewHandler(store, store, store, rules)
+		s, err := handler.Dismiss(ctx, req.ID)
+		if err != nil {
+			return suggestionErrorStatus(err), res, err
+		}
+		return http.StatusOK, toRuleSuggestion(s), nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.RuleSuggestionRequest], log, endpoint)
+}
+
+func suggestionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, learning.ErrSuggestionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, learning.ErrSuggestionHandled):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func toRuleSuggestion(s *learning.Suggestion) api.RuleSuggestion {
+	return api.RuleSuggestion{
+		ID:        s.ID,
+		KeyType:   string(s.KeyType),
+		Key:       s.Key,
+		Tag:       s.Tag,
+		Count:     s.Count,
+		Status:    string(s.Status),
+		RuleID:    s.RuleID,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
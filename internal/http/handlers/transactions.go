@@ -2,33 +2,59 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/lennardclaproth/my-finances-tracker/api"
 	httpx "github.com/lennardclaproth/my-finances-tracker/internal/http"
+	"github.com/lennardclaproth/my-finances-tracker/internal/label"
+	"github.com/lennardclaproth/my-finances-tracker/internal/learning"
 	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+	"github.com/lennardclaproth/my-finances-tracker/internal/money"
 	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
 )
 
 // TagTransaction applies a tag to an existing transaction.
 //
 // @Summary     Tag a transaction
-// @Description Apply a tag to a transaction by id
+// @Description Apply a category to a transaction by id, the tag is the slug of an existing category
 // @Accept      application/json
 // @Produce     application/json
 // @Param       payload body     api.TagTransactionRequest true "Tag request"
 // @Success     200 {object} map[string]string "OK"
 // @Failure     400 {object} map[string]string "Bad request"
+// @Failure     404 {object} map[string]string "Transaction not found"
 // @Failure     500 {object} map[string]string "Internal server error"
 // @Router      /transactions/tag [post]
 // @Tags        Transactions
-func TagTransaction(log logging.Logger, tagger *storage.SQLXTransactionStore) http.HandlerFunc {
+func TagTransaction(log logging.Logger, tagger *storage.SQLXTransactionStore, categories *storage.SQLXCategoryStore, learner *learning.RecordHandler) http.HandlerFunc {
 	// endpoint closure uses the injected tagger to construct the use-case handler
 	endpoint := func(ctx context.Context, req api.TagTransactionRequest) (status int, res struct{}, err error) {
-		err = tagger.Tag(ctx, req.Id, req.Tag)
+		if status, err := validateCategory(ctx, categories, req.Tag); err != nil {
+			return status, struct{}{}, err
+		}
+		tx, err := tagger.FetchByID(ctx, req.Id)
+		if errors.Is(err, transaction.ErrNoTransactionFound) {
+			return http.StatusNotFound, struct{}{}, err
+		}
+		if err != nil {
+			return http.StatusInternalServerError, struct{}{}, err
+		}
+		previousTag := tx.Tag
+		err = tagger.Tag(ctx, req.Id, req.Tag, transaction.ManualTag())
 		if err != nil {
 			return http.StatusInternalServerError, struct{}{}, err
 		}
+		// learning from the correction is best effort, the tag is saved anyway
+		tx.Tag = req.Tag
+		if _, err := learner.Handle(ctx, tx, previousTag); err != nil {
+			log.Error(ctx, "failed to learn from tag correction", err, "transaction_id", tx.ID)
+		}
 		return http.StatusOK, struct{}{}, nil
 	}
 	decoderFn := httpx.DecoderFunc[api.TagTransactionRequest](func(r *http.Request) (api.TagTransactionRequest, error) {
@@ -36,3 +62,135 @@ func TagTransaction(log logging.Logger, tagger *storage.SQLXTransactionStore) ht
 	})
 	return httpx.Endpoint(decoderFn, log, endpoint)
 }
+
+// CreateTransaction enters a transaction by hand.
+//
+// @Summary     Create a manual transaction
+// @Description Enter a transaction that is not on a bank statement, such as a cash payment. Manual entries can be deleted in bulk, imported transactions cannot.
+// @Accept      application/json
+// @Produce     application/json
+// @Param       payload body     api.CreateTransactionRequest true "Transaction"
+// @Success     201 {object} api.Transaction "Created transaction"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /transactions [post]
+// @Tags        Transactions
+func CreateTransaction(log logging.Logger, store *storage.SQLXTransactionStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.CreateTransactionRequest) (status int, res api.Transaction, err error) {
+		date, _ := time.Parse(time.DateOnly, req.Date)
+		amount := money.Amount{Cents: req.AmountCents, Currency: strings.ToUpper(req.Currency)}
+		tx, err := transaction.NewManualTransaction(req.Description, req.Note, transaction.CashFlowDirection(req.Direction), amount, date, req.Account)
+		if errors.Is(err, transaction.ErrInvalidAmount) || errors.Is(err, transaction.ErrUnsupportedDirection) {
+			return http.StatusBadRequest, res, err
+		}
+		if err != nil {
+			return http.StatusInternalServerError, res, err
+		}
+		if err := store.Create(ctx, tx); err != nil {
+			return http.StatusInternalServerError, res, err
+		}
+		return http.StatusCreated, toTransaction(tx), nil
+	}
+	return httpx.Endpoint(httpx.JSONDecoder[api.CreateTransactionRequest], log, endpoint)
+}
+
+// ListTransactions lists transactions matching the filters.
+//
+// @Summary     List transactions
+// @Description List transactions newest first, filtered by date range, category, direction, counterparty and labels
+// @Accept      json
+// @Produce     application/json
+// @Param       from            query    string false "First date (YYYY-MM-DD)"
+// @Param       to              query    string false "Last date (YYYY-MM-DD)"
+// @Param       tag             query    string false "Category slug"
+// @Param       direction       query    string false "in or out"
+// @Param       counterparty_id query    string false "Counterparty ID"
+// @Param       labels          query    string false "Comma separated labels the transactions must all carry"
+// @Param       page            query    int    false "Page, starting at 1"
+// @Param       page_size       query    int    false "Page size, 50 by default"
+// @Success     200 {object} api.TransactionPage "Transactions"
+// @Failure     400 {object} map[string]string "Bad request"
+// @Failure     500 {object} map[string]string "Internal server error"
+// @Router      /transactions [get]
+// @Tags        Transactions
+func ListTransactions(log logging.Logger, store *storage.SQLXTransactionStore, labels *storage.SQLXLabelStore) http.HandlerFunc {
+	endpoint := func(ctx context.Context, req api.ListTransactionsRequest) (status int, res api.TransactionPage, err error) {
+		names, err := label.Names(req.Labels)
+		if err != nil {
+			return http.StatusBadRequest, res, err
+		}
+		f := transaction.Filter{
+			From:      req.From,
+			To:        req.To,
+			Tag:       req.Tag,
+			Direction: transaction.CashFlowDirection(req.Direction),
+			Labels:    names,
+			Page:      req.Page,
+			PageSize:  req.PageSize,
+		}
+		if req.CounterpartyID != uuid.Nil {
+			f.CounterpartyID = &req.CounterpartyID
+		}
+		txs, total, err := store.Query(ctx, f)
+		if err != nil {
+			return http.StatusInternalServerError, res, err
+		}
+		items, err := toLabelledTransactions(ctx, labels, txs)
+		if err != nil {
+			return http.StatusInternalServerError, res, err
+		}
+		limit, offset := f.Limit()
+		return http.StatusOK, api.TransactionPage{
+			Items:    items,
+			Total:    total,
+			Page:     offset/limit + 1,
+			PageSize: limit,
+		}, nil
+	}
+	return httpx.Endpoint(httpx.QueryDecoder[api.ListTransactionsRequest], log, endpoint)
+}
+
+// toLabelledTransactions maps transactions to their API representation
+// including their labels.
+func toLabelledTransactions(ctx context.Context, labels *storage.SQLXLabelStore, txs []*transaction.Transaction) ([]api.Transaction, error) {
+	ids := make([]uuid.UUID, 0, len(txs))
+	for _, tx := range txs {
+		ids = append(ids, tx.ID)
+	}
+	names, err := labels.NamesFor(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]api.Transaction, 0, len(txs))
+	for _, tx := range txs {
+		t := toTransaction(tx)
+		t.Labels = names[tx.ID]
+		res = append(res, t)
+	}
+	return res, nil
+}
+
+// toTransaction maps a transaction to its API representation.
+func toTransaction(tx *transaction.Transaction) api.Transaction {
+	return api.Transaction{
+		ID:          tx.ID,
+		Description: tx.Description,
+		Note:        tx.Note,
+		Source:      tx.Source,
+		AmountCents: tx.AmountCents,
+		Currency:    tx.Currency,
+		Direction:   string(tx.Direction),
+		Date:        tx.Date,
+		Tag:         tx.Tag,
+
+		TagSource:     string(tx.TagSource),
+		TagConfidence: tx.TagConfidence,
+		TaggedBy:      tx.TaggedBy,
+		TaggedAt:      tx.TaggedAt,
+
+		CounterpartyID:   tx.CounterpartyID,
+		CounterpartyIBAN: tx.CounterpartyIBAN,
+
+		ParentID: tx.ParentID,
+	}
+}
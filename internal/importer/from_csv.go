@@ -1,6 +1,8 @@
 package importer
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"io"
 
@@ -38,15 +40,31 @@ func NewFromCsvHandler(ic ImportCreator, ifw ImportFileWriter, fr FileRemover, v
 	}
 }
 
-// Handle processes the CSV import for a given vendor ID.
+// spreadsheetMagic are the first bytes of XLS and XLSX files, which all
+// statement parsers read as text.
+var spreadsheetMagic = [][]byte{
+	{0xd0, 0xcf, 0x11, 0xe0, 0xa1, 0xb1, 0x1a, 0xe1},
+	[]byte("PK\x03\x04"),
+}
+
+// Handle processes the CSV import for a given vendor ID. Spreadsheets, such
+// as the XLS export of ABN AMRO, are rejected with ErrSpreadsheet before they
+// are stored.
 func (h *FromCsvHandler) Handle(ctx context.Context, r io.Reader, vendorId string) (uuid.UUID, error) {
 	// Get vendor via VendorFetcher
 	v, err := h.vf.FetchByName(ctx, vendor.VendorID(vendorId))
 	if err != nil {
 		return uuid.Nil, err
 	}
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(8)
+	for _, magic := range spreadsheetMagic {
+		if bytes.HasPrefix(head, magic) {
+			return uuid.Nil, ErrSpreadsheet
+		}
+	}
 	// Write file via ImportFileWriter
-	path, err := h.ifw.WriteCsv(r)
+	path, err := h.ifw.WriteCsv(br)
 	if err != nil {
 		return uuid.Nil, err
 	}
@@ -11,6 +11,7 @@ import (
 
 var (
 	ErrNoImportsPending = fmt.Errorf("no imports pending")
+	ErrSpreadsheet      = fmt.Errorf("spreadsheet files are not supported, upload the CSV or TXT export of the statement")
 )
 
 type ImportStatus string
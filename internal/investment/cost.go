@@ -0,0 +1,201 @@
+package investment
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/marketdata"
+)
+
+var ErrInvalidMethod = fmt.Errorf("cost basis method must be fifo or average")
+
+// Method decides which shares are sold first and so the cost basis of a
+// sale.
+type Method string
+
+const (
+	// MethodFIFO sells the oldest shares first.
+	MethodFIFO Method = "fifo"
+	// MethodAverage gives every share the average cost of the holding.
+	MethodAverage Method = "average"
+)
+
+// ParseMethod parses a cost basis method, FIFO when empty.
+func ParseMethod(s string) (Method, error) {
+	switch Method(s) {
+	case "", MethodFIFO:
+		return MethodFIFO, nil
+	case MethodAverage:
+		return MethodAverage, nil
+	default:
+		return "", ErrInvalidMethod
+	}
+}
+
+// Lot is a number of shares bought together. With average cost a holding
+// has a single lot.
+type Lot struct {
+	Date           time.Time
+	QuantityMicros int64
+	// CostCents is the price paid including fees.
+	CostCents int64
+}
+
+// Position is the holding of a security after a series of trades.
+type Position struct {
+	SecurityID uuid.UUID
+	Lots       []Lot
+	// RealisedCents is the proceeds of the sales minus the cost basis of
+	// the shares sold.
+	RealisedCents int64
+	// DividendCents are the dividends received after withheld tax.
+	DividendCents int64
+	// FeeCents are all fees paid, also those included in the cost basis and
+	// the realised gains.
+	FeeCents int64
+	// LastPrice is the price of the last buy or sell, it values the holding
+	// when there is no market price.
+	LastPrice     marketdata.Price
+	LastPriceDate time.Time
+	// Incomplete is set when more shares were sold than held, usually
+	// because the statement misses earlier purchases. The shares sold
+	// beyond the holding had no cost basis.
+	Incomplete bool
+}
+
+func (p *Position) QuantityMicros() int64 {
+	var q int64
+	for _, l := range p.Lots {
+		q += l.QuantityMicros
+	}
+	return q
+}
+
+// CostCents is the cost basis of the shares held.
+func (p *Position) CostCents() int64 {
+	var c int64
+	for _, l := range p.Lots {
+		c += l.CostCents
+	}
+	return c
+}
+
+// Ledger books trades in order of date into positions.
+type Ledger struct {
+	method    Method
+	positions map[uuid.UUID]*Position
+	// FeeCents are the fees that are not tied to a security, per currency.
+	FeeCents map[string]int64
+}
+
+func NewLedger(method Method) *Ledger {
+	return &Ledger{method: method, positions: map[uuid.UUID]*Position{}, FeeCents: map[string]int64{}}
+}
+
+// Position returns the position in the security, nil when it was never
+// traded.
+func (l *Ledger) Position(securityID uuid.UUID) *Position {
+	return l.positions[securityID]
+}
+
+// Positions returns every security that was traded, also those sold since.
+func (l *Ledger) Positions() []*Position {
+	res := make([]*Position, 0, len(l.positions))
+	for _, p := range l.positions {
+		res = append(res, p)
+	}
+	slices.SortFunc(res, func(a, b *Position) int { return slices.Compare(a.SecurityID[:], b.SecurityID[:]) })
+	return res
+}
+
+// Book applies the trade to its position.
+func (l *Ledger) Book(t *Trade) {
+	if t.SecurityID == nil {
+		l.FeeCents[t.Currency] += t.FeeCents
+		return
+	}
+	p, ok := l.positions[*t.SecurityID]
+	if !ok {
+		p = &Position{SecurityID: *t.SecurityID}
+		l.positions[*t.SecurityID] = p
+	}
+	p.FeeCents += t.FeeCents
+	switch t.Type {
+	case TradeBuy:
+		l.buy(p, t)
+	case TradeSell:
+		l.sell(p, t)
+	case TradeDividend:
+		p.DividendCents += t.AmountCents
+	case TradeSplit:
+		if t.RatioFrom > 0 && t.RatioTo > 0 {
+			for i := range p.Lots {
+				p.Lots[i].QuantityMicros = mulDiv(p.Lots[i].QuantityMicros, t.RatioTo, t.RatioFrom)
+			}
+		}
+	}
+	if (t.Type == TradeBuy || t.Type == TradeSell) && t.Price > 0 {
+		p.LastPrice, p.LastPriceDate = t.Price, t.Date
+	}
+}
+
+func (l *Ledger) buy(p *Position, t *Trade) {
+	lot := Lot{Date: t.Date, QuantityMicros: t.QuantityMicros, CostCents: -t.AmountCents + t.FeeCents}
+	if l.method == MethodAverage && len(p.Lots) > 0 {
+		p.Lots[0].QuantityMicros += lot.QuantityMicros
+		p.Lots[0].CostCents += lot.CostCents
+		return
+	}
+	p.Lots = append(p.Lots, lot)
+}
+
+// sell takes the shares from the oldest lots first, with average cost there
+// is only one lot so every share has the same cost.
+func (l *Ledger) sell(p *Position, t *Trade) {
+	remaining := t.QuantityMicros
+	var cost int64
+	for remaining > 0 && len(p.Lots) > 0 {
+		lot := &p.Lots[0]
+		if lot.QuantityMicros <= remaining {
+			remaining -= lot.QuantityMicros
+			cost += lot.CostCents
+			p.Lots = p.Lots[1:]
+			continue
+		}
+		part := mulDiv(lot.CostCents, remaining, lot.QuantityMicros)
+		cost += part
+		lot.CostCents -= part
+		lot.QuantityMicros -= remaining
+		remaining = 0
+	}
+	if remaining > 0 {
+		p.Incomplete = true
+	}
+	p.RealisedCents += t.AmountCents - t.FeeCents - cost
+}
+
+// SortTrades orders trades by date, trades at the same time are booked buys
+// and splits first so a sale never comes before the purchase it sells.
+func SortTrades(trades []*Trade) {
+	rank := map[TradeType]int{TradeBuy: 0, TradeSplit: 1, TradeDividend: 2, TradeFee: 3, TradeSell: 4}
+	slices.SortStableFunc(trades, func(a, b *Trade) int {
+		if c := a.Date.Compare(b.Date); c != 0 {
+			return c
+		}
+		return rank[a.Type] - rank[b.Type]
+	})
+}
+
+// mulDiv returns a*b/c rounded half away from zero without overflowing.
+func mulDiv(a, b, c int64) int64 {
+	v := new(big.Int).Mul(big.NewInt(a), big.NewInt(b))
+	d := big.NewInt(c)
+	q, r := new(big.Int).QuoRem(v, d, new(big.Int))
+	if new(big.Int).Abs(new(big.Int).Mul(r, big.NewInt(2))).Cmp(new(big.Int).Abs(d)) >= 0 {
+		q.Add(q, big.NewInt(int64(v.Sign()*d.Sign())))
+	}
+	return q.Int64()
+}
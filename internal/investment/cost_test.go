@@ -0,0 +1,258 @@
+package investment
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/marketdata"
+)
+
+var (
+	vwrl = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	nvda = uuid.MustParse("00000000-0000-0000-0000-000000000002")
+)
+
+func tradeDate(s string) time.Time {
+	d, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// trade returns a trade of whole shares at a price in whole units, the
+// amount is the quantity times the price, negative for buys.
+func trade(date string, security uuid.UUID, typ TradeType, shares, price, feeCents int64) *Trade {
+	t := &Trade{
+		SecurityID:     &security,
+		Date:           tradeDate(date),
+		Type:           typ,
+		QuantityMicros: shares * 1_000_000,
+		Price:          marketdata.Price(price * 1_000_000),
+		AmountCents:    shares * price * 100,
+		FeeCents:       feeCents,
+		Currency:       "EUR",
+	}
+	if typ == TradeBuy {
+		t.AmountCents = -t.AmountCents
+	}
+	return t
+}
+
+func TestParseMethod(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Method
+		wantErr error
+	}{
+		{"", MethodFIFO, nil},
+		{"fifo", MethodFIFO, nil},
+		{"average", MethodAverage, nil},
+		{"lifo", "", ErrInvalidMethod},
+	}
+	for _, tt := range tests {
+		got, err := ParseMethod(tt.in)
+		if got != tt.want || !errors.Is(err, tt.wantErr) {
+			t.Errorf("ParseMethod(%q) = %q, %v, want %q, %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// lot is a lot as shares in millionths and cost in cents.
+type lot struct {
+	quantity, cost int64
+}
+
+func TestLedgerBook(t *testing.T) {
+	split := trade("2024-02-01", nvda, TradeSplit, 0, 0, 0)
+	split.RatioFrom, split.RatioTo = 10_000_000, 30_000_000
+	dividend := trade("2024-04-01", vwrl, TradeDividend, 0, 0, 0)
+	dividend.AmountCents = 250
+	tax := trade("2024-04-01", vwrl, TradeDividend, 0, 0, 0)
+	tax.AmountCents = -38
+	connection := &Trade{Date: tradeDate("2024-01-01"), Type: TradeFee, FeeCents: 250, Currency: "EUR"}
+	thirds := trade("2024-01-02", vwrl, TradeBuy, 3, 0, 0)
+	thirds.AmountCents = -1000
+
+	tests := []struct {
+		name       string
+		method     Method
+		trades     []*Trade
+		security   uuid.UUID
+		lots       []lot
+		realised   int64
+		dividend   int64
+		fee        int64
+		incomplete bool
+	}{
+		{
+			// 10 at 100 and 10 at 120 with 2.00 fees each, 15 sold at 130
+			// with 3.00 fees: the first lot and half the second are sold,
+			// 1002.00 + 601.00 against 1950.00 - 3.00
+			name:   "fifo",
+			method: MethodFIFO,
+			trades: []*Trade{
+				trade("2024-01-02", vwrl, TradeBuy, 10, 100, 200),
+				trade("2024-02-01", vwrl, TradeBuy, 10, 120, 200),
+				trade("2024-03-01", vwrl, TradeSell, 15, 130, 300),
+			},
+			security: vwrl,
+			lots:     []lot{{5_000_000, 60100}},
+			realised: 195000 - 300 - 100200 - 60100,
+			fee:      700,
+		},
+		{
+			// the same trades at an average cost of 2204.00 / 20 = 110.20
+			name:   "average",
+			method: MethodAverage,
+			trades: []*Trade{
+				trade("2024-01-02", vwrl, TradeBuy, 10, 100, 200),
+				trade("2024-02-01", vwrl, TradeBuy, 10, 120, 200),
+				trade("2024-03-01", vwrl, TradeSell, 15, 130, 300),
+			},
+			security: vwrl,
+			lots:     []lot{{5_000_000, 55100}},
+			realised: 195000 - 300 - 165300,
+			fee:      700,
+		},
+		{
+			name:   "fifo keeps the lots",
+			method: MethodFIFO,
+			trades: []*Trade{
+				trade("2024-01-02", vwrl, TradeBuy, 10, 100, 200),
+				trade("2024-02-01", vwrl, TradeBuy, 10, 120, 200),
+			},
+			security: vwrl,
+			lots:     []lot{{10_000_000, 100200}, {10_000_000, 120200}},
+			fee:      400,
+		},
+		{
+			// a third of 10.00 is rounded to 3.33, the rest keeps 6.67
+			name:     "partial lot rounding",
+			method:   MethodFIFO,
+			trades:   []*Trade{thirds, trade("2024-03-01", vwrl, TradeSell, 1, 4, 0)},
+			security: vwrl,
+			lots:     []lot{{2_000_000, 667}},
+			realised: 400 - 333,
+		},
+		{
+			// 10 shares become 30, the cost basis stays 1001.00
+			name:   "split",
+			method: MethodFIFO,
+			trades: []*Trade{
+				trade("2024-01-02", nvda, TradeBuy, 10, 100, 100),
+				split,
+				trade("2024-03-01", nvda, TradeSell, 30, 40, 100),
+			},
+			security: nvda,
+			realised: 120000 - 100 - 100100,
+			fee:      200,
+		},
+		{
+			name:   "split with average cost",
+			method: MethodAverage,
+			trades: []*Trade{
+				trade("2024-01-02", nvda, TradeBuy, 10, 100, 100),
+				split,
+			},
+			security: nvda,
+			lots:     []lot{{30_000_000, 100100}},
+			fee:      100,
+		},
+		{
+			// the 3 shares sold beyond the holding have no cost basis
+			name:   "selling more than held",
+			method: MethodFIFO,
+			trades: []*Trade{
+				trade("2024-01-02", vwrl, TradeBuy, 5, 10, 0),
+				trade("2024-03-01", vwrl, TradeSell, 8, 12, 0),
+			},
+			security:   vwrl,
+			realised:   9600 - 5000,
+			incomplete: true,
+		},
+		{
+			name:     "dividend after withheld tax",
+			method:   MethodFIFO,
+			trades:   []*Trade{trade("2024-01-02", vwrl, TradeBuy, 1, 100, 0), dividend, tax},
+			security: vwrl,
+			lots:     []lot{{1_000_000, 10000}},
+			dividend: 212,
+		},
+		{
+			name:     "fee without a security",
+			method:   MethodFIFO,
+			trades:   []*Trade{connection, trade("2024-01-02", vwrl, TradeBuy, 1, 100, 50)},
+			security: vwrl,
+			lots:     []lot{{1_000_000, 10050}},
+			fee:      50,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLedger(tt.method)
+			for _, tr := range tt.trades {
+				l.Book(tr)
+			}
+			p := l.Position(tt.security)
+			if p == nil {
+				t.Fatal("Position() = nil")
+			}
+			var lots []lot
+			for _, pl := range p.Lots {
+				lots = append(lots, lot{pl.QuantityMicros, pl.CostCents})
+			}
+			if fmt.Sprint(lots) != fmt.Sprint(tt.lots) {
+				t.Errorf("lots = %v, want %v", lots, tt.lots)
+			}
+			if p.RealisedCents != tt.realised {
+				t.Errorf("RealisedCents = %d, want %d", p.RealisedCents, tt.realised)
+			}
+			if p.DividendCents != tt.dividend {
+				t.Errorf("DividendCents = %d, want %d", p.DividendCents, tt.dividend)
+			}
+			if p.FeeCents != tt.fee {
+				t.Errorf("FeeCents = %d, want %d", p.FeeCents, tt.fee)
+			}
+			if p.Incomplete != tt.incomplete {
+				t.Errorf("Incomplete = %v, want %v", p.Incomplete, tt.incomplete)
+			}
+			last := tt.trades[len(tt.trades)-1]
+			if (last.Type == TradeBuy || last.Type == TradeSell) && p.LastPrice != last.Price {
+				t.Errorf("LastPrice = %d, want %d", p.LastPrice, last.Price)
+			}
+		})
+	}
+}
+
+func TestLedgerFeeWithoutSecurity(t *testing.T) {
+	l := NewLedger(MethodFIFO)
+	l.Book(&Trade{Date: tradeDate("2024-01-01"), Type: TradeFee, FeeCents: 250, Currency: "EUR"})
+	l.Book(&Trade{Date: tradeDate("2025-01-01"), Type: TradeFee, FeeCents: 250, Currency: "EUR"})
+	if got := l.FeeCents["EUR"]; got != 500 {
+		t.Errorf("FeeCents[EUR] = %d, want 500", got)
+	}
+	if got := l.Positions(); len(got) != 0 {
+		t.Errorf("Positions() = %v, want none", got)
+	}
+}
+
+func TestSortTrades(t *testing.T) {
+	// a sale at the same time as the purchase it sells comes last
+	sell := trade("2024-01-02", vwrl, TradeSell, 1, 100, 0)
+	fee := &Trade{Date: tradeDate("2024-01-02"), Type: TradeFee}
+	buy := trade("2024-01-02", vwrl, TradeBuy, 1, 100, 0)
+	split := trade("2024-01-02", vwrl, TradeSplit, 0, 0, 0)
+	earlier := trade("2024-01-01", vwrl, TradeSell, 1, 100, 0)
+	trades := []*Trade{sell, fee, buy, split, earlier}
+	SortTrades(trades)
+	want := []*Trade{earlier, buy, split, fee, sell}
+	for i := range want {
+		if trades[i] != want[i] {
+			t.Fatalf("SortTrades() put %s %s at %d, want %s %s", trades[i].Date.Format(time.DateOnly), trades[i].Type, i, want[i].Date.Format(time.DateOnly), want[i].Type)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package investment
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/marketdata"
+)
+
+var (
+	ErrInvalidStatement = fmt.Errorf("the CSV file is not a DEGIRO account statement")
+	ErrInvalidLine      = fmt.Errorf("invalid statement line")
+)
+
+// StatementTrade is a trade read from a broker statement together with the
+// security it concerns, which may not be known yet.
+type StatementTrade struct {
+	Trade   *Trade
+	ISIN    string
+	Product string
+	// PriceCurrency is the currency of the security, the trade itself may
+	// be settled in another currency.
+	PriceCurrency string
+	// OrderID links the fees of a trade to it.
+	OrderID string
+}
+
+// Statement is a parsed broker statement, oldest trade first.
+type Statement struct {
+	Trades []StatementTrade
+	// Skipped is the number of lines that are not trades, such as deposits,
+	// currency exchanges and interest.
+	Skipped int
+}
+
+// degiroTrade matches the description of buys and sells in English, Dutch
+// and German, e.g. "Buy 10 VANGUARD FTSE AW@98.50 EUR (IE00B3RBWM25)" or
+// "Koop 10 VANGUARD FTSE AW@98,5 EUR (IE00B3RBWM25)".
+var degiroTrade = regexp.MustCompile(`(?i)^(?:stock split:\s*)?(buy|koop|kauf|sell|verkoop|verkauf)\s+([\d.,]+)\s+.*@([\d.,]+)\s+([A-Z]{3})`)
+
+var degiroColumns = map[string]string{
+	"date": "date", "datum": "date",
+	"time": "time", "tijd": "time", "uhrzeit": "time",
+	"product": "product", "produkt": "product",
+	"isin":        "isin",
+	"description": "description", "omschrijving": "description", "beschreibung": "description",
+	"change": "change", "mutatie": "change", "änderung": "change",
+	"order id": "order", "order-id": "order",
+}
+
+// ParseDEGIRO parses a DEGIRO account statement (Account.csv). Buys, sells,
+// dividends, withheld dividend tax and fees become trades, fees with the
+// order id of a trade are added to that trade. Stock splits, which DEGIRO
+// books as a sale and a purchase of the same ISIN, become a split.
+func ParseDEGIRO(r io.Reader, account string) (*Statement, error) {
+	account = strings.TrimSpace(account)
+	if account == "" {
+		return nil, ErrInvalidAccount
+	}
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, ErrInvalidStatement
+	}
+	index := map[string]int{}
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		if field, ok := degiroColumns[h]; ok {
+			if _, seen := index[field]; !seen {
+				index[field] = i
+			}
+		}
+	}
+	for _, field := range []string{"date", "description", "change"} {
+		if _, ok := index[field]; !ok {
+			return nil, ErrInvalidStatement
+		}
+	}
+
+	res := &Statement{}
+	seen := map[string]int{}
+	var splits []StatementTrade
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w on line %d: %v", ErrInvalidLine, line, err)
+		}
+		field := func(name string, offset int) string {
+			i, ok := index[name]
+			if !ok || i+offset >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i+offset])
+		}
+		if field("date", 0) == "" {
+			continue
+		}
+		st, err := parseDEGIROLine(field, account)
+		if err != nil {
+			return nil, fmt.Errorf("%w on line %d: %v", ErrInvalidLine, line, err)
+		}
+		if st == nil {
+			res.Skipped++
+			continue
+		}
+		// identical lines, e.g. two fills of the same size in the same
+		// minute, are told apart by their occurrence
+		key := strings.Join(record, "\x1f")
+		seen[key]++
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x1f%d", key, seen[key])))
+		st.Trade.ExternalID = "degiro:" + hex.EncodeToString(sum[:16])
+		if strings.HasPrefix(strings.ToLower(st.Trade.Description), "stock split") {
+			splits = append(splits, *st)
+			continue
+		}
+		res.Trades = append(res.Trades, *st)
+	}
+	res.Trades = append(res.Trades, mergeSplits(splits)...)
+	res.Trades = mergeFees(res.Trades)
+	// the statement lists the newest line first
+	slices.SortStableFunc(res.Trades, func(a, b StatementTrade) int { return a.Trade.Date.Compare(b.Trade.Date) })
+	return res, nil
+}
+
+// parseDEGIROLine returns nil for lines that are not trades.
+func parseDEGIROLine(field func(name string, offset int) string, account string) (*StatementTrade, error) {
+	date, err := time.Parse("02-01-2006 15:04", field("date", 0)+" "+field("time", 0))
+	if err != nil {
+		if date, err = time.Parse("02-01-2006", field("date", 0)); err != nil {
+			return nil, fmt.Errorf("invalid date %q", field("date", 0))
+		}
+	}
+	description := field("description", 0)
+	currency := field("change", 0)
+	amountText := field("change", 1)
+	if amountText == "" {
+		return nil, nil
+	}
+	amount, err := parseCents(amountText)
+	if err != nil {
+		return nil, err
+	}
+	st := &StatementTrade{
+		ISIN:    strings.ToUpper(field("isin", 0)),
+		Product: field("product", 0),
+		OrderID: field("order", 0),
+		Trade: &Trade{
+			ID:          uuid.New(),
+			Account:     account,
+			Date:        date,
+			Currency:    strings.ToUpper(currency),
+			Description: description,
+			CreatedAt:   time.Now().UTC(),
+		},
+	}
+	lower := strings.ToLower(description)
+	switch {
+	case degiroTrade.MatchString(description):
+		m := degiroTrade.FindStringSubmatch(description)
+		quantity, err := marketdata.ParsePrice(normaliseDecimal(m[2]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity %q", m[2])
+		}
+		price, err := marketdata.ParsePrice(normaliseDecimal(m[3]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid price %q", m[3])
+		}
+		st.Trade.Type = TradeBuy
+		if verb := strings.ToLower(m[1]); verb == "sell" || verb == "verkoop" || verb == "verkauf" {
+			st.Trade.Type = TradeSell
+		}
+		st.Trade.QuantityMicros = int64(quantity)
+		st.Trade.Price = price
+		st.Trade.AmountCents = amount
+		st.PriceCurrency = strings.ToUpper(m[4])
+	case strings.HasPrefix(lower, "dividend"):
+		st.Trade.Type = TradeDividend
+		st.Trade.AmountCents = amount
+	case strings.Contains(lower, "fee") || strings.Contains(lower, "kosten") || strings.Contains(lower, "gebühr"):
+		st.Trade.Type = TradeFee
+		st.Trade.FeeCents = -amount
+	default:
+		return nil, nil
+	}
+	if st.PriceCurrency == "" {
+		st.PriceCurrency = st.Trade.Currency
+	}
+	return st, nil
+}
+
+// mergeSplits turns a sale and a purchase of the same ISIN at the same time
+// into a split, other split lines are kept as ordinary trades.
+func mergeSplits(lines []StatementTrade) []StatementTrade {
+	var res []StatementTrade
+	used := make([]bool, len(lines))
+	for i, sell := range lines {
+		if used[i] || sell.Trade.Type != TradeSell {
+			continue
+		}
+		for j, buy := range lines {
+			if used[j] || buy.Trade.Type != TradeBuy || buy.ISIN != sell.ISIN || !buy.Trade.Date.Equal(sell.Trade.Date) {
+				continue
+			}
+			used[i], used[j] = true, true
+			split := buy
+			t := *buy.Trade
+			t.Type = TradeSplit
+			t.RatioFrom = sell.Trade.QuantityMicros
+			t.RatioTo = buy.Trade.QuantityMicros
+			t.QuantityMicros = 0
+			t.AmountCents = 0
+			split.Trade = &t
+			res = append(res, split)
+			break
+		}
+	}
+	for i, l := range lines {
+		if !used[i] {
+			res = append(res, l)
+		}
+	}
+	return res
+}
+
+// mergeFees adds the fees that carry the order id of a buy or sell to that
+// trade.
+func mergeFees(lines []StatementTrade) []StatementTrade {
+	orders := map[string]*Trade{}
+	for _, l := range lines {
+		if id := l.OrderID; (l.Trade.Type == TradeBuy || l.Trade.Type == TradeSell) && id != "" {
+			if _, ok := orders[id]; !ok {
+				orders[id] = l.Trade
+			}
+		}
+	}
+	res := make([]StatementTrade, 0, len(lines))
+	for _, l := range lines {
+		if l.Trade.Type == TradeFee {
+			if t, ok := orders[l.OrderID]; ok && l.OrderID != "" {
+				t.FeeCents += l.Trade.FeeCents
+				continue
+			}
+		}
+		res = append(res, l)
+	}
+	return res
+}
+
+// parseCents parses an amount with a decimal point or comma, such as
+// -1,234.56 or -1.234,56, into cents.
+func parseCents(s string) (int64, error) {
+	p, err := marketdata.ParsePrice(normaliseDecimal(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return p.ValueCents(1_000_000), nil
+}
+
+// normaliseDecimal removes thousands separators and turns a decimal comma
+// into a point. When both are used the last one is the decimal separator.
+func normaliseDecimal(s string) string {
+	s = strings.ReplaceAll(strings.TrimSpace(s), " ", "")
+	dot, comma := strings.LastIndex(s, "."), strings.LastIndex(s, ",")
+	switch {
+	case dot >= 0 && comma >= 0 && comma > dot:
+		s = strings.ReplaceAll(s, ".", "")
+	case dot >= 0 && comma >= 0:
+		s = strings.ReplaceAll(s, ",", "")
+	case comma >= 0 && strings.Count(s, ",") > 1:
+		s = strings.ReplaceAll(s, ",", "")
+	}
+	return strings.ReplaceAll(s, ",", ".")
+}
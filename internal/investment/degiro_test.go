@@ -0,0 +1,196 @@
+package investment
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// degiroSample is an English DEGIRO Account.csv, newest line first as
+// DEGIRO exports it.
+const degiroSample = `Date,Time,Value date,Product,ISIN,Description,FX,Change,,Balance,,Order Id
+20-06-2024,07:30,19-06-2024,APPLE INC,US0378331005,Dividend Tax,,USD,-3.60,USD,20.40,
+20-06-2024,07:30,19-06-2024,APPLE INC,US0378331005,Dividend,,USD,24.00,USD,24.00,
+02-04-2024,00:00,02-04-2024,,,DEGIRO Exchange Connection Fee 2024 (Euronext Amsterdam - EAM),,EUR,-2.50,EUR,1562.00,
+15-03-2024,09:05,15-03-2024,VANGUARD FTSE AW,IE00B3RBWM25,DEGIRO Transaction and/or third party fees,,EUR,-1.00,EUR,1564.50,7d3c2f1e-2
+15-03-2024,09:05,15-03-2024,VANGUARD FTSE AW,IE00B3RBWM25,Sell 5 VANGUARD FTSE AW@110.20 EUR (IE00B3RBWM25),,EUR,551.00,EUR,1565.50,7d3c2f1e-2
+01-03-2024,00:00,01-03-2024,NVIDIA CORP,US67066G1040,STOCK SPLIT: Buy 40 NVIDIA CORP@25 USD (US67066G1040),,USD,-1000.00,USD,0.00,
+01-03-2024,00:00,01-03-2024,NVIDIA CORP,US67066G1040,STOCK SPLIT: Sell 4 NVIDIA CORP@250 USD (US67066G1040),,USD,1000.00,USD,1000.00,
+02-01-2024,10:15,02-01-2024,VANGUARD FTSE AW,IE00B3RBWM25,DEGIRO Transaction and/or third party fees,,EUR,-2.00,EUR,1014.50,5a1b9c0d-1
+02-01-2024,10:15,02-01-2024,VANGUARD FTSE AW,IE00B3RBWM25,Buy 10 VANGUARD FTSE AW@98.50 EUR (IE00B3RBWM25),,EUR,-985.00,EUR,1016.50,5a1b9c0d-1
+02-01-2024,09:00,02-01-2024,,,Deposit,,EUR,2000.00,EUR,2000.00,
+`
+
+// degiroSampleDutch is the purchase of the sample in a Dutch export with
+// decimal commas.
+const degiroSampleDutch = `Datum,Tijd,Valutadatum,Product,ISIN,Omschrijving,FX,Mutatie,,Saldo,,Order Id
+02-01-2024,10:15,02-01-2024,VANGUARD FTSE AW,IE00B3RBWM25,DEGIRO Transactiekosten en/of kosten van derden,,EUR,"-2,00",EUR,"1014,50",5a1b9c0d-1
+02-01-2024,10:15,02-01-2024,VANGUARD FTSE AW,IE00B3RBWM25,"Koop 10 VANGUARD FTSE AW@98,5 EUR (IE00B3RBWM25)",,EUR,"-985,00",EUR,"1016,50",5a1b9c0d-1
+`
+
+// statementLine is a statement trade as
+// date type isin quantity price amount fee currency ratio.
+func statementLine(st StatementTrade) string {
+	t := st.Trade
+	return fmt.Sprintf("%s %s %s %d %d %d %d %s/%s %d:%d",
+		t.Date.Format("2006-01-02T15:04"), t.Type, st.ISIN, t.QuantityMicros, t.Price, t.AmountCents, t.FeeCents, t.Currency, st.PriceCurrency, t.RatioFrom, t.RatioTo)
+}
+
+func TestParseDEGIRO(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		want    []string
+		skipped int
+	}{
+		{
+			name: "english",
+			csv:  degiroSample,
+			want: []string{
+				// the fee of order 5a1b9c0d-1 is merged into the purchase
+				"2024-01-02T10:15 buy IE00B3RBWM25 10000000 98500000 -98500 200 EUR/EUR 0:0",
+				// 4 shares became 40
+				"2024-03-01T00:00 split US67066G1040 0 25000000 0 0 USD/USD 4000000:40000000",
+				"2024-03-15T09:05 sell IE00B3RBWM25 5000000 110200000 55100 100 EUR/EUR 0:0",
+				"2024-04-02T00:00 fee  0 0 0 250 EUR/EUR 0:0",
+				"2024-06-20T07:30 dividend US0378331005 0 0 -360 0 USD/USD 0:0",
+				"2024-06-20T07:30 dividend US0378331005 0 0 2400 0 USD/USD 0:0",
+			},
+			// the deposit
+			skipped: 1,
+		},
+		{
+			name: "dutch",
+			csv:  degiroSampleDutch,
+			want: []string{
+				"2024-01-02T10:15 buy IE00B3RBWM25 10000000 98500000 -98500 200 EUR/EUR 0:0",
+			},
+		},
+		{
+			name: "byte order mark and no time column",
+			csv:  "\ufeffDate,Product,ISIN,Description,Change,,Order Id\n02-01-2024,VANGUARD FTSE AW,IE00B3RBWM25,Buy 10 VANGUARD FTSE AW@98.50 EUR (IE00B3RBWM25),EUR,-985.00,\n",
+			want: []string{
+				"2024-01-02T00:00 buy IE00B3RBWM25 10000000 98500000 -98500 0 EUR/EUR 0:0",
+			},
+		},
+		{
+			// a trade settled in euro of a security priced in dollars
+			name: "settled in another currency",
+			csv:  "Date,Time,Product,ISIN,Description,Change,\n05-01-2024,15:31,APPLE INC,US0378331005,Buy 2 APPLE INC@181.91 USD (US0378331005),EUR,-332.10\n",
+			want: []string{
+				"2024-01-05T15:31 buy US0378331005 2000000 181910000 -33210 0 EUR/USD 0:0",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, err := ParseDEGIRO(strings.NewReader(tt.csv), " DEGIRO ")
+			if err != nil {
+				t.Fatalf("ParseDEGIRO() error = %v", err)
+			}
+			var got []string
+			for _, tr := range st.Trades {
+				got = append(got, statementLine(tr))
+				if tr.Trade.Account != "DEGIRO" {
+					t.Errorf("Account = %q, want DEGIRO", tr.Trade.Account)
+				}
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("ParseDEGIRO() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+			if st.Skipped != tt.skipped {
+				t.Errorf("Skipped = %d, want %d", st.Skipped, tt.skipped)
+			}
+		})
+	}
+}
+
+func TestParseDEGIROExternalIDs(t *testing.T) {
+	// two fills of the same size in the same minute are separate trades
+	line := "02-01-2024,10:15,VANGUARD FTSE AW,IE00B3RBWM25,Buy 1 VANGUARD FTSE AW@98.50 EUR (IE00B3RBWM25),EUR,-98.50\n"
+	csv := "Date,Time,Product,ISIN,Description,Change,\n" + line + line
+	first, err := ParseDEGIRO(strings.NewReader(csv), "DEGIRO")
+	if err != nil {
+		t.Fatalf("ParseDEGIRO() error = %v", err)
+	}
+	again, err := ParseDEGIRO(strings.NewReader(csv), "DEGIRO")
+	if err != nil {
+		t.Fatalf("ParseDEGIRO() error = %v", err)
+	}
+	if len(first.Trades) != 2 {
+		t.Fatalf("ParseDEGIRO() = %d trades, want 2", len(first.Trades))
+	}
+	a, b := first.Trades[0].Trade.ExternalID, first.Trades[1].Trade.ExternalID
+	if a == b || !strings.HasPrefix(a, "degiro:") {
+		t.Errorf("ExternalID = %q and %q, want distinct degiro ids", a, b)
+	}
+	// importing the same statement again gives the same ids
+	if again.Trades[0].Trade.ExternalID != a || again.Trades[1].Trade.ExternalID != b {
+		t.Errorf("ExternalID changed between imports")
+	}
+}
+
+func TestParseDEGIROErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		account string
+		wantErr error
+	}{
+		{"no account", degiroSample, " ", ErrInvalidAccount},
+		{"empty file", "", "DEGIRO", ErrInvalidStatement},
+		{"not a statement", "Date,Amount\n02-01-2024,1.00\n", "DEGIRO", ErrInvalidStatement},
+		{"invalid date", "Date,Description,Change,\n2024-01-02,Dividend,EUR,1.00\n", "DEGIRO", ErrInvalidLine},
+		{"invalid amount", "Date,Description,Change,\n02-01-2024,Dividend,EUR,one\n", "DEGIRO", ErrInvalidLine},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseDEGIRO(strings.NewReader(tt.csv), tt.account); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ParseDEGIRO() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBookDEGIRO(t *testing.T) {
+	// the sample booked by hand: 10 VWRL for 985.00 + 2.00, 5 sold for
+	// 551.00 - 1.00 against half the cost, 493.50
+	st, err := ParseDEGIRO(strings.NewReader(degiroSample), "DEGIRO")
+	if err != nil {
+		t.Fatalf("ParseDEGIRO() error = %v", err)
+	}
+	ids := map[string]uuid.UUID{"IE00B3RBWM25": vwrl, "US67066G1040": nvda, "US0378331005": uuid.MustParse("00000000-0000-0000-0000-000000000003")}
+	l := NewLedger(MethodFIFO)
+	for _, s := range st.Trades {
+		if id, ok := ids[s.ISIN]; ok {
+			s.Trade.SecurityID = &id
+		}
+		l.Book(s.Trade)
+	}
+	p := l.Position(vwrl)
+	if got := p.RealisedCents; got != 55000-49350 {
+		t.Errorf("RealisedCents = %d, want %d", got, 55000-49350)
+	}
+	if got := p.CostCents(); got != 49350 {
+		t.Errorf("CostCents() = %d, want 49350", got)
+	}
+	if got := p.QuantityMicros(); got != 5_000_000 {
+		t.Errorf("QuantityMicros() = %d, want 5000000", got)
+	}
+	if got := p.FeeCents; got != 300 {
+		t.Errorf("FeeCents = %d, want 300", got)
+	}
+	if got := p.LastPriceDate; !got.Equal(time.Date(2024, 3, 15, 9, 5, 0, 0, time.UTC)) {
+		t.Errorf("LastPriceDate = %s", got)
+	}
+	if got := l.Position(ids["US0378331005"]).DividendCents; got != 2040 {
+		t.Errorf("DividendCents = %d, want 2040", got)
+	}
+	if got := l.FeeCents["EUR"]; got != 250 {
+		t.Errorf("FeeCents[EUR] = %d, want 250", got)
+	}
+}
@@ -0,0 +1,120 @@
+package investment
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/fx"
+)
+
+// Shared interfaces used by multiple use cases
+
+type SecurityLister interface {
+	ListSecurities(ctx context.Context) ([]*Security, error)
+}
+
+type TradeLister interface {
+	// Trades returns the trades up to and including the day, oldest first.
+	Trades(ctx context.Context, through time.Time) ([]*Trade, error)
+}
+
+// Single-use interfaces only used by ImportHandler
+
+type SecurityStore interface {
+	FetchSecurityByISIN(ctx context.Context, isin string) (*Security, error)
+	CreateSecurity(ctx context.Context, s *Security) error
+}
+
+type TradeSaver interface {
+	// SaveTrades stores the trades that were not imported before and
+	// returns how many it stored.
+	SaveTrades(ctx context.Context, trades []*Trade) (int, error)
+}
+
+// ImportResult is the outcome of importing a statement.
+type ImportResult struct {
+	Imported   int
+	Duplicates int
+	// Skipped counts the lines that are not trades or concern a security
+	// without a valid ISIN.
+	Skipped    int
+	Securities []*Security
+}
+
+// ImportHandler imports broker statements.
+type ImportHandler struct {
+	ss SecurityStore
+	ts TradeSaver
+}
+
+func NewImportHandler(ss SecurityStore, ts TradeSaver) *ImportHandler {
+	return &ImportHandler{ss: ss, ts: ts}
+}
+
+// Handle imports a DEGIRO account statement into the account. Securities
+// are created by ISIN the first time they appear, without a symbol, so they
+// are valued at their last trade until a symbol is set. Lines that were
+// imported before are skipped.
+func (h *ImportHandler) Handle(ctx context.Context, r io.Reader, account string) (*ImportResult, error) {
+	statement, err := ParseDEGIRO(r, account)
+	if err != nil {
+		return nil, err
+	}
+	res := &ImportResult{Skipped: statement.Skipped, Securities: []*Security{}}
+	securities := map[string]*Security{}
+	trades := make([]*Trade, 0, len(statement.Trades))
+	for _, st := range statement.Trades {
+		if st.ISIN == "" {
+			if st.Trade.Type != TradeFee {
+				res.Skipped++
+				continue
+			}
+			trades = append(trades, st.Trade)
+			continue
+		}
+		s, ok := securities[st.ISIN]
+		if !ok {
+			var created bool
+			s, created, err = h.security(ctx, st)
+			if errors.Is(err, ErrInvalidISIN) || errors.Is(err, fx.ErrInvalidCurrency) {
+				res.Skipped++
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if created {
+				res.Securities = append(res.Securities, s)
+			}
+			securities[st.ISIN] = s
+		}
+		id := s.ID
+		st.Trade.SecurityID = &id
+		trades = append(trades, st.Trade)
+	}
+	if len(trades) > 0 {
+		if res.Imported, err = h.ts.SaveTrades(ctx, trades); err != nil {
+			return nil, err
+		}
+	}
+	res.Duplicates = len(trades) - res.Imported
+	return res, nil
+}
+
+// security returns the security with the ISIN of the trade, creating it
+// when it does not exist yet.
+func (h *ImportHandler) security(ctx context.Context, st StatementTrade) (*Security, bool, error) {
+	s, err := h.ss.FetchSecurityByISIN(ctx, st.ISIN)
+	if err == nil || !errors.Is(err, ErrSecurityNotFound) {
+		return s, false, err
+	}
+	if s, err = NewSecurity(st.ISIN, "", st.Product, st.PriceCurrency); err != nil {
+		return nil, false, err
+	}
+	if err := h.ss.CreateSecurity(ctx, s); err != nil {
+		return nil, false, err
+	}
+	return s, true, nil
+}
@@ -0,0 +1,145 @@
+package investment
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/fx"
+	"github.com/lennardclaproth/my-finances-tracker/internal/marketdata"
+)
+
+var (
+	ErrSecurityNotFound = fmt.Errorf("security not found")
+	ErrInvalidISIN      = fmt.Errorf("invalid ISIN")
+	ErrInvalidAccount   = fmt.Errorf("account is required")
+	ErrInvalidRange     = fmt.Errorf("to must not be before from")
+)
+
+// Security is a share, fund or bond that can be held.
+type Security struct {
+	ID   uuid.UUID `db:"id"`
+	ISIN string    `db:"isin"`
+	// Symbol is the ticker the market prices are stored under, e.g.
+	// VWRL.AS. Securities without a symbol are valued at their last trade.
+	Symbol string `db:"symbol"`
+	Name   string `db:"name"`
+	// Currency is the currency the security is traded in, its prices, cost
+	// basis and value are in this currency.
+	Currency  string    `db:"currency"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func NewSecurity(isin, symbol, name, currency string) (*Security, error) {
+	isin = strings.ToUpper(strings.TrimSpace(isin))
+	if !ValidISIN(isin) {
+		return nil, ErrInvalidISIN
+	}
+	currency, err := fx.NormaliseCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = isin
+	}
+	now := time.Now().UTC()
+	return &Security{
+		ID:        uuid.New(),
+		ISIN:      isin,
+		Symbol:    marketdata.NormaliseSymbol(symbol),
+		Name:      name,
+		Currency:  currency,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// ValidISIN checks the format and the check digit of an ISIN.
+func ValidISIN(isin string) bool {
+	if len(isin) != 12 {
+		return false
+	}
+	// letters count as two digits, A is 10
+	var digits []int
+	for i, r := range isin {
+		switch {
+		case r >= 'A' && r <= 'Z' && i < 11:
+			v := int(r-'A') + 10
+			digits = append(digits, v/10, v%10)
+		case r >= '0' && r <= '9' && i >= 2:
+			digits = append(digits, int(r-'0'))
+		default:
+			return false
+		}
+	}
+	// Luhn, doubling every second digit from the right
+	sum := 0
+	for i := range digits {
+		d := digits[len(digits)-1-i]
+		if i%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+type TradeType string
+
+const (
+	TradeBuy      TradeType = "buy"
+	TradeSell     TradeType = "sell"
+	TradeDividend TradeType = "dividend"
+	TradeFee      TradeType = "fee"
+	TradeSplit    TradeType = "split"
+)
+
+// Trade is a change to a holding or a payment related to it.
+type Trade struct {
+	ID      uuid.UUID `db:"id"`
+	Account string    `db:"account"`
+	// SecurityID is nil for fees that are not tied to a security, such as
+	// a yearly connectivity fee.
+	SecurityID *uuid.UUID `db:"security_id"`
+	Date       time.Time  `db:"date"`
+	Type       TradeType  `db:"type"`
+	// QuantityMicros is the number of shares bought or sold in millionths.
+	QuantityMicros int64            `db:"quantity_micros"`
+	Price          marketdata.Price `db:"price"`
+	// AmountCents is the change of cash without fees, negative for buys and
+	// for withheld dividend tax.
+	AmountCents int64 `db:"amount_cents"`
+	// FeeCents are the costs of the trade.
+	FeeCents int64  `db:"fee_cents"`
+	Currency string `db:"currency"`
+	// RatioFrom and RatioTo describe a split, RatioFrom shares become
+	// RatioTo shares.
+	RatioFrom int64 `db:"ratio_from"`
+	RatioTo   int64 `db:"ratio_to"`
+	// ExternalID identifies the statement line the trade was imported from,
+	// importing the same line again is skipped.
+	ExternalID  string    `db:"external_id"`
+	Description string    `db:"description"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+// FlowCents is the money the investor put in (negative) or got out
+// (positive) with the trade.
+func (t *Trade) FlowCents() int64 {
+	if t.Type == TradeSplit {
+		return 0
+	}
+	return t.AmountCents - t.FeeCents
+}
+
+// TradeFilter narrows down listed trades, zero fields match everything.
+type TradeFilter struct {
+	SecurityID *uuid.UUID
+	Account    string
+	From, To   time.Time
+}
@@ -0,0 +1,388 @@
+package investment
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/marketdata"
+)
+
+// Single-use interfaces only used by PortfolioHandler
+
+type PriceRanger interface {
+	// Range returns the history of the symbol from from through to, oldest
+	// first.
+	Range(ctx context.Context, symbol string, from, to time.Time) ([]*marketdata.History, error)
+}
+
+// Holding is a position valued at the end of a day.
+type Holding struct {
+	Security *Security
+	Position *Position
+	Price    marketdata.Price
+	// PriceDate is the day of the price, MarketPrice is false when the
+	// holding is valued at its last trade.
+	PriceDate   time.Time
+	MarketPrice bool
+	ValueCents  int64
+}
+
+// UnrealisedCents is the value minus the cost basis of the shares held.
+func (h Holding) UnrealisedCents() int64 {
+	return h.ValueCents - h.Position.CostCents()
+}
+
+// Performance is the result of the holdings in one currency over a period.
+type Performance struct {
+	Currency        string
+	From, To        time.Time
+	StartValueCents int64
+	EndValueCents   int64
+	// NetInvestedCents is the money put in minus the money taken out by
+	// buying and selling during the period.
+	NetInvestedCents int64
+	RealisedCents    int64
+	// UnrealisedCents is the unrealised gain at the end of the period,
+	// including gains from before it.
+	UnrealisedCents int64
+	DividendCents   int64
+	FeeCents        int64
+	// TimeWeighted is the return independent of when money was added,
+	// compounded over the period. TimeWeightedAnnual is only set for
+	// periods of a year or longer.
+	TimeWeighted       float64
+	TimeWeightedAnnual *float64
+	// MoneyWeighted is the yearly internal rate of return of the money put
+	// in, nil when it has no solution.
+	MoneyWeighted *float64
+}
+
+// PortfolioHandler values holdings and reports their performance.
+type PortfolioHandler struct {
+	sl SecurityLister
+	tl TradeLister
+	pr PriceRanger
+}
+
+func NewPortfolioHandler(sl SecurityLister, tl TradeLister, pr PriceRanger) *PortfolioHandler {
+	return &PortfolioHandler{sl: sl, tl: tl, pr: pr}
+}
+
+// portfolio replays the trades and values the holdings on given days.
+type portfolio struct {
+	securities map[uuid.UUID]*Security
+	prices     map[uuid.UUID][]*marketdata.History
+	trades     []*Trade
+	ledger     *Ledger
+	next       int
+}
+
+func (h *PortfolioHandler) load(ctx context.Context, through time.Time, method Method) (*portfolio, error) {
+	securities, err := h.sl.ListSecurities(ctx)
+	if err != nil {
+		return nil, err
+	}
+	trades, err := h.tl.Trades(ctx, through)
+	if err != nil {
+		return nil, err
+	}
+	SortTrades(trades)
+	p := &portfolio{
+		securities: map[uuid.UUID]*Security{},
+		prices:     map[uuid.UUID][]*marketdata.History{},
+		trades:     trades,
+		ledger:     NewLedger(method),
+	}
+	for _, s := range securities {
+		p.securities[s.ID] = s
+	}
+	if len(trades) == 0 {
+		return p, nil
+	}
+	first := trades[0].Date
+	for _, s := range securities {
+		if s.Symbol == "" {
+			continue
+		}
+		// a week earlier so a purchase on a monday finds the friday close
+		history, err := h.pr.Range(ctx, s.Symbol, first.AddDate(0, 0, -7), through)
+		if err != nil {
+			return nil, err
+		}
+		p.prices[s.ID] = history
+	}
+	return p, nil
+}
+
+// bookThrough books the trades up to the end of the day and returns them.
+func (p *portfolio) bookThrough(date time.Time) []*Trade {
+	end := day(date).AddDate(0, 0, 1)
+	start := p.next
+	for p.next < len(p.trades) && p.trades[p.next].Date.Before(end) {
+		p.ledger.Book(p.trades[p.next])
+		p.next++
+	}
+	return p.trades[start:p.next]
+}
+
+// holdings values the positions that hold shares at the end of the day.
+func (p *portfolio) holdings(date time.Time) []Holding {
+	var res []Holding
+	for _, pos := range p.ledger.Positions() {
+		if pos.QuantityMicros() == 0 {
+			continue
+		}
+		s, ok := p.securities[pos.SecurityID]
+		if !ok {
+			continue
+		}
+		h := Holding{Security: s, Position: pos, Price: pos.LastPrice, PriceDate: pos.LastPriceDate}
+		history := p.prices[s.ID]
+		i := sort.Search(len(history), func(i int) bool { return history[i].Date.After(day(date)) })
+		if i > 0 && !history[i-1].Date.Before(day(pos.LastPriceDate)) {
+			h.Price, h.PriceDate, h.MarketPrice = history[i-1].Close, history[i-1].Date, true
+		}
+		h.ValueCents = h.Price.ValueCents(pos.QuantityMicros())
+		res = append(res, h)
+	}
+	slices.SortFunc(res, func(a, b Holding) int {
+		if c := cmp.Compare(a.Security.Currency, b.Security.Currency); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ValueCents, a.ValueCents)
+	})
+	return res
+}
+
+// currency returns the currency the trade counts in, the currency of its
+// security when it has one.
+func (p *portfolio) currency(t *Trade) string {
+	if t.SecurityID != nil {
+		if s, ok := p.securities[*t.SecurityID]; ok {
+			return s.Currency
+		}
+	}
+	return t.Currency
+}
+
+// Holdings returns the holdings at the end of the day, also those that were
+// sold entirely so their realised gains and dividends are reported.
+func (h *PortfolioHandler) Holdings(ctx context.Context, date time.Time, method Method) ([]Holding, error) {
+	p, err := h.load(ctx, date, method)
+	if err != nil {
+		return nil, err
+	}
+	p.bookThrough(date)
+	res := p.holdings(date)
+	for _, pos := range p.ledger.Positions() {
+		if s, ok := p.securities[pos.SecurityID]; ok && pos.QuantityMicros() == 0 {
+			res = append(res, Holding{Security: s, Position: pos, Price: pos.LastPrice, PriceDate: pos.LastPriceDate})
+		}
+	}
+	return res, nil
+}
+
+// totals sums what the ledger booked so far per currency.
+type totals struct {
+	realised, dividend, fee int64
+}
+
+func (p *portfolio) totals() map[string]totals {
+	res := map[string]totals{}
+	for _, pos := range p.ledger.Positions() {
+		s, ok := p.securities[pos.SecurityID]
+		if !ok {
+			continue
+		}
+		t := res[s.Currency]
+		t.realised += pos.RealisedCents
+		t.dividend += pos.DividendCents
+		t.fee += pos.FeeCents
+		res[s.Currency] = t
+	}
+	for currency, fee := range p.ledger.FeeCents {
+		t := res[currency]
+		t.fee += fee
+		res[currency] = t
+	}
+	return res
+}
+
+func valuePerCurrency(holdings []Holding) map[string]int64 {
+	res := map[string]int64{}
+	for _, h := range holdings {
+		res[h.Security.Currency] += h.ValueCents
+	}
+	return res
+}
+
+// Performance reports the result of the holdings from from through to, per
+// currency. The time-weighted return chains the returns between the days
+// with trades, valuing the holdings at the close of every such day and
+// counting purchases and sales as made at the close. The money-weighted
+// return treats the value at the start as money put in and the value at the
+// end as money taken out.
+func (h *PortfolioHandler) Performance(ctx context.Context, from, to time.Time, method Method) ([]Performance, error) {
+	from, to = day(from), day(to)
+	if to.Before(from) {
+		return nil, ErrInvalidRange
+	}
+	p, err := h.load(ctx, to, method)
+	if err != nil {
+		return nil, err
+	}
+	before := from.AddDate(0, 0, -1)
+	p.bookThrough(before)
+	startTotals := p.totals()
+	startValues := valuePerCurrency(p.holdings(before))
+
+	type state struct {
+		perf    *Performance
+		value   int64
+		growth  float64
+		flows   []flow
+		started bool
+	}
+	states := map[string]*state{}
+	get := func(currency string) *state {
+		s, ok := states[currency]
+		if !ok {
+			s = &state{perf: &Performance{Currency: currency, From: from, To: to}, growth: 1}
+			s.value = startValues[currency]
+			s.perf.StartValueCents = s.value
+			if s.value != 0 {
+				s.flows = append(s.flows, flow{date: before, cents: -s.value})
+			}
+			states[currency] = s
+		}
+		return s
+	}
+	for currency := range startValues {
+		get(currency)
+	}
+
+	var days []time.Time
+	for _, t := range p.trades[p.next:] {
+		if d := day(t.Date); len(days) == 0 || !days[len(days)-1].Equal(d) {
+			days = append(days, d)
+		}
+	}
+	if len(days) == 0 || !days[len(days)-1].Equal(to) {
+		days = append(days, to)
+	}
+	for _, d := range days {
+		// net contribution and income of the day per currency
+		contributed := map[string]int64{}
+		income := map[string]int64{}
+		for _, t := range p.bookThrough(d) {
+			c := p.currency(t)
+			s := get(c)
+			s.flows = append(s.flows, flow{date: d, cents: t.FlowCents()})
+			switch t.Type {
+			case TradeBuy, TradeSell:
+				contributed[c] -= t.FlowCents()
+			default:
+				income[c] += t.FlowCents()
+			}
+		}
+		values := valuePerCurrency(p.holdings(d))
+		for c := range values {
+			get(c)
+		}
+		for c, s := range states {
+			end := values[c]
+			if s.value > 0 {
+				s.growth *= float64(end-contributed[c]+income[c]) / float64(s.value)
+			}
+			s.perf.NetInvestedCents += contributed[c]
+			s.value = end
+		}
+	}
+
+	endTotals := p.totals()
+	holdings := p.holdings(to)
+	res := make([]Performance, 0, len(states))
+	for c, s := range states {
+		perf := s.perf
+		perf.EndValueCents = s.value
+		perf.RealisedCents = endTotals[c].realised - startTotals[c].realised
+		perf.DividendCents = endTotals[c].dividend - startTotals[c].dividend
+		perf.FeeCents = endTotals[c].fee - startTotals[c].fee
+		for _, h := range holdings {
+			if h.Security.Currency == c {
+				perf.UnrealisedCents += h.UnrealisedCents()
+			}
+		}
+		perf.TimeWeighted = s.growth - 1
+		if years := to.Sub(before).Hours() / 24 / 365; years >= 1 && s.growth > 0 {
+			annual := math.Pow(s.growth, 1/years) - 1
+			perf.TimeWeightedAnnual = &annual
+		}
+		flows := s.flows
+		if s.value != 0 {
+			flows = append(flows, flow{date: to, cents: s.value})
+		}
+		if rate, ok := xirr(flows); ok {
+			perf.MoneyWeighted = &rate
+		}
+		res = append(res, *perf)
+	}
+	slices.SortFunc(res, func(a, b Performance) int { return cmp.Compare(a.Currency, b.Currency) })
+	return res, nil
+}
+
+// flow is money put in (negative) or taken out (positive) on a day.
+type flow struct {
+	date  time.Time
+	cents int64
+}
+
+// xirr returns the yearly rate at which the flows have a net present value
+// of zero, false when there is none because the flows do not change sign.
+func xirr(flows []flow) (float64, bool) {
+	var in, out bool
+	for _, f := range flows {
+		in = in || f.cents < 0
+		out = out || f.cents > 0
+	}
+	if !in || !out {
+		return 0, false
+	}
+	npv := func(rate float64) float64 {
+		var v float64
+		for _, f := range flows {
+			years := f.date.Sub(flows[0].date).Hours() / 24 / 365
+			v += float64(f.cents) / math.Pow(1+rate, years)
+		}
+		return v
+	}
+	// the value falls as the rate rises when money goes in first, bisect
+	// between a total loss and a hundredfold gain per year
+	lo, hi := -0.9999, 100.0
+	flo, fhi := npv(lo), npv(hi)
+	if math.IsNaN(flo) || math.IsNaN(fhi) || (flo > 0) == (fhi > 0) {
+		return 0, false
+	}
+	for range 200 {
+		mid := (lo + hi) / 2
+		fmid := npv(mid)
+		if (fmid > 0) == (flo > 0) {
+			lo, flo = mid, fmid
+		} else {
+			hi = mid
+		}
+		if hi-lo < 1e-9 {
+			break
+		}
+	}
+	return (lo + hi) / 2, true
+}
+
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,278 @@
+package investment
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/marketdata"
+)
+
+type fakeBook struct {
+	securities []*Security
+	trades     []*Trade
+	prices     map[string][]*marketdata.History
+}
+
+func (f *fakeBook) ListSecurities(ctx context.Context) ([]*Security, error) {
+	return f.securities, nil
+}
+
+func (f *fakeBook) Trades(ctx context.Context, through time.Time) ([]*Trade, error) {
+	var res []*Trade
+	for _, t := range f.trades {
+		if t.Date.Before(day(through).AddDate(0, 0, 1)) {
+			c := *t
+			res = append(res, &c)
+		}
+	}
+	return res, nil
+}
+
+func (f *fakeBook) Range(ctx context.Context, symbol string, from, to time.Time) ([]*marketdata.History, error) {
+	var res []*marketdata.History
+	for _, h := range f.prices[symbol] {
+		if !h.Date.Before(from) && !h.Date.After(to) {
+			res = append(res, h)
+		}
+	}
+	return res, nil
+}
+
+func closes(symbol string, prices map[string]int64) []*marketdata.History {
+	var res []*marketdata.History
+	for _, d := range []string{"2023-01-02", "2023-07-03", "2024-01-02"} {
+		if p, ok := prices[d]; ok {
+			price := marketdata.Price(p * 1_000_000)
+			res = append(res, marketdata.NewHistory(symbol, tradeDate(d), price, price, price, price, 0))
+		}
+	}
+	return res
+}
+
+// samplePortfolio holds a fund in euro that rises 10% in each half of 2023
+// with a second purchase halfway, and a share in dollars without market
+// prices that pays a dividend and is sold at a gain.
+func samplePortfolio() *fakeBook {
+	fund := &Security{ID: vwrl, ISIN: "IE00B3RBWM25", Symbol: "VWRL.AS", Currency: "EUR"}
+	share := &Security{ID: nvda, ISIN: "US67066G1040", Currency: "USD"}
+	dividend := trade("2023-06-01", nvda, TradeDividend, 0, 0, 0)
+	dividend.AmountCents = 1000
+	return &fakeBook{
+		securities: []*Security{fund, share},
+		trades: []*Trade{
+			trade("2023-01-02", vwrl, TradeBuy, 10, 100, 0),
+			trade("2023-07-03", vwrl, TradeBuy, 10, 110, 0),
+			trade("2023-01-02", nvda, TradeBuy, 10, 50, 100),
+			dividend,
+			trade("2023-12-01", nvda, TradeSell, 10, 60, 100),
+		},
+		prices: map[string][]*marketdata.History{
+			"VWRL.AS": closes("VWRL.AS", map[string]int64{"2023-01-02": 100, "2023-07-03": 110, "2024-01-02": 121}),
+		},
+	}
+}
+
+func TestPerformance(t *testing.T) {
+	annual := func(growth float64, days int) *float64 {
+		v := math.Pow(growth, 365/float64(days)) - 1
+		return &v
+	}
+	rate := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name     string
+		from, to string
+		want     []Performance
+	}{
+		{
+			// EUR: 1000.00 in, +10% to 1100.00, 1100.00 more in, +10% to
+			// 2420.00, the time-weighted return is 1.1 * 1.1 - 1.
+			// USD: 501.00 in, 10.00 dividend on 500.00 (+2%), sold for
+			// 599.00 (+19.8%), 1.02 * 1.198 - 1.
+			name: "whole year",
+			from: "2023-01-02",
+			to:   "2024-01-02",
+			want: []Performance{
+				{
+					Currency:           "EUR",
+					EndValueCents:      242000,
+					NetInvestedCents:   210000,
+					UnrealisedCents:    32000,
+					TimeWeighted:       0.21,
+					TimeWeightedAnnual: annual(1.21, 366),
+					MoneyWeighted:      rate(0.2097895),
+				},
+				{
+					Currency:           "USD",
+					NetInvestedCents:   50100 - 59900,
+					RealisedCents:      59900 - 50100,
+					DividendCents:      1000,
+					FeeCents:           200,
+					TimeWeighted:       1.02*1.198 - 1,
+					TimeWeightedAnnual: annual(1.02*1.198, 366),
+					MoneyWeighted:      rate(0.2411374),
+				},
+			},
+		},
+		{
+			// the fund held at the start is valued at the January close and
+			// counts as money put in the day before
+			name: "second half",
+			from: "2023-07-03",
+			to:   "2024-01-02",
+			want: []Performance{
+				{
+					Currency:         "EUR",
+					StartValueCents:  100000,
+					EndValueCents:    242000,
+					NetInvestedCents: 110000,
+					UnrealisedCents:  32000,
+					TimeWeighted:     0.21,
+					MoneyWeighted:    rate(0.3259790),
+				},
+				{
+					Currency:         "USD",
+					StartValueCents:  50000,
+					NetInvestedCents: -59900,
+					RealisedCents:    59900 - 50100,
+					FeeCents:         100,
+					TimeWeighted:     0.198,
+					MoneyWeighted:    rate(math.Pow(1.198, 365/152.0) - 1),
+				},
+			},
+		},
+		{
+			// nothing traded and no price change, the money-weighted
+			// return of getting back what was put in is zero
+			name: "quiet period",
+			from: "2023-03-01",
+			to:   "2023-03-31",
+			want: []Performance{
+				{Currency: "EUR", StartValueCents: 100000, EndValueCents: 100000, MoneyWeighted: rate(0)},
+				{Currency: "USD", StartValueCents: 50000, EndValueCents: 50000, UnrealisedCents: -100, MoneyWeighted: rate(0)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := samplePortfolio()
+			h := NewPortfolioHandler(book, book, book)
+			got, err := h.Performance(context.Background(), tradeDate(tt.from), tradeDate(tt.to), MethodFIFO)
+			if err != nil {
+				t.Fatalf("Performance() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Performance() = %d currencies, want %d", len(got), len(tt.want))
+			}
+			for i, want := range tt.want {
+				p := got[i]
+				if p.Currency != want.Currency || p.StartValueCents != want.StartValueCents || p.EndValueCents != want.EndValueCents ||
+					p.NetInvestedCents != want.NetInvestedCents || p.RealisedCents != want.RealisedCents ||
+					p.UnrealisedCents != want.UnrealisedCents || p.DividendCents != want.DividendCents || p.FeeCents != want.FeeCents {
+					t.Errorf("Performance() = %+v, want %+v", p, want)
+				}
+				if math.Abs(p.TimeWeighted-want.TimeWeighted) > 1e-9 {
+					t.Errorf("%s TimeWeighted = %f, want %f", p.Currency, p.TimeWeighted, want.TimeWeighted)
+				}
+				if !approx(p.TimeWeightedAnnual, want.TimeWeightedAnnual, 1e-9) {
+					t.Errorf("%s TimeWeightedAnnual = %v, want %v", p.Currency, deref(p.TimeWeightedAnnual), deref(want.TimeWeightedAnnual))
+				}
+				if !approx(p.MoneyWeighted, want.MoneyWeighted, 1e-6) {
+					t.Errorf("%s MoneyWeighted = %v, want %v", p.Currency, deref(p.MoneyWeighted), deref(want.MoneyWeighted))
+				}
+			}
+		})
+	}
+}
+
+func TestPerformanceInvalidRange(t *testing.T) {
+	book := samplePortfolio()
+	h := NewPortfolioHandler(book, book, book)
+	if _, err := h.Performance(context.Background(), tradeDate("2024-01-02"), tradeDate("2023-01-02"), MethodFIFO); !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("Performance() error = %v, want ErrInvalidRange", err)
+	}
+}
+
+func TestHoldings(t *testing.T) {
+	book := samplePortfolio()
+	// half the fund is sold in the autumn at the average cost, a gain
+	// against the first purchase with FIFO
+	book.trades = append(book.trades, trade("2023-10-02", vwrl, TradeSell, 10, 105, 0))
+	tests := []struct {
+		method     Method
+		realised   int64
+		unrealised int64
+	}{
+		{MethodFIFO, 105000 - 100000, 121000 - 110000},
+		{MethodAverage, 105000 - 105000, 121000 - 105000},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			h := NewPortfolioHandler(book, book, book)
+			got, err := h.Holdings(context.Background(), tradeDate("2024-01-02"), tt.method)
+			if err != nil {
+				t.Fatalf("Holdings() error = %v", err)
+			}
+			if len(got) != 2 {
+				t.Fatalf("Holdings() = %d holdings, want the fund and the sold share", len(got))
+			}
+			fund, share := got[0], got[1]
+			if fund.Security.ID != vwrl || !fund.MarketPrice || fund.ValueCents != 121000 || !fund.PriceDate.Equal(tradeDate("2024-01-02")) {
+				t.Errorf("fund = %+v", fund)
+			}
+			if fund.Position.RealisedCents != tt.realised {
+				t.Errorf("fund RealisedCents = %d, want %d", fund.Position.RealisedCents, tt.realised)
+			}
+			if fund.UnrealisedCents() != tt.unrealised {
+				t.Errorf("fund UnrealisedCents() = %d, want %d", fund.UnrealisedCents(), tt.unrealised)
+			}
+			// sold entirely, valued at its last trade
+			if share.Security.ID != nvda || share.MarketPrice || share.ValueCents != 0 || share.Price != marketdata.Price(60_000_000) {
+				t.Errorf("share = %+v", share)
+			}
+		})
+	}
+}
+
+func TestXIRR(t *testing.T) {
+	on := func(days int) time.Time { return tradeDate("2023-01-01").AddDate(0, 0, days) }
+	tests := []struct {
+		name  string
+		flows []flow
+		want  float64
+		ok    bool
+	}{
+		{"ten percent in a year", []flow{{on(0), -1000}, {on(365), 1100}}, 0.1, true},
+		{"ten percent a year over two years", []flow{{on(0), -1000}, {on(730), 1210}}, 0.1, true},
+		{"loss", []flow{{on(0), -1000}, {on(365), 800}}, -0.2, true},
+		// the second payment grows by 10% as well
+		{"second payment", []flow{{on(0), -1000}, {on(365), -1100}, {on(730), 2420}}, 0.1, true},
+		{"only money in", []flow{{on(0), -1000}, {on(365), -100}}, 0, false},
+		{"only money out", []flow{{on(0), 1000}}, 0, false},
+		{"no flows", nil, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := xirr(tt.flows)
+			if ok != tt.ok || math.Abs(got-tt.want) > 1e-6 {
+				t.Errorf("xirr() = %f, %v, want %f, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func approx(got, want *float64, tolerance float64) bool {
+	if got == nil || want == nil {
+		return got == want
+	}
+	return math.Abs(*got-*want) <= tolerance
+}
+
+func deref(f *float64) any {
+	if f == nil {
+		return nil
+	}
+	return *f
+}
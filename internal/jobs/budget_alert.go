@@ -0,0 +1,72 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/budget"
+	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
+	"go.elastic.co/apm/v2"
+)
+
+// BudgetAlertJob evaluates the budgets whenever transactions were imported or
+// changed, e.g. tagged, since the last run and sends alerts for the
+// thresholds reached.
+type BudgetAlertJob struct {
+	alerts *budget.AlertHandler
+	ts     *storage.SQLXTransactionStore
+	df     time.Duration
+	log    logging.Logger
+	seen   time.Time
+}
+
+func NewBudgetAlertJob(alerts *budget.AlertHandler, ts *storage.SQLXTransactionStore, df time.Duration, log logging.Logger) *BudgetAlertJob {
+	if df <= 0 {
+		df = time.Minute
+	}
+	return &BudgetAlertJob{alerts: alerts, ts: ts, df: df, log: log}
+}
+
+func (j *BudgetAlertJob) Name() string {
+	return "BudgetAlertJob"
+}
+
+func (j *BudgetAlertJob) Start(ctx context.Context) error {
+	ticker := time.NewTicker(j.df)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			j.evaluate(ctx)
+		}
+	}
+}
+
+func (j *BudgetAlertJob) evaluate(ctx context.Context) {
+	last, err := j.ts.LastUpdatedAt(ctx)
+	if err != nil {
+		j.log.Error(ctx, "failed to check for changed transactions", err)
+		return
+	}
+	if !last.After(j.seen) {
+		return
+	}
+	tx := apm.DefaultTracer().StartTransaction("BudgetAlertJob.evaluate", "job")
+	defer tx.End()
+	ctx = apm.ContextWithTransaction(ctx, tx)
+	sent, err := j.alerts.Handle(ctx, time.Now())
+	if sent > 0 {
+		j.log.Info(ctx, "sent budget alerts", "count", sent)
+	}
+	if err != nil {
+		// the budgets are evaluated again on the next tick, alerts that were
+		// sent are not sent twice
+		j.log.Error(ctx, "failed to evaluate budgets", err)
+		return
+	}
+	j.seen = last
+}
@@ -0,0 +1,53 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/classifier"
+	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+)
+
+// ClassifierJob periodically retrains the offline classifier on the tagged
+// transactions so manual corrections and agent results are picked up.
+type ClassifierJob struct {
+	cs  *classifier.Service
+	df  time.Duration
+	log logging.Logger
+}
+
+func NewClassifierJob(cs *classifier.Service, df time.Duration, log logging.Logger) *ClassifierJob {
+	if df <= 0 {
+		df = 10 * time.Minute
+	}
+	return &ClassifierJob{cs: cs, df: df, log: log}
+}
+
+func (j *ClassifierJob) Name() string {
+	return "ClassifierJob"
+}
+
+func (j *ClassifierJob) Start(ctx context.Context) error {
+	j.retrain(ctx)
+
+	ticker := time.NewTicker(j.df)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			j.retrain(ctx)
+		}
+	}
+}
+
+func (j *ClassifierJob) retrain(ctx context.Context) {
+	n, err := j.cs.Retrain(ctx)
+	if err != nil {
+		j.log.Error(ctx, "failed to retrain classifier", err)
+		return
+	}
+	j.log.Info(ctx, "retrained classifier", "samples", n)
+}
@@ -0,0 +1,63 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/fx"
+	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+	"go.elastic.co/apm/v2"
+)
+
+// FXRateJob imports the ECB reference rate files dropped in the rates folder.
+// It scans the folder every df, so a downloaded eurofxref file is picked up
+// without uploading it.
+type FXRateJob struct {
+	scan *fx.ScanHandler
+	df   time.Duration
+	log  logging.Logger
+}
+
+func NewFXRateJob(scan *fx.ScanHandler, df time.Duration, log logging.Logger) *FXRateJob {
+	if df <= 0 {
+		df = time.Hour
+	}
+	return &FXRateJob{scan: scan, df: df, log: log}
+}
+
+func (j *FXRateJob) Name() string {
+	return "FXRateJob"
+}
+
+func (j *FXRateJob) Start(ctx context.Context) error {
+	j.run(ctx)
+
+	ticker := time.NewTicker(j.df)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			j.run(ctx)
+		}
+	}
+}
+
+func (j *FXRateJob) run(ctx context.Context) {
+	tx := apm.DefaultTracer().StartTransaction("FXRateJob.run", "job")
+	defer tx.End()
+	ctx = apm.ContextWithTransaction(ctx, tx)
+	imported, failed, err := j.scan.Handle(ctx)
+	if err != nil {
+		j.log.Error(ctx, "failed to scan for exchange rate files", err)
+		return
+	}
+	for file, err := range failed {
+		j.log.Error(ctx, "failed to import exchange rates", err, "file", file)
+	}
+	for file, res := range imported {
+		j.log.Info(ctx, "imported exchange rates", "file", file, "rates", res.Rates, "currencies", len(res.Currencies))
+	}
+}
@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/lennardclaproth/my-finances-tracker/internal/counterparty"
 	"github.com/lennardclaproth/my-finances-tracker/internal/importer"
 	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
 	"github.com/lennardclaproth/my-finances-tracker/internal/parser"
+	"github.com/lennardclaproth/my-finances-tracker/internal/refund"
+	"github.com/lennardclaproth/my-finances-tracker/internal/rule"
 	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
 	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
 	"go.elastic.co/apm/v2"
@@ -19,7 +22,10 @@ type ImportJob struct {
 	vendorStore      *storage.SQLXVendorStore
 	importStore      *storage.SQLXImportStore
 	transactionStore *storage.SQLXTransactionStore
+	ruleStore        *storage.SQLXRuleStore
 	dh               *storage.Disk
+	counterparties   *counterparty.AssignHandler
+	refunds          *refund.DetectHandler
 	log              logging.Logger
 	interval         time.Duration
 }
@@ -28,7 +34,10 @@ func NewImportJob(
 	vendorStore *storage.SQLXVendorStore,
 	importStore *storage.SQLXImportStore,
 	transactionStore *storage.SQLXTransactionStore,
+	ruleStore *storage.SQLXRuleStore,
 	dh *storage.Disk,
+	counterparties *counterparty.AssignHandler,
+	refunds *refund.DetectHandler,
 	log logging.Logger,
 	interval time.Duration,
 ) *ImportJob {
@@ -36,7 +45,10 @@ func NewImportJob(
 		vendorStore:      vendorStore,
 		importStore:      importStore,
 		transactionStore: transactionStore,
+		ruleStore:        ruleStore,
 		dh:               dh,
+		counterparties:   counterparties,
+		refunds:          refunds,
 		log:              log,
 		interval:         interval,
 	}
@@ -94,15 +106,36 @@ func (j *ImportJob) process(ctx context.Context) error {
 	}
 	// maybe bad?
 	defer rc.Close()
+	// Rules are loaded once per import so every row is classified by the same
+	// set of rules, before anything reaches the tagger.
+	engine, err := rule.LoadEngine(ctx, j.ruleStore)
+	if err != nil {
+		j.handleError(ctx, imp, err)
+		return err
+	}
 	// txs := []*transaction.Transaction{}
 	for i, txd := range txds {
-		tx, err := transaction.NewTransaction(txd.Description, txd.Note, string(v.Name), transaction.CashFlowDirection(txd.Direction), txd.Amount, txd.Date, i, imp.ID)
+		txd.Source = string(v.Name)
+		tx, err := transaction.NewTransactionFromData(txd, i, imp.ID)
 		if err != nil {
 			j.handleError(ctx, imp, err)
 			return err
 		}
-		// txs = append(txs, tx)
-		if err := j.transactionStore.Create(ctx, tx); err != nil {
+		if err := j.create(ctx, engine, tx); err != nil {
+			j.handleError(ctx, imp, err)
+			return err
+		}
+		if txd.Fee.Cents == 0 {
+			continue
+		}
+		// Fees are booked as their own transaction so they can be tagged
+		// apart from what they were charged for.
+		fee, err := tx.NewFee(txd.Fee)
+		if err != nil {
+			j.handleError(ctx, imp, err)
+			return err
+		}
+		if err := j.create(ctx, engine, fee); err != nil {
 			j.handleError(ctx, imp, err)
 			return err
 		}
@@ -111,7 +144,27 @@ func (j *ImportJob) process(ctx context.Context) error {
 	if err := j.importStore.UpdateState(ctx, imp); err != nil {
 		j.log.Error(ctx, "Error marking import with id %s as completed: %v", err, imp.ID)
 	}
-	return err
+	// Refund detection is best effort, a failure should not fail the import
+	// that has already been completed.
+	suggested, err := j.refunds.Handle(ctx, imp.ID)
+	if err != nil {
+		j.log.Error(ctx, "Error detecting refunds for import with id %s: %v", err, imp.ID)
+		return nil
+	}
+	if suggested > 0 {
+		j.log.Info(ctx, "suggested refund links", "import_id", imp.ID, "count", suggested)
+	}
+	return nil
+}
+
+// create resolves the counterparty of the transaction, applies the rules and
+// stores it.
+func (j *ImportJob) create(ctx context.Context, engine *rule.Engine, tx *transaction.Transaction) error {
+	if err := j.counterparties.Handle(ctx, tx); err != nil {
+		return err
+	}
+	engine.Apply(tx)
+	return j.transactionStore.Create(ctx, tx)
 }
 
 func (j *ImportJob) handleError(ctx context.Context, imp *importer.Import, err error) {
@@ -0,0 +1,90 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+	"github.com/lennardclaproth/my-finances-tracker/internal/marketdata"
+	"go.elastic.co/apm/v2"
+)
+
+// MarketDataJob keeps the prices of the tracked symbols current. It
+// backfills missing prices when it starts and refreshes them every day at
+// a time after the markets closed. When the provider is rate limited the
+// refresh is retried once the provider allows it instead of the next day.
+type MarketDataJob struct {
+	refresh *marketdata.RefreshHandler
+	at      time.Duration
+	log     logging.Logger
+}
+
+// NewMarketDataJob creates a MarketDataJob that refreshes at the given time
+// of day in UTC, 22:00 when at is not within a day.
+func NewMarketDataJob(refresh *marketdata.RefreshHandler, at time.Duration, log logging.Logger) *MarketDataJob {
+	if at < 0 || at >= 24*time.Hour {
+		at = 22 * time.Hour
+	}
+	return &MarketDataJob{refresh: refresh, at: at, log: log}
+}
+
+func (j *MarketDataJob) Name() string {
+	return "MarketDataJob"
+}
+
+func (j *MarketDataJob) Start(ctx context.Context) error {
+	retry := j.run(ctx)
+	for {
+		wait := retry
+		if wait <= 0 {
+			wait = time.Until(j.next(time.Now().UTC()))
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+			retry = j.run(ctx)
+		}
+	}
+}
+
+// next returns the first refresh time after now.
+func (j *MarketDataJob) next(now time.Time) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).Add(j.at)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// run refreshes the prices and returns how long to wait before retrying, zero
+// when the next refresh is the scheduled one.
+func (j *MarketDataJob) run(ctx context.Context) time.Duration {
+	tx := apm.DefaultTracer().StartTransaction("MarketDataJob.run", "job")
+	defer tx.End()
+	ctx = apm.ContextWithTransaction(ctx, tx)
+	res, err := j.refresh.Handle(ctx, time.Now().UTC())
+	for symbol, err := range res.Failed {
+		j.log.Error(ctx, "failed to refresh market data", err, "symbol", symbol)
+	}
+	var limited *marketdata.RateLimitError
+	if errors.As(err, &limited) {
+		retry := max(limited.RetryAfter, time.Minute)
+		if limited.RetryAfter == 0 {
+			retry = 15 * time.Minute
+		}
+		j.log.Info(ctx, "market data provider is rate limited", "retry_after", retry, "days", res.Days)
+		return retry
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			j.log.Error(ctx, "failed to refresh market data", err)
+		}
+		return 0
+	}
+	j.log.Info(ctx, "refreshed market data", "symbols", res.Symbols, "days", res.Days)
+	return 0
+}
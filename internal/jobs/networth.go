@@ -0,0 +1,61 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+	"github.com/lennardclaproth/my-finances-tracker/internal/networth"
+	"go.elastic.co/apm/v2"
+)
+
+// NetWorthSnapshotJob materialises the net worth at the end of every month.
+// It checks every df whether the last month ended, so a month is snapshotted
+// shortly after it ends and missed months are filled in provisionally after
+// downtime.
+type NetWorthSnapshotJob struct {
+	snapshots *networth.SnapshotHandler
+	df        time.Duration
+	log       logging.Logger
+}
+
+func NewNetWorthSnapshotJob(snapshots *networth.SnapshotHandler, df time.Duration, log logging.Logger) *NetWorthSnapshotJob {
+	if df <= 0 {
+		df = 24 * time.Hour
+	}
+	return &NetWorthSnapshotJob{snapshots: snapshots, df: df, log: log}
+}
+
+func (j *NetWorthSnapshotJob) Name() string {
+	return "NetWorthSnapshotJob"
+}
+
+func (j *NetWorthSnapshotJob) Start(ctx context.Context) error {
+	j.snapshot(ctx)
+
+	ticker := time.NewTicker(j.df)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			j.snapshot(ctx)
+		}
+	}
+}
+
+func (j *NetWorthSnapshotJob) snapshot(ctx context.Context) {
+	tx := apm.DefaultTracer().StartTransaction("NetWorthSnapshotJob.snapshot", "job")
+	defer tx.End()
+	ctx = apm.ContextWithTransaction(ctx, tx)
+	n, err := j.snapshots.Handle(ctx, time.Now().UTC())
+	if err != nil {
+		j.log.Error(ctx, "failed to snapshot net worth", err)
+		return
+	}
+	if n > 0 {
+		j.log.Info(ctx, "snapshotted net worth", "months", n)
+	}
+}
@@ -0,0 +1,58 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+	"github.com/lennardclaproth/my-finances-tracker/internal/recurring"
+	"go.elastic.co/apm/v2"
+)
+
+// RecurringJob periodically detects recurring payments and income, so
+// expected dates and missed payments follow the calendar even without new
+// imports.
+type RecurringJob struct {
+	detector *recurring.DetectHandler
+	df       time.Duration
+	log      logging.Logger
+}
+
+func NewRecurringJob(detector *recurring.DetectHandler, df time.Duration, log logging.Logger) *RecurringJob {
+	if df <= 0 {
+		df = 6 * time.Hour
+	}
+	return &RecurringJob{detector: detector, df: df, log: log}
+}
+
+func (j *RecurringJob) Name() string {
+	return "RecurringJob"
+}
+
+func (j *RecurringJob) Start(ctx context.Context) error {
+	j.detect(ctx)
+
+	ticker := time.NewTicker(j.df)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			j.detect(ctx)
+		}
+	}
+}
+
+func (j *RecurringJob) detect(ctx context.Context) {
+	tx := apm.DefaultTracer().StartTransaction("RecurringJob.detect", "job")
+	defer tx.End()
+	ctx = apm.ContextWithTransaction(ctx, tx)
+	n, err := j.detector.Handle(ctx, time.Now())
+	if err != nil {
+		j.log.Error(ctx, "failed to detect recurring transactions", err)
+		return
+	}
+	j.log.Info(ctx, "detected recurring transactions", "series", n)
+}
@@ -2,26 +2,76 @@ package jobs
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/lennardclaproth/my-finances-tracker/internal/agent"
+	"github.com/lennardclaproth/my-finances-tracker/internal/category"
 	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
-	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
+	"github.com/lennardclaproth/my-finances-tracker/internal/tagging"
 	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
 	"go.elastic.co/apm/v2"
+	"golang.org/x/sync/errgroup"
 )
 
-// TaggerJob is responsible for automatically tagging transactions based on predefined rules.
+// TaggerJob is responsible for automatically tagging transactions with the configured tagger.
+// Each worker claims a batch of untagged transactions, so several workers or replicas never
+// tag the same rows, and tags the batch with a single request to the tagger. Transactions
+// the tagger failed on are left untouched and their claims released, they are tried again
+// after the worker backed off. While the agent circuit breaker is open the workers pause.
 // when there are no untagged transactions, it should sleep with exponential backoff until new transactions are imported.
 type TaggerJob struct {
-	ar  *agent.Runner
-	ts  *storage.SQLXTransactionStore
-	df  time.Duration
-	log logging.Logger
+	tagger      tagging.Tagger
+	breaker     *agent.Breaker
+	ts          taggingStore
+	cats        categoryLister
+	batchSize   int
+	concurrency int
+	lease       time.Duration
+	retryAfter  time.Duration
+	df          time.Duration
+	log         logging.Logger
 }
 
-func NewTaggerJob(ar *agent.Runner, ts *storage.SQLXTransactionStore, df time.Duration, log logging.Logger) *TaggerJob {
-	return &TaggerJob{ar: ar, ts: ts, df: df, log: log}
+// taggingStore claims untagged transactions and stores their tags,
+// implemented by storage.SQLXTransactionStore.
+type taggingStore interface {
+	ClaimUntagged(ctx context.Context, n int, lease, retryAfter time.Duration) ([]*transaction.Transaction, error)
+	Tag(ctx context.Context, id uuid.UUID, tag string, p transaction.TagProvenance) error
+	ReleaseClaims(ctx context.Context, ids []uuid.UUID) error
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// categoryLister lists the categories to tag with, implemented by
+// storage.SQLXCategoryStore.
+type categoryLister interface {
+	List(ctx context.Context) ([]*category.Category, error)
+}
+
+func NewTaggerJob(tagger tagging.Tagger, breaker *agent.Breaker, ts taggingStore, cats categoryLister, batchSize, concurrency int, lease, retryAfter, df time.Duration, log logging.Logger) *TaggerJob {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if lease <= 0 {
+		lease = 10 * time.Minute
+	}
+	return &TaggerJob{
+		tagger:      tagger,
+		breaker:     breaker,
+		ts:          ts,
+		cats:        cats,
+		batchSize:   batchSize,
+		concurrency: concurrency,
+		lease:       lease,
+		retryAfter:  retryAfter,
+		df:          df,
+		log:         log,
+	}
 }
 
 func (j *TaggerJob) Name() string {
@@ -29,6 +79,16 @@ func (j *TaggerJob) Name() string {
 }
 
 func (j *TaggerJob) Start(ctx context.Context) error {
+	g, ctx := errgroup.WithContext(ctx)
+	for range j.concurrency {
+		g.Go(func() error {
+			return j.work(ctx)
+		})
+	}
+	return g.Wait()
+}
+
+func (j *TaggerJob) work(ctx context.Context) error {
 	interval := j.df
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
@@ -38,42 +98,97 @@ func (j *TaggerJob) Start(ctx context.Context) error {
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-ticker.C:
-			untagged, err := j.ts.FetchUntagged(ctx, 1, 1)
+			if j.breaker != nil && j.breaker.Open() {
+				j.log.Info(ctx, "agent circuit breaker is open, pausing tagging")
+				interval = j.backoff(interval, ticker)
+				continue
+			}
+			untagged, err := j.ts.ClaimUntagged(ctx, j.batchSize, j.lease, j.retryAfter)
 			if err != nil {
-				j.log.Error(ctx, "failed to fetch untagged transactions", err)
+				j.log.Error(ctx, "failed to claim untagged transactions", err)
 			}
 			if len(untagged) == 0 {
 				j.log.Info(ctx, "no untagged transactions found, increasing interval with exponential backoff")
 				// No untagged transactions, increase the interval with exponential backoff
-				interval *= 2
-				if interval > time.Minute {
-					interval = time.Minute
-				}
-				ticker.Reset(interval)
+				interval = j.backoff(interval, ticker)
+				continue
+			}
+			if err := j.process(ctx, untagged); err != nil {
+				// back off instead of failing batch after batch in a tight loop
+				j.log.Error(ctx, "failed to process tagging batch", err, "transactions", len(untagged))
+				interval = j.backoff(interval, ticker)
 				continue
 			}
 			interval = j.df // reset interval to default when we find untagged transactions
 			ticker.Reset(interval)
-			tx := untagged[0]
-			if err := j.process(ctx, tx); err != nil {
-				// If tagging fails, log the error and tag the transaction as "unk" to avoid blocking the queue.
-				j.log.Error(ctx, "failed to process tagging for transaction %d: %v", err, tx.ID)
-				j.ts.Tag(ctx, tx.ID, "unk")
-			}
 		}
 	}
 }
 
-func (j *TaggerJob) process(ctx context.Context, tx *transaction.Transaction) error {
+// backoff doubles the interval up to a minute and resets the ticker to it.
+func (j *TaggerJob) backoff(interval time.Duration, ticker *time.Ticker) time.Duration {
+	interval *= 2
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+	ticker.Reset(interval)
+	return interval
+}
+
+func (j *TaggerJob) process(ctx context.Context, txs []*transaction.Transaction) error {
 	apmTx := apm.DefaultTracer().StartTransaction("TaggerJob.process", "job")
 	defer apmTx.End()
 
 	ctx = apm.ContextWithTransaction(ctx, apmTx)
 
-	span, ctx := apm.StartSpan(ctx, "RunTagAgent", "app")
-	defer span.End()
+	categories, err := j.cats.List(ctx)
+	if err != nil {
+		return err
+	}
+	slugs := make([]string, 0, len(categories))
+	for _, c := range categories {
+		slugs = append(slugs, c.Slug)
+	}
 
-	err := j.ar.RunTagAgent(ctx, tx)
+	ids := make([]uuid.UUID, 0, len(txs))
+	for _, tx := range txs {
+		ids = append(ids, tx.ID)
+	}
+
+	span, spanCtx := apm.StartSpan(ctx, "TagBatch", "app")
+	results, err := tagging.TagAll(spanCtx, j.tagger, txs, slugs)
+	span.End()
+	if err != nil {
+		apmTx.Result = "error"
+		apm.CaptureError(ctx, err).Send()
+		return errors.Join(err, j.ts.ReleaseClaims(ctx, ids))
+	}
+
+	var failed []error
+	err = j.ts.WithTx(ctx, func(ctx context.Context) error {
+		failed = nil
+		for i, tx := range txs {
+			res := results[i]
+			if res.Err != nil {
+				// the tagger did not answer, leave the transaction as it is
+				failed = append(failed, res.Err)
+				continue
+			}
+			if res.Tag == "" {
+				// no tagger had a guess, this is an answer and not a failure
+				res = tagging.Result{Tag: category.Uncategorised, Source: transaction.TagSourceAgent}
+			} else {
+				j.log.Info(ctx, "tagged transaction", "transaction", tx.ID, "tag", res.Tag, "tagger", res.Tagger, "confidence", res.Confidence, "rationale", res.Rationale)
+			}
+			if err := j.ts.Tag(ctx, tx.ID, res.Tag, res.Provenance()); err != nil {
+				return err
+			}
+		}
+		return j.ts.ReleaseClaims(ctx, ids)
+	})
+	if err == nil && len(failed) > 0 {
+		err = fmt.Errorf("tagging failed for %d of %d transactions: %w", len(failed), len(txs), errors.Join(failed...))
+	}
 	if err != nil {
 		apmTx.Result = "error"
 		apm.CaptureError(ctx, err).Send()
@@ -0,0 +1,170 @@
+package jobs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/agent"
+	"github.com/lennardclaproth/my-finances-tracker/internal/category"
+	"github.com/lennardclaproth/my-finances-tracker/internal/classifier"
+	"github.com/lennardclaproth/my-finances-tracker/internal/counterparty"
+	"github.com/lennardclaproth/my-finances-tracker/internal/rule"
+	"github.com/lennardclaproth/my-finances-tracker/internal/tagging"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(ctx context.Context, msg string, fields ...any)             {}
+func (nopLogger) Error(ctx context.Context, msg string, err error, fields ...any) {}
+
+// fakeStore records the tags written and the claims released.
+type fakeStore struct {
+	tags     map[uuid.UUID]string
+	released []uuid.UUID
+}
+
+func (s *fakeStore) ClaimUntagged(ctx context.Context, n int, lease, retryAfter time.Duration) ([]*transaction.Transaction, error) {
+	return nil, nil
+}
+
+func (s *fakeStore) Tag(ctx context.Context, id uuid.UUID, tag string, p transaction.TagProvenance) error {
+	s.tags[id] = tag
+	return nil
+}
+
+func (s *fakeStore) ReleaseClaims(ctx context.Context, ids []uuid.UUID) error {
+	s.released = append(s.released, ids...)
+	return nil
+}
+
+func (s *fakeStore) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type fakeCategories []string
+
+func (f fakeCategories) List(ctx context.Context) ([]*category.Category, error) {
+	var res []*category.Category
+	for _, slug := range f {
+		res = append(res, &category.Category{Slug: slug})
+	}
+	return res, nil
+}
+
+// noRules, noTagged and noHistory leave the rules, the classifier and the
+// prompt without anything to go on.
+type noRules struct{}
+
+func (noRules) FetchEnabled(ctx context.Context) ([]*rule.Rule, error) {
+	return nil, nil
+}
+
+type noTagged struct{}
+
+func (noTagged) FetchTagged(ctx context.Context, limit int) ([]*transaction.Transaction, error) {
+	return nil, nil
+}
+
+type noHistory struct{}
+
+func (noHistory) CounterpartyTags(ctx context.Context, id uuid.UUID, limit int) ([]tagging.TagCount, error) {
+	return nil, nil
+}
+
+func (noHistory) SimilarTagged(ctx context.Context, tx *transaction.Transaction, name string, limit int) ([]*transaction.Transaction, error) {
+	return nil, nil
+}
+
+func (noHistory) FetchByID(ctx context.Context, id uuid.UUID) (*counterparty.Counterparty, error) {
+	return nil, counterparty.ErrCounterpartyNotFound
+}
+
+func TestTaggerJobProcess(t *testing.T) {
+	defaultAgent, savingsAgent := uuid.New(), uuid.New()
+	const savings = "NL91ABNA0417164300"
+	tests := []struct {
+		name string
+		// answers per agent, a missing agent answers 503
+		answers map[uuid.UUID]string
+		want    []string
+		wantErr bool
+	}{
+		{
+			// nothing is written, not even uncategorised
+			name:    "agent unavailable",
+			want:    []string{"", "", ""},
+			wantErr: true,
+		},
+		{
+			name: "agent answers",
+			answers: map[uuid.UUID]string{
+				defaultAgent: `{"results": [{"index": 1, "tag": "groceries", "confidence": 0.9}]}`,
+				savingsAgent: `{"results": []}`,
+			},
+			// the agent answered without a guess for the second transaction
+			want: []string{"groceries", category.Uncategorised, category.Uncategorised},
+		},
+		{
+			// the transactions of the failed call are left for a retry
+			name: "one agent unavailable",
+			answers: map[uuid.UUID]string{
+				defaultAgent: `{"results": [{"index": 1, "tag": "groceries", "confidence": 0.9}]}`,
+			},
+			want:    []string{"groceries", category.Uncategorised, ""},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				id, _ := uuid.Parse(strings.Split(strings.Trim(r.URL.Path, "/"), "/")[0])
+				answer, ok := tt.answers[id]
+				if !ok {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.Write([]byte(answer))
+			}))
+			defer srv.Close()
+
+			tmpl, err := tagging.LoadTemplate("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			prompter := tagging.NewPrompter(tmpl, noHistory{}, noHistory{}, 0)
+			chain := tagging.NewChain(nopLogger{}, 0.5,
+				tagging.NewRuleTagger(noRules{}),
+				tagging.NewClassifierTagger(classifier.NewService(noTagged{}, 1)),
+				tagging.NewAgentTagger(agent.NewClient(srv.URL), prompter, defaultAgent, tagging.AgentRoute{AgentID: savingsAgent, Account: savings}),
+			)
+			store := &fakeStore{tags: map[uuid.UUID]string{}}
+			j := NewTaggerJob(chain, nil, store, fakeCategories{"groceries", category.Uncategorised}, 3, 1, time.Minute, 0, time.Second, nopLogger{})
+
+			txs := []*transaction.Transaction{
+				{ID: uuid.New(), Description: "ALBERT HEIJN", AmountCents: 1229, Direction: transaction.CashOut},
+				{ID: uuid.New(), Description: "BAKKER BART", AmountCents: 450, Direction: transaction.CashOut},
+				{ID: uuid.New(), Description: "SPAARREKENING", AmountCents: 10000, Direction: transaction.CashOut, Account: savings},
+			}
+			err = j.process(context.Background(), txs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("process() error = %v, want error %v", err, tt.wantErr)
+			}
+			for i, tx := range txs {
+				if got := store.tags[tx.ID]; got != tt.want[i] {
+					t.Errorf("transaction %d tagged %q, want %q", i, got, tt.want[i])
+				}
+			}
+			// claims are released either way, failed transactions are
+			// claimed again after the worker backed off
+			if len(store.released) != len(txs) {
+				t.Errorf("released %d claims, want %d", len(store.released), len(txs))
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package label
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Single-use interfaces only used by AssignHandler
+
+type LabelEnsurer interface {
+	// Ensure returns the labels with the given names, creating the ones that
+	// do not exist yet.
+	Ensure(ctx context.Context, names []string) ([]*Label, error)
+}
+
+type LabelsByNameFetcher interface {
+	FetchByNames(ctx context.Context, names []string) ([]*Label, error)
+}
+
+type TransactionLabeler interface {
+	Attach(ctx context.Context, transactionIDs, labelIDs []uuid.UUID) error
+	Detach(ctx context.Context, transactionIDs, labelIDs []uuid.UUID) error
+}
+
+// ErrTransactionNotFound is returned when one of the transactions to label
+// does not exist.
+var ErrTransactionNotFound = fmt.Errorf("one or more transactions not found")
+
+type AssignHandler struct {
+	tr  TxRunner
+	tc  TransactionChecker
+	le  LabelEnsurer
+	lf  LabelsByNameFetcher
+	tlr TransactionLabeler
+}
+
+func NewAssignHandler(tr TxRunner, tc TransactionChecker, le LabelEnsurer, lf LabelsByNameFetcher, tlr TransactionLabeler) *AssignHandler {
+	return &AssignHandler{tr: tr, tc: tc, le: le, lf: lf, tlr: tlr}
+}
+
+// Handle adds and removes labels on the transactions. Labels to add are
+// created when they do not exist yet, removing a label a transaction does not
+// carry is not an error.
+func (h *AssignHandler) Handle(ctx context.Context, transactionIDs []uuid.UUID, add, remove []string) error {
+	add, err := Names(add)
+	if err != nil {
+		return err
+	}
+	remove, err = Names(remove)
+	if err != nil {
+		return err
+	}
+	return h.tr.WithTx(ctx, func(ctx context.Context) error {
+		ok, err := h.tc.Exists(ctx, transactionIDs)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrTransactionNotFound
+		}
+		if len(add) > 0 {
+			labels, err := h.le.Ensure(ctx, add)
+			if err != nil {
+				return err
+			}
+			if err := h.tlr.Attach(ctx, transactionIDs, ids(labels)); err != nil {
+				return err
+			}
+		}
+		if len(remove) > 0 {
+			labels, err := h.lf.FetchByNames(ctx, remove)
+			if err != nil {
+				return err
+			}
+			if len(labels) > 0 {
+				return h.tlr.Detach(ctx, transactionIDs, ids(labels))
+			}
+		}
+		return nil
+	})
+}
+
+func ids(labels []*Label) []uuid.UUID {
+	res := make([]uuid.UUID, 0, len(labels))
+	for _, l := range labels {
+		res = append(res, l.ID)
+	}
+	return res
+}
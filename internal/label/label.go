@@ -0,0 +1,67 @@
+package label
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Label is a free-form marker like "vacation-2025" or "tax-deductible". A
+// transaction has a single category but any number of labels.
+type Label struct {
+	ID        uuid.UUID `db:"id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+var (
+	ErrLabelNotFound = fmt.Errorf("label not found")
+	ErrInvalidName   = fmt.Errorf("label name cannot be empty")
+)
+
+// Shared interfaces used by multiple use cases
+
+type TransactionChecker interface {
+	// Exists reports whether all transactions with the given ids exist.
+	Exists(ctx context.Context, ids []uuid.UUID) (bool, error)
+}
+
+// TxRunner runs fn within a single database transaction.
+type TxRunner interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Normalise lower-cases the name and joins its words with dashes, so
+// "Vacation 2025" and "vacation-2025" are the same label.
+func Normalise(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), "-")
+}
+
+// Names normalises the names and drops empty ones and duplicates.
+func Names(names []string) ([]string, error) {
+	seen := map[string]bool{}
+	res := make([]string, 0, len(names))
+	for _, n := range names {
+		n = Normalise(n)
+		if n == "" {
+			return nil, ErrInvalidName
+		}
+		if !seen[n] {
+			seen[n] = true
+			res = append(res, n)
+		}
+	}
+	return res, nil
+}
+
+// NewLabel creates a label with a normalised name.
+func NewLabel(name string) (*Label, error) {
+	name = Normalise(name)
+	if name == "" {
+		return nil, ErrInvalidName
+	}
+	return &Label{ID: uuid.New(), Name: name, CreatedAt: time.Now().UTC()}, nil
+}
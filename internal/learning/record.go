@@ -0,0 +1,73 @@
+package learning
+
+import (
+	"context"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// Single-use interfaces only used by RecordHandler
+
+type SuggestionRecorder interface {
+	// Record counts a correction for the key and tag of s, creating s when it
+	// does not exist yet, and returns the stored suggestion.
+	Record(ctx context.Context, s *Suggestion) (*Suggestion, error)
+}
+
+// RecordHandler learns from manual tag corrections. Every correction proposes
+// a rule mapping the transaction's key to the new tag, once the same
+// correction has been made autoCreateAfter times the rule is created.
+type RecordHandler struct {
+	tr              TxRunner
+	sr              SuggestionRecorder
+	su              SuggestionUpdater
+	rc              RuleCreator
+	autoCreateAfter int
+}
+
+func NewRecordHandler(tr TxRunner, sr SuggestionRecorder, su SuggestionUpdater, rc RuleCreator, autoCreateAfter int) *RecordHandler {
+	if autoCreateAfter <= 0 {
+		autoCreateAfter = DefaultAutoCreateAfter
+	}
+	return &RecordHandler{tr: tr, sr: sr, su: su, rc: rc, autoCreateAfter: autoCreateAfter}
+}
+
+// Handle records that tx was manually re-tagged from previousTag to tx.Tag. It
+// returns the resulting suggestion or nil when there is nothing to learn. Only
+// changes of an existing tag count as corrections, the first tag of an
+// untagged transaction corrects nothing.
+func (h *RecordHandler) Handle(ctx context.Context, tx *transaction.Transaction, previousTag string) (*Suggestion, error) {
+	if previousTag == "" || tx.Tag == "" || tx.Tag == previousTag {
+		return nil, nil
+	}
+	keyType, key := KeyFor(tx)
+	if key == "" {
+		return nil, nil
+	}
+	var s *Suggestion
+	err := h.tr.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		s, err = h.sr.Record(ctx, NewSuggestion(keyType, key, tx.Tag))
+		if err != nil {
+			return err
+		}
+		if s.Status != SuggestionPending || s.Count < h.autoCreateAfter {
+			return nil
+		}
+		r, err := s.Rule()
+		if err != nil {
+			return err
+		}
+		if err := h.rc.Create(ctx, r); err != nil {
+			return err
+		}
+		if err := s.Accept(r.ID); err != nil {
+			return err
+		}
+		return h.su.Update(ctx, s)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
@@ -0,0 +1,59 @@
+package learning
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type ReviewHandler struct {
+	tr TxRunner
+	sf SuggestionFetcher
+	su SuggestionUpdater
+	rc RuleCreator
+}
+
+func NewReviewHandler(tr TxRunner, sf SuggestionFetcher, su SuggestionUpdater, rc RuleCreator) *ReviewHandler {
+	return &ReviewHandler{tr: tr, sf: sf, su: su, rc: rc}
+}
+
+// Accept creates the rule proposed by the suggestion.
+func (h *ReviewHandler) Accept(ctx context.Context, id uuid.UUID) (*Suggestion, error) {
+	var s *Suggestion
+	err := h.tr.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		if s, err = h.sf.FetchByID(ctx, id); err != nil {
+			return err
+		}
+		if s.Status != SuggestionPending {
+			return ErrSuggestionHandled
+		}
+		r, err := s.Rule()
+		if err != nil {
+			return err
+		}
+		if err := h.rc.Create(ctx, r); err != nil {
+			return err
+		}
+		if err := s.Accept(r.ID); err != nil {
+			return err
+		}
+		return h.su.Update(ctx, s)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Dismiss rejects the suggestion so it is no longer proposed.
+func (h *ReviewHandler) Dismiss(ctx context.Context, id uuid.UUID) (*Suggestion, error) {
+	s, err := h.sf.FetchByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Dismiss(); err != nil {
+		return nil, err
+	}
+	return s, h.su.Update(ctx, s)
+}
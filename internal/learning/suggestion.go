@@ -0,0 +1,140 @@
+package learning
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/counterparty"
+	"github.com/lennardclaproth/my-finances-tracker/internal/rule"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+type KeyType string
+
+const (
+	// KeyCounterparty keys a suggestion on the resolved counterparty.
+	KeyCounterparty KeyType = "counterparty"
+	// KeyDescription keys a suggestion on the normalised description, used
+	// when a transaction has no counterparty.
+	KeyDescription KeyType = "description"
+)
+
+type SuggestionStatus string
+
+const (
+	SuggestionPending   SuggestionStatus = "pending"
+	SuggestionAccepted  SuggestionStatus = "accepted"
+	SuggestionDismissed SuggestionStatus = "dismissed"
+)
+
+// DefaultAutoCreateAfter is the number of identical manual corrections after
+// which a rule is created without confirmation.
+const DefaultAutoCreateAfter = 3
+
+// Suggestion is a rule proposed from manual tag corrections: transactions with
+// the same key were re-tagged to Tag Count times.
+type Suggestion struct {
+	ID        uuid.UUID        `db:"id"`
+	KeyType   KeyType          `db:"key_type"`
+	Key       string           `db:"key"`
+	Tag       string           `db:"tag"`
+	Count     int              `db:"count"`
+	Status    SuggestionStatus `db:"status"`
+	RuleID    *uuid.UUID       `db:"rule_id"`
+	CreatedAt time.Time        `db:"created_at"`
+	UpdatedAt time.Time        `db:"updated_at"`
+}
+
+var (
+	ErrSuggestionNotFound = fmt.Errorf("rule suggestion not found")
+	ErrSuggestionHandled  = fmt.Errorf("rule suggestion was already accepted or dismissed")
+)
+
+// Shared interfaces used by multiple use cases
+
+type SuggestionFetcher interface {
+	FetchByID(ctx context.Context, id uuid.UUID) (*Suggestion, error)
+}
+
+type SuggestionUpdater interface {
+	Update(ctx context.Context, s *Suggestion) error
+}
+
+type RuleCreator interface {
+	Create(ctx context.Context, r *rule.Rule) error
+}
+
+type TxRunner interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// KeyFor derives the suggestion key of a transaction.
+func KeyFor(tx *transaction.Transaction) (KeyType, string) {
+	if tx.CounterpartyID != nil {
+		return KeyCounterparty, tx.CounterpartyID.String()
+	}
+	return KeyDescription, counterparty.Normalise(tx.Description)
+}
+
+// NewSuggestion creates a pending suggestion for a first correction.
+func NewSuggestion(keyType KeyType, key, tag string) *Suggestion {
+	return &Suggestion{
+		ID:        uuid.New(),
+		KeyType:   keyType,
+		Key:       key,
+		Tag:       tag,
+		Count:     1,
+		Status:    SuggestionPending,
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+	}
+}
+
+// Rule builds the rule the suggestion proposes.
+func (s *Suggestion) Rule() (*rule.Rule, error) {
+	tag := s.Tag
+	c := rule.Conditions{}
+	switch s.KeyType {
+	case KeyCounterparty:
+		id, err := uuid.Parse(s.Key)
+		if err != nil {
+			return nil, fmt.Errorf("learning: invalid counterparty key %q: %w", s.Key, err)
+		}
+		c.CounterpartyID = &id
+	default:
+		// the key is a normalised description, match it as whole words in the
+		// raw description
+		c.DescriptionRegex = `\b` + regexp.QuoteMeta(s.Key) + `\b`
+	}
+	name := fmt.Sprintf("Learned: %s %s → %s", s.KeyType, s.Key, tag)
+	return rule.NewRule(name, LearnedRulePriority, true, c, rule.Actions{SetTag: &tag})
+}
+
+// LearnedRulePriority puts learned rules after hand written rules, which
+// usually use lower priorities.
+const LearnedRulePriority = 1000
+
+// Accept marks the suggestion as accepted by the rule with the given id.
+func (s *Suggestion) Accept(ruleID uuid.UUID) error {
+	if s.Status != SuggestionPending {
+		return ErrSuggestionHandled
+	}
+	s.Status = SuggestionAccepted
+	s.RuleID = &ruleID
+	s.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// Dismiss marks the suggestion as dismissed, it will not be proposed or
+// auto-created anymore.
+func (s *Suggestion) Dismiss() error {
+	if s.Status != SuggestionPending {
+		return ErrSuggestionHandled
+	}
+	s.Status = SuggestionDismissed
+	s.UpdatedAt = time.Now().UTC()
+	return nil
+}
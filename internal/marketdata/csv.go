@@ -0,0 +1,148 @@
+package marketdata
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format is the dialect of an OHLCV CSV file.
+type Format string
+
+const (
+	// FormatYahoo is the Yahoo Finance download:
+	// Date,Open,High,Low,Close,Adj Close,Volume
+	FormatYahoo Format = "yahoo"
+	// FormatStooq is the Stooq download, either Date,Open,High,Low,Close,Volume
+	// (or its Polish headers) or the ASCII format starting with <TICKER>.
+	FormatStooq Format = "stooq"
+)
+
+var (
+	ErrUnknownFormat = fmt.Errorf("the CSV header is neither a Yahoo nor a Stooq OHLCV header")
+	ErrEmptyFile     = fmt.Errorf("the CSV file has no header")
+	ErrInvalidRow    = fmt.Errorf("invalid row")
+)
+
+// columns maps the headers of both dialects to the fields they hold.
+var columns = map[string]string{
+	"date": "date", "data": "date", "<date>": "date",
+	"open": "open", "otwarcie": "open", "<open>": "open",
+	"high": "high", "najwyzszy": "high", "<high>": "high",
+	"low": "low", "najnizszy": "low", "<low>": "low",
+	"close": "close", "zamkniecie": "close", "<close>": "close",
+	"volume": "volume", "wolumen": "volume", "<vol>": "volume",
+	"<ticker>": "ticker",
+}
+
+// ParseResult holds the rows of a parsed file. Rows without prices, such as
+// the null rows Yahoo writes for holidays, are skipped.
+type ParseResult struct {
+	Format  Format
+	History []*History
+	Skipped int
+}
+
+// ParseCSV parses a Yahoo or Stooq style OHLCV file, the dialect is detected
+// from the header. The symbol is used for every row, files in the Stooq
+// ASCII format name their ticker themselves and may be parsed without one.
+func ParseCSV(r io.Reader, symbol string) (*ParseResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, ErrEmptyFile
+	}
+	if err != nil {
+		return nil, fmt.Errorf("marketdata: failed to read header: %w", err)
+	}
+	res := &ParseResult{Format: FormatStooq}
+	index := map[string]int{}
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		if h == "adj close" {
+			res.Format = FormatYahoo
+		}
+		if field, ok := columns[h]; ok {
+			index[field] = i
+		}
+	}
+	for _, field := range []string{"date", "open", "high", "low", "close"} {
+		if _, ok := index[field]; !ok {
+			return nil, ErrUnknownFormat
+		}
+	}
+	if _, ok := index["ticker"]; !ok && NormaliseSymbol(symbol) == "" {
+		return nil, ErrInvalidSymbol
+	}
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w on line %d: %v", ErrInvalidRow, line, err)
+		}
+		h, err := parseRecord(record, index, symbol)
+		if err != nil {
+			return nil, fmt.Errorf("%w on line %d: %v", ErrInvalidRow, line, err)
+		}
+		if h == nil {
+			res.Skipped++
+			continue
+		}
+		res.History = append(res.History, h)
+	}
+}
+
+// parseRecord returns nil for rows without prices.
+func parseRecord(record []string, index map[string]int, symbol string) (*History, error) {
+	field := func(name string) string {
+		i, ok := index[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	if symbol == "" {
+		symbol = field("ticker")
+	}
+	date, err := parseDate(field("date"))
+	if err != nil {
+		return nil, err
+	}
+	prices := make([]Price, 4)
+	for i, name := range []string{"open", "high", "low", "close"} {
+		v := field(name)
+		if v == "" || strings.EqualFold(v, "null") {
+			return nil, nil
+		}
+		if prices[i], err = ParsePrice(v); err != nil {
+			return nil, fmt.Errorf("invalid %s price %q", name, v)
+		}
+	}
+	var volume int64
+	if v := field("volume"); v != "" && !strings.EqualFold(v, "null") {
+		// some exports write the volume as a float
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid volume %q", v)
+		}
+		volume = int64(f)
+	}
+	return NewHistory(symbol, date, prices[0], prices[3], prices[1], prices[2], volume), nil
+}
+
+func parseDate(s string) (time.Time, error) {
+	for _, layout := range []string{time.DateOnly, "20060102"} {
+		if d, err := time.Parse(layout, s); err == nil {
+			return d, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
@@ -0,0 +1,46 @@
+package marketdata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileProvider reads prices from Yahoo or Stooq style CSV files named after
+// their symbol, e.g. VWRL.AS.csv, for use without network access. Files are
+// read on every fetch so they can be replaced while the server runs.
+type FileProvider struct {
+	dir string
+}
+
+func NewFileProvider(dir string) *FileProvider {
+	return &FileProvider{dir: dir}
+}
+
+func (p *FileProvider) Fetch(ctx context.Context, symbol string, from, to time.Time) ([]*History, error) {
+	symbol = NormaliseSymbol(symbol)
+	if symbol == "" || strings.ContainsAny(symbol, `/\`) || strings.Contains(symbol, "..") {
+		return nil, ErrInvalidSymbol
+	}
+	for _, name := range []string{symbol, strings.ToLower(symbol)} {
+		f, err := os.Open(filepath.Join(p.dir, name+".csv"))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("marketdata: failed to open prices of %s: %w", symbol, err)
+		}
+		defer f.Close()
+		parsed, err := ParseCSV(f, symbol)
+		if err != nil {
+			return nil, fmt.Errorf("marketdata: failed to parse %s: %w", f.Name(), err)
+		}
+		return within(parsed.History, from, to), nil
+	}
+	return nil, ErrNoHistory
+}
@@ -1,27 +1,36 @@
 package marketdata
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+var (
+	ErrNoHistory     = fmt.Errorf("no market history for the symbol")
+	ErrInvalidSymbol = fmt.Errorf("symbol is required")
+)
+
+// History is the daily open, high, low and close price and the traded volume
+// of a symbol. Prices are in the currency the symbol is traded in.
 type History struct {
 	ID     uuid.UUID `db:"id"`
 	Symbol string    `db:"symbol"`
 	Date   time.Time `db:"date"`
-	Open   float64   `db:"open"`
-	Close  float64   `db:"close"`
-	High   float64   `db:"high"`
-	Low    float64   `db:"low"`
+	Open   Price     `db:"open"`
+	Close  Price     `db:"close"`
+	High   Price     `db:"high"`
+	Low    Price     `db:"low"`
 	Volume int64     `db:"volume"`
 }
 
-func NewHistory(symbol string, date time.Time, open, close, high, low float64, volume int64) *History {
+func NewHistory(symbol string, date time.Time, open, close, high, low Price, volume int64) *History {
 	return &History{
 		ID:     uuid.New(),
-		Symbol: symbol,
-		Date:   date,
+		Symbol: NormaliseSymbol(symbol),
+		Date:   day(date),
 		Open:   open,
 		Close:  close,
 		High:   high,
@@ -29,3 +38,8 @@ func NewHistory(symbol string, date time.Time, open, close, high, low float64, v
 		Volume: volume,
 	}
 }
+
+// NormaliseSymbol upper cases the symbol so VWRL.as and VWRL.AS are the same.
+func NormaliseSymbol(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
@@ -0,0 +1,112 @@
+package marketdata
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.elastic.co/apm/module/apmhttp/v2"
+)
+
+// HTTPProvider downloads Yahoo or Stooq style CSV files from an endpoint.
+// The URL may contain the placeholders {symbol}, {from} and {to}
+// (YYYY-MM-DD), {from_compact} and {to_compact} (YYYYMMDD) and {from_unix}
+// and {to_unix} (seconds, to_unix is the end of the last day), e.g.
+// https://stooq.com/q/d/l/?s={symbol}&d1={from_compact}&d2={to_compact}&i=d
+type HTTPProvider struct {
+	http    *http.Client
+	url     string
+	headers map[string]string
+}
+
+// NewHTTPProvider creates an HTTPProvider, the headers are sent with every
+// request, e.g. to pass an API key.
+func NewHTTPProvider(url string, headers map[string]string, timeout time.Duration) *HTTPProvider {
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &HTTPProvider{
+		http: &http.Client{
+			Transport: apmhttp.WrapRoundTripper(http.DefaultTransport),
+			Timeout:   timeout,
+		},
+		url:     url,
+		headers: headers,
+	}
+}
+
+func (p *HTTPProvider) Fetch(ctx context.Context, symbol string, from, to time.Time) ([]*History, error) {
+	symbol = NormaliseSymbol(symbol)
+	if symbol == "" {
+		return nil, ErrInvalidSymbol
+	}
+	from, to = day(from), day(to)
+	target := strings.NewReplacer(
+		"{symbol}", url.QueryEscape(symbol),
+		"{from}", from.Format(time.DateOnly),
+		"{to}", to.Format(time.DateOnly),
+		"{from_compact}", from.Format("20060102"),
+		"{to_compact}", to.Format("20060102"),
+		"{from_unix}", strconv.FormatInt(from.Unix(), 10),
+		"{to_unix}", strconv.FormatInt(to.AddDate(0, 0, 1).Unix(), 10),
+	).Replace(p.url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("marketdata: failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/csv")
+	for k, v := range p.headers {
+		req.Header.Set(k, v)
+	}
+	res, err := p.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("marketdata: failed to fetch %s: %w", symbol, err)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(res.Body, 20<<20))
+	if err != nil {
+		return nil, fmt.Errorf("marketdata: failed to read prices of %s: %w", symbol, err)
+	}
+	switch {
+	case res.StatusCode == http.StatusTooManyRequests:
+		return nil, &RateLimitError{RetryAfter: retryAfter(res.Header.Get("Retry-After"))}
+	case res.StatusCode == http.StatusNotFound:
+		return nil, ErrNoHistory
+	case res.StatusCode >= 300:
+		return nil, fmt.Errorf("marketdata: provider answered %d for %s: %s", res.StatusCode, symbol, strings.TrimSpace(string(body)))
+	}
+	// Stooq answers unknown symbols and empty ranges with a plain text body
+	if text := strings.TrimSpace(string(body)); text == "" || strings.EqualFold(text, "no data") {
+		return []*History{}, nil
+	}
+	parsed, err := ParseCSV(bytes.NewReader(body), symbol)
+	if err != nil {
+		if errors.Is(err, ErrUnknownFormat) {
+			return nil, fmt.Errorf("marketdata: provider did not answer with prices for %s: %w", symbol, err)
+		}
+		return nil, err
+	}
+	return within(parsed.History, from, to), nil
+}
+
+// retryAfter parses a Retry-After header given either in seconds or as an
+// HTTP date.
+func retryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return 0
+}
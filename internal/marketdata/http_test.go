@@ -0,0 +1,149 @@
+package marketdata
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const yahooCSV = `Date,Open,High,Low,Close,Adj Close,Volume
+2023-12-29,100.00,101.00,99.00,100.50,100.50,1000
+2024-01-02,100.50,102.00,100.00,101.25,101.25,1200
+2024-01-03,101.25,101.50,100.75,101.00,101.00,900
+2024-01-04,null,null,null,null,null,null
+2024-01-05,101.00,103.00,101.00,102.75,102.75,1500
+`
+
+func TestHTTPProviderFetch(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		header  http.Header
+		body    string
+		want    []string
+		wantErr func(error) bool
+	}{
+		{
+			name:   "prices within the range",
+			status: http.StatusOK,
+			body:   yahooCSV,
+			want:   []string{"2024-01-02", "2024-01-03"},
+		},
+		{
+			name:   "no data",
+			status: http.StatusOK,
+			body:   "No data\n",
+			want:   []string{},
+		},
+		{
+			name:    "unknown symbol",
+			status:  http.StatusNotFound,
+			wantErr: func(err error) bool { return errors.Is(err, ErrNoHistory) },
+		},
+		{
+			name:   "rate limited",
+			status: http.StatusTooManyRequests,
+			header: http.Header{"Retry-After": {"30"}},
+			wantErr: func(err error) bool {
+				var limited *RateLimitError
+				return errors.As(err, &limited) && limited.RetryAfter == 30*time.Second
+			},
+		},
+		{
+			name:    "server error",
+			status:  http.StatusBadGateway,
+			body:    "upstream down",
+			wantErr: func(err error) bool { return err != nil && !errors.Is(err, ErrNoHistory) },
+		},
+		{
+			name:    "not a price file",
+			status:  http.StatusOK,
+			body:    "<html><body>Sign in</body></html>",
+			wantErr: func(err error) bool { return errors.Is(err, ErrUnknownFormat) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				q := r.URL.Query()
+				if q.Get("s") != "ASML.AS" || q.Get("d1") != "20240102" || q.Get("d2") != "20240103" ||
+					q.Get("from") != "2024-01-02" || q.Get("p1") != "1704153600" || q.Get("p2") != "1704326400" {
+					t.Errorf("unexpected query %s", r.URL.RawQuery)
+				}
+				if got := r.Header.Get("X-Api-Key"); got != "secret" {
+					t.Errorf("X-Api-Key = %q, want secret", got)
+				}
+				for k, v := range tt.header {
+					w.Header()[k] = v
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			p := NewHTTPProvider(srv.URL+"/?s={symbol}&d1={from_compact}&d2={to_compact}&from={from}&p1={from_unix}&p2={to_unix}",
+				map[string]string{"X-Api-Key": "secret"}, time.Second)
+			history, err := p.Fetch(context.Background(), " asml.as ", date("2024-01-02").Add(15*time.Hour), date("2024-01-03"))
+			if tt.wantErr != nil {
+				if !tt.wantErr(err) {
+					t.Fatalf("Fetch() error = %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			got := make([]string, 0, len(history))
+			for _, h := range history {
+				if h.Symbol != "ASML.AS" {
+					t.Errorf("symbol = %q, want ASML.AS", h.Symbol)
+				}
+				got = append(got, h.Date.Format(time.DateOnly))
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("dates = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("dates = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPProviderFetchPrices(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(yahooCSV))
+	}))
+	defer srv.Close()
+
+	history, err := NewHTTPProvider(srv.URL, nil, time.Second).Fetch(context.Background(), "ASML.AS", date("2024-01-02"), date("2024-01-02"))
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("got %d days, want 1", len(history))
+	}
+	h := history[0]
+	if h.Open != 100_500_000 || h.High != 102_000_000 || h.Low != 100_000_000 || h.Close != 101_250_000 || h.Volume != 1200 {
+		t.Errorf("history = %+v", *h)
+	}
+}
+
+func TestHTTPProviderTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	_, err := NewHTTPProvider(srv.URL, nil, 50*time.Millisecond).Fetch(context.Background(), "ASML.AS", date("2024-01-02"), date("2024-01-03"))
+	if err == nil {
+		t.Fatal("Fetch() error = nil, want a timeout")
+	}
+}
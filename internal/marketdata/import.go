@@ -0,0 +1,39 @@
+package marketdata
+
+import (
+	"context"
+	"io"
+)
+
+// Shared interfaces used by multiple use cases
+
+type HistoryUpserter interface {
+	// Upsert stores the history, a day that is already stored for the
+	// symbol is overwritten.
+	Upsert(ctx context.Context, history []*History) error
+}
+
+// ImportHandler imports the prices of an OHLCV CSV file.
+type ImportHandler struct {
+	u HistoryUpserter
+}
+
+func NewImportHandler(u HistoryUpserter) *ImportHandler {
+	return &ImportHandler{u: u}
+}
+
+// Handle parses the file and stores its prices for the symbol, re-importing
+// an overlapping file corrects the days it contains.
+func (h *ImportHandler) Handle(ctx context.Context, r io.Reader, symbol string) (*ParseResult, error) {
+	res, err := ParseCSV(r, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.History) == 0 {
+		return res, nil
+	}
+	if err := h.u.Upsert(ctx, res.History); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
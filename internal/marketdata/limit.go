@@ -0,0 +1,64 @@
+package marketdata
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Limited spaces the requests to a provider so it is called at most
+// perMinute times a minute. When the provider reports it is rate limited no
+// requests are made until the delay it asked for has passed.
+type Limited struct {
+	PriceProvider
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// NewLimited returns the provider as is when perMinute is not positive.
+func NewLimited(p PriceProvider, perMinute int) PriceProvider {
+	if perMinute <= 0 {
+		return p
+	}
+	return &Limited{PriceProvider: p, interval: time.Minute / time.Duration(perMinute)}
+}
+
+func (l *Limited) Fetch(ctx context.Context, symbol string, from, to time.Time) ([]*History, error) {
+	if err := l.wait(ctx); err != nil {
+		return nil, err
+	}
+	history, err := l.PriceProvider.Fetch(ctx, symbol, from, to)
+	var limited *RateLimitError
+	if errors.As(err, &limited) && limited.RetryAfter > 0 {
+		l.mu.Lock()
+		if next := time.Now().Add(limited.RetryAfter); next.After(l.next) {
+			l.next = next
+		}
+		l.mu.Unlock()
+	}
+	return history, err
+}
+
+// wait blocks until the next request slot.
+func (l *Limited) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(slot))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
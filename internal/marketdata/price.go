@@ -0,0 +1,86 @@
+package marketdata
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Price is an amount in millionths of a currency unit, 101.25 is 101250000.
+// Quotes often have more decimals than cents, integers keep them exact.
+type Price int64
+
+const priceScale = 1_000_000
+
+var ErrInvalidPrice = fmt.Errorf("invalid price")
+
+// ParsePrice parses a decimal price such as 101.25 or -0.5. A decimal comma
+// is accepted as well, thousands separators are not. Digits beyond the sixth
+// decimal are rounded half away from zero.
+func ParsePrice(s string) (Price, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", ".")
+	if s == "" {
+		return 0, ErrInvalidPrice
+	}
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimLeft(s, "+-")
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" {
+		whole = "0"
+	}
+	if !digits(whole) || !digits(frac) {
+		return 0, ErrInvalidPrice
+	}
+	round := len(frac) > 6 && frac[6] >= '5'
+	frac = (frac + "000000")[:6]
+	w, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || w > (1<<63-1)/priceScale-1 {
+		return 0, ErrInvalidPrice
+	}
+	f, _ := strconv.ParseInt(frac, 10, 64)
+	p := w*priceScale + f
+	if round {
+		p++
+	}
+	if negative {
+		p = -p
+	}
+	return Price(p), nil
+}
+
+func digits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the price with the decimals it needs, at least two.
+func (p Price) String() string {
+	sign := ""
+	v := int64(p)
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	frac := strings.TrimRight(fmt.Sprintf("%06d", v%priceScale), "0")
+	for len(frac) < 2 {
+		frac += "0"
+	}
+	return fmt.Sprintf("%s%d.%s", sign, v/priceScale, frac)
+}
+
+// ValueCents returns the value in cents of quantityMicros (millionths of a
+// unit) at the price, rounded half away from zero.
+func (p Price) ValueCents(quantityMicros int64) int64 {
+	// micros times micros is in 1e-12 units, a cent is 1e-2
+	v := new(big.Int).Mul(big.NewInt(quantityMicros), big.NewInt(int64(p)))
+	d := big.NewInt(priceScale * priceScale / 100)
+	q, r := new(big.Int).QuoRem(v, d, new(big.Int))
+	if new(big.Int).Abs(r).Cmp(new(big.Int).Rsh(d, 1)) >= 0 {
+		q.Add(q, big.NewInt(int64(v.Sign())))
+	}
+	return q.Int64()
+}
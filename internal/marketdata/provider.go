@@ -0,0 +1,47 @@
+package marketdata
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// PriceProvider fetches daily prices from an external source.
+type PriceProvider interface {
+	// Fetch returns the daily prices of the symbol from from through to,
+	// oldest first. ErrNoHistory is returned for symbols the provider does
+	// not know.
+	Fetch(ctx context.Context, symbol string, from, to time.Time) ([]*History, error)
+}
+
+// RateLimitError is returned when a provider refuses requests because too
+// many were made.
+type RateLimitError struct {
+	// RetryAfter is the delay the provider asked for, zero when it did not
+	// say.
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("market data provider is rate limited, retry after %s", e.RetryAfter)
+	}
+	return "market data provider is rate limited"
+}
+
+// within returns the history from from through to.
+func within(history []*History, from, to time.Time) []*History {
+	from, to = day(from), day(to)
+	res := make([]*History, 0, len(history))
+	for _, h := range history {
+		if h.Date.Before(from) || h.Date.After(to) {
+			continue
+		}
+		res = append(res, h)
+	}
+	return res
+}
+
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
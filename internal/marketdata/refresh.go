@@ -0,0 +1,149 @@
+package marketdata
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+)
+
+// maxGapDays is the longest run of days without prices that is not a gap,
+// markets close for weekends and holidays such as Christmas and Easter.
+const maxGapDays = 5
+
+// Single-use interfaces only used by RefreshHandler
+
+type SymbolLister interface {
+	// TrackedSymbols returns the symbols that are held or have prices.
+	TrackedSymbols(ctx context.Context) ([]string, error)
+}
+
+type HistoryRanger interface {
+	// Range returns the history of the symbol from from through to, oldest
+	// first.
+	Range(ctx context.Context, symbol string, from, to time.Time) ([]*History, error)
+}
+
+// RefreshResult is the outcome of a refresh.
+type RefreshResult struct {
+	Symbols int
+	// Days is the number of days stored, including days that were
+	// corrected.
+	Days int
+	// Failed holds the error per symbol that could not be refreshed.
+	Failed map[string]error
+}
+
+// RefreshHandler keeps the prices of the tracked symbols current.
+type RefreshHandler struct {
+	p        PriceProvider
+	sl       SymbolLister
+	hr       HistoryRanger
+	u        HistoryUpserter
+	symbols  []string
+	backfill int
+}
+
+// NewRefreshHandler creates a RefreshHandler that refreshes the tracked
+// symbols and the given symbols, filling in the last backfillDays days, 730
+// when not positive.
+func NewRefreshHandler(p PriceProvider, sl SymbolLister, hr HistoryRanger, u HistoryUpserter, symbols []string, backfillDays int) *RefreshHandler {
+	if backfillDays <= 0 {
+		backfillDays = 730
+	}
+	return &RefreshHandler{p: p, sl: sl, hr: hr, u: u, symbols: symbols, backfill: backfillDays}
+}
+
+// Handle fetches the days missing from the backfill window for every symbol:
+// everything when it has no prices yet, the days before the first stored
+// day, the gaps between stored days and the days since the last stored day,
+// which is fetched again in case it was stored before the market closed.
+// Gaps the provider cannot fill, such as a trading halt or the days before a
+// listing, are asked for again on every run. Symbols the provider does not
+// know are skipped and other failures are collected per symbol. A
+// *RateLimitError stops the refresh, the remaining symbols are refreshed on
+// the next run.
+func (h *RefreshHandler) Handle(ctx context.Context, today time.Time) (RefreshResult, error) {
+	res := RefreshResult{Failed: map[string]error{}}
+	tracked, err := h.sl.TrackedSymbols(ctx)
+	if err != nil {
+		return res, err
+	}
+	var symbols []string
+	for _, s := range append(tracked, h.symbols...) {
+		if s = NormaliseSymbol(s); s != "" && !slices.Contains(symbols, s) {
+			symbols = append(symbols, s)
+		}
+	}
+	slices.Sort(symbols)
+
+	to := day(today)
+	from := to.AddDate(0, 0, -h.backfill)
+	for _, symbol := range symbols {
+		n, err := h.refresh(ctx, symbol, from, to)
+		res.Days += n
+		var limited *RateLimitError
+		switch {
+		case errors.As(err, &limited), ctx.Err() != nil:
+			return res, err
+		case errors.Is(err, ErrNoHistory):
+			continue
+		case err != nil:
+			res.Failed[symbol] = err
+			continue
+		}
+		res.Symbols++
+	}
+	return res, nil
+}
+
+func (h *RefreshHandler) refresh(ctx context.Context, symbol string, from, to time.Time) (int, error) {
+	stored, err := h.hr.Range(ctx, symbol, from, to)
+	if err != nil {
+		return 0, err
+	}
+	dates := make([]time.Time, 0, len(stored))
+	for _, s := range stored {
+		dates = append(dates, day(s.Date))
+	}
+	n := 0
+	for _, r := range missing(dates, from, to) {
+		history, err := h.p.Fetch(ctx, symbol, r[0], r[1])
+		if err != nil {
+			return n, err
+		}
+		if len(history) == 0 {
+			continue
+		}
+		if err := h.u.Upsert(ctx, history); err != nil {
+			return n, err
+		}
+		n += len(history)
+	}
+	return n, nil
+}
+
+// missing returns the ranges from from through to that have to be fetched
+// given the stored dates, oldest first.
+func missing(dates []time.Time, from, to time.Time) [][2]time.Time {
+	if len(dates) == 0 {
+		return [][2]time.Time{{from, to}}
+	}
+	var ranges [][2]time.Time
+	if first := dates[0]; first.Sub(from) > maxGapDays*24*time.Hour {
+		ranges = append(ranges, [2]time.Time{from, first.AddDate(0, 0, -1)})
+	}
+	for i := 1; i < len(dates); i++ {
+		if dates[i].Sub(dates[i-1]) > maxGapDays*24*time.Hour {
+			ranges = append(ranges, [2]time.Time{dates[i-1].AddDate(0, 0, 1), dates[i].AddDate(0, 0, -1)})
+		}
+	}
+	last := dates[len(dates)-1]
+	for d := last.AddDate(0, 0, 1); !d.After(to); d = d.AddDate(0, 0, 1) {
+		if d.Weekday() != time.Saturday && d.Weekday() != time.Sunday {
+			ranges = append(ranges, [2]time.Time{last, to})
+			break
+		}
+	}
+	return ranges
+}
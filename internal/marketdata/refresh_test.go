@@ -0,0 +1,92 @@
+package marketdata
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	d, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func dates(ss ...string) []time.Time {
+	res := make([]time.Time, 0, len(ss))
+	for _, s := range ss {
+		res = append(res, date(s))
+	}
+	return res
+}
+
+func TestMissing(t *testing.T) {
+	tests := []struct {
+		name     string
+		stored   []time.Time
+		from, to string
+		want     [][2]string
+	}{
+		{
+			name: "nothing stored",
+			from: "2024-01-01", to: "2024-01-31",
+			want: [][2]string{{"2024-01-01", "2024-01-31"}},
+		},
+		{
+			name:   "days before the first stored day",
+			stored: dates("2024-01-15", "2024-01-16", "2024-01-17", "2024-01-18", "2024-01-19"),
+			from:   "2024-01-01", to: "2024-01-19",
+			want: [][2]string{{"2024-01-01", "2024-01-14"}},
+		},
+		{
+			name:   "weekend before the first stored day",
+			stored: dates("2024-01-15", "2024-01-16", "2024-01-17", "2024-01-18", "2024-01-19"),
+			from:   "2024-01-13", to: "2024-01-19",
+		},
+		{
+			name:   "gap between stored days",
+			stored: dates("2024-01-01", "2024-01-02", "2024-01-12", "2024-01-15", "2024-01-16", "2024-01-17", "2024-01-18", "2024-01-19"),
+			from:   "2024-01-01", to: "2024-01-19",
+			want: [][2]string{{"2024-01-03", "2024-01-11"}},
+		},
+		{
+			name:   "weekend and holidays are not a gap",
+			stored: dates("2024-03-28", "2024-04-02"),
+			from:   "2024-03-28", to: "2024-04-02",
+		},
+		{
+			name:   "days since the last stored day",
+			stored: dates("2024-01-15", "2024-01-16", "2024-01-17"),
+			from:   "2024-01-15", to: "2024-01-19",
+			want: [][2]string{{"2024-01-17", "2024-01-19"}},
+		},
+		{
+			name:   "only a weekend since the last stored day",
+			stored: dates("2024-01-15", "2024-01-16", "2024-01-17", "2024-01-18", "2024-01-19"),
+			from:   "2024-01-15", to: "2024-01-21",
+		},
+		{
+			name:   "leading, inner and trailing ranges",
+			stored: dates("2024-01-10", "2024-01-22", "2024-01-23"),
+			from:   "2024-01-01", to: "2024-01-25",
+			want: [][2]string{
+				{"2024-01-01", "2024-01-09"},
+				{"2024-01-11", "2024-01-21"},
+				{"2024-01-23", "2024-01-25"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][2]string
+			for _, r := range missing(tt.stored, date(tt.from), date(tt.to)) {
+				got = append(got, [2]string{r[0].Format(time.DateOnly), r[1].Format(time.DateOnly)})
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("missing() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package money
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidAmount = fmt.Errorf("invalid amount")
+	ErrInexactAmount = fmt.Errorf("amount has more decimals than its currency")
+)
+
+// Amount is an amount of money in cents, the minor unit of its currency
+// amounts are stored in throughout: hundredths for most currencies, whole
+// yen for JPY and thousandths for BHD. Cents are kept as an integer so
+// amounts add up exactly, floats cannot represent most of them.
+type Amount struct {
+	Cents int64
+	// Currency is the ISO 4217 code of the amount, empty when the source did
+	// not name one.
+	Currency string
+}
+
+func New(cents int64, currency string) Amount {
+	return Amount{Cents: cents, Currency: strings.ToUpper(strings.TrimSpace(currency))}
+}
+
+// exponents are the ISO 4217 minor units of the currencies that do not have
+// two decimals.
+var exponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0,
+	"KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0,
+	"XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// Exponent returns the number of decimals of the currency, 2 for currencies
+// it does not know and amounts without a currency.
+func Exponent(currency string) int {
+	if e, ok := exponents[strings.ToUpper(strings.TrimSpace(currency))]; ok {
+		return e
+	}
+	return 2
+}
+
+// pow10 returns 10 to the power of the exponent.
+func pow10(exp int) int64 {
+	p := int64(1)
+	for i := 0; i < exp; i++ {
+		p *= 10
+	}
+	return p
+}
+
+// Rounding decides what happens to the digits beyond the minor unit.
+type Rounding int
+
+const (
+	// RoundHalfAwayFromZero rounds 0.125 EUR to 0.13 and -0.125 EUR to
+	// -0.13.
+	RoundHalfAwayFromZero Rounding = iota
+	// RoundHalfEven rounds a half to the even minor unit, 0.125 EUR to 0.12
+	// and 0.135 EUR to 0.14.
+	RoundHalfEven
+	// RoundTowardZero drops the digits beyond the minor unit.
+	RoundTowardZero
+	// RoundExact rejects amounts with more decimals than their currency
+	// with ErrInexactAmount, unless the extra decimals are zeros.
+	RoundExact
+)
+
+// Unicode characters some statements write instead of a space or hyphen.
+const (
+	nbsp       rune = 0x00a0
+	narrowNBSP rune = 0x202f
+	minus      rune = 0x2212
+)
+
+// Format describes how a statement writes its amounts.
+type Format struct {
+	// Decimal separates the minor units, a point when zero.
+	Decimal rune
+	// Thousands groups the whole units, zero when the statement does not
+	// group them. Spaces are accepted as a group separator as well. Groups
+	// after the first have to be exactly three digits, so 12.50 in a
+	// decimal comma format is rejected rather than read as 1250.
+	Thousands rune
+	Rounding  Rounding
+}
+
+var (
+	// DecimalPoint parses amounts such as 1,234.56.
+	DecimalPoint = Format{Decimal: '.', Thousands: ','}
+	// DecimalComma parses amounts such as 1.234,56.
+	DecimalComma = Format{Decimal: ',', Thousands: '.'}
+)
+
+// Parse parses a decimal amount such as -1,234.56 in the format into the
+// minor units of the currency, see Exponent. A leading minus or plus sign is
+// accepted, as is the Unicode minus sign.
+func Parse(s, currency string, f Format) (Amount, error) {
+	if f.Decimal == 0 {
+		f.Decimal = '.'
+	}
+	raw := s
+	s = strings.TrimSpace(s)
+	negative := false
+	switch {
+	case strings.HasPrefix(s, "-"), strings.HasPrefix(s, "+"):
+		negative = s[0] == '-'
+		s = s[1:]
+	case strings.HasPrefix(s, string(minus)):
+		negative = true
+		s = strings.TrimPrefix(s, string(minus))
+	}
+	whole, frac, _ := strings.Cut(s, string(f.Decimal))
+	whole, ok := ungroup(whole, f.Thousands)
+	if !ok || whole == "" && frac == "" || !digits(whole) || !digits(frac) {
+		return Amount{}, fmt.Errorf("%w %q", ErrInvalidAmount, raw)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	exp := Exponent(currency)
+	scale := pow10(exp)
+	w, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || w > (1<<63-1)/scale-1 {
+		return Amount{}, fmt.Errorf("%w %q", ErrInvalidAmount, raw)
+	}
+	padded := frac + strings.Repeat("0", exp)
+	c, _ := strconv.ParseInt("0"+padded[:exp], 10, 64)
+	cents := w*scale + c
+	up, err := roundUp(cents, padded[exp:], f.Rounding)
+	if err != nil {
+		return Amount{}, fmt.Errorf("%w %q", err, raw)
+	}
+	if up {
+		cents++
+	}
+	if negative {
+		cents = -cents
+	}
+	return New(cents, currency), nil
+}
+
+// roundUp reports whether the minor units, without their sign, round up
+// given the digits beyond them.
+func roundUp(cents int64, rest string, rounding Rounding) (bool, error) {
+	rest = strings.TrimRight(rest, "0")
+	if rest == "" {
+		return false, nil
+	}
+	switch rounding {
+	case RoundTowardZero:
+		return false, nil
+	case RoundExact:
+		return false, ErrInexactAmount
+	case RoundHalfEven:
+		if rest == "5" {
+			return cents%2 == 1, nil
+		}
+		return rest[0] >= '5', nil
+	default:
+		return rest[0] >= '5', nil
+	}
+}
+
+// ungroup removes the group separators from the whole units. It reports
+// false when the groups are not one to three digits followed by groups of
+// exactly three.
+func ungroup(whole string, thousands rune) (string, bool) {
+	groups := strings.FieldsFunc(whole, func(r rune) bool {
+		return (thousands != 0 && r == thousands) || r == ' ' || r == nbsp || r == narrowNBSP
+	})
+	joined := strings.Join(groups, "")
+	// FieldsFunc drops empty groups, so separators at either end or next to
+	// each other leave more separators than gaps between the groups
+	if utf8.RuneCountInString(whole) != utf8.RuneCountInString(joined)+max(len(groups)-1, 0) {
+		return "", false
+	}
+	if len(groups) > 1 {
+		if len(groups[0]) > 3 {
+			return "", false
+		}
+		for _, g := range groups[1:] {
+			if len(g) != 3 {
+				return "", false
+			}
+		}
+	}
+	return joined, true
+}
+
+func digits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Abs returns the amount without its sign.
+func (a Amount) Abs() Amount {
+	if a.Cents < 0 {
+		a.Cents = -a.Cents
+	}
+	return a
+}
+
+// Decimal formats the amount as a decimal with the decimals of its
+// currency, such as -1234.56 or 1234 for JPY.
+func (a Amount) Decimal() string {
+	sign, cents := "", a.Cents
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	exp := Exponent(a.Currency)
+	if exp == 0 {
+		return fmt.Sprintf("%s%d", sign, cents)
+	}
+	scale := pow10(exp)
+	return fmt.Sprintf("%s%d.%0*d", sign, cents/scale, exp, cents%scale)
+}
+
+// String formats the amount followed by its currency, such as 12.34 EUR.
+func (a Amount) String() string {
+	if a.Currency == "" {
+		return a.Decimal()
+	}
+	return a.Decimal() + " " + a.Currency
+}
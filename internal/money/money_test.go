@@ -0,0 +1,154 @@
+package money
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExponent(t *testing.T) {
+	tests := []struct {
+		currency string
+		want     int
+	}{
+		{"EUR", 2},
+		{"USD", 2},
+		{"", 2},
+		{"JPY", 0},
+		{"jpy", 0},
+		{"KRW", 0},
+		{"BHD", 3},
+		{"KWD", 3},
+		{"CLF", 4},
+	}
+	for _, tt := range tests {
+		if got := Exponent(tt.currency); got != tt.want {
+			t.Errorf("Exponent(%q) = %d, want %d", tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	plain := Format{}
+	halfEven := Format{Decimal: '.', Thousands: ',', Rounding: RoundHalfEven}
+	towardZero := Format{Decimal: '.', Thousands: ',', Rounding: RoundTowardZero}
+	exact := Format{Decimal: '.', Thousands: ',', Rounding: RoundExact}
+	tests := []struct {
+		name     string
+		in       string
+		currency string
+		format   Format
+		want     int64
+		wantErr  error
+	}{
+		// separators
+		{"decimal point", "12.34", "EUR", DecimalPoint, 1234, nil},
+		{"grouped thousands", "1,234,567.89", "EUR", DecimalPoint, 123456789, nil},
+		{"decimal comma", "1.234,56", "EUR", DecimalComma, 123456, nil},
+		{"space as group", "1 234.56", "EUR", DecimalPoint, 123456, nil},
+		{"no-break space as group", "1\u00a0234,56", "EUR", DecimalComma, 123456, nil},
+		{"narrow no-break space as group", "1\u202f234,56", "EUR", DecimalComma, 123456, nil},
+		{"zero format", "1234.5", "EUR", plain, 123450, nil},
+		{"group separator of a zero format", "1,234.5", "EUR", plain, 0, ErrInvalidAmount},
+		{"surrounding spaces", " 3.10 ", "EUR", DecimalPoint, 310, nil},
+		{"no whole units", ".5", "EUR", DecimalPoint, 50, nil},
+		{"no decimals", "7", "EUR", DecimalPoint, 700, nil},
+		{"trailing separator", "7.", "EUR", DecimalPoint, 700, nil},
+		// signs
+		{"minus", "-1,234.56", "EUR", DecimalPoint, -123456, nil},
+		{"plus", "+5", "EUR", DecimalPoint, 500, nil},
+		{"unicode minus", "\u221212,30", "EUR", DecimalComma, -1230, nil},
+		{"minus zero", "-0.00", "EUR", DecimalPoint, 0, nil},
+		{"double sign", "--1", "EUR", DecimalPoint, 0, ErrInvalidAmount},
+		{"trailing sign", "1-", "EUR", DecimalPoint, 0, ErrInvalidAmount},
+		// rounding
+		{"half away from zero", "0.125", "EUR", DecimalPoint, 13, nil},
+		{"negative half away from zero", "-0.125", "EUR", DecimalPoint, -13, nil},
+		{"below half away from zero", "0.1249", "EUR", DecimalPoint, 12, nil},
+		{"half even down", "0.125", "EUR", halfEven, 12, nil},
+		{"half even up", "0.135", "EUR", halfEven, 14, nil},
+		{"negative half even", "-0.135", "EUR", halfEven, -14, nil},
+		{"half even above half", "0.1251", "EUR", halfEven, 13, nil},
+		{"half even trailing zeros", "0.12500", "EUR", halfEven, 12, nil},
+		{"toward zero", "0.129", "EUR", towardZero, 12, nil},
+		{"negative toward zero", "-0.129", "EUR", towardZero, -12, nil},
+		{"exact", "0.12", "EUR", exact, 12, nil},
+		{"exact trailing zeros", "0.1200", "EUR", exact, 12, nil},
+		{"inexact", "0.125", "EUR", exact, 0, ErrInexactAmount},
+		// minor units of the currency
+		{"yen", "1,234", "JPY", DecimalPoint, 1234, nil},
+		{"yen lower case", "1,234", "jpy", DecimalPoint, 1234, nil},
+		{"yen half away from zero", "1234.5", "JPY", DecimalPoint, 1235, nil},
+		{"yen half even", "1234.5", "JPY", halfEven, 1234, nil},
+		{"yen half even up", "1235.5", "JPY", halfEven, 1236, nil},
+		{"yen exact", "100.00", "JPY", exact, 100, nil},
+		{"yen inexact", "100.5", "JPY", exact, 0, ErrInexactAmount},
+		{"dinar", "1.234", "BHD", DecimalPoint, 1234, nil},
+		{"dinar padded", "12.5", "KWD", DecimalPoint, 12500, nil},
+		{"dinar half even", "1.2345", "BHD", halfEven, 1234, nil},
+		{"dinar decimal comma", "-1.234,567", "KWD", DecimalComma, -1234567, nil},
+		// invalid amounts
+		{"empty", "", "EUR", DecimalPoint, 0, ErrInvalidAmount},
+		{"sign only", "-", "EUR", DecimalPoint, 0, ErrInvalidAmount},
+		{"letters", "abc", "EUR", DecimalPoint, 0, ErrInvalidAmount},
+		{"two decimal separators", "1.2.3", "EUR", DecimalPoint, 0, ErrInvalidAmount},
+		{"exponent", "1e3", "EUR", DecimalPoint, 0, ErrInvalidAmount},
+		// grouping, a misplaced separator is not a thousands separator
+		{"decimal point in a decimal comma format", "12.50", "EUR", DecimalComma, 0, ErrInvalidAmount},
+		{"single decimal in a decimal comma format", "1.5", "EUR", DecimalComma, 0, ErrInvalidAmount},
+		{"decimal comma in a decimal point format", "12,50", "EUR", DecimalPoint, 0, ErrInvalidAmount},
+		{"group of four", "1.2345,00", "EUR", DecimalComma, 0, ErrInvalidAmount},
+		{"group of two", "1,234,56.00", "EUR", DecimalPoint, 0, ErrInvalidAmount},
+		{"first group of four", "1234,567.00", "EUR", DecimalPoint, 0, ErrInvalidAmount},
+		{"leading separator", ",234.00", "EUR", DecimalPoint, 0, ErrInvalidAmount},
+		{"trailing group separator", "1,234,.00", "EUR", DecimalPoint, 0, ErrInvalidAmount},
+		{"double separator", "1,,234", "EUR", DecimalPoint, 0, ErrInvalidAmount},
+		{"grouped space in a group", "1 23.00", "EUR", DecimalPoint, 0, ErrInvalidAmount},
+		{"grouped whole units", "1.234", "EUR", DecimalComma, 123400, nil},
+		{"grouped millions", "12.345.678,90", "EUR", DecimalComma, 1234567890, nil},
+		{"ungrouped", "1234567,89", "EUR", DecimalComma, 123456789, nil},
+		{"largest", "92233720368547757", "EUR", DecimalPoint, 9223372036854775700, nil},
+		{"overflow", "92233720368547758", "EUR", DecimalPoint, 0, ErrInvalidAmount},
+		{"larger in yen", "92233720368547758", "JPY", DecimalPoint, 92233720368547758, nil},
+		{"overflow in dinar", "9223372036854776", "BHD", DecimalPoint, 0, ErrInvalidAmount},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.in, tt.currency, tt.format)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse(%q) error = %v, want %v", tt.in, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Cents != tt.want {
+				t.Errorf("Parse(%q) = %d, want %d", tt.in, got.Cents, tt.want)
+			}
+			if got.Currency != New(0, tt.currency).Currency {
+				t.Errorf("Parse(%q) currency = %q, want %q", tt.in, got.Currency, tt.currency)
+			}
+		})
+	}
+}
+
+func TestAmountDecimal(t *testing.T) {
+	tests := []struct {
+		amount Amount
+		want   string
+		str    string
+	}{
+		{New(1234, "EUR"), "12.34", "12.34 EUR"},
+		{New(-5, "eur"), "-0.05", "-0.05 EUR"},
+		{New(0, ""), "0.00", "0.00"},
+		{New(1234, "JPY"), "1234", "1234 JPY"},
+		{New(-1234, "BHD"), "-1.234", "-1.234 BHD"},
+		{New(7, "KWD"), "0.007", "0.007 KWD"},
+	}
+	for _, tt := range tests {
+		if got := tt.amount.Decimal(); got != tt.want {
+			t.Errorf("%#v.Decimal() = %q, want %q", tt.amount, got, tt.want)
+		}
+		if got := tt.amount.String(); got != tt.str {
+			t.Errorf("%#v.String() = %q, want %q", tt.amount, got, tt.str)
+		}
+	}
+}
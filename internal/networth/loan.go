@@ -0,0 +1,73 @@
+package networth
+
+import (
+	"math"
+	"time"
+)
+
+type LoanMethod string
+
+const (
+	// Annuity loans are repaid in equal monthly payments of interest and
+	// principal.
+	Annuity LoanMethod = "annuity"
+	// Linear loans repay the same principal every month.
+	Linear LoanMethod = "linear"
+)
+
+// Loan is the amortisation schedule of a liability. Payments are due monthly
+// on the day of the month of the start date, the first one a month after
+// it.
+type Loan struct {
+	PrincipalCents int64 `json:"principalCents"`
+	// RateBasisPoints is the yearly interest rate in hundredths of a
+	// percent, 3.85% is 385.
+	RateBasisPoints int        `json:"rateBasisPoints"`
+	StartDate       time.Time  `json:"startDate"`
+	TermMonths      int        `json:"termMonths"`
+	Method          LoanMethod `json:"method"`
+}
+
+func (l *Loan) validate() error {
+	if l.PrincipalCents <= 0 || l.TermMonths <= 0 || l.RateBasisPoints < 0 || l.StartDate.IsZero() {
+		return ErrInvalidLoan
+	}
+	switch l.Method {
+	case Annuity, Linear:
+		return nil
+	default:
+		return ErrInvalidLoan
+	}
+}
+
+// Payments returns the number of payments made by the end of the day.
+func (l *Loan) Payments(date time.Time) int {
+	start, date := day(l.StartDate), day(date)
+	months := (date.Year()-start.Year())*12 + int(date.Month()) - int(start.Month())
+	if date.Day() < start.Day() && date.Day() != lastDay(date) {
+		months--
+	}
+	return max(0, min(months, l.TermMonths))
+}
+
+// OutstandingCents returns the principal still owed at the end of the day,
+// zero before the loan started.
+func (l *Loan) OutstandingCents(date time.Time) int64 {
+	if day(date).Before(day(l.StartDate)) {
+		return 0
+	}
+	k, n := float64(l.Payments(date)), float64(l.TermMonths)
+	p := float64(l.PrincipalCents)
+	r := float64(l.RateBasisPoints) / 10000 / 12
+	if l.Method == Linear || r == 0 {
+		return int64(math.Round(p - p*k/n))
+	}
+	// balance after k annuity payments
+	growth := math.Pow(1+r, k)
+	payment := p * r / (1 - math.Pow(1+r, -n))
+	return max(0, int64(math.Round(p*growth-payment*(growth-1)/r)))
+}
+
+func lastDay(date time.Time) int {
+	return time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
@@ -0,0 +1,184 @@
+package networth
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Class is the asset class of a component of the net worth.
+type Class string
+
+const (
+	ClassCash        Class = "cash"
+	ClassSavings     Class = "savings"
+	ClassInvestments Class = "investments"
+	ClassProperty    Class = "property"
+	ClassVehicle     Class = "vehicle"
+	ClassOtherAsset  Class = "other_asset"
+	ClassMortgage    Class = "mortgage"
+	ClassLoan        Class = "loan"
+	ClassOtherDebt   Class = "other_liability"
+)
+
+// Liability reports whether components of the class lower the net worth.
+func (c Class) Liability() bool {
+	return c == ClassMortgage || c == ClassLoan || c == ClassOtherDebt
+}
+
+var (
+	ErrItemNotFound = fmt.Errorf("net worth item not found")
+	ErrInvalidClass = fmt.Errorf("class must be property, vehicle, investments, other_asset, mortgage, loan or other_liability")
+	ErrInvalidName  = fmt.Errorf("name is required")
+	ErrInvalidLoan  = fmt.Errorf("loans need a positive principal and term, a start date and a non-negative rate")
+	ErrLoanOnAsset  = fmt.Errorf("only liabilities can have an amortisation schedule")
+	ErrHoldingClass = fmt.Errorf("only investments can hold a symbol")
+)
+
+// ParseItemClass returns the class of a manually entered item, accounts
+// provide the cash and savings classes.
+func ParseItemClass(s string) (Class, error) {
+	switch c := Class(s); c {
+	case ClassInvestments, ClassProperty, ClassVehicle, ClassOtherAsset, ClassMortgage, ClassLoan, ClassOtherDebt:
+		return c, nil
+	default:
+		return "", ErrInvalidClass
+	}
+}
+
+// Item is an asset or liability entered by hand, e.g. a house, a mortgage or
+// a car loan. Its value on a day is the latest valuation on or before it.
+// Loans with an amortisation schedule are valued at their outstanding
+// principal instead and investments holding a symbol at the quantity times
+// the close of that day.
+type Item struct {
+	ID    uuid.UUID
+	Name  string
+	Class Class
+	// Symbol and QuantityMicros (millionths of a unit) describe an
+	// investment holding.
+	Symbol         string
+	QuantityMicros int64
+	Loan           *Loan
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func NewItem(name string, class Class, symbol string, quantityMicros int64, loan *Loan) (*Item, error) {
+	now := time.Now().UTC()
+	item := &Item{
+		ID:             uuid.New(),
+		Name:           name,
+		Class:          class,
+		Symbol:         symbol,
+		QuantityMicros: quantityMicros,
+		Loan:           loan,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := item.validate(); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (i *Item) validate() error {
+	if i.Name == "" {
+		return ErrInvalidName
+	}
+	if _, err := ParseItemClass(string(i.Class)); err != nil {
+		return err
+	}
+	if i.Symbol != "" && i.Class != ClassInvestments {
+		return ErrHoldingClass
+	}
+	if i.Loan != nil {
+		if !i.Class.Liability() {
+			return ErrLoanOnAsset
+		}
+		return i.Loan.validate()
+	}
+	return nil
+}
+
+// Valuation is the value of an item at the end of a day. Values are
+// positive, liabilities included.
+type Valuation struct {
+	ItemID     uuid.UUID `db:"item_id"`
+	Date       time.Time `db:"date"`
+	ValueCents int64     `db:"value_cents"`
+}
+
+// Component is the value of a single account or item within the net worth.
+// Source identifies it, e.g. account:NL91ABNA0417164300 or item:<id>.
+type Component struct {
+	Source string `db:"source"`
+	Name   string `db:"name"`
+	Class  Class  `db:"class"`
+	// ValueCents is positive for liabilities too, it is in the reporting
+	// currency.
+	ValueCents int64 `db:"value_cents"`
+	// Currency is the currency the component is held in, OriginalCents its
+	// value in that currency.
+	Currency      string `db:"currency"`
+	OriginalCents int64  `db:"original_cents"`
+}
+
+// Point is the net worth at the end of a day.
+type Point struct {
+	Date       time.Time
+	Components []Component
+	// Snapshot is set when the point was read from a materialised snapshot,
+	// Provisional when that snapshot was backfilled and not yet confirmed.
+	Snapshot    bool
+	Provisional bool
+}
+
+func (p Point) AssetsCents() int64 {
+	var sum int64
+	for _, c := range p.Components {
+		if !c.Class.Liability() {
+			sum += c.ValueCents
+		}
+	}
+	return sum
+}
+
+func (p Point) LiabilitiesCents() int64 {
+	var sum int64
+	for _, c := range p.Components {
+		if c.Class.Liability() {
+			sum += c.ValueCents
+		}
+	}
+	return sum
+}
+
+func (p Point) NetWorthCents() int64 {
+	return p.AssetsCents() - p.LiabilitiesCents()
+}
+
+// ByClass sums the components per class, liabilities are positive as well.
+func (p Point) ByClass() map[Class]int64 {
+	res := map[Class]int64{}
+	for _, c := range p.Components {
+		res[c.Class] += c.ValueCents
+	}
+	return res
+}
+
+func sortComponents(cs []Component) {
+	slices.SortFunc(cs, func(a, b Component) int {
+		if c := cmp.Compare(a.Class, b.Class); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Source, b.Source)
+	})
+}
+
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
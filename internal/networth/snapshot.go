@@ -0,0 +1,191 @@
+package networth
+
+import (
+	"context"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/report"
+)
+
+// Snapshot is a materialised component of the net worth at the end of a
+// month. Snapshots are never recomputed, so corrections to prices or
+// transactions afterwards do not change the history. Backfilled snapshots
+// are the exception, they are provisional and recomputed until confirmed.
+type Snapshot struct {
+	Date time.Time `db:"date"`
+	Component
+	// Provisional is set on snapshots of months that had ended long before
+	// they were taken, statements of those months may still be imported.
+	Provisional bool      `db:"provisional"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+// Shared interfaces used by multiple use cases
+
+type SnapshotLister interface {
+	// Snapshots returns the snapshots from from through to.
+	Snapshots(ctx context.Context, from, to time.Time) ([]*Snapshot, error)
+}
+
+type Valuer interface {
+	Handle(ctx context.Context, dates []time.Time) ([]Point, error)
+}
+
+// Single-use interfaces only used by SnapshotHandler
+
+type SnapshotSaver interface {
+	// SaveSnapshots stores the snapshots of a day, replacing its
+	// provisional snapshots, unless the day already has final snapshots.
+	SaveSnapshots(ctx context.Context, date time.Time, snapshots []*Snapshot) error
+}
+
+// Single-use interfaces only used by ConfirmHandler
+
+type SnapshotConfirmer interface {
+	// ConfirmSnapshots makes the provisional snapshots through the day
+	// final and returns the number of days confirmed.
+	ConfirmSnapshots(ctx context.Context, through time.Time) (int, error)
+}
+
+// SnapshotHandler materialises the net worth at the end of every month.
+type SnapshotHandler struct {
+	sl       SnapshotLister
+	ss       SnapshotSaver
+	v        Valuer
+	backfill int
+}
+
+// NewSnapshotHandler creates a SnapshotHandler that fills in the missing
+// snapshots of the last backfillMonths months, 24 when not positive.
+func NewSnapshotHandler(sl SnapshotLister, ss SnapshotSaver, v Valuer, backfillMonths int) *SnapshotHandler {
+	if backfillMonths <= 0 {
+		backfillMonths = 24
+	}
+	return &SnapshotHandler{sl: sl, ss: ss, v: v, backfill: backfillMonths}
+}
+
+// Handle snapshots the month ends before today that have no final snapshot
+// yet and returns how many it took. The month that just ended is final,
+// earlier months are backfilled provisionally and recomputed on every run
+// until they are confirmed.
+func (h *SnapshotHandler) Handle(ctx context.Context, today time.Time) (int, error) {
+	current := report.Month.Start(today)
+	last := current.AddDate(0, 0, -1)
+	from := current.AddDate(0, -h.backfill, -1)
+	existing, err := h.sl.Snapshots(ctx, from, current)
+	if err != nil {
+		return 0, err
+	}
+	taken := map[time.Time]bool{}
+	for _, s := range existing {
+		if !s.Provisional {
+			taken[day(s.Date)] = true
+		}
+	}
+	var missing []time.Time
+	for start := current.AddDate(0, -h.backfill, 0); start.Before(current); start = report.Month.Next(start) {
+		if end := report.Month.End(start); !taken[end] {
+			missing = append(missing, end)
+		}
+	}
+	if len(missing) == 0 {
+		return 0, nil
+	}
+	points, err := h.v.Handle(ctx, missing)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	for _, p := range points {
+		snapshots := make([]*Snapshot, 0, len(p.Components))
+		for _, c := range p.Components {
+			snapshots = append(snapshots, &Snapshot{Date: p.Date, Component: c, Provisional: !p.Date.Equal(last), CreatedAt: now})
+		}
+		if err := h.ss.SaveSnapshots(ctx, p.Date, snapshots); err != nil {
+			return 0, err
+		}
+	}
+	return len(points), nil
+}
+
+// ConfirmHandler makes backfilled snapshots final once the statements of
+// their months have been imported.
+type ConfirmHandler struct {
+	sc SnapshotConfirmer
+}
+
+func NewConfirmHandler(sc SnapshotConfirmer) *ConfirmHandler {
+	return &ConfirmHandler{sc: sc}
+}
+
+// Handle confirms the provisional snapshots through the day and returns the
+// number of month ends confirmed.
+func (h *ConfirmHandler) Handle(ctx context.Context, through time.Time) (int, error) {
+	return h.sc.ConfirmSnapshots(ctx, day(through))
+}
+
+// SeriesHandler reports the net worth at the end of every interval.
+type SeriesHandler struct {
+	sl SnapshotLister
+	v  Valuer
+}
+
+func NewSeriesHandler(sl SnapshotLister, v Valuer) *SeriesHandler {
+	return &SeriesHandler{sl: sl, v: v}
+}
+
+// Handle returns the net worth at the end of every interval from from
+// through to, the last point is at to itself when it falls within an
+// interval. Days with a snapshot are read from it, other days are valued
+// on the fly.
+func (h *SeriesHandler) Handle(ctx context.Context, from, to time.Time, interval report.Interval) ([]Point, error) {
+	from, to = day(from), day(to)
+	var dates []time.Time
+	for start := interval.Start(from); !start.After(to); start = interval.Next(start) {
+		end := interval.End(start)
+		if end.After(to) {
+			end = to
+		}
+		dates = append(dates, end)
+	}
+	if len(dates) == 0 {
+		return []Point{}, nil
+	}
+	snapshots, err := h.sl.Snapshots(ctx, dates[0], dates[len(dates)-1])
+	if err != nil {
+		return nil, err
+	}
+	stored := map[time.Time][]Component{}
+	provisional := map[time.Time]bool{}
+	for _, s := range snapshots {
+		stored[day(s.Date)] = append(stored[day(s.Date)], s.Component)
+		provisional[day(s.Date)] = provisional[day(s.Date)] || s.Provisional
+	}
+	points := make([]Point, len(dates))
+	var live []time.Time
+	for i, d := range dates {
+		points[i].Date = d
+		if cs, ok := stored[d]; ok {
+			points[i].Components = cs
+			points[i].Snapshot = true
+			points[i].Provisional = provisional[d]
+			continue
+		}
+		live = append(live, d)
+	}
+	valued, err := h.v.Handle(ctx, live)
+	if err != nil {
+		return nil, err
+	}
+	byDate := map[time.Time]Point{}
+	for _, p := range valued {
+		byDate[p.Date] = p
+	}
+	for i := range points {
+		if !points[i].Snapshot {
+			points[i] = byDate[points[i].Date]
+		}
+		sortComponents(points[i].Components)
+	}
+	return points, nil
+}
@@ -0,0 +1,116 @@
+package networth
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// memSnapshots stores snapshots the way the database does.
+type memSnapshots map[time.Time][]*Snapshot
+
+func (m memSnapshots) Snapshots(ctx context.Context, from, to time.Time) ([]*Snapshot, error) {
+	var res []*Snapshot
+	for d, snapshots := range m {
+		if !d.Before(from) && !d.After(to) {
+			res = append(res, snapshots...)
+		}
+	}
+	return res, nil
+}
+
+func (m memSnapshots) SaveSnapshots(ctx context.Context, date time.Time, snapshots []*Snapshot) error {
+	if existing := m[date]; len(existing) > 0 && !existing[0].Provisional {
+		return nil
+	}
+	m[date] = snapshots
+	return nil
+}
+
+func (m memSnapshots) ConfirmSnapshots(ctx context.Context, through time.Time) (int, error) {
+	n := 0
+	for d, snapshots := range m {
+		if d.After(through) || !snapshots[0].Provisional {
+			continue
+		}
+		for _, s := range snapshots {
+			s.Provisional = false
+		}
+		n++
+	}
+	return n, nil
+}
+
+// fakeValuer values the only account at balance on every day.
+type fakeValuer struct {
+	balance int64
+}
+
+func (v *fakeValuer) Handle(ctx context.Context, dates []time.Time) ([]Point, error) {
+	points := make([]Point, 0, len(dates))
+	for _, d := range dates {
+		points = append(points, Point{Date: d, Components: []Component{{Source: "account:NL91", Class: ClassCash, ValueCents: v.balance}}})
+	}
+	return points, nil
+}
+
+func TestSnapshotHandler(t *testing.T) {
+	store := memSnapshots{}
+	valuer := &fakeValuer{balance: 1000}
+	h := NewSnapshotHandler(store, store, valuer, 3)
+	dec, jan, feb := date(2024, 12, 31), date(2025, 1, 31), date(2025, 2, 28)
+
+	// run snapshots the months of the backfill, the month that just ended
+	// is final and earlier months are provisional
+	run := func(today time.Time, want int) {
+		t.Helper()
+		n, err := h.Handle(context.Background(), today)
+		if err != nil {
+			t.Fatalf("Handle(%s) error = %v", today.Format(time.DateOnly), err)
+		}
+		if n != want {
+			t.Errorf("Handle(%s) took %d snapshots, want %d", today.Format(time.DateOnly), n, want)
+		}
+	}
+	assert := func(d time.Time, value int64, provisional bool) {
+		t.Helper()
+		snapshots := store[d]
+		if len(snapshots) != 1 {
+			t.Fatalf("%s has %d snapshots, want 1", d.Format(time.DateOnly), len(snapshots))
+		}
+		if s := snapshots[0]; s.ValueCents != value || s.Provisional != provisional {
+			t.Errorf("%s snapshot %d provisional %v, want %d provisional %v", d.Format(time.DateOnly), s.ValueCents, s.Provisional, value, provisional)
+		}
+	}
+
+	run(date(2025, 3, 10), 3)
+	assert(dec, 1000, true)
+	assert(jan, 1000, true)
+	assert(feb, 1000, false)
+
+	// older statements are imported, the backfilled months are recomputed
+	// and the final month is kept
+	valuer.balance = 2000
+	run(date(2025, 3, 11), 2)
+	assert(dec, 2000, true)
+	assert(jan, 2000, true)
+	assert(feb, 1000, false)
+
+	// confirmed months are final and no longer change with the prices
+	n, err := NewConfirmHandler(store).Handle(context.Background(), jan)
+	if err != nil || n != 2 {
+		t.Fatalf("confirm Handle() = %d, %v, want 2", n, err)
+	}
+	valuer.balance = 3000
+	run(date(2025, 3, 12), 0)
+	assert(dec, 2000, false)
+	assert(jan, 2000, false)
+
+	// the next month that ends is final right away
+	run(date(2025, 4, 1), 1)
+	assert(date(2025, 3, 31), 3000, false)
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
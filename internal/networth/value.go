@@ -0,0 +1,177 @@
+package networth
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/balance"
+	"github.com/lennardclaproth/my-finances-tracker/internal/fx"
+	"github.com/lennardclaproth/my-finances-tracker/internal/marketdata"
+)
+
+// Single-use interfaces only used by ValueHandler
+
+type AccountLister interface {
+	Accounts(ctx context.Context) ([]*balance.Account, error)
+}
+
+type DailyBalancer interface {
+	Daily(ctx context.Context, account string, from, to time.Time) ([]balance.Daily, error)
+}
+
+type ItemLister interface {
+	ListItems(ctx context.Context) ([]*Item, error)
+}
+
+type ValuationLister interface {
+	// Valuations returns the valuations of all items, oldest first.
+	Valuations(ctx context.Context) ([]*Valuation, error)
+}
+
+type PriceFetcher interface {
+	// LatestOn returns the last price of the symbol on or before the date,
+	// nil when there is none.
+	LatestOn(ctx context.Context, symbol string, date time.Time) (*marketdata.History, error)
+}
+
+// ValueHandler values the accounts and items on given days in the reporting
+// currency.
+type ValueHandler struct {
+	al       AccountLister
+	db       DailyBalancer
+	il       ItemLister
+	vl       ValuationLister
+	pf       PriceFetcher
+	rf       fx.RateFetcher
+	currency string
+}
+
+// NewValueHandler creates a ValueHandler, pf may be nil in which case
+// holdings are valued at their latest valuation. Account balances are
+// converted to the currency with the rates of the valued days, items are
+// entered in it.
+func NewValueHandler(al AccountLister, db DailyBalancer, il ItemLister, vl ValuationLister, pf PriceFetcher, rf fx.RateFetcher, currency string) *ValueHandler {
+	return &ValueHandler{al: al, db: db, il: il, vl: vl, pf: pf, rf: rf, currency: currency}
+}
+
+// Handle returns the net worth at the end of each of the days.
+func (h *ValueHandler) Handle(ctx context.Context, dates []time.Time) ([]Point, error) {
+	if len(dates) == 0 {
+		return []Point{}, nil
+	}
+	points := make([]Point, len(dates))
+	for i, d := range dates {
+		points[i] = Point{Date: day(d)}
+	}
+	from := slices.MinFunc(points, func(a, b Point) int { return a.Date.Compare(b.Date) }).Date
+	to := slices.MaxFunc(points, func(a, b Point) int { return a.Date.Compare(b.Date) }).Date
+
+	accounts, err := h.al.Accounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conv := fx.NewConverter(h.rf)
+	for _, a := range accounts {
+		days, err := h.db.Daily(ctx, a.ID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		for i := range points {
+			d := days[int(points[i].Date.Sub(from).Hours()/24)]
+			if d.BalanceCents == 0 {
+				continue
+			}
+			value, err := conv.Convert(ctx, d.BalanceCents, a.Currency, h.currency, points[i].Date)
+			if err != nil {
+				return nil, err
+			}
+			c := accountComponent(a, d.BalanceCents)
+			c.ValueCents = value
+			points[i].Components = append(points[i].Components, c)
+		}
+	}
+
+	items, err := h.il.ListItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+	valuations, err := h.vl.Valuations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byItem := map[uuid.UUID][]*Valuation{}
+	for _, v := range valuations {
+		byItem[v.ItemID] = append(byItem[v.ItemID], v)
+	}
+	for _, item := range items {
+		for i := range points {
+			value, ok, err := h.itemValue(ctx, item, byItem[item.ID], points[i].Date)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			points[i].Components = append(points[i].Components, Component{
+				Source:        "item:" + item.ID.String(),
+				Name:          item.Name,
+				Class:         item.Class,
+				ValueCents:    value,
+				Currency:      h.currency,
+				OriginalCents: value,
+			})
+		}
+	}
+	for i := range points {
+		sortComponents(points[i].Components)
+	}
+	return points, nil
+}
+
+// itemValue returns the value of the item at the end of the day, false when
+// it has none yet.
+func (h *ValueHandler) itemValue(ctx context.Context, item *Item, valuations []*Valuation, date time.Time) (int64, bool, error) {
+	if item.Loan != nil {
+		if date.Before(day(item.Loan.StartDate)) {
+			return 0, false, nil
+		}
+		return item.Loan.OutstandingCents(date), true, nil
+	}
+	if item.Symbol != "" && h.pf != nil {
+		price, err := h.pf.LatestOn(ctx, item.Symbol, date)
+		if err != nil {
+			return 0, false, err
+		}
+		if price != nil {
+			return price.Close.ValueCents(item.QuantityMicros), true, nil
+		}
+	}
+	var latest *Valuation
+	for _, v := range valuations {
+		if day(v.Date).After(date) {
+			break
+		}
+		latest = v
+	}
+	if latest == nil {
+		return 0, false, nil
+	}
+	return latest.ValueCents, true, nil
+}
+
+func accountComponent(a *balance.Account, balanceCents int64) Component {
+	class := ClassCash
+	switch a.Type {
+	case balance.AccountSavings:
+		class = ClassSavings
+	case balance.AccountInvestment:
+		class = ClassInvestments
+	}
+	name := a.Name
+	if name == "" {
+		name = a.ID
+	}
+	return Component{Source: "account:" + a.ID, Name: name, Class: class, ValueCents: balanceCents, Currency: a.Currency, OriginalCents: balanceCents}
+}
@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+)
+
+// Message is a notification for the user. Kind identifies what it is about,
+// e.g. "budget_alert", Data carries the details for machine consumers.
+type Message struct {
+	Kind  string         `json:"kind"`
+	Title string         `json:"title"`
+	Body  string         `json:"body"`
+	Data  map[string]any `json:"data,omitempty"`
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, m Message) error
+}
+
+// LogNotifier writes notifications to the log.
+type LogNotifier struct {
+	log logging.Logger
+}
+
+func NewLogNotifier(log logging.Logger) *LogNotifier {
+	return &LogNotifier{log: log}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, m Message) error {
+	n.log.Info(ctx, m.Title, "kind", m.Kind, "body", m.Body)
+	return nil
+}
+
+// WebhookNotifier posts notifications as JSON to a URL.
+type WebhookNotifier struct {
+	http *http.Client
+	url  string
+}
+
+func NewWebhookNotifier(url string, timeout time.Duration) *WebhookNotifier {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &WebhookNotifier{http: &http.Client{Timeout: timeout}, url: url}
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, m Message) error {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := n.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("notify: webhook call failed: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("notify: webhook call failed with status code %d", res.StatusCode)
+	}
+	return nil
+}
+
+// Multi sends notifications to all notifiers, it fails when any of them
+// fails.
+type Multi []Notifier
+
+func (m Multi) Notify(ctx context.Context, msg Message) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,224 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"iter"
+	"math/big"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/money"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// abnAmroColumns is the column order of the TXT export, which has no header.
+var abnAmroColumns = []string{"accountNumber", "mutationcode", "transactiondate", "valuedate", "startsaldo", "endsaldo", "amount", "description"}
+
+// sepaField matches the keys of the SEPA fields in a description such as
+// /TRTP/SEPA OVERBOEKING/IBAN/NL91ABNA0417164300/BIC/ABNANL2A/NAME/J Jansen/REMI/Rent/EREF/NOTPROVIDED.
+var sepaField = regexp.MustCompile(`/(TRTP|CSID|NAME|MARF|REMI|IBAN|BIC|EREF|ORDP|BENM|ID|SWOC|ISDT|RTRN|PREF|IREF|CNTP|ULTC|ULTD|PURP)/`)
+
+// sepaLabel matches the labels of the older description layout such as
+// SEPA Overboeking IBAN: NL91ABNA0417164300 BIC: ABNANL2A Naam: J Jansen Omschrijving: Rent.
+var sepaLabel = regexp.MustCompile(`\b(IBAN|BIC|Naam|Name|Omschrijving|Description|Kenmerk|Machtiging|Incassant|Betalingskenm\.):\s`)
+
+// AbnAmroParser parses the tab separated TXT exports of ABN AMRO. The XLS
+// export holds the same columns, it has to be saved as tab separated text
+// first, uploads of the XLS file itself are rejected.
+type AbnAmroParser struct {
+	headerToColumn map[string]int
+}
+
+func NewAbnAmroParser() *AbnAmroParser {
+	return &AbnAmroParser{
+		headerToColumn: make(map[string]int),
+	}
+}
+
+func (p *AbnAmroParser) ParseAll(rc io.ReadCloser) (iter.Seq2[int, transaction.TransactionData], error) {
+	data, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(data, []byte{0xd0, 0xcf, 0x11, 0xe0}) {
+		return nil, fmt.Errorf("the ABN AMRO XLS export is not supported, save it as tab separated text or use the TXT export")
+	}
+	csvReader := csv.NewReader(withoutBOM(bytes.NewReader(data)))
+	csvReader.Comma = '\t'
+	csvReader.LazyQuotes = true
+	csvReader.FieldsPerRecord = -1
+	first, err := csvReader.Read()
+	if err != nil {
+		return nil, err
+	}
+	// The TXT export starts with the first transaction, the XLS export
+	// saved as text with a header
+	hasHeader := p.parseHeader(first)
+	if _, ok := p.headerToColumn["amount"]; !ok {
+		return nil, fmt.Errorf("not an ABN AMRO export, missing column %q", "amount")
+	}
+	seq := func(yield func(int, transaction.TransactionData) bool) {
+		rowNumber := 1
+		if !hasHeader {
+			if td, err := p.ParseRow(first); err == nil && !yield(rowNumber, td) {
+				return
+			}
+			rowNumber++
+		}
+		for ; ; rowNumber++ {
+			record, err := csvReader.Read()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				// Decide policy: skip bad CSV row reads
+				continue
+			}
+			td, err := p.ParseRow(record)
+			if err != nil {
+				// Decide policy: skip rows that fail to parse
+				continue
+			}
+			if !yield(rowNumber, td) {
+				return
+			}
+		}
+	}
+	return seq, nil
+}
+
+// parseHeader maps the columns by the header when the first record is one
+// and by the fixed order of the TXT export otherwise. It reports whether
+// the record was a header.
+func (p *AbnAmroParser) parseHeader(first []string) bool {
+	p.headerToColumn = make(map[string]int, len(abnAmroColumns))
+	if slices.ContainsFunc(first, func(h string) bool { return strings.EqualFold(strings.TrimSpace(h), "transactiondate") }) {
+		for i, h := range first {
+			p.headerToColumn[strings.ToLower(strings.TrimSpace(h))] = i
+		}
+		return true
+	}
+	for i, h := range abnAmroColumns {
+		p.headerToColumn[strings.ToLower(h)] = i
+	}
+	return false
+}
+
+// ParseRow parses a single ABN AMRO row into a TransactionData.
+func (p *AbnAmroParser) ParseRow(record []string) (transaction.TransactionData, error) {
+	if len(p.headerToColumn) == 0 {
+		return transaction.TransactionData{}, fmt.Errorf("header map not initialized")
+	}
+	currency := p.field(record, "mutationcode")
+	amount, err := money.Parse(p.field(record, "amount"), currency, money.DecimalComma)
+	if err != nil {
+		return transaction.TransactionData{}, err
+	}
+	direction := transaction.CashIn
+	if amount.Cents < 0 {
+		direction = transaction.CashOut
+	}
+
+	var balanceAfter *int64
+	if s := p.field(record, "endsaldo"); s != "" {
+		balance, err := money.Parse(s, currency, money.DecimalComma)
+		if err != nil {
+			return transaction.TransactionData{}, fmt.Errorf("invalid end balance: %w", err)
+		}
+		balanceAfter = &balance.Cents
+	}
+
+	date, err := time.Parse("20060102", p.field(record, "transactiondate"))
+	if err != nil {
+		return transaction.TransactionData{}, fmt.Errorf("invalid date: %w", err)
+	}
+
+	td := transaction.TransactionData{
+		Source:    "ABN AMRO",
+		Direction: direction,
+		Amount:    amount.Abs(),
+		Date:      date,
+
+		Account:           abnAmroIBAN(p.field(record, "accountnumber")),
+		BalanceAfterCents: balanceAfter,
+	}
+	td.Description, td.Note, td.CounterpartyIBAN = parseAbnAmroDescription(p.field(record, "description"))
+	return td, nil
+}
+
+// parseAbnAmroDescription returns the name, the remittance information and
+// the IBAN of the counterparty of a SEPA description. Other descriptions,
+// such as card payments, are returned as the name with their spacing
+// collapsed.
+func parseAbnAmroDescription(s string) (name, remittance, iban string) {
+	fields := map[string]string{}
+	if matches := sepaField.FindAllStringSubmatchIndex(s, -1); len(matches) > 0 && matches[0][0] == 0 {
+		for i, m := range matches {
+			end := len(s)
+			if i+1 < len(matches) {
+				end = matches[i+1][0]
+			}
+			fields[s[m[2]:m[3]]] = strings.TrimSpace(s[m[1]:end])
+		}
+		name, remittance, iban = fields["NAME"], fields["REMI"], fields["IBAN"]
+	} else if matches := sepaLabel.FindAllStringSubmatchIndex(s, -1); len(matches) > 0 {
+		for i, m := range matches {
+			end := len(s)
+			if i+1 < len(matches) {
+				end = matches[i+1][0]
+			}
+			fields[strings.ToLower(s[m[2]:m[3]])] = strings.Join(strings.Fields(s[m[1]:end]), " ")
+		}
+		name, iban = fields["naam"], fields["iban"]
+		if name == "" {
+			name = fields["name"]
+		}
+		remittance = fields["omschrijving"]
+		if remittance == "" {
+			remittance = fields["description"]
+		}
+	}
+	if name == "" {
+		name = strings.Join(strings.Fields(s), " ")
+	}
+	return name, remittance, strings.ReplaceAll(iban, " ", "")
+}
+
+// abnAmroIBAN turns the account number of the export into the IBAN of the
+// account, so transfers between accounts are recognised. Numbers that are
+// not a plain ABN AMRO account number are returned as is.
+func abnAmroIBAN(account string) string {
+	if len(account) == 0 || len(account) > 10 || strings.Trim(account, "0123456789") != "" {
+		return account
+	}
+	bban := "ABNA" + strings.Repeat("0", 10-len(account)) + account
+	// ISO 13616 check digits: the BBAN followed by the country code and
+	// 00, letters as numbers from A=10, modulo 97
+	var digits strings.Builder
+	for _, r := range bban + "NL00" {
+		if r >= 'A' && r <= 'Z' {
+			fmt.Fprintf(&digits, "%d", r-'A'+10)
+			continue
+		}
+		digits.WriteRune(r)
+	}
+	n, _ := new(big.Int).SetString(digits.String(), 10)
+	check := 98 - new(big.Int).Mod(n, big.NewInt(97)).Int64()
+	return fmt.Sprintf("NL%02d%s", check, bban)
+}
+
+// field returns the trimmed value of the column, or an empty string when it
+// is missing.
+func (p *AbnAmroParser) field(record []string, header string) string {
+	i, ok := p.headerToColumn[header]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+// abnAmroStatement is a TXT export of ABN AMRO with a SEPA transfer, a
+// transfer in the older labelled layout, a card payment and a row with an
+// invalid date. The export has no header and separates the fields by tabs.
+const abnAmroStatement = "417164300\tEUR\t20240105\t20240105\t1.000,00\t900,00\t-100,00\t/TRTP/SEPA OVERBOEKING/IBAN/NL20INGB0001234567/BIC/INGBNL2A/NAME/J Jansen/REMI/Huur januari/EREF/NOTPROVIDED\n" +
+	"417164300\tEUR\t20240106\t20240106\t900,00\t3.400,00\t2.500,00\tSEPA Overboeking                 IBAN: NL20INGB0001234567        BIC: INGBNL2A                    Naam: ACME BV                    Omschrijving: Salaris januari\n" +
+	"417164300\tEUR\t20240107\t20240108\t3.400,00\t3.387,71\t-12,29\tBEA   NR:AB123C   07.01.24/10.12 Albert Heijn 1234,PAS123\n" +
+	"417164300\tEUR\t2024-01-09\t20240109\t3.387,71\t3.377,71\t-10,00\tBEA   NR:XY987Z   09.01.24/12.00 Bakker Bart\n"
+
+func TestAbnAmroParseAll(t *testing.T) {
+	got := parseAll(t, NewAbnAmroParser(), abnAmroStatement)
+	assertLines(t, got, []string{
+		// the account number is turned into the IBAN of the account
+		`1 2024-01-05 out 100.00 EUR NL91ABNA0417164300>NL20INGB0001234567 balance 900.00 fee 0.00 "J Jansen" "Huur januari"`,
+		`2 2024-01-06 in 2500.00 EUR NL91ABNA0417164300>NL20INGB0001234567 balance 3400.00 fee 0.00 "ACME BV" "Salaris januari"`,
+		// booked on the transaction date, not the value date
+		`3 2024-01-07 out 12.29 EUR NL91ABNA0417164300> balance 3387.71 fee 0.00 "BEA NR:AB123C 07.01.24/10.12 Albert Heijn 1234,PAS123" ""`,
+		// the row with a date that is not yyyymmdd is skipped
+	})
+}
+
+func TestAbnAmroHeader(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement string
+		want      []string
+	}{
+		{
+			// the XLS export saved as tab separated text has a header, the
+			// columns are found by name
+			name: "header",
+			statement: "\ufeffaccountNumber\tmutationcode\ttransactiondate\tvaluedate\tstartsaldo\tendsaldo\tamount\tdescription\n" +
+				"417164300\tEUR\t20240105\t20240105\t1.000,00\t900,00\t-100,00\t/TRTP/SEPA OVERBOEKING/IBAN/NL20INGB0001234567/BIC/INGBNL2A/NAME/J Jansen/REMI/Huur januari/EREF/NOTPROVIDED\n",
+			want: []string{
+				`1 2024-01-05 out 100.00 EUR NL91ABNA0417164300>NL20INGB0001234567 balance 900.00 fee 0.00 "J Jansen" "Huur januari"`,
+			},
+		},
+		{
+			name: "header in another order",
+			statement: "Transactiondate\tAmount\tMutationcode\tAccountNumber\tDescription\n" +
+				"20240107\t-12,29\tEUR\t417164300\tBEA   NR:AB123C   07.01.24/10.12 Albert Heijn 1234,PAS123\n",
+			want: []string{
+				`1 2024-01-07 out 12.29 EUR NL91ABNA0417164300> balance - fee 0.00 "BEA NR:AB123C 07.01.24/10.12 Albert Heijn 1234,PAS123" ""`,
+			},
+		},
+		{
+			name:      "without a header starting with a byte order mark",
+			statement: "\ufeff417164300\tEUR\t20240106\t20240106\t900,00\t3.400,00\t2.500,00\tSEPA Overboeking IBAN: NL20 INGB 0001 2345 67 BIC: INGBNL2A Name: ACME BV Description: Salary\n",
+			want: []string{
+				`1 2024-01-06 in 2500.00 EUR NL91ABNA0417164300>NL20INGB0001234567 balance 3400.00 fee 0.00 "ACME BV" "Salary"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertLines(t, parseAll(t, NewAbnAmroParser(), tt.statement), tt.want)
+		})
+	}
+}
+
+func TestAbnAmroIBAN(t *testing.T) {
+	tests := []struct {
+		account string
+		want    string
+	}{
+		{"417164300", "NL91ABNA0417164300"},
+		{"0417164300", "NL91ABNA0417164300"},
+		{"NL91ABNA0417164300", "NL91ABNA0417164300"},
+		{"12345678901", "12345678901"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := abnAmroIBAN(tt.account); got != tt.want {
+			t.Errorf("abnAmroIBAN(%q) = %q, want %q", tt.account, got, tt.want)
+		}
+	}
+}
+
+func TestAbnAmroInvalidStatement(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement string
+	}{
+		{"xls export", "\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1"},
+		{"header without an amount", "accountNumber\ttransactiondate\tdescription\n"},
+		{"empty", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewAbnAmroParser().ParseAll(io.NopCloser(strings.NewReader(tt.statement))); err == nil {
+				t.Error("ParseAll() error = nil")
+			}
+		})
+	}
+}
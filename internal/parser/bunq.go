@@ -0,0 +1,136 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/money"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// bunqRequired are the columns a bunq statement must have.
+var bunqRequired = []string{"Date", "Amount", "Account", "Counterparty", "Name", "Description"}
+
+// BunqParser parses the CSV statements of bunq. Older exports separate the
+// fields with semicolons and write a decimal comma, newer ones use commas
+// and a decimal point.
+type BunqParser struct {
+	headerToColumn map[string]int
+	format         money.Format
+}
+
+func NewBunqParser() *BunqParser {
+	return &BunqParser{
+		headerToColumn: make(map[string]int),
+		format:         money.DecimalPoint,
+	}
+}
+
+func (p *BunqParser) ParseAll(rc io.ReadCloser) (iter.Seq2[int, transaction.TransactionData], error) {
+	data, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return nil, err
+	}
+	line, _, _ := bytes.Cut(data, []byte("\n"))
+	csvReader := csv.NewReader(withoutBOM(bytes.NewReader(data)))
+	csvReader.LazyQuotes = true
+	csvReader.TrimLeadingSpace = true
+	csvReader.FieldsPerRecord = -1
+	if bytes.Count(line, []byte(";")) > bytes.Count(line, []byte(",")) {
+		csvReader.Comma = ';'
+		p.format = money.DecimalComma
+	}
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.parseHeader(header); err != nil {
+		return nil, err
+	}
+	seq := func(yield func(int, transaction.TransactionData) bool) {
+		for rowNumber := 1; ; rowNumber++ {
+			record, err := csvReader.Read()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				// Decide policy: skip bad CSV row reads
+				continue
+			}
+			td, err := p.ParseRow(record)
+			if err != nil {
+				// Decide policy: skip rows that fail to parse
+				continue
+			}
+			if !yield(rowNumber, td) {
+				return
+			}
+		}
+	}
+	return seq, nil
+}
+
+func (p *BunqParser) parseHeader(headers []string) error {
+	p.headerToColumn = make(map[string]int, len(headers))
+	for i, h := range headers {
+		p.headerToColumn[strings.TrimSpace(h)] = i
+	}
+	for _, h := range bunqRequired {
+		if _, ok := p.headerToColumn[h]; !ok {
+			return fmt.Errorf("not a bunq statement, missing column %q", h)
+		}
+	}
+	return nil
+}
+
+// ParseRow parses a single bunq CSV row into a TransactionData.
+func (p *BunqParser) ParseRow(record []string) (transaction.TransactionData, error) {
+	if len(p.headerToColumn) == 0 {
+		return transaction.TransactionData{}, fmt.Errorf("header map not initialized")
+	}
+	amount, err := money.Parse(p.field(record, "Amount"), transaction.DefaultCurrency, p.format)
+	if err != nil {
+		return transaction.TransactionData{}, err
+	}
+	direction := transaction.CashIn
+	if amount.Cents < 0 {
+		direction = transaction.CashOut
+	}
+
+	date, err := time.Parse(time.DateOnly, p.field(record, "Date"))
+	if err != nil {
+		return transaction.TransactionData{}, fmt.Errorf("invalid date: %w", err)
+	}
+
+	desc := p.field(record, "Name")
+	if desc == "" {
+		desc = p.field(record, "Description")
+	}
+	return transaction.TransactionData{
+		Description: desc,
+		Note:        p.field(record, "Description"),
+		Source:      "bunq",
+		Direction:   direction,
+		Amount:      amount.Abs(),
+		Date:        date,
+
+		CounterpartyIBAN: p.field(record, "Counterparty"),
+		Account:          p.field(record, "Account"),
+	}, nil
+}
+
+// field returns the trimmed value of the column, or an empty string when it
+// is missing.
+func (p *BunqParser) field(record []string, header string) string {
+	i, ok := p.headerToColumn[header]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
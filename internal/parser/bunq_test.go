@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestBunqParseAll(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement string
+		want      []string
+	}{
+		{
+			// older exports separate the fields with semicolons and write a
+			// decimal comma
+			name: "semicolons",
+			statement: `"Date";"Interest Date";"Amount";"Account";"Counterparty";"Name";"Description"
+"2024-01-05";"2024-01-05";"-1.250,50";"NL43BUNQ2025123456";"NL91ABNA0417164300";"J Jansen";"Huur januari"
+"2024-01-06";"2024-01-06";"3.500,00";"NL43BUNQ2025123456";"NL20INGB0001234567";"ACME BV";"Salaris januari"
+"2024-01-07";"2024-01-07";"-4,50";"NL43BUNQ2025123456";"";"";"Coffee Company Amsterdam"
+`,
+			want: []string{
+				`1 2024-01-05 out 1250.50 EUR NL43BUNQ2025123456>NL91ABNA0417164300 balance - fee 0.00 "J Jansen" "Huur januari"`,
+				`2 2024-01-06 in 3500.00 EUR NL43BUNQ2025123456>NL20INGB0001234567 balance - fee 0.00 "ACME BV" "Salaris januari"`,
+				// without a name the description names the payment
+				`3 2024-01-07 out 4.50 EUR NL43BUNQ2025123456> balance - fee 0.00 "Coffee Company Amsterdam" "Coffee Company Amsterdam"`,
+			},
+		},
+		{
+			// newer exports use commas and a decimal point, descriptions
+			// with a comma are quoted
+			name: "commas",
+			statement: "\ufeff" + `Date,Interest Date,Amount,Account,Counterparty,Name,Description
+2024-01-05,2024-01-05,-1250.50,NL43BUNQ2025123456,NL91ABNA0417164300,J Jansen,"Huur januari, kamer 2"
+2024-01-06,2024-01-06,3500.00,NL43BUNQ2025123456,NL20INGB0001234567,ACME BV,Salaris januari
+`,
+			want: []string{
+				`1 2024-01-05 out 1250.50 EUR NL43BUNQ2025123456>NL91ABNA0417164300 balance - fee 0.00 "J Jansen" "Huur januari, kamer 2"`,
+				`2 2024-01-06 in 3500.00 EUR NL43BUNQ2025123456>NL20INGB0001234567 balance - fee 0.00 "ACME BV" "Salaris januari"`,
+			},
+		},
+		{
+			name: "rows that do not parse are skipped",
+			statement: `Date,Interest Date,Amount,Account,Counterparty,Name,Description
+05-01-2024,05-01-2024,-1250.50,NL43BUNQ2025123456,NL91ABNA0417164300,J Jansen,Huur januari
+2024-01-06,2024-01-06,3.500.00,NL43BUNQ2025123456,NL20INGB0001234567,ACME BV,Salaris januari
+2024-01-07,2024-01-07,-4.50,NL43BUNQ2025123456,,,Coffee Company Amsterdam
+`,
+			want: []string{
+				`3 2024-01-07 out 4.50 EUR NL43BUNQ2025123456> balance - fee 0.00 "Coffee Company Amsterdam" "Coffee Company Amsterdam"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertLines(t, parseAll(t, NewBunqParser(), tt.statement), tt.want)
+		})
+	}
+}
+
+func TestBunqInvalidStatement(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement string
+	}{
+		// a Revolut statement is not a bunq statement
+		{"other bank", "Type,Product,Started Date,Completed Date,Description,Amount,Fee,Currency,State,Balance\n"},
+		{"missing counterparty", "Date;Amount;Account;Name;Description\n"},
+		{"empty", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewBunqParser().ParseAll(io.NopCloser(strings.NewReader(tt.statement))); err == nil {
+				t.Error("ParseAll() error = nil, want a missing column")
+			}
+		})
+	}
+}
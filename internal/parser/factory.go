@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"iter"
@@ -17,7 +19,25 @@ func CreateCsvParser(ID vendor.VendorID) (CsvParser, error) {
 	switch ID {
 	case vendor.VendorING:
 		return NewIngParser(), nil
+	case vendor.VendorRevolut:
+		return NewRevolutParser(), nil
+	case vendor.VendorWise:
+		return NewWiseParser(), nil
+	case vendor.VendorAbnAmro:
+		return NewAbnAmroParser(), nil
+	case vendor.VendorBunq:
+		return NewBunqParser(), nil
 	default:
 		return nil, fmt.Errorf("unsupported vendor ID: %s", ID)
 	}
 }
+
+// withoutBOM skips the UTF-8 byte order mark some exports start with, it
+// would otherwise end up in the first column name.
+func withoutBOM(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+	if b, err := br.Peek(3); err == nil && bytes.Equal(b, []byte{0xef, 0xbb, 0xbf}) {
+		br.Discard(3)
+	}
+	return br
+}
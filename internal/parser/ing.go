@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/money"
 	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
 )
 
@@ -91,12 +92,14 @@ func (p *IngParser) ParseRow(record []string, rowNumber int, importId uuid.UUID)
 	source := "ING"
 	amountStr := record[p.headerToColumn["Amount (EUR)"]]
 	directionRaw := record[p.headerToColumn["Debit/credit"]]
+	counterpartyIBAN := p.optional(record, "Counterparty")
+	account := p.optional(record, "Account")
+	balanceStr := p.optional(record, "Resulting balance")
 
-	// Parse amount (replace comma with dot)
-	amountStr = strings.ReplaceAll(amountStr, ",", ".")
-	var amount float64
-	if _, err := fmt.Sscanf(amountStr, "%f", &amount); err != nil {
-		return transaction.TransactionData{}, fmt.Errorf("invalid amount: %w", err)
+	// Parse amount
+	amount, err := money.Parse(amountStr, transaction.DefaultCurrency, ingFormat(amountStr))
+	if err != nil {
+		return transaction.TransactionData{}, err
 	}
 
 	// Parse direction
@@ -109,6 +112,16 @@ func (p *IngParser) ParseRow(record []string, rowNumber int, importId uuid.UUID)
 		return transaction.TransactionData{}, fmt.Errorf("invalid direction: %s", directionRaw)
 	}
 
+	// Parse the resulting balance, only newer exports contain it
+	var balanceAfter *int64
+	if balanceStr != "" {
+		balance, err := money.Parse(balanceStr, transaction.DefaultCurrency, ingFormat(balanceStr))
+		if err != nil {
+			return transaction.TransactionData{}, fmt.Errorf("invalid resulting balance: %w", err)
+		}
+		balanceAfter = &balance.Cents
+	}
+
 	// Parse date
 	parsedDate, err := time.Parse("20060102", dateStr)
 	if err != nil {
@@ -122,5 +135,29 @@ func (p *IngParser) ParseRow(record []string, rowNumber int, importId uuid.UUID)
 		Direction:   direction,
 		Amount:      amount,
 		Date:        parsedDate,
+
+		CounterpartyIBAN:  counterpartyIBAN,
+		Account:           account,
+		BalanceAfterCents: balanceAfter,
 	}, nil
 }
+
+// optional returns the trimmed value of a column that is not present in every
+// export, or an empty string when it is missing.
+func (p *IngParser) optional(record []string, header string) string {
+	i, ok := p.headerToColumn[header]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// ingFormat returns the format of an ING amount. ING does not group the
+// thousands, Dutch exports write a decimal comma and English exports a
+// decimal point.
+func ingFormat(s string) money.Format {
+	if strings.Contains(s, ",") {
+		return money.Format{Decimal: ','}
+	}
+	return money.Format{Decimal: '.'}
+}
@@ -0,0 +1,50 @@
+package parser
+
+import "testing"
+
+func TestIngParseAll(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement string
+		want      []string
+	}{
+		{
+			name: "decimal comma",
+			statement: `"Date";"Name / Description";"Account";"Counterparty";"Code";"Debit/credit";"Amount (EUR)";"Transaction type";"Notifications";"Resulting balance"
+"20240105";"Albert Heijn 1234";"NL20INGB0001234567";"";"BA";"Debit";"12,29";"Payment terminal";"Pasvolgnr: 001";"1987,71"
+"20240106";"ACME BV";"NL20INGB0001234567";"NL91ABNA0417164300";"OV";"Credit";"2500,00";"Transfer";"Salaris januari";"4487,71"
+`,
+			want: []string{
+				`1 2024-01-05 out 12.29 EUR NL20INGB0001234567> balance 1987.71 fee 0.00 "Albert Heijn 1234" "Pasvolgnr: 001"`,
+				`2 2024-01-06 in 2500.00 EUR NL20INGB0001234567>NL91ABNA0417164300 balance 4487.71 fee 0.00 "ACME BV" "Salaris januari"`,
+			},
+		},
+		{
+			name: "decimal point",
+			statement: `"Date";"Name / Description";"Account";"Counterparty";"Code";"Debit/credit";"Amount (EUR)";"Transaction type";"Notifications"
+"20240105";"Albert Heijn 1234";"NL20INGB0001234567";"";"BA";"Debit";"12.50";"Payment terminal";""
+"20240106";"Bakker Bart";"NL20INGB0001234567";"";"BA";"Debit";"1.5";"Payment terminal";""
+`,
+			want: []string{
+				`1 2024-01-05 out 12.50 EUR NL20INGB0001234567> balance - fee 0.00 "Albert Heijn 1234" ""`,
+				`2 2024-01-06 out 1.50 EUR NL20INGB0001234567> balance - fee 0.00 "Bakker Bart" ""`,
+			},
+		},
+		{
+			// ING does not group thousands, a grouped amount is not guessed at
+			name: "grouped amount",
+			statement: `"Date";"Name / Description";"Account";"Counterparty";"Code";"Debit/credit";"Amount (EUR)";"Transaction type";"Notifications"
+"20240105";"ACME BV";"NL20INGB0001234567";"";"OV";"Credit";"2.500,00";"Transfer";""
+"20240106";"Bakker Bart";"NL20INGB0001234567";"";"BA";"Debit";"4,50";"Payment terminal";""
+`,
+			want: []string{
+				`2 2024-01-06 out 4.50 EUR NL20INGB0001234567> balance - fee 0.00 "Bakker Bart" ""`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertLines(t, parseAll(t, NewIngParser(), tt.statement), tt.want)
+		})
+	}
+}
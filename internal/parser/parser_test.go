@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/money"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// parseAll parses the statement and returns its rows as described by line.
+func parseAll(t *testing.T, p CsvParser, statement string) []string {
+	t.Helper()
+	seq, err := p.ParseAll(io.NopCloser(strings.NewReader(statement)))
+	if err != nil {
+		t.Fatalf("ParseAll() error = %v", err)
+	}
+	var res []string
+	for n, td := range seq {
+		res = append(res, line(n, td))
+	}
+	return res
+}
+
+// line describes a parsed row as: row number, date, direction, amount,
+// account>counterparty, balance after, fee, description and note.
+func line(n int, td transaction.TransactionData) string {
+	balance := "-"
+	if td.BalanceAfterCents != nil {
+		balance = money.New(*td.BalanceAfterCents, td.Amount.Currency).Decimal()
+	}
+	return fmt.Sprintf("%d %s %s %s %s>%s balance %s fee %s %q %q",
+		n, td.Date.Format(time.DateOnly), td.Direction, td.Amount, td.Account, td.CounterpartyIBAN, balance, td.Fee.Decimal(), td.Description, td.Note)
+}
+
+func assertLines(t *testing.T, got, want []string) {
+	t.Helper()
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("ParseAll() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// feeBalances imports the rows that carry a fee and returns the balance
+// after each fee, which has to be the balance the statement reports.
+func feeBalances(t *testing.T, p CsvParser, statement string) map[int]string {
+	t.Helper()
+	seq, err := p.ParseAll(io.NopCloser(strings.NewReader(statement)))
+	if err != nil {
+		t.Fatalf("ParseAll() error = %v", err)
+	}
+	res := map[int]string{}
+	for n, td := range seq {
+		if td.Fee.Cents == 0 {
+			continue
+		}
+		tx, err := transaction.NewTransactionFromData(td, n, uuid.New())
+		if err != nil {
+			t.Fatalf("NewTransactionFromData() error = %v", err)
+		}
+		fee, err := tx.NewFee(td.Fee)
+		if err != nil {
+			t.Fatalf("NewFee() error = %v", err)
+		}
+		if fee.BalanceAfterCents == nil {
+			t.Fatalf("row %d: fee without a balance", n)
+		}
+		res[n] = money.New(*fee.BalanceAfterCents, fee.Currency).Decimal()
+	}
+	return res
+}
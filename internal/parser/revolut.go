@@ -0,0 +1,217 @@
+package parser
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"iter"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/money"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// revolutRequired are the columns a Revolut statement must have.
+var revolutRequired = []string{"Type", "Started Date", "Completed Date", "Description", "Amount", "Fee", "Currency", "State"}
+
+var currencyCode = regexp.MustCompile(`\b[A-Z]{3}\b`)
+
+// RevolutParser parses the CSV account statements of Revolut. Every
+// currency pocket is an account of its own, exchanges between pockets are
+// imported as transfers between them.
+type RevolutParser struct {
+	headerToColumn map[string]int
+}
+
+func NewRevolutParser() *RevolutParser {
+	return &RevolutParser{
+		headerToColumn: make(map[string]int),
+	}
+}
+
+// revolutRow is a completed row with its row number.
+type revolutRow struct {
+	number int
+	record []string
+}
+
+func (p *RevolutParser) ParseAll(rc io.ReadCloser) (iter.Seq2[int, transaction.TransactionData], error) {
+	csvReader := csv.NewReader(withoutBOM(rc))
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+	header, err := csvReader.Read()
+	if err != nil {
+		rc.Close()
+		return nil, err
+	}
+	if err := p.parseHeader(header); err != nil {
+		rc.Close()
+		return nil, err
+	}
+	// The rows are read up front, the legs of an exchange are matched to
+	// find the pocket on the other side.
+	var rows []revolutRow
+	for rowNumber := 1; ; rowNumber++ {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// Decide policy: skip bad CSV row reads
+			continue
+		}
+		// Pending, reverted and declined payments never changed the balance
+		if !strings.EqualFold(p.field(record, "State"), "COMPLETED") {
+			continue
+		}
+		rows = append(rows, revolutRow{number: rowNumber, record: record})
+	}
+	rc.Close()
+
+	seq := func(yield func(int, transaction.TransactionData) bool) {
+		for i, row := range rows {
+			td, err := p.ParseRow(row.record)
+			if err != nil {
+				// Decide policy: skip rows that fail to parse
+				continue
+			}
+			if strings.EqualFold(p.field(row.record, "Type"), "EXCHANGE") {
+				if other := p.exchangedWith(rows, i); other != "" {
+					td.CounterpartyIBAN = revolutPocket(p.field(row.record, "Product"), other)
+				}
+			}
+			if !yield(row.number, td) {
+				return
+			}
+		}
+	}
+	return seq, nil
+}
+
+func (p *RevolutParser) parseHeader(headers []string) error {
+	p.headerToColumn = make(map[string]int, len(headers))
+	for i, h := range headers {
+		p.headerToColumn[strings.TrimSpace(h)] = i
+	}
+	for _, h := range revolutRequired {
+		if _, ok := p.headerToColumn[h]; !ok {
+			return fmt.Errorf("not a Revolut statement, missing column %q", h)
+		}
+	}
+	return nil
+}
+
+// ParseRow parses a single Revolut CSV row into a TransactionData.
+func (p *RevolutParser) ParseRow(record []string) (transaction.TransactionData, error) {
+	if len(p.headerToColumn) == 0 {
+		return transaction.TransactionData{}, fmt.Errorf("header map not initialized")
+	}
+	currency := p.field(record, "Currency")
+	amount, err := money.Parse(p.field(record, "Amount"), currency, money.DecimalPoint)
+	if err != nil {
+		return transaction.TransactionData{}, err
+	}
+	fee := money.New(0, currency)
+	if s := p.field(record, "Fee"); s != "" {
+		if fee, err = money.Parse(s, currency, money.DecimalPoint); err != nil {
+			return transaction.TransactionData{}, fmt.Errorf("invalid fee: %w", err)
+		}
+	}
+
+	direction := transaction.CashIn
+	if amount.Cents < 0 {
+		direction = transaction.CashOut
+	}
+
+	// The fee is taken after the amount, the balance after the transaction
+	// itself is the reported balance before the fee.
+	var balanceAfter *int64
+	if s := p.field(record, "Balance"); s != "" {
+		balance, err := money.Parse(s, currency, money.DecimalPoint)
+		if err != nil {
+			return transaction.TransactionData{}, fmt.Errorf("invalid balance: %w", err)
+		}
+		cents := balance.Cents + fee.Cents
+		balanceAfter = &cents
+	}
+
+	dateStr := p.field(record, "Completed Date")
+	if dateStr == "" {
+		dateStr = p.field(record, "Started Date")
+	}
+	date, err := parseDateTime(dateStr)
+	if err != nil {
+		return transaction.TransactionData{}, err
+	}
+
+	return transaction.TransactionData{
+		Description: p.field(record, "Description"),
+		Note:        p.field(record, "Type"),
+		Source:      "Revolut",
+		Direction:   direction,
+		Amount:      amount.Abs(),
+		Date:        date,
+
+		Account:           revolutPocket(p.field(record, "Product"), currency),
+		BalanceAfterCents: balanceAfter,
+		Fee:               fee,
+	}, nil
+}
+
+// exchangedWith returns the currency of the pocket on the other side of the
+// exchange in rows[i]. The description names the target currency, for the
+// leg in the target currency the other leg is the exchange started at the
+// same time in another currency.
+func (p *RevolutParser) exchangedWith(rows []revolutRow, i int) string {
+	record := rows[i].record
+	currency := strings.ToUpper(p.field(record, "Currency"))
+	for _, code := range currencyCode.FindAllString(p.field(record, "Description"), -1) {
+		if code != currency {
+			return code
+		}
+	}
+	started := p.field(record, "Started Date")
+	for j, row := range rows {
+		if j == i || !strings.EqualFold(p.field(row.record, "Type"), "EXCHANGE") || p.field(row.record, "Started Date") != started {
+			continue
+		}
+		if other := strings.ToUpper(p.field(row.record, "Currency")); other != currency {
+			return other
+		}
+	}
+	return ""
+}
+
+// field returns the trimmed value of the column, or an empty string when it
+// is missing.
+func (p *RevolutParser) field(record []string, header string) string {
+	i, ok := p.headerToColumn[header]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// revolutPocket identifies the pocket of a currency within a Revolut
+// product, such as REVOLUT-CURRENT-EUR, the statement does not include the
+// IBAN.
+func revolutPocket(product, currency string) string {
+	if product == "" {
+		product = "Current"
+	}
+	return strings.ToUpper(strings.Join([]string{"Revolut", product, currency}, "-"))
+}
+
+// parseDateTime parses the dates of statements that include the time of
+// day, the transaction is booked on the day.
+func parseDateTime(s string) (time.Time, error) {
+	for _, layout := range []string{time.DateTime, "2006-01-02 15:04", time.DateOnly, "02-01-2006 15:04:05", "02-01-2006"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			y, m, d := t.Date()
+			return time.Date(y, m, d, 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date: %q", s)
+}
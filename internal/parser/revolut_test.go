@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// revolutStatement is a Revolut account statement with a card payment, an
+// exchange from euro to dollar with a fee on the euro leg, a pending
+// payment, a top up, a cash withdrawal with a fee and a payment in yen.
+const revolutStatement = `Type,Product,Started Date,Completed Date,Description,Amount,Fee,Currency,State,Balance
+CARD_PAYMENT,Current,2024-01-05 10:12:01,2024-01-06 09:01:22,Albert Heijn,-12.29,0.00,EUR,COMPLETED,87.71
+EXCHANGE,Current,2024-01-07 14:00:00,2024-01-07 14:00:00,Exchanged to USD,-50.00,0.25,EUR,COMPLETED,37.46
+EXCHANGE,Current,2024-01-07 14:00:00,2024-01-07 14:00:00,Exchanged to USD,54.10,0.00,USD,COMPLETED,54.10
+CARD_PAYMENT,Current,2024-01-08 09:00:00,,Bol.com,-20.00,0.00,EUR,PENDING,
+TOPUP,Current,2024-01-09 08:00:00,2024-01-09 08:00:10,Top-Up by *1234,100.00,0.00,EUR,COMPLETED,137.46
+ATM,Current,2024-01-10 12:00:00,2024-01-10 12:00:05,Cash at Chase,-20.00,1.00,USD,COMPLETED,33.10
+CARD_PAYMENT,Current,2024-01-12 03:10:00,2024-01-12 03:10:00,Lawson,-1200,0,JPY,COMPLETED,8800
+`
+
+func TestRevolutParseAll(t *testing.T) {
+	got := parseAll(t, NewRevolutParser(), revolutStatement)
+	assertLines(t, got, []string{
+		// booked on the completed date
+		`1 2024-01-06 out 12.29 EUR REVOLUT-CURRENT-EUR> balance 87.71 fee 0.00 "Albert Heijn" "CARD_PAYMENT"`,
+		// the legs of the exchange are transfers between the pockets, the
+		// euro leg names the dollar pocket in its description, the dollar
+		// leg is matched to the euro leg started at the same time
+		`2 2024-01-07 out 50.00 EUR REVOLUT-CURRENT-EUR>REVOLUT-CURRENT-USD balance 37.71 fee 0.25 "Exchanged to USD" "EXCHANGE"`,
+		`3 2024-01-07 in 54.10 USD REVOLUT-CURRENT-USD>REVOLUT-CURRENT-EUR balance 54.10 fee 0.00 "Exchanged to USD" "EXCHANGE"`,
+		// the pending payment on row 4 is skipped
+		`5 2024-01-09 in 100.00 EUR REVOLUT-CURRENT-EUR> balance 137.46 fee 0.00 "Top-Up by *1234" "TOPUP"`,
+		`6 2024-01-10 out 20.00 USD REVOLUT-CURRENT-USD> balance 34.10 fee 1.00 "Cash at Chase" "ATM"`,
+		`7 2024-01-12 out 1200 JPY REVOLUT-CURRENT-JPY> balance 8800 fee 0 "Lawson" "CARD_PAYMENT"`,
+	})
+}
+
+func TestRevolutFees(t *testing.T) {
+	// the balance the statement reports is the balance after the fee
+	got := feeBalances(t, NewRevolutParser(), revolutStatement)
+	want := map[int]string{2: "37.46", 6: "33.10"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("fee balances = %v, want %v", got, want)
+	}
+}
+
+func TestRevolutProducts(t *testing.T) {
+	// exchanges into a savings vault stay within the product
+	statement := `Type,Product,Started Date,Completed Date,Description,Amount,Fee,Currency,State,Balance
+EXCHANGE,Savings,2024-02-01 09:00:00,2024-02-01 09:00:01,Exchanged to GBP,-100.00,0.00,EUR,COMPLETED,400.00
+EXCHANGE,Savings,2024-02-01 09:00:00,2024-02-01 09:00:01,Exchanged to GBP,85.60,0.00,GBP,COMPLETED,85.60
+TRANSFER,,2024-02-02 09:00:00,2024-02-02 09:00:00,To J Jansen,-10.00,,EUR,COMPLETED,
+`
+	got := parseAll(t, NewRevolutParser(), statement)
+	assertLines(t, got, []string{
+		`1 2024-02-01 out 100.00 EUR REVOLUT-SAVINGS-EUR>REVOLUT-SAVINGS-GBP balance 400.00 fee 0.00 "Exchanged to GBP" "EXCHANGE"`,
+		`2 2024-02-01 in 85.60 GBP REVOLUT-SAVINGS-GBP>REVOLUT-SAVINGS-EUR balance 85.60 fee 0.00 "Exchanged to GBP" "EXCHANGE"`,
+		// without a product the current account, without a balance none
+		`3 2024-02-02 out 10.00 EUR REVOLUT-CURRENT-EUR> balance - fee 0.00 "To J Jansen" "TRANSFER"`,
+	})
+}
+
+func TestRevolutInvalidStatement(t *testing.T) {
+	// an ING statement is not a Revolut statement
+	statement := "Date,Name / Description,Account,Counterparty,Code,Debit/credit,Amount (EUR),Transaction type,Notifications\n"
+	if _, err := NewRevolutParser().ParseAll(io.NopCloser(strings.NewReader(statement))); err == nil {
+		t.Error("ParseAll() error = nil, want a missing column")
+	}
+}
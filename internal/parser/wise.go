@@ -0,0 +1,177 @@
+package parser
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/money"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// wiseRequired are the columns a Wise statement must have.
+var wiseRequired = []string{"TransferWise ID", "Date", "Amount", "Currency", "Description"}
+
+// WiseParser parses the CSV balance statements of Wise. Every currency
+// balance is an account of its own, conversions between balances are
+// imported as transfers between them.
+type WiseParser struct {
+	headerToColumn map[string]int
+}
+
+func NewWiseParser() *WiseParser {
+	return &WiseParser{
+		headerToColumn: make(map[string]int),
+	}
+}
+
+func (p *WiseParser) ParseAll(rc io.ReadCloser) (iter.Seq2[int, transaction.TransactionData], error) {
+	csvReader := csv.NewReader(withoutBOM(rc))
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+	header, err := csvReader.Read()
+	if err != nil {
+		rc.Close()
+		return nil, err
+	}
+	if err := p.parseHeader(header); err != nil {
+		rc.Close()
+		return nil, err
+	}
+	seq := func(yield func(int, transaction.TransactionData) bool) {
+		defer rc.Close()
+		for rowNumber := 1; ; rowNumber++ {
+			record, err := csvReader.Read()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				// Decide policy: skip bad CSV row reads
+				continue
+			}
+			// Statements only list completed transfers, the transaction
+			// history export has a status to check
+			if status := p.field(record, "Status"); status != "" && !strings.EqualFold(status, "COMPLETED") {
+				continue
+			}
+			td, err := p.ParseRow(record)
+			if err != nil {
+				// Decide policy: skip rows that fail to parse
+				continue
+			}
+			if !yield(rowNumber, td) {
+				return
+			}
+		}
+	}
+	return seq, nil
+}
+
+func (p *WiseParser) parseHeader(headers []string) error {
+	p.headerToColumn = make(map[string]int, len(headers))
+	for i, h := range headers {
+		p.headerToColumn[strings.TrimSpace(h)] = i
+	}
+	for _, h := range wiseRequired {
+		if _, ok := p.headerToColumn[h]; !ok {
+			return fmt.Errorf("not a Wise statement, missing column %q", h)
+		}
+	}
+	return nil
+}
+
+// ParseRow parses a single Wise CSV row into a TransactionData. The amount
+// Wise reports includes the fees, they are split off so the transaction
+// holds what was paid or received.
+func (p *WiseParser) ParseRow(record []string) (transaction.TransactionData, error) {
+	if len(p.headerToColumn) == 0 {
+		return transaction.TransactionData{}, fmt.Errorf("header map not initialized")
+	}
+	currency := p.field(record, "Currency")
+	amount, err := money.Parse(p.field(record, "Amount"), currency, money.DecimalPoint)
+	if err != nil {
+		return transaction.TransactionData{}, err
+	}
+	fee := money.New(0, currency)
+	if s := p.field(record, "Total fees"); s != "" {
+		if fee, err = money.Parse(s, currency, money.DecimalPoint); err != nil {
+			return transaction.TransactionData{}, fmt.Errorf("invalid fee: %w", err)
+		}
+	}
+	amount.Cents += fee.Cents
+
+	direction := transaction.CashIn
+	if amount.Cents < 0 {
+		direction = transaction.CashOut
+	}
+
+	var balanceAfter *int64
+	if s := p.field(record, "Running Balance"); s != "" {
+		balance, err := money.Parse(s, currency, money.DecimalPoint)
+		if err != nil {
+			return transaction.TransactionData{}, fmt.Errorf("invalid running balance: %w", err)
+		}
+		cents := balance.Cents + fee.Cents
+		balanceAfter = &cents
+	}
+
+	date, err := parseDateTime(p.field(record, "Date"))
+	if err != nil {
+		return transaction.TransactionData{}, err
+	}
+
+	note := p.field(record, "Payment Reference")
+	if note == "" {
+		note = p.field(record, "Note")
+	}
+	td := transaction.TransactionData{
+		Description: p.field(record, "Description"),
+		Note:        note,
+		Source:      "Wise",
+		Direction:   direction,
+		Amount:      amount.Abs(),
+		Date:        date,
+
+		Account:           wiseBalance(currency),
+		BalanceAfterCents: balanceAfter,
+		Fee:               fee,
+	}
+	if other := p.convertedWith(record); other != "" {
+		td.CounterpartyIBAN = wiseBalance(other)
+	} else if direction == transaction.CashOut {
+		td.CounterpartyIBAN = p.field(record, "Payee Account Number")
+	}
+	return td, nil
+}
+
+// convertedWith returns the currency of the balance on the other side of a
+// conversion, or an empty string when the row is not one.
+func (p *WiseParser) convertedWith(record []string) string {
+	from := strings.ToUpper(p.field(record, "Exchange From"))
+	to := strings.ToUpper(p.field(record, "Exchange To"))
+	if from == "" || to == "" || from == to || !strings.HasPrefix(strings.ToUpper(p.field(record, "TransferWise ID")), "BALANCE") {
+		return ""
+	}
+	if strings.EqualFold(p.field(record, "Currency"), from) {
+		return to
+	}
+	return from
+}
+
+// field returns the trimmed value of the column, or an empty string when it
+// is missing.
+func (p *WiseParser) field(record []string, header string) string {
+	i, ok := p.headerToColumn[header]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// wiseBalance identifies the balance of a currency, such as WISE-EUR, the
+// statement does not include the account details.
+func wiseBalance(currency string) string {
+	return strings.ToUpper("Wise-" + currency)
+}
@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// wiseStatement is a Wise balance statement of a euro and a dollar balance
+// with a transfer with fees, a conversion between the balances, a salary, a
+// card payment and a transfer without fees.
+const wiseStatement = `TransferWise ID,Date,Amount,Currency,Description,Payment Reference,Running Balance,Exchange From,Exchange To,Exchange Rate,Payer Name,Payee Name,Payee Account Number,Merchant,Card Last Four Digits,Card Holder Full Name,Attachment,Note,Total fees,Exchange To Amount
+TRANSFER-123456789,05-01-2024,-100.50,EUR,Sent money to J Jansen,Rent,899.50,,,,,J Jansen,NL91ABNA0417164300,,,,,,0.50,
+BALANCE-98765,06-01-2024,-200.00,EUR,Converted 200.00 EUR to 216.36 USD,,699.50,EUR,USD,1.08723,,,,,,,,,1.00,216.36
+BALANCE-98765,06-01-2024,216.36,USD,Converted 200.00 EUR to 216.36 USD,,216.36,EUR,USD,1.08723,,,,,,,,,0.00,216.36
+TRANSFER-555666777,07-01-2024,1500.00,EUR,Received money from ACME BV with reference Salary,Salary,2199.50,,,,ACME BV,,,,,,,,0.00,
+CARD-777888999,08-01-2024,-4.50,USD,Card transaction of 4.50 USD issued by Starbucks,,211.86,,,,,,,Starbucks,1234,J Doe,,,0.00,
+TRANSFER-111222333,09-01-2024,-50.00,EUR,Sent money to P Pietersen,,2149.50,,,,,P Pietersen,NL20INGB0001234567,,,,,Dinner,,
+`
+
+func TestWiseParseAll(t *testing.T) {
+	got := parseAll(t, NewWiseParser(), wiseStatement)
+	assertLines(t, got, []string{
+		// the 100.50 Wise reports includes the 0.50 fee, 100.00 reached
+		// the payee and the balance before the fee was 900.00
+		`1 2024-01-05 out 100.00 EUR WISE-EUR>NL91ABNA0417164300 balance 900.00 fee 0.50 "Sent money to J Jansen" "Rent"`,
+		// the conversion is a transfer between the balances, of the 200.00
+		// 1.00 went to fees
+		`2 2024-01-06 out 199.00 EUR WISE-EUR>WISE-USD balance 700.50 fee 1.00 "Converted 200.00 EUR to 216.36 USD" ""`,
+		`3 2024-01-06 in 216.36 USD WISE-USD>WISE-EUR balance 216.36 fee 0.00 "Converted 200.00 EUR to 216.36 USD" ""`,
+		`4 2024-01-07 in 1500.00 EUR WISE-EUR> balance 2199.50 fee 0.00 "Received money from ACME BV with reference Salary" "Salary"`,
+		`5 2024-01-08 out 4.50 USD WISE-USD> balance 211.86 fee 0.00 "Card transaction of 4.50 USD issued by Starbucks" ""`,
+		// without a payment reference the note is used
+		`6 2024-01-09 out 50.00 EUR WISE-EUR>NL20INGB0001234567 balance 2149.50 fee 0.00 "Sent money to P Pietersen" "Dinner"`,
+	})
+}
+
+func TestWiseFees(t *testing.T) {
+	// the running balance Wise reports is the balance after the fee
+	got := feeBalances(t, NewWiseParser(), wiseStatement)
+	want := map[int]string{1: "899.50", 2: "699.50"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("fee balances = %v, want %v", got, want)
+	}
+}
+
+func TestWiseTransactionHistory(t *testing.T) {
+	// the transaction history export has a status, only completed rows
+	// changed the balance, a conversion is only a transfer between
+	// balances when Wise booked it as one
+	statement := `TransferWise ID,Status,Date,Amount,Currency,Description,Exchange From,Exchange To,Total fees
+TRANSFER-1,COMPLETED,2024-01-05 10:00:00,-25.00,EUR,Sent money to J Jansen,,,0.00
+TRANSFER-2,CANCELLED,2024-01-06 10:00:00,-75.00,EUR,Sent money to J Jansen,,,0.00
+TRANSFER-3,COMPLETED,2024-01-07 10:00:00,-110.00,EUR,Sent money to Acme Inc,EUR,USD,2.00
+`
+	got := parseAll(t, NewWiseParser(), statement)
+	assertLines(t, got, []string{
+		`1 2024-01-05 out 25.00 EUR WISE-EUR> balance - fee 0.00 "Sent money to J Jansen" ""`,
+		`3 2024-01-07 out 108.00 EUR WISE-EUR> balance - fee 2.00 "Sent money to Acme Inc" ""`,
+	})
+}
+
+func TestWiseInvalidStatement(t *testing.T) {
+	statement := "Type,Product,Started Date,Completed Date,Description,Amount,Fee,Currency,State,Balance\n"
+	if _, err := NewWiseParser().ParseAll(io.NopCloser(strings.NewReader(statement))); err == nil {
+		t.Error("ParseAll() error = nil, want a missing column")
+	}
+}
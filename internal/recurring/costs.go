@@ -0,0 +1,48 @@
+package recurring
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/fx"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// Single-use interfaces only used by CostsHandler
+
+type SeriesLister interface {
+	List(ctx context.Context, status Status, direction transaction.CashFlowDirection) ([]*Series, error)
+}
+
+// CostsHandler sums the recurring payments and income in the reporting
+// currency.
+type CostsHandler struct {
+	sl       SeriesLister
+	rf       fx.RateFetcher
+	currency string
+}
+
+func NewCostsHandler(sl SeriesLister, rf fx.RateFetcher, currency string) *CostsHandler {
+	return &CostsHandler{sl: sl, rf: rf, currency: currency}
+}
+
+// Handle returns the costs of the series that have not ended, amounts in
+// other currencies are converted with the rates of today.
+func (h *CostsHandler) Handle(ctx context.Context, today time.Time) (Costs, error) {
+	series, err := h.sl.List(ctx, "", "")
+	if err != nil {
+		return Costs{}, err
+	}
+	conv := fx.NewConverter(h.rf)
+	yearly := make(map[uuid.UUID]int64, len(series))
+	for _, s := range series {
+		if s.Status == StatusEnded {
+			continue
+		}
+		if yearly[s.ID], err = conv.Convert(ctx, s.YearlyCents(), s.Currency, h.currency, today); err != nil {
+			return Costs{}, err
+		}
+	}
+	return Overview(series, h.currency, yearly), nil
+}
@@ -0,0 +1,229 @@
+package recurring
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/counterparty"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// DefaultTolerance is the relative difference between two amounts that are
+// still considered the same payment.
+const DefaultTolerance = 0.2
+
+// Single-use interfaces only used by DetectHandler
+
+type TransactionFetcher interface {
+	// FetchSince returns the transactions from the date on that count for
+	// reports, ignored transactions and transfers are left out.
+	FetchSince(ctx context.Context, from time.Time) ([]*transaction.Transaction, error)
+}
+
+type CounterpartyFetcher interface {
+	FetchByID(ctx context.Context, id uuid.UUID) (*counterparty.Counterparty, error)
+}
+
+type SeriesReplacer interface {
+	// Replace stores the series in place of all earlier detected ones.
+	Replace(ctx context.Context, series []*Series) error
+}
+
+// DetectHandler detects the recurring series in the transactions of the last
+// months and stores them.
+type DetectHandler struct {
+	tf        TransactionFetcher
+	cf        CounterpartyFetcher
+	sr        SeriesReplacer
+	lookback  int
+	tolerance float64
+}
+
+func NewDetectHandler(tf TransactionFetcher, cf CounterpartyFetcher, sr SeriesReplacer, lookbackMonths int, tolerance float64) *DetectHandler {
+	if lookbackMonths <= 0 {
+		lookbackMonths = 25
+	}
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+	return &DetectHandler{tf: tf, cf: cf, sr: sr, lookback: lookbackMonths, tolerance: tolerance}
+}
+
+// Handle returns the number of series detected.
+func (h *DetectHandler) Handle(ctx context.Context, today time.Time) (int, error) {
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	txs, err := h.tf.FetchSince(ctx, today.AddDate(0, -h.lookback, 0))
+	if err != nil {
+		return 0, err
+	}
+	series := Detect(txs, today, h.tolerance)
+	names := map[uuid.UUID]string{}
+	for _, s := range series {
+		if s.CounterpartyID == nil {
+			continue
+		}
+		name, ok := names[*s.CounterpartyID]
+		if !ok {
+			cp, err := h.cf.FetchByID(ctx, *s.CounterpartyID)
+			if err != nil {
+				return 0, err
+			}
+			name = cp.Name
+			names[cp.ID] = name
+		}
+		s.Name = name
+	}
+	if err := h.sr.Replace(ctx, series); err != nil {
+		return 0, err
+	}
+	return len(series), nil
+}
+
+// Detect finds the series in the transactions. Transactions are grouped per
+// account, currency, counterparty and direction, within a group payments
+// whose amounts differ by at most the tolerance form a candidate series. A
+// candidate is recurring when most intervals between its payments match a
+// frequency.
+func Detect(txs []*transaction.Transaction, today time.Time, tolerance float64) []*Series {
+	type group struct {
+		account   string
+		currency  string
+		key       string
+		direction transaction.CashFlowDirection
+	}
+	groups := map[group][]*transaction.Transaction{}
+	var order []group
+	for _, tx := range txs {
+		g := group{account: tx.Account, currency: tx.Currency, key: GroupKey(tx), direction: tx.Direction}
+		if g.key == "" {
+			continue
+		}
+		if _, ok := groups[g]; !ok {
+			order = append(order, g)
+		}
+		groups[g] = append(groups[g], tx)
+	}
+	var series []*Series
+	for _, g := range order {
+		members := groups[g]
+		slices.SortStableFunc(members, func(a, b *transaction.Transaction) int {
+			return a.Date.Compare(b.Date)
+		})
+		for _, c := range clusters(members, tolerance) {
+			s := detectSeries(c, today)
+			if s == nil {
+				continue
+			}
+			s.Key = g.key
+			s.Account = g.account
+			s.Currency = g.currency
+			s.Direction = g.direction
+			s.CounterpartyID = c[0].CounterpartyID
+			s.Name = counterparty.DisplayName(counterparty.Normalise(c[len(c)-1].Description))
+			series = append(series, s)
+		}
+	}
+	return series
+}
+
+// GroupKey returns the key of the series the transaction can belong to, its
+// counterparty or normalised description. It is empty when neither is known.
+func GroupKey(tx *transaction.Transaction) string {
+	if tx.CounterpartyID != nil {
+		return "counterparty:" + tx.CounterpartyID.String()
+	}
+	if name := counterparty.Normalise(tx.Description); name != "" {
+		return "description:" + name
+	}
+	return ""
+}
+
+// clusters splits the date ordered transactions into runs of similar
+// amounts. A payment joins the cluster whose last amount is closest within
+// the tolerance, so gradual price changes stay in the same cluster.
+func clusters(txs []*transaction.Transaction, tolerance float64) [][]*transaction.Transaction {
+	var cs [][]*transaction.Transaction
+	for _, tx := range txs {
+		best, bestDiff := -1, 0.0
+		for i, c := range cs {
+			last := c[len(c)-1].AmountCents
+			diff := relativeDiff(last, tx.AmountCents)
+			if diff <= tolerance && (best < 0 || diff < bestDiff) {
+				best, bestDiff = i, diff
+			}
+		}
+		if best < 0 {
+			cs = append(cs, []*transaction.Transaction{tx})
+			continue
+		}
+		cs[best] = append(cs[best], tx)
+	}
+	return cs
+}
+
+func relativeDiff(a, b int64) float64 {
+	hi := max(a, b)
+	if hi == 0 {
+		return 0
+	}
+	d := a - b
+	if d < 0 {
+		d = -d
+	}
+	return float64(d) / float64(hi)
+}
+
+// detectSeries returns the series formed by the payments or nil when they
+// do not recur. Payments on the same day count once.
+func detectSeries(txs []*transaction.Transaction, today time.Time) *Series {
+	txs = slices.CompactFunc(slices.Clone(txs), func(a, b *transaction.Transaction) bool {
+		return a.Date.Equal(b.Date)
+	})
+	if len(txs) < 2 {
+		return nil
+	}
+	intervals := make([]int, 0, len(txs)-1)
+	for i := 1; i < len(txs); i++ {
+		intervals = append(intervals, int(txs[i].Date.Sub(txs[i-1].Date).Hours()/24))
+	}
+	median := slices.Clone(intervals)
+	slices.Sort(median)
+	m := median[len(median)/2]
+	// the interval windows of the cadences do not overlap
+	for _, c := range cadences {
+		if m < c.minDays || m > c.maxDays || len(txs) < c.minOccurrences {
+			continue
+		}
+		matching := 0
+		for _, d := range intervals {
+			if d >= c.minDays && d <= c.maxDays {
+				matching++
+			}
+		}
+		if matching*4 < len(intervals)*3 {
+			return nil
+		}
+		last := txs[len(txs)-1]
+		previous := last.AmountCents
+		for i := len(txs) - 2; i >= 0 && previous == last.AmountCents; i-- {
+			previous = txs[i].AmountCents
+		}
+		s := &Series{
+			ID:                  uuid.New(),
+			Frequency:           c.frequency,
+			AmountCents:         last.AmountCents,
+			PreviousAmountCents: previous,
+			FirstDate:           txs[0].Date,
+			LastDate:            last.Date,
+			NextDate:            c.frequency.Next(last.Date),
+			Occurrences:         len(txs),
+			CreatedAt:           time.Now().UTC(),
+			UpdatedAt:           time.Now().UTC(),
+		}
+		s.Status = s.status(today)
+		return s
+	}
+	return nil
+}
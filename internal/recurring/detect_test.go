@@ -0,0 +1,203 @@
+package recurring
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// pay is an outgoing payment from the checking account.
+func pay(desc, date string, cents int64) *transaction.Transaction {
+	d, _ := time.Parse(time.DateOnly, date)
+	return &transaction.Transaction{Description: desc, Date: d, AmountCents: cents, Direction: transaction.CashOut, Account: "NL91ABNA0417164300", Currency: "EUR"}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name  string
+		txs   []*transaction.Transaction
+		today string
+		// series as "frequency amount previous occurrences next status"
+		want []string
+	}{
+		{
+			name: "monthly",
+			txs: []*transaction.Transaction{
+				pay("NETFLIX.COM", "2025-01-15", 1099),
+				pay("NETFLIX.COM", "2025-02-15", 1099),
+				pay("NETFLIX.COM", "2025-03-15", 1099),
+				pay("NETFLIX.COM", "2025-04-15", 1099),
+			},
+			today: "2025-05-01",
+			want:  []string{"monthly 1099 1099 4 2025-05-15 active"},
+		},
+		{
+			name: "weekly",
+			txs: []*transaction.Transaction{
+				pay("BAKKER BART", "2025-03-01", 450),
+				pay("BAKKER BART", "2025-03-08", 450),
+				pay("BAKKER BART", "2025-03-15", 450),
+				pay("BAKKER BART", "2025-03-22", 450),
+			},
+			today: "2025-03-23",
+			want:  []string{"weekly 450 450 4 2025-03-29 active"},
+		},
+		{
+			// weekly payments need four occurrences
+			name: "too few weekly payments",
+			txs: []*transaction.Transaction{
+				pay("BAKKER BART", "2025-03-01", 450),
+				pay("BAKKER BART", "2025-03-08", 450),
+				pay("BAKKER BART", "2025-03-15", 450),
+			},
+			today: "2025-03-16",
+		},
+		{
+			name: "yearly",
+			txs: []*transaction.Transaction{
+				pay("ANWB", "2023-06-01", 5600),
+				pay("ANWB", "2024-06-03", 5900),
+			},
+			today: "2024-07-01",
+			want:  []string{"yearly 5900 5600 2 2025-06-03 active"},
+		},
+		{
+			// payment days drift within the window of the frequency
+			name: "late payments",
+			txs: []*transaction.Transaction{
+				pay("ZIGGO", "2025-01-28", 5500),
+				pay("ZIGGO", "2025-03-03", 5500),
+				pay("ZIGGO", "2025-03-28", 5500),
+			},
+			today: "2025-04-01",
+			want:  []string{"monthly 5500 5500 3 2025-04-28 active"},
+		},
+		{
+			// one outlier in four intervals still recurs
+			name: "one skipped month",
+			txs: []*transaction.Transaction{
+				pay("SPOTIFY", "2025-01-10", 1199),
+				pay("SPOTIFY", "2025-02-10", 1199),
+				pay("SPOTIFY", "2025-03-10", 1199),
+				pay("SPOTIFY", "2025-04-10", 1199),
+				pay("SPOTIFY", "2025-06-10", 1199),
+			},
+			today: "2025-06-20",
+			want:  []string{"monthly 1199 1199 5 2025-07-10 active"},
+		},
+		{
+			name: "irregular",
+			txs: []*transaction.Transaction{
+				pay("IKEA", "2025-01-10", 5000),
+				pay("IKEA", "2025-01-20", 5000),
+				pay("IKEA", "2025-03-01", 5000),
+				pay("IKEA", "2025-05-10", 5000),
+			},
+			today: "2025-06-01",
+		},
+		{
+			// a price increase within the tolerance continues the series
+			name: "price increase",
+			txs: []*transaction.Transaction{
+				pay("NETFLIX.COM", "2025-01-15", 1099),
+				pay("NETFLIX.COM", "2025-02-15", 1099),
+				pay("NETFLIX.COM", "2025-03-15", 1299),
+				pay("NETFLIX.COM", "2025-04-15", 1299),
+			},
+			today: "2025-05-01",
+			want:  []string{"monthly 1299 1099 4 2025-05-15 active"},
+		},
+		{
+			// amounts further apart than the tolerance are separate
+			// series, each payment joins the closest one
+			name: "two amounts of the same counterparty",
+			txs: []*transaction.Transaction{
+				pay("GEMEENTE", "2025-01-05", 2000),
+				pay("GEMEENTE", "2025-01-20", 9000),
+				pay("GEMEENTE", "2025-02-05", 2100),
+				pay("GEMEENTE", "2025-02-20", 9000),
+				pay("GEMEENTE", "2025-03-05", 2100),
+				pay("GEMEENTE", "2025-03-20", 9500),
+			},
+			today: "2025-03-25",
+			want: []string{
+				"monthly 2100 2000 3 2025-04-05 active",
+				"monthly 9500 9000 3 2025-04-20 active",
+			},
+		},
+		{
+			// payments on the same day count once
+			name: "same day",
+			txs: []*transaction.Transaction{
+				pay("NETFLIX.COM", "2025-01-15", 1099),
+				pay("NETFLIX.COM", "2025-01-15", 1099),
+				pay("NETFLIX.COM", "2025-02-15", 1099),
+			},
+			today: "2025-03-01",
+		},
+		{
+			name: "missed",
+			txs: []*transaction.Transaction{
+				pay("NETFLIX.COM", "2025-01-15", 1099),
+				pay("NETFLIX.COM", "2025-02-15", 1099),
+				pay("NETFLIX.COM", "2025-03-15", 1099),
+			},
+			today: "2025-04-23",
+			want:  []string{"monthly 1099 1099 3 2025-04-15 missed"},
+		},
+		{
+			name: "ended",
+			txs: []*transaction.Transaction{
+				pay("NETFLIX.COM", "2025-01-15", 1099),
+				pay("NETFLIX.COM", "2025-02-15", 1099),
+				pay("NETFLIX.COM", "2025-03-15", 1099),
+			},
+			today: "2025-06-23",
+			want:  []string{"monthly 1099 1099 3 2025-04-15 ended"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			today, _ := time.Parse(time.DateOnly, tt.today)
+			var got []string
+			for _, s := range Detect(tt.txs, today, DefaultTolerance) {
+				got = append(got, fmt.Sprintf("%s %d %d %d %s %s", s.Frequency, s.AmountCents, s.PreviousAmountCents, s.Occurrences, s.NextDate.Format(time.DateOnly), s.Status))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Detect() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectGroups(t *testing.T) {
+	// the same payments from two accounts and in both directions are four
+	// series
+	var txs []*transaction.Transaction
+	for _, date := range []string{"2025-01-15", "2025-02-15", "2025-03-15"} {
+		out := pay("J JANSEN", date, 50000)
+		in := pay("J JANSEN", date, 50000)
+		in.Direction = transaction.CashIn
+		savings := pay("J JANSEN", date, 50000)
+		savings.Account = "NL20INGB0001234567"
+		savingsIn := pay("J JANSEN", date, 50000)
+		savingsIn.Account, savingsIn.Direction = savings.Account, transaction.CashIn
+		txs = append(txs, out, in, savings, savingsIn)
+	}
+	today, _ := time.Parse(time.DateOnly, "2025-03-20")
+	var got []string
+	for _, s := range Detect(txs, today, DefaultTolerance) {
+		got = append(got, fmt.Sprintf("%s %s %s", s.Account, s.Direction, s.Key))
+	}
+	want := []string{
+		"NL91ABNA0417164300 out description:J JANSEN",
+		"NL91ABNA0417164300 in description:J JANSEN",
+		"NL20INGB0001234567 out description:J JANSEN",
+		"NL20INGB0001234567 in description:J JANSEN",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Detect() = %q, want %q", got, want)
+	}
+}
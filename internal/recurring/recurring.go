@@ -0,0 +1,164 @@
+package recurring
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+type Frequency string
+
+const (
+	Weekly    Frequency = "weekly"
+	Monthly   Frequency = "monthly"
+	Quarterly Frequency = "quarterly"
+	Yearly    Frequency = "yearly"
+)
+
+type Status string
+
+const (
+	StatusActive Status = "active"
+	// StatusMissed means the expected payment is overdue.
+	StatusMissed Status = "missed"
+	// StatusEnded means several payments in a row did not happen, the
+	// subscription was most likely cancelled.
+	StatusEnded Status = "ended"
+)
+
+// Series is a detected recurring payment or income, e.g. a subscription or
+// salary. Series are detected per account, counterparty, or normalised
+// description for transactions without one, and direction.
+type Series struct {
+	ID uuid.UUID `db:"id"`
+	// Key identifies the counterparty the series was detected for.
+	Key            string                        `db:"group_key"`
+	Name           string                        `db:"name"`
+	CounterpartyID *uuid.UUID                    `db:"counterparty_id"`
+	Account        string                        `db:"account"`
+	Currency       string                        `db:"currency"`
+	Direction      transaction.CashFlowDirection `db:"direction"`
+	Frequency      Frequency                     `db:"frequency"`
+	// AmountCents is the amount of the last payment, PreviousAmountCents the
+	// amount before the last price change.
+	AmountCents         int64     `db:"amount_cents"`
+	PreviousAmountCents int64     `db:"previous_amount_cents"`
+	FirstDate           time.Time `db:"first_date"`
+	LastDate            time.Time `db:"last_date"`
+	NextDate            time.Time `db:"next_date"`
+	Occurrences         int       `db:"occurrences"`
+	Status              Status    `db:"status"`
+	CreatedAt           time.Time `db:"created_at"`
+	UpdatedAt           time.Time `db:"updated_at"`
+}
+
+// PriceIncreased reports whether the last price change was an increase.
+func (s *Series) PriceIncreased() bool {
+	return s.AmountCents > s.PreviousAmountCents
+}
+
+// YearlyCents is the amount the series costs or yields per year at the last
+// amount.
+func (s *Series) YearlyCents() int64 {
+	return s.AmountCents * int64(cadences[s.Frequency].perYear)
+}
+
+// cadence describes the intervals in days that belong to a frequency.
+type cadence struct {
+	frequency Frequency
+	// minDays and maxDays bound the interval between two payments.
+	minDays, maxDays int
+	// grace is how many days a payment may be late before it is missed.
+	grace          int
+	perYear        int
+	minOccurrences int
+}
+
+var cadences = map[Frequency]cadence{
+	Weekly:    {frequency: Weekly, minDays: 5, maxDays: 9, grace: 3, perYear: 52, minOccurrences: 4},
+	Monthly:   {frequency: Monthly, minDays: 25, maxDays: 36, grace: 7, perYear: 12, minOccurrences: 3},
+	Quarterly: {frequency: Quarterly, minDays: 80, maxDays: 100, grace: 15, perYear: 4, minOccurrences: 3},
+	Yearly:    {frequency: Yearly, minDays: 350, maxDays: 380, grace: 30, perYear: 1, minOccurrences: 2},
+}
+
+// Next returns the date a payment following the one on date is expected.
+// Months are added without overflowing, a payment on January 31 is expected
+// on the last day of February.
+func (f Frequency) Next(date time.Time) time.Time {
+	return f.After(date, 1)
+}
+
+// After returns the date the n-th payment following the one on date is
+// expected. Unlike calling Next n times the day of the month does not drift
+// after a short month.
+func (f Frequency) After(date time.Time, n int) time.Time {
+	switch f {
+	case Weekly:
+		return date.AddDate(0, 0, 7*n)
+	case Quarterly:
+		return addMonths(date, 3*n)
+	case Yearly:
+		return addMonths(date, 12*n)
+	default:
+		return addMonths(date, n)
+	}
+}
+
+func addMonths(date time.Time, n int) time.Time {
+	y, m, d := date.Date()
+	last := time.Date(y, m+time.Month(n)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	return time.Date(y, m+time.Month(n), min(d, last), 0, 0, 0, 0, time.UTC)
+}
+
+// status returns whether the series is still paid as of today.
+func (s *Series) status(today time.Time) Status {
+	c := cadences[s.Frequency]
+	if !today.After(s.NextDate.AddDate(0, 0, c.grace)) {
+		return StatusActive
+	}
+	// two more missed payments end the series
+	if today.After(s.Frequency.Next(s.Frequency.Next(s.NextDate)).AddDate(0, 0, c.grace)) {
+		return StatusEnded
+	}
+	return StatusMissed
+}
+
+// Costs sums the yearly amounts of the series that have not ended in the
+// reporting currency.
+type Costs struct {
+	Currency           string
+	YearlyExpenseCents int64
+	YearlyIncomeCents  int64
+	// ExpensesByFrequency are the yearly expenses per frequency.
+	ExpensesByFrequency map[Frequency]int64
+	// Expenses are the outgoing series, the most expensive per year first.
+	Expenses []*Series
+	// YearlyCents is the yearly amount of every series in the reporting
+	// currency, the series themselves keep the amounts in their own.
+	YearlyCents map[uuid.UUID]int64
+}
+
+// Overview sums the series in the currency, yearly holds the yearly amount
+// of every series converted to it.
+func Overview(series []*Series, currency string, yearly map[uuid.UUID]int64) Costs {
+	c := Costs{Currency: currency, ExpensesByFrequency: map[Frequency]int64{}, Expenses: []*Series{}, YearlyCents: yearly}
+	for _, s := range series {
+		if s.Status == StatusEnded {
+			continue
+		}
+		if s.Direction == transaction.CashIn {
+			c.YearlyIncomeCents += yearly[s.ID]
+			continue
+		}
+		c.YearlyExpenseCents += yearly[s.ID]
+		c.ExpensesByFrequency[s.Frequency] += yearly[s.ID]
+		c.Expenses = append(c.Expenses, s)
+	}
+	slices.SortStableFunc(c.Expenses, func(a, b *Series) int {
+		return cmp.Compare(yearly[b.ID], yearly[a.ID])
+	})
+	return c
+}
@@ -0,0 +1,73 @@
+package refund
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// Single-use interfaces only used by DetectHandler
+
+type IncomingFetcher interface {
+	FetchIncomingByImport(ctx context.Context, importID uuid.UUID) ([]*transaction.Transaction, error)
+}
+
+type CandidateFinder interface {
+	// FindCandidates returns outgoing transactions from the same counterparty
+	// as the refund, dated at most windowDays before it, with a remaining
+	// (not yet refunded) amount of at least the refund amount. The best
+	// candidate is expected first.
+	FindCandidates(ctx context.Context, refund *transaction.Transaction, windowDays int) ([]*transaction.Transaction, error)
+}
+
+type DetectHandler struct {
+	inf        IncomingFetcher
+	cf         CandidateFinder
+	lc         LinkCreator
+	windowDays int
+}
+
+func NewDetectHandler(inf IncomingFetcher, cf CandidateFinder, lc LinkCreator, windowDays int) *DetectHandler {
+	if windowDays <= 0 {
+		windowDays = DefaultWindowDays
+	}
+	return &DetectHandler{
+		inf:        inf,
+		cf:         cf,
+		lc:         lc,
+		windowDays: windowDays,
+	}
+}
+
+// Handle suggests refund links for the incoming transactions of the given
+// import and returns the number of links suggested.
+func (h *DetectHandler) Handle(ctx context.Context, importID uuid.UUID) (int, error) {
+	incoming, err := h.inf.FetchIncomingByImport(ctx, importID)
+	if err != nil {
+		return 0, err
+	}
+	suggested := 0
+	for _, tx := range incoming {
+		candidates, err := h.cf.FindCandidates(ctx, tx, h.windowDays)
+		if err != nil {
+			return suggested, err
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+		link, err := NewLink(candidates[0], tx)
+		if err != nil {
+			continue
+		}
+		if err := h.lc.Create(ctx, link); err != nil {
+			if errors.Is(err, ErrLinkAlreadyExists) {
+				continue
+			}
+			return suggested, err
+		}
+		suggested++
+	}
+	return suggested, nil
+}
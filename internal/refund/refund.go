@@ -0,0 +1,97 @@
+package refund
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+type LinkStatus string
+
+const (
+	LinkStatusSuggested LinkStatus = "suggested"
+	LinkStatusConfirmed LinkStatus = "confirmed"
+	LinkStatusRejected  LinkStatus = "rejected"
+)
+
+// DefaultWindowDays is the number of days between the original purchase and
+// the refund that is searched when no window is configured.
+const DefaultWindowDays = 60
+
+// Link connects an incoming refund (or reversal) to the earlier outgoing
+// transaction it pays back.
+type Link struct {
+	ID          uuid.UUID  `db:"id"`
+	OriginalID  uuid.UUID  `db:"original_id"`
+	RefundID    uuid.UUID  `db:"refund_id"`
+	AmountCents int64      `db:"amount_cents"`
+	Status      LinkStatus `db:"status"`
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at"`
+}
+
+var (
+	ErrLinkNotFound      = fmt.Errorf("refund link not found")
+	ErrLinkAlreadyExists = fmt.Errorf("refund link already exists for the given transactions")
+	ErrLinkNotSuggested  = fmt.Errorf("refund link is no longer a suggestion")
+	ErrInvalidLink       = fmt.Errorf("a refund must be incoming and link to an outgoing transaction")
+	ErrRefundTooLarge    = fmt.Errorf("refund amount exceeds the original transaction amount")
+)
+
+// Shared interfaces used by multiple use cases
+
+type LinkCreator interface {
+	Create(ctx context.Context, link *Link) error
+}
+
+type LinkFetcher interface {
+	FetchByID(ctx context.Context, id uuid.UUID) (*Link, error)
+}
+
+type LinkUpdater interface {
+	UpdateStatus(ctx context.Context, link *Link) error
+}
+
+// NewLink creates a suggested link between an outgoing original transaction
+// and an incoming refund transaction.
+func NewLink(original, refund *transaction.Transaction) (*Link, error) {
+	if original.Direction != transaction.CashOut || refund.Direction != transaction.CashIn {
+		return nil, ErrInvalidLink
+	}
+	if refund.AmountCents > original.AmountCents {
+		return nil, ErrRefundTooLarge
+	}
+	return &Link{
+		ID:          uuid.New(),
+		OriginalID:  original.ID,
+		RefundID:    refund.ID,
+		AmountCents: refund.AmountCents,
+		Status:      LinkStatusSuggested,
+		CreatedAt:   time.Now().UTC(),
+		UpdatedAt:   time.Now().UTC(),
+	}, nil
+}
+
+// Confirm marks a suggested link as confirmed by the user.
+func (l *Link) Confirm() error {
+	if l.Status != LinkStatusSuggested {
+		return ErrLinkNotSuggested
+	}
+	l.Status = LinkStatusConfirmed
+	l.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// Reject marks a suggested link as rejected, rejected links are kept so the
+// same pair is not suggested again.
+func (l *Link) Reject() error {
+	if l.Status != LinkStatusSuggested {
+		return ErrLinkNotSuggested
+	}
+	l.Status = LinkStatusRejected
+	l.UpdatedAt = time.Now().UTC()
+	return nil
+}
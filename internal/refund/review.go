@@ -0,0 +1,41 @@
+package refund
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type ReviewHandler struct {
+	lf LinkFetcher
+	lu LinkUpdater
+}
+
+func NewReviewHandler(lf LinkFetcher, lu LinkUpdater) *ReviewHandler {
+	return &ReviewHandler{lf: lf, lu: lu}
+}
+
+// Confirm accepts a suggested link, from then on the refund is netted against
+// the category of the original transaction in reports.
+func (h *ReviewHandler) Confirm(ctx context.Context, id uuid.UUID) (*Link, error) {
+	link, err := h.lf.FetchByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := link.Confirm(); err != nil {
+		return nil, err
+	}
+	return link, h.lu.UpdateStatus(ctx, link)
+}
+
+// Reject dismisses a suggested link.
+func (h *ReviewHandler) Reject(ctx context.Context, id uuid.UUID) (*Link, error) {
+	link, err := h.lf.FetchByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := link.Reject(); err != nil {
+		return nil, err
+	}
+	return link, h.lu.UpdateStatus(ctx, link)
+}
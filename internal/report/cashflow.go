@@ -0,0 +1,105 @@
+package report
+
+import (
+	"fmt"
+	"time"
+)
+
+// Interval is the length of the buckets a report is split into.
+type Interval string
+
+const (
+	Week    Interval = "week"
+	Month   Interval = "month"
+	Quarter Interval = "quarter"
+	Year    Interval = "year"
+)
+
+// GroupBy splits the buckets of a cash flow report further, the zero value
+// reports a single total per bucket.
+type GroupBy string
+
+const (
+	ByTag          GroupBy = "tag"
+	ByAccount      GroupBy = "account"
+	ByCounterparty GroupBy = "counterparty"
+)
+
+var (
+	ErrInvalidInterval = fmt.Errorf("interval must be week, month, quarter or year")
+	ErrInvalidGroupBy  = fmt.Errorf("group_by must be tag, account or counterparty")
+)
+
+// ParseInterval returns the interval with the given name, month when it is
+// empty.
+func ParseInterval(s string) (Interval, error) {
+	switch i := Interval(s); i {
+	case "":
+		return Month, nil
+	case Week, Month, Quarter, Year:
+		return i, nil
+	default:
+		return "", ErrInvalidInterval
+	}
+}
+
+func ParseGroupBy(s string) (GroupBy, error) {
+	switch g := GroupBy(s); g {
+	case "", ByTag, ByAccount, ByCounterparty:
+		return g, nil
+	default:
+		return "", ErrInvalidGroupBy
+	}
+}
+
+// Start returns the first day of the bucket containing date, weeks start on
+// Monday as with date_trunc.
+func (i Interval) Start(date time.Time) time.Time {
+	y, m, d := date.Date()
+	switch i {
+	case Week:
+		offset := (int(date.Weekday()) + 6) % 7
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, time.UTC)
+	case Quarter:
+		return time.Date(y, m-(m-1)%3, 1, 0, 0, 0, 0, time.UTC)
+	case Year:
+		return time.Date(y, time.January, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// Next returns the first day of the bucket following the one starting at
+// start.
+func (i Interval) Next(start time.Time) time.Time {
+	switch i {
+	case Week:
+		return start.AddDate(0, 0, 7)
+	case Quarter:
+		return start.AddDate(0, 3, 0)
+	case Year:
+		return start.AddDate(1, 0, 0)
+	default:
+		return start.AddDate(0, 1, 0)
+	}
+}
+
+// End returns the last day of the bucket starting at start.
+func (i Interval) End(start time.Time) time.Time {
+	return i.Next(start).AddDate(0, 0, -1)
+}
+
+// Cashflow is the income and expenses of a group within a bucket. Amounts
+// are positive, expenses are the outgoing transactions. Unconverted counts
+// the transactions left out because their currency has no exchange rate.
+type Cashflow struct {
+	Start        time.Time `db:"start"`
+	Group        string    `db:"grp"`
+	IncomeCents  int64     `db:"income_cents"`
+	ExpenseCents int64     `db:"expense_cents"`
+	Unconverted  int       `db:"unconverted"`
+}
+
+func (c Cashflow) NetCents() int64 {
+	return c.IncomeCents - c.ExpenseCents
+}
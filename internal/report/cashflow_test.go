@@ -0,0 +1,127 @@
+package report
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseInterval(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Interval
+		wantErr error
+	}{
+		{"", Month, nil},
+		{"week", Week, nil},
+		{"month", Month, nil},
+		{"quarter", Quarter, nil},
+		{"year", Year, nil},
+		{"day", "", ErrInvalidInterval},
+		{"Month", "", ErrInvalidInterval},
+	}
+	for _, tt := range tests {
+		got, err := ParseInterval(tt.in)
+		if got != tt.want || !errors.Is(err, tt.wantErr) {
+			t.Errorf("ParseInterval(%q) = %q, %v, want %q, %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseGroupBy(t *testing.T) {
+	for _, in := range []string{"", "tag", "account", "counterparty"} {
+		if got, err := ParseGroupBy(in); err != nil || string(got) != in {
+			t.Errorf("ParseGroupBy(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseGroupBy("month"); !errors.Is(err, ErrInvalidGroupBy) {
+		t.Errorf("ParseGroupBy(month) error = %v, want ErrInvalidGroupBy", err)
+	}
+}
+
+func TestIntervalBuckets(t *testing.T) {
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+	auckland, err := time.LoadLocation("Pacific/Auckland")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+	day := func(s string) time.Time {
+		d, err := time.Parse(time.DateOnly, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	tests := []struct {
+		name     string
+		interval Interval
+		date     time.Time
+		start    string
+		end      string
+	}{
+		{"week of a monday", Week, day("2024-01-01"), "2024-01-01", "2024-01-07"},
+		{"week of a sunday", Week, day("2024-01-07"), "2024-01-01", "2024-01-07"},
+		{"week across the year end", Week, day("2024-01-03"), "2024-01-01", "2024-01-07"},
+		{"week starting in the previous year", Week, day("2021-01-01"), "2020-12-28", "2021-01-03"},
+		{"week across a month end", Week, day("2024-03-01"), "2024-02-26", "2024-03-03"},
+		{"first day of a month", Month, day("2024-03-01"), "2024-03-01", "2024-03-31"},
+		{"last day of a month", Month, day("2024-01-31"), "2024-01-01", "2024-01-31"},
+		{"february of a leap year", Month, day("2024-02-29"), "2024-02-01", "2024-02-29"},
+		{"february", Month, day("2023-02-15"), "2023-02-01", "2023-02-28"},
+		{"december", Month, day("2023-12-31"), "2023-12-01", "2023-12-31"},
+		{"first quarter", Quarter, day("2024-03-31"), "2024-01-01", "2024-03-31"},
+		{"second quarter", Quarter, day("2024-04-01"), "2024-04-01", "2024-06-30"},
+		{"fourth quarter", Quarter, day("2024-12-31"), "2024-10-01", "2024-12-31"},
+		{"year", Year, day("2024-07-15"), "2024-01-01", "2024-12-31"},
+		// dates are plain dates, the day in the time zone of the date counts
+		// and buckets are reported in UTC
+		{"local date just after midnight", Month, time.Date(2024, 4, 1, 0, 30, 0, 0, amsterdam), "2024-04-01", "2024-04-30"},
+		{"local date just before midnight", Month, time.Date(2024, 3, 31, 23, 30, 0, 0, amsterdam), "2024-03-01", "2024-03-31"},
+		{"ahead of UTC on new year", Year, time.Date(2025, 1, 1, 8, 0, 0, 0, auckland), "2025-01-01", "2025-12-31"},
+		{"week on a DST change", Week, time.Date(2024, 3, 31, 12, 0, 0, 0, amsterdam), "2024-03-25", "2024-03-31"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := tt.interval.Start(tt.date)
+			if got := start.Format(time.DateOnly); got != tt.start {
+				t.Errorf("Start() = %s, want %s", got, tt.start)
+			}
+			if start.Location() != time.UTC || start.Hour() != 0 {
+				t.Errorf("Start() = %s, want midnight UTC", start)
+			}
+			if got := tt.interval.End(start).Format(time.DateOnly); got != tt.end {
+				t.Errorf("End() = %s, want %s", got, tt.end)
+			}
+			if next := tt.interval.Next(start); tt.interval.Start(next) != next || !next.After(tt.interval.End(start)) {
+				t.Errorf("Next() = %s does not start the following bucket", next)
+			}
+		})
+	}
+}
+
+func TestIntervalConsecutiveBuckets(t *testing.T) {
+	// walking the buckets covers every day exactly once
+	for _, interval := range []Interval{Week, Month, Quarter, Year} {
+		from := time.Date(2023, 11, 15, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC)
+		start := interval.Start(from)
+		for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+			if d.After(interval.End(start)) {
+				start = interval.Next(start)
+			}
+			if got := interval.Start(d); got != start {
+				t.Fatalf("%s: Start(%s) = %s, want %s", interval, d.Format(time.DateOnly), got.Format(time.DateOnly), start.Format(time.DateOnly))
+			}
+		}
+	}
+}
+
+func TestCashflowNet(t *testing.T) {
+	c := Cashflow{IncomeCents: 250000, ExpenseCents: 312345}
+	if got := c.NetCents(); got != -62345 {
+		t.Errorf("NetCents() = %d, want -62345", got)
+	}
+}
@@ -0,0 +1,59 @@
+package review
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// DefaultBelow is the confidence under which machine tags are reviewed when
+// no threshold is configured.
+const DefaultBelow = 0.9
+
+var (
+	ErrNotReviewable = fmt.Errorf("transaction tag was not chosen by a machine tagger")
+)
+
+// Single-use interfaces only used by TagHandler
+
+type TransactionFetcher interface {
+	FetchByID(ctx context.Context, id uuid.UUID) (*transaction.Transaction, error)
+}
+
+type TransactionTagger interface {
+	Tag(ctx context.Context, id uuid.UUID, tag string, p transaction.TagProvenance) error
+}
+
+// TagHandler accepts or corrects the tag a machine tagger chose. Either way
+// the tag becomes a manual tag and leaves the review queue.
+type TagHandler struct {
+	tf TransactionFetcher
+	tt TransactionTagger
+}
+
+func NewTagHandler(tf TransactionFetcher, tt TransactionTagger) *TagHandler {
+	return &TagHandler{tf: tf, tt: tt}
+}
+
+// Handle sets the tag of the transaction, an empty tag accepts the current
+// one. It returns the reviewed transaction and the tag it had before.
+func (h *TagHandler) Handle(ctx context.Context, id uuid.UUID, tag string) (*transaction.Transaction, string, error) {
+	tx, err := h.tf.FetchByID(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if !tx.TagSource.IsMachine() {
+		return nil, "", ErrNotReviewable
+	}
+	previousTag := tx.Tag
+	if tag == "" {
+		tag = tx.Tag
+	}
+	tx.SetTag(tag, transaction.ManualTag())
+	if err := h.tt.Tag(ctx, tx.ID, tx.Tag, tx.TagProvenance); err != nil {
+		return nil, "", err
+	}
+	return tx, previousTag, nil
+}
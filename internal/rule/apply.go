@@ -0,0 +1,79 @@
+package rule
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// Single-use interfaces only used by BackfillHandler
+
+type TransactionLister interface {
+	FetchAll(ctx context.Context) ([]*transaction.Transaction, error)
+}
+
+type TransactionClassifier interface {
+	// UpdateClassification persists the tag, ignored flag and note of tx.
+	UpdateClassification(ctx context.Context, tx *transaction.Transaction) error
+}
+
+type TxRunner interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// BackfillHandler applies a single rule to the transactions already stored.
+type BackfillHandler struct {
+	tr TxRunner
+	rf RuleFetcher
+	tl TransactionLister
+	tc TransactionClassifier
+}
+
+func NewBackfillHandler(tr TxRunner, rf RuleFetcher, tl TransactionLister, tc TransactionClassifier) *BackfillHandler {
+	return &BackfillHandler{tr: tr, rf: rf, tl: tl, tc: tc}
+}
+
+// Handle applies the rule to all stored transactions, or only the untagged
+// ones, and returns the changes. With dryRun nothing is written. The rule is
+// applied even when it is disabled, which allows trying out a rule before
+// enabling it.
+func (h *BackfillHandler) Handle(ctx context.Context, id uuid.UUID, dryRun, onlyUntagged bool) ([]Change, error) {
+	r, err := h.rf.FetchByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	enabled := *r
+	enabled.Enabled = true
+	engine := NewEngine([]*Rule{&enabled})
+
+	changes := []Change{}
+	err = h.tr.WithTx(ctx, func(ctx context.Context) error {
+		txs, err := h.tl.FetchAll(ctx)
+		if err != nil {
+			return err
+		}
+		for _, tx := range txs {
+			if onlyUntagged && tx.Tag != "" {
+				continue
+			}
+			change := engine.Evaluate(tx)
+			if !change.Changed() {
+				continue
+			}
+			changes = append(changes, change)
+			if dryRun {
+				continue
+			}
+			change.ApplyTo(tx)
+			if err := h.tc.UpdateClassification(ctx, tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
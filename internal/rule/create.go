@@ -0,0 +1,85 @@
+package rule
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Single-use interfaces only used by the create and update handlers
+
+type RuleCreator interface {
+	Create(ctx context.Context, r *Rule) error
+}
+
+type RuleUpdater interface {
+	Update(ctx context.Context, r *Rule) error
+}
+
+// Definition holds the user supplied fields of a rule.
+type Definition struct {
+	Name       string
+	Priority   int
+	Enabled    bool
+	Conditions Conditions
+	Actions    Actions
+}
+
+type CreateHandler struct {
+	rc RuleCreator
+}
+
+func NewCreateHandler(rc RuleCreator) *CreateHandler {
+	return &CreateHandler{rc: rc}
+}
+
+func (h *CreateHandler) Handle(ctx context.Context, d Definition) (*Rule, error) {
+	r, err := NewRule(d.Name, d.Priority, d.Enabled, d.Conditions, d.Actions)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.rc.Create(ctx, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+type UpdateHandler struct {
+	rf RuleFetcher
+	ru RuleUpdater
+}
+
+func NewUpdateHandler(rf RuleFetcher, ru RuleUpdater) *UpdateHandler {
+	return &UpdateHandler{rf: rf, ru: ru}
+}
+
+// Handle replaces the definition of an existing rule.
+func (h *UpdateHandler) Handle(ctx context.Context, id uuid.UUID, d Definition) (*Rule, error) {
+	r, err := h.rf.FetchByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.Name = d.Name
+	r.Priority = d.Priority
+	r.Enabled = d.Enabled
+	r.Conditions = d.Conditions
+	r.Actions = d.Actions
+	r.UpdatedAt = time.Now().UTC()
+	if err := r.Compile(); err != nil {
+		return nil, err
+	}
+	if err := h.ru.Update(ctx, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// LoadEngine creates an engine from the enabled rules in storage.
+func LoadEngine(ctx context.Context, rf EnabledRulesFetcher) (*Engine, error) {
+	rules, err := rf.FetchEnabled(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewEngine(rules), nil
+}
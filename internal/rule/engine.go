@@ -0,0 +1,117 @@
+package rule
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// Change describes the difference a rule run makes to a single transaction.
+type Change struct {
+	TransactionID uuid.UUID
+	Description   string
+	Date          time.Time
+	MatchedRules  []uuid.UUID
+	Tag           *FieldChange[string]
+	Ignored       *FieldChange[bool]
+	Note          *FieldChange[string]
+	// TagRuleID is the rule that decided the tag change.
+	TagRuleID uuid.UUID
+}
+
+type FieldChange[T any] struct {
+	From T
+	To   T
+}
+
+// Changed reports whether any field of the transaction changes.
+func (c Change) Changed() bool {
+	return c.Tag != nil || c.Ignored != nil || c.Note != nil
+}
+
+// Engine applies rules to transactions. Rules are evaluated in ascending
+// priority (ties broken by creation time) and for every field the first
+// matching rule that sets it wins, so a lower priority rule never overrides
+// a field set by a higher priority one.
+type Engine struct {
+	rules []*Rule
+}
+
+// NewEngine creates an engine for the enabled rules among rules. The rules are
+// expected to be compiled.
+func NewEngine(rules []*Rule) *Engine {
+	enabled := slices.DeleteFunc(slices.Clone(rules), func(r *Rule) bool { return !r.Enabled })
+	slices.SortStableFunc(enabled, func(a, b *Rule) int {
+		if a.Priority != b.Priority {
+			return a.Priority - b.Priority
+		}
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return &Engine{rules: enabled}
+}
+
+// Evaluate computes the change the rules would make to tx without modifying it.
+func (e *Engine) Evaluate(tx *transaction.Transaction) Change {
+	change := Change{TransactionID: tx.ID, Description: tx.Description, Date: tx.Date}
+	var tagSet, ignoredSet, noteSet bool
+	for _, r := range e.rules {
+		if !r.Matches(tx) {
+			continue
+		}
+		change.MatchedRules = append(change.MatchedRules, r.ID)
+		a := r.Actions
+		if a.SetTag != nil && !tagSet {
+			tagSet = true
+			if *a.SetTag != tx.Tag {
+				change.Tag = &FieldChange[string]{From: tx.Tag, To: *a.SetTag}
+				change.TagRuleID = r.ID
+			}
+		}
+		if a.SetIgnored != nil && !ignoredSet {
+			ignoredSet = true
+			if *a.SetIgnored != tx.Ignored {
+				change.Ignored = &FieldChange[bool]{From: tx.Ignored, To: *a.SetIgnored}
+			}
+		}
+		if a.SetNote != nil && !noteSet {
+			noteSet = true
+			if *a.SetNote != tx.Note {
+				change.Note = &FieldChange[string]{From: tx.Note, To: *a.SetNote}
+			}
+		}
+	}
+	return change
+}
+
+// TagRule returns the rule that decides the tag of tx, or nil when no
+// matching rule sets a tag.
+func (e *Engine) TagRule(tx *transaction.Transaction) *Rule {
+	for _, r := range e.rules {
+		if r.Actions.SetTag != nil && r.Matches(tx) {
+			return r
+		}
+	}
+	return nil
+}
+
+// Apply evaluates the rules and writes the resulting change to tx.
+func (e *Engine) Apply(tx *transaction.Transaction) Change {
+	change := e.Evaluate(tx)
+	change.ApplyTo(tx)
+	return change
+}
+
+// ApplyTo writes the change to tx.
+func (c Change) ApplyTo(tx *transaction.Transaction) {
+	if c.Tag != nil {
+		tx.SetTag(c.Tag.To, transaction.NewTagProvenance(transaction.TagSourceRule, 1, c.TagRuleID.String()))
+	}
+	if c.Ignored != nil {
+		tx.Ignored = c.Ignored.To
+	}
+	if c.Note != nil {
+		tx.Note = c.Note.To
+	}
+}
@@ -0,0 +1,159 @@
+package rule
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// testRule returns a compiled rule created the given number of minutes
+// after the first rule.
+func testRule(t *testing.T, name string, priority, minute int, c Conditions, a Actions) *Rule {
+	t.Helper()
+	r, err := NewRule(name, priority, true, c, a)
+	if err != nil {
+		t.Fatalf("NewRule(%s) error = %v", name, err)
+	}
+	r.CreatedAt = time.Date(2024, 1, 1, 0, minute, 0, 0, time.UTC)
+	return r
+}
+
+func testTransaction() *transaction.Transaction {
+	return &transaction.Transaction{
+		ID:          uuid.New(),
+		Description: "HUUR JANUARI J JANSEN",
+		AmountCents: 150000,
+		Direction:   transaction.CashOut,
+		Account:     "NL91ABNA0417164300",
+		Date:        time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestEnginePrecedence(t *testing.T) {
+	housing := testRule(t, "housing", 10, 0, Conditions{DescriptionRegex: "huur"}, Actions{SetTag: ptr("housing")})
+	rent := testRule(t, "rent", 10, 1, Conditions{DescriptionRegex: "huur"}, Actions{SetTag: ptr("rent")})
+	jansen := testRule(t, "jansen", 20, 2, Conditions{DescriptionRegex: "jansen"}, Actions{SetTag: ptr("gifts"), SetNote: ptr("landlord")})
+	joint := testRule(t, "joint account", 5, 3, Conditions{Account: "NL91 ABNA 0417 1643 00"}, Actions{SetIgnored: ptr(true)})
+	netflix := testRule(t, "netflix", 1, 4, Conditions{DescriptionRegex: "netflix"}, Actions{SetTag: ptr("subscriptions")})
+	disabled := testRule(t, "disabled", 1, 5, Conditions{DescriptionRegex: "huur"}, Actions{SetTag: ptr("other")})
+	disabled.Enabled = false
+
+	tests := []struct {
+		name    string
+		rules   []*Rule
+		tag     string
+		want    string
+		ignored bool
+		note    string
+		tagRule *Rule
+		matched []*Rule
+	}{
+		{
+			name:    "lower priority number wins",
+			rules:   []*Rule{jansen, housing},
+			want:    "housing",
+			note:    "landlord",
+			tagRule: housing,
+			matched: []*Rule{housing, jansen},
+		},
+		{
+			name:    "ties go to the oldest rule",
+			rules:   []*Rule{rent, housing},
+			want:    "housing",
+			tagRule: housing,
+			matched: []*Rule{housing, rent},
+		},
+		{
+			name:    "disabled rules are skipped",
+			rules:   []*Rule{disabled, housing},
+			want:    "housing",
+			tagRule: housing,
+			matched: []*Rule{housing},
+		},
+		{
+			name:    "rules that do not match are skipped",
+			rules:   []*Rule{netflix, jansen},
+			want:    "gifts",
+			note:    "landlord",
+			tagRule: jansen,
+			matched: []*Rule{jansen},
+		},
+		{
+			name:    "every field from the first rule that sets it",
+			rules:   []*Rule{jansen, housing, joint},
+			want:    "housing",
+			ignored: true,
+			note:    "landlord",
+			tagRule: housing,
+			matched: []*Rule{joint, housing, jansen},
+		},
+		{
+			// the first rule keeps the tag the transaction already has, a
+			// later rule does not get to change it
+			name:    "a kept tag is not overridden",
+			rules:   []*Rule{housing, jansen},
+			tag:     "housing",
+			want:    "housing",
+			note:    "landlord",
+			matched: []*Rule{housing, jansen},
+		},
+		{
+			name: "no rules",
+			tag:  "groceries",
+			want: "groceries",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := testTransaction()
+			tx.Tag = tt.tag
+			e := NewEngine(tt.rules)
+			change := e.Apply(tx)
+			if tx.Tag != tt.want || tx.Ignored != tt.ignored || tx.Note != tt.note {
+				t.Errorf("Apply() = tag %q, ignored %v, note %q, want %q, %v, %q", tx.Tag, tx.Ignored, tx.Note, tt.want, tt.ignored, tt.note)
+			}
+			var matched []uuid.UUID
+			for _, r := range tt.matched {
+				matched = append(matched, r.ID)
+			}
+			if !slices.Equal(change.MatchedRules, matched) {
+				t.Errorf("MatchedRules = %v, want %v", change.MatchedRules, matched)
+			}
+			if tt.tagRule == nil {
+				if change.Tag != nil {
+					t.Errorf("Tag = %+v, want no change", change.Tag)
+				}
+				return
+			}
+			if change.TagRuleID != tt.tagRule.ID || tx.TagProvenance.TaggedBy != tt.tagRule.ID.String() ||
+				tx.TagProvenance.TagSource != transaction.TagSourceRule {
+				t.Errorf("tag decided by %s (%+v), want %s", change.TagRuleID, tx.TagProvenance, tt.tagRule.Name)
+			}
+			if got := e.TagRule(testTransaction()); got != tt.tagRule {
+				t.Errorf("TagRule() = %v, want %s", got, tt.tagRule.Name)
+			}
+		})
+	}
+}
+
+func TestEngineEvaluateLeavesTransaction(t *testing.T) {
+	housing := testRule(t, "housing", 10, 0, Conditions{DescriptionRegex: "huur"}, Actions{SetTag: ptr("housing"), SetIgnored: ptr(true)})
+	tx := testTransaction()
+	change := NewEngine([]*Rule{housing}).Evaluate(tx)
+	if !change.Changed() || change.Tag.To != "housing" || !change.Ignored.To {
+		t.Errorf("Evaluate() = %+v", change)
+	}
+	if tx.Tag != "" || tx.Ignored {
+		t.Errorf("Evaluate() modified the transaction")
+	}
+	if NewEngine([]*Rule{housing}).Evaluate(&transaction.Transaction{Description: "NETFLIX"}).Changed() {
+		t.Errorf("Evaluate() changed a transaction no rule matches")
+	}
+}
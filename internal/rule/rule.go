@@ -0,0 +1,165 @@
+package rule
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// Conditions a transaction has to satisfy for a rule to match. Empty fields
+// are ignored, all set fields have to match.
+type Conditions struct {
+	DescriptionRegex string                        `json:"descriptionRegex,omitempty"`
+	NoteRegex        string                        `json:"noteRegex,omitempty"`
+	CounterpartyID   *uuid.UUID                    `json:"counterpartyId,omitempty"`
+	MinAmountCents   *int64                        `json:"minAmountCents,omitempty"`
+	MaxAmountCents   *int64                        `json:"maxAmountCents,omitempty"`
+	Direction        transaction.CashFlowDirection `json:"direction,omitempty"`
+	Account          string                        `json:"account,omitempty"`
+	Weekdays         []time.Weekday                `json:"weekdays,omitempty"`
+}
+
+// Actions applied to a transaction when a rule matches. Nil fields are left
+// untouched.
+type Actions struct {
+	SetTag     *string `json:"setTag,omitempty"`
+	SetIgnored *bool   `json:"setIgnored,omitempty"`
+	SetNote    *string `json:"setNote,omitempty"`
+}
+
+// Rule deterministically classifies transactions. Rules are evaluated in
+// ascending priority, see Engine.
+type Rule struct {
+	ID         uuid.UUID
+	Name       string
+	Priority   int
+	Enabled    bool
+	Conditions Conditions
+	Actions    Actions
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+
+	description *regexp.Regexp
+	note        *regexp.Regexp
+}
+
+var (
+	ErrRuleNotFound     = fmt.Errorf("rule not found")
+	ErrInvalidName      = fmt.Errorf("rule name cannot be empty")
+	ErrNoConditions     = fmt.Errorf("rule needs at least one condition")
+	ErrNoActions        = fmt.Errorf("rule needs at least one action")
+	ErrInvalidRegex     = fmt.Errorf("invalid regular expression")
+	ErrInvalidAmount    = fmt.Errorf("invalid amount range")
+	ErrInvalidDirection = fmt.Errorf("direction must be in or out")
+)
+
+// Shared interfaces used by multiple use cases
+
+type RuleFetcher interface {
+	FetchByID(ctx context.Context, id uuid.UUID) (*Rule, error)
+}
+
+type EnabledRulesFetcher interface {
+	FetchEnabled(ctx context.Context) ([]*Rule, error)
+}
+
+// NewRule creates and validates a new rule.
+func NewRule(name string, priority int, enabled bool, c Conditions, a Actions) (*Rule, error) {
+	r := &Rule{
+		ID:         uuid.New(),
+		Name:       strings.TrimSpace(name),
+		Priority:   priority,
+		Enabled:    enabled,
+		Conditions: c,
+		Actions:    a,
+		CreatedAt:  time.Now().UTC(),
+		UpdatedAt:  time.Now().UTC(),
+	}
+	if err := r.Compile(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Compile validates the rule and prepares its regular expressions. Rules read
+// from storage need to be compiled before they can be matched.
+func (r *Rule) Compile() error {
+	if r.Name == "" {
+		return ErrInvalidName
+	}
+	c := r.Conditions
+	if c.DescriptionRegex == "" && c.NoteRegex == "" && c.CounterpartyID == nil &&
+		c.MinAmountCents == nil && c.MaxAmountCents == nil && c.Direction == "" &&
+		c.Account == "" && len(c.Weekdays) == 0 {
+		return ErrNoConditions
+	}
+	a := r.Actions
+	if a.SetTag == nil && a.SetIgnored == nil && a.SetNote == nil {
+		return ErrNoActions
+	}
+	if c.Direction != "" && c.Direction != transaction.CashIn && c.Direction != transaction.CashOut {
+		return ErrInvalidDirection
+	}
+	if (c.MinAmountCents != nil && *c.MinAmountCents < 0) ||
+		(c.MaxAmountCents != nil && *c.MaxAmountCents < 0) ||
+		(c.MinAmountCents != nil && c.MaxAmountCents != nil && *c.MinAmountCents > *c.MaxAmountCents) {
+		return ErrInvalidAmount
+	}
+	var err error
+	if r.description, err = compile(c.DescriptionRegex); err != nil {
+		return err
+	}
+	if r.note, err = compile(c.NoteRegex); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Matches reports whether all conditions of the rule hold for tx.
+func (r *Rule) Matches(tx *transaction.Transaction) bool {
+	c := r.Conditions
+	if r.description != nil && !r.description.MatchString(tx.Description) {
+		return false
+	}
+	if r.note != nil && !r.note.MatchString(tx.Note) {
+		return false
+	}
+	if c.CounterpartyID != nil && (tx.CounterpartyID == nil || *tx.CounterpartyID != *c.CounterpartyID) {
+		return false
+	}
+	if c.MinAmountCents != nil && tx.AmountCents < *c.MinAmountCents {
+		return false
+	}
+	if c.MaxAmountCents != nil && tx.AmountCents > *c.MaxAmountCents {
+		return false
+	}
+	if c.Direction != "" && tx.Direction != c.Direction {
+		return false
+	}
+	if c.Account != "" && !strings.EqualFold(strings.ReplaceAll(tx.Account, " ", ""), strings.ReplaceAll(c.Account, " ", "")) {
+		return false
+	}
+	if len(c.Weekdays) > 0 && !slices.Contains(c.Weekdays, tx.Date.Weekday()) {
+		return false
+	}
+	return true
+}
+
+// compile compiles a case insensitive regular expression, an empty pattern
+// compiles to nil.
+func compile(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRegex, err)
+	}
+	return re, nil
+}
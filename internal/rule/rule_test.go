@@ -0,0 +1,86 @@
+package rule
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+func TestNewRule(t *testing.T) {
+	tag := Actions{SetTag: ptr("housing")}
+	tests := []struct {
+		name    string
+		rule    string
+		c       Conditions
+		a       Actions
+		wantErr error
+	}{
+		{"valid", "rent", Conditions{DescriptionRegex: "huur"}, tag, nil},
+		{"no name", " ", Conditions{DescriptionRegex: "huur"}, tag, ErrInvalidName},
+		{"no conditions", "rent", Conditions{}, tag, ErrNoConditions},
+		{"no actions", "rent", Conditions{DescriptionRegex: "huur"}, Actions{}, ErrNoActions},
+		{"invalid regex", "rent", Conditions{DescriptionRegex: "huur("}, tag, ErrInvalidRegex},
+		{"invalid note regex", "rent", Conditions{NoteRegex: "[a-"}, tag, ErrInvalidRegex},
+		{"invalid direction", "rent", Conditions{Direction: "sideways"}, tag, ErrInvalidDirection},
+		{"negative amount", "rent", Conditions{MinAmountCents: ptr[int64](-1)}, tag, ErrInvalidAmount},
+		{"empty amount range", "rent", Conditions{MinAmountCents: ptr[int64](200), MaxAmountCents: ptr[int64](100)}, tag, ErrInvalidAmount},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRule(tt.rule, 0, true, tt.c, tt.a); !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewRule() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRuleMatches(t *testing.T) {
+	landlord := uuid.New()
+	tx := &transaction.Transaction{
+		Description:    "HUUR JANUARI J JANSEN",
+		Note:           "paid late",
+		CounterpartyID: &landlord,
+		AmountCents:    150000,
+		Direction:      transaction.CashOut,
+		Account:        "NL91ABNA0417164300",
+		// a monday
+		Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	other := uuid.New()
+	tests := []struct {
+		name string
+		c    Conditions
+		want bool
+	}{
+		{"description ignores case", Conditions{DescriptionRegex: "^huur"}, true},
+		{"description", Conditions{DescriptionRegex: "^jansen"}, false},
+		{"note", Conditions{NoteRegex: "late"}, true},
+		{"counterparty", Conditions{CounterpartyID: &landlord}, true},
+		{"other counterparty", Conditions{CounterpartyID: &other}, false},
+		{"amount within range", Conditions{MinAmountCents: ptr[int64](100000), MaxAmountCents: ptr[int64](150000)}, true},
+		{"amount below minimum", Conditions{MinAmountCents: ptr[int64](150001)}, false},
+		{"amount above maximum", Conditions{MaxAmountCents: ptr[int64](149999)}, false},
+		{"direction", Conditions{Direction: transaction.CashOut}, true},
+		{"other direction", Conditions{Direction: transaction.CashIn}, false},
+		{"account with spaces", Conditions{Account: "nl91 abna 0417 1643 00"}, true},
+		{"other account", Conditions{Account: "NL20INGB0001234567"}, false},
+		{"weekday", Conditions{Weekdays: []time.Weekday{time.Monday, time.Friday}}, true},
+		{"other weekday", Conditions{Weekdays: []time.Weekday{time.Saturday}}, false},
+		{"all conditions", Conditions{DescriptionRegex: "huur", Direction: transaction.CashOut, Weekdays: []time.Weekday{time.Monday}}, true},
+		{"one condition fails", Conditions{DescriptionRegex: "huur", Direction: transaction.CashIn}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewRule("test", 0, true, tt.c, Actions{SetTag: ptr("housing")})
+			if err != nil {
+				t.Fatalf("NewRule() error = %v", err)
+			}
+			if got := r.Matches(tx); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -19,11 +19,41 @@ const (
 )
 
 const (
-	TableVendors      = "vendors"
-	TableTransactions = "transactions"
-	TableImports      = "imports"
+	TableVendors           = "vendors"
+	TableTransactions      = "transactions"
+	TableImports           = "imports"
+	TableRefundLinks       = "refund_links"
+	TableCounterparties    = "counterparties"
+	TableRules             = "rules"
+	TableRuleSuggestions   = "rule_suggestions"
+	TableCategories        = "categories"
+	TableLabels            = "labels"
+	TableTransactionLabels = "transaction_labels"
+	TableBulkBatches       = "bulk_batches"
+	TableBulkBatchItems    = "bulk_batch_items"
+	TableTaggingClaims     = "tagging_claims"
+	TableBudgets           = "budgets"
+	TableBudgetAlerts      = "budget_alerts"
+	TableRecurringSeries   = "recurring_series"
+	TableBalanceAnchors    = "balance_anchors"
+	TableAccounts          = "accounts"
+	TableNetWorthItems     = "networth_items"
+	TableNetWorthValues    = "networth_valuations"
+	TableNetWorthSnapshots = "networth_snapshots"
+	TableMarketHistory     = "market_history"
+	TableSecurities        = "securities"
+	TableInvestmentTrades  = "investment_trades"
+	TableFXRates           = "fx_rates"
+
+	// ViewReportTransactions is the view reports read from, confirmed refunds
+	// carry the tag of their original transaction.
+	ViewReportTransactions = "report_transactions"
 )
 
+// txContextKey is the context key under which an active database transaction
+// is stored, see WithTx and GetExecutor.
+const txContextKey = "tx"
+
 type DB struct {
 	*sqlx.DB
 }
@@ -40,9 +70,31 @@ func NewDB(connStr string, connType ConnectionType) *DB {
 }
 
 func (db *DB) GetExecutor(ctx context.Context) sqlx.ExtContext {
-	tx, ok := ctx.Value("tx").(*sqlx.Tx)
+	tx, ok := ctx.Value(txContextKey).(*sqlx.Tx)
 	if ok {
 		return tx
 	}
 	return db
 }
+
+// WithTx runs fn within a database transaction. Stores pick up the transaction
+// from the context passed to fn through GetExecutor. The transaction is rolled
+// back when fn returns an error and committed otherwise. Nested calls reuse
+// the outer transaction.
+func (db *DB) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txContextKey).(*sqlx.Tx); ok {
+		return fn(ctx)
+	}
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("db: failed to begin transaction: %w", err)
+	}
+	if err := fn(context.WithValue(ctx, txContextKey, tx)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("db: failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package storage_test
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
+	"github.com/lennardclaproth/my-finances-tracker/migrations"
+)
+
+// testDB returns a database migrated in a schema of its own within the
+// PostgreSQL database of TEST_DATABASE_URL, the schema is dropped when the
+// test ends. The test is skipped when TEST_DATABASE_URL is not set.
+func testDB(t *testing.T) *storage.DB {
+	t.Helper()
+	connStr := os.Getenv("TEST_DATABASE_URL")
+	if connStr == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+	admin, err := sqlx.Connect("postgres", connStr)
+	if err != nil {
+		t.Fatalf("failed to connect to the test database: %v", err)
+	}
+	schema := "test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	if _, err := admin.ExecContext(ctx, "CREATE SCHEMA "+schema); err != nil {
+		admin.Close()
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		admin.ExecContext(ctx, "DROP SCHEMA "+schema+" CASCADE")
+		admin.Close()
+	})
+
+	u, err := url.Parse(connStr)
+	if err != nil {
+		t.Fatalf("invalid TEST_DATABASE_URL: %v", err)
+	}
+	q := u.Query()
+	q.Set("search_path", schema+",public")
+	u.RawQuery = q.Encode()
+	db := storage.NewDB(u.String(), storage.Postgres)
+	t.Cleanup(func() { db.Close() })
+
+	log := logging.NewSlogLogger(slog.LevelError)
+	if err := migrations.NewMigrator(db, storage.Postgres, log).RunMigrations(ctx, db, storage.Postgres); err != nil {
+		t.Fatalf("failed to migrate the test database: %v", err)
+	}
+	return db
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lennardclaproth/my-finances-tracker/internal/balance"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+type SQLXBalanceStore struct {
+	db *DB
+}
+
+func NewSQLXBalanceStore(db *DB) *SQLXBalanceStore {
+	return &SQLXBalanceStore{db: db}
+}
+
+// SaveAnchor creates the anchor or replaces the balance of the anchor on the
+// same day.
+func (s *SQLXBalanceStore) SaveAnchor(ctx context.Context, a *balance.Anchor) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (account, date, balance_cents, created_at, updated_at)
+		VALUES (:account, :date, :balance_cents, :created_at, :updated_at)
+		ON CONFLICT (account, date) DO UPDATE SET balance_cents = EXCLUDED.balance_cents, updated_at = EXCLUDED.updated_at
+	`, TableBalanceAnchors)
+	if _, err := sqlx.NamedExecContext(ctx, s.db.GetExecutor(ctx), query, a); err != nil {
+		return fmt.Errorf("sqlx_balance_store: failed to save anchor: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLXBalanceStore) DeleteAnchor(ctx context.Context, account string, date time.Time) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE account = $1 AND date = $2`, TableBalanceAnchors)
+	res, err := s.db.GetExecutor(ctx).ExecContext(ctx, query, account, date.Format(time.DateOnly))
+	if err != nil {
+		return fmt.Errorf("sqlx_balance_store: failed to delete anchor: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return balance.ErrAnchorNotFound
+	}
+	return nil
+}
+
+// Anchors returns the anchors of the account, oldest first.
+func (s *SQLXBalanceStore) Anchors(ctx context.Context, account string) ([]*balance.Anchor, error) {
+	anchors := []*balance.Anchor{}
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE account = $1 ORDER BY date ASC`, TableBalanceAnchors)
+	if err := sqlx.SelectContext(ctx, s.db.GetExecutor(ctx), &anchors, query, account); err != nil {
+		return nil, fmt.Errorf("sqlx_balance_store: failed to list anchors: %w", err)
+	}
+	return anchors, nil
+}
+
+// Accounts returns the accounts that have transactions, anchors or
+// settings. Accounts without settings are checking accounts in the currency
+// of their last transaction.
+func (s *SQLXBalanceStore) Accounts(ctx context.Context) ([]*balance.Account, error) {
+	accounts := []*balance.Account{}
+	query := fmt.Sprintf(`
+		SELECT ids.id, COALESCE(a.name, '') AS name, COALESCE(a.type, $1) AS type,
+			COALESCE(a.currency, (
+				SELECT t.currency FROM %[1]s t WHERE t.account = ids.id ORDER BY t.date DESC LIMIT 1
+			), $2) AS currency,
+			COALESCE(a.created_at, NOW()) AS created_at, COALESCE(a.updated_at, NOW()) AS updated_at
+		FROM (
+			SELECT account AS id FROM %[1]s
+			UNION SELECT account FROM %[2]s
+			UNION SELECT id FROM %[3]s
+		) ids
+		LEFT JOIN %[3]s a ON a.id = ids.id
+		ORDER BY ids.id ASC
+	`, TableTransactions, TableBalanceAnchors, TableAccounts)
+	if err := sqlx.SelectContext(ctx, s.db.GetExecutor(ctx), &accounts, query, balance.AccountChecking, transaction.DefaultCurrency); err != nil {
+		return nil, fmt.Errorf("sqlx_balance_store: failed to list accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// FetchAccount returns the settings of the account, the defaults in the
+// currency of its last transaction when none were saved.
+func (s *SQLXBalanceStore) FetchAccount(ctx context.Context, id string) (*balance.Account, error) {
+	var a balance.Account
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE id = $1`, TableAccounts)
+	if err := sqlx.GetContext(ctx, s.db.GetExecutor(ctx), &a, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return s.defaultAccount(ctx, id)
+		}
+		return nil, fmt.Errorf("sqlx_balance_store: failed to fetch account: %w", err)
+	}
+	return &a, nil
+}
+
+func (s *SQLXBalanceStore) defaultAccount(ctx context.Context, id string) (*balance.Account, error) {
+	a, err := balance.NewAccount(id)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(`SELECT currency FROM %s WHERE account = $1 ORDER BY date DESC LIMIT 1`, TableTransactions)
+	if err := sqlx.GetContext(ctx, s.db.GetExecutor(ctx), &a.Currency, query, id); err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("sqlx_balance_store: failed to fetch account currency: %w", err)
+	}
+	return a, nil
+}
+
+func (s *SQLXBalanceStore) SaveAccount(ctx context.Context, a *balance.Account) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (id, name, type, currency, created_at, updated_at)
+		VALUES (:id, :name, :type, :currency, :created_at, :updated_at)
+		ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, type = EXCLUDED.type, currency = EXCLUDED.currency,
+			updated_at = EXCLUDED.updated_at
+	`, TableAccounts)
+	if _, err := sqlx.NamedExecContext(ctx, s.db.GetExecutor(ctx), query, a); err != nil {
+		return fmt.Errorf("sqlx_balance_store: failed to save account: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lennardclaproth/my-finances-tracker/internal/budget"
+	"github.com/lib/pq"
+)
+
+const budgetColumns = `id, category_id, amount_cents, period, start_date, rollover, created_at, updated_at`
+
+type SQLXBudgetStore struct {
+	db *DB
+}
+
+func NewSQLXBudgetStore(db *DB) *SQLXBudgetStore {
+	return &SQLXBudgetStore{db: db}
+}
+
+func (s *SQLXBudgetStore) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return s.db.WithTx(ctx, fn)
+}
+
+func (s *SQLXBudgetStore) Create(ctx context.Context, b *budget.Budget) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (%s)
+		VALUES (:id, :category_id, :amount_cents, :period, :start_date, :rollover, :created_at, :updated_at)
+	`, TableBudgets, budgetColumns)
+	if _, err := sqlx.NamedExecContext(ctx, s.db.GetExecutor(ctx), query, b); err != nil {
+		return budgetWriteError(err, "failed to save budget")
+	}
+	return nil
+}
+
+func (s *SQLXBudgetStore) Update(ctx context.Context, b *budget.Budget) error {
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET category_id = :category_id, amount_cents = :amount_cents, period = :period,
+			start_date = :start_date, rollover = :rollover, updated_at = :updated_at
+		WHERE id = :id
+	`, TableBudgets)
+	res, err := sqlx.NamedExecContext(ctx, s.db.GetExecutor(ctx), query, b)
+	if err != nil {
+		return budgetWriteError(err, "failed to update budget")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return budget.ErrBudgetNotFound
+	}
+	return nil
+}
+
+func budgetWriteError(err error, msg string) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return budget.ErrBudgetExists
+	}
+	return fmt.Errorf("sqlx_budget_store: %s: %w", msg, err)
+}
+
+func (s *SQLXBudgetStore) Delete(ctx context.Context, id uuid.UUID) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, TableBudgets)
+	res, err := s.db.GetExecutor(ctx).ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("sqlx_budget_store: failed to delete budget: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return budget.ErrBudgetNotFound
+	}
+	return nil
+}
+
+func (s *SQLXBudgetStore) FetchByID(ctx context.Context, id uuid.UUID) (*budget.Budget, error) {
+	var b budget.Budget
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE id = $1`, budgetColumns, TableBudgets)
+	if err := sqlx.GetContext(ctx, s.db.GetExecutor(ctx), &b, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, budget.ErrBudgetNotFound
+		}
+		return nil, fmt.Errorf("sqlx_budget_store: failed to fetch budget: %w", err)
+	}
+	return &b, nil
+}
+
+func (s *SQLXBudgetStore) List(ctx context.Context) ([]*budget.Budget, error) {
+	budgets := []*budget.Budget{}
+	query := fmt.Sprintf(`SELECT %s FROM %s ORDER BY created_at ASC`, budgetColumns, TableBudgets)
+	if err := sqlx.SelectContext(ctx, s.db.GetExecutor(ctx), &budgets, query); err != nil {
+		return nil, fmt.Errorf("sqlx_budget_store: failed to list budgets: %w", err)
+	}
+	return budgets, nil
+}
+
+func (s *SQLXBudgetStore) RecordAlert(ctx context.Context, budgetID uuid.UUID, periodStart time.Time, threshold int, spentCents int64) (bool, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (budget_id, period_start, threshold, spent_cents)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+	`, TableBudgetAlerts)
+	res, err := s.db.GetExecutor(ctx).ExecContext(ctx, query, budgetID, periodStart.Format(time.DateOnly), threshold, spentCents)
+	if err != nil {
+		return false, fmt.Errorf("sqlx_budget_store: failed to record budget alert: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+func (s *SQLXBudgetStore) ForgetAlert(ctx context.Context, budgetID uuid.UUID, periodStart time.Time, threshold int) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE budget_id = $1 AND period_start = $2 AND threshold = $3`, TableBudgetAlerts)
+	if _, err := s.db.GetExecutor(ctx).ExecContext(ctx, query, budgetID, periodStart.Format(time.DateOnly), threshold); err != nil {
+		return fmt.Errorf("sqlx_budget_store: failed to forget budget alert: %w", err)
+	}
+	return nil
+}
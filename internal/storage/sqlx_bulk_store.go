@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lennardclaproth/my-finances-tracker/internal/bulk"
+)
+
+// bulkBatchRow is the database representation of a batch, the operation is
+// stored as JSONB.
+type bulkBatchRow struct {
+	ID        uuid.UUID  `db:"id"`
+	Operation []byte     `db:"operation"`
+	Affected  int        `db:"affected"`
+	CreatedAt time.Time  `db:"created_at"`
+	UndoneAt  *time.Time `db:"undone_at"`
+}
+
+type bulkItemRow struct {
+	TransactionID uuid.UUID `db:"transaction_id"`
+	Before        []byte    `db:"before"`
+}
+
+type SQLXBulkStore struct {
+	db *DB
+}
+
+func NewSQLXBulkStore(db *DB) *SQLXBulkStore {
+	return &SQLXBulkStore{db: db}
+}
+
+func (s *SQLXBulkStore) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return s.db.WithTx(ctx, fn)
+}
+
+func (s *SQLXBulkStore) Create(ctx context.Context, b *bulk.Batch) error {
+	op, err := json.Marshal(b.Operation)
+	if err != nil {
+		return fmt.Errorf("sqlx_bulk_store: failed to encode operation: %w", err)
+	}
+	executor := s.db.GetExecutor(ctx)
+	query := fmt.Sprintf(`INSERT INTO %s (id, operation, affected, created_at) VALUES ($1, $2, $3, $4)`, TableBulkBatches)
+	if _, err := executor.ExecContext(ctx, query, b.ID, op, b.Affected, b.CreatedAt); err != nil {
+		return fmt.Errorf("sqlx_bulk_store: failed to save batch: %w", err)
+	}
+	itemQuery := fmt.Sprintf(`INSERT INTO %s (batch_id, transaction_id, before) VALUES ($1, $2, $3)`, TableBulkBatchItems)
+	for _, item := range b.Items {
+		before, err := json.Marshal(item.Before)
+		if err != nil {
+			return fmt.Errorf("sqlx_bulk_store: failed to encode state of transaction %s: %w", item.TransactionID, err)
+		}
+		if _, err := executor.ExecContext(ctx, itemQuery, b.ID, item.TransactionID, before); err != nil {
+			return fmt.Errorf("sqlx_bulk_store: failed to save batch item: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *SQLXBulkStore) FetchByID(ctx context.Context, id uuid.UUID) (*bulk.Batch, error) {
+	executor := s.db.GetExecutor(ctx)
+	var row bulkBatchRow
+	query := fmt.Sprintf(`SELECT id, operation, affected, created_at, undone_at FROM %s WHERE id = $1`, TableBulkBatches)
+	if err := sqlx.GetContext(ctx, executor, &row, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, bulk.ErrBatchNotFound
+		}
+		return nil, fmt.Errorf("sqlx_bulk_store: failed to fetch batch: %w", err)
+	}
+	b := &bulk.Batch{ID: row.ID, Affected: row.Affected, CreatedAt: row.CreatedAt, UndoneAt: row.UndoneAt}
+	if err := json.Unmarshal(row.Operation, &b.Operation); err != nil {
+		return nil, fmt.Errorf("sqlx_bulk_store: failed to decode operation of batch %s: %w", row.ID, err)
+	}
+
+	var items []bulkItemRow
+	itemQuery := fmt.Sprintf(`SELECT transaction_id, before FROM %s WHERE batch_id = $1`, TableBulkBatchItems)
+	if err := sqlx.SelectContext(ctx, executor, &items, itemQuery, id); err != nil {
+		return nil, fmt.Errorf("sqlx_bulk_store: failed to fetch batch items: %w", err)
+	}
+	b.Items = make([]bulk.Item, 0, len(items))
+	for _, r := range items {
+		item := bulk.Item{TransactionID: r.TransactionID}
+		if err := json.Unmarshal(r.Before, &item.Before); err != nil {
+			return nil, fmt.Errorf("sqlx_bulk_store: failed to decode state of transaction %s: %w", r.TransactionID, err)
+		}
+		b.Items = append(b.Items, item)
+	}
+	return b, nil
+}
+
+func (s *SQLXBulkStore) MarkUndone(ctx context.Context, b *bulk.Batch) error {
+	query := fmt.Sprintf(`UPDATE %s SET undone_at = $1 WHERE id = $2`, TableBulkBatches)
+	if _, err := s.db.GetExecutor(ctx).ExecContext(ctx, query, b.UndoneAt, b.ID); err != nil {
+		return fmt.Errorf("sqlx_bulk_store: failed to mark batch as undone: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lennardclaproth/my-finances-tracker/internal/category"
+)
+
+const categoryColumns = `id, slug, name, parent_id, type, colour, icon, position, system, created_at, updated_at`
+
+type SQLXCategoryStore struct {
+	db *DB
+}
+
+func NewSQLXCategoryStore(db *DB) *SQLXCategoryStore {
+	return &SQLXCategoryStore{db: db}
+}
+
+func (s *SQLXCategoryStore) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return s.db.WithTx(ctx, fn)
+}
+
+func (s *SQLXCategoryStore) Create(ctx context.Context, c *category.Category) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (%s)
+		VALUES (:id, :slug, :name, :parent_id, :type, :colour, :icon, :position, :system, :created_at, :updated_at)
+	`, TableCategories, categoryColumns)
+	if _, err := sqlx.NamedExecContext(ctx, s.db.GetExecutor(ctx), query, c); err != nil {
+		return fmt.Errorf("sqlx_category_store: failed to save category: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLXCategoryStore) Update(ctx context.Context, c *category.Category) error {
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET slug = :slug, name = :name, parent_id = :parent_id, type = :type, colour = :colour,
+			icon = :icon, position = :position, updated_at = :updated_at
+		WHERE id = :id
+	`, TableCategories)
+	res, err := sqlx.NamedExecContext(ctx, s.db.GetExecutor(ctx), query, c)
+	if err != nil {
+		return fmt.Errorf("sqlx_category_store: failed to update category: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return category.ErrCategoryNotFound
+	}
+	return nil
+}
+
+func (s *SQLXCategoryStore) Delete(ctx context.Context, id uuid.UUID) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, TableCategories)
+	if _, err := s.db.GetExecutor(ctx).ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("sqlx_category_store: failed to delete category: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLXCategoryStore) FetchByID(ctx context.Context, id uuid.UUID) (*category.Category, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE id = $1`, categoryColumns, TableCategories)
+	return s.fetchOne(ctx, query, id)
+}
+
+func (s *SQLXCategoryStore) FetchBySlug(ctx context.Context, slug string) (*category.Category, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE slug = $1`, categoryColumns, TableCategories)
+	return s.fetchOne(ctx, query, slug)
+}
+
+// List returns all categories, see category.NewTree for the tree order.
+func (s *SQLXCategoryStore) List(ctx context.Context) ([]*category.Category, error) {
+	var categories []*category.Category
+	query := fmt.Sprintf(`SELECT %s FROM %s ORDER BY position ASC, name ASC`, categoryColumns, TableCategories)
+	if err := sqlx.SelectContext(ctx, s.db.GetExecutor(ctx), &categories, query); err != nil {
+		return nil, fmt.Errorf("sqlx_category_store: failed to list categories: %w", err)
+	}
+	return categories, nil
+}
+
+// Retag moves all references of the category slug from to the slug to.
+// Suggestions that would collide with an existing suggestion for the target
+// are dropped.
+func (s *SQLXCategoryStore) Retag(ctx context.Context, from, to string) error {
+	queries := []string{
+		fmt.Sprintf(`UPDATE %s SET tag = $2, updated_at = NOW() WHERE tag = $1`, TableTransactions),
+		fmt.Sprintf(`
+			UPDATE %s SET actions = jsonb_set(actions, '{setTag}', to_jsonb($2::text)), updated_at = NOW()
+			WHERE actions->>'setTag' = $1
+		`, TableRules),
+		fmt.Sprintf(`
+			DELETE FROM %[1]s a
+			WHERE a.tag = $1 AND EXISTS (
+				SELECT 1 FROM %[1]s b WHERE b.tag = $2 AND b.key_type = a.key_type AND b.key = a.key
+			)
+		`, TableRuleSuggestions),
+		fmt.Sprintf(`UPDATE %s SET tag = $2, updated_at = NOW() WHERE tag = $1`, TableRuleSuggestions),
+		fmt.Sprintf(`UPDATE %s SET default_tag = $2, updated_at = NOW() WHERE default_tag = $1`, TableCounterparties),
+	}
+	executor := s.db.GetExecutor(ctx)
+	for _, query := range queries {
+		if _, err := executor.ExecContext(ctx, query, from, to); err != nil {
+			return fmt.Errorf("sqlx_category_store: failed to retag %q to %q: %w", from, to, err)
+		}
+	}
+	return nil
+}
+
+// Totals sums the cash flows per category over the report view converted to
+// the currency, ignored transactions and transactions in a currency without
+// rates are left out. Zero from or to dates leave the range open. Untagged
+// transactions count as uncategorised.
+func (s *SQLXCategoryStore) Totals(ctx context.Context, from, to time.Time, currency string) (map[string]category.Totals, error) {
+	var rows []struct {
+		Tag       string `db:"tag"`
+		Direction string `db:"direction"`
+		Cents     int64  `db:"cents"`
+	}
+	query := fmt.Sprintf(`
+		SELECT COALESCE(NULLIF(tag, ''), $3) AS tag, direction, COALESCE(SUM(fx_convert(amount_cents, currency, $4, date)), 0) AS cents
+		FROM %s
+		WHERE NOT ignored
+		  AND ($1::date IS NULL OR date >= $1)
+		  AND ($2::date IS NULL OR date <= $2)
+		GROUP BY 1, 2
+	`, ViewReportTransactions)
+	if err := sqlx.SelectContext(ctx, s.db.GetExecutor(ctx), &rows, query, nullDate(from), nullDate(to), category.Uncategorised, currency); err != nil {
+		return nil, fmt.Errorf("sqlx_category_store: failed to sum category totals: %w", err)
+	}
+	totals := map[string]category.Totals{}
+	for _, r := range rows {
+		t := totals[r.Tag]
+		if r.Direction == "in" {
+			t.InCents += r.Cents
+		} else {
+			t.OutCents += r.Cents
+		}
+		totals[r.Tag] = t
+	}
+	return totals, nil
+}
+
+func (s *SQLXCategoryStore) fetchOne(ctx context.Context, query string, args ...any) (*category.Category, error) {
+	var c category.Category
+	if err := sqlx.GetContext(ctx, s.db.GetExecutor(ctx), &c, query, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, category.ErrCategoryNotFound
+		}
+		return nil, fmt.Errorf("sqlx_category_store: failed to fetch category: %w", err)
+	}
+	return &c, nil
+}
+
+// nullDate maps the zero time to NULL.
+func nullDate(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lennardclaproth/my-finances-tracker/internal/counterparty"
+	"github.com/lib/pq"
+)
+
+// counterpartyRow is the database representation of a counterparty, the
+// alias and IBAN lists are stored as postgres text arrays.
+type counterpartyRow struct {
+	ID         uuid.UUID      `db:"id"`
+	Name       string         `db:"name"`
+	Aliases    pq.StringArray `db:"aliases"`
+	IBANs      pq.StringArray `db:"ibans"`
+	DefaultTag string         `db:"default_tag"`
+	LogoURL    string         `db:"logo_url"`
+	CreatedAt  time.Time      `db:"created_at"`
+	UpdatedAt  time.Time      `db:"updated_at"`
+}
+
+func toCounterpartyRow(cp *counterparty.Counterparty) counterpartyRow {
+	return counterpartyRow{
+		ID:         cp.ID,
+		Name:       cp.Name,
+		Aliases:    pq.StringArray(cp.Aliases),
+		IBANs:      pq.StringArray(cp.IBANs),
+		DefaultTag: cp.DefaultTag,
+		LogoURL:    cp.LogoURL,
+		CreatedAt:  cp.CreatedAt,
+		UpdatedAt:  cp.UpdatedAt,
+	}
+}
+
+func (r counterpartyRow) toCounterparty() *counterparty.Counterparty {
+	cp := &counterparty.Counterparty{
+		ID:         r.ID,
+		Name:       r.Name,
+		Aliases:    []string(r.Aliases),
+		IBANs:      []string(r.IBANs),
+		DefaultTag: r.DefaultTag,
+		LogoURL:    r.LogoURL,
+		CreatedAt:  r.CreatedAt,
+		UpdatedAt:  r.UpdatedAt,
+	}
+	if cp.Aliases == nil {
+		cp.Aliases = []string{}
+	}
+	if cp.IBANs == nil {
+		cp.IBANs = []string{}
+	}
+	return cp
+}
+
+const counterpartyColumns = `id, name, aliases, ibans, default_tag, logo_url, created_at, updated_at`
+
+type SQLXCounterpartyStore struct {
+	db *DB
+}
+
+func NewSQLXCounterpartyStore(db *DB) *SQLXCounterpartyStore {
+	return &SQLXCounterpartyStore{db: db}
+}
+
+func (s *SQLXCounterpartyStore) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return s.db.WithTx(ctx, fn)
+}
+
+func (s *SQLXCounterpartyStore) Create(ctx context.Context, cp *counterparty.Counterparty) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (%s)
+		VALUES (:id, :name, :aliases, :ibans, :default_tag, :logo_url, :created_at, :updated_at)
+	`, TableCounterparties, counterpartyColumns)
+	if _, err := sqlx.NamedExecContext(ctx, s.db.GetExecutor(ctx), query, toCounterpartyRow(cp)); err != nil {
+		return fmt.Errorf("sqlx_counterparty_store: failed to save counterparty: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLXCounterpartyStore) Update(ctx context.Context, cp *counterparty.Counterparty) error {
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET name = :name, aliases = :aliases, ibans = :ibans, default_tag = :default_tag, logo_url = :logo_url, updated_at = :updated_at
+		WHERE id = :id
+	`, TableCounterparties)
+	res, err := sqlx.NamedExecContext(ctx, s.db.GetExecutor(ctx), query, toCounterpartyRow(cp))
+	if err != nil {
+		return fmt.Errorf("sqlx_counterparty_store: failed to update counterparty: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return counterparty.ErrCounterpartyNotFound
+	}
+	return nil
+}
+
+func (s *SQLXCounterpartyStore) Delete(ctx context.Context, id uuid.UUID) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, TableCounterparties)
+	if _, err := s.db.GetExecutor(ctx).ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("sqlx_counterparty_store: failed to delete counterparty: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLXCounterpartyStore) FetchByID(ctx context.Context, id uuid.UUID) (*counterparty.Counterparty, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE id = $1`, counterpartyColumns, TableCounterparties)
+	return s.fetchOne(ctx, query, id)
+}
+
+func (s *SQLXCounterpartyStore) FetchByIBAN(ctx context.Context, iban string) (*counterparty.Counterparty, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE $1 = ANY(ibans) ORDER BY created_at ASC LIMIT 1`, counterpartyColumns, TableCounterparties)
+	return s.fetchOne(ctx, query, iban)
+}
+
+func (s *SQLXCounterpartyStore) FetchByAlias(ctx context.Context, alias string) (*counterparty.Counterparty, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE $1 = ANY(aliases) ORDER BY created_at ASC LIMIT 1`, counterpartyColumns, TableCounterparties)
+	return s.fetchOne(ctx, query, alias)
+}
+
+// List returns counterparties ordered by name, optionally filtered by a case
+// insensitive search on the name.
+func (s *SQLXCounterpartyStore) List(ctx context.Context, search string) ([]*counterparty.Counterparty, error) {
+	var rows []counterpartyRow
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE $1 = '' OR name ILIKE '%%' || $1 || '%%' ORDER BY name ASC`, counterpartyColumns, TableCounterparties)
+	if err := sqlx.SelectContext(ctx, s.db.GetExecutor(ctx), &rows, query, search); err != nil {
+		return nil, fmt.Errorf("sqlx_counterparty_store: failed to list counterparties: %w", err)
+	}
+	cps := make([]*counterparty.Counterparty, 0, len(rows))
+	for _, r := range rows {
+		cps = append(cps, r.toCounterparty())
+	}
+	return cps, nil
+}
+
+func (s *SQLXCounterpartyStore) fetchOne(ctx context.Context, query string, args ...any) (*counterparty.Counterparty, error) {
+	var row counterpartyRow
+	if err := sqlx.GetContext(ctx, s.db.GetExecutor(ctx), &row, query, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, counterparty.ErrCounterpartyNotFound
+		}
+		return nil, fmt.Errorf("sqlx_counterparty_store: failed to fetch counterparty: %w", err)
+	}
+	return row.toCounterparty(), nil
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lennardclaproth/my-finances-tracker/internal/fx"
+)
+
+type SQLXFXStore struct {
+	db *DB
+}
+
+func NewSQLXFXStore(db *DB) *SQLXFXStore {
+	return &SQLXFXStore{db: db}
+}
+
+// Upsert stores the rates, a rate that is already stored for the currency
+// and day is overwritten.
+func (s *SQLXFXStore) Upsert(ctx context.Context, rates []*fx.Rate) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (currency, date, rate_micros)
+		VALUES (:currency, :date, :rate_micros)
+		ON CONFLICT (currency, date) DO UPDATE SET rate_micros = EXCLUDED.rate_micros
+	`, TableFXRates)
+	return s.db.WithTx(ctx, func(ctx context.Context) error {
+		for start := 0; start < len(rates); start += upsertBatchSize {
+			batch := rates[start:min(start+upsertBatchSize, len(rates))]
+			if _, err := sqlx.NamedExecContext(ctx, s.db.GetExecutor(ctx), query, dedupeRates(batch)); err != nil {
+				return fmt.Errorf("sqlx_fx_store: failed to upsert rates: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// dedupeRates keeps the last rate of every currency and day, Postgres
+// rejects an upsert that touches the same row twice.
+func dedupeRates(rates []*fx.Rate) []*fx.Rate {
+	type key struct {
+		currency string
+		date     time.Time
+	}
+	index := map[key]int{}
+	res := make([]*fx.Rate, 0, len(rates))
+	for _, r := range rates {
+		k := key{r.Currency, r.Date}
+		if i, ok := index[k]; ok {
+			res[i] = r
+			continue
+		}
+		index[k] = len(res)
+		res = append(res, r)
+	}
+	return res
+}
+
+// RateOn returns the rate of the currency on the last day on or before the
+// date that has one, the first later rate when there is none before it and
+// fx.ErrNoRate when the currency has no rates at all.
+func (s *SQLXFXStore) RateOn(ctx context.Context, currency string, date time.Time) (*fx.Rate, error) {
+	var r fx.Rate
+	// two lookups along the primary key, the later rate is only used when
+	// there is none before the date
+	query := fmt.Sprintf(`
+		SELECT currency, date, rate_micros FROM (
+			(SELECT currency, date, rate_micros FROM %[1]s
+			 WHERE currency = $1 AND date <= $2::date
+			 ORDER BY date DESC LIMIT 1)
+			UNION ALL
+			(SELECT currency, date, rate_micros FROM %[1]s
+			 WHERE currency = $1 AND date > $2::date
+			 ORDER BY date ASC LIMIT 1)
+		) r
+		ORDER BY date ASC
+		LIMIT 1
+	`, TableFXRates)
+	if err := sqlx.GetContext(ctx, s.db.GetExecutor(ctx), &r, query, currency, date.Format(time.DateOnly)); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %s", fx.ErrNoRate, currency)
+		}
+		return nil, fmt.Errorf("sqlx_fx_store: failed to fetch rate: %w", err)
+	}
+	return &r, nil
+}
+
+// List returns the rates from from through to, of all currencies when
+// currency is empty, oldest first.
+func (s *SQLXFXStore) List(ctx context.Context, currency string, from, to time.Time) ([]*fx.Rate, error) {
+	rates := []*fx.Rate{}
+	query := fmt.Sprintf(`
+		SELECT currency, date, rate_micros FROM %s
+		WHERE ($1 = '' OR currency = $1) AND date >= $2 AND date <= $3
+		ORDER BY date ASC, currency ASC
+	`, TableFXRates)
+	if err := sqlx.SelectContext(ctx, s.db.GetExecutor(ctx), &rates, query, currency, from.Format(time.DateOnly), to.Format(time.DateOnly)); err != nil {
+		return nil, fmt.Errorf("sqlx_fx_store: failed to list rates: %w", err)
+	}
+	return rates, nil
+}
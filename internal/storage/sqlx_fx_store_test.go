@@ -0,0 +1,103 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/fx"
+	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
+)
+
+func TestSQLXFXStoreRateOn(t *testing.T) {
+	db := testDB(t)
+	store := storage.NewSQLXFXStore(db)
+	ctx := context.Background()
+	day := func(s string) time.Time {
+		d, _ := time.Parse(time.DateOnly, s)
+		return d
+	}
+	var rates []*fx.Rate
+	for _, r := range []struct {
+		date   string
+		micros int64
+	}{
+		{"2024-01-02", 1090000},
+		{"2024-01-05", 1095000},
+		{"2024-01-08", 1100000},
+	} {
+		rate, err := fx.NewRate(day(r.date), "USD", r.micros)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rates = append(rates, rate)
+	}
+	if err := store.Upsert(ctx, rates); err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		date string
+		want int64
+	}{
+		{"before the first rate", "2023-12-29", 1090000},
+		{"on a rate", "2024-01-05", 1095000},
+		{"weekend after a rate", "2024-01-07", 1095000},
+		{"after the last rate", "2024-02-01", 1100000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := store.RateOn(ctx, "USD", day(tt.date))
+			if err != nil {
+				t.Fatalf("RateOn() error = %v", err)
+			}
+			if rate.RateMicros != tt.want {
+				t.Errorf("RateOn() = %d, want %d", rate.RateMicros, tt.want)
+			}
+			// the SQL function must agree with the store
+			var micros int64
+			if err := db.GetContext(ctx, &micros, `SELECT fx_rate('USD', $1::date)::BIGINT`, tt.date); err != nil {
+				t.Fatalf("fx_rate() error = %v", err)
+			}
+			if micros != tt.want {
+				t.Errorf("fx_rate() = %d, want %d", micros, tt.want)
+			}
+		})
+	}
+
+	if _, err := store.RateOn(ctx, "CHF", day("2024-01-05")); !errors.Is(err, fx.ErrNoRate) {
+		t.Errorf("RateOn() without rates error = %v, want fx.ErrNoRate", err)
+	}
+}
+
+func TestFXConvertMinorUnits(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	if _, err := db.ExecContext(ctx, `INSERT INTO fx_rates (currency, date, rate_micros) VALUES ('JPY', '2024-01-05', 160250000), ('BHD', '2024-01-05', 410000)`); err != nil {
+		t.Fatalf("failed to seed rates: %v", err)
+	}
+	// fx_convert must agree with fx.Convert on currencies without two decimals
+	tests := []struct {
+		cents    int64
+		from, to string
+		micros   [2]int64
+	}{
+		{10000, "EUR", "JPY", [2]int64{1000000, 160250000}},
+		{16025, "JPY", "EUR", [2]int64{160250000, 1000000}},
+		{10000, "EUR", "BHD", [2]int64{1000000, 410000}},
+		{1000, "JPY", "BHD", [2]int64{160250000, 410000}},
+		{-1001, "BHD", "JPY", [2]int64{410000, 160250000}},
+	}
+	for _, tt := range tests {
+		want := fx.Convert(tt.cents, tt.from, tt.to, tt.micros[0], tt.micros[1])
+		var got int64
+		if err := db.GetContext(ctx, &got, `SELECT fx_convert($1, $2, $3, '2024-01-05')`, tt.cents, tt.from, tt.to); err != nil {
+			t.Fatalf("fx_convert() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("fx_convert(%d, %s, %s) = %d, want %d", tt.cents, tt.from, tt.to, got, want)
+		}
+	}
+}
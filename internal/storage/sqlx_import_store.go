@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/lennardclaproth/my-finances-tracker/internal/importer"
 )
 
@@ -43,6 +44,21 @@ func (s *SQLXImportStore) OldestPending() (*importer.Import, error) {
 	return &imp, nil
 }
 
+// FetchCompleted returns the completed imports, oldest first.
+func (s *SQLXImportStore) FetchCompleted(ctx context.Context) ([]*importer.Import, error) {
+	var imps []*importer.Import
+	query := fmt.Sprintf(`
+		SELECT id, vendor_id, path, status, status_msg, duplicates, total_rows, imported, failed, created_at, updated_at
+		FROM %s
+		WHERE status = $1
+		ORDER BY created_at ASC
+	`, TableImports)
+	if err := sqlx.SelectContext(ctx, s.db.GetExecutor(ctx), &imps, query, importer.ImportStatusCompleted); err != nil {
+		return nil, fmt.Errorf("sqlx_import_store: failed to fetch completed imports: %w", err)
+	}
+	return imps, nil
+}
+
 func (s *SQLXImportStore) UpdateState(ctx context.Context, imp *importer.Import) error {
 	query := fmt.Sprintf(`
 		UPDATE %s
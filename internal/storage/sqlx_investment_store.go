@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lennardclaproth/my-finances-tracker/internal/investment"
+)
+
+type SQLXInvestmentStore struct {
+	db *DB
+}
+
+func NewSQLXInvestmentStore(db *DB) *SQLXInvestmentStore {
+	return &SQLXInvestmentStore{db: db}
+}
+
+func (s *SQLXInvestmentStore) CreateSecurity(ctx context.Context, sec *investment.Security) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (id, isin, symbol, name, currency, created_at, updated_at)
+		VALUES (:id, :isin, :symbol, :name, :currency, :created_at, :updated_at)
+	`, TableSecurities)
+	if _, err := sqlx.NamedExecContext(ctx, s.db.GetExecutor(ctx), query, sec); err != nil {
+		return fmt.Errorf("sqlx_investment_store: failed to save security: %w", err)
+	}
+	return nil
+}
+
+// SaveSecurity updates the symbol and name of the security.
+func (s *SQLXInvestmentStore) SaveSecurity(ctx context.Context, sec *investment.Security) error {
+	query := fmt.Sprintf(`
+		UPDATE %s SET symbol = :symbol, name = :name, updated_at = :updated_at
+		WHERE id = :id
+	`, TableSecurities)
+	res, err := sqlx.NamedExecContext(ctx, s.db.GetExecutor(ctx), query, sec)
+	if err != nil {
+		return fmt.Errorf("sqlx_investment_store: failed to update security: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return investment.ErrSecurityNotFound
+	}
+	return nil
+}
+
+func (s *SQLXInvestmentStore) FetchSecurity(ctx context.Context, id uuid.UUID) (*investment.Security, error) {
+	return s.fetchSecurity(ctx, "id", id)
+}
+
+func (s *SQLXInvestmentStore) FetchSecurityByISIN(ctx context.Context, isin string) (*investment.Security, error) {
+	return s.fetchSecurity(ctx, "isin", strings.ToUpper(isin))
+}
+
+func (s *SQLXInvestmentStore) fetchSecurity(ctx context.Context, column string, value any) (*investment.Security, error) {
+	var sec investment.Security
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE %s = $1`, TableSecurities, column)
+	if err := sqlx.GetContext(ctx, s.db.GetExecutor(ctx), &sec, query, value); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, investment.ErrSecurityNotFound
+		}
+		return nil, fmt.Errorf("sqlx_investment_store: failed to fetch security: %w", err)
+	}
+	return &sec, nil
+}
+
+func (s *SQLXInvestmentStore) ListSecurities(ctx context.Context) ([]*investment.Security, error) {
+	securities := []*investment.Security{}
+	query := fmt.Sprintf(`SELECT * FROM %s ORDER BY name ASC`, TableSecurities)
+	if err := sqlx.SelectContext(ctx, s.db.GetExecutor(ctx), &securities, query); err != nil {
+		return nil, fmt.Errorf("sqlx_investment_store: failed to list securities: %w", err)
+	}
+	return securities, nil
+}
+
+// tradeBatchSize keeps the bind parameters of a single insert, 15 per trade,
+// below the limit of Postgres.
+const tradeBatchSize = 1000
+
+const tradeColumns = `id, account, security_id, date, type, quantity_micros, price, amount_cents, fee_cents, currency, ratio_from, ratio_to, external_id, description, created_at`
+
+// SaveTrades stores the trades that were not imported before and returns how
+// many it stored.
+func (s *SQLXInvestmentStore) SaveTrades(ctx context.Context, trades []*investment.Trade) (int, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (%s)
+		VALUES (:id, :account, :security_id, :date, :type, :quantity_micros, :price, :amount_cents, :fee_cents, :currency, :ratio_from, :ratio_to, :external_id, :description, :created_at)
+		ON CONFLICT (account, external_id) DO NOTHING
+	`, TableInvestmentTrades, tradeColumns)
+	saved := 0
+	err := s.db.WithTx(ctx, func(ctx context.Context) error {
+		for start := 0; start < len(trades); start += tradeBatchSize {
+			batch := trades[start:min(start+tradeBatchSize, len(trades))]
+			res, err := sqlx.NamedExecContext(ctx, s.db.GetExecutor(ctx), query, batch)
+			if err != nil {
+				return fmt.Errorf("sqlx_investment_store: failed to save trades: %w", err)
+			}
+			n, _ := res.RowsAffected()
+			saved += int(n)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return saved, nil
+}
+
+// Trades returns the trades up to and including the day, oldest first.
+func (s *SQLXInvestmentStore) Trades(ctx context.Context, through time.Time) ([]*investment.Trade, error) {
+	end := time.Date(through.Year(), through.Month(), through.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	return s.ListTrades(ctx, investment.TradeFilter{To: end.Add(-time.Nanosecond)})
+}
+
+// ListTrades returns the trades matching the filter, oldest first.
+func (s *SQLXInvestmentStore) ListTrades(ctx context.Context, f investment.TradeFilter) ([]*investment.Trade, error) {
+	where := []string{"TRUE"}
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if f.SecurityID != nil {
+		where = append(where, "security_id = "+arg(*f.SecurityID))
+	}
+	if f.Account != "" {
+		where = append(where, "account = "+arg(f.Account))
+	}
+	if !f.From.IsZero() {
+		where = append(where, "date >= "+arg(f.From))
+	}
+	if !f.To.IsZero() {
+		where = append(where, "date <= "+arg(f.To))
+	}
+	trades := []*investment.Trade{}
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE %s ORDER BY date ASC`, tradeColumns, TableInvestmentTrades, strings.Join(where, " AND "))
+	if err := sqlx.SelectContext(ctx, s.db.GetExecutor(ctx), &trades, query, args...); err != nil {
+		return nil, fmt.Errorf("sqlx_investment_store: failed to list trades: %w", err)
+	}
+	return trades, nil
+}
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lennardclaproth/my-finances-tracker/internal/label"
+	"github.com/lib/pq"
+)
+
+// LabelUsage is a label with the number of transactions carrying it.
+type LabelUsage struct {
+	label.Label
+	Count int `db:"count"`
+}
+
+// LabelTotals are the cash flows of the transactions carrying a label.
+type LabelTotals struct {
+	Name     string `db:"name"`
+	Count    int    `db:"count"`
+	InCents  int64  `db:"in_cents"`
+	OutCents int64  `db:"out_cents"`
+}
+
+type SQLXLabelStore struct {
+	db *DB
+}
+
+func NewSQLXLabelStore(db *DB) *SQLXLabelStore {
+	return &SQLXLabelStore{db: db}
+}
+
+func (s *SQLXLabelStore) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return s.db.WithTx(ctx, fn)
+}
+
+// Ensure returns the labels with the given names, creating missing ones.
+func (s *SQLXLabelStore) Ensure(ctx context.Context, names []string) ([]*label.Label, error) {
+	executor := s.db.GetExecutor(ctx)
+	insert := fmt.Sprintf(`INSERT INTO %s (id, name, created_at) VALUES ($1, $2, $3) ON CONFLICT (name) DO NOTHING`, TableLabels)
+	for _, name := range names {
+		l, err := label.NewLabel(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := executor.ExecContext(ctx, insert, l.ID, l.Name, l.CreatedAt); err != nil {
+			return nil, fmt.Errorf("sqlx_label_store: failed to save label: %w", err)
+		}
+	}
+	return s.FetchByNames(ctx, names)
+}
+
+func (s *SQLXLabelStore) FetchByNames(ctx context.Context, names []string) ([]*label.Label, error) {
+	var labels []*label.Label
+	query := fmt.Sprintf(`SELECT id, name, created_at FROM %s WHERE name = ANY($1) ORDER BY name ASC`, TableLabels)
+	if err := sqlx.SelectContext(ctx, s.db.GetExecutor(ctx), &labels, query, pq.Array(names)); err != nil {
+		return nil, fmt.Errorf("sqlx_label_store: failed to fetch labels: %w", err)
+	}
+	return labels, nil
+}
+
+// List returns all labels with their usage, most used first.
+func (s *SQLXLabelStore) List(ctx context.Context) ([]LabelUsage, error) {
+	var labels []LabelUsage
+	query := fmt.Sprintf(`
+		SELECT l.id, l.name, l.created_at, COUNT(tl.transaction_id) AS count
+		FROM %s l
+		LEFT JOIN %s tl ON tl.label_id = l.id
+		GROUP BY l.id
+		ORDER BY count DESC, l.name ASC
+	`, TableLabels, TableTransactionLabels)
+	if err := sqlx.SelectContext(ctx, s.db.GetExecutor(ctx), &labels, query); err != nil {
+		return nil, fmt.Errorf("sqlx_label_store: failed to list labels: %w", err)
+	}
+	return labels, nil
+}
+
+func (s *SQLXLabelStore) Attach(ctx context.Context, transactionIDs, labelIDs []uuid.UUID) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (transaction_id, label_id)
+		SELECT t, l FROM unnest($1::uuid[]) t CROSS JOIN unnest($2::uuid[]) l
+		ON CONFLICT DO NOTHING
+	`, TableTransactionLabels)
+	if _, err := s.db.GetExecutor(ctx).ExecContext(ctx, query, pq.Array(transactionIDs), pq.Array(labelIDs)); err != nil {
+		return fmt.Errorf("sqlx_label_store: failed to attach labels: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLXLabelStore) Detach(ctx context.Context, transactionIDs, labelIDs []uuid.UUID) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE transaction_id = ANY($1) AND label_id = ANY($2)`, TableTransactionLabels)
+	if _, err := s.db.GetExecutor(ctx).ExecContext(ctx, query, pq.Array(transactionIDs), pq.Array(labelIDs)); err != nil {
+		return fmt.Errorf("sqlx_label_store: failed to detach labels: %w", err)
+	}
+	return nil
+}
+
+// NamesFor returns the label names per transaction.
+func (s *SQLXLabelStore) NamesFor(ctx context.Context, transactionIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	var rows []struct {
+		TransactionID uuid.UUID `db:"transaction_id"`
+		Name          string    `db:"name"`
+	}
+	query := fmt.Sprintf(`
+		SELECT tl.transaction_id, l.name
+		FROM %s tl JOIN %s l ON l.id = tl.label_id
+		WHERE tl.transaction_id = ANY($1)
+		ORDER BY l.name ASC
+	`, TableTransactionLabels, TableLabels)
+	if err := sqlx.SelectContext(ctx, s.db.GetExecutor(ctx), &rows, query, pq.Array(transactionIDs)); err != nil {
+		return nil, fmt.Errorf("sqlx_label_store: failed to fetch transaction labels: %w", err)
+	}
+	names := make(map[uuid.UUID][]string, len(transactionIDs))
+	for _, r := range rows {
+		names[r.TransactionID] = append(names[r.TransactionID], r.Name)
+	}
+	return names, nil
+}
+
+// Totals sums the cash flows per label over the report view converted to
+// the currency, ignored transactions and transactions in a currency without
+// rates are left out. Confirmed refunds count towards the labels of their
+// original transaction. Zero from or to dates leave the range open.
+func (s *SQLXLabelStore) Totals(ctx context.Context, from, to time.Time, currency string) ([]LabelTotals, error) {
+	var totals []LabelTotals
+	query := fmt.Sprintf(`
+		SELECT
+			l.name,
+			COUNT(*) AS count,
+			COALESCE(SUM(fx_convert(r.amount_cents, r.currency, $3, r.date)) FILTER (WHERE r.direction = 'in'), 0) AS in_cents,
+			COALESCE(SUM(fx_convert(r.amount_cents, r.currency, $3, r.date)) FILTER (WHERE r.direction = 'out'), 0) AS out_cents
+		FROM %s r
+		JOIN %s tl ON tl.transaction_id = COALESCE(r.refund_of, r.id)
+		JOIN %s l ON l.id = tl.label_id
+		WHERE NOT r.ignored
+		  AND ($1::date IS NULL OR r.date >= $1)
+		  AND ($2::date IS NULL OR r.date <= $2)
+		GROUP BY l.name
+		ORDER BY l.name ASC
+	`, ViewReportTransactions, TableTransactionLabels, TableLabels)
+	if err := sqlx.SelectContext(ctx, s.db.GetExecutor(ctx), &totals, query, nullDate(from), nullDate(to), currency); err != nil {
+		return nil, fmt.Errorf("sqlx_label_store: failed to sum label totals: %w", err)
+	}
+	return totals, nil
+}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lennardclaproth/my-finances-tracker/internal/marketdata"
+)
+
+// upsertBatchSize keeps the number of bind parameters of a single insert
+// well below the limit of Postgres.
+const upsertBatchSize = 1000
+
+type SQLXMarketHistoryStore struct {
+	db *DB
+}
+
+func NewSQLXMarketHistoryStore(db *DB) *SQLXMarketHistoryStore {
+	return &SQLXMarketHistoryStore{db: db}
+}
+
+// Upsert stores the history, a day that is already stored for the symbol is
+// overwritten.
+func (s *SQLXMarketHistoryStore) Upsert(ctx context.Context, history []*marketdata.History) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (id, symbol, date, open, high, low, close, volume)
+		VALUES (:id, :symbol, :date, :open, :high, :low, :close, :volume)
+		ON CONFLICT (symbol, date) DO UPDATE SET
+			open = EXCLUDED.open,
+			high = EXCLUDED.high,
+			low = EXCLUDED.low,
+			close = EXCLUDED.close,
+			volume = EXCLUDED.volume
+	`, TableMarketHistory)
+	return s.db.WithTx(ctx, func(ctx context.Context) error {
+		for start := 0; start < len(history); start += upsertBatchSize {
+			batch := history[start:min(start+upsertBatchSize, len(history))]
+			if _, err := sqlx.NamedExecContext(ctx, s.db.GetExecutor(ctx), query, dedupeHistory(batch)); err != nil {
+				return fmt.Errorf("sqlx_market_history_store: failed to upsert history: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// dedupeHistory keeps the last row of every symbol and day, Postgres rejects
+// an upsert that touches the same row twice.
+func dedupeHistory(history []*marketdata.History) []*marketdata.History {
+	type key struct {
+		symbol string
+		date   time.Time
+	}
+	index := map[key]int{}
+	res := make([]*marketdata.History, 0, len(history))
+	for _, h := range history {
+		k := key{h.Symbol, h.Date}
+		if i, ok := index[k]; ok {
+			res[i] = h
+			continue
+		}
+		index[k] = len(res)
+		res = append(res, h)
+	}
+	return res
+}
+
+// Range returns the history of the symbol from from through to, oldest
+// first.
+func (s *SQLXMarketHistoryStore) Range(ctx context.Context, symbol string, from, to time.Time) ([]*marketdata.History, error) {
+	history := []*marketdata.History{}
+	query := fmt.Sprintf(`
+		SELECT * FROM %s
+		WHERE symbol = $1 AND date >= $2 AND date <= $3
+		ORDER BY date ASC
+	`, TableMarketHistory)
+	if err := sqlx.SelectContext(ctx, s.db.GetExecutor(ctx), &history, query, marketdata.NormaliseSymbol(symbol), from.Format(time.DateOnly), to.Format(time.DateOnly)); err != nil {
+		return nil, fmt.Errorf("sqlx_market_history_store: failed to list history: %w", err)
+	}
+	return history, nil
+}
+
+// Latest returns the most recent price of the symbol.
+func (s *SQLXMarketHistoryStore) Latest(ctx context.Context, symbol string) (*marketdata.History, error) {
+	var h marketdata.History
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE symbol = $1 ORDER BY date DESC LIMIT 1`, TableMarketHistory)
+	if err := sqlx.GetContext(ctx, s.db.GetExecutor(ctx), &h, query, marketdata.NormaliseSymbol(symbol)); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, marketdata.ErrNoHistory
+		}
+		return nil, fmt.Errorf("sqlx_market_history_store: failed to fetch latest price: %w", err)
+	}
+	return &h, nil
+}
+
+// LatestOn returns the last price of the symbol on or before the date, nil
+// when there is none.
+func (s *SQLXMarketHistoryStore) LatestOn(ctx context.Context, symbol string, date time.Time) (*marketdata.History, error) {
+	var h marketdata.History
+	query := fmt.Sprintf(`
+		SELECT * FROM %s
+		WHERE symbol = $1 AND date <= $2
+		ORDER BY date DESC LIMIT 1
+	`, TableMarketHistory)
+	if err := sqlx.GetContext(ctx, s.db.GetExecutor(ctx), &h, query, marketdata.NormaliseSymbol(symbol), date.Format(time.DateOnly)); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("sqlx_market_history_store: failed to fetch price: %w", err)
+	}
+	return &h, nil
+}
+
+// TrackedSymbols returns the symbols of net worth items and securities and
+// the symbols that have prices.
+func (s *SQLXMarketHistoryStore) TrackedSymbols(ctx context.Context) ([]string, error) {
+	symbols := []string{}
+	query := fmt.Sprintf(`
+		SELECT UPPER(TRIM(symbol)) FROM %s WHERE TRIM(symbol) <> ''
+		UNION
+		SELECT symbol FROM %s WHERE symbol <> ''
+		UNION
+		SELECT DISTINCT symbol FROM %s
+		ORDER BY 1
+	`, TableNetWorthItems, TableSecurities, TableMarketHistory)
+	if err := sqlx.SelectContext(ctx, s.db.GetExecutor(ctx), &symbols, query); err != nil {
+		return nil, fmt.Errorf("sqlx_market_history_store: failed to list symbols: %w", err)
+	}
+	return symbols, nil
+}
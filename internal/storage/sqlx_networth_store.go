@@ -0,0 +1,218 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lennardclaproth/my-finances-tracker/internal/networth"
+)
+
+// networthItemRow is the database representation of an item, the loan is
+// stored as JSONB.
+type networthItemRow struct {
+	ID             uuid.UUID `db:"id"`
+	Name           string    `db:"name"`
+	Class          string    `db:"class"`
+	Symbol         string    `db:"symbol"`
+	QuantityMicros int64     `db:"quantity_micros"`
+	Loan           []byte    `db:"loan"`
+	CreatedAt      time.Time `db:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at"`
+}
+
+func toNetworthItemRow(i *networth.Item) (networthItemRow, error) {
+	row := networthItemRow{
+		ID:             i.ID,
+		Name:           i.Name,
+		Class:          string(i.Class),
+		Symbol:         i.Symbol,
+		QuantityMicros: i.QuantityMicros,
+		CreatedAt:      i.CreatedAt,
+		UpdatedAt:      i.UpdatedAt,
+	}
+	if i.Loan != nil {
+		loan, err := json.Marshal(i.Loan)
+		if err != nil {
+			return row, fmt.Errorf("sqlx_networth_store: failed to encode loan: %w", err)
+		}
+		row.Loan = loan
+	}
+	return row, nil
+}
+
+func (row networthItemRow) toItem() (*networth.Item, error) {
+	i := &networth.Item{
+		ID:             row.ID,
+		Name:           row.Name,
+		Class:          networth.Class(row.Class),
+		Symbol:         row.Symbol,
+		QuantityMicros: row.QuantityMicros,
+		CreatedAt:      row.CreatedAt,
+		UpdatedAt:      row.UpdatedAt,
+	}
+	if row.Loan != nil {
+		if err := json.Unmarshal(row.Loan, &i.Loan); err != nil {
+			return nil, fmt.Errorf("sqlx_networth_store: failed to decode loan of item %s: %w", row.ID, err)
+		}
+	}
+	return i, nil
+}
+
+const networthItemColumns = `id, name, class, symbol, quantity_micros, loan, created_at, updated_at`
+
+type SQLXNetWorthStore struct {
+	db *DB
+}
+
+func NewSQLXNetWorthStore(db *DB) *SQLXNetWorthStore {
+	return &SQLXNetWorthStore{db: db}
+}
+
+func (s *SQLXNetWorthStore) CreateItem(ctx context.Context, i *networth.Item) error {
+	row, err := toNetworthItemRow(i)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		INSERT INTO %s (%s)
+		VALUES (:id, :name, :class, :symbol, :quantity_micros, :loan, :created_at, :updated_at)
+	`, TableNetWorthItems, networthItemColumns)
+	if _, err := sqlx.NamedExecContext(ctx, s.db.GetExecutor(ctx), query, row); err != nil {
+		return fmt.Errorf("sqlx_networth_store: failed to save item: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLXNetWorthStore) DeleteItem(ctx context.Context, id uuid.UUID) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, TableNetWorthItems)
+	res, err := s.db.GetExecutor(ctx).ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("sqlx_networth_store: failed to delete item: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return networth.ErrItemNotFound
+	}
+	return nil
+}
+
+func (s *SQLXNetWorthStore) FetchItem(ctx context.Context, id uuid.UUID) (*networth.Item, error) {
+	var row networthItemRow
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE id = $1`, networthItemColumns, TableNetWorthItems)
+	if err := sqlx.GetContext(ctx, s.db.GetExecutor(ctx), &row, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, networth.ErrItemNotFound
+		}
+		return nil, fmt.Errorf("sqlx_networth_store: failed to fetch item: %w", err)
+	}
+	return row.toItem()
+}
+
+func (s *SQLXNetWorthStore) ListItems(ctx context.Context) ([]*networth.Item, error) {
+	var rows []networthItemRow
+	query := fmt.Sprintf(`SELECT %s FROM %s ORDER BY class ASC, name ASC`, networthItemColumns, TableNetWorthItems)
+	if err := sqlx.SelectContext(ctx, s.db.GetExecutor(ctx), &rows, query); err != nil {
+		return nil, fmt.Errorf("sqlx_networth_store: failed to list items: %w", err)
+	}
+	items := make([]*networth.Item, 0, len(rows))
+	for _, row := range rows {
+		i, err := row.toItem()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	return items, nil
+}
+
+// SaveValuation creates the valuation or replaces the value of the item on
+// the same day.
+func (s *SQLXNetWorthStore) SaveValuation(ctx context.Context, v *networth.Valuation) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (item_id, date, value_cents)
+		VALUES (:item_id, :date, :value_cents)
+		ON CONFLICT (item_id, date) DO UPDATE SET value_cents = EXCLUDED.value_cents
+	`, TableNetWorthValues)
+	if _, err := sqlx.NamedExecContext(ctx, s.db.GetExecutor(ctx), query, v); err != nil {
+		return fmt.Errorf("sqlx_networth_store: failed to save valuation: %w", err)
+	}
+	return nil
+}
+
+// Valuations returns the valuations of all items, oldest first.
+func (s *SQLXNetWorthStore) Valuations(ctx context.Context) ([]*networth.Valuation, error) {
+	valuations := []*networth.Valuation{}
+	query := fmt.Sprintf(`SELECT item_id, date, value_cents FROM %s ORDER BY date ASC`, TableNetWorthValues)
+	if err := sqlx.SelectContext(ctx, s.db.GetExecutor(ctx), &valuations, query); err != nil {
+		return nil, fmt.Errorf("sqlx_networth_store: failed to list valuations: %w", err)
+	}
+	return valuations, nil
+}
+
+// Snapshots returns the snapshots from from through to.
+func (s *SQLXNetWorthStore) Snapshots(ctx context.Context, from, to time.Time) ([]*networth.Snapshot, error) {
+	snapshots := []*networth.Snapshot{}
+	query := fmt.Sprintf(`
+		SELECT date, source, name, class, value_cents, currency, original_cents, provisional, created_at FROM %s
+		WHERE date >= $1 AND date <= $2
+		ORDER BY date ASC, source ASC
+	`, TableNetWorthSnapshots)
+	if err := sqlx.SelectContext(ctx, s.db.GetExecutor(ctx), &snapshots, query, from.Format(time.DateOnly), to.Format(time.DateOnly)); err != nil {
+		return nil, fmt.Errorf("sqlx_networth_store: failed to list snapshots: %w", err)
+	}
+	return snapshots, nil
+}
+
+// SaveSnapshots stores the snapshots of a day, replacing its provisional
+// snapshots, unless the day already has final snapshots. Final history is
+// never overwritten.
+func (s *SQLXNetWorthStore) SaveSnapshots(ctx context.Context, date time.Time, snapshots []*networth.Snapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	return s.db.WithTx(ctx, func(ctx context.Context) error {
+		executor := s.db.GetExecutor(ctx)
+		var exists bool
+		query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE date = $1 AND NOT provisional)`, TableNetWorthSnapshots)
+		if err := sqlx.GetContext(ctx, executor, &exists, query, date.Format(time.DateOnly)); err != nil {
+			return fmt.Errorf("sqlx_networth_store: failed to check snapshots: %w", err)
+		}
+		if exists {
+			return nil
+		}
+		query = fmt.Sprintf(`DELETE FROM %s WHERE date = $1`, TableNetWorthSnapshots)
+		if _, err := executor.ExecContext(ctx, query, date.Format(time.DateOnly)); err != nil {
+			return fmt.Errorf("sqlx_networth_store: failed to delete provisional snapshots: %w", err)
+		}
+		query = fmt.Sprintf(`
+			INSERT INTO %s (date, source, name, class, value_cents, currency, original_cents, provisional, created_at)
+			VALUES (:date, :source, :name, :class, :value_cents, :currency, :original_cents, :provisional, :created_at)
+		`, TableNetWorthSnapshots)
+		if _, err := sqlx.NamedExecContext(ctx, executor, query, snapshots); err != nil {
+			return fmt.Errorf("sqlx_networth_store: failed to save snapshots: %w", err)
+		}
+		return nil
+	})
+}
+
+// ConfirmSnapshots makes the provisional snapshots through the day final and
+// returns the number of days confirmed.
+func (s *SQLXNetWorthStore) ConfirmSnapshots(ctx context.Context, through time.Time) (int, error) {
+	var n int
+	query := fmt.Sprintf(`
+		WITH confirmed AS (
+			UPDATE %s SET provisional = FALSE
+			WHERE provisional AND date <= $1
+			RETURNING date
+		)
+		SELECT COUNT(DISTINCT date) FROM confirmed
+	`, TableNetWorthSnapshots)
+	if err := sqlx.GetContext(ctx, s.db.GetExecutor(ctx), &n, query, through.Format(time.DateOnly)); err != nil {
+		return 0, fmt.Errorf("sqlx_networth_store: failed to confirm snapshots: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lennardclaproth/my-finances-tracker/internal/recurring"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+const recurringColumns = `id, group_key, name, counterparty_id, account, currency, direction, frequency, amount_cents, previous_amount_cents,
+	first_date, last_date, next_date, occurrences, status, created_at, updated_at`
+
+type SQLXRecurringStore struct {
+	db *DB
+}
+
+func NewSQLXRecurringStore(db *DB) *SQLXRecurringStore {
+	return &SQLXRecurringStore{db: db}
+}
+
+// Replace stores the series in place of all earlier detected ones.
+func (s *SQLXRecurringStore) Replace(ctx context.Context, series []*recurring.Series) error {
+	return s.db.WithTx(ctx, func(ctx context.Context) error {
+		executor := s.db.GetExecutor(ctx)
+		if _, err := executor.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s`, TableRecurringSeries)); err != nil {
+			return fmt.Errorf("sqlx_recurring_store: failed to clear recurring series: %w", err)
+		}
+		if len(series) == 0 {
+			return nil
+		}
+		query := fmt.Sprintf(`
+			INSERT INTO %s (%s)
+			VALUES (:id, :group_key, :name, :counterparty_id, :account, :currency, :direction, :frequency, :amount_cents, :previous_amount_cents,
+				:first_date, :last_date, :next_date, :occurrences, :status, :created_at, :updated_at)
+		`, TableRecurringSeries, recurringColumns)
+		if _, err := sqlx.NamedExecContext(ctx, executor, query, series); err != nil {
+			return fmt.Errorf("sqlx_recurring_store: failed to save recurring series: %w", err)
+		}
+		return nil
+	})
+}
+
+// List returns the series with the given status and direction, the next
+// expected payment first. Empty values match any series.
+func (s *SQLXRecurringStore) List(ctx context.Context, status recurring.Status, direction transaction.CashFlowDirection) ([]*recurring.Series, error) {
+	series := []*recurring.Series{}
+	query := fmt.Sprintf(`
+		SELECT %s FROM %s
+		WHERE ($1 = '' OR status = $1) AND ($2 = '' OR direction = $2)
+		ORDER BY next_date ASC, name ASC
+	`, recurringColumns, TableRecurringSeries)
+	if err := sqlx.SelectContext(ctx, s.db.GetExecutor(ctx), &series, query, status, direction); err != nil {
+		return nil, fmt.Errorf("sqlx_recurring_store: failed to list recurring series: %w", err)
+	}
+	return series, nil
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/refund"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+	"github.com/lib/pq"
+)
+
+type SQLXRefundStore struct {
+	db *DB
+}
+
+func NewSQLXRefundStore(db *DB) *SQLXRefundStore {
+	return &SQLXRefundStore{db: db}
+}
+
+func (s *SQLXRefundStore) Create(ctx context.Context, link *refund.Link) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (id, original_id, refund_id, amount_cents, status, created_at, updated_at)
+		VALUES (:id, :original_id, :refund_id, :amount_cents, :status, :created_at, :updated_at)
+	`, TableRefundLinks)
+	_, err := s.db.NamedExecContext(ctx, query, link)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return refund.ErrLinkAlreadyExists
+		}
+		return fmt.Errorf("sqlx_refund_store: failed to save refund link: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLXRefundStore) FetchByID(ctx context.Context, id uuid.UUID) (*refund.Link, error) {
+	var link refund.Link
+	query := fmt.Sprintf(`SELECT id, original_id, refund_id, amount_cents, status, created_at, updated_at FROM %s WHERE id = $1`, TableRefundLinks)
+	err := s.db.GetContext(ctx, &link, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, refund.ErrLinkNotFound
+		}
+		return nil, fmt.Errorf("sqlx_refund_store: failed to fetch refund link: %w", err)
+	}
+	return &link, nil
+}
+
+func (s *SQLXRefundStore) UpdateStatus(ctx context.Context, link *refund.Link) error {
+	query := fmt.Sprintf(`UPDATE %s SET status = :status, updated_at = :updated_at WHERE id = :id`, TableRefundLinks)
+	_, err := s.db.NamedExecContext(ctx, query, link)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return refund.ErrLinkAlreadyExists
+		}
+		return fmt.Errorf("sqlx_refund_store: failed to update refund link: %w", err)
+	}
+	return nil
+}
+
+// List returns the refund links with the given status, newest first. An empty
+// status returns all links.
+func (s *SQLXRefundStore) List(ctx context.Context, status refund.LinkStatus) ([]*refund.Link, error) {
+	links := []*refund.Link{}
+	query := fmt.Sprintf(`
+		SELECT id, original_id, refund_id, amount_cents, status, created_at, updated_at
+		FROM %s
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at DESC
+	`, TableRefundLinks)
+	if err := s.db.SelectContext(ctx, &links, query, status); err != nil {
+		return nil, fmt.Errorf("sqlx_refund_store: failed to list refund links: %w", err)
+	}
+	return links, nil
+}
+
+func (s *SQLXRefundStore) FindCandidates(ctx context.Context, rf *transaction.Transaction, windowDays int) ([]*transaction.Transaction, error) {
+	// Candidates are outgoing transactions from the same counterparty, matched
+	// on the resolved counterparty or the raw description, within the
+	// window whose amount minus what has already been refunded still covers the
+	// refund. Pairs that were rejected before are never suggested again. The
+	// closest amount wins, ties are broken by the most recent purchase.
+	query := fmt.Sprintf(`
+		SELECT t.* FROM %[1]s t
+		WHERE t.direction = $1
+			AND t.id <> $2
+			AND (
+				($7::uuid IS NOT NULL AND t.counterparty_id = $7::uuid)
+				OR lower(trim(t.description)) = lower(trim($3))
+			)
+			AND t.date <= $4::date
+			AND t.date >= $4::date - $5::int
+			AND t.amount_cents - COALESCE((
+				SELECT SUM(l.amount_cents) FROM %[2]s l
+				WHERE l.original_id = t.id AND l.status <> 'rejected'
+			), 0) >= $6
+			AND NOT EXISTS (
+				SELECT 1 FROM %[2]s r WHERE r.original_id = t.id AND r.refund_id = $2
+			)
+		ORDER BY t.amount_cents - $6 ASC, t.date DESC
+		LIMIT 5
+	`, TableTransactions, TableRefundLinks)
+	executor := s.db.GetExecutor(ctx)
+	rows, err := executor.QueryxContext(ctx, query, transaction.CashOut, rf.ID, rf.Description, rf.Date, windowDays, rf.AmountCents, rf.CounterpartyID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlx_refund_store: failed to find refund candidates: %w", err)
+	}
+	defer rows.Close()
+	return parseRows(rows)
+}
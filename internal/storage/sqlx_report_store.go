@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lennardclaproth/my-finances-tracker/internal/category"
+	"github.com/lennardclaproth/my-finances-tracker/internal/report"
+)
+
+type SQLXReportStore struct {
+	db *DB
+}
+
+func NewSQLXReportStore(db *DB) *SQLXReportStore {
+	return &SQLXReportStore{db: db}
+}
+
+// cashflowGroups maps the groupings to the expression of the group key.
+var cashflowGroups = map[report.GroupBy]string{
+	"":                    "''",
+	report.ByTag:          "COALESCE(NULLIF(r.tag, ''), $5)",
+	report.ByAccount:      "r.account",
+	report.ByCounterparty: "COALESCE(c.name, '')",
+}
+
+// Cashflow sums income and expenses per bucket and group over the report
+// view, converted to the currency with the rates of the transaction dates.
+// Transactions in a currency without rates are counted as unconverted and
+// left out of the sums. Ignored transactions and transfers between our own
+// accounts are left out. Zero from or to dates leave the range open. Dates
+// are truncated as plain dates, the session time zone does not move
+// transactions to another bucket.
+func (s *SQLXReportStore) Cashflow(ctx context.Context, from, to time.Time, interval report.Interval, groupBy report.GroupBy, currency string) ([]report.Cashflow, error) {
+	group, ok := cashflowGroups[groupBy]
+	if !ok {
+		return nil, report.ErrInvalidGroupBy
+	}
+	var rows []report.Cashflow
+	query := fmt.Sprintf(`
+		SELECT
+			date_trunc($3, r.date::timestamp)::date AS start,
+			%s AS grp,
+			COALESCE(SUM(x.cents) FILTER (WHERE r.direction = 'in'), 0) AS income_cents,
+			COALESCE(SUM(x.cents) FILTER (WHERE r.direction = 'out'), 0) AS expense_cents,
+			COUNT(*) FILTER (WHERE x.cents IS NULL) AS unconverted
+		FROM %s r
+		CROSS JOIN LATERAL (SELECT fx_convert(r.amount_cents, r.currency, $4, r.date) AS cents) x
+		LEFT JOIN %s c ON c.id = r.counterparty_id
+		WHERE NOT r.ignored AND NOT r.transfer
+		  AND ($1::date IS NULL OR r.date >= $1)
+		  AND ($2::date IS NULL OR r.date <= $2)
+		GROUP BY 1, 2
+		ORDER BY 1, 2
+	`, group, ViewReportTransactions, TableCounterparties)
+	args := []any{nullDate(from), nullDate(to), string(interval), currency}
+	if groupBy == report.ByTag {
+		args = append(args, category.Uncategorised)
+	}
+	if err := sqlx.SelectContext(ctx, s.db.GetExecutor(ctx), &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("sqlx_report_store: failed to sum cash flow: %w", err)
+	}
+	for i := range rows {
+		// dates are scanned with a fixed zero offset, report them in UTC
+		y, m, d := rows[i].Start.Date()
+		rows[i].Start = time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	return rows, nil
+}
@@ -0,0 +1,241 @@
+package storage_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/report"
+	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
+)
+
+const (
+	accountA = "NL01TEST0000000001"
+	accountB = "NL02TEST0000000002"
+)
+
+type fixture struct {
+	date        string
+	cents       int64
+	direction   string
+	account     string
+	iban        string
+	tag         string
+	currency    string
+	ignored     bool
+	description string
+}
+
+// cashflowFixtures covers month, quarter and year ends, a leap day, a week
+// across the year end, an ignored transaction, a transfer between our own
+// accounts, a converted amount and one without an exchange rate.
+var cashflowFixtures = []fixture{
+	{date: "2024-01-31", cents: 1000, direction: "out", account: accountA, tag: "groceries"},
+	{date: "2024-02-01", cents: 2000, direction: "out", account: accountA, tag: "groceries"},
+	{date: "2024-02-29", cents: 300000, direction: "in", account: accountA, iban: "NL99OTHR0123456789"},
+	{date: "2024-03-31", cents: 500, direction: "out", account: accountA, ignored: true},
+	{date: "2024-03-31", cents: 10000, direction: "out", account: accountA, iban: accountB},
+	{date: "2024-03-31", cents: 10000, direction: "in", account: accountB, iban: accountA},
+	{date: "2024-03-15", cents: 1000, direction: "out", account: accountB, currency: "USD"},
+	{date: "2024-03-20", cents: 1000, direction: "out", account: accountB, currency: "CHF"},
+	{date: "2024-04-01", cents: 700, direction: "out", account: accountB, tag: "transport"},
+	{date: "2024-12-31", cents: 100, direction: "out", account: accountB, tag: "transport"},
+	{date: "2025-01-01", cents: 5000, direction: "in", account: accountB},
+}
+
+func seedCashflow(t *testing.T, db *storage.DB) {
+	t.Helper()
+	ctx := context.Background()
+	var importID uuid.UUID
+	err := db.QueryRowxContext(ctx, `
+		WITH v AS (INSERT INTO vendors (name) VALUES ('test') RETURNING id)
+		INSERT INTO imports (vendor_id, path) SELECT id, 'test.csv' FROM v RETURNING id
+	`).Scan(&importID)
+	if err != nil {
+		t.Fatalf("failed to seed import: %v", err)
+	}
+	// 1 EUR buys 1.10 USD the day before the USD transaction
+	if _, err := db.ExecContext(ctx, `INSERT INTO fx_rates (currency, date, rate_micros) VALUES ('USD', '2024-03-14', 1100000)`); err != nil {
+		t.Fatalf("failed to seed rates: %v", err)
+	}
+	for i, f := range cashflowFixtures {
+		currency := f.currency
+		if currency == "" {
+			currency = "EUR"
+		}
+		_, err := db.ExecContext(ctx, `
+			INSERT INTO transactions (description, note, source, amount_cents, direction, date, checksum, tag, ignored, row_number, import_id, account, counterparty_iban, currency)
+			VALUES ($1, '', 'test', $2, $3, $4, $5, NULLIF($6, ''), $7, $8, $9, $10, $11, $12)
+		`, fmt.Sprintf("transaction %d", i+1), f.cents, f.direction, f.date, fmt.Sprintf("checksum-%d", i), f.tag, f.ignored, i+1, importID, f.account, f.iban, currency)
+		if err != nil {
+			t.Fatalf("failed to seed transaction %d: %v", i+1, err)
+		}
+	}
+}
+
+// flow is a cash flow row as start/group: income, expense, unconverted.
+type flow struct {
+	start       string
+	group       string
+	income      int64
+	expense     int64
+	unconverted int
+}
+
+func flows(rows []report.Cashflow) []flow {
+	res := make([]flow, 0, len(rows))
+	for _, r := range rows {
+		res = append(res, flow{r.Start.Format(time.DateOnly), r.Group, r.IncomeCents, r.ExpenseCents, r.Unconverted})
+	}
+	return res
+}
+
+func TestSQLXReportStoreCashflow(t *testing.T) {
+	db := testDB(t)
+	seedCashflow(t, db)
+	store := storage.NewSQLXReportStore(db)
+
+	tests := []struct {
+		name     string
+		from, to string
+		interval report.Interval
+		groupBy  report.GroupBy
+		want     []flow
+	}{
+		{
+			name:     "weeks",
+			interval: report.Week,
+			want: []flow{
+				// 31 January and 1 February are in the same week
+				{"2024-01-29", "", 0, 3000, 0},
+				{"2024-02-26", "", 300000, 0, 0},
+				// 1000 USD cents at 1.10
+				{"2024-03-11", "", 0, 909, 0},
+				{"2024-03-18", "", 0, 0, 1},
+				{"2024-04-01", "", 0, 700, 0},
+				// the week of new year starts in December
+				{"2024-12-30", "", 5000, 100, 0},
+			},
+		},
+		{
+			name:     "months",
+			interval: report.Month,
+			want: []flow{
+				{"2024-01-01", "", 0, 1000, 0},
+				{"2024-02-01", "", 300000, 2000, 0},
+				{"2024-03-01", "", 0, 909, 1},
+				{"2024-04-01", "", 0, 700, 0},
+				{"2024-12-01", "", 0, 100, 0},
+				{"2025-01-01", "", 5000, 0, 0},
+			},
+		},
+		{
+			name:     "quarters",
+			interval: report.Quarter,
+			want: []flow{
+				{"2024-01-01", "", 300000, 3909, 1},
+				{"2024-04-01", "", 0, 700, 0},
+				{"2024-10-01", "", 0, 100, 0},
+				{"2025-01-01", "", 5000, 0, 0},
+			},
+		},
+		{
+			name:     "years",
+			interval: report.Year,
+			want: []flow{
+				{"2024-01-01", "", 300000, 4709, 1},
+				{"2025-01-01", "", 5000, 0, 0},
+			},
+		},
+		{
+			name:     "range ending on a month end",
+			from:     "2024-02-01",
+			to:       "2024-03-31",
+			interval: report.Month,
+			want: []flow{
+				{"2024-02-01", "", 300000, 2000, 0},
+				{"2024-03-01", "", 0, 909, 1},
+			},
+		},
+		{
+			name:     "by tag",
+			to:       "2024-04-30",
+			interval: report.Quarter,
+			groupBy:  report.ByTag,
+			want: []flow{
+				{"2024-01-01", "groceries", 0, 3000, 0},
+				{"2024-01-01", "uncategorised", 300000, 909, 1},
+				{"2024-04-01", "transport", 0, 700, 0},
+			},
+		},
+		{
+			name:     "by account",
+			to:       "2024-12-31",
+			interval: report.Year,
+			groupBy:  report.ByAccount,
+			want: []flow{
+				{"2024-01-01", accountA, 300000, 3000, 0},
+				{"2024-01-01", accountB, 0, 1709, 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var from, to time.Time
+			if tt.from != "" {
+				from, _ = time.Parse(time.DateOnly, tt.from)
+			}
+			if tt.to != "" {
+				to, _ = time.Parse(time.DateOnly, tt.to)
+			}
+			rows, err := store.Cashflow(context.Background(), from, to, tt.interval, tt.groupBy, "EUR")
+			if err != nil {
+				t.Fatalf("Cashflow() error = %v", err)
+			}
+			got := flows(rows)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Cashflow() =\n%v\nwant\n%v", got, tt.want)
+			}
+			for _, r := range rows {
+				if r.Start.Location() != time.UTC {
+					t.Errorf("start %s is not in UTC", r.Start)
+				}
+			}
+		})
+	}
+}
+
+func TestSQLXReportStoreCashflowTimeZones(t *testing.T) {
+	db := testDB(t)
+	seedCashflow(t, db)
+	store := storage.NewSQLXReportStore(db)
+	ctx := context.Background()
+
+	want, err := store.Cashflow(ctx, time.Time{}, time.Time{}, report.Month, "", "EUR")
+	if err != nil {
+		t.Fatalf("Cashflow() error = %v", err)
+	}
+	// the session time zone must not move a transaction on a month end to
+	// another bucket
+	for _, tz := range []string{"Pacific/Auckland", "America/Los_Angeles", "Europe/Amsterdam"} {
+		t.Run(tz, func(t *testing.T) {
+			var rows []report.Cashflow
+			err := db.WithTx(ctx, func(ctx context.Context) error {
+				if _, err := db.GetExecutor(ctx).ExecContext(ctx, fmt.Sprintf("SET LOCAL TIME ZONE '%s'", tz)); err != nil {
+					return err
+				}
+				var err error
+				rows, err = store.Cashflow(ctx, time.Time{}, time.Time{}, report.Month, "", "EUR")
+				return err
+			})
+			if err != nil {
+				t.Fatalf("Cashflow() error = %v", err)
+			}
+			if fmt.Sprint(flows(rows)) != fmt.Sprint(flows(want)) {
+				t.Errorf("Cashflow() in %s =\n%v\nwant\n%v", tz, flows(rows), flows(want))
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lennardclaproth/my-finances-tracker/internal/rule"
+)
+
+// ruleRow is the database representation of a rule, conditions and actions
+// are stored as JSONB.
+type ruleRow struct {
+	ID         uuid.UUID `db:"id"`
+	Name       string    `db:"name"`
+	Priority   int       `db:"priority"`
+	Enabled    bool      `db:"enabled"`
+	Conditions []byte    `db:"conditions"`
+	Actions    []byte    `db:"actions"`
+	CreatedAt  time.Time `db:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+func toRuleRow(r *rule.Rule) (ruleRow, error) {
+	conditions, err := json.Marshal(r.Conditions)
+	if err != nil {
+		return ruleRow{}, fmt.Errorf("sqlx_rule_store: failed to encode rule conditions: %w", err)
+	}
+	actions, err := json.Marshal(r.Actions)
+	if err != nil {
+		return ruleRow{}, fmt.Errorf("sqlx_rule_store: failed to encode rule actions: %w", err)
+	}
+	return ruleRow{
+		ID:         r.ID,
+		Name:       r.Name,
+		Priority:   r.Priority,
+		Enabled:    r.Enabled,
+		Conditions: conditions,
+		Actions:    actions,
+		CreatedAt:  r.CreatedAt,
+		UpdatedAt:  r.UpdatedAt,
+	}, nil
+}
+
+// toRule decodes and compiles a stored rule.
+func (row ruleRow) toRule() (*rule.Rule, error) {
+	r := &rule.Rule{
+		ID:        row.ID,
+		Name:      row.Name,
+		Priority:  row.Priority,
+		Enabled:   row.Enabled,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+	}
+	if err := json.Unmarshal(row.Conditions, &r.Conditions); err != nil {
+		return nil, fmt.Errorf("sqlx_rule_store: failed to decode conditions of rule %s: %w", row.ID, err)
+	}
+	if err := json.Unmarshal(row.Actions, &r.Actions); err != nil {
+		return nil, fmt.Errorf("sqlx_rule_store: failed to decode actions of rule %s: %w", row.ID, err)
+	}
+	if err := r.Compile(); err != nil {
+		return nil, fmt.Errorf("sqlx_rule_store: failed to compile rule %s: %w", row.ID, err)
+	}
+	return r, nil
+}
+
+const ruleColumns = `id, name, priority, enabled, conditions, actions, created_at, updated_at`
+
+type SQLXRuleStore struct {
+	db *DB
+}
+
+func NewSQLXRuleStore(db *DB) *SQLXRuleStore {
+	return &SQLXRuleStore{db: db}
+}
+
+func (s *SQLXRuleStore) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return s.db.WithTx(ctx, fn)
+}
+
+func (s *SQLXRuleStore) Create(ctx context.Context, r *rule.Rule) error {
+	row, err := toRuleRow(r)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		INSERT INTO %s (%s)
+		VALUES (:id, :name, :priority, :enabled, :conditions, :actions, :created_at, :updated_at)
+	`, TableRules, ruleColumns)
+	if _, err := sqlx.NamedExecContext(ctx, s.db.GetExecutor(ctx), query, row); err != nil {
+		return fmt.Errorf("sqlx_rule_store: failed to save rule: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLXRuleStore) Update(ctx context.Context, r *rule.Rule) error {
+	row, err := toRuleRow(r)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET name = :name, priority = :priority, enabled = :enabled, conditions = :conditions, actions = :actions, updated_at = :updated_at
+		WHERE id = :id
+	`, TableRules)
+	res, err := sqlx.NamedExecContext(ctx, s.db.GetExecutor(ctx), query, row)
+	if err != nil {
+		return fmt.Errorf("sqlx_rule_store: failed to update rule: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return rule.ErrRuleNotFound
+	}
+	return nil
+}
+
+func (s *SQLXRuleStore) Delete(ctx context.Context, id uuid.UUID) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, TableRules)
+	res, err := s.db.GetExecutor(ctx).ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("sqlx_rule_store: failed to delete rule: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return rule.ErrRuleNotFound
+	}
+	return nil
+}
+
+func (s *SQLXRuleStore) FetchByID(ctx context.Context, id uuid.UUID) (*rule.Rule, error) {
+	var row ruleRow
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE id = $1`, ruleColumns, TableRules)
+	if err := sqlx.GetContext(ctx, s.db.GetExecutor(ctx), &row, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, rule.ErrRuleNotFound
+		}
+		return nil, fmt.Errorf("sqlx_rule_store: failed to fetch rule: %w", err)
+	}
+	return row.toRule()
+}
+
+// List returns all rules in evaluation order.
+func (s *SQLXRuleStore) List(ctx context.Context) ([]*rule.Rule, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s ORDER BY priority ASC, created_at ASC`, ruleColumns, TableRules)
+	return s.fetchMany(ctx, query)
+}
+
+func (s *SQLXRuleStore) FetchEnabled(ctx context.Context) ([]*rule.Rule, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE enabled ORDER BY priority ASC, created_at ASC`, ruleColumns, TableRules)
+	return s.fetchMany(ctx, query)
+}
+
+func (s *SQLXRuleStore) fetchMany(ctx context.Context, query string, args ...any) ([]*rule.Rule, error) {
+	var rows []ruleRow
+	if err := sqlx.SelectContext(ctx, s.db.GetExecutor(ctx), &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("sqlx_rule_store: failed to fetch rules: %w", err)
+	}
+	rules := make([]*rule.Rule, 0, len(rows))
+	for _, row := range rows {
+		r, err := row.toRule()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lennardclaproth/my-finances-tracker/internal/learning"
+)
+
+const suggestionColumns = `id, key_type, key, tag, count, status, rule_id, created_at, updated_at`
+
+type SQLXSuggestionStore struct {
+	db *DB
+}
+
+func NewSQLXSuggestionStore(db *DB) *SQLXSuggestionStore {
+	return &SQLXSuggestionStore{db: db}
+}
+
+func (s *SQLXSuggestionStore) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return s.db.WithTx(ctx, fn)
+}
+
+func (s *SQLXSuggestionStore) Record(ctx context.Context, sg *learning.Suggestion) (*learning.Suggestion, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %[1]s (%[2]s)
+		VALUES (:id, :key_type, :key, :tag, :count, :status, :rule_id, :created_at, :updated_at)
+		ON CONFLICT (key_type, key, tag) DO UPDATE
+		SET count = %[1]s.count + 1, updated_at = EXCLUDED.updated_at
+		RETURNING %[2]s
+	`, TableRuleSuggestions, suggestionColumns)
+	executor := s.db.GetExecutor(ctx)
+	namedQuery, args, err := sqlx.Named(query, sg)
+	if err != nil {
+		return nil, fmt.Errorf("sqlx_suggestion_store: failed to bind named params: %w", err)
+	}
+	var recorded learning.Suggestion
+	if err := sqlx.GetContext(ctx, executor, &recorded, sqlx.Rebind(sqlx.DOLLAR, namedQuery), args...); err != nil {
+		return nil, fmt.Errorf("sqlx_suggestion_store: failed to record suggestion: %w", err)
+	}
+	return &recorded, nil
+}
+
+func (s *SQLXSuggestionStore) Update(ctx context.Context, sg *learning.Suggestion) error {
+	query := fmt.Sprintf(`UPDATE %s SET status = :status, rule_id = :rule_id, updated_at = :updated_at WHERE id = :id`, TableRuleSuggestions)
+	if _, err := sqlx.NamedExecContext(ctx, s.db.GetExecutor(ctx), query, sg); err != nil {
+		return fmt.Errorf("sqlx_suggestion_store: failed to update suggestion: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLXSuggestionStore) FetchByID(ctx context.Context, id uuid.UUID) (*learning.Suggestion, error) {
+	var sg learning.Suggestion
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE id = $1`, suggestionColumns, TableRuleSuggestions)
+	if err := sqlx.GetContext(ctx, s.db.GetExecutor(ctx), &sg, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, learning.ErrSuggestionNotFound
+		}
+		return nil, fmt.Errorf("sqlx_suggestion_store: failed to fetch suggestion: %w", err)
+	}
+	return &sg, nil
+}
+
+// List returns suggestions with the given status, most frequent first. An
+// empty status returns all suggestions.
+func (s *SQLXSuggestionStore) List(ctx context.Context, status learning.SuggestionStatus) ([]*learning.Suggestion, error) {
+	suggestions := []*learning.Suggestion{}
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE $1 = '' OR status = $1 ORDER BY count DESC, updated_at DESC`, suggestionColumns, TableRuleSuggestions)
+	if err := sqlx.SelectContext(ctx, s.db.GetExecutor(ctx), &suggestions, query, status); err != nil {
+		return nil, fmt.Errorf("sqlx_suggestion_store: failed to list suggestions: %w", err)
+	}
+	return suggestions, nil
+}
@@ -5,9 +5,13 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lennardclaproth/my-finances-tracker/internal/category"
+	"github.com/lennardclaproth/my-finances-tracker/internal/tagging"
 	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
 	"github.com/lib/pq"
 )
@@ -20,6 +24,10 @@ func NewSQLXTransactionStore(db *DB) *SQLXTransactionStore {
 	return &SQLXTransactionStore{db: db}
 }
 
+func (s *SQLXTransactionStore) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return s.db.WithTx(ctx, fn)
+}
+
 func parseRows(rows *sqlx.Rows) ([]*transaction.Transaction, error) {
 	var transactions []*transaction.Transaction
 	for rows.Next() {
@@ -35,13 +43,15 @@ func parseRows(rows *sqlx.Rows) ([]*transaction.Transaction, error) {
 func (s *SQLXTransactionStore) Create(ctx context.Context, tx *transaction.Transaction) error {
 	query := fmt.Sprintf(`
         INSERT INTO %s (
-            id, description, note, source, amount_cents,
+            id, description, note, source, amount_cents, currency,
             direction, date, checksum, created_at, updated_at, tag,
-			row_number, ignored, import_id
+			row_number, ignored, import_id, counterparty_id, counterparty_iban,
+			account, balance_after_cents, parent_id, tag_source, tag_confidence, tagged_by, tagged_at
         ) VALUES (
-            :id, :description, :note, :source, :amount_cents,
+            :id, :description, :note, :source, :amount_cents, :currency,
             :direction, :date, :checksum, :created_at, :updated_at, :tag,
-			:row_number, :ignored, :import_id
+			:row_number, :ignored, :import_id, :counterparty_id, :counterparty_iban,
+			:account, :balance_after_cents, :parent_id, :tag_source, :tag_confidence, :tagged_by, :tagged_at
         )
     `, TableTransactions)
 	executor := s.db.GetExecutor(ctx)
@@ -88,12 +98,398 @@ func (s *SQLXTransactionStore) FetchUntagged(ctx context.Context, page, pageSize
 	return transactions, nil
 }
 
-func (s *SQLXTransactionStore) Tag(ctx context.Context, id uuid.UUID, tag string) error {
-	query := fmt.Sprintf(`UPDATE %s SET tag = $1, updated_at = NOW() WHERE id = $2`, TableTransactions)
+// ClaimUntagged reserves up to n untagged transactions for the duration of
+// the lease and returns them. Rows locked or claimed by another worker are
+// skipped, claims that expired are taken over. Transactions a machine tagger
+// left uncategorised are tagged again once retryAfter has passed, a retryAfter
+// of zero disables this.
+func (s *SQLXTransactionStore) ClaimUntagged(ctx context.Context, n int, lease, retryAfter time.Duration) ([]*transaction.Transaction, error) {
+	query := fmt.Sprintf(`
+		WITH claimable AS (
+			SELECT t.id FROM %[1]s t
+			LEFT JOIN %[2]s c ON c.transaction_id = t.id
+			WHERE (
+				t.tag IS NULL OR t.tag = ''
+				OR ($3::float8 > 0 AND t.tag = $4 AND t.tag_source IN ($5, $6) AND t.tagged_at < NOW() - make_interval(secs => $3::float8))
+			) AND (c.claimed_until IS NULL OR c.claimed_until < NOW())
+			ORDER BY t.date DESC
+			LIMIT $1
+			FOR UPDATE OF t SKIP LOCKED
+		), claimed AS (
+			INSERT INTO %[2]s (transaction_id, claimed_until)
+			SELECT id, NOW() + make_interval(secs => $2) FROM claimable
+			ON CONFLICT (transaction_id) DO UPDATE SET claimed_until = EXCLUDED.claimed_until
+			RETURNING transaction_id
+		)
+		SELECT t.* FROM %[1]s t JOIN claimed c ON c.transaction_id = t.id ORDER BY t.date DESC
+	`, TableTransactions, TableTaggingClaims)
+	rows, err := s.db.GetExecutor(ctx).QueryxContext(ctx, query, n, lease.Seconds(), retryAfter.Seconds(),
+		category.Uncategorised, transaction.TagSourceClassifier, transaction.TagSourceAgent)
+	if err != nil {
+		return nil, fmt.Errorf("sqlx_transaction_store: failed to claim untagged transactions: %w", err)
+	}
+	defer rows.Close()
+	transactions, err := parseRows(rows)
+	if err != nil {
+		return nil, fmt.Errorf("sqlx_transaction_store: failed to parse transaction rows: %w", err)
+	}
+	return transactions, nil
+}
+
+// ReleaseClaims removes the tagging claims on the transactions.
+func (s *SQLXTransactionStore) ReleaseClaims(ctx context.Context, ids []uuid.UUID) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE transaction_id = ANY($1)`, TableTaggingClaims)
+	if _, err := s.db.GetExecutor(ctx).ExecContext(ctx, query, pq.Array(ids)); err != nil {
+		return fmt.Errorf("sqlx_transaction_store: failed to release tagging claims: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLXTransactionStore) Tag(ctx context.Context, id uuid.UUID, tag string, p transaction.TagProvenance) error {
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET tag = $1, tag_source = $2, tag_confidence = $3, tagged_by = $4, tagged_at = $5, updated_at = NOW()
+		WHERE id = $6
+	`, TableTransactions)
 	executor := s.db.GetExecutor(ctx)
-	_, err := executor.ExecContext(ctx, query, tag, id)
+	_, err := executor.ExecContext(ctx, query, tag, p.TagSource, p.TagConfidence, p.TaggedBy, p.TaggedAt, id)
 	if err != nil {
 		return fmt.Errorf("sqlx_transaction_store: failed to tag transaction: %w", err)
 	}
 	return nil
 }
+
+func (s *SQLXTransactionStore) FetchIncomingByImport(ctx context.Context, importID uuid.UUID) ([]*transaction.Transaction, error) {
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE import_id = $1 AND direction = $2 ORDER BY date ASC`, TableTransactions)
+	executor := s.db.GetExecutor(ctx)
+	rows, err := executor.QueryxContext(ctx, query, importID, transaction.CashIn)
+	if err != nil {
+		return nil, fmt.Errorf("sqlx_transaction_store: failed to fetch incoming transactions: %w", err)
+	}
+	defer rows.Close()
+	return parseRows(rows)
+}
+
+func (s *SQLXTransactionStore) FetchByImport(ctx context.Context, importID uuid.UUID) ([]*transaction.Transaction, error) {
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE import_id = $1 ORDER BY row_number ASC`, TableTransactions)
+	executor := s.db.GetExecutor(ctx)
+	rows, err := executor.QueryxContext(ctx, query, importID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlx_transaction_store: failed to fetch transactions of import: %w", err)
+	}
+	defer rows.Close()
+	return parseRows(rows)
+}
+
+func (s *SQLXTransactionStore) FetchByIDs(ctx context.Context, ids []uuid.UUID) ([]*transaction.Transaction, error) {
+	if len(ids) == 0 {
+		return []*transaction.Transaction{}, nil
+	}
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE id = ANY($1)`, TableTransactions)
+	executor := s.db.GetExecutor(ctx)
+	rows, err := executor.QueryxContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("sqlx_transaction_store: failed to fetch transactions by id: %w", err)
+	}
+	defer rows.Close()
+	return parseRows(rows)
+}
+
+func (s *SQLXTransactionStore) FetchByCounterparty(ctx context.Context, id uuid.UUID) ([]*transaction.Transaction, error) {
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE counterparty_id = $1 ORDER BY date DESC`, TableTransactions)
+	executor := s.db.GetExecutor(ctx)
+	rows, err := executor.QueryxContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("sqlx_transaction_store: failed to fetch transactions by counterparty: %w", err)
+	}
+	defer rows.Close()
+	return parseRows(rows)
+}
+
+func (s *SQLXTransactionStore) Reassign(ctx context.Context, ids []uuid.UUID, to uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	query := fmt.Sprintf(`UPDATE %s SET counterparty_id = $1, updated_at = NOW() WHERE id = ANY($2)`, TableTransactions)
+	executor := s.db.GetExecutor(ctx)
+	if _, err := executor.ExecContext(ctx, query, to, pq.Array(ids)); err != nil {
+		return fmt.Errorf("sqlx_transaction_store: failed to reassign transactions: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLXTransactionStore) ReassignCounterparty(ctx context.Context, from, to uuid.UUID) error {
+	query := fmt.Sprintf(`UPDATE %s SET counterparty_id = $1, updated_at = NOW() WHERE counterparty_id = $2`, TableTransactions)
+	executor := s.db.GetExecutor(ctx)
+	if _, err := executor.ExecContext(ctx, query, to, from); err != nil {
+		return fmt.Errorf("sqlx_transaction_store: failed to reassign counterparty transactions: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLXTransactionStore) FetchAll(ctx context.Context) ([]*transaction.Transaction, error) {
+	query := fmt.Sprintf(`SELECT * FROM %s ORDER BY date DESC`, TableTransactions)
+	executor := s.db.GetExecutor(ctx)
+	rows, err := executor.QueryxContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("sqlx_transaction_store: failed to fetch transactions: %w", err)
+	}
+	defer rows.Close()
+	return parseRows(rows)
+}
+
+func (s *SQLXTransactionStore) UpdateClassification(ctx context.Context, tx *transaction.Transaction) error {
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET tag = :tag, tag_source = :tag_source, tag_confidence = :tag_confidence, tagged_by = :tagged_by, tagged_at = :tagged_at,
+			ignored = :ignored, note = :note, updated_at = NOW()
+		WHERE id = :id
+	`, TableTransactions)
+	if _, err := sqlx.NamedExecContext(ctx, s.db.GetExecutor(ctx), query, tx); err != nil {
+		return fmt.Errorf("sqlx_transaction_store: failed to update transaction classification: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLXTransactionStore) FetchByID(ctx context.Context, id uuid.UUID) (*transaction.Transaction, error) {
+	var tx transaction.Transaction
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE id = $1`, TableTransactions)
+	if err := sqlx.GetContext(ctx, s.db.GetExecutor(ctx), &tx, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, transaction.ErrNoTransactionFound
+		}
+		return nil, fmt.Errorf("sqlx_transaction_store: failed to fetch transaction: %w", err)
+	}
+	return &tx, nil
+}
+
+// FetchTagged returns the most recent categorised transactions tagged by hand
+// or by a rule, uncategorised is what the tagger falls back to and not
+// something to learn from. Machine tags are left out so the classifier does
+// not learn from its own predictions or from the guesses of the agent.
+func (s *SQLXTransactionStore) FetchTagged(ctx context.Context, limit int) ([]*transaction.Transaction, error) {
+	query := fmt.Sprintf(`
+		SELECT * FROM %s
+		WHERE tag IS NOT NULL AND tag NOT IN ('', $2) AND tag_source IN ($3, $4)
+		ORDER BY date DESC LIMIT $1
+	`, TableTransactions)
+	executor := s.db.GetExecutor(ctx)
+	rows, err := executor.QueryxContext(ctx, query, limit, category.Uncategorised, transaction.TagSourceManual, transaction.TagSourceRule)
+	if err != nil {
+		return nil, fmt.Errorf("sqlx_transaction_store: failed to fetch tagged transactions: %w", err)
+	}
+	defer rows.Close()
+	return parseRows(rows)
+}
+
+// FetchSince returns the transactions from the date on that count for
+// reports, oldest first. Ignored transactions and transfers between our own
+// accounts are left out.
+func (s *SQLXTransactionStore) FetchSince(ctx context.Context, from time.Time) ([]*transaction.Transaction, error) {
+	query := fmt.Sprintf(`
+		SELECT t.* FROM %s t
+		JOIN %s r ON r.id = t.id
+		WHERE NOT r.ignored AND NOT r.transfer AND t.date >= $1
+		ORDER BY t.date ASC, t.row_number ASC
+	`, TableTransactions, ViewReportTransactions)
+	rows, err := s.db.GetExecutor(ctx).QueryxContext(ctx, query, from.Format(time.DateOnly))
+	if err != nil {
+		return nil, fmt.Errorf("sqlx_transaction_store: failed to fetch transactions: %w", err)
+	}
+	defer rows.Close()
+	return parseRows(rows)
+}
+
+// FetchByAccount returns all transactions booked on the account, oldest
+// first.
+func (s *SQLXTransactionStore) FetchByAccount(ctx context.Context, account string) ([]*transaction.Transaction, error) {
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE account = $1 ORDER BY date ASC, row_number ASC`, TableTransactions)
+	rows, err := s.db.GetExecutor(ctx).QueryxContext(ctx, query, account)
+	if err != nil {
+		return nil, fmt.Errorf("sqlx_transaction_store: failed to fetch transactions by account: %w", err)
+	}
+	defer rows.Close()
+	return parseRows(rows)
+}
+
+// LastUpdatedAt returns when a transaction was last created or changed, the
+// zero time when there are none.
+func (s *SQLXTransactionStore) LastUpdatedAt(ctx context.Context) (time.Time, error) {
+	var last sql.NullTime
+	query := fmt.Sprintf(`SELECT MAX(updated_at) FROM %s`, TableTransactions)
+	if err := sqlx.GetContext(ctx, s.db.GetExecutor(ctx), &last, query); err != nil {
+		return time.Time{}, fmt.Errorf("sqlx_transaction_store: failed to fetch last update: %w", err)
+	}
+	return last.Time, nil
+}
+
+// CounterpartyTags counts the tags of the counterparty's transactions, most
+// used first. Untagged and uncategorised transactions are not counted.
+func (s *SQLXTransactionStore) CounterpartyTags(ctx context.Context, id uuid.UUID, limit int) ([]tagging.TagCount, error) {
+	var counts []tagging.TagCount
+	query := fmt.Sprintf(`
+		SELECT tag, COUNT(*) AS count FROM %s
+		WHERE counterparty_id = $1 AND tag NOT IN ('', $2)
+		GROUP BY tag
+		ORDER BY count DESC, tag ASC
+		LIMIT $3
+	`, TableTransactions)
+	if err := sqlx.SelectContext(ctx, s.db.GetExecutor(ctx), &counts, query, id, category.Uncategorised, limit); err != nil {
+		return nil, fmt.Errorf("sqlx_transaction_store: failed to count counterparty tags: %w", err)
+	}
+	return counts, nil
+}
+
+// SimilarTagged returns tagged transactions of the counterparty of tx or with
+// name in their description, transactions of the same counterparty and newer
+// ones first. Machine tags are left out so guesses are not used as examples.
+func (s *SQLXTransactionStore) SimilarTagged(ctx context.Context, tx *transaction.Transaction, name string, limit int) ([]*transaction.Transaction, error) {
+	if tx.CounterpartyID == nil && name == "" {
+		return []*transaction.Transaction{}, nil
+	}
+	pattern := ""
+	if name != "" {
+		pattern = "%" + likeEscaper.Replace(strings.ToUpper(name)) + "%"
+	}
+	query := fmt.Sprintf(`
+		SELECT * FROM %s t
+		WHERE t.id <> $1 AND t.tag NOT IN ('', $2) AND t.tag_source NOT IN ($3, $4)
+			AND (t.counterparty_id = $5 OR ($6 <> '' AND upper(t.description) LIKE $6))
+		ORDER BY (t.counterparty_id IS NOT DISTINCT FROM $5) DESC, t.date DESC
+		LIMIT $7
+	`, TableTransactions)
+	rows, err := s.db.GetExecutor(ctx).QueryxContext(ctx, query, tx.ID, category.Uncategorised,
+		transaction.TagSourceClassifier, transaction.TagSourceAgent, tx.CounterpartyID, pattern, limit)
+	if err != nil {
+		return nil, fmt.Errorf("sqlx_transaction_store: failed to fetch similar transactions: %w", err)
+	}
+	defer rows.Close()
+	return parseRows(rows)
+}
+
+// likeEscaper escapes the LIKE wildcards in user provided text.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Query returns a page of transactions matching the filter, newest first,
+// together with the total number of matches.
+func (s *SQLXTransactionStore) Query(ctx context.Context, f transaction.Filter) ([]*transaction.Transaction, int, error) {
+	conditions, args := filterConditions(f)
+	executor := s.db.GetExecutor(ctx)
+
+	var total int
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM %s t WHERE %s`, TableTransactions, conditions)
+	if err := sqlx.GetContext(ctx, executor, &total, countQuery, args...); err != nil {
+		return nil, 0, fmt.Errorf("sqlx_transaction_store: failed to count transactions: %w", err)
+	}
+
+	limit, offset := f.Limit()
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(`SELECT t.* FROM %s t WHERE %s ORDER BY t.date DESC, t.row_number ASC LIMIT $%d OFFSET $%d`,
+		TableTransactions, conditions, len(args)-1, len(args))
+	rows, err := executor.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("sqlx_transaction_store: failed to query transactions: %w", err)
+	}
+	defer rows.Close()
+	txs, err := parseRows(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return txs, total, nil
+}
+
+// Select returns all transactions matching the filter, the paging fields of
+// the filter are ignored.
+func (s *SQLXTransactionStore) Select(ctx context.Context, f transaction.Filter) ([]*transaction.Transaction, error) {
+	conditions, args := filterConditions(f)
+	query := fmt.Sprintf(`SELECT t.* FROM %s t WHERE %s ORDER BY t.date DESC`, TableTransactions, conditions)
+	rows, err := s.db.GetExecutor(ctx).QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("sqlx_transaction_store: failed to select transactions: %w", err)
+	}
+	defer rows.Close()
+	return parseRows(rows)
+}
+
+// filterConditions builds the WHERE clause of a filter on the transactions
+// table aliased as t.
+func filterConditions(f transaction.Filter) (string, []any) {
+	where := []string{"TRUE"}
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if !f.From.IsZero() {
+		where = append(where, "t.date >= "+arg(f.From))
+	}
+	if !f.To.IsZero() {
+		where = append(where, "t.date <= "+arg(f.To))
+	}
+	if f.Tag != "" {
+		where = append(where, "t.tag = "+arg(f.Tag))
+	}
+	if f.Direction != "" {
+		where = append(where, "t.direction = "+arg(f.Direction))
+	}
+	if f.CounterpartyID != nil {
+		where = append(where, "t.counterparty_id = "+arg(*f.CounterpartyID))
+	}
+	if len(f.Labels) > 0 {
+		where = append(where, fmt.Sprintf(`(
+			SELECT COUNT(*) FROM %s tl JOIN %s l ON l.id = tl.label_id
+			WHERE tl.transaction_id = t.id AND l.name = ANY(%s)
+		) = %s`, TableTransactionLabels, TableLabels, arg(pq.Array(f.Labels)), arg(len(f.Labels))))
+	}
+	if f.ReviewBelow > 0 {
+		where = append(where, fmt.Sprintf("t.tag_source IN (%s, %s) AND (t.tag_confidence < %s OR t.tag = %s)",
+			arg(transaction.TagSourceClassifier), arg(transaction.TagSourceAgent), arg(f.ReviewBelow), arg(category.Uncategorised)))
+	}
+	return strings.Join(where, " AND "), args
+}
+
+func (s *SQLXTransactionStore) SetTag(ctx context.Context, ids []uuid.UUID, tag string, p transaction.TagProvenance) error {
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET tag = $1, tag_source = $2, tag_confidence = $3, tagged_by = $4, tagged_at = $5, updated_at = NOW()
+		WHERE id = ANY($6)
+	`, TableTransactions)
+	if _, err := s.db.GetExecutor(ctx).ExecContext(ctx, query, tag, p.TagSource, p.TagConfidence, p.TaggedBy, p.TaggedAt, pq.Array(ids)); err != nil {
+		return fmt.Errorf("sqlx_transaction_store: failed to tag transactions: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLXTransactionStore) SetIgnored(ctx context.Context, ids []uuid.UUID, ignored bool) error {
+	query := fmt.Sprintf(`UPDATE %s SET ignored = $1, updated_at = NOW() WHERE id = ANY($2)`, TableTransactions)
+	if _, err := s.db.GetExecutor(ctx).ExecContext(ctx, query, ignored, pq.Array(ids)); err != nil {
+		return fmt.Errorf("sqlx_transaction_store: failed to update ignored transactions: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLXTransactionStore) Delete(ctx context.Context, ids []uuid.UUID) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = ANY($1)`, TableTransactions)
+	if _, err := s.db.GetExecutor(ctx).ExecContext(ctx, query, pq.Array(ids)); err != nil {
+		return fmt.Errorf("sqlx_transaction_store: failed to delete transactions: %w", err)
+	}
+	return nil
+}
+
+// Exists reports whether all transactions with the given ids exist.
+func (s *SQLXTransactionStore) Exists(ctx context.Context, ids []uuid.UUID) (bool, error) {
+	var n int
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE id = ANY($1)`, TableTransactions)
+	if err := sqlx.GetContext(ctx, s.db.GetExecutor(ctx), &n, query, pq.Array(ids)); err != nil {
+		return false, fmt.Errorf("sqlx_transaction_store: failed to check transactions: %w", err)
+	}
+	return n == len(uniqueIDs(ids)), nil
+}
+
+func uniqueIDs(ids []uuid.UUID) map[uuid.UUID]bool {
+	set := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
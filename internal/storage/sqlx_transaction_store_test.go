@@ -0,0 +1,56 @@
+package storage_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/storage"
+)
+
+func TestFetchTaggedSources(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	var importID uuid.UUID
+	err := db.QueryRowxContext(ctx, `
+		WITH v AS (INSERT INTO vendors (name) VALUES ('test') RETURNING id)
+		INSERT INTO imports (vendor_id, path) SELECT id, 'test.csv' FROM v RETURNING id
+	`).Scan(&importID)
+	if err != nil {
+		t.Fatalf("failed to seed import: %v", err)
+	}
+	tags := []struct {
+		description, tag, source string
+	}{
+		{"manual", "groceries", "manual"},
+		{"rule", "transport", "rule"},
+		{"classifier", "groceries", "classifier"},
+		{"agent", "rent", "agent"},
+		{"manual uncategorised", "uncategorised", "manual"},
+		{"untagged", "", ""},
+	}
+	for i, tt := range tags {
+		_, err := db.ExecContext(ctx, `
+			INSERT INTO transactions (description, note, source, amount_cents, direction, date, checksum, tag, tag_source, row_number, import_id)
+			VALUES ($1, '', 'test', 100, 'out', '2024-01-05', $2, NULLIF($3, ''), $4, $5, $6)
+		`, tt.description, fmt.Sprintf("checksum-%d", i), tt.tag, tt.source, i+1, importID)
+		if err != nil {
+			t.Fatalf("failed to seed transaction %s: %v", tt.description, err)
+		}
+	}
+
+	txs, err := storage.NewSQLXTransactionStore(db).FetchTagged(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, tx := range txs {
+		got = append(got, tx.Description)
+	}
+	slices.Sort(got)
+	if want := []string{"manual", "rule"}; !slices.Equal(got, want) {
+		t.Errorf("FetchTagged() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,144 @@
+package tagging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/agent"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// AgentRoute sends the transactions it matches to a specific agent, zero
+// fields match any transaction.
+type AgentRoute struct {
+	AgentID        uuid.UUID
+	Account        string
+	MinAmountCents *int64
+	MaxAmountCents *int64
+}
+
+func (r AgentRoute) Matches(tx *transaction.Transaction) bool {
+	if r.Account != "" && r.Account != tx.Account {
+		return false
+	}
+	if r.MinAmountCents != nil && tx.AmountCents < *r.MinAmountCents {
+		return false
+	}
+	if r.MaxAmountCents != nil && tx.AmountCents > *r.MaxAmountCents {
+		return false
+	}
+	return true
+}
+
+// AgentTagger asks the agent service for a tag. The agent answers with the
+// result instead of saving the tag through a tool call, the caller writes it.
+// Transactions go to the agent of the first matching route, or the default
+// agent when no route matches.
+type AgentTagger struct {
+	c            *agent.Client
+	p            *Prompter
+	defaultAgent uuid.UUID
+	routes       []AgentRoute
+}
+
+func NewAgentTagger(c *agent.Client, p *Prompter, defaultAgent uuid.UUID, routes ...AgentRoute) *AgentTagger {
+	return &AgentTagger{c: c, p: p, defaultAgent: defaultAgent, routes: routes}
+}
+
+func (t *AgentTagger) Name() string {
+	return "agent"
+}
+
+func (t *AgentTagger) Tag(ctx context.Context, tx *transaction.Transaction, categories []string) (Result, error) {
+	return single(t.TagBatch(ctx, []*transaction.Transaction{tx}, categories))
+}
+
+// TagBatch makes one call per agent the transactions are routed to. An error
+// is only returned when all calls failed, otherwise the transactions of a
+// failed call get its error in their Result.
+func (t *AgentTagger) TagBatch(ctx context.Context, txs []*transaction.Transaction, categories []string) ([]Result, error) {
+	var agents []uuid.UUID
+	groups := map[uuid.UUID][]int{}
+	for i, tx := range txs {
+		id := t.route(tx)
+		if _, ok := groups[id]; !ok {
+			agents = append(agents, id)
+		}
+		groups[id] = append(groups[id], i)
+	}
+	results := make([]Result, len(txs))
+	var errs []error
+	for _, id := range agents {
+		batch := make([]*transaction.Transaction, 0, len(groups[id]))
+		for _, i := range groups[id] {
+			batch = append(batch, txs[i])
+		}
+		batchResults, err := t.call(ctx, id, batch, categories)
+		if err != nil {
+			errs = append(errs, err)
+			for _, i := range groups[id] {
+				results[i].Err = err
+			}
+			continue
+		}
+		for j, i := range groups[id] {
+			results[i] = batchResults[j]
+		}
+	}
+	if len(errs) == len(agents) && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return results, nil
+}
+
+func (t *AgentTagger) route(tx *transaction.Transaction) uuid.UUID {
+	for _, r := range t.routes {
+		if r.Matches(tx) {
+			return r.AgentID
+		}
+	}
+	return t.defaultAgent
+}
+
+func (t *AgentTagger) call(ctx context.Context, agentID uuid.UUID, txs []*transaction.Transaction, categories []string) ([]Result, error) {
+	system, user, err := t.p.Render(ctx, txs, categories)
+	if err != nil {
+		return nil, err
+	}
+	answer, err := t.c.CallAgent(ctx, agentID, system+"\n\n"+user)
+	if err != nil {
+		return nil, err
+	}
+	results, err := parseBatchResult(unwrapAnswer(answer), len(txs), categories)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Source = transaction.TagSourceAgent
+		results[i].Model = agentID.String()
+	}
+	return results, nil
+}
+
+// unwrapAnswer returns the text of answers the agent service wraps in a JSON
+// envelope like {"output": "..."}.
+func unwrapAnswer(answer string) string {
+	var envelope map[string]any
+	if err := json.Unmarshal([]byte(answer), &envelope); err != nil {
+		return answer
+	}
+	if _, ok := envelope["tag"]; ok {
+		return answer
+	}
+	if _, ok := envelope["results"]; ok {
+		return answer
+	}
+	for _, key := range []string{"output", "response", "message", "content", "result"} {
+		if s, ok := envelope[key].(string); ok {
+			return s
+		}
+	}
+	return answer
+}
@@ -0,0 +1,43 @@
+package tagging
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// BatchTagger is implemented by taggers that tag several transactions with a
+// single request. The results are in the order of txs, a transaction the
+// tagger has no opinion on gets an empty Result and one it failed on a Result
+// with only Err set.
+type BatchTagger interface {
+	TagBatch(ctx context.Context, txs []*transaction.Transaction, categories []string) ([]Result, error)
+}
+
+// TagAll tags the transactions with a single request when the tagger supports
+// batches and one by one otherwise. An error is only returned when no
+// transaction could be tagged because the tagger failed.
+func TagAll(ctx context.Context, t Tagger, txs []*transaction.Transaction, categories []string) ([]Result, error) {
+	if bt, ok := t.(BatchTagger); ok {
+		return bt.TagBatch(ctx, txs, categories)
+	}
+	results := make([]Result, len(txs))
+	var errs []error
+	for i, tx := range txs {
+		res, err := t.Tag(ctx, tx, categories)
+		if errors.Is(err, ErrNoTag) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			results[i].Err = err
+			continue
+		}
+		results[i] = res
+	}
+	if len(txs) > 0 && len(errs) == len(txs) {
+		return nil, errors.Join(errs...)
+	}
+	return results, nil
+}
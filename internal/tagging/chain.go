@@ -0,0 +1,151 @@
+package tagging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/logging"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// Chain tries its taggers in order and returns the first result that is
+// confident enough. Failing taggers are logged and skipped so an unavailable
+// backend does not block the ones after it. When no tagger is confident the
+// most confident guess is returned, its low confidence puts it in the review
+// queue. A transaction is only left without a tag or with a guess when every
+// tagger answered, when one failed it may have known better and the
+// transaction is failed instead so it is tagged again later.
+type Chain struct {
+	taggers       []Tagger
+	minConfidence float64
+	log           logging.Logger
+}
+
+func NewChain(log logging.Logger, minConfidence float64, taggers ...Tagger) *Chain {
+	return &Chain{taggers: taggers, minConfidence: minConfidence, log: log}
+}
+
+func (c *Chain) Name() string {
+	return "chain"
+}
+
+// Tag returns ErrNoTag when no tagger produced a result and the joined errors
+// when no tagger was confident and one of them failed.
+func (c *Chain) Tag(ctx context.Context, tx *transaction.Transaction, categories []string) (Result, error) {
+	if len(c.taggers) == 0 {
+		return Result{}, ErrNoTaggers
+	}
+	var best Result
+	var errs []error
+	for _, t := range c.taggers {
+		res, err := t.Tag(ctx, tx, categories)
+		if errors.Is(err, ErrNoTag) {
+			continue
+		}
+		if err != nil {
+			c.log.Error(ctx, "tagger failed", err, "tagger", t.Name(), "transaction", tx.ID)
+			errs = append(errs, err)
+			continue
+		}
+		res.Tagger = t.Name()
+		if c.accept(ctx, res, categories, &best) {
+			return res, nil
+		}
+	}
+	if len(errs) == len(c.taggers) {
+		return Result{}, fmt.Errorf("tagging: all taggers failed: %w", errors.Join(errs...))
+	}
+	if len(errs) > 0 {
+		return Result{}, fmt.Errorf("tagging: no confident tag while %d of %d taggers failed: %w", len(errs), len(c.taggers), errors.Join(errs...))
+	}
+	if best.Tag == "" {
+		return Result{}, ErrNoTag
+	}
+	return best, nil
+}
+
+// TagBatch tags the transactions with one request per tagger, transactions a
+// tagger did not confidently tag are passed on to the next one. Transactions
+// no tagger had a guess for get an empty Result, transactions without a
+// confident result that a tagger failed on get the errors in Err. An error is
+// returned when all taggers failed.
+func (c *Chain) TagBatch(ctx context.Context, txs []*transaction.Transaction, categories []string) ([]Result, error) {
+	if len(c.taggers) == 0 {
+		return nil, ErrNoTaggers
+	}
+	results := make([]Result, len(txs))
+	pending := make([]int, len(txs))
+	for i := range txs {
+		pending[i] = i
+	}
+	var errs []error
+	failures := make([][]error, len(txs))
+	for _, t := range c.taggers {
+		if len(pending) == 0 {
+			break
+		}
+		batch := make([]*transaction.Transaction, 0, len(pending))
+		for _, i := range pending {
+			batch = append(batch, txs[i])
+		}
+		batchResults, err := TagAll(ctx, t, batch, categories)
+		if err != nil {
+			c.log.Error(ctx, "tagger failed", err, "tagger", t.Name(), "transactions", len(batch))
+			errs = append(errs, err)
+			for _, i := range pending {
+				failures[i] = append(failures[i], err)
+			}
+			continue
+		}
+		var rest []int
+		for j, i := range pending {
+			res := batchResults[j]
+			if res.Err != nil {
+				c.log.Error(ctx, "tagger failed", res.Err, "tagger", t.Name(), "transaction", txs[i].ID)
+				failures[i] = append(failures[i], res.Err)
+				rest = append(rest, i)
+				continue
+			}
+			if res.Tag == "" {
+				rest = append(rest, i)
+				continue
+			}
+			res.Tagger = t.Name()
+			// results[i] holds the best guess until a confident result replaces it
+			if !c.accept(ctx, res, categories, &results[i]) {
+				rest = append(rest, i)
+				continue
+			}
+			results[i] = res
+		}
+		pending = rest
+	}
+	if len(errs) == len(c.taggers) {
+		return nil, fmt.Errorf("tagging: all taggers failed: %w", errors.Join(errs...))
+	}
+	for _, i := range pending {
+		if len(failures[i]) > 0 {
+			results[i] = Result{Err: errors.Join(failures[i]...)}
+		}
+	}
+	return results, nil
+}
+
+// accept reports whether res is confident enough, results that are not are
+// kept in best when they are more confident than it.
+func (c *Chain) accept(ctx context.Context, res Result, categories []string, best *Result) bool {
+	if !slices.Contains(categories, res.Tag) {
+		c.log.Error(ctx, "tagger returned an unknown category", ErrUnknownCategory, "tagger", res.Tagger, "tag", res.Tag)
+		return false
+	}
+	if res.Confidence < c.minConfidence {
+		c.log.Info(ctx, "tagger not confident enough", "tagger", res.Tagger, "tag", res.Tag, "confidence", res.Confidence)
+		if best.Tag == "" || res.Confidence > best.Confidence {
+			*best = res
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,233 @@
+package tagging
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(ctx context.Context, msg string, fields ...any)             {}
+func (nopLogger) Error(ctx context.Context, msg string, err error, fields ...any) {}
+
+// fakeTagger answers per description, transactions it has no result for get
+// ErrNoTag. When err is set every call fails with it.
+type fakeTagger struct {
+	name    string
+	results map[string]Result
+	err     error
+	calls   int
+}
+
+func (f *fakeTagger) Name() string {
+	return f.name
+}
+
+func (f *fakeTagger) Tag(ctx context.Context, tx *transaction.Transaction, categories []string) (Result, error) {
+	f.calls++
+	if f.err != nil {
+		return Result{}, f.err
+	}
+	res, ok := f.results[tx.Description]
+	if !ok {
+		return Result{}, ErrNoTag
+	}
+	return res, nil
+}
+
+func TestChainTag(t *testing.T) {
+	failing := errors.New("backend down")
+	tests := []struct {
+		name    string
+		taggers []*fakeTagger
+		want    Result
+		wantErr error
+	}{
+		{
+			name: "first confident tagger wins",
+			taggers: []*fakeTagger{
+				{name: "rules", results: map[string]Result{"ALBERT HEIJN": {Tag: "groceries", Confidence: 1}}},
+				{name: "agent", results: map[string]Result{"ALBERT HEIJN": {Tag: "rent", Confidence: 1}}},
+			},
+			want: Result{Tag: "groceries", Confidence: 1, Tagger: "rules"},
+		},
+		{
+			name: "falls through when a tagger has no opinion",
+			taggers: []*fakeTagger{
+				{name: "rules"},
+				{name: "agent", results: map[string]Result{"ALBERT HEIJN": {Tag: "groceries", Confidence: 0.8}}},
+			},
+			want: Result{Tag: "groceries", Confidence: 0.8, Tagger: "agent"},
+		},
+		{
+			name: "falls through when a tagger fails",
+			taggers: []*fakeTagger{
+				{name: "agent", err: failing},
+				{name: "classifier", results: map[string]Result{"ALBERT HEIJN": {Tag: "groceries", Confidence: 0.8}}},
+			},
+			want: Result{Tag: "groceries", Confidence: 0.8, Tagger: "classifier"},
+		},
+		{
+			name: "falls through on an unknown category",
+			taggers: []*fakeTagger{
+				{name: "agent", results: map[string]Result{"ALBERT HEIJN": {Tag: "crypto", Confidence: 1}}},
+				{name: "classifier", results: map[string]Result{"ALBERT HEIJN": {Tag: "groceries", Confidence: 0.6}}},
+			},
+			want: Result{Tag: "groceries", Confidence: 0.6, Tagger: "classifier"},
+		},
+		{
+			name: "most confident guess when none is confident",
+			taggers: []*fakeTagger{
+				{name: "classifier", results: map[string]Result{"ALBERT HEIJN": {Tag: "groceries", Confidence: 0.4}}},
+				{name: "agent", results: map[string]Result{"ALBERT HEIJN": {Tag: "rent", Confidence: 0.3}}},
+			},
+			want: Result{Tag: "groceries", Confidence: 0.4, Tagger: "classifier"},
+		},
+		{
+			// the failed agent might have been confident
+			name: "no guess when a tagger fails",
+			taggers: []*fakeTagger{
+				{name: "classifier", results: map[string]Result{"ALBERT HEIJN": {Tag: "groceries", Confidence: 0.4}}},
+				{name: "agent", err: failing},
+			},
+			wantErr: failing,
+		},
+		{
+			name:    "no opinion is not an answer when a tagger fails",
+			taggers: []*fakeTagger{{name: "rules"}, {name: "agent", err: failing}},
+			wantErr: failing,
+		},
+		{
+			name:    "no tagger has an opinion",
+			taggers: []*fakeTagger{{name: "rules"}, {name: "classifier"}},
+			wantErr: ErrNoTag,
+		},
+		{
+			name:    "all taggers fail",
+			taggers: []*fakeTagger{{name: "agent", err: failing}, {name: "openai", err: failing}},
+			wantErr: failing,
+		},
+		{
+			name:    "no taggers",
+			wantErr: ErrNoTaggers,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var taggers []Tagger
+			for _, f := range tt.taggers {
+				taggers = append(taggers, f)
+			}
+			got, err := NewChain(nopLogger{}, 0.5, taggers...).Tag(context.Background(), testTransaction("ALBERT HEIJN", 1229), testCategories)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Tag() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Tag() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Tag() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChainFallsThroughOpenAI(t *testing.T) {
+	srv := chatServer(t, http.StatusServiceUnavailable, "overloaded", "")
+	openai := NewOpenAITagger(testPrompter(t), srv.URL+"/v1", "sk-test", "test-model", time.Second)
+	classifier := &fakeTagger{name: "classifier", results: map[string]Result{"ALBERT HEIJN": {Tag: "groceries", Confidence: 0.7}}}
+
+	got, err := NewChain(nopLogger{}, 0.5, openai, classifier).Tag(context.Background(), testTransaction("ALBERT HEIJN", 1229), testCategories)
+	if err != nil {
+		t.Fatalf("Tag() error = %v", err)
+	}
+	if got.Tag != "groceries" || got.Tagger != "classifier" {
+		t.Errorf("Tag() = %+v, want groceries by the classifier", got)
+	}
+}
+
+func TestChainTagBatch(t *testing.T) {
+	srv := chatServer(t, 0, "", `{"results": [
+		{"index": 1, "tag": "groceries", "confidence": 0.9},
+		{"index": 2, "tag": "crypto", "confidence": 0.9},
+		{"index": 3, "tag": "transport", "confidence": 0.2}
+	]}`)
+	openai := NewOpenAITagger(testPrompter(t), srv.URL+"/v1", "sk-test", "test-model", time.Second)
+	classifier := &fakeTagger{name: "classifier", results: map[string]Result{
+		"BITVAVO":  {Tag: "uncategorised", Confidence: 0.6},
+		"NS GROEP": {Tag: "transport", Confidence: 0.1},
+	}}
+	txs := []*transaction.Transaction{
+		testTransaction("ALBERT HEIJN", 1229),
+		testTransaction("BITVAVO", 10000),
+		testTransaction("NS GROEP", 420),
+		testTransaction("UNKNOWN", 100),
+	}
+
+	got, err := NewChain(nopLogger{}, 0.5, openai, classifier).TagBatch(context.Background(), txs, testCategories)
+	if err != nil {
+		t.Fatalf("TagBatch() error = %v", err)
+	}
+	want := []struct {
+		tag, tagger string
+	}{
+		{"groceries", "openai"},
+		{"uncategorised", "classifier"},
+		// neither is confident, the better guess is kept for review
+		{"transport", "openai"},
+		{"", ""},
+	}
+	for i, w := range want {
+		if got[i].Tag != w.tag || got[i].Tagger != w.tagger {
+			t.Errorf("result %d = %+v, want %s by %s", i, got[i], w.tag, w.tagger)
+		}
+	}
+	// only the transactions the first tagger left are passed on
+	if classifier.calls != 3 {
+		t.Errorf("classifier called %d times, want 3", classifier.calls)
+	}
+}
+
+func TestChainTagBatchAllFail(t *testing.T) {
+	failing := errors.New("backend down")
+	txs := []*transaction.Transaction{testTransaction("ALBERT HEIJN", 1229), testTransaction("BITVAVO", 10000)}
+	chain := NewChain(nopLogger{}, 0.5, &fakeTagger{name: "agent", err: failing}, &fakeTagger{name: "classifier", err: failing})
+
+	if _, err := chain.TagBatch(context.Background(), txs, testCategories); !errors.Is(err, failing) {
+		t.Fatalf("TagBatch() error = %v, want %v", err, failing)
+	}
+}
+
+func TestChainTagBatchFailedTagger(t *testing.T) {
+	failing := errors.New("backend down")
+	txs := []*transaction.Transaction{
+		testTransaction("ALBERT HEIJN", 1229),
+		testTransaction("NS GROEP", 420),
+		testTransaction("UNKNOWN", 100),
+	}
+	rules := &fakeTagger{name: "rules", results: map[string]Result{"ALBERT HEIJN": {Tag: "groceries", Confidence: 1}}}
+	classifier := &fakeTagger{name: "classifier", results: map[string]Result{"NS GROEP": {Tag: "transport", Confidence: 0.2}}}
+	agent := &fakeTagger{name: "agent", err: failing}
+
+	got, err := NewChain(nopLogger{}, 0.5, rules, classifier, agent).TagBatch(context.Background(), txs, testCategories)
+	if err != nil {
+		t.Fatalf("TagBatch() error = %v", err)
+	}
+	if got[0].Tag != "groceries" || got[0].Err != nil {
+		t.Errorf("result 0 = %+v, want groceries by the rules", got[0])
+	}
+	// the guess and the missing answer are failures, the agent did not answer
+	for i := 1; i < len(txs); i++ {
+		if got[i].Tag != "" || !errors.Is(got[i].Err, failing) {
+			t.Errorf("result %d = %+v, want failed", i, got[i])
+		}
+	}
+}
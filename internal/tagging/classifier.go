@@ -0,0 +1,37 @@
+package tagging
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/category"
+	"github.com/lennardclaproth/my-finances-tracker/internal/classifier"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// ClassifierTagger tags with the offline naive Bayes classifier.
+type ClassifierTagger struct {
+	cs *classifier.Service
+}
+
+func NewClassifierTagger(cs *classifier.Service) *ClassifierTagger {
+	return &ClassifierTagger{cs: cs}
+}
+
+func (t *ClassifierTagger) Name() string {
+	return "classifier"
+}
+
+func (t *ClassifierTagger) Tag(ctx context.Context, tx *transaction.Transaction, categories []string) (Result, error) {
+	p, ok := t.cs.Predict(tx)
+	if !ok || p.Label == "" || p.Label == category.Uncategorised {
+		return Result{}, ErrNoTag
+	}
+	return Result{
+		Tag:        p.Label,
+		Confidence: p.Confidence,
+		Rationale:  fmt.Sprintf("similar to previously tagged transactions (p=%.2f)", p.Confidence),
+		Source:     transaction.TagSourceClassifier,
+		Model:      "naive-bayes",
+	}, nil
+}
@@ -0,0 +1,73 @@
+package tagging
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// Limited spaces the requests of a tagger so it is called at most perMinute
+// times a minute, shared by all workers using it. Batches count as a single
+// request when the tagger supports them.
+type Limited struct {
+	Tagger
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// NewLimited returns the tagger as is when perMinute is not positive.
+func NewLimited(t Tagger, perMinute int) Tagger {
+	if perMinute <= 0 {
+		return t
+	}
+	return &Limited{Tagger: t, interval: time.Minute / time.Duration(perMinute)}
+}
+
+func (l *Limited) Tag(ctx context.Context, tx *transaction.Transaction, categories []string) (Result, error) {
+	if err := l.wait(ctx); err != nil {
+		return Result{}, err
+	}
+	return l.Tagger.Tag(ctx, tx, categories)
+}
+
+func (l *Limited) TagBatch(ctx context.Context, txs []*transaction.Transaction, categories []string) ([]Result, error) {
+	bt, ok := l.Tagger.(BatchTagger)
+	if !ok {
+		// hide TagBatch so every transaction waits for its own slot
+		return TagAll(ctx, oneByOne{l}, txs, categories)
+	}
+	if err := l.wait(ctx); err != nil {
+		return nil, err
+	}
+	return bt.TagBatch(ctx, txs, categories)
+}
+
+// oneByOne only exposes the Tagger methods of the wrapped tagger.
+type oneByOne struct {
+	Tagger
+}
+
+// wait blocks until the next request slot.
+func (l *Limited) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(slot))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,133 @@
+package tagging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+	"go.elastic.co/apm/module/apmhttp/v2"
+)
+
+// OpenAITagger asks an OpenAI compatible chat completions endpoint for a tag.
+// Any server implementing POST {baseURL}/chat/completions works, including
+// local ones like Ollama or llama.cpp.
+type OpenAITagger struct {
+	http    *http.Client
+	p       *Prompter
+	baseURL string
+	apiKey  string
+	model   string
+}
+
+func NewOpenAITagger(p *Prompter, baseURL, apiKey, model string, timeout time.Duration) *OpenAITagger {
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &OpenAITagger{
+		http: &http.Client{
+			Transport: apmhttp.WrapRoundTripper(http.DefaultTransport),
+			Timeout:   timeout,
+		},
+		p:       p,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		model:   model,
+	}
+}
+
+func (t *OpenAITagger) Name() string {
+	return "openai"
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model          string            `json:"model"`
+	Messages       []chatMessage     `json:"messages"`
+	Temperature    float64           `json:"temperature"`
+	ResponseFormat map[string]string `json:"response_format,omitempty"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+func (t *OpenAITagger) Tag(ctx context.Context, tx *transaction.Transaction, categories []string) (Result, error) {
+	return single(t.TagBatch(ctx, []*transaction.Transaction{tx}, categories))
+}
+
+func (t *OpenAITagger) TagBatch(ctx context.Context, txs []*transaction.Transaction, categories []string) ([]Result, error) {
+	system, user, err := t.p.Render(ctx, txs, categories)
+	if err != nil {
+		return nil, err
+	}
+	answer, err := t.complete(ctx, system, user)
+	if err != nil {
+		return nil, err
+	}
+	results, err := parseBatchResult(answer, len(txs), categories)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Source = transaction.TagSourceAgent
+		results[i].Model = t.model
+	}
+	return results, nil
+}
+
+// complete sends the system and user message and returns the content of the
+// first choice.
+func (t *OpenAITagger) complete(ctx context.Context, system, user string) (string, error) {
+	body, err := json.Marshal(chatRequest{
+		Model: t.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: user},
+		},
+		ResponseFormat: map[string]string{"type": "json_object"},
+	})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if t.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	}
+	res, err := t.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+	if res.StatusCode >= 300 {
+		return "", fmt.Errorf("tagging: chat completion failed with status code %d, message: %s", res.StatusCode, resBody)
+	}
+	var completion chatResponse
+	if err := json.Unmarshal(resBody, &completion); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidResult, err)
+	}
+	if len(completion.Choices) == 0 {
+		return "", fmt.Errorf("%w: no choices", ErrInvalidResult)
+	}
+	return completion.Choices[0].Message.Content, nil
+}
@@ -0,0 +1,204 @@
+package tagging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/counterparty"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+var testCategories = []string{"groceries", "rent", "transport", "uncategorised"}
+
+// noHistory is a HistoryFetcher and CounterpartyFetcher without any tagged
+// transactions.
+type noHistory struct{}
+
+func (noHistory) CounterpartyTags(ctx context.Context, id uuid.UUID, limit int) ([]TagCount, error) {
+	return nil, nil
+}
+
+func (noHistory) SimilarTagged(ctx context.Context, tx *transaction.Transaction, name string, limit int) ([]*transaction.Transaction, error) {
+	return nil, nil
+}
+
+func (noHistory) FetchByID(ctx context.Context, id uuid.UUID) (*counterparty.Counterparty, error) {
+	return nil, counterparty.ErrCounterpartyNotFound
+}
+
+func testPrompter(t *testing.T) *Prompter {
+	t.Helper()
+	tmpl, err := LoadTemplate("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewPrompter(tmpl, noHistory{}, noHistory{}, 0)
+}
+
+func testTransaction(description string, cents int64) *transaction.Transaction {
+	return &transaction.Transaction{
+		ID:          uuid.New(),
+		Description: description,
+		AmountCents: cents,
+		Direction:   transaction.CashOut,
+		Date:        time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+// chatServer answers chat completions with the content, or with the status
+// and body when status is set.
+func chatServer(t *testing.T, status int, body, content string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("request %s %s, want POST /v1/chat/completions", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer sk-test" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var req chatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		if req.Model != "test-model" || len(req.Messages) != 2 || req.Messages[0].Role != "system" || req.Messages[1].Role != "user" {
+			t.Errorf("request = %+v", req)
+		}
+		if len(req.Messages) == 2 && !strings.Contains(req.Messages[1].Content, "Categories: groceries, rent") {
+			t.Errorf("user message does not list the categories: %s", req.Messages[1].Content)
+		}
+		if status != 0 {
+			w.WriteHeader(status)
+			w.Write([]byte(body))
+			return
+		}
+		var res chatResponse
+		res.Choices = append(res.Choices, struct {
+			Message chatMessage `json:"message"`
+		}{chatMessage{Role: "assistant", Content: content}})
+		json.NewEncoder(w).Encode(res)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestOpenAITaggerTag(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		content string
+		want    Result
+		wantErr error
+	}{
+		{
+			name:    "tag",
+			content: `{"results": [{"index": 1, "tag": "groceries", "confidence": 0.9, "rationale": " supermarket "}]}`,
+			want:    Result{Tag: "groceries", Confidence: 0.9, Rationale: "supermarket"},
+		},
+		{
+			name:    "single result without an index in a code fence",
+			content: "Sure!\n```json\n{\"tag\": \"Groceries\", \"confidence\": 1.5}\n```",
+			want:    Result{Tag: "groceries", Confidence: 1},
+		},
+		{
+			name:    "unknown category",
+			content: `{"results": [{"index": 1, "tag": "crypto", "confidence": 0.9}]}`,
+			wantErr: ErrNoTag,
+		},
+		{
+			name:    "unknown index",
+			content: `{"results": [{"index": 2, "tag": "groceries", "confidence": 0.9}]}`,
+			wantErr: ErrNoTag,
+		},
+		{
+			name:    "prose instead of JSON",
+			content: "This looks like groceries to me.",
+			wantErr: ErrInvalidResult,
+		},
+		{
+			name:    "malformed JSON",
+			content: `{"results": [{"index": 1, "tag": }]}`,
+			wantErr: ErrInvalidResult,
+		},
+		{
+			name:    "not a chat completion",
+			status:  http.StatusOK,
+			body:    "<html>gateway</html>",
+			wantErr: ErrInvalidResult,
+		},
+		{
+			name:    "no choices",
+			status:  http.StatusOK,
+			body:    `{"choices": []}`,
+			wantErr: ErrInvalidResult,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := chatServer(t, tt.status, tt.body, tt.content)
+			tagger := NewOpenAITagger(testPrompter(t), srv.URL+"/v1/", "sk-test", "test-model", time.Second)
+
+			got, err := tagger.Tag(context.Background(), testTransaction("ALBERT HEIJN 1234", 1229), testCategories)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Tag() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Tag() error = %v", err)
+			}
+			tt.want.Source = transaction.TagSourceAgent
+			tt.want.Model = "test-model"
+			if got != tt.want {
+				t.Errorf("Tag() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenAITaggerStatus(t *testing.T) {
+	srv := chatServer(t, http.StatusServiceUnavailable, "overloaded", "")
+	tagger := NewOpenAITagger(testPrompter(t), srv.URL+"/v1", "sk-test", "test-model", time.Second)
+
+	_, err := tagger.Tag(context.Background(), testTransaction("ALBERT HEIJN 1234", 1229), testCategories)
+	if err == nil || errors.Is(err, ErrInvalidResult) || !strings.Contains(err.Error(), "503") {
+		t.Fatalf("Tag() error = %v, want the status", err)
+	}
+}
+
+func TestOpenAITaggerTagBatch(t *testing.T) {
+	srv := chatServer(t, 0, "", `{"results": [
+		{"index": 3, "tag": "transport", "confidence": 0.7},
+		{"index": 1, "tag": "groceries", "confidence": 0.9},
+		{"index": 2, "tag": "crypto", "confidence": 0.9},
+		{"index": 9, "tag": "rent", "confidence": 0.9}
+	]}`)
+	tagger := NewOpenAITagger(testPrompter(t), srv.URL+"/v1", "sk-test", "test-model", time.Second)
+	txs := []*transaction.Transaction{
+		testTransaction("ALBERT HEIJN 1234", 1229),
+		testTransaction("BITVAVO", 10000),
+		testTransaction("NS GROEP", 420),
+	}
+
+	got, err := tagger.TagBatch(context.Background(), txs, testCategories)
+	if err != nil {
+		t.Fatalf("TagBatch() error = %v", err)
+	}
+	want := []string{"groceries", "", "transport"}
+	if len(got) != len(want) {
+		t.Fatalf("TagBatch() returned %d results, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Tag != want[i] {
+			t.Errorf("result %d = %q, want %q", i, got[i].Tag, want[i])
+		}
+	}
+}
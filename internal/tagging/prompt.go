@@ -0,0 +1,244 @@
+package tagging
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/category"
+	"github.com/lennardclaproth/my-finances-tracker/internal/counterparty"
+	"github.com/lennardclaproth/my-finances-tracker/internal/money"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+//go:embed prompt.tmpl
+var defaultPrompt string
+
+// DefaultExamples is the number of similar tagged transactions added to the
+// prompt of every transaction when none is configured.
+const DefaultExamples = 3
+
+// TagCount is how often a tag was used.
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// Single-use interfaces only used by Prompter
+
+type HistoryFetcher interface {
+	// CounterpartyTags returns the tags of the counterparty's transactions,
+	// most used first.
+	CounterpartyTags(ctx context.Context, id uuid.UUID, limit int) ([]TagCount, error)
+	// SimilarTagged returns tagged transactions of the same counterparty or
+	// with the given name in their description, newest first.
+	SimilarTagged(ctx context.Context, tx *transaction.Transaction, name string, limit int) ([]*transaction.Transaction, error)
+}
+
+type CounterpartyFetcher interface {
+	FetchByID(ctx context.Context, id uuid.UUID) (*counterparty.Counterparty, error)
+}
+
+// Prompter renders the prompt template for a batch of transactions. Every
+// transaction is described together with the tags its counterparty had so far
+// and a few similar tagged transactions as examples.
+type Prompter struct {
+	tmpl     *template.Template
+	hf       HistoryFetcher
+	cf       CounterpartyFetcher
+	examples int
+}
+
+func NewPrompter(tmpl *template.Template, hf HistoryFetcher, cf CounterpartyFetcher, examples int) *Prompter {
+	if examples <= 0 {
+		examples = DefaultExamples
+	}
+	return &Prompter{tmpl: tmpl, hf: hf, cf: cf, examples: examples}
+}
+
+// LoadTemplate parses the prompt template file at path, the built-in template
+// is used when path is empty. The template must define "system" and "user".
+func LoadTemplate(path string) (*template.Template, error) {
+	tmpl := template.New("prompt").Funcs(template.FuncMap{"join": strings.Join})
+	var err error
+	if path == "" {
+		tmpl, err = tmpl.Parse(defaultPrompt)
+	} else {
+		tmpl, err = tmpl.ParseFiles(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tagging: failed to parse prompt template: %w", err)
+	}
+	for _, name := range []string{"system", "user"} {
+		if tmpl.Lookup(name) == nil {
+			return nil, fmt.Errorf("tagging: prompt template does not define %q", name)
+		}
+	}
+	return tmpl, nil
+}
+
+type promptData struct {
+	Categories    []string
+	Uncategorised string
+	Transactions  []promptTransaction
+}
+
+type promptTransaction struct {
+	Index        int
+	Amount       string
+	Direction    string
+	Date         string
+	Description  string
+	Note         string
+	Account      string
+	Counterparty string
+	History      []TagCount
+	Examples     []promptExample
+}
+
+type promptExample struct {
+	Description string
+	Amount      string
+	Direction   string
+	Tag         string
+}
+
+// Render returns the system and user message for the transactions.
+func (p *Prompter) Render(ctx context.Context, txs []*transaction.Transaction, categories []string) (system, user string, err error) {
+	data := promptData{Categories: categories, Uncategorised: category.Uncategorised}
+	for i, tx := range txs {
+		pt, err := p.describe(ctx, tx)
+		if err != nil {
+			return "", "", err
+		}
+		pt.Index = i + 1
+		data.Transactions = append(data.Transactions, pt)
+	}
+	var b bytes.Buffer
+	if err := p.tmpl.ExecuteTemplate(&b, "system", data); err != nil {
+		return "", "", fmt.Errorf("tagging: failed to render prompt: %w", err)
+	}
+	system = strings.TrimSpace(b.String())
+	b.Reset()
+	if err := p.tmpl.ExecuteTemplate(&b, "user", data); err != nil {
+		return "", "", fmt.Errorf("tagging: failed to render prompt: %w", err)
+	}
+	return system, strings.TrimSpace(b.String()), nil
+}
+
+func (p *Prompter) describe(ctx context.Context, tx *transaction.Transaction) (promptTransaction, error) {
+	pt := promptTransaction{
+		Amount:      money.New(tx.AmountCents, tx.Currency).Decimal(),
+		Direction:   string(tx.Direction),
+		Date:        tx.Date.Format("2006-01-02"),
+		Description: tx.Description,
+		Note:        tx.Note,
+		Account:     tx.Account,
+	}
+	name := counterparty.Normalise(tx.Description)
+	if tx.CounterpartyID != nil {
+		cp, err := p.cf.FetchByID(ctx, *tx.CounterpartyID)
+		if err != nil {
+			return pt, err
+		}
+		pt.Counterparty = cp.Name
+		if pt.History, err = p.hf.CounterpartyTags(ctx, cp.ID, 5); err != nil {
+			return pt, err
+		}
+	}
+	similar, err := p.hf.SimilarTagged(ctx, tx, name, p.examples)
+	if err != nil {
+		return pt, err
+	}
+	for _, s := range similar {
+		pt.Examples = append(pt.Examples, promptExample{
+			Description: s.Description,
+			Amount:      money.New(s.AmountCents, s.Currency).Decimal(),
+			Direction:   string(s.Direction),
+			Tag:         s.Tag,
+		})
+	}
+	return pt, nil
+}
+
+// rawResult is a result as answered by a model.
+type rawResult struct {
+	Index      int     `json:"index"`
+	Tag        string  `json:"tag"`
+	Confidence float64 `json:"confidence"`
+	Rationale  string  `json:"rationale"`
+}
+
+func (r rawResult) toResult(categories []string) (Result, error) {
+	tag := strings.ToLower(strings.TrimSpace(r.Tag))
+	if tag == "" {
+		return Result{}, fmt.Errorf("%w: empty tag", ErrInvalidResult)
+	}
+	if !slices.Contains(categories, tag) {
+		return Result{}, fmt.Errorf("%w: %q", ErrUnknownCategory, tag)
+	}
+	return Result{Tag: tag, Confidence: min(max(r.Confidence, 0), 1), Rationale: strings.TrimSpace(r.Rationale)}, nil
+}
+
+// decodeObject decodes the JSON object in a model answer, models tend to
+// wrap it in prose or code fences.
+func decodeObject(answer string, v any) error {
+	start := strings.Index(answer, "{")
+	end := strings.LastIndex(answer, "}")
+	if start < 0 || end < start {
+		return fmt.Errorf("%w: no JSON object in %q", ErrInvalidResult, answer)
+	}
+	if err := json.Unmarshal([]byte(answer[start:end+1]), v); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidResult, err)
+	}
+	return nil
+}
+
+// parseBatchResult extracts the results for n transactions from a model
+// answer. Entries with an unknown index or category are dropped instead of
+// failing the whole batch. A single result without an index is accepted for
+// a batch of one.
+func parseBatchResult(answer string, n int, categories []string) ([]Result, error) {
+	var res struct {
+		rawResult
+		Results []rawResult `json:"results"`
+	}
+	if err := decodeObject(answer, &res); err != nil {
+		return nil, err
+	}
+	if len(res.Results) == 0 && n == 1 && res.Tag != "" {
+		res.Index = 1
+		res.Results = []rawResult{res.rawResult}
+	}
+	results := make([]Result, n)
+	for _, r := range res.Results {
+		if r.Index < 1 || r.Index > n {
+			continue
+		}
+		if parsed, err := r.toResult(categories); err == nil {
+			results[r.Index-1] = parsed
+		}
+	}
+	return results, nil
+}
+
+// single returns the only result of a batch of one, ErrNoTag when it is
+// empty.
+func single(results []Result, err error) (Result, error) {
+	if err != nil {
+		return Result{}, err
+	}
+	if len(results) == 1 && results[0].Err != nil {
+		return Result{}, results[0].Err
+	}
+	if len(results) != 1 || results[0].Tag == "" {
+		return Result{}, ErrNoTag
+	}
+	return results[0], nil
+}
@@ -0,0 +1,50 @@
+package tagging
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/rule"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// RuleTagger tags with the deterministic rule engine, a matching rule is
+// fully confident.
+type RuleTagger struct {
+	rf rule.EnabledRulesFetcher
+}
+
+func NewRuleTagger(rf rule.EnabledRulesFetcher) *RuleTagger {
+	return &RuleTagger{rf: rf}
+}
+
+func (t *RuleTagger) Name() string {
+	return "rules"
+}
+
+func (t *RuleTagger) Tag(ctx context.Context, tx *transaction.Transaction, categories []string) (Result, error) {
+	return single(t.TagBatch(ctx, []*transaction.Transaction{tx}, categories))
+}
+
+// TagBatch loads the enabled rules once for all transactions.
+func (t *RuleTagger) TagBatch(ctx context.Context, txs []*transaction.Transaction, categories []string) ([]Result, error) {
+	engine, err := rule.LoadEngine(ctx, t.rf)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]Result, len(txs))
+	for i, tx := range txs {
+		r := engine.TagRule(tx)
+		if r == nil {
+			continue
+		}
+		results[i] = Result{
+			Tag:        *r.Actions.SetTag,
+			Confidence: 1,
+			Rationale:  fmt.Sprintf("matched rule %q", r.Name),
+			Source:     transaction.TagSourceRule,
+			Model:      r.ID.String(),
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,88 @@
+package tagging
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/rule"
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// countingRules returns the rules and counts how often they were fetched.
+type countingRules struct {
+	rules []*rule.Rule
+	err   error
+	calls int
+}
+
+func (f *countingRules) FetchEnabled(ctx context.Context) ([]*rule.Rule, error) {
+	f.calls++
+	return f.rules, f.err
+}
+
+func testRules(t *testing.T) []*rule.Rule {
+	t.Helper()
+	groceries, transport := "groceries", "transport"
+	ah, err := rule.NewRule("ah", 10, true, rule.Conditions{DescriptionRegex: "albert heijn"}, rule.Actions{SetTag: &groceries})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ns, err := rule.NewRule("ns", 10, true, rule.Conditions{DescriptionRegex: "^ns "}, rule.Actions{SetTag: &transport})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []*rule.Rule{ah, ns}
+}
+
+func TestRuleTaggerTagBatch(t *testing.T) {
+	rules := &countingRules{rules: testRules(t)}
+	txs := []*transaction.Transaction{
+		testTransaction("ALBERT HEIJN 1234", 1229),
+		testTransaction("UNKNOWN", 100),
+		testTransaction("NS GROEP", 420),
+	}
+	got, err := NewRuleTagger(rules).TagBatch(context.Background(), txs, testCategories)
+	if err != nil {
+		t.Fatalf("TagBatch() error = %v", err)
+	}
+	want := []string{"groceries", "", "transport"}
+	for i, w := range want {
+		if got[i].Tag != w {
+			t.Errorf("result %d = %+v, want %q", i, got[i], w)
+		}
+		if w != "" && (got[i].Source != transaction.TagSourceRule || got[i].Confidence != 1) {
+			t.Errorf("result %d = %+v, want a confident rule result", i, got[i])
+		}
+	}
+	// the rules are loaded once per batch, not per transaction
+	if rules.calls != 1 {
+		t.Errorf("rules fetched %d times, want 1", rules.calls)
+	}
+}
+
+func TestRuleTaggerTag(t *testing.T) {
+	failing := errors.New("database down")
+	tests := []struct {
+		name    string
+		rules   *countingRules
+		tx      *transaction.Transaction
+		want    string
+		wantErr error
+	}{
+		{"match", &countingRules{rules: testRules(t)}, testTransaction("ALBERT HEIJN 1234", 1229), "groceries", nil},
+		{"no match", &countingRules{rules: testRules(t)}, testTransaction("UNKNOWN", 100), "", ErrNoTag},
+		{"rules fail", &countingRules{err: failing}, testTransaction("ALBERT HEIJN 1234", 1229), "", failing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewRuleTagger(tt.rules).Tag(context.Background(), tt.tx, testCategories)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Tag() error = %v, want %v", err, tt.wantErr)
+			}
+			if got.Tag != tt.want {
+				t.Errorf("Tag() = %+v, want %q", got, tt.want)
+			}
+		})
+	}
+}
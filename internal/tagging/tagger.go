@@ -0,0 +1,48 @@
+package tagging
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lennardclaproth/my-finances-tracker/internal/transaction"
+)
+
+// Result is the outcome of tagging a single transaction.
+type Result struct {
+	// Tag is the slug of the chosen category.
+	Tag string
+	// Confidence between 0 and 1.
+	Confidence float64
+	// Rationale explains the choice in a few words.
+	Rationale string
+	// Tagger is the name of the tagger that produced the result.
+	Tagger string
+	// Source and Model are recorded as the provenance of the tag, Model
+	// identifies the rule, model or agent that chose it.
+	Source transaction.TagSource
+	Model  string
+	// Err is set in batch results when the tagger failed on the transaction,
+	// as opposed to having no opinion on it. Failed transactions are not
+	// tagged so they are tried again.
+	Err error
+}
+
+// Provenance returns the provenance to store with the tag.
+func (r Result) Provenance() transaction.TagProvenance {
+	return transaction.NewTagProvenance(r.Source, r.Confidence, r.Model)
+}
+
+// Tagger chooses a category for a transaction among the given category
+// slugs. Implementations return ErrNoTag when they have no opinion.
+type Tagger interface {
+	Name() string
+	Tag(ctx context.Context, tx *transaction.Transaction, categories []string) (Result, error)
+}
+
+var (
+	ErrNoTag           = fmt.Errorf("tagger could not decide on a tag")
+	ErrInvalidResult   = fmt.Errorf("tagger returned an invalid result")
+	ErrUnknownTagger   = fmt.Errorf("unknown tagger")
+	ErrNoTaggers       = fmt.Errorf("no taggers configured")
+	ErrUnknownCategory = fmt.Errorf("tagger returned an unknown category")
+)
@@ -0,0 +1,45 @@
+package transaction
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultPageSize is used when a filter does not specify a page size.
+const DefaultPageSize = 50
+
+// Filter selects transactions, zero fields are ignored.
+type Filter struct {
+	From           time.Time
+	To             time.Time
+	Tag            string
+	Direction      CashFlowDirection
+	CounterpartyID *uuid.UUID
+	// Labels the transactions must all carry.
+	Labels []string
+	// ReviewBelow selects machine tags with a confidence below it and machine
+	// tags that are uncategorised.
+	ReviewBelow float64
+	Page        int
+	PageSize    int
+}
+
+// IsEmpty reports whether the filter matches every transaction.
+func (f Filter) IsEmpty() bool {
+	return f.From.IsZero() && f.To.IsZero() && f.Tag == "" && f.Direction == "" &&
+		f.CounterpartyID == nil && len(f.Labels) == 0 && f.ReviewBelow <= 0
+}
+
+// Limit returns the page size and offset of the filter.
+func (f Filter) Limit() (limit, offset int) {
+	limit = f.PageSize
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	page := f.Page
+	if page < 1 {
+		page = 1
+	}
+	return limit, (page - 1) * limit
+}
@@ -0,0 +1,49 @@
+package transaction
+
+import "time"
+
+// TagSource tells who decided the tag of a transaction.
+type TagSource string
+
+const (
+	TagSourceManual TagSource = "manual"
+	// TagSourceRule is used for rules and counterparty default tags, both are
+	// defined by the user.
+	TagSourceRule       TagSource = "rule"
+	TagSourceClassifier TagSource = "classifier"
+	// TagSourceAgent is used for taggers backed by a language model and for
+	// the tagger job when no tagger decided.
+	TagSourceAgent TagSource = "agent"
+)
+
+// IsMachine reports whether the tag was guessed rather than decided by the
+// user or one of their rules.
+func (s TagSource) IsMachine() bool {
+	return s == TagSourceClassifier || s == TagSourceAgent
+}
+
+// TagProvenance records who decided the tag of a transaction and when. It is
+// empty for untagged transactions and tags from before it was recorded.
+type TagProvenance struct {
+	TagSource     TagSource `db:"tag_source"`
+	TagConfidence float64   `db:"tag_confidence"`
+	// TaggedBy identifies the rule, model or agent that chose the tag.
+	TaggedBy string     `db:"tagged_by"`
+	TaggedAt *time.Time `db:"tagged_at"`
+}
+
+func NewTagProvenance(source TagSource, confidence float64, taggedBy string) TagProvenance {
+	now := time.Now().UTC()
+	return TagProvenance{TagSource: source, TagConfidence: confidence, TaggedBy: taggedBy, TaggedAt: &now}
+}
+
+// ManualTag is the provenance of a tag chosen by the user.
+func ManualTag() TagProvenance {
+	return NewTagProvenance(TagSourceManual, 1, "")
+}
+
+// SetTag sets the tag of the transaction together with its provenance.
+func (t *Transaction) SetTag(tag string, p TagProvenance) {
+	t.Tag = tag
+	t.TagProvenance = p
+}
@@ -4,11 +4,11 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
-	"math"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lennardclaproth/my-finances-tracker/internal/money"
 )
 
 type CashFlowDirection string
@@ -18,6 +18,10 @@ const (
 	CashOut CashFlowDirection = "out"
 )
 
+// SourceManual is the source of transactions entered by hand instead of
+// imported from a bank statement.
+const SourceManual = "manual"
+
 type Transaction struct {
 	ID          uuid.UUID         `db:"id"`
 	Description string            `db:"description"`
@@ -32,7 +36,26 @@ type Transaction struct {
 	Tag         string            `db:"tag"`
 	RowNumber   int               `db:"row_number"`
 	Ignored     bool              `db:"ignored"`
-	ImportID    uuid.UUID         `db:"import_id"`
+	// ImportID references the import the transaction was read from, nil
+	// for manual entries.
+	ImportID *uuid.UUID `db:"import_id"`
+	// CounterpartyID references the merchant or payee, it is nil until the
+	// counterparty has been resolved.
+	CounterpartyID   *uuid.UUID `db:"counterparty_id"`
+	CounterpartyIBAN string     `db:"counterparty_iban"`
+	// Account identifies our own account the transaction was booked on,
+	// usually its IBAN.
+	Account string `db:"account"`
+	// BalanceAfterCents is the balance of the account after the transaction
+	// as reported by the bank statement, nil when it does not report one.
+	BalanceAfterCents *int64 `db:"balance_after_cents"`
+	// Currency is the ISO 4217 code of the amount, the currency of the
+	// account.
+	Currency string `db:"currency"`
+	// ParentID references the transaction a fee was charged for, nil for
+	// other transactions.
+	ParentID *uuid.UUID `db:"parent_id"`
+	TagProvenance
 }
 
 type TransactionData struct {
@@ -40,10 +63,29 @@ type TransactionData struct {
 	Note        string
 	Source      string
 	Direction   CashFlowDirection
-	Amount      float64
-	Date        time.Time
+	// Amount is the amount without its sign, the direction says which way
+	// it went. Its currency is DefaultCurrency when the statement does not
+	// name one.
+	Amount money.Amount
+	Date   time.Time
+	// CounterpartyIBAN is the account number of the other party when the
+	// statement provides it.
+	CounterpartyIBAN string
+	// Account is the identifier (usually the IBAN) of our own account.
+	Account string
+	// BalanceAfterCents is the resulting balance when the statement
+	// provides it.
+	BalanceAfterCents *int64
+	// Fee is the fee charged on top of the amount, it is imported as a
+	// separate transaction linked to this one. A negative fee was refunded.
+	// The balance after the transaction is the balance before the fee.
+	Fee money.Amount
 }
 
+// DefaultCurrency is the currency of transactions whose statement does not
+// name one.
+const DefaultCurrency = "EUR"
+
 var (
 	ErrDuplicateTransaction = fmt.Errorf("duplicate transaction")
 	ErrInvalidAmount        = fmt.Errorf("invalid amount")
@@ -52,11 +94,15 @@ var (
 )
 
 // NewTransaction creates a new Transaction instance and generates its checksum.
-func NewTransaction(desc, note, source string, direction CashFlowDirection, amount float64, date time.Time, rowNumber int, importID uuid.UUID) (*Transaction, error) {
+func NewTransaction(desc, note, source string, direction CashFlowDirection, amount money.Amount, date time.Time, rowNumber int, importID uuid.UUID) (*Transaction, error) {
 	// Guard on domain level against invalid amount values
-	if math.IsNaN(amount) || math.IsInf(amount, 0) || amount < 0 {
+	if amount.Cents < 0 {
 		return nil, ErrInvalidAmount
 	}
+	currency := DefaultCurrency
+	if amount.Currency != "" {
+		currency = amount.Currency
+	}
 
 	t := &Transaction{
 		ID:          uuid.New(),
@@ -64,17 +110,79 @@ func NewTransaction(desc, note, source string, direction CashFlowDirection, amou
 		Note:        note,
 		Source:      source,
 		Direction:   direction,
-		AmountCents: int64(amount * 100),
+		AmountCents: amount.Cents,
+		Currency:    currency,
 		Date:        date,
 		CreatedAt:   time.Now().UTC(),
 		UpdatedAt:   time.Now().UTC(),
 		RowNumber:   rowNumber,
-		ImportID:    importID,
+		ImportID:    &importID,
+	}
+	t.Checksum = t.generateChecksum()
+	return t, nil
+}
+
+// NewManualTransaction creates a transaction entered by hand, it does not
+// belong to an import.
+func NewManualTransaction(desc, note string, direction CashFlowDirection, amount money.Amount, date time.Time, account string) (*Transaction, error) {
+	if direction != CashIn && direction != CashOut {
+		return nil, ErrUnsupportedDirection
+	}
+	if amount.Cents <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	t, err := NewTransaction(desc, note, SourceManual, direction, amount, date, 0, uuid.Nil)
+	if err != nil {
+		return nil, err
 	}
+	t.ImportID = nil
+	t.Account = strings.TrimSpace(account)
 	t.Checksum = t.generateChecksum()
 	return t, nil
 }
 
+// IsManual reports whether the transaction was entered by hand.
+func (t *Transaction) IsManual() bool {
+	return t.Source == SourceManual && t.ImportID == nil
+}
+
+// NewTransactionFromData creates a new Transaction from parsed statement data.
+func NewTransactionFromData(txd TransactionData, rowNumber int, importID uuid.UUID) (*Transaction, error) {
+	t, err := NewTransaction(txd.Description, txd.Note, txd.Source, txd.Direction, txd.Amount, txd.Date, rowNumber, importID)
+	if err != nil {
+		return nil, err
+	}
+	t.CounterpartyIBAN = strings.TrimSpace(txd.CounterpartyIBAN)
+	t.Account = strings.TrimSpace(txd.Account)
+	t.BalanceAfterCents = txd.BalanceAfterCents
+	return t, nil
+}
+
+// NewFee creates the transaction of a fee charged for t, linked to it. A
+// negative fee was refunded and comes in.
+func (t *Transaction) NewFee(fee money.Amount) (*Transaction, error) {
+	direction := CashOut
+	if fee.Cents < 0 {
+		direction = CashIn
+	}
+	if fee.Currency == "" {
+		fee.Currency = t.Currency
+	}
+	f, err := NewTransaction(fmt.Sprintf("%s fee", t.Source), t.Description, t.Source, direction, fee.Abs(), t.Date, t.RowNumber, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+	f.ImportID = t.ImportID
+	f.Checksum = f.generateChecksum()
+	f.Account = t.Account
+	f.ParentID = &t.ID
+	if t.BalanceAfterCents != nil {
+		balance := *t.BalanceAfterCents - fee.Cents
+		f.BalanceAfterCents = &balance
+	}
+	return f, nil
+}
+
 // generateChecksum creates a checksum for the transaction based on the fields
 // description, note, source, amountCents, and date. It uses amountCents instead
 // of amount to avoid floating-point precision issues.
@@ -87,7 +195,12 @@ func (t *Transaction) generateChecksum() string {
 	direction := string(t.Direction)
 	amountCents := fmt.Sprintf("%d", t.AmountCents)
 	rowNumber := fmt.Sprintf("%d", t.RowNumber)
-	importID := t.ImportID.String()
+	// manual entries are never duplicates of each other, their id takes the
+	// place of the import
+	importID := t.ID.String()
+	if t.ImportID != nil {
+		importID = t.ImportID.String()
+	}
 	date := t.Date.Format("20060102") // Standard date format
 	// concatenate all fields to form the payload string to generate a checksum
 	const sep = "\x1F" // Unit Separator character see -> https://www.ascii-code.com/character/%E2%90%9F
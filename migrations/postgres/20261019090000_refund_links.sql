@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE refund_links (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    original_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    refund_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    amount_cents BIGINT NOT NULL CHECK (amount_cents >= 0),
+    status TEXT NOT NULL DEFAULT 'suggested' CHECK (status IN ('suggested', 'confirmed', 'rejected')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (original_id, refund_id)
+);
+
+-- a refund can only be linked to a single original unless the link was rejected
+CREATE UNIQUE INDEX refund_links_active_refund_key ON refund_links(refund_id) WHERE status <> 'rejected';
+CREATE INDEX idx_refund_links_original_id ON refund_links(original_id);
+CREATE INDEX idx_refund_links_status ON refund_links(status);
+
+-- report_transactions is the source for reporting queries. Confirmed refunds
+-- take over the tag of the original transaction so they are netted against
+-- the original's category instead of showing up as unrelated income.
+CREATE VIEW report_transactions AS
+SELECT
+    t.id,
+    t.description,
+    t.note,
+    t.source,
+    t.amount_cents,
+    t.direction,
+    t.date,
+    t.ignored,
+    t.import_id,
+    COALESCE(o.tag, t.tag) AS tag,
+    l.original_id AS refund_of
+FROM transactions t
+LEFT JOIN refund_links l ON l.refund_id = t.id AND l.status = 'confirmed'
+LEFT JOIN transactions o ON o.id = l.original_id;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP VIEW report_transactions;
+DROP TABLE refund_links;
+-- +goose StatementEnd
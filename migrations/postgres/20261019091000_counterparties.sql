@@ -0,0 +1,65 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE counterparties (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    aliases TEXT[] NOT NULL DEFAULT '{}',
+    ibans TEXT[] NOT NULL DEFAULT '{}',
+    default_tag TEXT NOT NULL DEFAULT '',
+    logo_url TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_counterparties_aliases ON counterparties USING GIN (aliases);
+CREATE INDEX idx_counterparties_ibans ON counterparties USING GIN (ibans);
+
+ALTER TABLE transactions
+    ADD COLUMN counterparty_id UUID REFERENCES counterparties(id) ON DELETE SET NULL,
+    ADD COLUMN counterparty_iban TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_transactions_counterparty_id ON transactions(counterparty_id);
+
+CREATE OR REPLACE VIEW report_transactions AS
+SELECT
+    t.id,
+    t.description,
+    t.note,
+    t.source,
+    t.amount_cents,
+    t.direction,
+    t.date,
+    t.ignored,
+    t.import_id,
+    COALESCE(o.tag, t.tag) AS tag,
+    l.original_id AS refund_of,
+    t.counterparty_id
+FROM transactions t
+LEFT JOIN refund_links l ON l.refund_id = t.id AND l.status = 'confirmed'
+LEFT JOIN transactions o ON o.id = l.original_id;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP VIEW report_transactions;
+CREATE VIEW report_transactions AS
+SELECT
+    t.id,
+    t.description,
+    t.note,
+    t.source,
+    t.amount_cents,
+    t.direction,
+    t.date,
+    t.ignored,
+    t.import_id,
+    COALESCE(o.tag, t.tag) AS tag,
+    l.original_id AS refund_of
+FROM transactions t
+LEFT JOIN refund_links l ON l.refund_id = t.id AND l.status = 'confirmed'
+LEFT JOIN transactions o ON o.id = l.original_id;
+ALTER TABLE transactions DROP COLUMN counterparty_iban, DROP COLUMN counterparty_id;
+DROP TABLE counterparties;
+-- +goose StatementEnd
@@ -0,0 +1,64 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    priority INT NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    conditions JSONB NOT NULL DEFAULT '{}',
+    actions JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_rules_priority ON rules(priority, created_at) WHERE enabled;
+
+ALTER TABLE transactions ADD COLUMN account TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_transactions_account ON transactions(account);
+
+CREATE OR REPLACE VIEW report_transactions AS
+SELECT
+    t.id,
+    t.description,
+    t.note,
+    t.source,
+    t.amount_cents,
+    t.direction,
+    t.date,
+    t.ignored,
+    t.import_id,
+    COALESCE(o.tag, t.tag) AS tag,
+    l.original_id AS refund_of,
+    t.counterparty_id,
+    t.account
+FROM transactions t
+LEFT JOIN refund_links l ON l.refund_id = t.id AND l.status = 'confirmed'
+LEFT JOIN transactions o ON o.id = l.original_id;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP VIEW report_transactions;
+CREATE VIEW report_transactions AS
+SELECT
+    t.id,
+    t.description,
+    t.note,
+    t.source,
+    t.amount_cents,
+    t.direction,
+    t.date,
+    t.ignored,
+    t.import_id,
+    COALESCE(o.tag, t.tag) AS tag,
+    l.original_id AS refund_of,
+    t.counterparty_id
+FROM transactions t
+LEFT JOIN refund_links l ON l.refund_id = t.id AND l.status = 'confirmed'
+LEFT JOIN transactions o ON o.id = l.original_id;
+ALTER TABLE transactions DROP COLUMN account;
+DROP TABLE rules;
+-- +goose StatementEnd
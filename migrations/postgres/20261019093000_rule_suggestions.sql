@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE rule_suggestions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    key_type TEXT NOT NULL CHECK (key_type IN ('counterparty', 'description')),
+    key TEXT NOT NULL,
+    tag TEXT NOT NULL,
+    count INT NOT NULL DEFAULT 1,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'dismissed')),
+    rule_id UUID REFERENCES rules(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (key_type, key, tag)
+);
+
+CREATE INDEX idx_rule_suggestions_status ON rule_suggestions(status);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE rule_suggestions;
+-- +goose StatementEnd
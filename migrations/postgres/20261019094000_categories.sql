@@ -0,0 +1,85 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE categories (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    slug TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    parent_id UUID REFERENCES categories(id),
+    type TEXT NOT NULL CHECK (type IN ('income', 'expense')),
+    colour TEXT NOT NULL DEFAULT '',
+    icon TEXT NOT NULL DEFAULT '',
+    position INT NOT NULL DEFAULT 0,
+    system BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_categories_parent_id ON categories(parent_id);
+
+-- category_slug mirrors category.Slugify
+CREATE FUNCTION category_slug(tag TEXT) RETURNS TEXT AS $$
+    SELECT trim(BOTH '-' FROM regexp_replace(lower(trim(tag)), '[^a-z0-9]+', '-', 'g'))
+$$ LANGUAGE SQL IMMUTABLE;
+
+INSERT INTO categories (slug, name, type, system)
+VALUES ('uncategorised', 'Uncategorised', 'expense', TRUE);
+
+-- the "unk" sentinel of the tagger becomes uncategorised, empty tags mean
+-- untagged
+UPDATE transactions SET tag = '' WHERE tag IS NULL OR trim(tag) = '';
+UPDATE transactions SET tag = 'uncategorised' WHERE lower(trim(tag)) = 'unk';
+UPDATE counterparties SET default_tag = 'uncategorised' WHERE lower(trim(default_tag)) = 'unk';
+
+-- every other tag in use becomes a top level category, tags only used on
+-- incoming transactions are income categories
+INSERT INTO categories (slug, name, type, position)
+SELECT
+    slug,
+    initcap(replace(slug, '-', ' ')),
+    CASE WHEN bool_and(direction = 'in') THEN 'income' ELSE 'expense' END,
+    row_number() OVER (ORDER BY slug)
+FROM (
+    SELECT category_slug(tag) AS slug, direction FROM transactions WHERE tag <> ''
+    UNION ALL
+    SELECT category_slug(default_tag), NULL FROM counterparties
+    UNION ALL
+    SELECT category_slug(actions->>'setTag'), NULL FROM rules WHERE actions ? 'setTag'
+    UNION ALL
+    SELECT category_slug(tag), NULL FROM rule_suggestions
+) tags
+WHERE slug <> '' AND slug <> 'uncategorised'
+GROUP BY slug;
+
+UPDATE transactions
+SET tag = COALESCE(NULLIF(category_slug(tag), ''), 'uncategorised')
+WHERE tag <> '';
+
+UPDATE counterparties SET default_tag = category_slug(default_tag);
+
+UPDATE rules
+SET actions = jsonb_set(actions, '{setTag}', to_jsonb(COALESCE(NULLIF(category_slug(actions->>'setTag'), ''), 'uncategorised')))
+WHERE actions ? 'setTag';
+
+-- tags differing only in spelling collapse into one suggestion
+DELETE FROM rule_suggestions a
+USING rule_suggestions b
+WHERE a.key_type = b.key_type
+  AND a.key = b.key
+  AND category_slug(a.tag) = category_slug(b.tag)
+  AND (a.count, a.id) < (b.count, b.id);
+
+UPDATE rule_suggestions SET tag = COALESCE(NULLIF(category_slug(tag), ''), 'uncategorised');
+
+CREATE INDEX idx_transactions_tag ON transactions(tag);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_transactions_tag;
+UPDATE transactions SET tag = 'unk' WHERE tag = 'uncategorised';
+UPDATE counterparties SET default_tag = 'unk' WHERE default_tag = 'uncategorised';
+DROP FUNCTION category_slug(TEXT);
+DROP TABLE categories;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE bulk_batches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    operation JSONB NOT NULL,
+    affected INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    undone_at TIMESTAMPTZ
+);
+
+-- items keep the state of a transaction before the batch, there is no
+-- foreign key on the transaction so deletions can be undone
+CREATE TABLE bulk_batch_items (
+    batch_id UUID NOT NULL REFERENCES bulk_batches(id) ON DELETE CASCADE,
+    transaction_id UUID NOT NULL,
+    before JSONB NOT NULL,
+    PRIMARY KEY (batch_id, transaction_id)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE bulk_batch_items;
+DROP TABLE bulk_batches;
+-- +goose StatementEnd
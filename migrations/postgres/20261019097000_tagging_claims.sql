@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- claims reserve untagged transactions for a tagger worker until they
+-- expire, so several workers or replicas never tag the same rows
+CREATE TABLE tagging_claims (
+    transaction_id UUID PRIMARY KEY REFERENCES transactions(id) ON DELETE CASCADE,
+    claimed_until TIMESTAMPTZ NOT NULL
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE tagging_claims;
+-- +goose StatementEnd
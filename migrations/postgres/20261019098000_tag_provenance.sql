@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE transactions
+    ADD COLUMN tag_source TEXT NOT NULL DEFAULT '' CHECK (tag_source IN ('', 'manual', 'rule', 'classifier', 'agent')),
+    ADD COLUMN tag_confidence DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN tagged_by TEXT NOT NULL DEFAULT '',
+    ADD COLUMN tagged_at TIMESTAMPTZ;
+
+-- uncategorised transactions were written by the tagger when tagging
+-- failed, they go to the review queue and are tagged again
+UPDATE transactions
+SET tag_source = 'agent', tagged_at = updated_at
+WHERE tag = 'uncategorised';
+
+CREATE INDEX idx_transactions_tag_review ON transactions(tag_confidence)
+WHERE tag_source IN ('classifier', 'agent');
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_transactions_tag_review;
+ALTER TABLE transactions
+    DROP COLUMN tag_source,
+    DROP COLUMN tag_confidence,
+    DROP COLUMN tagged_by,
+    DROP COLUMN tagged_at;
+-- +goose StatementEnd
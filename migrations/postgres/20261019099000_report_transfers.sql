@@ -0,0 +1,56 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- transfers between our own accounts are neither income nor expense, a
+-- transaction is a transfer when its counterparty is one of the accounts we
+-- import statements of. The accounts are collected once per query instead of
+-- probed per transaction, idx_transactions_account covers them.
+CREATE OR REPLACE VIEW report_transactions AS
+WITH own_accounts AS (
+    SELECT DISTINCT account FROM transactions WHERE account <> ''
+)
+SELECT
+    t.id,
+    t.description,
+    t.note,
+    t.source,
+    t.amount_cents,
+    t.direction,
+    t.date,
+    t.ignored,
+    t.import_id,
+    COALESCE(o.tag, t.tag) AS tag,
+    l.original_id AS refund_of,
+    t.counterparty_id,
+    t.account,
+    t.counterparty_iban,
+    own.account IS NOT NULL AS transfer
+FROM transactions t
+LEFT JOIN refund_links l ON l.refund_id = t.id AND l.status = 'confirmed'
+LEFT JOIN transactions o ON o.id = l.original_id
+LEFT JOIN own_accounts own ON own.account = t.counterparty_iban;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP VIEW report_transactions;
+CREATE VIEW report_transactions AS
+SELECT
+    t.id,
+    t.description,
+    t.note,
+    t.source,
+    t.amount_cents,
+    t.direction,
+    t.date,
+    t.ignored,
+    t.import_id,
+    COALESCE(o.tag, t.tag) AS tag,
+    l.original_id AS refund_of,
+    t.counterparty_id,
+    t.account
+FROM transactions t
+LEFT JOIN refund_links l ON l.refund_id = t.id AND l.status = 'confirmed'
+LEFT JOIN transactions o ON o.id = l.original_id;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE budgets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    amount_cents BIGINT NOT NULL CHECK (amount_cents > 0),
+    period TEXT NOT NULL DEFAULT 'month' CHECK (period IN ('week', 'month', 'quarter', 'year')),
+    start_date DATE NOT NULL,
+    rollover BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (category_id, period)
+);
+
+-- budget_alerts records the thresholds that were reached per period so every
+-- alert is only sent once
+CREATE TABLE budget_alerts (
+    budget_id UUID NOT NULL REFERENCES budgets(id) ON DELETE CASCADE,
+    period_start DATE NOT NULL,
+    threshold INT NOT NULL,
+    spent_cents BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (budget_id, period_start, threshold)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE budget_alerts;
+DROP TABLE budgets;
+-- +goose StatementEnd
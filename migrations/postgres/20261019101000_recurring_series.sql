@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- recurring_series holds the series found by the last detection run, every
+-- run replaces all of them
+CREATE TABLE recurring_series (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    group_key TEXT NOT NULL,
+    name TEXT NOT NULL,
+    counterparty_id UUID REFERENCES counterparties(id) ON DELETE SET NULL,
+    direction TEXT NOT NULL CHECK (direction IN ('in', 'out')),
+    frequency TEXT NOT NULL CHECK (frequency IN ('weekly', 'monthly', 'quarterly', 'yearly')),
+    amount_cents BIGINT NOT NULL,
+    previous_amount_cents BIGINT NOT NULL,
+    first_date DATE NOT NULL,
+    last_date DATE NOT NULL,
+    next_date DATE NOT NULL,
+    occurrences INT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('active', 'missed', 'ended')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_recurring_series_next_date ON recurring_series(next_date);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE recurring_series;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- series are detected per account so forecasts can be made per account
+ALTER TABLE recurring_series ADD COLUMN account TEXT NOT NULL DEFAULT '';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE recurring_series DROP COLUMN account;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- balance_after_cents is the resulting balance reported by the statement
+ALTER TABLE transactions ADD COLUMN balance_after_cents BIGINT;
+
+-- balance_anchors are closing balances set by the user, daily balances are
+-- reconstructed from them
+CREATE TABLE balance_anchors (
+    account TEXT NOT NULL,
+    date DATE NOT NULL,
+    balance_cents BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (account, date)
+);
+
+CREATE INDEX idx_transactions_account_date ON transactions(account, date);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_transactions_account_date;
+DROP TABLE balance_anchors;
+ALTER TABLE transactions DROP COLUMN balance_after_cents;
+-- +goose StatementEnd
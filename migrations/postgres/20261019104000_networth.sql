@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- accounts holds the settings of our own accounts, accounts without a row
+-- are checking accounts
+CREATE TABLE accounts (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL DEFAULT '',
+    type TEXT NOT NULL DEFAULT 'checking' CHECK (type IN ('checking', 'savings', 'investment')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- networth_items are assets and liabilities entered by hand, loan holds the
+-- amortisation schedule of a liability
+CREATE TABLE networth_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    class TEXT NOT NULL CHECK (class IN ('investments', 'property', 'vehicle', 'other_asset', 'mortgage', 'loan', 'other_liability')),
+    symbol TEXT NOT NULL DEFAULT '',
+    quantity_micros BIGINT NOT NULL DEFAULT 0,
+    loan JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE networth_valuations (
+    item_id UUID NOT NULL REFERENCES networth_items(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    value_cents BIGINT NOT NULL,
+    PRIMARY KEY (item_id, date)
+);
+
+-- networth_snapshots materialise the net worth per component at the end of
+-- every month, they are written once and never updated
+CREATE TABLE networth_snapshots (
+    date DATE NOT NULL,
+    source TEXT NOT NULL,
+    name TEXT NOT NULL,
+    class TEXT NOT NULL,
+    value_cents BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (date, source)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE networth_snapshots;
+DROP TABLE networth_valuations;
+DROP TABLE networth_items;
+DROP TABLE accounts;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- market_history holds the daily prices of securities, prices are stored in
+-- millionths of a currency unit
+CREATE TABLE market_history (
+    id UUID PRIMARY KEY,
+    symbol TEXT NOT NULL,
+    date DATE NOT NULL,
+    open BIGINT NOT NULL,
+    high BIGINT NOT NULL,
+    low BIGINT NOT NULL,
+    close BIGINT NOT NULL,
+    volume BIGINT NOT NULL DEFAULT 0,
+    UNIQUE (symbol, date)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE market_history;
+-- +goose StatementEnd
@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE securities (
+    id UUID PRIMARY KEY,
+    isin TEXT NOT NULL UNIQUE,
+    -- symbol is the ticker the market prices are stored under
+    symbol TEXT NOT NULL DEFAULT '',
+    name TEXT NOT NULL,
+    currency TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- investment_trades are buys, sells, dividends, fees and splits, amounts are
+-- in cents and quantities and prices in millionths
+CREATE TABLE investment_trades (
+    id UUID PRIMARY KEY,
+    account TEXT NOT NULL,
+    security_id UUID REFERENCES securities(id) ON DELETE CASCADE,
+    date TIMESTAMPTZ NOT NULL,
+    type TEXT NOT NULL,
+    quantity_micros BIGINT NOT NULL DEFAULT 0,
+    price BIGINT NOT NULL DEFAULT 0,
+    amount_cents BIGINT NOT NULL DEFAULT 0,
+    fee_cents BIGINT NOT NULL DEFAULT 0,
+    currency TEXT NOT NULL,
+    ratio_from BIGINT NOT NULL DEFAULT 0,
+    ratio_to BIGINT NOT NULL DEFAULT 0,
+    -- external_id identifies the statement line so re-imports are skipped
+    external_id TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (account, external_id)
+);
+
+CREATE INDEX idx_investment_trades_date ON investment_trades(date);
+CREATE INDEX idx_investment_trades_security ON investment_trades(security_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE investment_trades;
+DROP TABLE securities;
+-- +goose StatementEnd
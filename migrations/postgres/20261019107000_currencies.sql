@@ -0,0 +1,127 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- amounts are in the currency of their account, everything imported so far
+-- was in euros
+ALTER TABLE transactions ADD COLUMN currency TEXT NOT NULL DEFAULT 'EUR';
+ALTER TABLE accounts ADD COLUMN currency TEXT NOT NULL DEFAULT 'EUR';
+ALTER TABLE recurring_series ADD COLUMN currency TEXT NOT NULL DEFAULT 'EUR';
+
+-- snapshots keep the value in the currency of the component next to the
+-- converted value
+ALTER TABLE networth_snapshots ADD COLUMN currency TEXT NOT NULL DEFAULT 'EUR';
+ALTER TABLE networth_snapshots ADD COLUMN original_cents BIGINT;
+UPDATE networth_snapshots SET original_cents = value_cents;
+ALTER TABLE networth_snapshots ALTER COLUMN original_cents SET NOT NULL;
+
+-- fx_rates holds the ECB reference rates, the amount of the currency one
+-- euro buys in millionths
+CREATE TABLE fx_rates (
+    currency TEXT NOT NULL,
+    date DATE NOT NULL,
+    rate_micros BIGINT NOT NULL CHECK (rate_micros > 0),
+    PRIMARY KEY (currency, date)
+);
+
+-- fx_rate returns the rate of the currency on the last day on or before the
+-- date that has one, the first later rate when there is none before it and
+-- NULL when the currency has no rates
+CREATE FUNCTION fx_rate(ccy TEXT, on_date DATE) RETURNS NUMERIC AS $$
+    SELECT CASE WHEN ccy = 'EUR' THEN 1000000::NUMERIC ELSE COALESCE(
+        (SELECT r.rate_micros::NUMERIC FROM fx_rates r
+         WHERE r.currency = ccy AND r.date <= on_date
+         ORDER BY r.date DESC LIMIT 1),
+        (SELECT r.rate_micros::NUMERIC FROM fx_rates r
+         WHERE r.currency = ccy AND r.date > on_date
+         ORDER BY r.date ASC LIMIT 1)
+    ) END
+$$ LANGUAGE SQL STABLE;
+
+-- currency_exponent returns the number of decimals of the currency like
+-- money.Exponent
+CREATE FUNCTION currency_exponent(ccy TEXT) RETURNS INT AS $$
+    SELECT CASE
+        WHEN ccy IN ('BIF', 'CLP', 'DJF', 'GNF', 'ISK', 'JPY', 'KMF', 'KRW', 'PYG',
+                     'RWF', 'UGX', 'UYI', 'VND', 'VUV', 'XAF', 'XOF', 'XPF') THEN 0
+        WHEN ccy IN ('BHD', 'IQD', 'JOD', 'KWD', 'LYD', 'OMR', 'TND') THEN 3
+        WHEN ccy IN ('CLF', 'UYW') THEN 4
+        ELSE 2
+    END
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- fx_convert converts cents, the minor units of each currency, between
+-- currencies with the rates of the date, rounding half away from zero like
+-- fx.Convert
+CREATE FUNCTION fx_convert(amount_cents BIGINT, from_ccy TEXT, to_ccy TEXT, on_date DATE) RETURNS BIGINT AS $$
+    SELECT CASE WHEN from_ccy = to_ccy THEN amount_cents
+        ELSE ROUND(amount_cents * fx_rate(to_ccy, on_date)
+            * 10::NUMERIC ^ (currency_exponent(to_ccy) - currency_exponent(from_ccy))
+            / fx_rate(from_ccy, on_date))::BIGINT END
+$$ LANGUAGE SQL STABLE;
+
+CREATE OR REPLACE VIEW report_transactions AS
+WITH own_accounts AS (
+    SELECT DISTINCT account FROM transactions WHERE account <> ''
+)
+SELECT
+    t.id,
+    t.description,
+    t.note,
+    t.source,
+    t.amount_cents,
+    t.direction,
+    t.date,
+    t.ignored,
+    t.import_id,
+    COALESCE(o.tag, t.tag) AS tag,
+    l.original_id AS refund_of,
+    t.counterparty_id,
+    t.account,
+    t.counterparty_iban,
+    own.account IS NOT NULL AS transfer,
+    t.currency
+FROM transactions t
+LEFT JOIN refund_links l ON l.refund_id = t.id AND l.status = 'confirmed'
+LEFT JOIN transactions o ON o.id = l.original_id
+LEFT JOIN own_accounts own ON own.account = t.counterparty_iban;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP VIEW report_transactions;
+CREATE VIEW report_transactions AS
+WITH own_accounts AS (
+    SELECT DISTINCT account FROM transactions WHERE account <> ''
+)
+SELECT
+    t.id,
+    t.description,
+    t.note,
+    t.source,
+    t.amount_cents,
+    t.direction,
+    t.date,
+    t.ignored,
+    t.import_id,
+    COALESCE(o.tag, t.tag) AS tag,
+    l.original_id AS refund_of,
+    t.counterparty_id,
+    t.account,
+    t.counterparty_iban,
+    own.account IS NOT NULL AS transfer
+FROM transactions t
+LEFT JOIN refund_links l ON l.refund_id = t.id AND l.status = 'confirmed'
+LEFT JOIN transactions o ON o.id = l.original_id
+LEFT JOIN own_accounts own ON own.account = t.counterparty_iban;
+
+DROP FUNCTION fx_convert(BIGINT, TEXT, TEXT, DATE);
+DROP FUNCTION currency_exponent(TEXT);
+DROP FUNCTION fx_rate(TEXT, DATE);
+DROP TABLE fx_rates;
+ALTER TABLE networth_snapshots DROP COLUMN original_cents;
+ALTER TABLE networth_snapshots DROP COLUMN currency;
+ALTER TABLE recurring_series DROP COLUMN currency;
+ALTER TABLE accounts DROP COLUMN currency;
+ALTER TABLE transactions DROP COLUMN currency;
+-- +goose StatementEnd
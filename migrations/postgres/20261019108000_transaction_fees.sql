@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- parent_id links a fee to the transaction it was charged for, statements
+-- that report fees next to the amount are imported as two transactions
+ALTER TABLE transactions ADD COLUMN parent_id UUID REFERENCES transactions(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_transactions_parent_id ON transactions(parent_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_transactions_parent_id;
+ALTER TABLE transactions DROP COLUMN parent_id;
+-- +goose StatementEnd
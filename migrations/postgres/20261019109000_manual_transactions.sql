@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- manual entries are entered by hand and do not belong to an import
+ALTER TABLE transactions ALTER COLUMN import_id DROP NOT NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM transactions WHERE import_id IS NULL;
+ALTER TABLE transactions ALTER COLUMN import_id SET NOT NULL;
+-- +goose StatementEnd
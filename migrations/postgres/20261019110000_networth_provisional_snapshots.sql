@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- provisional snapshots were backfilled for months that had ended long
+-- before, they are recomputed until they are confirmed
+ALTER TABLE networth_snapshots ADD COLUMN provisional BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- snapshots taken after the following month had ended were backfilled
+UPDATE networth_snapshots SET provisional = TRUE
+WHERE created_at::date > date + INTERVAL '1 month';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE networth_snapshots DROP COLUMN provisional;
+-- +goose StatementEnd